@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/app"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/spf13/cobra"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	backupTenantID string
+	backupFile     string
+)
+
+var backupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Export or restore a tenant's data archive",
+}
+
+var backupExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export a tenant's data to a gzipped archive",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tenantID, err := primitive.ObjectIDFromHex(backupTenantID)
+		if err != nil {
+			return fmt.Errorf("invalid tenant ID: %w", err)
+		}
+
+		out, err := os.Create(backupFile)
+		if err != nil {
+			return fmt.Errorf("failed to create archive: %w", err)
+		}
+		defer out.Close()
+
+		return runBackup(cmd, func(ctx context.Context, uc usecase.BackupUsecase) (*models.BackupManifest, error) {
+			return uc.Export(ctx, tenantID, out)
+		})
+	},
+}
+
+var backupRestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore a tenant archive, overwriting documents with the same IDs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		in, err := os.Open(backupFile)
+		if err != nil {
+			return fmt.Errorf("failed to open archive: %w", err)
+		}
+		defer in.Close()
+
+		return runBackup(cmd, func(ctx context.Context, uc usecase.BackupUsecase) (*models.BackupManifest, error) {
+			return uc.Restore(ctx, in)
+		})
+	},
+}
+
+func runBackup(cmd *cobra.Command, run func(ctx context.Context, uc usecase.BackupUsecase) (*models.BackupManifest, error)) error {
+	var backupUsecase usecase.BackupUsecase
+	fxApp := app.Invoke(func(uc usecase.BackupUsecase) {
+		backupUsecase = uc
+	})
+	if err := fxApp.Start(cmd.Context()); err != nil {
+		return err
+	}
+	defer fxApp.Stop(context.Background())
+
+	manifest, err := run(cmd.Context(), backupUsecase)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(cmd.OutOrStdout())
+	enc.SetIndent("", "  ")
+	return enc.Encode(manifest)
+}
+
+func init() {
+	backupExportCmd.Flags().StringVar(&backupTenantID, "tenant", "", "tenant to export")
+	backupExportCmd.Flags().StringVar(&backupFile, "out", "", "archive file to write")
+	_ = backupExportCmd.MarkFlagRequired("tenant")
+	_ = backupExportCmd.MarkFlagRequired("out")
+
+	backupRestoreCmd.Flags().StringVar(&backupFile, "in", "", "archive file to restore")
+	_ = backupRestoreCmd.MarkFlagRequired("in")
+
+	backupCmd.AddCommand(backupExportCmd, backupRestoreCmd)
+	rootCmd.AddCommand(backupCmd)
+}
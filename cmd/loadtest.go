@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/loadtest"
+	"github.com/spf13/cobra"
+)
+
+var loadTestOpts loadtest.Options
+
+var loadTestCmd = &cobra.Command{
+	Use:   "loadtest",
+	Short: "Post synthetic messages to a running instance and report throughput, stage latencies and MongoDB work",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		report, err := loadtest.Run(cmd.Context(), loadTestOpts)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	},
+}
+
+func init() {
+	flags := loadTestCmd.Flags()
+	flags.StringVar(&loadTestOpts.URL, "url", "http://localhost:8080", "base URL of the instance under test")
+	flags.StringVar(&loadTestOpts.APIKey, "api-key", "", "tenant API key, when the instance requires one")
+	flags.StringVar(&loadTestOpts.AdminKey, "admin-key", "", "admin key, needed for the mongo, llm and app stages")
+	flags.StringVar(&loadTestOpts.ChatMode, "chat-mode", "", "chat mode the messages are processed with")
+	flags.StringSliceVar(&loadTestOpts.ChannelIDs, "channel-id", nil, "sandbox channels the messages are spread across")
+	flags.StringVar(&loadTestOpts.SenderID, "sender-id", "onboarding-buyer", "buyer sending the messages")
+	flags.Float64Var(&loadTestOpts.Rate, "rate", 10, "messages started per second")
+	flags.DurationVar(&loadTestOpts.Duration, "duration", time.Minute, "how long messages are sent")
+	flags.IntVar(&loadTestOpts.Concurrency, "concurrency", 200, "messages in flight before new ones are dropped")
+	flags.BoolVar(&loadTestOpts.DryTools, "dry-tools", true, "run the session's tools on fixtures")
+	flags.DurationVar(&loadTestOpts.Timeout, "timeout", 2*time.Minute, "timeout of one message")
+	_ = loadTestCmd.MarkFlagRequired("chat-mode")
+	_ = loadTestCmd.MarkFlagRequired("channel-id")
+
+	rootCmd.AddCommand(loadTestCmd)
+}
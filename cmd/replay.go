@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/app"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+var (
+	replayReq        models.ReplayRequest
+	replayPromptFile string
+	replayCondition  string
+	replayProvider   string
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay logged sessions against a chat mode and print the tool-call diff report",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if replayPromptFile != "" {
+			prompt, err := os.ReadFile(replayPromptFile)
+			if err != nil {
+				return fmt.Errorf("failed to read prompt file: %w", err)
+			}
+			replayReq.PromptTemplate = string(prompt)
+		}
+		if cmd.Flags().Changed("condition") {
+			replayReq.Condition = &replayCondition
+		}
+		replayReq.Provider = models.ReplayProvider(replayProvider)
+
+		var replayUsecase usecase.ReplayUsecase
+		fxApp := app.Invoke(func(uc usecase.ReplayUsecase) {
+			replayUsecase = uc
+		})
+		if err := fxApp.Start(cmd.Context()); err != nil {
+			return err
+		}
+		defer fxApp.Stop(context.Background())
+
+		// like the admin endpoint, replays read sessions of every tenant
+		report, err := replayUsecase.Run(models.WithAllTenants(cmd.Context()), replayReq)
+		if err != nil {
+			return err
+		}
+
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	},
+}
+
+func init() {
+	flags := replayCmd.Flags()
+	flags.StringVar(&replayReq.ChatMode, "chat-mode", "", "stored chat mode the candidate starts from")
+	flags.StringVar(&replayPromptFile, "prompt-file", "", "file with the candidate prompt template")
+	flags.StringVar(&replayCondition, "condition", "", "candidate when condition")
+	flags.StringVar(&replayReq.Model, "model", "", "candidate model")
+	flags.StringSliceVar(&replayReq.Tools, "tools", nil, "candidate tools")
+	flags.StringSliceVar(&replayReq.SessionIDs, "session-id", nil, "sessions to replay, defaults to the most recent ones")
+	flags.StringVar(&replayReq.SourceChatMode, "source-chat-mode", "", "only replay recent sessions of this chat mode")
+	flags.IntVar(&replayReq.Limit, "limit", 0, "number of recent sessions to replay")
+	flags.StringVar(&replayProvider, "provider", string(models.ReplayProviderRecorded), "recorded or live")
+	_ = replayCmd.MarkFlagRequired("chat-mode")
+
+	rootCmd.AddCommand(replayCmd)
+}
@@ -18,6 +18,10 @@ var rootCmd = &cobra.Command{
 			usecase.AutoMigrate,
 			server.StartServer,
 			kafka.StartConsumeMessages,
+			usecase.StartReconciler,
+			usecase.StartOutcomeResolver,
+			usecase.StartSessionTimeouts,
+			usecase.StartJobWorker,
 		).Run()
 	},
 }
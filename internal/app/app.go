@@ -9,12 +9,21 @@ import (
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chotot"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/googleai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mailer"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/storage"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/add_item"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/end_session"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/fetch_full_context"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/fetch_messages"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/get_buyer_profile"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/list_products"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/purchase_intent"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reply_message"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reserve_item"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/set_outcome"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/switch_item"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
 	"github.com/nguyentranbao-ct/chat-bot/internal/server"
 	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
@@ -42,19 +51,107 @@ func Invoke(funcs ...any) *fx.App {
 			server.NewHandler,
 
 			usecase.NewLLMUsecase,
+			usecase.NewLLMKeyUsecase,
 			usecase.NewMessageUsecase,
+			usecase.NewOnboardingUsecase,
+			usecase.NewOutcomeUsecase,
+			usecase.NewPrivacyUsecase,
+			usecase.NewPersonaUsecase,
+			usecase.NewPromptLogUsecase,
+			usecase.NewReconcileUsecase,
+			usecase.NewReplyAssistUsecase,
+			usecase.NewReplayUsecase,
 			usecase.NewWhitelistService,
 			usecase.NewUserUsecase,
+			usecase.NewActivityFeedUsecase,
+			usecase.NewAuditUsecase,
+			usecase.NewAuthGuardUsecase,
+			usecase.NewAvatarUsecase,
+			usecase.NewBackupUsecase,
+			usecase.NewBudgetUsecase,
+			usecase.NewBuyerLanguageTracker,
+			usecase.NewCaptchaVerifier,
+			usecase.NewBulkUsecase,
+			usecase.NewChannelClaimUsecase,
+			usecase.NewChannelUsecase,
+			usecase.NewChatModeChecker,
+			usecase.NewChatModePackUsecase,
+			usecase.NewChatModeSelector,
+			usecase.NewChototLinkUsecase,
+			usecase.NewContextCompactor,
+			usecase.NewCostUsecase,
+			usecase.NewChatEventUsecase,
+			usecase.NewDeadLetterUsecase,
+			usecase.NewConversationRecapper,
+			usecase.NewDraftUsecase,
+			usecase.NewEmailVerificationUsecase,
+			usecase.NewIdentityMapper,
+			usecase.NewIntentClassifier,
+			usecase.NewJobUsecase,
+			usecase.NewUserHydrator,
+			usecase.NewListingExpander,
+			usecase.NewReservationUsecase,
+			usecase.NewScamUsecase,
+			usecase.NewSentimentUsecase,
+			usecase.NewSessionTimeoutUsecase,
+			usecase.NewSessionTraceUsecase,
+			usecase.NewSimulatorUsecase,
+			usecase.NewSuggestionUsecase,
+			usecase.NewSystemMessageUsecase,
+			usecase.NewTeamUsecase,
+			usecase.NewTemplateUsecase,
+			usecase.NewTenantUsecase,
+			usecase.NewTestMessageUsecase,
+			usecase.NewTranscriptUsecase,
 
+			mongodb.NewActivityFeedRepository,
+			mongodb.NewAPIKeyRepository,
+			mongodb.NewAuditLogRepository,
+			mongodb.NewAuthFailureRepository,
+			mongodb.NewBackupRepository,
+			mongodb.NewChannelAssignmentRepository,
+			mongodb.NewChannelBudgetRepository,
+			mongodb.NewChannelClaimRepository,
+			mongodb.NewChannelChatModeRepository,
+			mongodb.NewChannelContextRepository,
+			mongodb.NewChannelCursorRepository,
+			mongodb.NewChannelItemRepository,
+			mongodb.NewChannelPrivacyRepository,
+			mongodb.NewChannelSentimentRepository,
 			mongodb.NewChatActivityRepository,
 			mongodb.NewChatModeRepository,
 			mongodb.NewChatSessionRepository,
+			mongodb.NewChototLinkRepository,
+			mongodb.NewDeadLetterRepository,
+			mongodb.NewDraftRepository,
+			mongodb.NewEmailVerificationRepository,
+			mongodb.NewJobRepository,
+			mongodb.NewLLMCostRepository,
+			mongodb.NewLLMKeyRepository,
+			mongodb.NewLLMOutageRepository,
+			mongodb.NewMessageTemplateRepository,
+			mongodb.NewOnboardingRepository,
+			mongodb.NewPersonaRepository,
+			mongodb.NewPromptLogRepository,
 			mongodb.NewPurchaseIntentRepository,
+			mongodb.NewReplySuggestionRepository,
+			mongodb.NewReservationRepository,
+			mongodb.NewScamFlagRepository,
+			mongodb.NewSessionTraceRepository,
+			mongodb.NewSystemMessageRepository,
+			mongodb.NewTeamRepository,
+			mongodb.NewTenantRepository,
+			mongodb.NewTestMessageRepository,
+			mongodb.NewTranscriptRepository,
 			mongodb.NewUserRepository,
 			mongodb.NewUserAttributeRepository,
+			mongodb.NewWhitelistRepository,
 
 			chatapi.NewChatAPIClient,
 			chotot.NewClient,
+			googleai.NewClient,
+			mailer.NewMailer,
+			storage.NewStorage,
 			list_products.NewProductServiceRegistry,
 
 			toolsmanager.NewToolsManager,
@@ -64,10 +161,25 @@ func Invoke(funcs ...any) *fx.App {
 			fetch_messages.NewTool,
 			reply_message.NewTool,
 			list_products.NewTool,
+			reserve_item.NewTool,
+			get_buyer_profile.NewTool,
+			set_outcome.NewTool,
+			add_item.NewTool,
+			switch_item.NewTool,
+			fetch_full_context.NewTool,
+			newOutcomeRecorder,
+		),
+		fx.Decorate(
+			cacheChatModeRepository,
+			cacheUserAttributeRepository,
+			decorateChatAPIClient,
 		),
 		fx.Supply(conf),
+		fx.Invoke(InitializeIndexes),
+		fx.Invoke(InitializeSchemaValidators),
 		fx.Invoke(InitializeUsers),
 		fx.Invoke(InitializeProductServices),
+		fx.Invoke(SelfCheck),
 		fx.Invoke(funcs...),
 	)
 }
@@ -96,15 +208,174 @@ func InitializeProductServices(
 	})
 }
 
+// InitializeIndexes ensures collection indexes required for correctness exist on startup
+func InitializeIndexes(
+	lc fx.Lifecycle,
+	reservationRepo mongodb.ReservationRepository,
+	apiKeyRepo mongodb.APIKeyRepository,
+	auditLogRepo mongodb.AuditLogRepository,
+	userAttrRepo mongodb.UserAttributeRepository,
+	draftRepo mongodb.DraftRepository,
+	transcriptRepo mongodb.TranscriptRepository,
+	promptLogRepo mongodb.PromptLogRepository,
+	onboardingRepo mongodb.OnboardingRepository,
+	chototLinkRepo mongodb.ChototLinkRepository,
+	channelCursorRepo mongodb.ChannelCursorRepository,
+	channelChatModeRepo mongodb.ChannelChatModeRepository,
+	sessionRepo mongodb.ChatSessionRepository,
+	personaRepo mongodb.PersonaRepository,
+	suggestionRepo mongodb.ReplySuggestionRepository,
+	channelSentimentRepo mongodb.ChannelSentimentRepository,
+	scamFlagRepo mongodb.ScamFlagRepository,
+	channelBudgetRepo mongodb.ChannelBudgetRepository,
+	llmOutageRepo mongodb.LLMOutageRepository,
+	channelItemRepo mongodb.ChannelItemRepository,
+	channelContextRepo mongodb.ChannelContextRepository,
+	jobRepo mongodb.JobRepository,
+	testMessageRepo mongodb.TestMessageRepository,
+	activityFeedRepo mongodb.ActivityFeedRepository,
+	teamRepo mongodb.TeamRepository,
+	channelAssignmentRepo mongodb.ChannelAssignmentRepository,
+	channelClaimRepo mongodb.ChannelClaimRepository,
+	templateRepo mongodb.MessageTemplateRepository,
+	systemMessageRepo mongodb.SystemMessageRepository,
+	sessionTraceRepo mongodb.SessionTraceRepository,
+	whitelistRepo mongodb.WhitelistRepository,
+	emailVerificationRepo mongodb.EmailVerificationRepository,
+	authFailureRepo mongodb.AuthFailureRepository,
+	llmCostRepo mongodb.LLMCostRepository,
+	deadLetterRepo mongodb.DeadLetterRepository,
+	channelPrivacyRepo mongodb.ChannelPrivacyRepository,
+) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if err := reservationRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := apiKeyRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := auditLogRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := userAttrRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := draftRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := transcriptRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := promptLogRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := onboardingRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := chototLinkRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelCursorRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelChatModeRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := sessionRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := personaRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := suggestionRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelSentimentRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := scamFlagRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelBudgetRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := llmOutageRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelItemRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelContextRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := jobRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := testMessageRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := activityFeedRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := teamRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelAssignmentRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := channelClaimRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := templateRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := systemMessageRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := sessionTraceRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := whitelistRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := emailVerificationRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := authFailureRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := llmCostRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			if err := deadLetterRepo.EnsureIndexes(ctx); err != nil {
+				return err
+			}
+			return channelPrivacyRepo.EnsureIndexes(ctx)
+		},
+	})
+}
+
+// InitializeSchemaValidators applies the collection validators before any write on startup
+func InitializeSchemaValidators(lc fx.Lifecycle, db *mongodb.DB, conf *config.Config) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return mongodb.ApplySchemaValidators(ctx, db, conf.Database.SchemaValidation)
+		},
+	})
+}
+
 // InitializeUsers initializes default users and attributes on startup
 func InitializeUsers(
 	lc fx.Lifecycle,
 	userRepo mongodb.UserRepository,
 	userAttrRepo mongodb.UserAttributeRepository,
+	auditUsecase usecase.AuditUsecase,
+	conf *config.Config,
 ) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
-			return usecase.AutoMigrateUsers(userRepo, userAttrRepo)
+			return usecase.AutoMigrateUsers(userRepo, userAttrRepo, auditUsecase, conf.Timeouts.Startup)
 		},
 	})
 }
@@ -3,20 +3,55 @@ package app
 import (
 	"context"
 	"fmt"
-	"time"
+	"strconv"
 
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/set_outcome"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 	"go.uber.org/fx"
 )
 
 func newMongoDB(lc fx.Lifecycle, cfg *config.Config) (*mongodb.DB, error) {
+	readPref, err := newReadPref(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	writeConcern, err := newWriteConcern(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := options.Client().
 		SetAppName("chat-bot").
 		SetDirect(cfg.Database.Direct).
-		SetHosts(cfg.Database.Hosts)
+		SetHosts(cfg.Database.Hosts).
+		SetReadPreference(readPref).
+		SetWriteConcern(writeConcern).
+		SetRetryWrites(cfg.Database.RetryWrites).
+		SetRetryReads(cfg.Database.RetryReads).
+		SetTimeout(cfg.Database.Timeout).
+		SetConnectTimeout(cfg.Database.ConnectTimeout).
+		SetServerSelectionTimeout(cfg.Database.ServerSelectionTimeout).
+		SetMaxPoolSize(cfg.Database.MaxPoolSize)
+
+	var monitors []*event.CommandMonitor
+	if cfg.Database.SlowQueryThreshold > 0 {
+		monitors = append(monitors, mongodb.NewSlowQueryMonitor(cfg.Database.SlowQueryThreshold))
+	}
+	if cfg.Server.DebugStats {
+		monitors = append(monitors, mongodb.NewRequestStatsMonitor())
+	}
+	if len(monitors) > 0 {
+		opts.SetMonitor(mongodb.CombineMonitors(monitors...))
+	}
 
 	if cfg.Database.Username != "" {
 		opts.SetAuth(options.Credential{
@@ -27,7 +62,7 @@ func newMongoDB(lc fx.Lifecycle, cfg *config.Config) (*mongodb.DB, error) {
 		})
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeouts.Startup)
 	defer cancel()
 	mongoClient, err := mongo.Connect(ctx, opts)
 	if err != nil {
@@ -50,3 +85,81 @@ func newMongoDB(lc fx.Lifecycle, cfg *config.Config) (*mongodb.DB, error) {
 		Database: mongoDB,
 	}, nil
 }
+
+func newReadPref(cfg config.DatabaseConfig) (*readpref.ReadPref, error) {
+	mode, err := readpref.ModeFromString(cfg.ReadPreference)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read preference: %w", err)
+	}
+
+	var opts []readpref.Option
+	// max staleness is rejected by the server for primary reads
+	if cfg.MaxStaleness > 0 && mode != readpref.PrimaryMode {
+		opts = append(opts, readpref.WithMaxStaleness(cfg.MaxStaleness))
+	}
+
+	readPref, err := readpref.New(mode, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid read preference: %w", err)
+	}
+	return readPref, nil
+}
+
+func newWriteConcern(cfg config.DatabaseConfig) (*writeconcern.WriteConcern, error) {
+	wc := &writeconcern.WriteConcern{Journal: &cfg.WriteJournal}
+	if cfg.WriteConcern == "majority" {
+		wc.W = "majority"
+		return wc, nil
+	}
+
+	w, err := strconv.Atoi(cfg.WriteConcern)
+	if err != nil || w < 0 {
+		return nil, fmt.Errorf("invalid write concern %q: must be majority or a node count", cfg.WriteConcern)
+	}
+	wc.W = w
+	if w == 0 {
+		// journaling cannot be requested for unacknowledged writes
+		wc.Journal = nil
+	}
+	return wc, nil
+}
+
+func cacheChatModeRepository(repo mongodb.ChatModeRepository, cfg *config.Config) mongodb.ChatModeRepository {
+	if cfg.Cache.RepositoryTTL <= 0 {
+		return repo
+	}
+	return mongodb.NewCachedChatModeRepository(repo, cfg.Cache.RepositoryTTL)
+}
+
+func cacheUserAttributeRepository(repo mongodb.UserAttributeRepository, cfg *config.Config) mongodb.UserAttributeRepository {
+	if cfg.Cache.RepositoryTTL <= 0 {
+		return repo
+	}
+	return mongodb.NewCachedUserAttributeRepository(repo, cfg.Cache.RepositoryTTL)
+}
+
+// decorateChatAPIClient holds the bot's chat-api messages when chat-api is in
+// test mode, and sends templates outside its reply window when it has one.
+// Test mode wraps the client first, so held messages are the templates that
+// would have been sent.
+func decorateChatAPIClient(
+	client chatapi.Client,
+	testMessageRepo mongodb.TestMessageRepository,
+	cursorRepo mongodb.ChannelCursorRepository,
+	templateRepo mongodb.MessageTemplateRepository,
+	cfg *config.Config,
+) chatapi.Client {
+	if cfg.TestMode.Applies(models.PartnerChatAPI) {
+		client = chatapi.NewTestModeClient(client, testMessageRepo, cfg.TestMode.Retention, cfg.ChatAPI.MaxMessageChars, cfg.ChatAPI.BlockTypes)
+	}
+	if cfg.ReplyWindow.Applies(models.PartnerChatAPI) {
+		client = chatapi.NewReplyWindowClient(client, cursorRepo, templateRepo, cfg.ReplyWindow.Window, cfg.ReplyWindow.Mode == "block")
+	}
+	return client
+}
+
+// newOutcomeRecorder hands the outcome usecase to the SetOutcome tool, which
+// cannot import the usecase package
+func newOutcomeRecorder(uc usecase.OutcomeUsecase) set_outcome.Recorder {
+	return uc
+}
@@ -0,0 +1,143 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/googleai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"go.uber.org/fx"
+)
+
+// selfCheckResult is one line of the startup self-check report
+type selfCheckResult struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+const (
+	selfCheckPassed  = "passed"
+	selfCheckFailed  = "failed"
+	selfCheckSkipped = "skipped"
+)
+
+// errSelfCheckSkipped marks a check that does not apply to this deployment
+type errSelfCheckSkipped string
+
+func (e errSelfCheckSkipped) Error() string { return string(e) }
+
+// SelfCheck verifies MongoDB, its indexes, chat-api reachability, the Google
+// AI key and the chat modes once the indexes are ensured, logging the report.
+// Failures stop startup unless SELF_CHECK_STRICT is off.
+func SelfCheck(lc fx.Lifecycle, db *mongodb.DB, googleAIClient googleai.Client, chatModeChecker usecase.ChatModeChecker, conf *config.Config) {
+	if !conf.SelfCheck.Enabled {
+		return
+	}
+
+	checks := []struct {
+		name string
+		run  func(ctx context.Context) error
+	}{
+		{"mongodb", func(ctx context.Context) error {
+			return db.Client.Ping(ctx, nil)
+		}},
+		{"mongodb_indexes", func(ctx context.Context) error {
+			missing, err := mongodb.MissingIndexes(ctx, db)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("no indexes on %s", strings.Join(missing, ", "))
+			}
+			return nil
+		}},
+		{"chat_api", func(ctx context.Context) error {
+			if conf.TestMode.Applies(models.PartnerChatAPI) {
+				return errSelfCheckSkipped("chat-api is in test mode")
+			}
+			return checkReachable(ctx, conf.ChatAPI.BaseURL)
+		}},
+		{"google_ai_key", func(ctx context.Context) error {
+			if conf.LLM.Fake {
+				return errSelfCheckSkipped("the fake LLM is enabled")
+			}
+			if conf.LLM.GoogleAIAPIKey == "" {
+				return errSelfCheckSkipped("no deployment key, tenant keys are validated when set")
+			}
+			return googleAIClient.ValidateAPIKey(ctx, conf.LLM.GoogleAIAPIKey)
+		}},
+		{"chat_modes", func(ctx context.Context) error {
+			unhealthy, err := chatModeChecker.Check(ctx)
+			if err != nil {
+				return err
+			}
+			if len(unhealthy) > 0 {
+				names := slices.Sorted(maps.Keys(unhealthy))
+				return fmt.Errorf("unhealthy chat modes %s", strings.Join(names, ", "))
+			}
+			return nil
+		}},
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			report := make([]selfCheckResult, 0, len(checks))
+			var failed []string
+			for _, check := range checks {
+				checkCtx, cancel := context.WithTimeout(ctx, conf.SelfCheck.Timeout)
+				start := time.Now()
+				err := check.run(checkCtx)
+				cancel()
+
+				result := selfCheckResult{Name: check.name, Status: selfCheckPassed, Duration: time.Since(start)}
+				var skipped errSelfCheckSkipped
+				if errors.As(err, &skipped) {
+					result.Status = selfCheckSkipped
+					result.Detail = string(skipped)
+				} else if err != nil {
+					result.Status = selfCheckFailed
+					result.Detail = err.Error()
+					failed = append(failed, check.name)
+				}
+				report = append(report, result)
+			}
+
+			if len(failed) == 0 {
+				log.Infow(ctx, "Startup self-check passed", "report", report)
+				return nil
+			}
+			log.Errorw(ctx, "Startup self-check failed", "report", report, "failed", failed)
+			if !conf.SelfCheck.Strict {
+				return nil
+			}
+			return fmt.Errorf("startup self-check failed: %s", strings.Join(failed, ", "))
+		},
+	})
+}
+
+// checkReachable succeeds on any HTTP response from url, since only the
+// connection matters and the base URL itself may not be routed
+func checkReachable(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unreachable: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
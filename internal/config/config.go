@@ -1,19 +1,98 @@
 package config
 
 import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
 	"github.com/caarlos0/env/v11"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/httpx"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/secrets"
 )
 
 type Config struct {
-	Server   ServerConfig   `envPrefix:"SERVER_"`
-	Database DatabaseConfig `envPrefix:"DATABASE_"`
-	ChatAPI  ChatAPIConfig  `envPrefix:"CHAT_API_"`
-	LLM      LLMConfig      `envPrefix:"LLM_"`
-	Kafka    KafkaConfig    `envPrefix:"KAFKA_"`
+	Server      ServerConfig      `envPrefix:"SERVER_"`
+	Database    DatabaseConfig    `envPrefix:"DATABASE_"`
+	ChatAPI     ChatAPIConfig     `envPrefix:"CHAT_API_"`
+	LLM         LLMConfig         `envPrefix:"LLM_"`
+	LLMFallback LLMFallbackConfig `envPrefix:"LLM_FALLBACK_"`
+	Kafka       KafkaConfig       `envPrefix:"KAFKA_"`
+	Reservation ReservationConfig `envPrefix:"RESERVATION_"`
+	Tenant      TenantConfig      `envPrefix:"TENANT_"`
+	Storage     StorageConfig     `envPrefix:"STORAGE_"`
+	Cache       CacheConfig       `envPrefix:"CACHE_"`
+	Timeouts    TimeoutConfig     `envPrefix:"TIMEOUT_"`
+	Transcript  TranscriptConfig  `envPrefix:"TRANSCRIPT_"`
+	PromptLog   PromptLogConfig   `envPrefix:"PROMPT_LOG_"`
+	Onboarding  OnboardingConfig  `envPrefix:"ONBOARDING_"`
+	ChototLink  ChototLinkConfig  `envPrefix:"CHOTOT_LINK_"`
+	Reconcile   ReconcileConfig   `envPrefix:"RECONCILE_"`
+	ChatMode    ChatModeConfig    `envPrefix:"CHAT_MODE_"`
+	SafeMode    SafeModeConfig    `envPrefix:"SAFE_MODE_"`
+	Sentiment   SentimentConfig   `envPrefix:"SENTIMENT_"`
+	Scam        ScamConfig        `envPrefix:"SCAM_"`
+	Budget      BudgetConfig      `envPrefix:"BUDGET_"`
+	Outcome     OutcomeConfig     `envPrefix:"OUTCOME_"`
+	// PartnerHTTP is the retry, rate limit and circuit breaker policy of partner clients
+	PartnerHTTP httpx.Config `envPrefix:"PARTNER_HTTP_"`
+	// ChannelContext bounds the channel metadata context put in prompts
+	ChannelContext ChannelContextConfig `envPrefix:"CHANNEL_CONTEXT_"`
+	// Job runs the background jobs, such as admin bulk operations
+	Job JobConfig `envPrefix:"JOB_"`
+	// TestMode keeps staging environments from messaging real users
+	TestMode TestModeConfig `envPrefix:"TEST_MODE_"`
+	// SelfCheck verifies dependencies on startup instead of at the first request
+	SelfCheck SelfCheckConfig `envPrefix:"SELF_CHECK_"`
+	// Secrets are the stores secret settings can reference, e.g.
+	// LLM_GOOGLE_AI_API_KEY=vault:secret/data/chat-bot#google_ai_key
+	Secrets SecretsConfig `envPrefix:"SECRETS_"`
+	// ActivityFeed keeps the noteworthy events shown to sellers
+	ActivityFeed ActivityFeedConfig `envPrefix:"ACTIVITY_FEED_"`
+	// SessionTimeout ends sessions past their chat mode's inactivity timeout
+	SessionTimeout SessionTimeoutConfig `envPrefix:"SESSION_TIMEOUT_"`
+	// ChannelClaim releases the rooms of agents who stopped typing
+	ChannelClaim ChannelClaimConfig `envPrefix:"CHANNEL_CLAIM_"`
+	// ReplyWindow restricts partners that only accept templates once the
+	// buyer has been quiet for a while
+	ReplyWindow ReplyWindowConfig `envPrefix:"REPLY_WINDOW_"`
+	// SystemMessages localizes the canned messages the bot sends to chats
+	SystemMessages SystemMessagesConfig `envPrefix:"SYSTEM_MESSAGES_"`
+	// SessionTrace records a summary of every agent loop iteration
+	SessionTrace SessionTraceConfig `envPrefix:"SESSION_TRACE_"`
+	// Whitelist controls how often stored whitelist changes are picked up
+	Whitelist WhitelistConfig `envPrefix:"WHITELIST_"`
+	// Mailer sends emails to users
+	Mailer MailerConfig `envPrefix:"MAILER_"`
+	// EmailVerification proves users own their email address
+	EmailVerification EmailVerificationConfig `envPrefix:"EMAIL_VERIFICATION_"`
+	// AuthGuard locks out addresses and accounts that keep failing to authenticate
+	AuthGuard AuthGuardConfig `envPrefix:"AUTH_GUARD_"`
+	// Cost prices LLM tokens to estimate what tenants, sellers and chat modes spend
+	Cost CostConfig `envPrefix:"COST_"`
+	// Privacy keeps private rooms and messages away from the LLM
+	Privacy PrivacyConfig `envPrefix:"PRIVACY_"`
+
+	secrets    *secrets.Resolver
+	secretRefs map[string]string
 }
 
 type ServerConfig struct {
 	Addr string `env:"ADDR" envDefault:"localhost:8080"`
+	// BodyLimit bounds request bodies in bytes on routes without their own limit
+	BodyLimit int64 `env:"BODY_LIMIT" envDefault:"1048576"`
+	// MessageBodyLimit bounds the bodies of incoming messages
+	MessageBodyLimit int64 `env:"MESSAGE_BODY_LIMIT" envDefault:"65536"`
+	// UploadBodyLimit bounds tenant restore archives and avatar uploads
+	UploadBodyLimit int64 `env:"UPLOAD_BODY_LIMIT" envDefault:"67108864"`
+	// DebugStats lets admins request per-request query and LLM stats with the
+	// X-Debug-Stats header
+	DebugStats bool `env:"DEBUG_STATS" envDefault:"false"`
+	// TrustedProxies are the CIDR ranges of the proxies in front of the
+	// server. Client addresses are only read from X-Forwarded-For when the
+	// request came through one of them, otherwise the peer address is used.
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
 }
 
 type DatabaseConfig struct {
@@ -23,6 +102,25 @@ type DatabaseConfig struct {
 	Database string   `env:"DATABASE" envDefault:"chat-bot"`
 	AuthDB   string   `env:"AUTH_DB" envDefault:"admin"`
 	Direct   bool     `env:"DIRECT" envDefault:"true"`
+	// ReadPreference is one of primary, primaryPreferred, secondary, secondaryPreferred or nearest
+	ReadPreference string `env:"READ_PREFERENCE" envDefault:"primary"`
+	// MaxStaleness bounds replication lag for non-primary reads; 0 leaves it unbounded
+	MaxStaleness time.Duration `env:"MAX_STALENESS" envDefault:"0"`
+	// WriteConcern is "majority" or a number of acknowledging nodes
+	WriteConcern string `env:"WRITE_CONCERN" envDefault:"majority"`
+	WriteJournal bool   `env:"WRITE_JOURNAL" envDefault:"true"`
+	RetryWrites  bool   `env:"RETRY_WRITES" envDefault:"true"`
+	RetryReads   bool   `env:"RETRY_READS" envDefault:"true"`
+	// Timeout applies to every operation that has no deadline of its own
+	Timeout                time.Duration `env:"TIMEOUT" envDefault:"10s"`
+	ConnectTimeout         time.Duration `env:"CONNECT_TIMEOUT" envDefault:"10s"`
+	ServerSelectionTimeout time.Duration `env:"SERVER_SELECTION_TIMEOUT" envDefault:"10s"`
+	MaxPoolSize            uint64        `env:"MAX_POOL_SIZE" envDefault:"100"`
+	// SlowQueryThreshold logs commands slower than this; 0 disables the log
+	SlowQueryThreshold time.Duration `env:"SLOW_QUERY_THRESHOLD" envDefault:"500ms"`
+	// SchemaValidation is "error" to reject documents failing the collection
+	// validators, "warn" to only log them on the server, or "off"
+	SchemaValidation string `env:"SCHEMA_VALIDATION" envDefault:"warn"`
 }
 
 type ChatAPIConfig struct {
@@ -30,12 +128,40 @@ type ChatAPIConfig struct {
 	ProjectID string `env:"PROJECT_ID,required" envDefault:"16f38160-3afa-4707-b8cb-354d2cbf1590"`
 	APIKey    string `env:"API_KEY,required"`
 	Service   string `env:"SERVICE" envDefault:"chat-bot"`
+	// MaxMessageChars bounds the messages the bot sends, longer ones are cut with an ellipsis
+	MaxMessageChars int `env:"MAX_MESSAGE_CHARS" envDefault:"2000"`
+	// BlockTypes lists the message blocks chat-api clients render, others are
+	// downgraded to text. Empty keeps every block as text.
+	BlockTypes []string `env:"BLOCK_TYPES" envDefault:""`
 }
 
 type LLMConfig struct {
 	OpenAIAPIKey    string `env:"OPENAI_API_KEY"`
 	AnthropicAPIKey string `env:"ANTHROPIC_API_KEY"`
 	GoogleAIAPIKey  string `env:"GOOGLE_AI_API_KEY"`
+	// KeyEncryptionKey is a base64 AES key used to encrypt per-tenant provider keys at rest
+	KeyEncryptionKey string `env:"KEY_ENCRYPTION_KEY"`
+	// Fake answers every session generation after FakeLatency without calling
+	// a provider, for load tests against a local instance
+	Fake        bool          `env:"FAKE" envDefault:"false"`
+	FakeLatency time.Duration `env:"FAKE_LATENCY" envDefault:"800ms"`
+}
+
+// LLMFallbackConfig is what the bot does when the model of a chat mode fails:
+// retry it, then try Model, then acknowledge the buyer
+type LLMFallbackConfig struct {
+	Retries     int           `env:"RETRIES" envDefault:"2"`
+	BaseBackoff time.Duration `env:"BASE_BACKOFF" envDefault:"500ms"`
+	MaxBackoff  time.Duration `env:"MAX_BACKOFF" envDefault:"4s"`
+	// Model is tried once the chat mode's model failed every retry, empty
+	// skips it. It must be served by a registered plugin.
+	Model string `env:"MODEL"`
+	// Acknowledge sends the acknowledge system message when no model could
+	// answer. AcknowledgeMessage replaces its text in every locale.
+	Acknowledge        bool   `env:"ACKNOWLEDGE" envDefault:"true"`
+	AcknowledgeMessage string `env:"ACKNOWLEDGE_MESSAGE"`
+	// OutageRetention is how long outage events are kept
+	OutageRetention time.Duration `env:"OUTAGE_RETENTION" envDefault:"720h"`
 }
 
 type KafkaConfig struct {
@@ -44,6 +170,390 @@ type KafkaConfig struct {
 	Topic     string   `env:"TOPIC" envDefault:"chat.event.messages"`
 	GroupID   string   `env:"GROUP_ID" envDefault:"chat-bot-consumers"`
 	Whitelist []string `env:"SELLER_WHITELIST" envDefault:"11198316,11356173,11296497,all"`
+	// MaxAttempts is how many times a message is processed before it is
+	// dead-lettered; only unexpected errors are retried
+	MaxAttempts  int           `env:"MAX_ATTEMPTS" envDefault:"3"`
+	RetryBackoff time.Duration `env:"RETRY_BACKOFF" envDefault:"2s"`
+	// DeadLetterRetention is how long dead letters are kept
+	DeadLetterRetention time.Duration `env:"DEAD_LETTER_RETENTION" envDefault:"720h"`
+}
+
+type ReservationConfig struct {
+	DefaultTTL time.Duration `env:"DEFAULT_TTL" envDefault:"30m"`
+	MaxTTL     time.Duration `env:"MAX_TTL" envDefault:"24h"`
+}
+
+type TenantConfig struct {
+	// Required rejects API requests without an X-API-Key. Without it those
+	// requests only see data that belongs to no tenant.
+	Required bool `env:"REQUIRED" envDefault:"false"`
+	// AdminAPIKey guards the tenant admin endpoints, which are disabled when empty
+	AdminAPIKey string `env:"ADMIN_API_KEY"`
+}
+
+type StorageConfig struct {
+	// Provider is either "local" or "s3"
+	Provider string `env:"PROVIDER" envDefault:"local"`
+	// LocalDir is where the local provider writes objects, served under /media
+	LocalDir string `env:"LOCAL_DIR" envDefault:"./data/media"`
+	// PublicBaseURL prefixes object keys in returned URLs; for s3 it defaults to endpoint/bucket
+	PublicBaseURL     string `env:"PUBLIC_BASE_URL" envDefault:"/media"`
+	S3Endpoint        string `env:"S3_ENDPOINT"`
+	S3Region          string `env:"S3_REGION" envDefault:"us-east-1"`
+	S3Bucket          string `env:"S3_BUCKET"`
+	S3AccessKeyID     string `env:"S3_ACCESS_KEY_ID"`
+	S3SecretAccessKey string `env:"S3_SECRET_ACCESS_KEY"`
+}
+
+type CacheConfig struct {
+	// RepositoryTTL is how long chat modes and user attributes are cached in
+	// memory, bounding staleness across instances; 0 disables the cache
+	RepositoryTTL time.Duration `env:"REPOSITORY_TTL" envDefault:"1m"`
+}
+
+type TranscriptConfig struct {
+	// Enabled stores the model conversation of every session for QA review
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// Retention is how long transcripts are kept before MongoDB removes them
+	Retention time.Duration `env:"RETENTION" envDefault:"720h"`
+}
+
+type PromptLogConfig struct {
+	// Enabled captures the rendered prompts and model responses of sampled sessions
+	Enabled bool `env:"ENABLED" envDefault:"false"`
+	// SampleRate is the fraction of sessions captured, from 0 to 1
+	SampleRate float64 `env:"SAMPLE_RATE" envDefault:"0.1"`
+	// Retention is how long records are kept before MongoDB removes them
+	Retention time.Duration `env:"RETENTION" envDefault:"168h"`
+}
+
+func (c PromptLogConfig) Validate() error {
+	if c.SampleRate < 0 || c.SampleRate > 1 {
+		return fmt.Errorf("PROMPT_LOG_SAMPLE_RATE must be between 0 and 1, got %v", c.SampleRate)
+	}
+	if c.Retention <= 0 {
+		return fmt.Errorf("PROMPT_LOG_RETENTION must be positive, got %s", c.Retention)
+	}
+	return nil
+}
+
+type OnboardingConfig struct {
+	// SandboxChannelID is the chat-api channel test conversations are posted
+	// to; when empty they are only generated
+	SandboxChannelID string `env:"SANDBOX_CHANNEL_ID"`
+	// SandboxBuyerID is the chat-api user posting the sample buyer message
+	SandboxBuyerID string `env:"SANDBOX_BUYER_ID" envDefault:"onboarding-buyer"`
+	TestMessage    string `env:"TEST_MESSAGE" envDefault:"Hi, is this item still available?"`
+}
+
+type ChototLinkConfig struct {
+	// CodeTTL is how long a linking challenge code can be sent before it expires
+	CodeTTL time.Duration `env:"CODE_TTL" envDefault:"15m"`
+}
+
+type ReconcileConfig struct {
+	// Interval between runs of the missed message reconciler; 0 disables it
+	Interval time.Duration `env:"INTERVAL" envDefault:"5m"`
+	// Lookback limits reconciliation to channels with a message in this window
+	Lookback time.Duration `env:"LOOKBACK" envDefault:"24h"`
+	// MinAge leaves messages younger than this to the Kafka consumer
+	MinAge time.Duration `env:"MIN_AGE" envDefault:"2m"`
+}
+
+func (c ReconcileConfig) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("RECONCILE_INTERVAL must not be negative, got %s", c.Interval)
+	}
+	if c.MinAge < 0 || c.MinAge >= c.Lookback {
+		return fmt.Errorf("RECONCILE_MIN_AGE (%s) must be between 0 and RECONCILE_LOOKBACK (%s)", c.MinAge, c.Lookback)
+	}
+	return nil
+}
+
+type ChatModeConfig struct {
+	// Default answers chats no channel override, seller choice or tenant setting picks a mode for
+	Default string `env:"DEFAULT" envDefault:"sales_assistant"`
+}
+
+type SafeModeConfig struct {
+	// Sellers and Channels have their bot replies held for the seller's
+	// approval in every tenant, on top of each tenant's safe mode settings
+	Sellers  []string `env:"SELLERS"`
+	Channels []string `env:"CHANNELS"`
+}
+
+type SentimentConfig struct {
+	// AlertThreshold alerts on a channel once its moving sentiment score, from
+	// -1 to 1, drops to it
+	AlertThreshold float64 `env:"ALERT_THRESHOLD" envDefault:"-0.5"`
+	// AlertMinMessages is the number of buyer messages scored before alerting
+	AlertMinMessages int `env:"ALERT_MIN_MESSAGES" envDefault:"3"`
+	// HandOff stops the bot in alerted channels until the seller resumes it
+	HandOff bool `env:"HAND_OFF" envDefault:"true"`
+	// TrendSize is the number of latest message scores kept per channel
+	TrendSize int `env:"TREND_SIZE" envDefault:"20"`
+}
+
+func (c SentimentConfig) Validate() error {
+	if c.AlertThreshold < -1 || c.AlertThreshold > 1 {
+		return fmt.Errorf("SENTIMENT_ALERT_THRESHOLD must be between -1 and 1, got %v", c.AlertThreshold)
+	}
+	if c.TrendSize <= 0 {
+		return fmt.Errorf("SENTIMENT_TREND_SIZE must be positive, got %d", c.TrendSize)
+	}
+	return nil
+}
+
+type ScamConfig struct {
+	// Notify posts the scam_notice system message to a channel the first time
+	// one of its buyer messages is flagged as a likely scam. Notice replaces
+	// its text in every locale.
+	Notify bool   `env:"NOTIFY" envDefault:"true"`
+	Notice string `env:"NOTICE"`
+}
+
+type BudgetConfig struct {
+	// MaxRepliesPerDay caps the bot replies sent in a channel per day, 0 disables it
+	MaxRepliesPerDay int `env:"MAX_REPLIES_PER_DAY" envDefault:"30"`
+	// MaxTokensPerSession caps the LLM tokens of a session, 0 disables it
+	MaxTokensPerSession int `env:"MAX_TOKENS_PER_SESSION" envDefault:"50000"`
+	// Notify posts the budget_paused system message to the channel the first
+	// time the bot stops in it on a day. Notice replaces its text in every
+	// locale.
+	Notify bool   `env:"NOTIFY" envDefault:"true"`
+	Notice string `env:"NOTICE"`
+	// Retention is how long daily channel counters are kept
+	Retention time.Duration `env:"RETENTION" envDefault:"720h"`
+}
+
+type OutcomeConfig struct {
+	// Interval between runs of the no_response resolver; 0 disables it
+	Interval time.Duration `env:"INTERVAL" envDefault:"1h"`
+	// NoResponseAfter is how long a channel stays quiet before its latest
+	// session is resolved as no_response
+	NoResponseAfter time.Duration `env:"NO_RESPONSE_AFTER" envDefault:"72h"`
+	// Lookback limits the resolver to channels with a session in this window
+	Lookback time.Duration `env:"LOOKBACK" envDefault:"720h"`
+}
+
+func (c OutcomeConfig) Validate() error {
+	if c.Interval < 0 {
+		return fmt.Errorf("OUTCOME_INTERVAL must not be negative, got %s", c.Interval)
+	}
+	if c.NoResponseAfter <= 0 || c.NoResponseAfter >= c.Lookback {
+		return fmt.Errorf("OUTCOME_NO_RESPONSE_AFTER (%s) must be between 0 and OUTCOME_LOOKBACK (%s)", c.NoResponseAfter, c.Lookback)
+	}
+	return nil
+}
+
+type ChannelContextConfig struct {
+	// MaxChars is the longest channel context put in prompts as is. Longer
+	// ones are summarized to at most MaxChars; 0 disables it.
+	MaxChars int `env:"MAX_CHARS" envDefault:"2000"`
+}
+
+type JobConfig struct {
+	// PollInterval between checks for pending jobs; 0 disables the worker
+	PollInterval time.Duration `env:"POLL_INTERVAL" envDefault:"5s"`
+	// HeartbeatInterval between progress updates of a running job, which
+	// also pick up cancellations
+	HeartbeatInterval time.Duration `env:"HEARTBEAT_INTERVAL" envDefault:"10s"`
+	// StaleAfter is how long a running job goes without heartbeat before
+	// another worker picks it up
+	StaleAfter time.Duration `env:"STALE_AFTER" envDefault:"1m"`
+}
+
+func (c JobConfig) Validate() error {
+	if c.PollInterval < 0 {
+		return fmt.Errorf("JOB_POLL_INTERVAL must not be negative, got %s", c.PollInterval)
+	}
+	if c.HeartbeatInterval <= 0 || c.HeartbeatInterval >= c.StaleAfter {
+		return fmt.Errorf("JOB_HEARTBEAT_INTERVAL (%s) must be between 0 and JOB_STALE_AFTER (%s)", c.HeartbeatInterval, c.StaleAfter)
+	}
+	return nil
+}
+
+type TestModeConfig struct {
+	// Enabled puts every partner in test mode
+	Enabled bool `env:"ENABLED" envDefault:"false"`
+	// Partners in test mode, e.g. "chat-api"
+	Partners []string `env:"PARTNERS"`
+	// Retention is how long the messages held in test mode are kept
+	Retention time.Duration `env:"RETENTION" envDefault:"168h"`
+}
+
+// Applies reports whether messages to the partner are held instead of sent
+func (c TestModeConfig) Applies(partner string) bool {
+	return c.Enabled || slices.Contains(c.Partners, partner)
+}
+
+type SelfCheckConfig struct {
+	// Enabled checks MongoDB indexes, partner reachability and the LLM key on startup
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// Strict fails startup when a check fails instead of only logging the report
+	Strict bool `env:"STRICT" envDefault:"true"`
+	// Timeout bounds each check
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"5s"`
+}
+
+type ActivityFeedConfig struct {
+	// Retention is how long feed items are kept, seen or not
+	Retention time.Duration `env:"RETENTION" envDefault:"720h"`
+}
+
+type SessionTimeoutConfig struct {
+	// Interval between sweeps of inactive sessions; 0 disables them
+	Interval time.Duration `env:"INTERVAL" envDefault:"1m"`
+	// BatchSize bounds the sessions of a chat mode ended per sweep
+	BatchSize int `env:"BATCH_SIZE" envDefault:"200"`
+}
+
+type ChannelClaimConfig struct {
+	// Inactivity after which an agent's claim on a room is released
+	Inactivity time.Duration `env:"INACTIVITY" envDefault:"2m"`
+}
+
+type ReplyWindowConfig struct {
+	// Partners whose free-form messages are limited to the window, e.g. "chat-api"
+	Partners []string `env:"PARTNERS"`
+	// Window after the buyer's last message in which free-form messages are
+	// allowed; later messages are sent as approved templates
+	Window time.Duration `env:"WINDOW" envDefault:"24h"`
+	// Mode is what happens to messages outside the window: "template" sends
+	// an approved template instead, "block" drops them
+	Mode string `env:"MODE" envDefault:"template"`
+}
+
+// Applies reports whether messages to the partner are limited to the window
+func (c ReplyWindowConfig) Applies(partner string) bool {
+	return slices.Contains(c.Partners, partner)
+}
+
+type SystemMessagesConfig struct {
+	// DefaultLocale is used when the buyer's language is unknown or has no
+	// bundle
+	DefaultLocale string `env:"DEFAULT_LOCALE" envDefault:"en"`
+}
+
+type SessionTraceConfig struct {
+	Enabled bool `env:"ENABLED" envDefault:"true"`
+	// MaxPerSession bounds the traces kept for one session, later iterations
+	// are not recorded
+	MaxPerSession int `env:"MAX_PER_SESSION" envDefault:"50"`
+	// Retention is how long traces are kept before MongoDB removes them
+	Retention time.Duration `env:"RETENTION" envDefault:"720h"`
+}
+
+type WhitelistConfig struct {
+	// RefreshInterval is how long an instance uses the stored whitelist
+	// entries and rollout before reloading them
+	RefreshInterval time.Duration `env:"REFRESH_INTERVAL" envDefault:"30s"`
+}
+
+type MailerConfig struct {
+	// SMTPAddr is the host:port of the SMTP server; emails are only logged
+	// when it is empty
+	SMTPAddr string `env:"SMTP_ADDR"`
+	Username string `env:"USERNAME"`
+	Password string `env:"PASSWORD"`
+	From     string `env:"FROM" envDefault:"chat-bot@localhost"`
+}
+
+type EmailVerificationConfig struct {
+	// Required blocks partner account linking until the user's email is verified
+	Required bool `env:"REQUIRED" envDefault:"true"`
+	// CodeTTL is how long a verification code and link can be used
+	CodeTTL time.Duration `env:"CODE_TTL" envDefault:"30m"`
+	// MaxAttempts is how many wrong codes end a verification
+	MaxAttempts int `env:"MAX_ATTEMPTS" envDefault:"5"`
+	// LinkBaseURL is the public address of this server, used to build
+	// verification links
+	LinkBaseURL string `env:"LINK_BASE_URL" envDefault:"http://localhost:8080"`
+}
+
+type AuthGuardConfig struct {
+	// MaxFailures within Window lock an address or account out
+	MaxFailures int           `env:"MAX_FAILURES" envDefault:"10"`
+	Window      time.Duration `env:"WINDOW" envDefault:"15m"`
+	Lockout     time.Duration `env:"LOCKOUT" envDefault:"30m"`
+	// CaptchaAfter is the number of failures after which an account must
+	// answer a captcha, 0 disables captchas
+	CaptchaAfter int `env:"CAPTCHA_AFTER" envDefault:"0"`
+}
+
+type CostConfig struct {
+	// InputPrices and OutputPrices are the USD prices per million input and
+	// output tokens by model, e.g. "gpt-4o-mini:0.15,gemini-2.0-flash:0.10".
+	// Models without a price are counted at no cost.
+	InputPrices  map[string]float64 `env:"INPUT_PRICES"`
+	OutputPrices map[string]float64 `env:"OUTPUT_PRICES"`
+}
+
+// Estimate returns the USD cost of the tokens of model
+func (c CostConfig) Estimate(model string, inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*c.InputPrices[model] + float64(outputTokens)*c.OutputPrices[model]) / 1e6
+}
+
+type PrivacyConfig struct {
+	// DetectSensitive treats messages holding card or national ID numbers as
+	// private without anyone marking them
+	DetectSensitive bool `env:"DETECT_SENSITIVE" envDefault:"true"`
+}
+
+type TimeoutConfig struct {
+	// MessageProcessing bounds the handling of one incoming message, LLM turns and tool calls included
+	MessageProcessing time.Duration `env:"MESSAGE_PROCESSING" envDefault:"30s"`
+	// LLMGenerate bounds a single model generation
+	LLMGenerate time.Duration `env:"LLM_GENERATE" envDefault:"20s"`
+	// Tool bounds a single tool call unless overridden in ToolOverrides
+	Tool time.Duration `env:"TOOL" envDefault:"20s"`
+	// ToolOverrides sets per-tool timeouts, e.g. "ReplyMessage:10s,FetchMessages:5s"
+	ToolOverrides map[string]time.Duration `env:"TOOL_OVERRIDES"`
+	// Startup bounds each startup task such as connecting to MongoDB and seeding defaults
+	Startup time.Duration `env:"STARTUP" envDefault:"30s"`
+}
+
+// ToolTimeout returns the timeout of the named tool
+func (c TimeoutConfig) ToolTimeout(name string) time.Duration {
+	if d, ok := c.ToolOverrides[name]; ok {
+		return d
+	}
+	return c.Tool
+}
+
+// Validate rejects non-positive timeouts and steps that cannot finish within
+// the message processing budget they run under
+func (c TimeoutConfig) Validate() error {
+	if c.MessageProcessing <= 0 {
+		return fmt.Errorf("TIMEOUT_MESSAGE_PROCESSING must be positive, got %s", c.MessageProcessing)
+	}
+	if c.Startup <= 0 {
+		return fmt.Errorf("TIMEOUT_STARTUP must be positive, got %s", c.Startup)
+	}
+
+	steps := map[string]time.Duration{
+		"TIMEOUT_LLM_GENERATE": c.LLMGenerate,
+		"TIMEOUT_TOOL":         c.Tool,
+	}
+	for name, d := range c.ToolOverrides {
+		steps["TIMEOUT_TOOL_OVERRIDES "+name] = d
+	}
+	names := make([]string, 0, len(steps))
+	for name := range steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		d := steps[name]
+		if d <= 0 {
+			return fmt.Errorf("%s must be positive, got %s", name, d)
+		}
+		if d > c.MessageProcessing {
+			return fmt.Errorf("%s (%s) exceeds TIMEOUT_MESSAGE_PROCESSING (%s)", name, d, c.MessageProcessing)
+		}
+	}
+	return nil
 }
 
 func Load() (*Config, error) {
@@ -51,6 +561,12 @@ func Load() (*Config, error) {
 	if err := env.Parse(cfg); err != nil {
 		return nil, err
 	}
+	if err := cfg.loadSecrets(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
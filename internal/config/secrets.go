@@ -0,0 +1,127 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/pkg/secrets"
+)
+
+type SecretsConfig struct {
+	// CacheTTL is how long a fetched secret is reused; a rotated secret is
+	// picked up by SecretValue once its cached value expires
+	CacheTTL time.Duration `env:"CACHE_TTL" envDefault:"5m"`
+	// Timeout bounds each call to a secret store
+	Timeout        time.Duration `env:"TIMEOUT" envDefault:"10s"`
+	VaultAddr      string        `env:"VAULT_ADDR"`
+	VaultToken     string        `env:"VAULT_TOKEN"`
+	VaultNamespace string        `env:"VAULT_NAMESPACE"`
+	// AWS credentials of the ssm and kms references
+	AWSRegion          string `env:"AWS_REGION" envDefault:"us-east-1"`
+	AWSAccessKeyID     string `env:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey string `env:"AWS_SECRET_ACCESS_KEY"`
+	AWSSessionToken    string `env:"AWS_SESSION_TOKEN"`
+	// AWSEndpoint replaces the AWS service endpoints, e.g. for LocalStack
+	AWSEndpoint string `env:"AWS_ENDPOINT"`
+}
+
+// newResolver registers a provider for each secret store that is configured
+func (c SecretsConfig) newResolver() *secrets.Resolver {
+	httpClient := &http.Client{Timeout: c.Timeout}
+	providers := make(map[string]secrets.Provider)
+	if c.VaultAddr != "" {
+		providers[secrets.SchemeVault] = secrets.NewVault(c.VaultAddr, c.VaultToken, c.VaultNamespace, httpClient)
+	}
+	if c.AWSAccessKeyID != "" {
+		aws := secrets.AWSConfig{
+			Region:          c.AWSRegion,
+			AccessKeyID:     c.AWSAccessKeyID,
+			SecretAccessKey: c.AWSSecretAccessKey,
+			SessionToken:    c.AWSSessionToken,
+			Endpoint:        c.AWSEndpoint,
+		}
+		providers[secrets.SchemeSSM] = secrets.NewSSM(aws, httpClient)
+		providers[secrets.SchemeKMS] = secrets.NewKMS(aws, httpClient)
+	}
+	return secrets.NewResolver(c.CacheTTL, providers)
+}
+
+// Secret settings read through SecretValue, by environment variable
+const (
+	SecretGoogleAIAPIKey = "LLM_GOOGLE_AI_API_KEY"
+	SecretAdminAPIKey    = "TENANT_ADMIN_API_KEY"
+)
+
+// secretFields are the settings that may hold a secret reference instead of
+// a value, by environment variable
+func (c *Config) secretFields() map[string]*string {
+	return map[string]*string{
+		"DATABASE_PASSWORD":            &c.Database.Password,
+		"CHAT_API_API_KEY":             &c.ChatAPI.APIKey,
+		"LLM_OPENAI_API_KEY":           &c.LLM.OpenAIAPIKey,
+		"LLM_ANTHROPIC_API_KEY":        &c.LLM.AnthropicAPIKey,
+		SecretGoogleAIAPIKey:           &c.LLM.GoogleAIAPIKey,
+		"LLM_KEY_ENCRYPTION_KEY":       &c.LLM.KeyEncryptionKey,
+		SecretAdminAPIKey:              &c.Tenant.AdminAPIKey,
+		"STORAGE_S3_ACCESS_KEY_ID":     &c.Storage.S3AccessKeyID,
+		"STORAGE_S3_SECRET_ACCESS_KEY": &c.Storage.S3SecretAccessKey,
+		"MAILER_PASSWORD":              &c.Mailer.Password,
+	}
+}
+
+// loadSecrets replaces the secret references of the config with their values,
+// remembering the references for SecretValue. Every reference that cannot be
+// resolved is reported.
+func (c *Config) loadSecrets(ctx context.Context) error {
+	c.secrets = c.Secrets.newResolver()
+	c.secretRefs = make(map[string]string)
+
+	fields := c.secretFields()
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		field := fields[name]
+		if _, _, ok := secrets.ParseReference(*field); !ok {
+			continue
+		}
+		value, err := c.secrets.Resolve(ctx, *field)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", name, err))
+			continue
+		}
+		c.secretRefs[name] = *field
+		*field = value
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// SecretValue returns the current value of a secret setting, by environment
+// variable. Settings loaded from a secret store are fetched again once their
+// cached value expires, so callers reading them on every use pick up
+// rotations. The value loaded at startup is kept when the store fails.
+func (c *Config) SecretValue(ctx context.Context, name string) string {
+	field, ok := c.secretFields()[name]
+	if !ok {
+		return ""
+	}
+	ref, ok := c.secretRefs[name]
+	if !ok || c.secrets == nil {
+		return *field
+	}
+	value, err := c.secrets.Resolve(ctx, ref)
+	if err != nil {
+		return *field
+	}
+	return value
+}
@@ -0,0 +1,237 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// minAdminAPIKeyLength keeps the admin key out of brute force range
+const minAdminAPIKeyLength = 16
+
+// ValidationError lists every problem found in a config, so a bad deploy
+// reports them all at once instead of one per restart
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d config problem(s): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// Validate checks required keys, URL formats, secret lengths and settings
+// that cannot be combined, on top of the section validators. It returns a
+// *ValidationError listing every problem found.
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	sections := []struct {
+		name     string
+		validate func() error
+	}{
+		{"timeout", c.Timeouts.Validate},
+		{"prompt log", c.PromptLog.Validate},
+		{"reconcile", c.Reconcile.Validate},
+		{"sentiment", c.Sentiment.Validate},
+		{"outcome", c.Outcome.Validate},
+		{"job", c.Job.Validate},
+	}
+	for _, section := range sections {
+		if err := section.validate(); err != nil {
+			add("invalid %s config: %s", section.name, err)
+		}
+	}
+
+	if c.Server.Addr == "" {
+		add("SERVER_ADDR is required")
+	}
+	if c.Server.BodyLimit <= 0 || c.Server.MessageBodyLimit <= 0 || c.Server.UploadBodyLimit <= 0 {
+		add("SERVER_BODY_LIMIT, SERVER_MESSAGE_BODY_LIMIT and SERVER_UPLOAD_BODY_LIMIT must be positive")
+	}
+	for _, cidr := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			add("SERVER_TRUSTED_PROXIES has an invalid range %q", cidr)
+		}
+	}
+
+	if len(c.Database.Hosts) == 0 {
+		add("DATABASE_HOSTS is required")
+	}
+	if c.Database.Database == "" {
+		add("DATABASE_DATABASE is required")
+	}
+	switch c.Database.ReadPreference {
+	case "primary":
+		if c.Database.MaxStaleness > 0 {
+			add("DATABASE_MAX_STALENESS cannot be set with the primary read preference")
+		}
+	case "primaryPreferred", "secondary", "secondaryPreferred", "nearest":
+	default:
+		add("DATABASE_READ_PREFERENCE %q is not a read preference", c.Database.ReadPreference)
+	}
+	if c.Database.WriteConcern != "majority" {
+		if n, err := strconv.Atoi(c.Database.WriteConcern); err != nil || n < 0 {
+			add("DATABASE_WRITE_CONCERN must be majority or a number of nodes, got %q", c.Database.WriteConcern)
+		}
+	}
+	switch c.Database.SchemaValidation {
+	case "error", "warn", "off":
+	default:
+		add("DATABASE_SCHEMA_VALIDATION must be error, warn or off, got %q", c.Database.SchemaValidation)
+	}
+
+	if err := validateHTTPURL(c.ChatAPI.BaseURL); err != nil {
+		add("CHAT_API_BASE_URL %s", err)
+	}
+	if c.ChatAPI.MaxMessageChars <= 0 {
+		add("CHAT_API_MAX_MESSAGE_CHARS must be positive, got %d", c.ChatAPI.MaxMessageChars)
+	}
+	for _, blockType := range c.ChatAPI.BlockTypes {
+		if blockType != "product_card" {
+			add("CHAT_API_BLOCK_TYPES must only list product_card, got %q", blockType)
+		}
+	}
+
+	if c.LLM.KeyEncryptionKey != "" {
+		key, err := base64.StdEncoding.DecodeString(c.LLM.KeyEncryptionKey)
+		switch {
+		case err != nil:
+			add("LLM_KEY_ENCRYPTION_KEY must be base64: %s", err)
+		case len(key) != 16 && len(key) != 24 && len(key) != 32:
+			add("LLM_KEY_ENCRYPTION_KEY must decode to 16, 24 or 32 bytes, got %d", len(key))
+		}
+	}
+	if c.LLMFallback.Retries < 0 {
+		add("LLM_FALLBACK_RETRIES must not be negative, got %d", c.LLMFallback.Retries)
+	}
+	if c.LLMFallback.MaxBackoff < c.LLMFallback.BaseBackoff {
+		add("LLM_FALLBACK_MAX_BACKOFF (%s) is below LLM_FALLBACK_BASE_BACKOFF (%s)", c.LLMFallback.MaxBackoff, c.LLMFallback.BaseBackoff)
+	}
+
+	if c.Kafka.Enabled {
+		if len(c.Kafka.Brokers) == 0 {
+			add("KAFKA_BROKERS is required when KAFKA_ENABLED is set")
+		}
+		if c.Kafka.Topic == "" {
+			add("KAFKA_TOPIC is required when KAFKA_ENABLED is set")
+		}
+		if c.Kafka.GroupID == "" {
+			add("KAFKA_GROUP_ID is required when KAFKA_ENABLED is set")
+		}
+	}
+
+	if c.Reservation.DefaultTTL <= 0 || c.Reservation.DefaultTTL > c.Reservation.MaxTTL {
+		add("RESERVATION_DEFAULT_TTL (%s) must be between 0 and RESERVATION_MAX_TTL (%s)", c.Reservation.DefaultTTL, c.Reservation.MaxTTL)
+	}
+
+	if key := c.Tenant.AdminAPIKey; key != "" && len(key) < minAdminAPIKeyLength {
+		add("TENANT_ADMIN_API_KEY must be at least %d characters", minAdminAPIKeyLength)
+	}
+
+	switch c.Storage.Provider {
+	case "local":
+		if c.Storage.LocalDir == "" {
+			add("STORAGE_LOCAL_DIR is required for the local provider")
+		}
+	case "s3":
+		if err := validateHTTPURL(c.Storage.S3Endpoint); err != nil {
+			add("STORAGE_S3_ENDPOINT %s", err)
+		}
+		if c.Storage.S3Bucket == "" {
+			add("STORAGE_S3_BUCKET is required for the s3 provider")
+		}
+		if c.Storage.S3AccessKeyID == "" || c.Storage.S3SecretAccessKey == "" {
+			add("STORAGE_S3_ACCESS_KEY_ID and STORAGE_S3_SECRET_ACCESS_KEY are required for the s3 provider")
+		}
+	default:
+		add("STORAGE_PROVIDER must be local or s3, got %q", c.Storage.Provider)
+	}
+
+	if c.SelfCheck.Timeout <= 0 {
+		add("SELF_CHECK_TIMEOUT must be positive, got %s", c.SelfCheck.Timeout)
+	}
+	if c.ActivityFeed.Retention <= 0 {
+		add("ACTIVITY_FEED_RETENTION must be positive, got %s", c.ActivityFeed.Retention)
+	}
+	if c.SessionTimeout.Interval < 0 {
+		add("SESSION_TIMEOUT_INTERVAL must not be negative, got %s", c.SessionTimeout.Interval)
+	}
+	if c.SessionTimeout.BatchSize <= 0 {
+		add("SESSION_TIMEOUT_BATCH_SIZE must be positive, got %d", c.SessionTimeout.BatchSize)
+	}
+	if c.ChannelClaim.Inactivity <= 0 {
+		add("CHANNEL_CLAIM_INACTIVITY must be positive, got %s", c.ChannelClaim.Inactivity)
+	}
+	if c.ReplyWindow.Window <= 0 {
+		add("REPLY_WINDOW_WINDOW must be positive, got %s", c.ReplyWindow.Window)
+	}
+	if c.ReplyWindow.Mode != "template" && c.ReplyWindow.Mode != "block" {
+		add("REPLY_WINDOW_MODE must be template or block, got %q", c.ReplyWindow.Mode)
+	}
+	if c.SessionTrace.MaxPerSession <= 0 {
+		add("SESSION_TRACE_MAX_PER_SESSION must be positive, got %d", c.SessionTrace.MaxPerSession)
+	}
+	if c.SessionTrace.Retention <= 0 {
+		add("SESSION_TRACE_RETENTION must be positive, got %s", c.SessionTrace.Retention)
+	}
+	if c.Whitelist.RefreshInterval <= 0 {
+		add("WHITELIST_REFRESH_INTERVAL must be positive, got %s", c.Whitelist.RefreshInterval)
+	}
+	if c.EmailVerification.CodeTTL <= 0 || c.EmailVerification.MaxAttempts <= 0 {
+		add("EMAIL_VERIFICATION_CODE_TTL and EMAIL_VERIFICATION_MAX_ATTEMPTS must be positive")
+	}
+	if err := validateHTTPURL(c.EmailVerification.LinkBaseURL); err != nil {
+		add("EMAIL_VERIFICATION_LINK_BASE_URL %s", err)
+	}
+	if c.AuthGuard.MaxFailures <= 0 || c.AuthGuard.Window <= 0 || c.AuthGuard.Lockout <= 0 {
+		add("AUTH_GUARD_MAX_FAILURES, AUTH_GUARD_WINDOW and AUTH_GUARD_LOCKOUT must be positive")
+	}
+	if c.AuthGuard.CaptchaAfter < 0 {
+		add("AUTH_GUARD_CAPTCHA_AFTER must not be negative, got %d", c.AuthGuard.CaptchaAfter)
+	}
+	for _, prices := range []struct {
+		name   string
+		prices map[string]float64
+	}{{"COST_INPUT_PRICES", c.Cost.InputPrices}, {"COST_OUTPUT_PRICES", c.Cost.OutputPrices}} {
+		for model, price := range prices.prices {
+			if price < 0 {
+				add("%s must not be negative, got %v for %s", prices.name, price, model)
+			}
+		}
+	}
+	if c.Kafka.MaxAttempts < 1 {
+		add("KAFKA_MAX_ATTEMPTS must be at least 1, got %d", c.Kafka.MaxAttempts)
+	}
+	if c.Kafka.RetryBackoff < 0 || c.Kafka.DeadLetterRetention <= 0 {
+		add("KAFKA_RETRY_BACKOFF must not be negative and KAFKA_DEAD_LETTER_RETENTION must be positive")
+	}
+	if c.LLM.FakeLatency < 0 {
+		add("LLM_FAKE_LATENCY must not be negative, got %s", c.LLM.FakeLatency)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validateHTTPURL rejects anything but an absolute http or https URL
+func validateHTTPURL(raw string) error {
+	if raw == "" {
+		return fmt.Errorf("is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("is not a valid URL: %w", err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https URL, got %q", raw)
+	}
+	return nil
+}
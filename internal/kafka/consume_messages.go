@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"runtime"
 
-	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
@@ -18,7 +17,8 @@ func StartConsumeMessages(
 	sd fx.Shutdowner,
 	lc fx.Lifecycle,
 	conf *config.Config,
-	messageUsecase usecase.MessageUsecase,
+	chatEventUsecase usecase.ChatEventUsecase,
+	deadLetterUsecase usecase.DeadLetterUsecase,
 ) error {
 	return startKafkaConsumer(consumerOptions{
 		sd: sd,
@@ -29,7 +29,9 @@ func StartConsumeMessages(
 			GroupTopics: []string{conf.Kafka.Topic},
 		},
 		maxWorkers:     5,
-		consumeTimeout: 30 * 1e9, // 30 seconds
+		consumeTimeout: conf.Timeouts.MessageProcessing,
+		// replies in a channel must follow the order of its messages
+		orderingKey: channelOrderingKey,
 		handler: func(ctx context.Context, msg kafka.Message) (err error) {
 			defer func() {
 				if r := recover(); r != nil {
@@ -39,36 +41,32 @@ func StartConsumeMessages(
 				}
 			}()
 
-			// Parse Kafka message into the actual format
-			var kafkaMessage models.KafkaMessage
-			if err := json.Unmarshal(msg.Value, &kafkaMessage); err != nil {
-				return fmt.Errorf("failed to unmarshal kafka message: %w", err)
-			}
-
-			// Only process message.sent events
-			if kafkaMessage.Pattern != "message.sent" {
-				log.Infow(ctx, "Ignoring non-message.sent event", "pattern", kafkaMessage.Pattern)
-				return nil
-			}
-
-			// Convert to internal IncomingMessage format
-			incomingMessage := models.IncomingMessage{
-				ChannelID: kafkaMessage.Data.ChannelID,
-				CreatedAt: kafkaMessage.Data.CreatedAt,
-				SenderID:  kafkaMessage.Data.SenderID,
-				Message:   kafkaMessage.Data.Message,
-				Metadata: models.IncomingMessageMeta{
-					LLM: models.LLMMetadata{
-						ChatMode: "sales_assistant",
-					},
-				}, // Initialize with empty metadata for now
-			}
-
-			log.Infow(ctx, "Processing Kafka message",
-				"channel_id", incomingMessage.ChannelID,
-				"sender_id", incomingMessage.SenderID)
-
-			return messageUsecase.ProcessMessage(ctx, incomingMessage)
+			// events of every tenant share the topic, the message flow scopes
+			// itself to the tenant of the seller
+			return chatEventUsecase.HandleEvent(models.WithAllTenants(ctx), msg.Value)
+		},
+		maxAttempts:  conf.Kafka.MaxAttempts,
+		retryBackoff: conf.Kafka.RetryBackoff,
+		deadLetter: func(ctx context.Context, msg kafka.Message, err error, attempts int) {
+			deadLetterUsecase.Record(ctx, &models.DeadLetter{
+				Topic:     msg.Topic,
+				Partition: msg.Partition,
+				Offset:    msg.Offset,
+				Key:       string(msg.Key),
+				Payload:   string(msg.Value),
+				Error:     err.Error(),
+				Attempts:  attempts,
+			})
 		},
 	})
 }
+
+// channelOrderingKey keys a chat event by its channel, so the events of one
+// channel are processed in the order they were published
+func channelOrderingKey(msg kafka.Message) string {
+	var kafkaMessage models.KafkaMessage
+	if err := json.Unmarshal(msg.Value, &kafkaMessage); err != nil {
+		return ""
+	}
+	return kafkaMessage.Data.ChannelID
+}
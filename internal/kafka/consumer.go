@@ -4,14 +4,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	httpkit "github.com/carousell/ct-go/pkg/httpclient"
 	"github.com/carousell/ct-go/pkg/json"
 	"github.com/carousell/ct-go/pkg/logger"
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
-	"github.com/carousell/ct-go/pkg/workerpool"
 	"github.com/nguyentranbao-ct/chat-bot/pkg/ctxval"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/keyedpool"
 	"github.com/nguyentranbao-ct/chat-bot/pkg/tmplx"
 	"github.com/nguyentranbao-ct/chat-bot/pkg/util"
 	"github.com/prometheus/client_golang/prometheus"
@@ -33,7 +34,16 @@ type consumerOptions struct {
 	readerConf     kafka.ReaderConfig
 	maxWorkers     int
 	consumeTimeout time.Duration
-	handler        func(context.Context, kafka.Message) error
+	// orderingKey returns the key whose messages are handled one at a time in
+	// offset order; messages without a key are handled concurrently
+	orderingKey func(kafka.Message) string
+	handler     func(context.Context, kafka.Message) error
+	// maxAttempts bounds how many times a message failing with an unexpected
+	// error is handled; expected errors such as invalid input are not retried
+	maxAttempts  int
+	retryBackoff time.Duration
+	// deadLetter, when set, receives the messages that failed every attempt
+	deadLetter func(ctx context.Context, msg kafka.Message, err error, attempts int)
 }
 
 func startKafkaConsumer(opts consumerOptions) error {
@@ -72,7 +82,7 @@ func startKafkaConsumer(opts consumerOptions) error {
 func (w *kafkaConsumer) Start(ctx context.Context) error {
 	defer w.reader.Close()
 
-	pool := workerpool.New(w.opts.maxWorkers)
+	pool := keyedpool.New(w.opts.maxWorkers, w.opts.maxWorkers)
 	defer pool.Close()
 
 	groupID := w.reader.Config().GroupID
@@ -81,18 +91,12 @@ func (w *kafkaConsumer) Start(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		pool.Run(func() {
+		pool.Run(w.orderingKey(msg), func() {
 			start := time.Now()
 			lagMs := start.Sub(msg.Time).Milliseconds()
 
 			ctx := httpkit.InjectCorrelationIDToContext(ctx, httpkit.GenerateCorrelationID())
-			ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.opts.consumeTimeout)
-			defer cancel()
-
-			// wrap context with shared values
-			ctx = ctxval.Wrap(ctx)
-
-			err := w.opts.handler(ctx, msg)
+			attempts, err := w.handle(ctx, msg)
 			duration := time.Since(start)
 
 			code := getCode(err)
@@ -104,6 +108,7 @@ func (w *kafkaConsumer) Start(ctx context.Context) error {
 				"partition", msg.Partition,
 				"offset", msg.Offset,
 				"lag_ms", lagMs,
+				"attempts", attempts,
 				"key", string(msg.Key),
 				"value", json.RawMessage(msg.Value),
 				logger.Error(err),
@@ -111,11 +116,52 @@ func (w *kafkaConsumer) Start(ctx context.Context) error {
 			w.metrics.
 				WithLabelValues(code.String(), msg.Topic, groupID).
 				Observe(duration.Seconds())
+
+			if level == logger.ErrorLevel && w.opts.deadLetter != nil {
+				w.opts.deadLetter(context.WithoutCancel(ctx), msg, err, attempts)
+			}
 		})
 	}
 	return nil
 }
 
+// handle runs the handler on msg, retrying unexpected errors until
+// maxAttempts or shutdown. Each attempt gets its own timeout and shared values.
+func (w *kafkaConsumer) handle(ctx context.Context, msg kafka.Message) (attempts int, err error) {
+	for {
+		attempts++
+		err = w.attempt(ctx, msg)
+		if getLogLevel(getCode(err)) != logger.ErrorLevel || attempts >= w.opts.maxAttempts {
+			return attempts, err
+		}
+		log.Warnw(ctx, "kafka retry", "attempts", attempts, "offset", msg.Offset, logger.Error(err))
+		select {
+		case <-ctx.Done():
+			return attempts, err
+		case <-time.After(w.opts.retryBackoff * time.Duration(attempts)):
+		}
+	}
+}
+
+func (w *kafkaConsumer) attempt(ctx context.Context, msg kafka.Message) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.opts.consumeTimeout)
+	defer cancel()
+
+	// wrap context with shared values
+	ctx = ctxval.Wrap(ctx)
+
+	return w.opts.handler(ctx, msg)
+}
+
+func (w *kafkaConsumer) orderingKey(msg kafka.Message) string {
+	if w.opts.orderingKey != nil {
+		if key := w.opts.orderingKey(msg); key != "" {
+			return key
+		}
+	}
+	return strconv.Itoa(msg.Partition) + "/" + strconv.FormatInt(msg.Offset, 10)
+}
+
 func getCode(err error) codes.Code {
 	if errors.Is(err, context.DeadlineExceeded) {
 		return codes.DeadlineExceeded
@@ -0,0 +1,289 @@
+// Package loadtest posts synthetic buyer messages to a running chat-bot at a
+// fixed rate and reports throughput, latency per stage and database work, so
+// changes to message processing can be measured before they ship.
+package loadtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
+)
+
+const (
+	headerAPIKey     = "X-API-Key"
+	headerAdminKey   = "X-Admin-Key"
+	headerDebugStats = "X-Debug-Stats"
+)
+
+// Stages a message goes through. App is the request time not spent in
+// MongoDB or the LLM: queueing, chat-api calls and the bot's own work.
+const (
+	StageRequest = "request"
+	StageMongo   = "mongo"
+	StageLLM     = "llm"
+	StageApp     = "app"
+)
+
+type Options struct {
+	// URL is the base URL of the instance, e.g. http://localhost:8080
+	URL      string
+	APIKey   string
+	AdminKey string
+	ChatMode string
+	// ChannelIDs are the rooms messages are spread across, round robin
+	ChannelIDs []string
+	SenderID   string
+	// Rate is the number of messages started per second
+	Rate     float64
+	Duration time.Duration
+	// Concurrency caps the messages in flight; messages due while it is
+	// reached are dropped and counted, so the rate stays fixed
+	Concurrency int
+	DryTools    bool
+	Timeout     time.Duration
+}
+
+func (o Options) validate() error {
+	switch {
+	case o.URL == "":
+		return fmt.Errorf("url is required")
+	case o.ChatMode == "":
+		return fmt.Errorf("chat mode is required")
+	case len(o.ChannelIDs) == 0:
+		return fmt.Errorf("at least one channel ID is required")
+	case o.SenderID == "":
+		return fmt.Errorf("sender ID is required")
+	case o.Rate <= 0:
+		return fmt.Errorf("rate must be positive")
+	case o.Duration <= 0:
+		return fmt.Errorf("duration must be positive")
+	case o.Concurrency <= 0:
+		return fmt.Errorf("concurrency must be positive")
+	}
+	return nil
+}
+
+type Report struct {
+	Sent      int `json:"sent"`
+	Succeeded int `json:"succeeded"`
+	Failed    int `json:"failed"`
+	// Dropped messages were due while Concurrency messages were in flight
+	Dropped int `json:"dropped"`
+	// Statuses counts responses by HTTP status, 0 for transport errors
+	Statuses   map[int]int `json:"statuses"`
+	ElapsedMs  int64       `json:"elapsed_ms"`
+	Throughput float64     `json:"throughput_per_sec"`
+	// Stages are only measured beyond request when the instance returned
+	// debug stats, which needs the admin key
+	Stages       map[string]Latency `json:"stages"`
+	DebugStats   bool               `json:"debug_stats"`
+	MongoQueries int                `json:"mongo_queries"`
+	// MongoPerMessage is the average number of queries of a succeeded message
+	MongoPerMessage float64 `json:"mongo_queries_per_message"`
+	LLMCalls        int     `json:"llm_calls"`
+	CacheHits       int     `json:"cache_hits"`
+	CacheMisses     int     `json:"cache_misses"`
+	// Errors holds the first distinct failures, for a quick look
+	Errors []string `json:"errors,omitempty"`
+}
+
+type Latency struct {
+	P50Ms float64 `json:"p50_ms"`
+	P95Ms float64 `json:"p95_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+// maxReportedErrors bounds Report.Errors
+const maxReportedErrors = 10
+
+// result is the outcome of one message
+type result struct {
+	status  int
+	elapsed time.Duration
+	stats   *reqstats.Stats
+	err     error
+}
+
+// Run posts messages until opts.Duration has passed or ctx is done, waits for
+// the ones in flight and reports on them
+func Run(ctx context.Context, opts Options) (*Report, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: opts.Timeout}
+	endpoint := strings.TrimRight(opts.URL, "/") + "/api/v1/messages"
+
+	var (
+		mu      sync.Mutex
+		results []result
+		wg      sync.WaitGroup
+		dropped int
+	)
+	inFlight := make(chan struct{}, opts.Concurrency)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.Rate))
+	defer ticker.Stop()
+	deadline := time.After(opts.Duration)
+
+	start := time.Now()
+loop:
+	for i := 0; ; i++ {
+		select {
+		case inFlight <- struct{}{}:
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-inFlight }()
+				res := send(ctx, client, endpoint, opts, i)
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+			}(i)
+		default:
+			dropped++
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline:
+			break loop
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	wg.Wait()
+
+	report := summarize(results, time.Since(start))
+	report.Dropped = dropped
+	return report, nil
+}
+
+// send posts the i-th message and collects the debug stats of its request
+func send(ctx context.Context, client *http.Client, endpoint string, opts Options, i int) result {
+	message := models.IncomingMessage{
+		ChannelID: opts.ChannelIDs[i%len(opts.ChannelIDs)],
+		CreatedAt: time.Now().UnixMilli(),
+		SenderID:  opts.SenderID,
+		Message:   fmt.Sprintf("Load test message %d, is this still available?", i),
+		Metadata: models.IncomingMessageMeta{
+			LLM: models.LLMMetadata{ChatMode: opts.ChatMode, DryTools: opts.DryTools},
+		},
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return result{err: fmt.Errorf("failed to marshal message: %w", err)}
+	}
+
+	// the message outlives a cancelled run, so the report covers it
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return result{err: fmt.Errorf("failed to create request: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if opts.APIKey != "" {
+		req.Header.Set(headerAPIKey, opts.APIKey)
+	}
+	if opts.AdminKey != "" {
+		req.Header.Set(headerAdminKey, opts.AdminKey)
+		req.Header.Set(headerDebugStats, "1")
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return result{elapsed: time.Since(start), err: err}
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	res := result{status: resp.StatusCode, elapsed: time.Since(start)}
+
+	if header := resp.Header.Get(headerDebugStats); header != "" {
+		if res.stats, err = reqstats.Parse(header); err != nil {
+			res.err = err
+			return res
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		res.err = fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	return res
+}
+
+func summarize(results []result, elapsed time.Duration) *Report {
+	report := &Report{
+		Sent:      len(results),
+		Statuses:  map[int]int{},
+		ElapsedMs: elapsed.Milliseconds(),
+		Stages:    map[string]Latency{},
+	}
+
+	var request, mongo, llm, app []time.Duration
+	for _, res := range results {
+		report.Statuses[res.status]++
+		if res.err != nil {
+			report.Failed++
+			if msg := res.err.Error(); len(report.Errors) < maxReportedErrors && !slices.Contains(report.Errors, msg) {
+				report.Errors = append(report.Errors, msg)
+			}
+			continue
+		}
+		report.Succeeded++
+		request = append(request, res.elapsed)
+		if res.stats == nil {
+			continue
+		}
+
+		report.DebugStats = true
+		report.MongoQueries += res.stats.MongoQueries
+		report.LLMCalls += res.stats.LLMCalls
+		report.CacheHits += res.stats.CacheHits
+		report.CacheMisses += res.stats.CacheMisses
+		mongo = append(mongo, res.stats.MongoDuration)
+		llm = append(llm, res.stats.LLMDuration)
+		app = append(app, max(res.elapsed-res.stats.MongoDuration-res.stats.LLMDuration, 0))
+	}
+
+	if elapsed > 0 {
+		report.Throughput = float64(report.Succeeded) / elapsed.Seconds()
+	}
+	if len(mongo) > 0 {
+		report.MongoPerMessage = float64(report.MongoQueries) / float64(len(mongo))
+	}
+	for stage, durations := range map[string][]time.Duration{
+		StageRequest: request,
+		StageMongo:   mongo,
+		StageLLM:     llm,
+		StageApp:     app,
+	} {
+		if len(durations) > 0 {
+			report.Stages[stage] = latency(durations)
+		}
+	}
+	return report
+}
+
+// latency reports nearest-rank percentiles of durations
+func latency(durations []time.Duration) Latency {
+	slices.Sort(durations)
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(durations)))) - 1
+		return float64(durations[max(i, 0)].Microseconds()) / 1000
+	}
+	return Latency{
+		P50Ms: rank(0.50),
+		P95Ms: rank(0.95),
+		P99Ms: rank(0.99),
+		MaxMs: rank(1),
+	}
+}
@@ -0,0 +1,98 @@
+package loadtest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	opts := Options{
+		ChatMode:    "seller_mode",
+		ChannelIDs:  []string{"channel-1", "channel-2"},
+		SenderID:    "buyer-1",
+		Rate:        200,
+		Duration:    100 * time.Millisecond,
+		Concurrency: 50,
+		Timeout:     time.Second,
+	}
+
+	t.Run("Reports Stages From Debug Stats", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/messages", r.URL.Path)
+			assert.Equal(t, "admin-key", r.Header.Get(headerAdminKey))
+			w.Header().Set(headerDebugStats, "mongo=4;mongo_ms=2;cache_hit=1;cache_miss=0;llm=1;llm_ms=3")
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		run := opts
+		run.URL = srv.URL
+		run.AdminKey = "admin-key"
+		report, err := Run(context.Background(), run)
+		require.NoError(t, err)
+
+		assert.Positive(t, report.Succeeded)
+		assert.Zero(t, report.Failed)
+		assert.True(t, report.DebugStats)
+		assert.Equal(t, 4*report.Succeeded, report.MongoQueries)
+		assert.InDelta(t, 4, report.MongoPerMessage, 0.001)
+		assert.Equal(t, 3.0, report.Stages[StageLLM].P99Ms)
+		assert.Contains(t, report.Stages, StageApp)
+	})
+
+	t.Run("Counts Failures And Drops", func(t *testing.T) {
+		t.Parallel()
+		var calls atomic.Int32
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			<-release
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		run := opts
+		run.URL = srv.URL
+		run.Concurrency = 1
+		time.AfterFunc(run.Duration+50*time.Millisecond, func() { close(release) })
+		report, err := Run(context.Background(), run)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Failed)
+		assert.Equal(t, 1, report.Statuses[http.StatusInternalServerError])
+		assert.Positive(t, report.Dropped)
+		assert.False(t, report.DebugStats)
+		assert.Equal(t, []string{"status 500: boom"}, report.Errors)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Rejects Incomplete Options", func(t *testing.T) {
+		t.Parallel()
+		_, err := Run(context.Background(), Options{URL: "http://localhost:8080"})
+		assert.Error(t, err)
+	})
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Nearest Rank Percentiles", func(t *testing.T) {
+		var durations []time.Duration
+		for i := 100; i >= 1; i-- {
+			durations = append(durations, time.Duration(i)*time.Millisecond)
+		}
+
+		got := latency(durations)
+		assert.Equal(t, Latency{P50Ms: 50, P95Ms: 95, P99Ms: 99, MaxMs: 100}, got)
+	})
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeedEventType is the kind of event shown in a seller's activity feed
+type FeedEventType string
+
+const (
+	// FeedPurchaseIntent is a buyer interest logged by the PurchaseIntent tool
+	FeedPurchaseIntent FeedEventType = "purchase_intent"
+	// FeedOffer is a buyer message negotiating the price
+	FeedOffer FeedEventType = "offer"
+	// FeedHandoff is a channel the bot left to the seller after negative sentiment
+	FeedHandoff FeedEventType = "handoff"
+	// FeedDeliveryFailed is a bot reply chat-api did not accept
+	FeedDeliveryFailed FeedEventType = "delivery_failed"
+	// FeedAssignmentChanged is a channel assigned to another team agent or
+	// unassigned
+	FeedAssignmentChanged FeedEventType = "assignment_changed"
+	// FeedClaimChanged is an agent taking over or releasing a room
+	FeedClaimChanged FeedEventType = "claim_changed"
+)
+
+// FeedItem is one noteworthy event of a seller's chats. Items are removed
+// once past ExpiresAt, seen or not.
+type FeedItem struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	Type      FeedEventType       `bson:"type" json:"type"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	BuyerID   string              `bson:"buyer_id,omitempty" json:"buyer_id,omitempty"`
+	// Summary is a short description of the event for the seller
+	Summary string         `bson:"summary" json:"summary"`
+	Data    map[string]any `bson:"data,omitempty" json:"data,omitempty"`
+	SeenAt  *time.Time     `bson:"seen_at,omitempty" json:"seen_at,omitempty"`
+	// CreatedAt is when the event happened
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at" json:"-"`
+}
+
+// FeedFilter narrows a seller's activity feed. Before is the ID of the last
+// item of the previous page.
+type FeedFilter struct {
+	SellerID   string
+	Types      []FeedEventType
+	UnseenOnly bool
+	Before     *primitive.ObjectID
+	Limit      int
+}
+
+// FeedPage is a page of an activity feed, newest first
+type FeedPage struct {
+	Items []*FeedItem `json:"items"`
+	// Unseen counts the seller's unseen items across all pages
+	Unseen int64 `json:"unseen"`
+	// NextBefore fetches the next page, empty on the last one
+	NextBefore string `json:"next_before,omitempty"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ActorType string
+
+const (
+	ActorTypeSystem ActorType = "system"
+	ActorTypeAdmin  ActorType = "admin"
+	ActorTypeAPIKey ActorType = "api_key"
+	ActorTypeUser   ActorType = "user"
+	// ActorTypeAnonymous is an API caller that presented no credentials
+	ActorTypeAnonymous ActorType = "anonymous"
+)
+
+// Actor identifies who performed an audited operation
+type Actor struct {
+	Type ActorType `bson:"type" json:"type"`
+	ID   string    `bson:"id,omitempty" json:"id,omitempty"`
+}
+
+type AuditAction string
+
+const (
+	AuditUserCreate           AuditAction = "user.create"
+	AuditUserUpdate           AuditAction = "user.update"
+	AuditUserDelete           AuditAction = "user.delete"
+	AuditUserAvatarUpdate     AuditAction = "user.avatar_update"
+	AuditUserAttributeSet     AuditAction = "user_attribute.set"
+	AuditUserAttributeRemove  AuditAction = "user_attribute.remove"
+	AuditChatModeMigrate      AuditAction = "chat_mode.migrate"
+	AuditUserMigrate          AuditAction = "user.migrate"
+	AuditUserAttributeMigrate AuditAction = "user_attribute.migrate"
+	AuditTenantCreate         AuditAction = "tenant.create"
+	AuditTenantUpdateSettings AuditAction = "tenant.update_settings"
+	AuditTenantExport         AuditAction = "tenant.export"
+	AuditTenantRestore        AuditAction = "tenant.restore"
+	AuditAPIKeyCreate         AuditAction = "api_key.create"
+	AuditAPIKeyRevoke         AuditAction = "api_key.revoke"
+	AuditLLMKeySet            AuditAction = "llm_key.set"
+	AuditLLMKeyDelete         AuditAction = "llm_key.delete"
+	AuditReservationRelease   AuditAction = "reservation.release"
+	AuditOnboardingUpdate     AuditAction = "onboarding.update"
+	AuditChototLinkStart      AuditAction = "chotot_link.start"
+	AuditChototLinkVerify     AuditAction = "chotot_link.verify"
+	AuditChototLinkRevoke     AuditAction = "chotot_link.revoke"
+	AuditChannelChatModeSet   AuditAction = "channel.set_chat_mode"
+	AuditChannelChatModeClear AuditAction = "channel.clear_chat_mode"
+	AuditPersonaSet           AuditAction = "persona.set"
+	AuditPersonaDelete        AuditAction = "persona.delete"
+	AuditSuggestionApprove    AuditAction = "suggestion.approve"
+	AuditSuggestionReject     AuditAction = "suggestion.reject"
+	// AuditChannelSentimentResume hands a channel alerted for negative sentiment back to the bot
+	AuditChannelSentimentResume   AuditAction = "channel.resume_bot"
+	AuditScamFlagReview           AuditAction = "scam_flag.review"
+	AuditSessionOutcome           AuditAction = "session.outcome"
+	AuditSessionsAbandon          AuditAction = "session.abandon_inactive"
+	AuditSellerChatModeReassign   AuditAction = "seller.reassign_chat_mode"
+	AuditJobEnqueue               AuditAction = "job.enqueue"
+	AuditJobCancel                AuditAction = "job.cancel"
+	AuditTenantToolPolicy         AuditAction = "tenant.update_tool_policy"
+	AuditTeamCreate               AuditAction = "team.create"
+	AuditTeamAddMember            AuditAction = "team.add_member"
+	AuditTeamRemoveMember         AuditAction = "team.remove_member"
+	AuditChannelAssign            AuditAction = "channel.assign"
+	AuditChannelUnassign          AuditAction = "channel.unassign"
+	AuditChannelClaimOverride     AuditAction = "channel.claim_override"
+	AuditTemplateSet              AuditAction = "template.set"
+	AuditTemplateDelete           AuditAction = "template.delete"
+	AuditSystemMessageSet         AuditAction = "system_message.set"
+	AuditSystemMessageDelete      AuditAction = "system_message.delete"
+	AuditWhitelistAdd             AuditAction = "whitelist.add"
+	AuditWhitelistRemove          AuditAction = "whitelist.remove"
+	AuditWhitelistRollout         AuditAction = "whitelist.rollout"
+	AuditUserEmailVerify          AuditAction = "user.email_verify"
+	AuditAuthLockout              AuditAction = "auth.lockout"
+	AuditAuthUnlock               AuditAction = "auth.unlock"
+	AuditDeadLetterRequeue        AuditAction = "dead_letter.requeue"
+	AuditChannelPrivacySet        AuditAction = "channel.set_privacy"
+	AuditChannelMessagePrivacySet AuditAction = "channel.set_message_privacy"
+)
+
+// AuditLog records a data-mutating operation. Before and After hold JSON-shaped
+// snapshots so fields hidden from the API (hashes, encrypted keys) never land here.
+type AuditLog struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID     *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Actor        Actor               `bson:"actor" json:"actor"`
+	Action       AuditAction         `bson:"action" json:"action"`
+	ResourceType string              `bson:"resource_type" json:"resource_type"`
+	ResourceID   string              `bson:"resource_id" json:"resource_id"`
+	Before       map[string]any      `bson:"before,omitempty" json:"before,omitempty"`
+	After        map[string]any      `bson:"after,omitempty" json:"after,omitempty"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// AuditLogFilter narrows an audit log query, zero values match everything
+type AuditLogFilter struct {
+	TenantID     *primitive.ObjectID
+	ActorType    ActorType
+	ActorID      string
+	Action       AuditAction
+	ResourceType string
+	ResourceID   string
+	Since        time.Time
+	Until        time.Time
+	Limit        int
+}
+
+type actorCtxKey struct{}
+
+// WithActor returns a copy of ctx carrying the actor performing the request
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorCtxKey{}, actor)
+}
+
+// ActorFromContext returns the actor of ctx, defaulting to the system actor
+func ActorFromContext(ctx context.Context) Actor {
+	if actor, ok := ctx.Value(actorCtxKey{}).(Actor); ok {
+		return actor
+	}
+	return Actor{Type: ActorTypeSystem}
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// AuthScope is what failed authentication attempts are counted against
+type AuthScope string
+
+const (
+	// AuthScopeIP counts the failures of a client address, across keys and
+	// accounts
+	AuthScopeIP AuthScope = "ip"
+	// AuthScopeAccount counts the failures against a user, by user ID
+	AuthScopeAccount AuthScope = "account"
+)
+
+// Valid reports whether s is a known scope
+func (s AuthScope) Valid() bool {
+	return s == AuthScopeIP || s == AuthScopeAccount
+}
+
+// AuthFailure counts the failed attempts of a client address or account
+// within the current window, and locks it out once there are too many
+type AuthFailure struct {
+	Scope    AuthScope `bson:"scope" json:"scope"`
+	Key      string    `bson:"key" json:"key"`
+	Failures int       `bson:"failures" json:"failures"`
+	// WindowStart is the first failure counted in Failures
+	WindowStart   time.Time  `bson:"window_start" json:"window_start"`
+	LastFailureAt time.Time  `bson:"last_failure_at" json:"last_failure_at"`
+	LockedUntil   *time.Time `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	// ExpiresAt is when MongoDB removes the record, once neither the window
+	// nor the lockout matter
+	ExpiresAt time.Time `bson:"expires_at" json:"-"`
+}
+
+// Locked reports whether the lockout is still running at now
+func (f *AuthFailure) Locked(now time.Time) bool {
+	return f != nil && f.LockedUntil != nil && f.LockedUntil.After(now)
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BackupVersion is bumped whenever the archive layout changes incompatibly
+const BackupVersion = 1
+
+// BackupManifest closes a tenant archive, so a truncated archive is detected
+type BackupManifest struct {
+	Version  int                `bson:"version" json:"version"`
+	TenantID primitive.ObjectID `bson:"tenant_id" json:"tenant_id"`
+	// Collections counts the documents of each collection in the archive
+	Collections map[string]int `bson:"collections" json:"collections"`
+	CreatedAt   time.Time      `bson:"created_at" json:"created_at"`
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BudgetLimit names the cap that stopped the bot
+type BudgetLimit string
+
+const (
+	BudgetLimitDailyReplies  BudgetLimit = "daily_replies"
+	BudgetLimitSessionTokens BudgetLimit = "session_tokens"
+)
+
+// SessionUsage counts the LLM generations of a session and the tokens they took
+type SessionUsage struct {
+	Generations  int `bson:"generations" json:"generations"`
+	InputTokens  int `bson:"input_tokens" json:"input_tokens"`
+	OutputTokens int `bson:"output_tokens" json:"output_tokens"`
+}
+
+func (u SessionUsage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// ChannelBudget counts what the bot spent in a channel on one day
+type ChannelBudget struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	// Day is the local date the counters cover, see BudgetDay
+	Day     string `bson:"day" json:"day"`
+	Replies int    `bson:"replies" json:"replies"`
+	Tokens  int    `bson:"tokens" json:"tokens"`
+	// ExceededLimit and NotifiedAt are set when the bot stopped for the day
+	ExceededLimit BudgetLimit `bson:"exceeded_limit,omitempty" json:"exceeded_limit,omitempty"`
+	NotifiedAt    *time.Time  `bson:"notified_at,omitempty" json:"notified_at,omitempty"`
+	ExpiresAt     time.Time   `bson:"expires_at" json:"-"`
+	CreatedAt     time.Time   `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time   `bson:"updated_at" json:"updated_at"`
+}
+
+// ChannelBudgetReport is a channel's counters for today along with the caps
+// they are checked against, zero caps being disabled
+type ChannelBudgetReport struct {
+	ChannelID           string         `json:"channel_id"`
+	Today               *ChannelBudget `json:"today"`
+	MaxRepliesPerDay    int            `json:"max_replies_per_day"`
+	MaxTokensPerSession int            `json:"max_tokens_per_session"`
+}
+
+// BudgetDay returns the date budget counters of t are kept under
+func BudgetDay(t time.Time) string {
+	return t.Format(time.DateOnly)
+}
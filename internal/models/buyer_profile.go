@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// BuyerProfile is what the bot knows about a buyer from earlier chats with
+// the same seller, kept compact for the prompt
+type BuyerProfile struct {
+	BuyerID string `json:"buyer_id"`
+	// Returning is true when the buyer talked to the seller in an earlier session
+	Returning        bool       `json:"returning"`
+	PreviousSessions int        `json:"previous_sessions"`
+	PreviousChannels int        `json:"previous_channels"`
+	FirstContactAt   *time.Time `json:"first_contact_at,omitempty"`
+	LastContactAt    *time.Time `json:"last_contact_at,omitempty"`
+	// PurchaseIntents are the latest intents logged with this seller
+	PurchaseIntents []BuyerIntent `json:"purchase_intents,omitempty"`
+	// ReservedItems are the seller's listings currently held for the buyer
+	ReservedItems []string `json:"reserved_items,omitempty"`
+	// PreferredLanguage is "vi" or "en", guessed from the buyer's recent
+	// messages; empty when unknown
+	PreferredLanguage string `json:"preferred_language,omitempty"`
+	// LeadScore from 0 to 100 estimates how likely the buyer is to purchase
+	LeadScore int `json:"lead_score"`
+}
+
+type BuyerIntent struct {
+	ItemName   string    `json:"item_name"`
+	Intent     string    `json:"intent"`
+	Percentage int       `json:"percentage"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ConversationRecap sums up an earlier conversation of the buyer with the
+// same seller in another channel
+type ConversationRecap struct {
+	ChannelID     string    `json:"channel_id"`
+	ItemName      string    `json:"item_name,omitempty"`
+	Messages      int       `json:"messages"`
+	LastContactAt time.Time `json:"last_contact_at"`
+	// PurchaseIntent is the strongest intent logged in the channel
+	PurchaseIntent *BuyerIntent `json:"purchase_intent,omitempty"`
+	// Reserved is true while an item is held for the buyer in the channel
+	Reserved bool `json:"reserved"`
+}
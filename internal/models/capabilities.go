@@ -0,0 +1,16 @@
+package models
+
+// ProtocolVersions are the client API versions the server speaks, oldest
+// first. A version is only added when existing routes or fields change.
+var ProtocolVersions = []string{"v1"}
+
+// Capabilities tells clients what the server supports, so older clients can
+// degrade gracefully as features are added
+type Capabilities struct {
+	ProtocolVersions []string `json:"protocol_versions"`
+	// Features are keyed by name. Features a client may ask about but that
+	// the server lacks are listed as false rather than left out.
+	Features map[string]bool `json:"features"`
+	// EventTypes are the chat-api events the bot consumes
+	EventTypes []string `json:"event_types"`
+}
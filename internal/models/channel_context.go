@@ -0,0 +1,30 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelContext keeps the full context of a channel whose metadata context
+// was too long for prompts, with the digest the bot is given instead
+type ChannelContext struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	// Hash identifies Full, so a context changed in chat-api is summarized again
+	Hash string `bson:"hash" json:"hash"`
+	Full string `bson:"full" json:"full"`
+	// Digest is empty until a summary succeeds
+	Digest    string    `bson:"digest,omitempty" json:"digest,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ChannelContextHash identifies a channel context
+func ChannelContextHash(context string) string {
+	sum := sha256.Sum256([]byte(context))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelItemID is the item of the room itself, from chat-api's channel
+// metadata, which has no listing ID of its own
+const ChannelItemID = "channel"
+
+// ChannelItemStatus is where an item stands in the conversation
+type ChannelItemStatus string
+
+const (
+	// ChannelItemCurrent is the item the buyer is talking about now; a room
+	// has at most one
+	ChannelItemCurrent     ChannelItemStatus = "current"
+	ChannelItemConsidering ChannelItemStatus = "considering"
+	ChannelItemDropped     ChannelItemStatus = "dropped"
+)
+
+// ChannelItem is a listing discussed in a room
+type ChannelItem struct {
+	ItemID    string            `bson:"item_id" json:"item_id"`
+	ItemName  string            `bson:"item_name" json:"item_name"`
+	ItemPrice string            `bson:"item_price,omitempty" json:"item_price,omitempty"`
+	Status    ChannelItemStatus `bson:"status" json:"status"`
+	AddedAt   time.Time         `bson:"added_at" json:"added_at"`
+}
+
+// ChannelItems lists the items discussed in a room, in the order they came up
+type ChannelItems struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	Items     []ChannelItem       `bson:"items" json:"items"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// Current returns the item discussed now, nil when there is none
+func (c *ChannelItems) Current() *ChannelItem {
+	for i := range c.Items {
+		if c.Items[i].Status == ChannelItemCurrent {
+			return &c.Items[i]
+		}
+	}
+	return nil
+}
+
+// Add lists the item, or refreshes its name and price when it is listed
+// already. With current, it becomes the item discussed now.
+func (c *ChannelItems) Add(item ChannelItem, current bool) {
+	i := c.index(item.ItemID)
+	if i < 0 {
+		item.Status = ChannelItemConsidering
+		item.AddedAt = time.Now()
+		c.Items = append(c.Items, item)
+		i = len(c.Items) - 1
+	} else {
+		c.Items[i].ItemName = item.ItemName
+		if item.ItemPrice != "" {
+			c.Items[i].ItemPrice = item.ItemPrice
+		}
+		if c.Items[i].Status == ChannelItemDropped {
+			c.Items[i].Status = ChannelItemConsidering
+		}
+	}
+	if current || c.Current() == nil {
+		c.Switch(item.ItemID, false)
+	}
+}
+
+// Switch makes the item the one discussed now. The previous one stays under
+// consideration, or is dropped with dropPrevious. It reports whether the item
+// is listed.
+func (c *ChannelItems) Switch(itemID string, dropPrevious bool) bool {
+	i := c.index(itemID)
+	if i < 0 {
+		return false
+	}
+	if previous := c.Current(); previous != nil && previous.ItemID != itemID {
+		previous.Status = ChannelItemConsidering
+		if dropPrevious {
+			previous.Status = ChannelItemDropped
+		}
+	}
+	c.Items[i].Status = ChannelItemCurrent
+	return true
+}
+
+func (c *ChannelItems) index(itemID string) int {
+	for i := range c.Items {
+		if c.Items[i].ItemID == itemID {
+			return i
+		}
+	}
+	return -1
+}
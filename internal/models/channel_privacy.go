@@ -0,0 +1,47 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/pkg/redact"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelPrivacy keeps a room, or some of its messages, away from the LLM.
+// Private content stays visible to the people in the room.
+type ChannelPrivacy struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	// Private keeps the whole room away from the LLM, the bot stays quiet in it
+	Private bool `bson:"private" json:"private"`
+	// MessageIDs are the messages marked private in the room
+	MessageIDs []string  `bson:"message_ids,omitempty" json:"message_ids,omitempty"`
+	CreatedAt  time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Excludes reports whether message is private: the room is, the message was
+// marked, or detectSensitive is set and the text holds sensitive data. A nil
+// privacy only detects.
+func (p *ChannelPrivacy) Excludes(message HistoryMessage, detectSensitive bool) bool {
+	if p != nil && (p.Private || slices.Contains(p.MessageIDs, message.ID)) {
+		return true
+	}
+	return detectSensitive && redact.Sensitive(message.Message)
+}
+
+// FilterHistory returns history without its private messages
+func (p *ChannelPrivacy) FilterHistory(history *MessageHistory, detectSensitive bool) *MessageHistory {
+	if history == nil {
+		return nil
+	}
+	filtered := &MessageHistory{Messages: []HistoryMessage{}, HasMore: history.HasMore}
+	for _, message := range history.Messages {
+		if !p.Excludes(message, detectSensitive) {
+			filtered.Messages = append(filtered.Messages, message)
+		}
+	}
+	return filtered
+}
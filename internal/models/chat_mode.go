@@ -16,44 +16,99 @@ type ChatMode struct {
 	MaxIterations     int                 `bson:"max_iterations" json:"max_iterations" yaml:"max_iterations"`
 	MaxPromptTokens   int                 `bson:"max_prompt_tokens" json:"max_prompt_tokens" yaml:"max_prompt_tokens"`
 	MaxResponseTokens int                 `bson:"max_response_tokens" json:"max_response_tokens" yaml:"max_response_tokens"`
+	OutputSchema      map[string]any      `bson:"output_schema,omitempty" json:"output_schema,omitempty" yaml:"output_schema,omitempty"`
+	SessionTimeout    *SessionTimeout     `bson:"session_timeout,omitempty" json:"session_timeout,omitempty" yaml:"session_timeout,omitempty"`
 	UserID            *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty" yaml:"user_id,omitempty"`
+	TenantID          *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty" yaml:"-"`
 	CreatedAt         time.Time           `bson:"created_at" json:"created_at" yaml:"-"`
 	UpdatedAt         time.Time           `bson:"updated_at" json:"updated_at" yaml:"-"`
 }
 
 type ChatSession struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	ChannelID string             `bson:"channel_id" json:"channel_id"`
-	UserID    string             `bson:"user_id" json:"user_id"`
-	ChatMode  string             `bson:"chat_mode" json:"chat_mode"`
-	Status    SessionStatus      `bson:"status" json:"status"`
-	StartedAt time.Time          `bson:"started_at" json:"started_at"`
-	EndedAt   *time.Time         `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	UserID    string              `bson:"user_id" json:"user_id"`
+	// SellerID is the chat-api seller the bot answers for; sessions started
+	// before it was recorded have none
+	SellerID string `bson:"seller_id,omitempty" json:"seller_id,omitempty"`
+	// ItemName is the channel's item when the session started, kept for
+	// recaps of earlier conversations
+	ItemName string `bson:"item_name,omitempty" json:"item_name,omitempty"`
+	// Intent is the label of the buyer message that started the session
+	Intent MessageIntent `bson:"intent,omitempty" json:"intent,omitempty"`
+	// Sentiment is the score of the buyer message that started the session
+	Sentiment *SentimentScore `bson:"sentiment,omitempty" json:"sentiment,omitempty"`
+	ChatMode  string          `bson:"chat_mode" json:"chat_mode"`
+	Status    SessionStatus   `bson:"status" json:"status"`
+	StartedAt time.Time       `bson:"started_at" json:"started_at"`
+	EndedAt   *time.Time      `bson:"ended_at,omitempty" json:"ended_at,omitempty"`
+	CreatedAt time.Time       `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time       `bson:"updated_at" json:"updated_at"`
+	// Usage counts the LLM tokens the session spent
+	Usage SessionUsage `bson:"usage" json:"usage"`
+	// BudgetExceeded is the cap the session stopped at, if any
+	BudgetExceeded BudgetLimit `bson:"budget_exceeded,omitempty" json:"budget_exceeded,omitempty"`
+	// Output is the structured response of a chat mode with an output schema
+	Output map[string]any `bson:"output,omitempty" json:"output,omitempty"`
+	// PromptVersion is the version of the chat mode's prompt template the
+	// session started with
+	PromptVersion string `bson:"prompt_version,omitempty" json:"prompt_version,omitempty"`
+	// Outcome is set once the conversation ended for the seller
+	Outcome *SessionOutcomeRecord `bson:"outcome,omitempty" json:"outcome,omitempty"`
+	// DryTools is set on sessions whose tools returned fixtures
+	DryTools bool `bson:"dry_tools,omitempty" json:"dry_tools,omitempty"`
+	// TestMode is set on sessions started while chat-api was in test mode,
+	// whose replies were held instead of sent
+	TestMode bool `bson:"test_mode,omitempty" json:"test_mode,omitempty"`
+	// Language is detected from the buyer message that started the session
+	Language string `bson:"language,omitempty" json:"language,omitempty"`
+	// EndReason tells why the session is no longer active
+	EndReason SessionEndReason `bson:"end_reason,omitempty" json:"end_reason,omitempty"`
+}
+
+// SessionTimeout ends the sessions of a chat mode that stay inactive
+type SessionTimeout struct {
+	// InactiveMinutes is how long a session may go without activity before
+	// it is ended, 0 keeps it open
+	InactiveMinutes int `bson:"inactive_minutes" json:"inactive_minutes" yaml:"inactive_minutes"`
+	// Farewell is sent to the channel as the seller when its latest session
+	// times out, e.g. a check-in message; empty sends nothing
+	Farewell string `bson:"farewell,omitempty" json:"farewell,omitempty" yaml:"farewell,omitempty"`
+}
+
+// Inactivity returns how long a session may stay inactive, 0 when the
+// timeout is off
+func (t *SessionTimeout) Inactivity() time.Duration {
+	if t == nil || t.InactiveMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(t.InactiveMinutes) * time.Minute
 }
 
 type ChatActivity struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	SessionID  primitive.ObjectID `bson:"session_id" json:"session_id"`
-	ChannelID  string             `bson:"channel_id" json:"channel_id"`
-	MessageID  string             `bson:"message_id,omitempty" json:"message_id,omitempty"`
-	Action     ActivityAction     `bson:"action" json:"action"`
-	Data       interface{}        `bson:"data,omitempty" json:"data,omitempty"`
-	ExecutedAt time.Time          `bson:"executed_at" json:"executed_at"`
-	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID   *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID  primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChannelID  string              `bson:"channel_id" json:"channel_id"`
+	MessageID  string              `bson:"message_id,omitempty" json:"message_id,omitempty"`
+	Action     ActivityAction      `bson:"action" json:"action"`
+	Data       interface{}         `bson:"data,omitempty" json:"data,omitempty"`
+	ExecutedAt time.Time           `bson:"executed_at" json:"executed_at"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
 }
 
 type PurchaseIntent struct {
-	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	SessionID  primitive.ObjectID `bson:"session_id" json:"session_id"`
-	ChannelID  string             `bson:"channel_id" json:"channel_id"`
-	UserID     string             `bson:"user_id" json:"user_id"`
-	ItemName   string             `bson:"item_name" json:"item_name"`
-	ItemPrice  string             `bson:"item_price" json:"item_price"`
-	Intent     string             `bson:"intent" json:"intent"`
-	Percentage int                `bson:"percentage" json:"percentage"`
-	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID   *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID  primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChannelID  string              `bson:"channel_id" json:"channel_id"`
+	UserID     string              `bson:"user_id" json:"user_id"`
+	ItemName   string              `bson:"item_name" json:"item_name"`
+	ItemPrice  string              `bson:"item_price" json:"item_price"`
+	Intent     string              `bson:"intent" json:"intent"`
+	Percentage int                 `bson:"percentage" json:"percentage"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
 }
 
 type SessionStatus string
@@ -64,12 +119,29 @@ const (
 	SessionStatusAbandoned SessionStatus = "abandoned"
 )
 
+type SessionEndReason string
+
+const (
+	// SessionEndedByModel is a session the model ended with EndSession
+	SessionEndedByModel SessionEndReason = "end_session"
+	// SessionEndedInactive is a session that passed its chat mode's timeout
+	SessionEndedInactive SessionEndReason = "inactivity"
+	// SessionEndedAbandoned is a session abandoned by an admin bulk operation
+	SessionEndedAbandoned SessionEndReason = "abandoned"
+)
+
 type ActivityAction string
 
 const (
-	ActivityPurchaseIntent ActivityAction = "purchase_intent"
-	ActivityReplyMessage   ActivityAction = "reply_message"
-	ActivityFetchMessages  ActivityAction = "fetch_messages"
-	ActivityEndSession     ActivityAction = "end_session"
-	ActivityListProducts   ActivityAction = "list_products"
+	ActivityPurchaseIntent  ActivityAction = "purchase_intent"
+	ActivityReplyMessage    ActivityAction = "reply_message"
+	ActivityFetchMessages   ActivityAction = "fetch_messages"
+	ActivityEndSession      ActivityAction = "end_session"
+	ActivityListProducts    ActivityAction = "list_products"
+	ActivityReserveItem     ActivityAction = "reserve_item"
+	ActivityGetBuyerProfile ActivityAction = "get_buyer_profile"
+	ActivityAddItem         ActivityAction = "add_item"
+	ActivitySwitchItem      ActivityAction = "switch_item"
+	// ActivityReplySuggested is a ReplyMessage call held for approval in safe mode
+	ActivityReplySuggested ActivityAction = "reply_suggested"
 )
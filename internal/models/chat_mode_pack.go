@@ -0,0 +1,12 @@
+package models
+
+// ChatModePack is a curated bundle of chat modes for one vertical, shipped
+// with the service and installed as global chat modes on startup
+type ChatModePack struct {
+	Vertical    string `yaml:"vertical" json:"vertical"`
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description"`
+	// DefaultChatMode is the pack mode a seller switches to when picking the vertical
+	DefaultChatMode string     `yaml:"default_chat_mode" json:"default_chat_mode"`
+	ChatModes       []ChatMode `yaml:"chat_modes" json:"-"`
+}
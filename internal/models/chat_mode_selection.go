@@ -0,0 +1,65 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChatModeSource tells which step of the selection picked a chat mode
+type ChatModeSource string
+
+const (
+	ChatModeSourceRequest ChatModeSource = "request"
+	ChatModeSourceChannel ChatModeSource = "channel"
+	ChatModeSourceSeller  ChatModeSource = "seller"
+	ChatModeSourceRule    ChatModeSource = "rule"
+	ChatModeSourceTenant  ChatModeSource = "tenant"
+	ChatModeSourceDefault ChatModeSource = "default"
+)
+
+// ChannelChatMode pins the chat mode of one channel, overriding the seller's choice
+type ChannelChatMode struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	ChatMode  string              `bson:"chat_mode" json:"chat_mode" validate:"required"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// ChatModeRule picks a chat mode for channels whose item matches, e.g. cars
+// and electronics get different modes. Empty conditions match any item.
+type ChatModeRule struct {
+	ChatMode string `bson:"chat_mode" json:"chat_mode" validate:"required"`
+	// Categories are chotot category IDs as sent in the channel metadata
+	Categories []string `bson:"categories,omitempty" json:"categories,omitempty"`
+	// MinPrice and MaxPrice bound the item price, inclusive; 0 leaves a side
+	// open. A rule with a bound never matches an item without a price.
+	MinPrice int64 `bson:"min_price,omitempty" json:"min_price,omitempty"`
+	MaxPrice int64 `bson:"max_price,omitempty" json:"max_price,omitempty"`
+	// Intents limit the rule to buyer messages with one of these labels
+	Intents []MessageIntent `bson:"intents,omitempty" json:"intents,omitempty"`
+}
+
+// Matches reports whether a message of the given intent about an item of the
+// given category and price, 0 when unknown, satisfies the rule
+func (r ChatModeRule) Matches(category string, price int64, intent MessageIntent) bool {
+	if len(r.Categories) > 0 && !slices.Contains(r.Categories, category) {
+		return false
+	}
+	if len(r.Intents) > 0 && !slices.Contains(r.Intents, intent) {
+		return false
+	}
+	if (r.MinPrice > 0 || r.MaxPrice > 0) && price == 0 {
+		return false
+	}
+	if r.MinPrice > 0 && price < r.MinPrice {
+		return false
+	}
+	if r.MaxPrice > 0 && price > r.MaxPrice {
+		return false
+	}
+	return true
+}
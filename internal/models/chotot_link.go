@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ChototLinkStatus string
+
+const (
+	// ChototLinkPending waits for the challenge code to arrive from the chotot account
+	ChototLinkPending  ChototLinkStatus = "pending"
+	ChototLinkVerified ChototLinkStatus = "verified"
+	ChototLinkRevoked  ChototLinkStatus = "revoked"
+)
+
+// ChototLink tracks a user's claim on a chotot account. The chotot_id and
+// chotot_oid attributes are only stored once the link is verified.
+type ChototLink struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	ChototID  string              `bson:"chotot_id" json:"chotot_id"`
+	ChototOID string              `bson:"chotot_oid" json:"chotot_oid"`
+	Status    ChototLinkStatus    `bson:"status" json:"status"`
+	// CodeHash is the SHA-256 of the pending challenge code
+	CodeHash string `bson:"code_hash,omitempty" json:"-"`
+	// Code is the challenge the seller sends from the chotot account. It is
+	// only returned when the link is started and never stored.
+	Code          string     `bson:"-" json:"code,omitempty"`
+	CodeExpiresAt *time.Time `bson:"code_expires_at,omitempty" json:"code_expires_at,omitempty"`
+	VerifiedAt    *time.Time `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+	RevokedAt     *time.Time `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt     time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+}
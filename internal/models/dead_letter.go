@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DeadLetterStatus string
+
+const (
+	// DeadLetterPending letters wait to be requeued
+	DeadLetterPending DeadLetterStatus = "pending"
+	// DeadLetterRequeuing letters are being processed again
+	DeadLetterRequeuing DeadLetterStatus = "requeuing"
+	// DeadLetterResolved letters were processed by a requeue
+	DeadLetterResolved DeadLetterStatus = "resolved"
+)
+
+// DeadLetter is a consumed event whose processing kept failing, kept with
+// its payload so it can be requeued once the cause is fixed
+type DeadLetter struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Topic     string              `bson:"topic" json:"topic"`
+	Partition int                 `bson:"partition" json:"partition"`
+	Offset    int64               `bson:"offset" json:"offset"`
+	Key       string              `bson:"key,omitempty" json:"key,omitempty"`
+	// Payload is the raw event
+	Payload   string           `bson:"payload" json:"payload"`
+	ChannelID string           `bson:"channel_id,omitempty" json:"channel_id,omitempty"`
+	Status    DeadLetterStatus `bson:"status" json:"status"`
+	// Error is the error of the last attempt
+	Error string `bson:"error" json:"error"`
+	// Attempts counts every processing of the event, requeues included
+	Attempts   int        `bson:"attempts" json:"attempts"`
+	ResolvedAt *time.Time `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	ExpiresAt  time.Time  `bson:"expires_at" json:"-"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// DeadLetterFilter narrows the dead letter list, zero values match everything
+type DeadLetterFilter struct {
+	Status    DeadLetterStatus
+	ChannelID string
+	Limit     int
+}
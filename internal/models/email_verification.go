@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailVerification is a pending proof that a user owns their email. The
+// email carries both a code to type in and a link to open; either verifies
+// the address. Only their hashes are stored.
+type EmailVerification struct {
+	ID       primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	UserID   primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	// Email is the address the code was sent to, so a changed address
+	// cannot be verified with an old code
+	Email     string `bson:"email" json:"email"`
+	CodeHash  string `bson:"code_hash" json:"-"`
+	TokenHash string `bson:"token_hash" json:"-"`
+	// Attempts counts the wrong codes entered
+	Attempts  int       `bson:"attempts" json:"attempts"`
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+}
@@ -6,3 +6,93 @@ import (
 )
 
 var ErrNotFound = status.Errorf(codes.NotFound, "not found")
+
+var ErrReservationConflict = status.Errorf(codes.AlreadyExists, "item is already reserved by another buyer")
+
+var ErrQuotaExceeded = status.Errorf(codes.ResourceExhausted, "tenant quota exceeded")
+
+var ErrUnsupportedLLMProvider = status.Errorf(codes.InvalidArgument, "unsupported llm provider")
+
+var ErrInvalidLLMKey = status.Errorf(codes.InvalidArgument, "llm api key was rejected by the provider")
+
+var ErrAttributeConflict = status.Errorf(codes.AlreadyExists, "attribute value is already claimed by another user")
+
+var ErrInvalidImage = status.Errorf(codes.InvalidArgument, "file is not a supported image")
+
+var ErrAvatarUnavailable = status.Errorf(codes.NotFound, "no partner avatar available for user")
+
+var ErrInvalidReplayRequest = status.Errorf(codes.InvalidArgument, "invalid replay request")
+
+var ErrOnboardingStepLocked = status.Errorf(codes.FailedPrecondition, "previous onboarding steps must be completed first")
+
+var ErrChototLinkUnverified = status.Errorf(codes.InvalidArgument, "chotot account could not be verified")
+
+var ErrInvalidWorkingHours = status.Errorf(codes.InvalidArgument, "invalid working hours")
+
+var ErrOnboardingTestFailed = status.Errorf(codes.FailedPrecondition, "chat mode did not reply to the test message")
+
+var ErrChototLinkActive = status.Errorf(codes.FailedPrecondition, "user is already linked to a chotot account, revoke it first")
+
+var ErrProtectedAttribute = status.Errorf(codes.PermissionDenied, "attribute is set by chotot account linking")
+
+var ErrInvalidBackup = status.Errorf(codes.InvalidArgument, "invalid backup archive")
+
+var ErrEmptyPersona = status.Errorf(codes.InvalidArgument, "persona must set at least one field")
+
+var ErrSuggestionDecided = status.Errorf(codes.FailedPrecondition, "suggestion was already approved or rejected")
+
+var ErrNoBuyerMessage = status.Errorf(codes.FailedPrecondition, "channel has no buyer message to reply to")
+
+var ErrScamFlagReviewed = status.Errorf(codes.FailedPrecondition, "scam flag was already reviewed")
+
+var ErrBudgetExceeded = status.Errorf(codes.ResourceExhausted, "bot budget of the channel exceeded")
+
+var ErrLLMUnavailable = status.Errorf(codes.Unavailable, "llm provider unavailable")
+
+var ErrOutcomeSet = status.Errorf(codes.FailedPrecondition, "session outcome was already set")
+
+var ErrJobFinished = status.Errorf(codes.FailedPrecondition, "job already finished")
+
+var ErrUnknownTool = status.Errorf(codes.InvalidArgument, "unknown tool")
+
+var ErrInvalidSimulation = status.Errorf(codes.InvalidArgument, "invalid simulation request")
+
+var ErrTeamExists = status.Errorf(codes.AlreadyExists, "seller already has a team")
+
+var ErrNotTeamMember = status.Errorf(codes.FailedPrecondition, "user is not a member of the team")
+
+var ErrLastTeamOwner = status.Errorf(codes.FailedPrecondition, "team must keep an owner")
+
+var ErrChannelClaimed = status.Errorf(codes.FailedPrecondition, "channel is handled by another agent")
+
+var ErrOutsideReplyWindow = status.Errorf(codes.FailedPrecondition, "partner reply window closed, message not sent")
+
+var ErrTemplateParams = status.Errorf(codes.InvalidArgument, "template uses unknown params")
+
+var ErrUnknownSystemMessage = status.Errorf(codes.InvalidArgument, "unknown system message or locale")
+
+var ErrInvalidSystemMessage = status.Errorf(codes.InvalidArgument, "invalid system message template")
+
+var ErrInvalidParticipants = status.Errorf(codes.FailedPrecondition, "channel participants have no single primary seller")
+
+var ErrInvalidWhitelistEntry = status.Errorf(codes.InvalidArgument, "invalid whitelist entry")
+
+var ErrEmailUnverified = status.Errorf(codes.FailedPrecondition, "email address is not verified")
+
+var ErrEmailVerificationInvalid = status.Errorf(codes.InvalidArgument, "invalid or expired verification code")
+
+var ErrAuthLocked = status.Errorf(codes.ResourceExhausted, "too many failed attempts, locked out")
+
+var ErrCaptchaRequired = status.Errorf(codes.FailedPrecondition, "captcha required after repeated failures")
+
+var ErrInvalidMessageBlock = status.Errorf(codes.InvalidArgument, "invalid message block")
+
+var ErrInvalidCostGroupBy = status.Errorf(codes.InvalidArgument, "group_by must be tenant, seller, chat_mode or model")
+
+var ErrInvalidCostMonth = status.Errorf(codes.InvalidArgument, "month must be formatted as YYYY-MM")
+
+var ErrDeadLetterNotPending = status.Errorf(codes.FailedPrecondition, "dead letter is resolved or being requeued")
+
+var ErrTenantRequired = status.Errorf(codes.PermissionDenied, "an api key or the admin key is required")
+
+var ErrGlobalLLMKey = status.Errorf(codes.InvalidArgument, "seller_id is required without a tenant, the global llm key comes from the config")
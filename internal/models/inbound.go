@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+// Partner payloads are normalized here before the bot acts on them. The
+// payloads they must keep handling are in testdata/inbound.
+
+// ParseChatEvent normalizes a raw chat-api Kafka event. The message is nil for
+// events other than message.sent, whose pattern is returned for logging.
+func ParseChatEvent(payload []byte) (*IncomingMessage, string, error) {
+	var event KafkaMessage
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal kafka message: %w", err)
+	}
+	if event.Pattern != EventMessageSent {
+		return nil, event.Pattern, nil
+	}
+
+	message := IncomingMessage{
+		ChannelID: event.Data.ChannelID,
+		CreatedAt: event.Data.CreatedAt,
+		SenderID:  event.Data.SenderID,
+		Message:   event.Data.Message,
+	}.Normalize()
+	return &message, event.Pattern, nil
+}
+
+// Normalize returns the message with its text normalized and the metadata the
+// bot derives itself cleared, whichever partner it came from
+func (m IncomingMessage) Normalize() IncomingMessage {
+	m.Message = textx.Normalize(m.Message)
+	m.Metadata = IncomingMessageMeta{LLM: m.Metadata.LLM}
+	return m
+}
+
+// NewParticipant normalizes a room member reported by chat-api. Group rooms
+// name the seller the bot replies as in primarySellerID.
+func NewParticipant(userID, role, primarySellerID string) Participant {
+	return Participant{
+		UserID:  userID,
+		Role:    ParseParticipantRole(role),
+		Primary: primarySellerID != "" && userID == primarySellerID,
+	}
+}
@@ -0,0 +1,103 @@
+package models_test
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites the golden files from the current output:
+// go test ./internal/models -run TestInboundNormalization -update
+var update = flag.Bool("update", false, "rewrite the golden files of the inbound fixtures")
+
+// inboundNormalizers turn a raw payload of each inbound source, named after
+// its directory in testdata/inbound, into what the bot acts on
+var inboundNormalizers = map[string]func(payload []byte) (any, error){
+	// chat-api message events consumed from Kafka
+	"chat_api_kafka": func(payload []byte) (any, error) {
+		message, pattern, err := models.ParseChatEvent(payload)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"pattern": pattern, "message": message}, nil
+	},
+	// messages posted to POST /api/v1/messages, bound as echo binds them
+	"http_api": func(payload []byte) (any, error) {
+		var message models.IncomingMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return nil, err
+		}
+		return message.Normalize(), nil
+	},
+	// room members as chat-api lists them for a channel
+	"chat_api_members": func(payload []byte) (any, error) {
+		var room struct {
+			PrimarySellerID string `json:"primary_seller_id"`
+			Members         []struct {
+				UserID string `json:"user_id"`
+				Role   string `json:"role"`
+			} `json:"members"`
+		}
+		if err := json.Unmarshal(payload, &room); err != nil {
+			return nil, err
+		}
+
+		info := &models.ChannelInfo{}
+		for _, member := range room.Members {
+			info.Participants = append(info.Participants, models.NewParticipant(member.UserID, member.Role, room.PrimarySellerID))
+		}
+		result := map[string]any{"participants": info.Participants}
+		if err := info.Validate(); err != nil {
+			result["invalid"] = err.Error()
+		}
+		return result, nil
+	},
+}
+
+func TestInboundNormalization(t *testing.T) {
+	t.Parallel()
+
+	sources, err := os.ReadDir(filepath.Join("testdata", "inbound"))
+	require.NoError(t, err)
+
+	for _, source := range sources {
+		normalize, ok := inboundNormalizers[source.Name()]
+		require.True(t, ok, "no normalizer for inbound source %s", source.Name())
+
+		fixtures, err := filepath.Glob(filepath.Join("testdata", "inbound", source.Name(), "*.json"))
+		require.NoError(t, err)
+
+		for _, fixture := range fixtures {
+			if strings.HasSuffix(fixture, ".golden.json") {
+				continue
+			}
+			name := source.Name() + "/" + strings.TrimSuffix(filepath.Base(fixture), ".json")
+			t.Run(name, func(t *testing.T) {
+				payload, err := os.ReadFile(fixture)
+				require.NoError(t, err)
+
+				result, err := normalize(payload)
+				if err != nil {
+					result = map[string]string{"error": err.Error()}
+				}
+				got, err := json.MarshalIndent(result, "", "  ")
+				require.NoError(t, err)
+
+				golden := strings.TrimSuffix(fixture, ".json") + ".golden.json"
+				if *update {
+					require.NoError(t, os.WriteFile(golden, append(got, '\n'), 0o644))
+				}
+				want, err := os.ReadFile(golden)
+				require.NoError(t, err, "missing golden file, run with -update to create it")
+				assert.JSONEq(t, string(want), string(got))
+			})
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package models
+
+// MessageIntent labels what a buyer message is about
+type MessageIntent string
+
+const (
+	MessageIntentPriceNegotiation MessageIntent = "price_negotiation"
+	MessageIntentAvailability     MessageIntent = "availability"
+	MessageIntentShipping         MessageIntent = "shipping"
+	MessageIntentComplaint        MessageIntent = "complaint"
+	MessageIntentSpam             MessageIntent = "spam"
+	// MessageIntentOther labels messages no rule matches
+	MessageIntentOther MessageIntent = "other"
+)
+
+// IntentRule labels messages containing any of its keywords. Keywords are
+// matched on whole words, ignoring case and Vietnamese accents.
+type IntentRule struct {
+	Intent   MessageIntent `yaml:"intent"`
+	Keywords []string      `yaml:"keywords"`
+}
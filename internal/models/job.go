@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobType names what a job runs
+type JobType string
+
+const (
+	JobAbandonInactiveSessions JobType = "abandon_inactive_sessions"
+	JobReassignChatMode        JobType = "reassign_chat_mode"
+	JobRequeueDeadLetters      JobType = "requeue_dead_letters"
+)
+
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Finished reports whether the job stopped for good
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// MaxJobErrors bounds the errors kept on a job, later ones are only counted
+// in its progress
+const MaxJobErrors = 20
+
+// JobProgress counts the items a job went through
+type JobProgress struct {
+	// Total is 0 until the job knows how many items it has
+	Total  int64 `bson:"total" json:"total"`
+	Done   int64 `bson:"done" json:"done"`
+	Failed int64 `bson:"failed" json:"failed"`
+}
+
+// Job is a long-running operation run in the background by a worker. A
+// running job whose worker stops sending heartbeats is picked up again by
+// another one, so job handlers must be safe to rerun.
+type Job struct {
+	ID       primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Type     JobType             `bson:"type" json:"type"`
+	Params   map[string]any      `bson:"params,omitempty" json:"params,omitempty"`
+	Status   JobStatus           `bson:"status" json:"status"`
+	Progress JobProgress         `bson:"progress" json:"progress"`
+	Errors   []string            `bson:"errors,omitempty" json:"errors,omitempty"`
+	// CancelRequested stops a running job at its worker's next heartbeat
+	CancelRequested bool       `bson:"cancel_requested,omitempty" json:"cancel_requested,omitempty"`
+	WorkerID        string     `bson:"worker_id,omitempty" json:"worker_id,omitempty"`
+	Attempts        int        `bson:"attempts" json:"attempts"`
+	HeartbeatAt     *time.Time `bson:"heartbeat_at,omitempty" json:"heartbeat_at,omitempty"`
+	StartedAt       *time.Time `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	FinishedAt      *time.Time `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// JobFilter narrows the job list, zero values match everything
+type JobFilter struct {
+	TenantID *primitive.ObjectID
+	Type     JobType
+	Status   JobStatus
+	Limit    int
+}
+
+// AbandonInactiveJobParams are the params of JobAbandonInactiveSessions
+type AbandonInactiveJobParams struct {
+	InactiveDays int `json:"inactive_days"`
+}
+
+// ReassignChatModeJobParams are the params of JobReassignChatMode
+type ReassignChatModeJobParams struct {
+	SellerID string `json:"seller_id"`
+	ChatMode string `json:"chat_mode"`
+}
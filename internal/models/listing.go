@@ -0,0 +1,21 @@
+package models
+
+// ListingCard is the structured form of a chotot listing referenced by URL in a message
+type ListingCard struct {
+	ListID      string `json:"list_id"`
+	Title       string `json:"title"`
+	Price       int    `json:"price"`
+	PriceString string `json:"price_string"`
+	ImageURL    string `json:"image_url,omitempty"`
+	Location    string `json:"location,omitempty"`
+	SellerOID   string `json:"seller_oid,omitempty"`
+	URL         string `json:"url"`
+}
+
+const MessageBlockProductCard = "product_card"
+
+// MessageBlock is rich content rendered by clients alongside the message text
+type MessageBlock struct {
+	Type    string       `json:"type"`
+	Product *ListingCard `json:"product,omitempty"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LLMCost counts the tokens a seller's sessions of one chat mode and model
+// spent in a month, and their estimated cost
+type LLMCost struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID     *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Month        string              `bson:"month" json:"month"`
+	SellerID     string              `bson:"seller_id" json:"seller_id"`
+	ChatMode     string              `bson:"chat_mode" json:"chat_mode"`
+	Model        string              `bson:"model" json:"model"`
+	Generations  int                 `bson:"generations" json:"generations"`
+	InputTokens  int                 `bson:"input_tokens" json:"input_tokens"`
+	OutputTokens int                 `bson:"output_tokens" json:"output_tokens"`
+	CostUSD      float64             `bson:"cost_usd" json:"cost_usd"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt    time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// CostGroupBy is the dimension a cost report is broken down by
+type CostGroupBy string
+
+const (
+	CostGroupByTenant   CostGroupBy = "tenant"
+	CostGroupBySeller   CostGroupBy = "seller"
+	CostGroupByChatMode CostGroupBy = "chat_mode"
+	CostGroupByModel    CostGroupBy = "model"
+)
+
+// Field is the LLMCost field the dimension groups on
+func (g CostGroupBy) Field() (string, bool) {
+	switch g {
+	case CostGroupByTenant:
+		return "tenant_id", true
+	case CostGroupBySeller:
+		return "seller_id", true
+	case CostGroupByChatMode:
+		return "chat_mode", true
+	case CostGroupByModel:
+		return "model", true
+	}
+	return "", false
+}
+
+// CostBreakdown sums the costs of one value of the report's dimension. Key
+// is the tenant ID, seller ID, chat mode or model.
+type CostBreakdown struct {
+	Key          string  `bson:"key" json:"key"`
+	Generations  int     `bson:"generations" json:"generations"`
+	InputTokens  int     `bson:"input_tokens" json:"input_tokens"`
+	OutputTokens int     `bson:"output_tokens" json:"output_tokens"`
+	CostUSD      float64 `bson:"cost_usd" json:"cost_usd"`
+}
+
+// CostReport breaks a month's estimated LLM costs down by one dimension,
+// most expensive first
+type CostReport struct {
+	Month    string          `json:"month"`
+	GroupBy  CostGroupBy     `json:"group_by"`
+	TotalUSD float64         `json:"total_usd"`
+	Rows     []CostBreakdown `json:"rows"`
+}
+
+// CostMonth returns the month costs of t are counted under
+func CostMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type LLMProvider string
+
+const (
+	LLMProviderGoogleAI LLMProvider = "googleai"
+)
+
+// LLMKey is a provider API key owned by a tenant, optionally narrowed to one seller.
+// A seller key takes precedence over the tenant key, which takes precedence over
+// the global key from config.
+type LLMKey struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID     *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SellerID     string              `bson:"seller_id" json:"seller_id,omitempty"`
+	Provider     LLMProvider         `bson:"provider" json:"provider"`
+	EncryptedKey string              `bson:"encrypted_key" json:"-"`
+	// Hint holds the last characters of the key so operators can tell keys apart
+	Hint      string    `bson:"hint" json:"hint"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
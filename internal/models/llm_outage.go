@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LLMOutageOutcome is how a message was answered once its chat mode's model
+// failed every retry
+type LLMOutageOutcome string
+
+const (
+	// LLMOutageFallback means the fallback model answered instead
+	LLMOutageFallback LLMOutageOutcome = "fallback"
+	// LLMOutageAcknowledged means the buyer got the canned acknowledgment
+	LLMOutageAcknowledged LLMOutageOutcome = "acknowledged"
+	// LLMOutageFailed means the buyer got nothing from the bot
+	LLMOutageFailed LLMOutageOutcome = "failed"
+)
+
+// LLMOutage records a generation the chat mode's model could not serve
+type LLMOutage struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID string              `bson:"session_id" json:"session_id"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	ChatMode  string              `bson:"chat_mode" json:"chat_mode"`
+	Model     string              `bson:"model" json:"model"`
+	// FallbackModel is empty when no fallback model is configured
+	FallbackModel string           `bson:"fallback_model,omitempty" json:"fallback_model,omitempty"`
+	Outcome       LLMOutageOutcome `bson:"outcome" json:"outcome"`
+	// Error is the last error of the chat mode's model
+	Error     string    `bson:"error" json:"error"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time `bson:"expires_at" json:"-"`
+}
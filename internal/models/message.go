@@ -1,6 +1,14 @@
 package models
 
-import "time"
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// EventMessageSent is the chat-api event of a new message, the only one the
+// bot consumes
+const EventMessageSent = "message.sent"
 
 // KafkaMessage represents the top-level Kafka message structure
 type KafkaMessage struct {
@@ -37,16 +45,35 @@ type IncomingMessage struct {
 
 type IncomingMessageMeta struct {
 	LLM LLMMetadata `json:"llm"`
+	// Listings are resolved from chotot URLs in the message, not sent by the client
+	Listings []ListingCard `json:"listings,omitempty"`
+	// Intent is classified from the message, not sent by the client
+	Intent MessageIntent `json:"intent,omitempty"`
+	// Sentiment is scored from the message, not sent by the client
+	Sentiment *SentimentScore `json:"sentiment,omitempty"`
+	// Language is detected from the message, not sent by the client
+	Language string `json:"language,omitempty"`
 }
 
 type LLMMetadata struct {
-	ChatMode string `json:"chat_mode" validate:"required"`
+	// ChatMode forces the chat mode; empty lets the chat mode selector pick it
+	ChatMode string `json:"chat_mode,omitempty"`
+	// DryTools makes the session's tools return fixtures instead of running,
+	// so chat modes can be tried without side effects
+	DryTools bool `json:"dry_tools,omitempty"`
+	// ToolFixtures override the default fixtures of dry tools, by tool name
+	ToolFixtures map[string]any `json:"tool_fixtures,omitempty" validate:"omitempty,maxdepth=8,maxjson=16384"`
 }
 
 type OutgoingMessage struct {
 	ChannelID string `json:"channel_id" validate:"required"`
 	SenderID  string `json:"sender_id" validate:"required"`
 	Message   string `json:"message" validate:"required"`
+	// Template is set when Message was rendered from a template, because the
+	// partner's reply window closed
+	Template *TemplateMessage `json:"template,omitempty"`
+	// Blocks are rendered or downgraded to text depending on the partner
+	Blocks []MessageBlock `json:"blocks,omitempty"`
 }
 
 type ChannelInfo struct {
@@ -54,13 +81,26 @@ type ChannelInfo struct {
 	Name         string        `json:"name"`
 	ItemName     string        `json:"item_name"`
 	ItemPrice    string        `json:"item_price"`
+	ItemCategory string        `json:"item_category,omitempty"`
 	Context      string        `json:"context"`
 	Participants []Participant `json:"participants"`
 }
 
 type Participant struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID  string          `json:"user_id"`
+	Role    ParticipantRole `json:"role"`
+	Profile *UserProfile    `json:"profile,omitempty"`
+	// Primary marks the seller the bot replies as when the room has several
+	Primary bool `json:"primary,omitempty"`
+}
+
+// UserProfile is the display information of a chat-api user, resolved through
+// the internal user linked by the chotot_id attribute
+type UserProfile struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	Name      string `json:"name"`
+	AvatarURL string `json:"avatar_url,omitempty"`
 }
 
 type MessageHistory struct {
@@ -69,9 +109,45 @@ type MessageHistory struct {
 }
 
 type HistoryMessage struct {
-	ID        string    `json:"id"`
-	ChannelID string    `json:"channel_id"`
-	SenderID  string    `json:"sender_id"`
-	Message   string    `json:"message"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        string         `json:"id"`
+	ChannelID string         `json:"channel_id"`
+	SenderID  string         `json:"sender_id"`
+	Sender    *UserProfile   `json:"sender,omitempty"`
+	Message   string         `json:"message"`
+	Blocks    []MessageBlock `json:"blocks,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// Outbound records whether handling a message already had an effect a retry
+// would repeat, such as a sent reply or a reserved item. Every method is safe
+// on a nil *Outbound.
+type Outbound struct {
+	done atomic.Bool
+}
+
+type outboundCtxKey struct{}
+
+// WithOutbound returns a context whose tools record their outbound effects
+// into a fresh Outbound
+func WithOutbound(ctx context.Context) (context.Context, *Outbound) {
+	outbound := &Outbound{}
+	return context.WithValue(ctx, outboundCtxKey{}, outbound), outbound
+}
+
+// OutboundFromContext returns the Outbound of ctx, or nil when ctx records none
+func OutboundFromContext(ctx context.Context) *Outbound {
+	outbound, _ := ctx.Value(outboundCtxKey{}).(*Outbound)
+	return outbound
+}
+
+// Mark records an outbound effect
+func (o *Outbound) Mark() {
+	if o != nil {
+		o.done.Store(true)
+	}
+}
+
+// Done reports whether an outbound effect was recorded
+func (o *Outbound) Done() bool {
+	return o != nil && o.done.Load()
 }
@@ -0,0 +1,90 @@
+package models
+
+import (
+	"slices"
+	"strings"
+)
+
+// BlockCapabilities lists the block types a partner's clients render
+type BlockCapabilities struct {
+	Partner    string   `json:"partner"`
+	BlockTypes []string `json:"block_types"`
+}
+
+// Renders reports whether the partner's clients render blocks of blockType
+func (c BlockCapabilities) Renders(blockType string) bool {
+	return slices.Contains(c.BlockTypes, blockType)
+}
+
+// RenderedMessage is a message with blocks as it is sent to one partner
+type RenderedMessage struct {
+	Partner string `json:"partner"`
+	Message string `json:"message"`
+	// Blocks are the blocks the partner renders itself
+	Blocks []MessageBlock `json:"blocks,omitempty"`
+	// Downgraded lists the types of the blocks sent as text instead
+	Downgraded []string `json:"downgraded,omitempty"`
+}
+
+// Validate reports whether the block carries the content its type needs
+func (b MessageBlock) Validate() error {
+	switch b.Type {
+	case MessageBlockProductCard:
+		if b.Product == nil || b.Product.URL == "" || b.Product.Title == "" {
+			return ErrInvalidMessageBlock
+		}
+		return nil
+	default:
+		return ErrInvalidMessageBlock
+	}
+}
+
+// Summary is the plain text a block is downgraded to for partners that do not
+// render it. A product card becomes its title and price, location, link and
+// image URL, one per line.
+func (b MessageBlock) Summary() string {
+	if b.Type != MessageBlockProductCard || b.Product == nil {
+		return ""
+	}
+	p := b.Product
+	headline := p.Title
+	if p.PriceString != "" {
+		headline += " - " + p.PriceString
+	}
+	lines := []string{headline}
+	for _, line := range []string{p.Location, p.URL, p.ImageURL} {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderBlocks folds the blocks of a message into what caps.Partner can show.
+// Product cards the partner renders are sent as their listing URL, which
+// clients expand into a card; other blocks are appended as their Summary.
+func RenderBlocks(caps BlockCapabilities, text string, blocks []MessageBlock) RenderedMessage {
+	rendered := RenderedMessage{Partner: caps.Partner, Message: text}
+	for _, block := range blocks {
+		if caps.Renders(block.Type) {
+			rendered.Blocks = append(rendered.Blocks, block)
+			if block.Product != nil && !strings.Contains(rendered.Message, block.Product.URL) {
+				rendered.Message = appendParagraph(rendered.Message, block.Product.URL)
+			}
+			continue
+		}
+		rendered.Downgraded = append(rendered.Downgraded, block.Type)
+		rendered.Message = appendParagraph(rendered.Message, block.Summary())
+	}
+	return rendered
+}
+
+func appendParagraph(text, paragraph string) string {
+	if paragraph == "" {
+		return text
+	}
+	if text == "" {
+		return paragraph
+	}
+	return text + "\n\n" + paragraph
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type OnboardingStep string
+
+const (
+	OnboardingStepLinkChotot       OnboardingStep = "link_chotot"
+	OnboardingStepChatMode         OnboardingStep = "chat_mode"
+	OnboardingStepWorkingHours     OnboardingStep = "working_hours"
+	OnboardingStepTestConversation OnboardingStep = "test_conversation"
+	OnboardingStepCompleted        OnboardingStep = "completed"
+)
+
+// OnboardingSteps are the steps a new seller goes through, in order
+var OnboardingSteps = []OnboardingStep{
+	OnboardingStepLinkChotot,
+	OnboardingStepChatMode,
+	OnboardingStepWorkingHours,
+	OnboardingStepTestConversation,
+}
+
+// Onboarding tracks a seller's progress through the onboarding steps. Step
+// is the first step not completed yet; completed steps may be redone.
+type Onboarding struct {
+	ID               primitive.ObjectID          `bson:"_id,omitempty" json:"id"`
+	TenantID         *primitive.ObjectID         `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	UserID           primitive.ObjectID          `bson:"user_id" json:"user_id"`
+	Step             OnboardingStep              `bson:"step" json:"step"`
+	CompletedSteps   []OnboardingStep            `bson:"completed_steps" json:"completed_steps"`
+	ChototID         string                      `bson:"chotot_id,omitempty" json:"chotot_id,omitempty"`
+	ChototOID        string                      `bson:"chotot_oid,omitempty" json:"chotot_oid,omitempty"`
+	ChatMode         string                      `bson:"chat_mode,omitempty" json:"chat_mode,omitempty"`
+	WorkingHours     *WorkingHours               `bson:"working_hours,omitempty" json:"working_hours,omitempty"`
+	TestConversation *OnboardingTestConversation `bson:"test_conversation,omitempty" json:"test_conversation,omitempty"`
+	CreatedAt        time.Time                   `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time                   `bson:"updated_at" json:"updated_at"`
+	CompletedAt      *time.Time                  `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+// IsCompleted reports whether step has been completed
+func (o *Onboarding) IsCompleted(step OnboardingStep) bool {
+	for _, completed := range o.CompletedSteps {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkingHours are the hours a seller answers buyers, in their own timezone
+type WorkingHours struct {
+	// Timezone is an IANA name such as Asia/Ho_Chi_Minh
+	Timezone string             `bson:"timezone" json:"timezone" validate:"required"`
+	Days     []WorkingHoursSlot `bson:"days" json:"days" validate:"required,min=1,dive"`
+}
+
+type WorkingHoursSlot struct {
+	// Weekday is 0 for Sunday through 6 for Saturday
+	Weekday time.Weekday `bson:"weekday" json:"weekday" validate:"min=0,max=6"`
+	// Start and End are HH:MM wall clock times, End after Start
+	Start string `bson:"start" json:"start" validate:"required"`
+	End   string `bson:"end" json:"end" validate:"required"`
+}
+
+// OnboardingTestConversation is the sample exchange generated with the
+// chosen chat mode
+type OnboardingTestConversation struct {
+	ChannelID    string `bson:"channel_id,omitempty" json:"channel_id,omitempty"`
+	BuyerMessage string `bson:"buyer_message" json:"buyer_message"`
+	Reply        string `bson:"reply" json:"reply"`
+	// ToolCalls are the other tools the bot would have called, as Name(input)
+	ToolCalls []string `bson:"tool_calls,omitempty" json:"tool_calls,omitempty"`
+	// Posted is set when the exchange was sent to the sandbox channel
+	Posted bool      `bson:"posted" json:"posted"`
+	At     time.Time `bson:"at" json:"at"`
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SessionOutcome is how the conversation of a session ended for the seller
+type SessionOutcome string
+
+const (
+	SessionOutcomeSold       SessionOutcome = "sold"
+	SessionOutcomeLost       SessionOutcome = "lost"
+	SessionOutcomeNoResponse SessionOutcome = "no_response"
+	SessionOutcomeHandedOff  SessionOutcome = "handed_off"
+)
+
+func (o SessionOutcome) Valid() bool {
+	switch o {
+	case SessionOutcomeSold, SessionOutcomeLost, SessionOutcomeNoResponse, SessionOutcomeHandedOff:
+		return true
+	}
+	return false
+}
+
+// OutcomeSource is who set a session outcome
+type OutcomeSource string
+
+const (
+	// OutcomeSourceTool is the bot, with the SetOutcome tool
+	OutcomeSourceTool OutcomeSource = "tool"
+	// OutcomeSourceSeller is the seller API, which overrides any other source
+	OutcomeSourceSeller OutcomeSource = "seller"
+	// OutcomeSourceHeuristic covers buyers that stopped answering and
+	// channels handed off to the seller
+	OutcomeSourceHeuristic OutcomeSource = "heuristic"
+)
+
+// SessionOutcomeRecord is the outcome of a session with what led to it
+type SessionOutcomeRecord struct {
+	Outcome SessionOutcome `bson:"outcome" json:"outcome"`
+	Source  OutcomeSource  `bson:"source" json:"source"`
+	Reason  string         `bson:"reason,omitempty" json:"reason,omitempty"`
+	// OrderID is the seller's reference of the order, when they gave one
+	OrderID string `bson:"order_id,omitempty" json:"order_id,omitempty"`
+	// PurchaseIntentIDs and ReservationIDs are those of the session's channel
+	// when the outcome was set
+	PurchaseIntentIDs []primitive.ObjectID `bson:"purchase_intent_ids,omitempty" json:"purchase_intent_ids,omitempty"`
+	ReservationIDs    []primitive.ObjectID `bson:"reservation_ids,omitempty" json:"reservation_ids,omitempty"`
+	At                time.Time            `bson:"at" json:"at"`
+}
+
+// ConversionReport attributes session outcomes to the chat mode and prompt
+// version of the session they were set on
+type ConversionReport struct {
+	Since time.Time       `json:"since"`
+	Until time.Time       `json:"until"`
+	Rows  []ConversionRow `json:"rows"`
+}
+
+type ConversionRow struct {
+	ChatMode      string `json:"chat_mode"`
+	PromptVersion string `json:"prompt_version"`
+	// Sessions counts the sessions with an outcome
+	Sessions   int64 `json:"sessions"`
+	Sold       int64 `json:"sold"`
+	Lost       int64 `json:"lost"`
+	NoResponse int64 `json:"no_response"`
+	HandedOff  int64 `json:"handed_off"`
+	// ConversionRate is Sold over Sessions
+	ConversionRate float64 `json:"conversion_rate"`
+}
+
+// ConversionCount is the number of sessions of a chat mode and prompt
+// version that ended with an outcome
+type ConversionCount struct {
+	ChatMode      string         `bson:"chat_mode"`
+	PromptVersion string         `bson:"prompt_version"`
+	Outcome       SessionOutcome `bson:"outcome"`
+	Count         int64          `bson:"count"`
+}
+
+// PromptVersion identifies a prompt template, so outcomes can be compared
+// across edits of a chat mode
+func PromptVersion(template string) string {
+	sum := sha256.Sum256([]byte(template))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,96 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParticipantRole is the role of a chat-api channel participant
+type ParticipantRole string
+
+const (
+	ParticipantRoleBuyer  ParticipantRole = "buyer"
+	ParticipantRoleSeller ParticipantRole = "seller"
+	// ParticipantRoleUnknown is any role chat-api reports that the bot does
+	// not act on
+	ParticipantRoleUnknown ParticipantRole = "unknown"
+)
+
+// ParseParticipantRole maps a chat-api role to a ParticipantRole
+func ParseParticipantRole(role string) ParticipantRole {
+	switch r := ParticipantRole(strings.ToLower(strings.TrimSpace(role))); r {
+	case ParticipantRoleBuyer, ParticipantRoleSeller:
+		return r
+	default:
+		return ParticipantRoleUnknown
+	}
+}
+
+// Participant returns the participant with the user ID, nil when the user is
+// not in the channel
+func (c *ChannelInfo) Participant(userID string) *Participant {
+	if c == nil {
+		return nil
+	}
+	for i := range c.Participants {
+		if c.Participants[i].UserID == userID {
+			return &c.Participants[i]
+		}
+	}
+	return nil
+}
+
+// PrimarySeller returns the seller the bot replies as: the one marked
+// primary, or the only seller of the channel. It is nil when the channel has
+// no seller, or several and none marked primary.
+func (c *ChannelInfo) PrimarySeller() *Participant {
+	if c == nil {
+		return nil
+	}
+	var only *Participant
+	sellers := 0
+	for i := range c.Participants {
+		p := &c.Participants[i]
+		if p.Role != ParticipantRoleSeller {
+			continue
+		}
+		if p.Primary {
+			return p
+		}
+		sellers++
+		only = p
+	}
+	if sellers != 1 {
+		return nil
+	}
+	return only
+}
+
+// Validate checks the channel has a single primary seller and that user IDs
+// are unique. Group rooms may have any number of buyers and sellers.
+func (c *ChannelInfo) Validate() error {
+	seen := make(map[string]bool, len(c.Participants))
+	primaries := 0
+	for _, p := range c.Participants {
+		if p.UserID == "" {
+			return fmt.Errorf("%w: participant without user ID", ErrInvalidParticipants)
+		}
+		if seen[p.UserID] {
+			return fmt.Errorf("%w: user %s is listed twice", ErrInvalidParticipants, p.UserID)
+		}
+		seen[p.UserID] = true
+		if p.Primary {
+			if p.Role != ParticipantRoleSeller {
+				return fmt.Errorf("%w: primary participant %s is a %s", ErrInvalidParticipants, p.UserID, p.Role)
+			}
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		return fmt.Errorf("%w: %d sellers marked primary", ErrInvalidParticipants, primaries)
+	}
+	if c.PrimarySeller() == nil {
+		return ErrInvalidParticipants
+	}
+	return nil
+}
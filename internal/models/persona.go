@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Persona is who the bot says it is, shared by every chat mode. A tenant-wide
+// persona has no seller; a seller's persona overrides it field by field.
+type Persona struct {
+	ID       primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SellerID string              `bson:"seller_id" json:"seller_id,omitempty"`
+	// DisplayName is the name the bot introduces itself with
+	DisplayName string `bson:"display_name,omitempty" json:"display_name,omitempty"`
+	// Tone is free text guidance, e.g. "friendly, short sentences, no emoji"
+	Tone string `bson:"tone,omitempty" json:"tone,omitempty"`
+	// Languages the bot may answer in, most preferred first
+	Languages []string `bson:"languages,omitempty" json:"languages,omitempty"`
+	// ForbiddenTopics the bot must decline to discuss
+	ForbiddenTopics []string  `bson:"forbidden_topics,omitempty" json:"forbidden_topics,omitempty"`
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// IsEmpty reports whether the persona sets nothing
+func (p Persona) IsEmpty() bool {
+	return p.DisplayName == "" && p.Tone == "" && len(p.Languages) == 0 && len(p.ForbiddenTopics) == 0
+}
+
+// Override returns p with the fields set in other taking precedence
+func (p Persona) Override(other *Persona) Persona {
+	if other == nil {
+		return p
+	}
+	if other.DisplayName != "" {
+		p.DisplayName = other.DisplayName
+	}
+	if other.Tone != "" {
+		p.Tone = other.Tone
+	}
+	if len(other.Languages) > 0 {
+		p.Languages = other.Languages
+	}
+	if len(other.ForbiddenTopics) > 0 {
+		p.ForbiddenTopics = other.ForbiddenTopics
+	}
+	return p
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PromptLog is one model generation of a sampled session: the rendered
+// prompt sent to the model and what it answered. Secrets and personal data
+// are redacted before it is stored.
+type PromptLog struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChatMode  string              `bson:"chat_mode" json:"chat_mode"`
+	Model     string              `bson:"model" json:"model"`
+	// Iteration is the agent loop iteration the generation belongs to, starting at 1
+	Iteration int                `bson:"iteration" json:"iteration"`
+	Messages  []PromptLogMessage `bson:"messages" json:"messages"`
+	Response  string             `bson:"response,omitempty" json:"response,omitempty"`
+	// ToolRequests are the tool calls of the response, rendered as Name(input)
+	ToolRequests []string  `bson:"tool_requests,omitempty" json:"tool_requests,omitempty"`
+	FinishReason string    `bson:"finish_reason,omitempty" json:"finish_reason,omitempty"`
+	InputTokens  int       `bson:"input_tokens,omitempty" json:"input_tokens,omitempty"`
+	OutputTokens int       `bson:"output_tokens,omitempty" json:"output_tokens,omitempty"`
+	LatencyMs    int64     `bson:"latency_ms" json:"latency_ms"`
+	Error        string    `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt    time.Time `bson:"created_at" json:"created_at"`
+	// ExpiresAt is when MongoDB removes the record
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// PromptLogMessage is a message of a prompt flattened to text
+type PromptLogMessage struct {
+	Role string `bson:"role" json:"role"`
+	Text string `bson:"text" json:"text"`
+}
+
+// PromptLogFilter narrows a prompt log query, zero values match everything
+type PromptLogFilter struct {
+	TenantID  *primitive.ObjectID
+	SessionID *primitive.ObjectID
+	ChatMode  string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelCursor records the newest message of a channel handed to the bot.
+// Every ingestion path claims it before processing, so a message delivered by
+// Kafka, the HTTP API and the reconciler is answered only once.
+type ChannelCursor struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	// BuyerID is the sender of the last claimed message, whose history the
+	// reconciler reads
+	BuyerID  string `bson:"buyer_id" json:"buyer_id"`
+	ChatMode string `bson:"chat_mode" json:"chat_mode"`
+	// LastMessageAt is the chat-api created_at of the last claimed message, in milliseconds
+	LastMessageAt int64 `bson:"last_message_at" json:"last_message_at"`
+	// PreviousMessageAt is set by a successful claim to the LastMessageAt it
+	// moved from, which releasing the claim restores
+	PreviousMessageAt int64     `bson:"-" json:"-"`
+	UpdatedAt         time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// ReconcileReport summarizes one reconciliation run
+type ReconcileReport struct {
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt time.Time           `json:"finished_at"`
+	Channels   int                 `json:"channels"`
+	Backfilled []ReconciledMessage `json:"backfilled"`
+	Failed     []ReconciledMessage `json:"failed"`
+}
+
+type ReconciledMessage struct {
+	ChannelID string `json:"channel_id"`
+	SenderID  string `json:"sender_id,omitempty"`
+	CreatedAt int64  `json:"created_at,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
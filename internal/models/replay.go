@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+type ReplayProvider string
+
+const (
+	// ReplayProviderRecorded answers with the model turns recorded in the
+	// transcript, dropping calls to tools the candidate does not offer. It
+	// costs nothing and checks conditions, prompt rendering and tool sets.
+	ReplayProviderRecorded ReplayProvider = "recorded"
+	// ReplayProviderLive asks the candidate's real model
+	ReplayProviderLive ReplayProvider = "live"
+)
+
+// ReplayRequest evaluates a candidate chat mode against logged sessions
+type ReplayRequest struct {
+	// ChatMode is the stored chat mode the candidate starts from
+	ChatMode string `json:"chat_mode" validate:"required"`
+	// PromptTemplate, Condition, Model and Tools override the stored chat mode
+	// so an unsaved prompt version can be evaluated
+	PromptTemplate string   `json:"prompt_template,omitempty"`
+	Condition      *string  `json:"condition,omitempty"`
+	Model          string   `json:"model,omitempty"`
+	Tools          []string `json:"tools,omitempty"`
+
+	// SessionIDs are the sessions to replay. When empty, the most recent
+	// transcripts of SourceChatMode, or of any chat mode, are used.
+	SessionIDs     []string       `json:"session_ids,omitempty" validate:"omitempty,dive,objectid"`
+	SourceChatMode string         `json:"source_chat_mode,omitempty"`
+	Limit          int            `json:"limit,omitempty"`
+	Provider       ReplayProvider `json:"provider,omitempty"`
+}
+
+// ReplayReport compares the tool-call decisions of a candidate chat mode
+// with the ones originally made
+type ReplayReport struct {
+	ChatMode  string         `json:"chat_mode"`
+	Model     string         `json:"model"`
+	Provider  ReplayProvider `json:"provider"`
+	Sessions  int            `json:"sessions"`
+	Changed   int            `json:"changed"`
+	Failed    int            `json:"failed"`
+	Results   []ReplayResult `json:"results"`
+	StartedAt time.Time      `json:"started_at"`
+	Duration  string         `json:"duration"`
+}
+
+// ReplayResult is the outcome of replaying one session. Decisions are
+// compared by tool name; inputs such as reply wording are kept for review.
+type ReplayResult struct {
+	SessionID      string           `json:"session_id"`
+	SourceChatMode string           `json:"source_chat_mode"`
+	Original       []ReplayToolCall `json:"original"`
+	Candidate      []ReplayToolCall `json:"candidate"`
+	// Skipped is set when the candidate's condition rejects the message
+	Skipped bool `json:"skipped,omitempty"`
+	Changed bool `json:"changed"`
+	// Diff lists tools only the original called as "-Name" and tools only the
+	// candidate called as "+Name"
+	Diff  []string `json:"diff,omitempty"`
+	Error string   `json:"error,omitempty"`
+}
+
+type ReplayToolCall struct {
+	Iteration int    `json:"iteration"`
+	Name      string `json:"name"`
+	// Input is the JSON encoded tool input
+	Input string `json:"input"`
+}
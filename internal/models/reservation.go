@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reservation is a short hold placed on a seller's listing on behalf of a buyer
+type Reservation struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID   *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID  primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChannelID  string              `bson:"channel_id" json:"channel_id"`
+	SellerID   string              `bson:"seller_id" json:"seller_id"`
+	BuyerID    string              `bson:"buyer_id" json:"buyer_id"`
+	ItemID     string              `bson:"item_id" json:"item_id"`
+	ItemName   string              `bson:"item_name" json:"item_name"`
+	Status     ReservationStatus   `bson:"status" json:"status"`
+	ExpiresAt  time.Time           `bson:"expires_at" json:"expires_at"`
+	ReleasedAt *time.Time          `bson:"released_at,omitempty" json:"released_at,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type ReservationStatus string
+
+const (
+	ReservationStatusActive   ReservationStatus = "active"
+	ReservationStatusReleased ReservationStatus = "released"
+	ReservationStatusExpired  ReservationStatus = "expired"
+)
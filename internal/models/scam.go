@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScamPattern names the kind of scam a message looks like
+type ScamPattern string
+
+const (
+	// ScamPatternExternalLink flags links outside the allowed domains, e.g.
+	// fake payment or delivery pages
+	ScamPatternExternalLink     ScamPattern = "external_link"
+	ScamPatternOffPlatform      ScamPattern = "off_platform"
+	ScamPatternAdvancePayment   ScamPattern = "advance_payment"
+	ScamPatternVerificationCode ScamPattern = "verification_code"
+	ScamPatternTemplate         ScamPattern = "scam_template"
+)
+
+// ScamRules configure the scam detector. Keywords are matched on whole words,
+// ignoring case and Vietnamese accents, like intent rules.
+type ScamRules struct {
+	// AllowedDomains never flag as external links, subdomains included
+	AllowedDomains []string   `yaml:"allowed_domains"`
+	Patterns       []ScamRule `yaml:"patterns"`
+}
+
+type ScamRule struct {
+	Pattern  ScamPattern `yaml:"pattern"`
+	Keywords []string    `yaml:"keywords"`
+}
+
+// ScamFlag is a buyer message flagged as a likely scam, queued for review by
+// an admin
+type ScamFlag struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	SenderID  string              `bson:"sender_id" json:"sender_id"`
+	Message   string              `bson:"message" json:"message"`
+	Patterns  []ScamPattern       `bson:"patterns" json:"patterns"`
+	Status    ScamFlagStatus      `bson:"status" json:"status"`
+	// Notified is set when the safety notice was posted to the channel
+	Notified   bool       `bson:"notified" json:"notified"`
+	ReviewedAt *time.Time `bson:"reviewed_at,omitempty" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+type ScamFlagStatus string
+
+const (
+	ScamFlagStatusPending   ScamFlagStatus = "pending"
+	ScamFlagStatusConfirmed ScamFlagStatus = "confirmed"
+	ScamFlagStatusDismissed ScamFlagStatus = "dismissed"
+)
+
+// ScamFlagFilter narrows the review queue, zero values match everything
+type ScamFlagFilter struct {
+	TenantID  *primitive.ObjectID
+	Status    ScamFlagStatus
+	ChannelID string
+	Limit     int
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Sentiment string
+
+const (
+	SentimentPositive Sentiment = "positive"
+	SentimentNeutral  Sentiment = "neutral"
+	SentimentNegative Sentiment = "negative"
+)
+
+// SentimentScore rates one buyer message, Score going from -1 to 1
+type SentimentScore struct {
+	Sentiment Sentiment `bson:"sentiment" json:"sentiment"`
+	Score     float64   `bson:"score" json:"score"`
+	At        time.Time `bson:"at" json:"at"`
+}
+
+// ChannelSentiment aggregates the sentiment of the buyer messages of a channel
+type ChannelSentiment struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	BuyerID   string              `bson:"buyer_id" json:"buyer_id"`
+	Messages  int                 `bson:"messages" json:"messages"`
+	Positive  int                 `bson:"positive" json:"positive"`
+	Neutral   int                 `bson:"neutral" json:"neutral"`
+	Negative  int                 `bson:"negative" json:"negative"`
+	// Score is a moving average of the message scores, recent ones weighing more
+	Score float64 `bson:"score" json:"score"`
+	// Trend holds the latest message scores, oldest first
+	Trend []SentimentScore `bson:"trend" json:"trend"`
+	// AlertedAt is set when Score dropped below the alert threshold, until the
+	// seller resumes the channel
+	AlertedAt *time.Time `bson:"alerted_at,omitempty" json:"alerted_at,omitempty"`
+	CreatedAt time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// SentimentLexicon lists the words scoring a message up or down. Words are
+// matched like intent keywords.
+type SentimentLexicon struct {
+	Positive []string `yaml:"positive"`
+	Negative []string `yaml:"negative"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SessionTrace is the summary of one agent loop iteration. Unlike prompt
+// logs it is kept for every session and holds no prompt text, only a hash
+// that tells whether two iterations were given the same prompt.
+type SessionTrace struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChatMode  string              `bson:"chat_mode" json:"chat_mode"`
+	Model     string              `bson:"model" json:"model"`
+	// Iteration is the agent loop iteration, starting at 1
+	Iteration int   `bson:"iteration" json:"iteration"`
+	LatencyMs int64 `bson:"latency_ms" json:"latency_ms"`
+	// PromptHash is a truncated SHA-256 of the prompt sent to the model
+	PromptHash     string `bson:"prompt_hash" json:"prompt_hash"`
+	PromptMessages int    `bson:"prompt_messages" json:"prompt_messages"`
+	// ToolsRequested are the names of the tools the model called
+	ToolsRequested []string  `bson:"tools_requested,omitempty" json:"tools_requested,omitempty"`
+	FinishReason   string    `bson:"finish_reason,omitempty" json:"finish_reason,omitempty"`
+	InputTokens    int       `bson:"input_tokens,omitempty" json:"input_tokens,omitempty"`
+	OutputTokens   int       `bson:"output_tokens,omitempty" json:"output_tokens,omitempty"`
+	Error          string    `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+	// ExpiresAt is when MongoDB removes the record
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
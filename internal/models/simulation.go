@@ -0,0 +1,43 @@
+package models
+
+// SimulationRequest is a conversation to run a chat mode against. The last
+// message is the one the bot answers, the others are its recent history.
+type SimulationRequest struct {
+	Messages []SimulationMessage `json:"messages" validate:"required,min=1,dive"`
+	// ItemName, ItemPrice and Context describe the simulated channel
+	ItemName  string `json:"item_name,omitempty"`
+	ItemPrice string `json:"item_price,omitempty"`
+	Context   string `json:"context,omitempty"`
+	// ToolFixtures override the default results of the dry tools, by tool name
+	ToolFixtures map[string]any `json:"tool_fixtures,omitempty" validate:"omitempty,maxdepth=8,maxjson=16384"`
+}
+
+type SimulationMessage struct {
+	// Role is "buyer" or "seller"
+	Role    string `json:"role" validate:"required,oneof=buyer seller"`
+	Message string `json:"message" validate:"required"`
+}
+
+// SimulationResult is what the chat mode did with a simulated conversation
+type SimulationResult struct {
+	ChatMode string `json:"chat_mode"`
+	Model    string `json:"model"`
+	// Skipped is set when the chat mode's condition rejects the last message
+	Skipped bool `json:"skipped,omitempty"`
+	// Replies are the messages the bot sent through ReplyMessage
+	Replies   []string            `json:"replies"`
+	ToolCalls []SimulatedToolCall `json:"tool_calls"`
+	// Transcript is the full model conversation, secrets redacted
+	Transcript []TranscriptEntry `json:"transcript"`
+	// Error is why the agent loop stopped early, if it did
+	Error string `json:"error,omitempty"`
+}
+
+type SimulatedToolCall struct {
+	Iteration int    `json:"iteration"`
+	Name      string `json:"name"`
+	// Input and Output are JSON encoded
+	Input  string `json:"input"`
+	Output string `json:"output,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
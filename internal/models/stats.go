@@ -0,0 +1,56 @@
+package models
+
+import "github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+
+// Live stats series recorded in livestats.Default
+const (
+	// StatMessagesPrefix is followed by the ingestion source: kafka, http or reconcile
+	StatMessagesPrefix = "messages."
+	StatBotReplies     = "bot_replies"
+	// StatIntentsPrefix is followed by the intent of the buyer message
+	StatIntentsPrefix = "intents."
+	// StatSentimentPrefix is followed by the sentiment of the buyer message
+	StatSentimentPrefix = "sentiment."
+	StatSentimentAlerts = "sentiment_alerts"
+	StatScamFlags       = "scam_flags"
+	// StatLLMGenerate observes the latency in milliseconds of each model generation
+	StatLLMGenerate = "llm.generate"
+	StatLLMErrors   = "llm.errors"
+	// StatLLMOutagesPrefix is followed by the outcome of the outage
+	StatLLMOutagesPrefix = "llm.outages."
+	// StatReplyWindowPrefix is followed by what happened to a message sent
+	// outside a partner's reply window: template or blocked
+	StatReplyWindowPrefix = "reply_window."
+)
+
+// LiveStats is the rolling one-minute view of the service
+type LiveStats struct {
+	WindowSeconds int                       `json:"window_seconds"`
+	Messages      map[string]livestats.Stat `json:"messages"`
+	Intents       map[string]livestats.Stat `json:"intents"`
+	Sentiment     map[string]livestats.Stat `json:"sentiment"`
+	// SentimentAlerts counts channels alerted for negative buyer sentiment
+	SentimentAlerts livestats.Stat              `json:"sentiment_alerts"`
+	ScamFlags       livestats.Stat              `json:"scam_flags"`
+	BotReplies      livestats.Stat              `json:"bot_replies"`
+	LLM             LiveLLMStats                `json:"llm"`
+	Partners        map[string]LivePartnerStats `json:"partners"`
+	// ReplyWindow counts messages sent outside a partner's reply window, by
+	// what happened to them
+	ReplyWindow map[string]livestats.Stat `json:"reply_window"`
+}
+
+type LiveLLMStats struct {
+	Generations  livestats.Stat `json:"generations"`
+	Errors       livestats.Stat `json:"errors"`
+	ErrorRate    float64        `json:"error_rate"`
+	AvgLatencyMs float64        `json:"avg_latency_ms"`
+	// Outages counts generations the chat mode's model failed, by outcome
+	Outages map[string]livestats.Stat `json:"outages"`
+}
+
+type LivePartnerStats struct {
+	Requests     livestats.Stat `json:"requests"`
+	Errors       livestats.Stat `json:"errors"`
+	AvgLatencyMs float64        `json:"avg_latency_ms"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReplySuggestion is a bot reply held for the seller's approval in safe mode
+// instead of being sent to the channel
+type ReplySuggestion struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	BuyerID   string              `bson:"buyer_id" json:"buyer_id"`
+	// Message is the reply drafted by the bot
+	Message string           `bson:"message" json:"message"`
+	Status  SuggestionStatus `bson:"status" json:"status"`
+	// SentMessage is what was sent on approval, the seller's edit if any
+	SentMessage string     `bson:"sent_message,omitempty" json:"sent_message,omitempty"`
+	DecidedAt   *time.Time `bson:"decided_at,omitempty" json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time  `bson:"updated_at" json:"updated_at"`
+	// DecidedBy is who approved or rejected the suggestion, e.g. a team agent
+	DecidedBy *Actor `bson:"decided_by,omitempty" json:"decided_by,omitempty"`
+}
+
+type SuggestionStatus string
+
+const (
+	SuggestionStatusPending  SuggestionStatus = "pending"
+	SuggestionStatusApproved SuggestionStatus = "approved"
+	SuggestionStatusRejected SuggestionStatus = "rejected"
+)
+
+// ReplyCandidates are replies drafted for the seller to pick from. Unlike
+// suggestions they are neither stored nor sent by the bot.
+type ReplyCandidates struct {
+	ChannelID string `json:"channel_id"`
+	ChatMode  string `json:"chat_mode"`
+	// InReplyTo is the buyer message the replies answer
+	InReplyTo string   `json:"in_reply_to"`
+	Replies   []string `json:"replies"`
+}
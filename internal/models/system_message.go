@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SystemMessageKey names a canned message the bot sends to chats
+type SystemMessageKey string
+
+const (
+	// SystemMessageAcknowledge answers the buyer when no model could
+	SystemMessageAcknowledge SystemMessageKey = "acknowledge"
+	// SystemMessageBudgetPaused tells the chat the bot stopped for its budget
+	SystemMessageBudgetPaused SystemMessageKey = "budget_paused"
+	// SystemMessageScamNotice warns the chat after a likely scam message
+	SystemMessageScamNotice SystemMessageKey = "scam_notice"
+)
+
+// SystemMessageOverride is a tenant's text for a system message in a locale
+type SystemMessageOverride struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Key       SystemMessageKey    `bson:"key" json:"key"`
+	Locale    string              `bson:"locale" json:"locale"`
+	Text      string              `bson:"text" json:"text"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// SystemMessage is the text a system message is sent with in a locale
+type SystemMessage struct {
+	Key    SystemMessageKey `json:"key"`
+	Locale string           `json:"locale"`
+	Text   string           `json:"text"`
+	// Overridden tells the text is the tenant's rather than the bundle's
+	Overridden bool `json:"overridden"`
+}
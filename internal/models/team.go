@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Team lets several users, its agents, share the inbox of one chat-api
+// seller. A seller has at most one team.
+type Team struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	Name      string              `bson:"name" json:"name"`
+	Members   []TeamMember        `bson:"members" json:"members"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// Member returns the team member with the user ID, nil when the user is not
+// in the team
+func (t *Team) Member(userID primitive.ObjectID) *TeamMember {
+	for i := range t.Members {
+		if t.Members[i].UserID == userID {
+			return &t.Members[i]
+		}
+	}
+	return nil
+}
+
+type TeamRole string
+
+const (
+	// TeamRoleOwner manages the team and works chats like an agent
+	TeamRoleOwner TeamRole = "owner"
+	TeamRoleAgent TeamRole = "agent"
+)
+
+type TeamMember struct {
+	UserID  primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Role    TeamRole           `bson:"role" json:"role"`
+	AddedAt time.Time          `bson:"added_at" json:"added_at"`
+}
+
+// ChannelAssignment gives one of the seller's channels to an agent of the
+// seller's team
+type ChannelAssignment struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	TeamID    primitive.ObjectID  `bson:"team_id" json:"team_id"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	AgentID   primitive.ObjectID  `bson:"agent_id" json:"agent_id"`
+	// AssignedBy is who made the latest assignment
+	AssignedBy Actor     `bson:"assigned_by" json:"assigned_by"`
+	AssignedAt time.Time `bson:"assigned_at" json:"assigned_at"`
+}
+
+// ChannelClaim marks the team agent currently handling a room, so two agents
+// and the bot do not reply at the same time. Claims lapse at ExpiresAt unless
+// the agent keeps typing.
+type ChannelClaim struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SellerID  string              `bson:"seller_id" json:"seller_id"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	// AgentID is who claimed the room, shown to the other agents as claimed_by
+	AgentID      primitive.ObjectID `bson:"agent_id" json:"claimed_by"`
+	ClaimedAt    time.Time          `bson:"claimed_at" json:"claimed_at"`
+	LastActiveAt time.Time          `bson:"last_active_at" json:"last_active_at"`
+	ExpiresAt    time.Time          `bson:"expires_at" json:"expires_at"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MessageTemplate is a message pre-approved by a partner, the only kind some
+// partners deliver outside the reply window. Params are the {{name}}
+// placeholders of Body.
+type MessageTemplate struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Partner   string              `bson:"partner" json:"partner"`
+	Name      string              `bson:"name" json:"name"`
+	Body      string              `bson:"body" json:"body"`
+	Params    []string            `bson:"params" json:"params"`
+	Status    TemplateStatus      `bson:"status" json:"status"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// TemplateStatus is the partner's review of a template
+type TemplateStatus string
+
+const (
+	TemplateStatusPending  TemplateStatus = "pending"
+	TemplateStatusApproved TemplateStatus = "approved"
+	TemplateStatusRejected TemplateStatus = "rejected"
+)
+
+// The values a template can use, filled in when it is sent
+const (
+	// TemplateParamMessage is the free-form message the template replaces
+	TemplateParamMessage   = "message"
+	TemplateParamItemName  = "item_name"
+	TemplateParamItemPrice = "item_price"
+)
+
+// TemplateParamNames lists the params a template body may use
+var TemplateParamNames = []string{TemplateParamMessage, TemplateParamItemName, TemplateParamItemPrice}
+
+var templateParamPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// TemplateParams returns the distinct {{name}} placeholders of body in order
+func TemplateParams(body string) []string {
+	params := []string{}
+	seen := make(map[string]bool)
+	for _, match := range templateParamPattern.FindAllStringSubmatch(body, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			params = append(params, match[1])
+		}
+	}
+	return params
+}
+
+// Render fills the template's placeholders, reporting false when a param has
+// no value
+func (t *MessageTemplate) Render(values map[string]string) (string, bool) {
+	for _, param := range t.Params {
+		if strings.TrimSpace(values[param]) == "" {
+			return "", false
+		}
+	}
+	rendered := templateParamPattern.ReplaceAllStringFunc(t.Body, func(placeholder string) string {
+		return values[templateParamPattern.FindStringSubmatch(placeholder)[1]]
+	})
+	return rendered, true
+}
+
+// TemplateMessage is the template an outgoing message was sent as, for
+// partners that deliver templates by name
+type TemplateMessage struct {
+	Name   string            `json:"name"`
+	Params map[string]string `json:"params"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tenant is a company running its own isolated instance of the bot
+type Tenant struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name" validate:"required"`
+	Settings  TenantSettings     `bson:"settings" json:"settings"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type TenantSettings struct {
+	// EnabledPartners lists the product services (e.g. "chotot") the tenant may use.
+	// Empty means every registered partner is allowed.
+	EnabledPartners []string     `bson:"enabled_partners" json:"enabled_partners"`
+	Quotas          TenantQuotas `bson:"quotas" json:"quotas"`
+	// DefaultChatMode answers the tenant's chats no override, seller choice
+	// or rule picks a mode for; empty falls back to the global default
+	DefaultChatMode string `bson:"default_chat_mode,omitempty" json:"default_chat_mode,omitempty"`
+	// ChatModeRules pick a chat mode from the channel's item, first match wins
+	ChatModeRules []ChatModeRule `bson:"chat_mode_rules,omitempty" json:"chat_mode_rules,omitempty"`
+	// DisableConversationRecap keeps a buyer's earlier conversations with the
+	// seller out of the prompt
+	DisableConversationRecap bool `bson:"disable_conversation_recap" json:"disable_conversation_recap"`
+	// SafeMode holds the bot's replies for approval in some sellers' chats
+	SafeMode SafeModeSettings `bson:"safe_mode" json:"safe_mode"`
+	// ToolPolicy limits the tools chat modes may use in the tenant's chats
+	ToolPolicy ToolPolicy `bson:"tool_policy" json:"tool_policy"`
+}
+
+// ToolPolicy is an allowlist of tools. A seller listed in Sellers gets their
+// own list, the others get Tools; an empty Tools allows every tool.
+type ToolPolicy struct {
+	Tools   []string            `bson:"tools,omitempty" json:"tools,omitempty"`
+	Sellers map[string][]string `bson:"sellers,omitempty" json:"sellers,omitempty"`
+}
+
+// AllowedTools returns the tools the seller may use, nil when every tool is allowed
+func (p ToolPolicy) AllowedTools(sellerID string) []string {
+	if tools, ok := p.Sellers[sellerID]; ok {
+		if tools == nil {
+			return []string{}
+		}
+		return tools
+	}
+	if len(p.Tools) == 0 {
+		return nil
+	}
+	return p.Tools
+}
+
+// ToolNames lists every tool the policy names
+func (p ToolPolicy) ToolNames() []string {
+	names := slices.Clone(p.Tools)
+	for _, tools := range p.Sellers {
+		names = append(names, tools...)
+	}
+	return names
+}
+
+// SafeModeSettings list the sellers and channels whose bot replies wait for
+// the seller's approval instead of being sent
+type SafeModeSettings struct {
+	Sellers  []string `bson:"sellers,omitempty" json:"sellers,omitempty"`
+	Channels []string `bson:"channels,omitempty" json:"channels,omitempty"`
+}
+
+// Applies reports whether replies for the seller in the channel need approval
+func (s SafeModeSettings) Applies(sellerID, channelID string) bool {
+	return slices.Contains(s.Sellers, sellerID) || slices.Contains(s.Channels, channelID)
+}
+
+type TenantQuotas struct {
+	// MaxSessionsPerDay caps the number of bot sessions started per day, 0 means unlimited
+	MaxSessionsPerDay int `bson:"max_sessions_per_day" json:"max_sessions_per_day"`
+}
+
+// IsPartnerEnabled reports whether the tenant may use the given partner
+func (s TenantSettings) IsPartnerEnabled(partner string) bool {
+	if len(s.EnabledPartners) == 0 {
+		return true
+	}
+	for _, p := range s.EnabledPartners {
+		if p == partner {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey authenticates API callers and binds them to a tenant
+type APIKey struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID  primitive.ObjectID `bson:"tenant_id" json:"tenant_id"`
+	Name      string             `bson:"name" json:"name"`
+	Prefix    string             `bson:"prefix" json:"prefix"`
+	KeyHash   string             `bson:"key_hash" json:"-"`
+	RevokedAt *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type tenantCtxKey struct{}
+
+// WithTenantID returns a copy of ctx scoped to the given tenant
+func WithTenantID(ctx context.Context, tenantID primitive.ObjectID) context.Context {
+	return context.WithValue(ctx, tenantCtxKey{}, tenantID)
+}
+
+// TenantIDFromContext returns the tenant the ctx is scoped to, if any
+func TenantIDFromContext(ctx context.Context) (primitive.ObjectID, bool) {
+	tenantID, ok := ctx.Value(tenantCtxKey{}).(primitive.ObjectID)
+	if !ok || tenantID.IsZero() {
+		return primitive.NilObjectID, false
+	}
+	return tenantID, true
+}
+
+type allTenantsCtxKey struct{}
+
+// WithAllTenants returns a copy of ctx that reads across every tenant. It is
+// meant for system work such as sweeps, the message consumer and admin
+// requests; a context with neither a tenant nor this mark only sees data that
+// belongs to no tenant.
+func WithAllTenants(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allTenantsCtxKey{}, true)
+}
+
+// AllTenantsFromContext reports whether ctx reads across every tenant. A
+// tenant set on ctx takes precedence.
+func AllTenantsFromContext(ctx context.Context) bool {
+	if _, ok := TenantIDFromContext(ctx); ok {
+		return false
+	}
+	all, _ := ctx.Value(allTenantsCtxKey{}).(bool)
+	return all
+}
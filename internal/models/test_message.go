@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TestMessage is a message to a partner in test mode, stored instead of sent
+type TestMessage struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Partner   string              `bson:"partner" json:"partner"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	SenderID  string              `bson:"sender_id" json:"sender_id"`
+	Message   string              `bson:"message" json:"message"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	ExpiresAt time.Time           `bson:"expires_at" json:"-"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TranscriptEntryType string
+
+const (
+	TranscriptSystem       TranscriptEntryType = "system"
+	TranscriptUser         TranscriptEntryType = "user"
+	TranscriptModel        TranscriptEntryType = "model"
+	TranscriptToolRequest  TranscriptEntryType = "tool_request"
+	TranscriptToolResponse TranscriptEntryType = "tool_response"
+)
+
+// SessionTranscript is the full model conversation of a session, kept so bad
+// bot answers can be reviewed. Secrets are redacted before it is stored.
+type SessionTranscript struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	SessionID primitive.ObjectID  `bson:"session_id" json:"session_id"`
+	ChannelID string              `bson:"channel_id" json:"channel_id"`
+	UserID    string              `bson:"user_id" json:"user_id"`
+	ChatMode  string              `bson:"chat_mode" json:"chat_mode"`
+	Model     string              `bson:"model" json:"model"`
+	Input     *TranscriptInput    `bson:"input,omitempty" json:"input,omitempty"`
+	Entries   []TranscriptEntry   `bson:"entries" json:"entries"`
+	// Error is why processing stopped early, if it did
+	Error     string    `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt time.Time `bson:"created_at" json:"created_at"`
+	// ExpiresAt is when MongoDB removes the transcript
+	ExpiresAt time.Time `bson:"expires_at" json:"expires_at"`
+}
+
+// TranscriptInput is what the prompt was rendered from, kept so the session
+// can be replayed against another chat mode
+type TranscriptInput struct {
+	ChannelInfo    *ChannelInfo    `bson:"channel_info,omitempty" json:"channel_info,omitempty"`
+	SenderRole     string          `bson:"sender_role" json:"sender_role"`
+	Message        string          `bson:"message" json:"message"`
+	RecentMessages *MessageHistory `bson:"recent_messages,omitempty" json:"recent_messages,omitempty"`
+	Listings       []ListingCard   `bson:"listings,omitempty" json:"listings,omitempty"`
+}
+
+type TranscriptEntry struct {
+	Type TranscriptEntryType `bson:"type" json:"type"`
+	// Iteration is the agent loop iteration, 0 for the initial messages
+	Iteration int    `bson:"iteration" json:"iteration"`
+	Text      string `bson:"text,omitempty" json:"text,omitempty"`
+	ToolName  string `bson:"tool_name,omitempty" json:"tool_name,omitempty"`
+	ToolRef   string `bson:"tool_ref,omitempty" json:"tool_ref,omitempty"`
+	// ToolData is the JSON encoded tool input or output
+	ToolData string    `bson:"tool_data,omitempty" json:"tool_data,omitempty"`
+	Error    string    `bson:"error,omitempty" json:"error,omitempty"`
+	At       time.Time `bson:"at" json:"at"`
+}
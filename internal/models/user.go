@@ -6,20 +6,56 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// PartnerChotot is the partner whose user IDs chat-api carries
+const PartnerChotot = "chotot"
+
+// PartnerChatAPI is the messaging partner the bot reads and sends chats through
+const PartnerChatAPI = "chat-api"
+
+// ExternalIDKey is the user attribute holding a user's ID at partner, e.g.
+// AttributeChototID
+func ExternalIDKey(partner string) string {
+	return partner + "_id"
+}
+
+const (
+	AttributeChototID  = "chotot_id"
+	AttributeChototOID = "chotot_oid"
+	// AttributeChototChatMode is the chat mode a seller chose for their chotot chats
+	AttributeChototChatMode = "chotot_chat_mode"
+	// AttributeChototVertical is the chat mode pack vertical a seller picked
+	AttributeChototVertical = "chotot_vertical"
+	// AttributeBuyerLanguage is the language a user mostly writes in as a
+	// buyer, detected from their messages
+	AttributeBuyerLanguage = "buyer_language"
+)
+
+// UniqueAttributeKeys are identity attributes whose value may belong to at most one user
+var UniqueAttributeKeys = []string{AttributeChototID, AttributeChototOID}
+
+// VerifiedAttributeKeys are only set by a verified chotot link, never by API clients
+var VerifiedAttributeKeys = []string{AttributeChototID, AttributeChototOID}
+
 type User struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name      string             `bson:"name" json:"name" validate:"required"`
-	Email     string             `bson:"email" json:"email" validate:"required,email"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	Name      string              `bson:"name" json:"name" validate:"required"`
+	Email     string              `bson:"email" json:"email" validate:"required,email"`
+	AvatarURL string              `bson:"avatar_url,omitempty" json:"avatar_url,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+	// EmailVerifiedAt is set once the user proved they own Email, and
+	// cleared when Email changes
+	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
 }
 
 type UserAttribute struct {
-	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID    primitive.ObjectID `bson:"user_id" json:"user_id" validate:"required"`
-	Key       string             `bson:"key" json:"key" validate:"required"`
-	Value     string             `bson:"value" json:"value" validate:"required"`
-	Tags      []string           `bson:"tags" json:"tags"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
-}
\ No newline at end of file
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	TenantID  *primitive.ObjectID `bson:"tenant_id,omitempty" json:"tenant_id,omitempty"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id" validate:"required"`
+	Key       string              `bson:"key" json:"key" validate:"required"`
+	Value     string              `bson:"value" json:"value" validate:"required"`
+	Tags      []string            `bson:"tags" json:"tags"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
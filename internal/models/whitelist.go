@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WhitelistKind is what a whitelist entry lets the bot engage
+type WhitelistKind string
+
+const (
+	// WhitelistKindSeller admits every conversation of a chat-api seller
+	WhitelistKindSeller WhitelistKind = "seller"
+	// WhitelistKindChannel admits a single chat-api channel
+	WhitelistKindChannel WhitelistKind = "channel"
+)
+
+// Valid reports whether k is a known kind
+func (k WhitelistKind) Valid() bool {
+	return k == WhitelistKindSeller || k == WhitelistKindChannel
+}
+
+// WhitelistEntry admits a seller or channel on top of the
+// KAFKA_SELLER_WHITELIST setting. Entries are global, not per tenant.
+type WhitelistEntry struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Kind      WhitelistKind      `bson:"kind" json:"kind"`
+	Value     string             `bson:"value" json:"value"`
+	Note      string             `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedBy Actor              `bson:"created_by" json:"created_by"`
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// WhitelistRollout admits a percentage of sellers that are not whitelisted,
+// picked by a hash of their ID so a seller stays in or out as it grows
+type WhitelistRollout struct {
+	Percent   int       `bson:"percent" json:"percent"`
+	UpdatedBy Actor     `bson:"updated_by" json:"updated_by"`
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// Whitelist is the whole set of rules deciding which conversations the bot
+// engages
+type Whitelist struct {
+	// Sellers are the KAFKA_SELLER_WHITELIST setting, "all" admits everyone
+	Sellers []string          `json:"sellers"`
+	Entries []*WhitelistEntry `json:"entries"`
+	// Rollout is nil until an administrator sets a percentage
+	Rollout *WhitelistRollout `json:"rollout,omitempty"`
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/carousell/chat-api/pkg/client"
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/httpx"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
 )
 
 type MessageHistoryRequest struct {
@@ -26,8 +28,12 @@ type Client interface {
 }
 
 type chatAPIClient struct {
-	client    client.InternalAPI
-	projectID string
+	client          client.InternalAPI
+	projectID       string
+	maxMessageChars int
+	blockTypes      []string
+	// partner applies the shared timeout, retry and circuit breaker policy around SDK calls
+	partner *httpx.Client
 }
 
 func NewChatAPIClient(conf *config.Config) Client {
@@ -45,22 +51,21 @@ func NewChatAPIClient(conf *config.Config) Client {
 	}
 
 	return &chatAPIClient{
-		client:    chatClient,
-		projectID: cfg.ProjectID,
+		client:          chatClient,
+		projectID:       cfg.ProjectID,
+		maxMessageChars: cfg.MaxMessageChars,
+		blockTypes:      cfg.BlockTypes,
+		partner:         httpx.New(models.PartnerChatAPI, conf.PartnerHTTP),
 	}
 }
 
 func (c *chatAPIClient) GetChannelInfo(ctx context.Context, channelID string) (*models.ChannelInfo, error) {
-	// Create timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
 	request := types.GetPlainUserChannelsRequest{
 		ProjectID: c.projectID,
 		ChannelID: channelID,
 	}
 
-	resp, err := c.client.GetPlainUserChannels(timeoutCtx, request)
+	resp, err := call(ctx, c.partner, true, c.client.GetPlainUserChannels, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get plain user channels: %w", err)
 	}
@@ -78,16 +83,16 @@ func (c *chatAPIClient) GetChannelInfo(ctx context.Context, channelID string) (*
 		Name:         firstChannel.Name,
 		ItemName:     firstChannel.ItemName,
 		ItemPrice:    firstChannel.ItemPrice,
+		ItemCategory: getMetadataString(firstChannel.Metadata, "category"),
 		Context:      getMetadataString(firstChannel.Metadata, "context"),
 		Participants: make([]models.Participant, 0, len(resp.Data)),
 	}
 
-	// Convert all user channels to participants
+	// Convert all user channels to participants. Group rooms name the seller
+	// the bot replies as in the primary_seller_id metadata.
+	primarySellerID := getMetadataString(firstChannel.Metadata, "primary_seller_id")
 	for _, userChannel := range resp.Data {
-		participant := models.Participant{
-			UserID: userChannel.UserID,
-			Role:   userChannel.Role,
-		}
+		participant := models.NewParticipant(userChannel.UserID, userChannel.Role, primarySellerID)
 		channelInfo.Participants = append(channelInfo.Participants, participant)
 	}
 
@@ -105,10 +110,6 @@ func (c *chatAPIClient) GetMessageHistory(ctx context.Context, userID, channelID
 }
 
 func (c *chatAPIClient) GetMessageHistoryWithParams(ctx context.Context, req MessageHistoryRequest) (*models.MessageHistory, error) {
-	// Create timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
 	request := types.GetChannelMessagesRequest{
 		ProjectID: c.projectID,
 		UserID:    req.UserID,
@@ -118,7 +119,7 @@ func (c *chatAPIClient) GetMessageHistoryWithParams(ctx context.Context, req Mes
 		Order:     "desc",
 	}
 
-	resp, err := c.client.GetChannelMessages(timeoutCtx, request)
+	resp, err := call(ctx, c.partner, true, c.client.GetChannelMessages, request)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get channel messages: %w", err)
 	}
@@ -147,19 +148,16 @@ func (c *chatAPIClient) GetMessageHistoryWithParams(ctx context.Context, req Mes
 }
 
 func (c *chatAPIClient) SendMessage(ctx context.Context, message *models.OutgoingMessage) error {
-	// Create timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-	defer cancel()
-
 	request := types.InternalSendMessageRequest{
 		ProjectID: c.projectID,
 		ChannelID: message.ChannelID,
 		SenderID:  message.SenderID, // This should be the bot/system sender ID
-		Message:   message.Message,
+		Message:   RenderMessage(message, c.blockTypes, c.maxMessageChars),
 		Type:      "text",
 	}
 
-	_, err := c.client.SendMessage(timeoutCtx, request)
+	// not retried: chat-api has no idempotency key, so a retry could post the message twice
+	_, err := call(ctx, c.partner, false, c.client.SendMessage, request)
 	if err != nil {
 		return fmt.Errorf("failed to send message: %w", err)
 	}
@@ -167,6 +165,38 @@ func (c *chatAPIClient) SendMessage(ctx context.Context, message *models.Outgoin
 	return nil
 }
 
+// FormatMessage normalizes text for chat-api and cuts it to maxChars
+// characters without splitting emoji or accented letters
+func FormatMessage(text string, maxChars int) string {
+	return textx.Truncate(textx.Normalize(text), maxChars)
+}
+
+// BlockCapabilities describes chat-api clients, which render the blockTypes
+// configured for them
+func BlockCapabilities(blockTypes []string) models.BlockCapabilities {
+	return models.BlockCapabilities{Partner: models.PartnerChatAPI, BlockTypes: blockTypes}
+}
+
+// RenderMessage folds the blocks of message into its text as chat-api clients
+// show them, then formats the text. chat-api takes text messages only, and its
+// clients expand listing URLs into product cards.
+func RenderMessage(message *models.OutgoingMessage, blockTypes []string, maxChars int) string {
+	rendered := models.RenderBlocks(BlockCapabilities(blockTypes), message.Message, message.Blocks)
+	return FormatMessage(rendered.Message, maxChars)
+}
+
+// call runs an SDK method under the partner policy. All calls share the one
+// chat-api rate limit and circuit breaker.
+func call[Req, Resp any](ctx context.Context, partner *httpx.Client, retryable bool, fn func(context.Context, Req) (Resp, error), req Req) (Resp, error) {
+	var resp Resp
+	err := partner.Call(ctx, "chat-api", retryable, func(ctx context.Context) error {
+		var err error
+		resp, err = fn(ctx, req)
+		return err
+	})
+	return resp, err
+}
+
 // Helper functions for metadata extraction
 func getMetadataString(metadata map[string]interface{}, key string) string {
 	if metadata == nil {
@@ -0,0 +1,122 @@
+package chatapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+)
+
+// LastMessageStore tells when the buyer of a channel last wrote
+type LastMessageStore interface {
+	Get(ctx context.Context, channelID string) (*models.ChannelCursor, error)
+}
+
+// TemplateStore lists the templates a partner approved
+type TemplateStore interface {
+	ListApproved(ctx context.Context, partner string) ([]*models.MessageTemplate, error)
+}
+
+// replyWindowClient sends free-form messages only within the window after the
+// buyer's last message. Later messages are sent as the first approved template
+// whose params can all be filled, or blocked.
+type replyWindowClient struct {
+	Client
+	lastMessages LastMessageStore
+	templates    TemplateStore
+	window       time.Duration
+	// block drops messages outside the window instead of sending templates
+	block bool
+}
+
+// NewReplyWindowClient wraps next so that messages outside the reply window
+// are replaced by an approved template, or fail with
+// models.ErrOutsideReplyWindow when block is set or no template fits
+func NewReplyWindowClient(next Client, lastMessages LastMessageStore, templates TemplateStore, window time.Duration, block bool) Client {
+	return &replyWindowClient{Client: next, lastMessages: lastMessages, templates: templates, window: window, block: block}
+}
+
+func (c *replyWindowClient) SendMessage(ctx context.Context, message *models.OutgoingMessage) error {
+	open, err := c.windowOpen(ctx, message.ChannelID)
+	if err != nil {
+		return err
+	}
+	if open {
+		return c.Client.SendMessage(ctx, message)
+	}
+
+	var templated *models.OutgoingMessage
+	if !c.block {
+		templated, err = c.template(ctx, message)
+		if err != nil && !errors.Is(err, models.ErrOutsideReplyWindow) {
+			return err
+		}
+	}
+	if templated == nil {
+		livestats.Inc(models.StatReplyWindowPrefix + "blocked")
+		log.Warnw(ctx, "Reply window closed, message blocked", "partner", models.PartnerChatAPI,
+			"channel_id", message.ChannelID, "sender_id", message.SenderID)
+		return models.ErrOutsideReplyWindow
+	}
+
+	livestats.Inc(models.StatReplyWindowPrefix + "template")
+	log.Infow(ctx, "Reply window closed, sending a template", "partner", models.PartnerChatAPI,
+		"channel_id", message.ChannelID, "template", templated.Template.Name)
+	return c.Client.SendMessage(ctx, templated)
+}
+
+// windowOpen reports whether the buyer wrote within the window. A channel
+// the buyer never wrote in has no open window.
+func (c *replyWindowClient) windowOpen(ctx context.Context, channelID string) (bool, error) {
+	cursor, err := c.lastMessages.Get(ctx, channelID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get last buyer message: %w", err)
+	}
+	if cursor == nil {
+		return false, nil
+	}
+	return time.Since(time.UnixMilli(cursor.LastMessageAt)) < c.window, nil
+}
+
+// template renders the message as the first approved template whose params
+// all have a value
+func (c *replyWindowClient) template(ctx context.Context, message *models.OutgoingMessage) (*models.OutgoingMessage, error) {
+	templates, err := c.templates.ListApproved(ctx, models.PartnerChatAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+	if len(templates) == 0 {
+		return nil, models.ErrOutsideReplyWindow
+	}
+
+	values := map[string]string{models.TemplateParamMessage: message.Message}
+	channelInfo, err := c.GetChannelInfo(ctx, message.ChannelID)
+	if err != nil {
+		log.Warnw(ctx, "Failed to get channel info for template params", "channel_id", message.ChannelID, "error", err)
+	} else {
+		values[models.TemplateParamItemName] = channelInfo.ItemName
+		values[models.TemplateParamItemPrice] = channelInfo.ItemPrice
+	}
+
+	for _, template := range templates {
+		rendered, ok := template.Render(values)
+		if !ok {
+			continue
+		}
+		params := make(map[string]string, len(template.Params))
+		for _, param := range template.Params {
+			params[param] = values[param]
+		}
+		return &models.OutgoingMessage{
+			ChannelID: message.ChannelID,
+			SenderID:  message.SenderID,
+			Message:   rendered,
+			Template:  &models.TemplateMessage{Name: template.Name, Params: params},
+		}, nil
+	}
+	return nil, models.ErrOutsideReplyWindow
+}
@@ -0,0 +1,48 @@
+package chatapi
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// TestMessageStore keeps the messages held in test mode
+type TestMessageStore interface {
+	Create(ctx context.Context, message *models.TestMessage) error
+}
+
+// testModeClient reads from chat-api as usual but stores and logs the
+// messages it would send, so staging environments never message real users
+type testModeClient struct {
+	Client
+	store           TestMessageStore
+	retention       time.Duration
+	maxMessageChars int
+	blockTypes      []string
+}
+
+// NewTestModeClient wraps next so that SendMessage is held instead of sent.
+// Held messages are formatted as they would have been sent.
+func NewTestModeClient(next Client, store TestMessageStore, retention time.Duration, maxMessageChars int, blockTypes []string) Client {
+	return &testModeClient{Client: next, store: store, retention: retention, maxMessageChars: maxMessageChars, blockTypes: blockTypes}
+}
+
+func (c *testModeClient) SendMessage(ctx context.Context, message *models.OutgoingMessage) error {
+	held := &models.TestMessage{
+		Partner:   models.PartnerChatAPI,
+		ChannelID: message.ChannelID,
+		SenderID:  message.SenderID,
+		Message:   RenderMessage(message, c.blockTypes, c.maxMessageChars),
+		ExpiresAt: time.Now().Add(c.retention),
+	}
+	if err := c.store.Create(ctx, held); err != nil {
+		return fmt.Errorf("failed to store test message: %w", err)
+	}
+
+	log.Infow(ctx, "Test mode, message held instead of sent", "partner", models.PartnerChatAPI,
+		"channel_id", message.ChannelID, "sender_id", message.SenderID)
+	return nil
+}
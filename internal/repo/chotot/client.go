@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/httpx"
 )
 
 type AdsResponse struct {
@@ -41,21 +43,35 @@ type GetUserAdsResponse struct {
 	Total int           `json:"total"`
 }
 
+type AdListingResponse struct {
+	Ad ChototAd `json:"ad"`
+}
+
+type ChototProfile struct {
+	AccountOID string `json:"account_oid"`
+	FullName   string `json:"full_name"`
+	Avatar     string `json:"avatar"`
+}
+
 type Client interface {
 	GetUserAds(ctx context.Context, accountOID string, limit, page int) (*GetUserAdsResponse, error)
+	GetProfile(ctx context.Context, accountOID string) (*ChototProfile, error)
+	GetAd(ctx context.Context, listID string) (*ChototAd, error)
 }
 
 type client struct {
-	httpClient *http.Client
-	baseURL    string
+	httpClient     *httpx.Client
+	baseURL        string
+	profileBaseURL string
+	adBaseURL      string
 }
 
-func NewClient() Client {
+func NewClient(conf *config.Config) Client {
 	return &client{
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		baseURL: "https://gateway.chotot.org/v1/public/theia",
+		httpClient:     httpx.New("chotot", conf.PartnerHTTP),
+		baseURL:        "https://gateway.chotot.org/v1/public/theia",
+		profileBaseURL: "https://gateway.chotot.org/v1/public/profile",
+		adBaseURL:      "https://gateway.chotot.org/v1/public/ad-listing",
 	}
 }
 
@@ -91,3 +107,55 @@ func (c *client) GetUserAds(ctx context.Context, accountOID string, limit, page
 
 	return &chototResp, nil
 }
+
+func (c *client) GetProfile(ctx context.Context, accountOID string) (*ChototProfile, error) {
+	url := fmt.Sprintf("%s/%s", c.profileBaseURL, accountOID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var profile ChototProfile
+	if err := json.NewDecoder(resp.Body).Decode(&profile); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &profile, nil
+}
+
+func (c *client) GetAd(ctx context.Context, listID string) (*ChototAd, error) {
+	url := fmt.Sprintf("%s/%s", c.adBaseURL, listID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var adResp AdListingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&adResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &adResp.Ad, nil
+}
@@ -0,0 +1,28 @@
+package chotot
+
+import "regexp"
+
+// listingURLPattern matches listing pages on chotot.com and its vertical
+// subdomains, e.g. https://www.chotot.com/mua-ban-dien-thoai/118234567.htm
+// or https://xe.chotot.com/mua-ban-xe-may/118234567.htm
+var listingURLPattern = regexp.MustCompile(`https?://(?:[a-z0-9-]+\.)*chotot\.com/\S*?(\d{6,})\.htm`)
+
+// ParseListingIDs returns the list IDs of chotot listing URLs found in text,
+// in order of appearance and without duplicates
+func ParseListingIDs(text string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, match := range listingURLPattern.FindAllStringSubmatch(text, -1) {
+		if seen[match[1]] {
+			continue
+		}
+		seen[match[1]] = true
+		ids = append(ids, match[1])
+	}
+	return ids
+}
+
+// ListingURL returns the canonical chotot.com URL of a listing
+func ListingURL(listID string) string {
+	return "https://www.chotot.com/" + listID + ".htm"
+}
@@ -0,0 +1,46 @@
+package googleai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/httpx"
+)
+
+type Client interface {
+	// ValidateAPIKey performs a cheap authenticated call to check the key is accepted
+	ValidateAPIKey(ctx context.Context, apiKey string) error
+}
+
+type client struct {
+	httpClient *httpx.Client
+	baseURL    string
+}
+
+func NewClient(conf *config.Config) Client {
+	return &client{
+		httpClient: httpx.New("googleai", conf.PartnerHTTP),
+		baseURL:    "https://generativelanguage.googleapis.com/v1beta",
+	}
+}
+
+func (c *client) ValidateAPIKey(ctx context.Context, apiKey string) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models?pageSize=1", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("x-goog-api-key", apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("key rejected with status %d", resp.StatusCode)
+	}
+	return nil
+}
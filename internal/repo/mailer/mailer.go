@@ -0,0 +1,67 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+)
+
+// Mail is a plain text email
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails to users, such as verification codes
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
+
+// NewMailer sends through the configured SMTP server, or only logs emails
+// when MAILER_SMTP_ADDR is empty, which suits local development
+func NewMailer(cfg *config.Config) Mailer {
+	if cfg.Mailer.SMTPAddr == "" {
+		return &logMailer{}
+	}
+	return &smtpMailer{conf: cfg.Mailer}
+}
+
+type smtpMailer struct {
+	conf config.MailerConfig
+}
+
+func (m *smtpMailer) Send(ctx context.Context, mail Mail) error {
+	var auth smtp.Auth
+	if m.conf.Username != "" {
+		host, _, err := net.SplitHostPort(m.conf.SMTPAddr)
+		if err != nil {
+			return fmt.Errorf("invalid smtp address: %w", err)
+		}
+		auth = smtp.PlainAuth("", m.conf.Username, m.conf.Password, host)
+	}
+
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", m.conf.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", mail.To)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mail.Subject)
+	msg.WriteString("MIME-Version: 1.0\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(mail.Body)
+
+	if err := smtp.SendMail(m.conf.SMTPAddr, auth, m.conf.From, []string{mail.To}, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+type logMailer struct{}
+
+func (m *logMailer) Send(ctx context.Context, mail Mail) error {
+	log.Infow(ctx, "Email not sent, no SMTP server configured", "to", mail.To, "subject", mail.Subject, "body", mail.Body)
+	return nil
+}
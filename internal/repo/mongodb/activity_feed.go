@@ -0,0 +1,129 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultFeedLimit = 20
+
+type ActivityFeedRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create stores an event for its seller, kept for retention
+	Create(ctx context.Context, item *models.FeedItem, retention time.Duration) error
+	// List returns the seller's items newest first
+	List(ctx context.Context, filter models.FeedFilter) ([]*models.FeedItem, error)
+	CountUnseen(ctx context.Context, sellerID string) (int64, error)
+	// MarkSeen marks the seller's items with the given IDs seen, or all of
+	// them without IDs, and returns how many were unseen
+	MarkSeen(ctx context.Context, sellerID string, ids []primitive.ObjectID) (int64, error)
+}
+
+type activityFeedRepo struct {
+	collection *mongo.Collection
+}
+
+func NewActivityFeedRepository(db *DB) ActivityFeedRepository {
+	return &activityFeedRepo{
+		collection: db.Database.Collection("activity_feed"),
+	}
+}
+
+// EnsureIndexes creates the feed index, the unseen count index and the TTL
+// index that removes items once they pass expires_at
+func (r *activityFeedRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "seller_id", Value: 1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName("tenant_seller_id"),
+		},
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "seller_id", Value: 1}, {Key: "seen_at", Value: 1}},
+			Options: options.Index().SetName("tenant_seller_seen_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create activity feed indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *activityFeedRepo) Create(ctx context.Context, item *models.FeedItem, retention time.Duration) error {
+	now := time.Now()
+	item.ID = primitive.NewObjectID()
+	if item.TenantID == nil {
+		item.TenantID = ctxTenantID(ctx)
+	}
+	item.CreatedAt = now
+	item.ExpiresAt = now.Add(retention)
+
+	if _, err := r.collection.InsertOne(ctx, item); err != nil {
+		return fmt.Errorf("failed to create feed item: %w", err)
+	}
+	return nil
+}
+
+func (r *activityFeedRepo) List(ctx context.Context, filter models.FeedFilter) ([]*models.FeedItem, error) {
+	query := bson.M{"seller_id": filter.SellerID}
+	if len(filter.Types) > 0 {
+		query["type"] = bson.M{"$in": filter.Types}
+	}
+	if filter.UnseenOnly {
+		query["seen_at"] = nil
+	}
+	if filter.Before != nil {
+		query["_id"] = bson.M{"$lt": *filter.Before}
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, scoped(ctx, query), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feed items: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	items := []*models.FeedItem{}
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode feed items: %w", err)
+	}
+	return items, nil
+}
+
+func (r *activityFeedRepo) CountUnseen(ctx context.Context, sellerID string) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, scoped(ctx, bson.M{"seller_id": sellerID, "seen_at": nil}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unseen feed items: %w", err)
+	}
+	return count, nil
+}
+
+func (r *activityFeedRepo) MarkSeen(ctx context.Context, sellerID string, ids []primitive.ObjectID) (int64, error) {
+	filter := bson.M{"seller_id": sellerID, "seen_at": nil}
+	if len(ids) > 0 {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	result, err := r.collection.UpdateMany(ctx, scoped(ctx, filter), bson.M{"$set": bson.M{"seen_at": time.Now()}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark feed items seen: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
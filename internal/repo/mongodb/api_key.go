@@ -0,0 +1,94 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, key *models.APIKey) error
+	GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	Revoke(ctx context.Context, tenantID, id primitive.ObjectID) error
+}
+
+type apiKeyRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAPIKeyRepository(db *DB) APIKeyRepository {
+	return &apiKeyRepo{
+		collection: db.Database.Collection("api_keys"),
+	}
+}
+
+func (r *apiKeyRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key_hash", Value: 1}},
+			Options: options.Index().SetName("uniq_key_hash").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}},
+			Options: options.Index().SetName("tenant_id"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create api key indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) Create(ctx context.Context, key *models.APIKey) error {
+	key.ID = primitive.NewObjectID()
+	key.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to create api key: %w", err)
+	}
+	return nil
+}
+
+// GetActiveByHash looks up a non-revoked key, returning nil when none matches
+func (r *apiKeyRepo) GetActiveByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	filter := bson.M{
+		"key_hash":   keyHash,
+		"revoked_at": bson.M{"$exists": false},
+	}
+
+	var key models.APIKey
+	err := r.collection.FindOne(ctx, filter).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) Revoke(ctx context.Context, tenantID, id primitive.ObjectID) error {
+	filter := bson.M{
+		"_id":        id,
+		"tenant_id":  tenantID,
+		"revoked_at": bson.M{"$exists": false},
+	}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now()}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultAuditLogLimit = 100
+
+type AuditLogRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, entry *models.AuditLog) error
+	List(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error)
+}
+
+type auditLogRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogRepository(db *DB) AuditLogRepository {
+	return &auditLogRepo{
+		collection: db.Database.Collection("audit_logs"),
+	}
+}
+
+func (r *auditLogRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("tenant_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "resource_type", Value: 1}, {Key: "resource_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("resource_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "actor.type", Value: 1}, {Key: "actor.id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("actor_created_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create audit log indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *auditLogRepo) Create(ctx context.Context, entry *models.AuditLog) error {
+	entry.ID = primitive.NewObjectID()
+	entry.CreatedAt = time.Now()
+	if entry.TenantID == nil {
+		entry.TenantID = ctxTenantID(ctx)
+	}
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
+
+// List is meant for administrators, so it filters by filter.TenantID rather
+// than by the tenant of ctx
+func (r *auditLogRepo) List(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error) {
+	query := bson.M{}
+	if filter.TenantID != nil {
+		query["tenant_id"] = *filter.TenantID
+	}
+	if filter.ActorType != "" {
+		query["actor.type"] = filter.ActorType
+	}
+	if filter.ActorID != "" {
+		query["actor.id"] = filter.ActorID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.ResourceType != "" {
+		query["resource_type"] = filter.ResourceType
+	}
+	if filter.ResourceID != "" {
+		query["resource_id"] = filter.ResourceID
+	}
+	createdAt := bson.M{}
+	if !filter.Since.IsZero() {
+		createdAt["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		createdAt["$lt"] = filter.Until
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditLogLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*models.AuditLog
+	for cursor.Next(ctx) {
+		var entry models.AuditLog
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode audit log: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return entries, nil
+}
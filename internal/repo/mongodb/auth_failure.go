@@ -0,0 +1,147 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuthFailureRepository counts failed authentication attempts. Addresses and
+// accounts are guarded across tenants, so nothing here is tenant scoped.
+type AuthFailureRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// RecordFailure counts a failure, starting a new window when the
+	// current one began before windowStart, and returns the updated record
+	RecordFailure(ctx context.Context, scope models.AuthScope, key string, windowStart time.Time, expiresAt time.Time) (*models.AuthFailure, error)
+	// Lock locks the record out until until
+	Lock(ctx context.Context, scope models.AuthScope, key string, until time.Time) error
+	// Get returns the record, or nil when there is none
+	Get(ctx context.Context, scope models.AuthScope, key string) (*models.AuthFailure, error)
+	// ListLocked returns the records locked out at now, latest failure first
+	ListLocked(ctx context.Context, now time.Time) ([]*models.AuthFailure, error)
+	// Delete clears the record, which unlocks it
+	Delete(ctx context.Context, scope models.AuthScope, key string) error
+}
+
+type authFailureRepo struct {
+	collection *mongo.Collection
+}
+
+func NewAuthFailureRepository(db *DB) AuthFailureRepository {
+	return &authFailureRepo{
+		collection: db.Database.Collection("auth_failures"),
+	}
+}
+
+func (r *authFailureRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetName("uniq_scope_key").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "locked_until", Value: 1}},
+			Options: options.Index().SetName("locked_until").SetSparse(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create auth failure indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *authFailureRepo) RecordFailure(ctx context.Context, scope models.AuthScope, key string, windowStart time.Time, expiresAt time.Time) (*models.AuthFailure, error) {
+	now := time.Now()
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// count the failure in the running window
+	var failure models.AuthFailure
+	filter := bson.M{"scope": scope, "key": key, "window_start": bson.M{"$gte": windowStart}}
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{"last_failure_at": now},
+		"$max": bson.M{"expires_at": expiresAt},
+	}
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&failure)
+	if err == nil {
+		return &failure, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("failed to record auth failure: %w", err)
+	}
+
+	// or start a new window, keeping a running lockout
+	filter = bson.M{"scope": scope, "key": key}
+	update = bson.M{
+		"$set": bson.M{
+			"failures":        1,
+			"window_start":    now,
+			"last_failure_at": now,
+		},
+		"$max": bson.M{"expires_at": expiresAt},
+	}
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts.SetUpsert(true)).Decode(&failure)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record auth failure: %w", err)
+	}
+	return &failure, nil
+}
+
+func (r *authFailureRepo) Lock(ctx context.Context, scope models.AuthScope, key string, until time.Time) error {
+	update := bson.M{
+		"$set": bson.M{"locked_until": until},
+		"$max": bson.M{"expires_at": until},
+	}
+	if _, err := r.collection.UpdateOne(ctx, bson.M{"scope": scope, "key": key}, update); err != nil {
+		return fmt.Errorf("failed to lock out %s %s: %w", scope, key, err)
+	}
+	return nil
+}
+
+func (r *authFailureRepo) Get(ctx context.Context, scope models.AuthScope, key string) (*models.AuthFailure, error) {
+	var failure models.AuthFailure
+	err := r.collection.FindOne(ctx, bson.M{"scope": scope, "key": key}).Decode(&failure)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get auth failures: %w", err)
+	}
+	return &failure, nil
+}
+
+func (r *authFailureRepo) ListLocked(ctx context.Context, now time.Time) ([]*models.AuthFailure, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "last_failure_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"locked_until": bson.M{"$gt": now}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list lockouts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var failures []*models.AuthFailure
+	if err := cursor.All(ctx, &failures); err != nil {
+		return nil, fmt.Errorf("failed to decode lockouts: %w", err)
+	}
+	return failures, nil
+}
+
+func (r *authFailureRepo) Delete(ctx context.Context, scope models.AuthScope, key string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"scope": scope, "key": key})
+	if err != nil {
+		return fmt.Errorf("failed to clear auth failures: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
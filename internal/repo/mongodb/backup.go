@@ -0,0 +1,207 @@
+package mongodb
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// backupCollections hold the tenant_id scoped data exported with a tenant.
+// API keys and LLM keys are left out: their hashes and secrets encrypted with
+// this environment's key are useless elsewhere and must not leave it.
+var backupCollections = []string{
+	"users",
+	"user_attributes",
+	"chat_modes",
+	"channel_chat_modes",
+	"channel_sentiments",
+	"scam_flags",
+	"channel_budgets",
+	"channel_items",
+	"channel_privacy",
+	"channel_contexts",
+	"personas",
+	"chat_sessions",
+	"chat_activities",
+	"purchase_intents",
+	"reservations",
+	"reply_suggestions",
+	"drafts",
+	"onboardings",
+	"chotot_links",
+	"session_transcripts",
+}
+
+const tenantsCollection = "tenants"
+
+// maxBackupLine bounds one archived document, above MongoDB's 16MB limit once encoded
+const maxBackupLine = 64 << 20
+
+// backupRecord is one line of an archive: a document of a collection, or the
+// closing manifest
+type backupRecord struct {
+	Collection string                 `bson:"collection,omitempty"`
+	Document   bson.Raw               `bson:"document,omitempty"`
+	Manifest   *models.BackupManifest `bson:"manifest,omitempty"`
+}
+
+// BackupRepository copies a tenant's documents to and from gzipped archives
+// of canonical extended JSON lines, which keep BSON types across environments
+type BackupRepository interface {
+	Export(ctx context.Context, tenantID primitive.ObjectID, w io.Writer) (*models.BackupManifest, error)
+	// Restore upserts every archived document by _id, overwriting documents
+	// with the same IDs
+	Restore(ctx context.Context, r io.Reader) (*models.BackupManifest, error)
+}
+
+type backupRepo struct {
+	database *mongo.Database
+}
+
+func NewBackupRepository(db *DB) BackupRepository {
+	return &backupRepo{
+		database: db.Database,
+	}
+}
+
+func (r *backupRepo) Export(ctx context.Context, tenantID primitive.ObjectID, w io.Writer) (*models.BackupManifest, error) {
+	gz := gzip.NewWriter(w)
+	manifest := &models.BackupManifest{
+		Version:     models.BackupVersion,
+		TenantID:    tenantID,
+		Collections: make(map[string]int),
+		CreatedAt:   time.Now(),
+	}
+
+	collections := append([]string{tenantsCollection}, backupCollections...)
+	for _, name := range collections {
+		filter := bson.M{"tenant_id": tenantID}
+		if name == tenantsCollection {
+			filter = bson.M{"_id": tenantID}
+		}
+
+		count, err := r.exportCollection(ctx, gz, name, filter)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Collections[name] = count
+	}
+
+	if err := writeBackupRecord(gz, backupRecord{Manifest: manifest}); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish backup archive: %w", err)
+	}
+	return manifest, nil
+}
+
+func (r *backupRepo) exportCollection(ctx context.Context, w io.Writer, name string, filter bson.M) (int, error) {
+	cursor, err := r.database.Collection(name).Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return 0, fmt.Errorf("failed to export %s: %w", name, err)
+	}
+	defer cursor.Close(ctx)
+
+	count := 0
+	for cursor.Next(ctx) {
+		if err := writeBackupRecord(w, backupRecord{Collection: name, Document: cursor.Current}); err != nil {
+			return 0, err
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return 0, fmt.Errorf("failed to export %s: %w", name, err)
+	}
+	return count, nil
+}
+
+func writeBackupRecord(w io.Writer, record backupRecord) error {
+	line, err := bson.MarshalExtJSON(record, true, false)
+	if err != nil {
+		return fmt.Errorf("failed to encode backup record: %w", err)
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write backup archive: %w", err)
+	}
+	return nil
+}
+
+func (r *backupRepo) Restore(ctx context.Context, reader io.Reader) (*models.BackupManifest, error) {
+	gz, err := gzip.NewReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidBackup, err)
+	}
+	defer gz.Close()
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxBackupLine)
+
+	counts := make(map[string]int)
+	for scanner.Scan() {
+		var record backupRecord
+		if err := bson.UnmarshalExtJSON(scanner.Bytes(), true, &record); err != nil {
+			return nil, fmt.Errorf("%w: %v", models.ErrInvalidBackup, err)
+		}
+
+		if record.Manifest != nil {
+			if err := checkBackupManifest(record.Manifest, counts); err != nil {
+				return nil, err
+			}
+			return record.Manifest, nil
+		}
+
+		if err := r.restoreDocument(ctx, record); err != nil {
+			return nil, err
+		}
+		counts[record.Collection]++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidBackup, err)
+	}
+	return nil, fmt.Errorf("%w: archive is truncated, no manifest found", models.ErrInvalidBackup)
+}
+
+func (r *backupRepo) restoreDocument(ctx context.Context, record backupRecord) error {
+	if record.Collection != tenantsCollection && !slices.Contains(backupCollections, record.Collection) {
+		return fmt.Errorf("%w: unexpected collection %q", models.ErrInvalidBackup, record.Collection)
+	}
+	id, err := record.Document.LookupErr("_id")
+	if err != nil {
+		return fmt.Errorf("%w: %s document without _id", models.ErrInvalidBackup, record.Collection)
+	}
+
+	_, err = r.database.Collection(record.Collection).ReplaceOne(ctx,
+		bson.M{"_id": id}, record.Document, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to restore %s document %s: %w", record.Collection, id, err)
+	}
+	return nil
+}
+
+func checkBackupManifest(manifest *models.BackupManifest, counts map[string]int) error {
+	if manifest.Version != models.BackupVersion {
+		return fmt.Errorf("%w: unsupported version %d", models.ErrInvalidBackup, manifest.Version)
+	}
+	var errs []error
+	for name, want := range manifest.Collections {
+		if counts[name] != want {
+			errs = append(errs, fmt.Errorf("%s has %d documents, manifest lists %d", name, counts[name], want))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%w: %w", models.ErrInvalidBackup, errors.Join(errs...))
+	}
+	return nil
+}
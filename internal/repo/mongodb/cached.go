@@ -0,0 +1,177 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/ttlcache"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// readCache is the read-through cache behind the caching repository decorators.
+// Any write through the decorator invalidates the whole cache: the cached
+// collections change rarely, and coarse invalidation cannot miss a dependent key.
+// Writes made by other instances become visible once the TTL expires.
+type readCache[V any] struct {
+	mu         sync.Mutex
+	entries    *ttlcache.Cache[string, V]
+	generation uint64
+}
+
+func newReadCache[V any](ttl time.Duration) *readCache[V] {
+	return &readCache[V]{
+		entries: ttlcache.New[string, V](ttl),
+	}
+}
+
+// get returns the cached value for key, or loads and caches it. Errors are never cached.
+func (c *readCache[V]) get(ctx context.Context, key string, load func() (V, error)) (V, error) {
+	if value, ok := c.entries.Get(key); ok {
+		reqstats.FromContext(ctx).AddCache(true)
+		return value, nil
+	}
+	reqstats.FromContext(ctx).AddCache(false)
+
+	c.mu.Lock()
+	generation := c.generation
+	c.mu.Unlock()
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// a write that landed while loading may have made value stale
+	if c.generation == generation {
+		c.entries.Set(key, value)
+	}
+	return value, nil
+}
+
+func (c *readCache[V]) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	c.entries.Clear()
+}
+
+// cacheKey namespaces key by the tenant of ctx, since reads are tenant scoped
+func cacheKey(ctx context.Context, parts ...string) string {
+	key := "-"
+	if tenantID := ctxTenantID(ctx); tenantID != nil {
+		key = tenantID.Hex()
+	} else if models.AllTenantsFromContext(ctx) {
+		key = "*"
+	}
+	for _, part := range parts {
+		key += "/" + part
+	}
+	return key
+}
+
+// clone returns a shallow copy so callers cannot mutate cached values
+func clone[T any](value *T) *T {
+	if value == nil {
+		return nil
+	}
+	copied := *value
+	return &copied
+}
+
+type cachedChatModeRepo struct {
+	ChatModeRepository
+	cache *readCache[*models.ChatMode]
+}
+
+// NewCachedChatModeRepository caches GetByName, which runs for every incoming message
+func NewCachedChatModeRepository(repo ChatModeRepository, ttl time.Duration) ChatModeRepository {
+	return &cachedChatModeRepo{
+		ChatModeRepository: repo,
+		cache:              newReadCache[*models.ChatMode](ttl),
+	}
+}
+
+func (r *cachedChatModeRepo) GetByName(ctx context.Context, name string) (*models.ChatMode, error) {
+	mode, err := r.cache.get(ctx, cacheKey(ctx, name), func() (*models.ChatMode, error) {
+		return r.ChatModeRepository.GetByName(ctx, name)
+	})
+	return clone(mode), err
+}
+
+func (r *cachedChatModeRepo) Create(ctx context.Context, mode *models.ChatMode) error {
+	defer r.cache.invalidate()
+	return r.ChatModeRepository.Create(ctx, mode)
+}
+
+func (r *cachedChatModeRepo) Update(ctx context.Context, mode *models.ChatMode) error {
+	defer r.cache.invalidate()
+	return r.ChatModeRepository.Update(ctx, mode)
+}
+
+func (r *cachedChatModeRepo) Upsert(ctx context.Context, mode *models.ChatMode) error {
+	defer r.cache.invalidate()
+	return r.ChatModeRepository.Upsert(ctx, mode)
+}
+
+func (r *cachedChatModeRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cache.invalidate()
+	return r.ChatModeRepository.Delete(ctx, id)
+}
+
+type cachedUserAttributeRepo struct {
+	UserAttributeRepository
+	cache *readCache[*models.UserAttribute]
+}
+
+// NewCachedUserAttributeRepository caches the single attribute lookups used while
+// handling messages, such as resolving a seller from its chotot_id
+func NewCachedUserAttributeRepository(repo UserAttributeRepository, ttl time.Duration) UserAttributeRepository {
+	return &cachedUserAttributeRepo{
+		UserAttributeRepository: repo,
+		cache:                   newReadCache[*models.UserAttribute](ttl),
+	}
+}
+
+func (r *cachedUserAttributeRepo) GetByUserIDAndKey(ctx context.Context, userID primitive.ObjectID, key string) (*models.UserAttribute, error) {
+	attr, err := r.cache.get(ctx, cacheKey(ctx, "user", userID.Hex(), key), func() (*models.UserAttribute, error) {
+		return r.UserAttributeRepository.GetByUserIDAndKey(ctx, userID, key)
+	})
+	return clone(attr), err
+}
+
+func (r *cachedUserAttributeRepo) GetByKeyAndValue(ctx context.Context, key, value string) (*models.UserAttribute, error) {
+	attr, err := r.cache.get(ctx, cacheKey(ctx, "value", key, value), func() (*models.UserAttribute, error) {
+		return r.UserAttributeRepository.GetByKeyAndValue(ctx, key, value)
+	})
+	return clone(attr), err
+}
+
+func (r *cachedUserAttributeRepo) Create(ctx context.Context, attr *models.UserAttribute) error {
+	defer r.cache.invalidate()
+	return r.UserAttributeRepository.Create(ctx, attr)
+}
+
+func (r *cachedUserAttributeRepo) Update(ctx context.Context, attr *models.UserAttribute) error {
+	defer r.cache.invalidate()
+	return r.UserAttributeRepository.Update(ctx, attr)
+}
+
+func (r *cachedUserAttributeRepo) Upsert(ctx context.Context, attr *models.UserAttribute) error {
+	defer r.cache.invalidate()
+	return r.UserAttributeRepository.Upsert(ctx, attr)
+}
+
+func (r *cachedUserAttributeRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	defer r.cache.invalidate()
+	return r.UserAttributeRepository.Delete(ctx, id)
+}
+
+func (r *cachedUserAttributeRepo) DeleteByUserIDAndKey(ctx context.Context, userID primitive.ObjectID, key string) error {
+	defer r.cache.invalidate()
+	return r.UserAttributeRepository.DeleteByUserIDAndKey(ctx, userID, key)
+}
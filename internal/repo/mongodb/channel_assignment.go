@@ -0,0 +1,125 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelAssignmentRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Assign gives the channel to the assignment's agent and returns the
+	// assignment it replaced, nil when the channel was unassigned
+	Assign(ctx context.Context, assignment *models.ChannelAssignment) (*models.ChannelAssignment, error)
+	// Get returns the channel's assignment, or nil when there is none
+	Get(ctx context.Context, channelID string) (*models.ChannelAssignment, error)
+	// List returns the team's assignments, only the agent's when agentID is set
+	List(ctx context.Context, teamID primitive.ObjectID, agentID *primitive.ObjectID) ([]*models.ChannelAssignment, error)
+	// Unassign removes the channel's assignment and returns it, nil when the
+	// channel was unassigned
+	Unassign(ctx context.Context, channelID string) (*models.ChannelAssignment, error)
+}
+
+type channelAssignmentRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelAssignmentRepository(db *DB) ChannelAssignmentRepository {
+	return &channelAssignmentRepo{
+		collection: db.Database.Collection("channel_assignments"),
+	}
+}
+
+func (r *channelAssignmentRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "channel_id", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "team_id", Value: 1}, {Key: "agent_id", Value: 1}, {Key: "assigned_at", Value: -1}},
+			Options: options.Index().SetName("team_agent_assigned_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel assignment indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelAssignmentRepo) Assign(ctx context.Context, assignment *models.ChannelAssignment) (*models.ChannelAssignment, error) {
+	assignment.AssignedAt = time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"team_id":     assignment.TeamID,
+			"seller_id":   assignment.SellerID,
+			"agent_id":    assignment.AgentID,
+			"assigned_by": assignment.AssignedBy,
+			"assigned_at": assignment.AssignedAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id": primitive.NewObjectID(),
+		},
+	}
+
+	var previous models.ChannelAssignment
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"channel_id": assignment.ChannelID}), update, opts).Decode(&previous)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to assign channel: %w", err)
+	}
+	return &previous, nil
+}
+
+func (r *channelAssignmentRepo) Get(ctx context.Context, channelID string) (*models.ChannelAssignment, error) {
+	var assignment models.ChannelAssignment
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&assignment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel assignment: %w", err)
+	}
+	return &assignment, nil
+}
+
+func (r *channelAssignmentRepo) List(ctx context.Context, teamID primitive.ObjectID, agentID *primitive.ObjectID) ([]*models.ChannelAssignment, error) {
+	filter := bson.M{"team_id": teamID}
+	if agentID != nil {
+		filter["agent_id"] = *agentID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "assigned_at", Value: -1}})
+	cursor, err := r.collection.Find(ctx, scoped(ctx, filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel assignments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	assignments := []*models.ChannelAssignment{}
+	if err := cursor.All(ctx, &assignments); err != nil {
+		return nil, fmt.Errorf("failed to decode channel assignments: %w", err)
+	}
+	return assignments, nil
+}
+
+func (r *channelAssignmentRepo) Unassign(ctx context.Context, channelID string) (*models.ChannelAssignment, error) {
+	var assignment models.ChannelAssignment
+	err := r.collection.FindOneAndDelete(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&assignment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to unassign channel: %w", err)
+	}
+	return &assignment, nil
+}
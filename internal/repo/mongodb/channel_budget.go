@@ -0,0 +1,119 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelBudgetRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Get returns the channel's counters of day, or nil when nothing was spent
+	Get(ctx context.Context, channelID, day string) (*models.ChannelBudget, error)
+	// Add adds replies and tokens to the channel's counters of day, which are
+	// kept for retention
+	Add(ctx context.Context, channelID, day string, replies, tokens int, retention time.Duration) error
+	// MarkExceeded records the limit the bot stopped at unless the day already
+	// has one, reporting whether it did
+	MarkExceeded(ctx context.Context, channelID, day string, limit models.BudgetLimit, retention time.Duration) (bool, error)
+	SetNotified(ctx context.Context, channelID, day string) error
+}
+
+type channelBudgetRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelBudgetRepository(db *DB) ChannelBudgetRepository {
+	return &channelBudgetRepo{
+		collection: db.Database.Collection("channel_budgets"),
+	}
+}
+
+func (r *channelBudgetRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+				{Key: "day", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_channel_day").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel budget indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelBudgetRepo) Get(ctx context.Context, channelID, day string) (*models.ChannelBudget, error) {
+	var budget models.ChannelBudget
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID, "day": day})).Decode(&budget)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel budget: %w", err)
+	}
+	return &budget, nil
+}
+
+func (r *channelBudgetRepo) Add(ctx context.Context, channelID, day string, replies, tokens int, retention time.Duration) error {
+	now := time.Now()
+	update := bson.M{
+		"$inc": bson.M{"replies": replies, "tokens": tokens},
+		"$set": bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{
+			"expires_at": now.Add(retention),
+			"created_at": now,
+		},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := r.collection.UpdateOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID, "day": day}), update, opts); err != nil {
+		return fmt.Errorf("failed to add to channel budget: %w", err)
+	}
+	return nil
+}
+
+func (r *channelBudgetRepo) MarkExceeded(ctx context.Context, channelID, day string, limit models.BudgetLimit, retention time.Duration) (bool, error) {
+	now := time.Now()
+	filter := exactTenant(ctx, bson.M{"channel_id": channelID, "day": day})
+	update := bson.M{
+		"$set": bson.M{"exceeded_limit": limit, "updated_at": now},
+		"$setOnInsert": bson.M{
+			"replies":    0,
+			"tokens":     0,
+			"expires_at": now.Add(retention),
+			"created_at": now,
+		},
+	}
+	filter["exceeded_limit"] = bson.M{"$exists": false}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		// the day already has a limit: the upsert ran into the unique index
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to mark channel budget exceeded: %w", err)
+	}
+	return result.ModifiedCount > 0 || result.UpsertedCount > 0, nil
+}
+
+func (r *channelBudgetRepo) SetNotified(ctx context.Context, channelID, day string) error {
+	update := bson.M{"$set": bson.M{"notified_at": time.Now()}}
+	if _, err := r.collection.UpdateOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID, "day": day}), update); err != nil {
+		return fmt.Errorf("failed to set channel budget notified: %w", err)
+	}
+	return nil
+}
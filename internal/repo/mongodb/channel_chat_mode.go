@@ -0,0 +1,92 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelChatModeRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Upsert(ctx context.Context, override *models.ChannelChatMode) error
+	Get(ctx context.Context, channelID string) (*models.ChannelChatMode, error)
+	Delete(ctx context.Context, channelID string) error
+}
+
+type channelChatModeRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelChatModeRepository(db *DB) ChannelChatModeRepository {
+	return &channelChatModeRepo{
+		collection: db.Database.Collection("channel_chat_modes"),
+	}
+}
+
+func (r *channelChatModeRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel chat mode indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelChatModeRepo) Upsert(ctx context.Context, override *models.ChannelChatMode) error {
+	now := time.Now()
+	filter := exactTenant(ctx, bson.M{"channel_id": override.ChannelID})
+	update := bson.M{
+		"$set": bson.M{
+			"chat_mode":  override.ChatMode,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(override)
+	if err != nil {
+		return fmt.Errorf("failed to upsert channel chat mode: %w", err)
+	}
+	return nil
+}
+
+// Get returns the channel's override, or nil when there is none
+func (r *channelChatModeRepo) Get(ctx context.Context, channelID string) (*models.ChannelChatMode, error) {
+	var override models.ChannelChatMode
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&override)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel chat mode: %w", err)
+	}
+	return &override, nil
+}
+
+func (r *channelChatModeRepo) Delete(ctx context.Context, channelID string) error {
+	result, err := r.collection.DeleteOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID}))
+	if err != nil {
+		return fmt.Errorf("failed to delete channel chat mode: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelClaimRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Claim gives the room to the claim's agent until inactivity passes. A
+	// claim the agent already holds is extended. Another agent's active claim
+	// returns models.ErrChannelClaimed unless override is set. The claim it
+	// replaced is returned, nil when the room was free or already the agent's.
+	Claim(ctx context.Context, claim *models.ChannelClaim, inactivity time.Duration, override bool) (*models.ChannelClaim, error)
+	// Get returns the room's active claim, or nil when there is none
+	Get(ctx context.Context, channelID string) (*models.ChannelClaim, error)
+	// Release removes the agent's claim on the room, reporting whether there
+	// was one
+	Release(ctx context.Context, channelID string, agentID primitive.ObjectID) (bool, error)
+}
+
+type channelClaimRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelClaimRepository(db *DB) ChannelClaimRepository {
+	return &channelClaimRepo{
+		collection: db.Database.Collection("channel_claims"),
+	}
+}
+
+// EnsureIndexes creates the one claim per room index and the TTL index that
+// removes claims once they pass expires_at
+func (r *channelClaimRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "channel_id", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel claim indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelClaimRepo) Claim(ctx context.Context, claim *models.ChannelClaim, inactivity time.Duration, override bool) (*models.ChannelClaim, error) {
+	now := time.Now()
+	claim.LastActiveAt = now
+	claim.ExpiresAt = now.Add(inactivity)
+
+	// the agent typing again only extends its claim
+	filter := exactTenant(ctx, bson.M{"channel_id": claim.ChannelID})
+	filter["agent_id"] = claim.AgentID
+	extend := bson.M{"$set": bson.M{"last_active_at": claim.LastActiveAt, "expires_at": claim.ExpiresAt}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, extend, opts).Decode(claim)
+	if err == nil {
+		return nil, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to extend channel claim: %w", err)
+	}
+
+	// the TTL monitor runs once a minute, so expired claims may still be
+	// stored and are taken over like missing ones
+	filter = exactTenant(ctx, bson.M{"channel_id": claim.ChannelID})
+	if !override {
+		filter["expires_at"] = bson.M{"$lte": now}
+	}
+	claim.ClaimedAt = now
+	update := bson.M{
+		"$set": bson.M{
+			"seller_id":      claim.SellerID,
+			"agent_id":       claim.AgentID,
+			"claimed_at":     claim.ClaimedAt,
+			"last_active_at": claim.LastActiveAt,
+			"expires_at":     claim.ExpiresAt,
+		},
+		"$setOnInsert": bson.M{
+			"_id": primitive.NewObjectID(),
+		},
+	}
+
+	// an active claim fails the filter, and the upsert then collides with it
+	var previous models.ChannelClaim
+	opts = options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	switch {
+	case mongo.IsDuplicateKeyError(err):
+		return nil, models.ErrChannelClaimed
+	case err == mongo.ErrNoDocuments:
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to claim channel: %w", err)
+	}
+	return &previous, nil
+}
+
+func (r *channelClaimRepo) Get(ctx context.Context, channelID string) (*models.ChannelClaim, error) {
+	filter := exactTenant(ctx, bson.M{"channel_id": channelID})
+	filter["expires_at"] = bson.M{"$gt": time.Now()}
+
+	var claim models.ChannelClaim
+	err := r.collection.FindOne(ctx, filter).Decode(&claim)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel claim: %w", err)
+	}
+	return &claim, nil
+}
+
+func (r *channelClaimRepo) Release(ctx context.Context, channelID string, agentID primitive.ObjectID) (bool, error) {
+	filter := exactTenant(ctx, bson.M{"channel_id": channelID})
+	filter["agent_id"] = agentID
+
+	result, err := r.collection.DeleteOne(ctx, filter)
+	if err != nil {
+		return false, fmt.Errorf("failed to release channel claim: %w", err)
+	}
+	return result.DeletedCount > 0, nil
+}
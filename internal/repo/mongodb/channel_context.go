@@ -0,0 +1,78 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelContextRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Get returns the channel's stored context, or nil when it was never compacted
+	Get(ctx context.Context, channelID string) (*models.ChannelContext, error)
+	// Save stores the channel's full context and its digest
+	Save(ctx context.Context, channelContext *models.ChannelContext) error
+}
+
+type channelContextRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelContextRepository(db *DB) ChannelContextRepository {
+	return &channelContextRepo{
+		collection: db.Database.Collection("channel_contexts"),
+	}
+}
+
+func (r *channelContextRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel context indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelContextRepo) Get(ctx context.Context, channelID string) (*models.ChannelContext, error) {
+	var channelContext models.ChannelContext
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&channelContext)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel context: %w", err)
+	}
+	return &channelContext, nil
+}
+
+func (r *channelContextRepo) Save(ctx context.Context, channelContext *models.ChannelContext) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"hash":       channelContext.Hash,
+			"full":       channelContext.Full,
+			"digest":     channelContext.Digest,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	filter := exactTenant(ctx, bson.M{"channel_id": channelContext.ChannelID})
+	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save channel context: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelCursorRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Claim moves the cursor of cursor.ChannelID to cursor.LastMessageAt,
+	// returning false when a message at or after it was already claimed. A
+	// successful claim sets cursor.PreviousMessageAt.
+	Claim(ctx context.Context, cursor *models.ChannelCursor) (bool, error)
+	// Release moves a claimed cursor back to cursor.PreviousMessageAt so the
+	// message can be claimed again, unless a newer message was claimed since
+	Release(ctx context.Context, cursor *models.ChannelCursor) error
+	// ListUpdatedSince returns the cursors of channels with a message claimed
+	// after since, across tenants
+	ListUpdatedSince(ctx context.Context, since time.Time) ([]*models.ChannelCursor, error)
+	// Get returns the channel's cursor, or nil when no message of the channel
+	// was claimed
+	Get(ctx context.Context, channelID string) (*models.ChannelCursor, error)
+}
+
+type channelCursorRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelCursorRepository(db *DB) ChannelCursorRepository {
+	return &channelCursorRepo{
+		collection: db.Database.Collection("channel_cursors"),
+	}
+}
+
+func (r *channelCursorRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "channel_id", Value: 1}},
+			Options: options.Index().SetName("uniq_channel_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "updated_at", Value: 1}},
+			Options: options.Index().SetName("updated_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel cursor indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelCursorRepo) Claim(ctx context.Context, cursor *models.ChannelCursor) (bool, error) {
+	// channel IDs are global in chat-api, so the cursor is not tenant scoped
+	filter := bson.M{
+		"channel_id":      cursor.ChannelID,
+		"last_message_at": bson.M{"$lt": cursor.LastMessageAt},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"tenant_id":       ctxTenantID(ctx),
+			"buyer_id":        cursor.BuyerID,
+			"chat_mode":       cursor.ChatMode,
+			"last_message_at": cursor.LastMessageAt,
+			"updated_at":      time.Now(),
+		},
+		"$setOnInsert": bson.M{
+			"_id": primitive.NewObjectID(),
+		},
+	}
+
+	// a newer cursor fails the filter, and the upsert then collides with it
+	var previous models.ChannelCursor
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&previous)
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	// no document before means the claim created the cursor
+	if err != nil && err != mongo.ErrNoDocuments {
+		return false, fmt.Errorf("failed to claim channel cursor: %w", err)
+	}
+	cursor.PreviousMessageAt = previous.LastMessageAt
+	return true, nil
+}
+
+func (r *channelCursorRepo) Release(ctx context.Context, cursor *models.ChannelCursor) error {
+	filter := bson.M{
+		"channel_id":      cursor.ChannelID,
+		"last_message_at": cursor.LastMessageAt,
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"last_message_at": cursor.PreviousMessageAt,
+			"updated_at":      time.Now(),
+		},
+	}
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to release channel cursor: %w", err)
+	}
+	return nil
+}
+
+func (r *channelCursorRepo) ListUpdatedSince(ctx context.Context, since time.Time) ([]*models.ChannelCursor, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"updated_at": bson.M{"$gte": since}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel cursors: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var cursors []*models.ChannelCursor
+	if err := cursor.All(ctx, &cursors); err != nil {
+		return nil, fmt.Errorf("failed to decode channel cursors: %w", err)
+	}
+	return cursors, nil
+}
+
+func (r *channelCursorRepo) Get(ctx context.Context, channelID string) (*models.ChannelCursor, error) {
+	var cursor models.ChannelCursor
+	err := r.collection.FindOne(ctx, bson.M{"channel_id": channelID}).Decode(&cursor)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel cursor: %w", err)
+	}
+	return &cursor, nil
+}
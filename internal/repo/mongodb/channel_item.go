@@ -0,0 +1,97 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelItemRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Seed returns the channel's items, storing seed as its items when the
+	// channel has none yet
+	Seed(ctx context.Context, channelID string, seed []models.ChannelItem) (*models.ChannelItems, error)
+	// Get returns the channel's items, or nil when none were stored
+	Get(ctx context.Context, channelID string) (*models.ChannelItems, error)
+	// SetItems replaces the channel's items
+	SetItems(ctx context.Context, channelID string, items []models.ChannelItem) error
+}
+
+type channelItemRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelItemRepository(db *DB) ChannelItemRepository {
+	return &channelItemRepo{
+		collection: db.Database.Collection("channel_items"),
+	}
+}
+
+func (r *channelItemRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel item indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelItemRepo) Seed(ctx context.Context, channelID string, seed []models.ChannelItem) (*models.ChannelItems, error) {
+	if seed == nil {
+		seed = []models.ChannelItem{}
+	}
+	now := time.Now()
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"items":      seed,
+			"created_at": now,
+			"updated_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var items models.ChannelItems
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"channel_id": channelID}), update, opts).Decode(&items)
+	if err != nil {
+		return nil, fmt.Errorf("failed to seed channel items: %w", err)
+	}
+	return &items, nil
+}
+
+func (r *channelItemRepo) Get(ctx context.Context, channelID string) (*models.ChannelItems, error) {
+	var items models.ChannelItems
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&items)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel items: %w", err)
+	}
+	return &items, nil
+}
+
+func (r *channelItemRepo) SetItems(ctx context.Context, channelID string, items []models.ChannelItem) error {
+	now := time.Now()
+	update := bson.M{
+		"$set":         bson.M{"items": items, "updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID}), update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to set channel items: %w", err)
+	}
+	return nil
+}
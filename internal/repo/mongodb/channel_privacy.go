@@ -0,0 +1,91 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChannelPrivacyRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Get returns the channel's privacy, or nil when nothing was made private
+	Get(ctx context.Context, channelID string) (*models.ChannelPrivacy, error)
+	SetPrivate(ctx context.Context, channelID string, private bool) (*models.ChannelPrivacy, error)
+	SetMessagePrivate(ctx context.Context, channelID, messageID string, private bool) (*models.ChannelPrivacy, error)
+}
+
+type channelPrivacyRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelPrivacyRepository(db *DB) ChannelPrivacyRepository {
+	return &channelPrivacyRepo{
+		collection: db.Database.Collection("channel_privacy"),
+	}
+}
+
+func (r *channelPrivacyRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel privacy indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelPrivacyRepo) Get(ctx context.Context, channelID string) (*models.ChannelPrivacy, error) {
+	var privacy models.ChannelPrivacy
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&privacy)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel privacy: %w", err)
+	}
+	return &privacy, nil
+}
+
+func (r *channelPrivacyRepo) SetPrivate(ctx context.Context, channelID string, private bool) (*models.ChannelPrivacy, error) {
+	return r.upsert(ctx, channelID, bson.M{"private": private}, bson.M{})
+}
+
+func (r *channelPrivacyRepo) SetMessagePrivate(ctx context.Context, channelID, messageID string, private bool) (*models.ChannelPrivacy, error) {
+	update := bson.M{"$addToSet": bson.M{"message_ids": messageID}}
+	if !private {
+		update = bson.M{"$pull": bson.M{"message_ids": messageID}}
+	}
+	return r.upsert(ctx, channelID, bson.M{}, update)
+}
+
+// upsert applies update with set to the channel's privacy, creating it when
+// the channel has none
+func (r *channelPrivacyRepo) upsert(ctx context.Context, channelID string, set, update bson.M) (*models.ChannelPrivacy, error) {
+	now := time.Now()
+	set["updated_at"] = now
+	update["$set"] = set
+	update["$setOnInsert"] = bson.M{
+		"_id":        primitive.NewObjectID(),
+		"created_at": now,
+	}
+
+	var privacy models.ChannelPrivacy
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"channel_id": channelID}), update, opts).Decode(&privacy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update channel privacy: %w", err)
+	}
+	return &privacy, nil
+}
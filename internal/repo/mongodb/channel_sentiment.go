@@ -0,0 +1,172 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sentimentSmoothing is the weight of the latest message in the moving score
+const sentimentSmoothing = 0.4
+
+type ChannelSentimentRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Record adds the score of a buyer message to the channel's aggregate and
+	// returns the updated aggregate
+	Record(ctx context.Context, channelID, sellerID, buyerID string, score models.SentimentScore, trendSize int) (*models.ChannelSentiment, error)
+	// Get returns the channel's aggregate, or nil when none was recorded
+	Get(ctx context.Context, channelID string) (*models.ChannelSentiment, error)
+	ListAlertedBySeller(ctx context.Context, sellerID string) ([]*models.ChannelSentiment, error)
+	// MarkAlerted sets alerted_at unless already set, reporting whether it did
+	MarkAlerted(ctx context.Context, channelID string) (bool, error)
+	// ClearAlert unsets alerted_at and resets the moving score, so earlier
+	// messages do not alert again right away
+	ClearAlert(ctx context.Context, channelID string) error
+}
+
+type channelSentimentRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChannelSentimentRepository(db *DB) ChannelSentimentRepository {
+	return &channelSentimentRepo{
+		collection: db.Database.Collection("channel_sentiments"),
+	}
+}
+
+func (r *channelSentimentRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_channel").SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "seller_id", Value: 1},
+				{Key: "alerted_at", Value: 1},
+			},
+			Options: options.Index().
+				SetName("tenant_seller_alerted_at").
+				SetPartialFilterExpression(bson.M{"alerted_at": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create channel sentiment indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *channelSentimentRepo) Record(ctx context.Context, channelID, sellerID, buyerID string, score models.SentimentScore, trendSize int) (*models.ChannelSentiment, error) {
+	now := time.Now()
+	counter := func(field string, sentiment models.Sentiment) bson.M {
+		inc := 0
+		if score.Sentiment == sentiment {
+			inc = 1
+		}
+		return bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + field, 0}}, inc}}
+	}
+
+	// a pipeline update, so the moving score and trend are computed in place;
+	// values go through $literal as strings starting with $ are field paths
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"seller_id": bson.M{"$literal": sellerID},
+			"buyer_id":  bson.M{"$literal": buyerID},
+			"messages":  bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$messages", 0}}, 1}},
+			"positive":  counter("positive", models.SentimentPositive),
+			"neutral":   counter("neutral", models.SentimentNeutral),
+			"negative":  counter("negative", models.SentimentNegative),
+			"score": bson.M{"$add": bson.A{
+				bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$score", score.Score}}, 1 - sentimentSmoothing}},
+				score.Score * sentimentSmoothing,
+			}},
+			"trend": bson.M{"$slice": bson.A{
+				bson.M{"$concatArrays": bson.A{bson.M{"$ifNull": bson.A{"$trend", bson.A{}}}, bson.A{bson.M{"$literal": score}}}},
+				-trendSize,
+			}},
+			"created_at": bson.M{"$ifNull": bson.A{"$created_at", now}},
+			"updated_at": now,
+		}}},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var sentiment models.ChannelSentiment
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"channel_id": channelID}), update, opts).Decode(&sentiment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record channel sentiment: %w", err)
+	}
+	return &sentiment, nil
+}
+
+func (r *channelSentimentRepo) Get(ctx context.Context, channelID string) (*models.ChannelSentiment, error) {
+	var sentiment models.ChannelSentiment
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID})).Decode(&sentiment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get channel sentiment: %w", err)
+	}
+	return &sentiment, nil
+}
+
+// ListAlertedBySeller returns the seller's alerted channels, latest alert first
+func (r *channelSentimentRepo) ListAlertedBySeller(ctx context.Context, sellerID string) ([]*models.ChannelSentiment, error) {
+	filter := scoped(ctx, bson.M{
+		"seller_id":  sellerID,
+		"alerted_at": bson.M{"$exists": true},
+	})
+	opts := options.Find().SetSort(bson.D{{Key: "alerted_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerted channels: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sentiments []*models.ChannelSentiment
+	if err := cursor.All(ctx, &sentiments); err != nil {
+		return nil, fmt.Errorf("failed to decode channel sentiments: %w", err)
+	}
+	return sentiments, nil
+}
+
+func (r *channelSentimentRepo) MarkAlerted(ctx context.Context, channelID string) (bool, error) {
+	filter := exactTenant(ctx, bson.M{"channel_id": channelID})
+	filter["alerted_at"] = bson.M{"$exists": false}
+	now := time.Now()
+	update := bson.M{"$set": bson.M{"alerted_at": now, "updated_at": now}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark channel alerted: %w", err)
+	}
+	return result.ModifiedCount > 0, nil
+}
+
+func (r *channelSentimentRepo) ClearAlert(ctx context.Context, channelID string) error {
+	filter := exactTenant(ctx, bson.M{"channel_id": channelID})
+	filter["alerted_at"] = bson.M{"$exists": true}
+	update := bson.M{
+		"$set":   bson.M{"score": 0, "updated_at": time.Now()},
+		"$unset": bson.M{"alerted_at": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to clear channel alert: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -30,6 +30,9 @@ func NewChatActivityRepository(db *DB) ChatActivityRepository {
 
 func (r *chatActivityRepo) Create(ctx context.Context, activity *models.ChatActivity) error {
 	activity.ID = primitive.NewObjectID()
+	if activity.TenantID == nil {
+		activity.TenantID = ctxTenantID(ctx)
+	}
 	activity.ExecutedAt = time.Now()
 	activity.CreatedAt = time.Now()
 
@@ -41,7 +44,7 @@ func (r *chatActivityRepo) Create(ctx context.Context, activity *models.ChatActi
 }
 
 func (r *chatActivityRepo) GetBySessionID(ctx context.Context, sessionID primitive.ObjectID) ([]*models.ChatActivity, error) {
-	filter := bson.M{"session_id": sessionID}
+	filter := scoped(ctx, bson.M{"session_id": sessionID})
 	opts := options.Find().SetSort(bson.D{{Key: "executed_at", Value: 1}})
 
 	cursor, err := r.collection.Find(ctx, filter, opts)
@@ -67,7 +70,7 @@ func (r *chatActivityRepo) GetBySessionID(ctx context.Context, sessionID primiti
 }
 
 func (r *chatActivityRepo) GetByChannelID(ctx context.Context, channelID string, limit int) ([]*models.ChatActivity, error) {
-	filter := bson.M{"channel_id": channelID}
+	filter := scoped(ctx, bson.M{"channel_id": channelID})
 	opts := options.Find().
 		SetSort(bson.D{{Key: "executed_at", Value: -1}}).
 		SetLimit(int64(limit))
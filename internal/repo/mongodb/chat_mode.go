@@ -32,8 +32,17 @@ func NewChatModeRepository(db *DB) ChatModeRepository {
 }
 
 func (r *chatModeRepo) GetByName(ctx context.Context, name string) (*models.ChatMode, error) {
+	// A tenant's own mode takes precedence over the global default of the same
+	// name. Without a tenant only the global default is used, so a mode is
+	// never picked from some other tenant.
+	filter := bson.M{"name": name, "tenant_id": nil}
+	if tenantID := ctxTenantID(ctx); tenantID != nil {
+		filter["tenant_id"] = bson.M{"$in": bson.A{*tenantID, nil}}
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "tenant_id", Value: -1}})
+
 	var mode models.ChatMode
-	err := r.collection.FindOne(ctx, bson.M{"name": name}).Decode(&mode)
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&mode)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("chat mode '%s' not found", name)
@@ -45,6 +54,9 @@ func (r *chatModeRepo) GetByName(ctx context.Context, name string) (*models.Chat
 
 func (r *chatModeRepo) Create(ctx context.Context, mode *models.ChatMode) error {
 	mode.ID = primitive.NewObjectID()
+	if mode.TenantID == nil {
+		mode.TenantID = ctxTenantID(ctx)
+	}
 	mode.CreatedAt = time.Now()
 	mode.UpdatedAt = time.Now()
 
@@ -58,7 +70,7 @@ func (r *chatModeRepo) Create(ctx context.Context, mode *models.ChatMode) error
 func (r *chatModeRepo) Update(ctx context.Context, mode *models.ChatMode) error {
 	mode.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": mode.ID}
+	filter := scoped(ctx, bson.M{"_id": mode.ID})
 	update := bson.M{"$set": mode}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
@@ -71,7 +83,8 @@ func (r *chatModeRepo) Update(ctx context.Context, mode *models.ChatMode) error
 func (r *chatModeRepo) Upsert(ctx context.Context, mode *models.ChatMode) error {
 	now := time.Now()
 
-	filter := bson.M{"name": mode.Name}
+	// a global upsert never overwrites a tenant's customised mode of the same name
+	filter := exactTenant(ctx, bson.M{"name": mode.Name})
 	update := bson.M{
 		"$set": bson.M{
 			"prompt_template":     mode.PromptTemplate,
@@ -81,10 +94,13 @@ func (r *chatModeRepo) Upsert(ctx context.Context, mode *models.ChatMode) error
 			"max_iterations":      mode.MaxIterations,
 			"max_prompt_tokens":   mode.MaxPromptTokens,
 			"max_response_tokens": mode.MaxResponseTokens,
+			"output_schema":       mode.OutputSchema,
+			"session_timeout":     mode.SessionTimeout,
 			"updated_at":          now,
 		},
 		"$setOnInsert": bson.M{
 			"_id":        primitive.NewObjectID(),
+			"tenant_id":  ctxTenantID(ctx),
 			"created_at": now,
 		},
 	}
@@ -97,7 +113,7 @@ func (r *chatModeRepo) Upsert(ctx context.Context, mode *models.ChatMode) error
 }
 
 func (r *chatModeRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	_, err := r.collection.DeleteOne(ctx, scoped(ctx, bson.M{"_id": id}))
 	if err != nil {
 		return fmt.Errorf("failed to delete chat mode: %w", err)
 	}
@@ -105,7 +121,7 @@ func (r *chatModeRepo) Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 func (r *chatModeRepo) List(ctx context.Context) ([]*models.ChatMode, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list chat modes: %w", err)
 	}
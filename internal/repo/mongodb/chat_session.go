@@ -9,15 +9,51 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type ChatSessionRepository interface {
+	EnsureIndexes(ctx context.Context) error
 	Create(ctx context.Context, session *models.ChatSession) error
 	GetByChannelAndUser(ctx context.Context, channelID, userID string) (*models.ChatSession, error)
 	GetByID(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error)
 	Update(ctx context.Context, session *models.ChatSession) error
-	EndSession(ctx context.Context, id primitive.ObjectID) error
+	// EndSession ends the session for reason
+	EndSession(ctx context.Context, id primitive.ObjectID, reason models.SessionEndReason) error
 	ListActiveSessions(ctx context.Context) ([]*models.ChatSession, error)
+	CountStartedSince(ctx context.Context, since time.Time) (int64, error)
+	// AddUsage adds the usage of a generation to the session and returns the
+	// updated session
+	AddUsage(ctx context.Context, id primitive.ObjectID, usage models.SessionUsage) (*models.ChatSession, error)
+	SetBudgetExceeded(ctx context.Context, id primitive.ObjectID, limit models.BudgetLimit) error
+	// SetOutput stores the structured response of the session's chat mode
+	SetOutput(ctx context.Context, id primitive.ObjectID, output map[string]any) error
+	// ListByBuyerAndSeller returns the latest sessions of a buyer with a
+	// seller, most recent first
+	ListByBuyerAndSeller(ctx context.Context, buyerID, sellerID string, limit int) ([]*models.ChatSession, error)
+	// GetLatestByChannel returns the channel's most recent session, nil when
+	// it has none
+	GetLatestByChannel(ctx context.Context, channelID string) (*models.ChatSession, error)
+	// SetOutcome stores the outcome of a session. Unless overwrite is set, a
+	// session that already has one is left as is and false is returned.
+	SetOutcome(ctx context.Context, id primitive.ObjectID, outcome *models.SessionOutcomeRecord, overwrite bool) (bool, error)
+	// ListUnresolved returns the latest session of each channel active since
+	// since, when it started before before and has no outcome yet
+	ListUnresolved(ctx context.Context, since, before time.Time) ([]*models.ChatSession, error)
+	// CountOutcomes counts the outcomes set in [since, until) per chat mode
+	// and prompt version
+	CountOutcomes(ctx context.Context, since, until time.Time) ([]models.ConversionCount, error)
+	// AbandonInactive marks the active sessions not updated since before as
+	// abandoned, returning how many it changed
+	AbandonInactive(ctx context.Context, before time.Time) (int64, error)
+	// ListChannelsBySeller returns the channels the seller has sessions in
+	ListChannelsBySeller(ctx context.Context, sellerID string) ([]string, error)
+	// ListInactive returns up to limit active sessions of the chat mode not
+	// updated since before, least recently updated first
+	ListInactive(ctx context.Context, chatMode string, before time.Time, limit int) ([]*models.ChatSession, error)
+	// EndInactive ends the session for inactivity unless it was updated since
+	// before, returning whether it ended it
+	EndInactive(ctx context.Context, id primitive.ObjectID, before time.Time) (bool, error)
 }
 
 type chatSessionRepo struct {
@@ -30,8 +66,49 @@ func NewChatSessionRepository(db *DB) ChatSessionRepository {
 	}
 }
 
+func (r *chatSessionRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "seller_id", Value: 1},
+				{Key: "started_at", Value: -1},
+			},
+			Options: options.Index().SetName("tenant_user_seller_started_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+				{Key: "started_at", Value: -1},
+			},
+			Options: options.Index().SetName("tenant_channel_started_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "started_at", Value: -1}},
+			Options: options.Index().SetName("started_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "outcome.at", Value: -1}},
+			Options: options.Index().SetName("tenant_outcome_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "chat_mode", Value: 1}, {Key: "updated_at", Value: 1}},
+			Options: options.Index().SetName("status_chat_mode_updated_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chat session indexes: %w", err)
+	}
+	return nil
+}
+
 func (r *chatSessionRepo) Create(ctx context.Context, session *models.ChatSession) error {
 	session.ID = primitive.NewObjectID()
+	if session.TenantID == nil {
+		session.TenantID = ctxTenantID(ctx)
+	}
 	session.CreatedAt = time.Now()
 	session.UpdatedAt = time.Now()
 
@@ -43,11 +120,11 @@ func (r *chatSessionRepo) Create(ctx context.Context, session *models.ChatSessio
 }
 
 func (r *chatSessionRepo) GetByChannelAndUser(ctx context.Context, channelID, userID string) (*models.ChatSession, error) {
-	filter := bson.M{
+	filter := scoped(ctx, bson.M{
 		"channel_id": channelID,
 		"user_id":    userID,
 		"status":     models.SessionStatusActive,
-	}
+	})
 
 	var session models.ChatSession
 	err := r.collection.FindOne(ctx, filter).Decode(&session)
@@ -62,10 +139,10 @@ func (r *chatSessionRepo) GetByChannelAndUser(ctx context.Context, channelID, us
 
 func (r *chatSessionRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error) {
 	var session models.ChatSession
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&session)
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&session)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			return nil, fmt.Errorf("chat session not found")
+			return nil, models.ErrNotFound
 		}
 		return nil, fmt.Errorf("failed to get chat session: %w", err)
 	}
@@ -75,7 +152,7 @@ func (r *chatSessionRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*
 func (r *chatSessionRepo) Update(ctx context.Context, session *models.ChatSession) error {
 	session.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": session.ID}
+	filter := scoped(ctx, bson.M{"_id": session.ID})
 	update := bson.M{"$set": session}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
@@ -85,12 +162,13 @@ func (r *chatSessionRepo) Update(ctx context.Context, session *models.ChatSessio
 	return nil
 }
 
-func (r *chatSessionRepo) EndSession(ctx context.Context, id primitive.ObjectID) error {
+func (r *chatSessionRepo) EndSession(ctx context.Context, id primitive.ObjectID, reason models.SessionEndReason) error {
 	now := time.Now()
-	filter := bson.M{"_id": id}
+	filter := scoped(ctx, bson.M{"_id": id})
 	update := bson.M{
 		"$set": bson.M{
 			"status":     models.SessionStatusEnded,
+			"end_reason": reason,
 			"ended_at":   now,
 			"updated_at": now,
 		},
@@ -103,8 +181,54 @@ func (r *chatSessionRepo) EndSession(ctx context.Context, id primitive.ObjectID)
 	return nil
 }
 
+func (r *chatSessionRepo) AddUsage(ctx context.Context, id primitive.ObjectID, usage models.SessionUsage) (*models.ChatSession, error) {
+	filter := scoped(ctx, bson.M{"_id": id})
+	update := bson.M{
+		"$inc": bson.M{
+			"usage.generations":   usage.Generations,
+			"usage.input_tokens":  usage.InputTokens,
+			"usage.output_tokens": usage.OutputTokens,
+		},
+		"$set": bson.M{"updated_at": time.Now()},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var session models.ChatSession
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&session); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to add chat session usage: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *chatSessionRepo) SetBudgetExceeded(ctx context.Context, id primitive.ObjectID, limit models.BudgetLimit) error {
+	filter := scoped(ctx, bson.M{"_id": id})
+	update := bson.M{"$set": bson.M{"budget_exceeded": limit, "updated_at": time.Now()}}
+
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to set chat session budget exceeded: %w", err)
+	}
+	return nil
+}
+
+func (r *chatSessionRepo) SetOutput(ctx context.Context, id primitive.ObjectID, output map[string]any) error {
+	filter := scoped(ctx, bson.M{"_id": id})
+	update := bson.M{"$set": bson.M{"output": output, "updated_at": time.Now()}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to set chat session output: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
 func (r *chatSessionRepo) ListActiveSessions(ctx context.Context) ([]*models.ChatSession, error) {
-	filter := bson.M{"status": models.SessionStatusActive}
+	filter := scoped(ctx, bson.M{"status": models.SessionStatusActive})
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list active sessions: %w", err)
@@ -126,3 +250,204 @@ func (r *chatSessionRepo) ListActiveSessions(ctx context.Context) ([]*models.Cha
 
 	return sessions, nil
 }
+
+func (r *chatSessionRepo) CountStartedSince(ctx context.Context, since time.Time) (int64, error) {
+	filter := scoped(ctx, bson.M{"started_at": bson.M{"$gte": since}})
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count chat sessions: %w", err)
+	}
+	return count, nil
+}
+
+func (r *chatSessionRepo) AbandonInactive(ctx context.Context, before time.Time) (int64, error) {
+	now := time.Now()
+	filter := scoped(ctx, bson.M{
+		"status":     models.SessionStatusActive,
+		"updated_at": bson.M{"$lt": before},
+	})
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.SessionStatusAbandoned,
+			"end_reason": models.SessionEndedAbandoned,
+			"ended_at":   now,
+			"updated_at": now,
+		},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to abandon inactive chat sessions: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *chatSessionRepo) ListChannelsBySeller(ctx context.Context, sellerID string) ([]string, error) {
+	values, err := r.collection.Distinct(ctx, "channel_id", scoped(ctx, bson.M{"seller_id": sellerID}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list seller channels: %w", err)
+	}
+
+	channelIDs := make([]string, 0, len(values))
+	for _, value := range values {
+		if channelID, ok := value.(string); ok {
+			channelIDs = append(channelIDs, channelID)
+		}
+	}
+	return channelIDs, nil
+}
+
+func (r *chatSessionRepo) ListByBuyerAndSeller(ctx context.Context, buyerID, sellerID string, limit int) ([]*models.ChatSession, error) {
+	filter := scoped(ctx, bson.M{
+		"user_id":   buyerID,
+		"seller_id": sellerID,
+	})
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat sessions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*models.ChatSession
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode chat sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *chatSessionRepo) GetLatestByChannel(ctx context.Context, channelID string) (*models.ChatSession, error) {
+	filter := scoped(ctx, bson.M{"channel_id": channelID})
+	opts := options.FindOne().SetSort(bson.D{{Key: "started_at", Value: -1}})
+
+	var session models.ChatSession
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get latest chat session: %w", err)
+	}
+	return &session, nil
+}
+
+func (r *chatSessionRepo) SetOutcome(ctx context.Context, id primitive.ObjectID, outcome *models.SessionOutcomeRecord, overwrite bool) (bool, error) {
+	filter := scoped(ctx, bson.M{"_id": id})
+	if !overwrite {
+		filter["outcome"] = bson.M{"$exists": false}
+	}
+	update := bson.M{"$set": bson.M{"outcome": outcome, "updated_at": time.Now()}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to set chat session outcome: %w", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *chatSessionRepo) ListUnresolved(ctx context.Context, since, before time.Time) ([]*models.ChatSession, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: scoped(ctx, bson.M{"started_at": bson.M{"$gte": since}})}},
+		{{Key: "$sort", Value: bson.D{{Key: "started_at", Value: -1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":     bson.D{{Key: "tenant_id", Value: "$tenant_id"}, {Key: "channel_id", Value: "$channel_id"}},
+			"session": bson.M{"$first": "$$ROOT"},
+		}}},
+		{{Key: "$replaceRoot", Value: bson.M{"newRoot": "$session"}}},
+		{{Key: "$match", Value: bson.M{
+			"started_at": bson.M{"$lt": before},
+			"outcome":    bson.M{"$exists": false},
+		}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list unresolved chat sessions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*models.ChatSession
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode chat sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *chatSessionRepo) CountOutcomes(ctx context.Context, since, until time.Time) ([]models.ConversionCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: scoped(ctx, bson.M{"outcome.at": bson.M{"$gte": since, "$lt": until}})}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.D{
+				{Key: "chat_mode", Value: "$chat_mode"},
+				{Key: "prompt_version", Value: "$prompt_version"},
+				{Key: "outcome", Value: "$outcome.outcome"},
+			},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":            0,
+			"chat_mode":      "$_id.chat_mode",
+			"prompt_version": "$_id.prompt_version",
+			"outcome":        "$_id.outcome",
+			"count":          1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "chat_mode", Value: 1}, {Key: "prompt_version", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count chat session outcomes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var counts []models.ConversionCount
+	if err := cursor.All(ctx, &counts); err != nil {
+		return nil, fmt.Errorf("failed to decode outcome counts: %w", err)
+	}
+	return counts, nil
+}
+
+func (r *chatSessionRepo) ListInactive(ctx context.Context, chatMode string, before time.Time, limit int) ([]*models.ChatSession, error) {
+	filter := scoped(ctx, bson.M{
+		"status":     models.SessionStatusActive,
+		"chat_mode":  chatMode,
+		"updated_at": bson.M{"$lt": before},
+	})
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list inactive chat sessions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var sessions []*models.ChatSession
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, fmt.Errorf("failed to decode chat sessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (r *chatSessionRepo) EndInactive(ctx context.Context, id primitive.ObjectID, before time.Time) (bool, error) {
+	now := time.Now()
+	filter := scoped(ctx, bson.M{
+		"_id":        id,
+		"status":     models.SessionStatusActive,
+		"updated_at": bson.M{"$lt": before},
+	})
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.SessionStatusEnded,
+			"end_reason": models.SessionEndedInactive,
+			"ended_at":   now,
+			"updated_at": now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to end inactive chat session: %w", err)
+	}
+	return result.ModifiedCount > 0, nil
+}
@@ -0,0 +1,109 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ChototLinkRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// GetByUserID returns nil when the user never started a link
+	GetByUserID(ctx context.Context, userID primitive.ObjectID) (*models.ChototLink, error)
+	// GetPendingByCode returns the unexpired pending link of chototID whose
+	// code hashes to codeHash, or nil. It is not tenant scoped since incoming
+	// chat messages carry no tenant.
+	GetPendingByCode(ctx context.Context, chototID, codeHash string) (*models.ChototLink, error)
+	Upsert(ctx context.Context, link *models.ChototLink) error
+}
+
+type chototLinkRepo struct {
+	collection *mongo.Collection
+}
+
+func NewChototLinkRepository(db *DB) ChototLinkRepository {
+	return &chototLinkRepo{
+		collection: db.Database.Collection("chotot_links"),
+	}
+}
+
+func (r *chototLinkRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_user").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "chotot_id", Value: 1}, {Key: "status", Value: 1}},
+			Options: options.Index().SetName("chotot_id_status"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create chotot link indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *chototLinkRepo) GetByUserID(ctx context.Context, userID primitive.ObjectID) (*models.ChototLink, error) {
+	var link models.ChototLink
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"user_id": userID})).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get chotot link: %w", err)
+	}
+	return &link, nil
+}
+
+func (r *chototLinkRepo) GetPendingByCode(ctx context.Context, chototID, codeHash string) (*models.ChototLink, error) {
+	filter := bson.M{
+		"chotot_id":       chototID,
+		"status":          models.ChototLinkPending,
+		"code_hash":       codeHash,
+		"code_expires_at": bson.M{"$gt": time.Now()},
+	}
+
+	var link models.ChototLink
+	err := r.collection.FindOne(ctx, filter).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get pending chotot link: %w", err)
+	}
+	return &link, nil
+}
+
+func (r *chototLinkRepo) Upsert(ctx context.Context, link *models.ChototLink) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"chotot_id":       link.ChototID,
+			"chotot_oid":      link.ChototOID,
+			"status":          link.Status,
+			"code_hash":       link.CodeHash,
+			"code_expires_at": link.CodeExpiresAt,
+			"verified_at":     link.VerifiedAt,
+			"revoked_at":      link.RevokedAt,
+			"updated_at":      now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"user_id": link.UserID}), update, opts).Decode(link)
+	if err != nil {
+		return fmt.Errorf("failed to upsert chotot link: %w", err)
+	}
+	return nil
+}
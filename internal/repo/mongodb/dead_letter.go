@@ -0,0 +1,202 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultDeadLetterLimit = 100
+
+type DeadLetterRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, letter *models.DeadLetter) error
+	// GetByID is not tenant scoped, dead letters are handled by admins
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.DeadLetter, error)
+	// List returns letters matching filter across tenants, latest first
+	List(ctx context.Context, filter models.DeadLetterFilter) ([]*models.DeadLetter, error)
+	// ListPending returns up to limit pending letters created after the
+	// letter afterID, oldest first; pass primitive.NilObjectID to start over
+	ListPending(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*models.DeadLetter, error)
+	// Claim moves a pending letter, or one left requeuing since before
+	// staleBefore, to requeuing. It returns models.ErrNotFound when the letter
+	// is resolved or being requeued.
+	Claim(ctx context.Context, id primitive.ObjectID, staleBefore time.Time) (*models.DeadLetter, error)
+	// Finish ends a requeue: the letter is resolved when errMessage is empty,
+	// and pending again with errMessage otherwise
+	Finish(ctx context.Context, id primitive.ObjectID, errMessage string) (*models.DeadLetter, error)
+}
+
+type deadLetterRepo struct {
+	collection *mongo.Collection
+}
+
+func NewDeadLetterRepository(db *DB) DeadLetterRepository {
+	return &deadLetterRepo{
+		collection: db.Database.Collection("dead_letters"),
+	}
+}
+
+func (r *deadLetterRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("status_created_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "channel_id", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("channel_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *deadLetterRepo) Create(ctx context.Context, letter *models.DeadLetter) error {
+	now := time.Now()
+	letter.ID = primitive.NewObjectID()
+	letter.TenantID = ctxTenantID(ctx)
+	letter.Status = models.DeadLetterPending
+	letter.CreatedAt = now
+	letter.UpdatedAt = now
+
+	if _, err := r.collection.InsertOne(ctx, letter); err != nil {
+		return fmt.Errorf("failed to create dead letter: %w", err)
+	}
+	return nil
+}
+
+func (r *deadLetterRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.DeadLetter, error) {
+	var letter models.DeadLetter
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&letter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return &letter, nil
+}
+
+func (r *deadLetterRepo) List(ctx context.Context, filter models.DeadLetterFilter) ([]*models.DeadLetter, error) {
+	query := bson.M{}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.ChannelID != "" {
+		query["channel_id"] = filter.ChannelID
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultDeadLetterLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list dead letters: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var letters []*models.DeadLetter
+	if err := cursor.All(ctx, &letters); err != nil {
+		return nil, fmt.Errorf("failed to decode dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+func (r *deadLetterRepo) ListPending(ctx context.Context, afterID primitive.ObjectID, limit int) ([]*models.DeadLetter, error) {
+	query := bson.M{
+		"status": models.DeadLetterPending,
+		"_id":    bson.M{"$gt": afterID},
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending dead letters: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var letters []*models.DeadLetter
+	if err := cursor.All(ctx, &letters); err != nil {
+		return nil, fmt.Errorf("failed to decode dead letters: %w", err)
+	}
+	return letters, nil
+}
+
+func (r *deadLetterRepo) Claim(ctx context.Context, id primitive.ObjectID, staleBefore time.Time) (*models.DeadLetter, error) {
+	filter := bson.M{
+		"_id": id,
+		"$or": bson.A{
+			bson.M{"status": models.DeadLetterPending},
+			bson.M{"status": models.DeadLetterRequeuing, "updated_at": bson.M{"$lt": staleBefore}},
+		},
+	}
+	update := bson.M{"$set": bson.M{
+		"status":     models.DeadLetterRequeuing,
+		"updated_at": time.Now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var letter models.DeadLetter
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&letter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to claim dead letter: %w", err)
+	}
+	return &letter, nil
+}
+
+func (r *deadLetterRepo) Finish(ctx context.Context, id primitive.ObjectID, errMessage string) (*models.DeadLetter, error) {
+	now := time.Now()
+	set := bson.M{"updated_at": now}
+	if errMessage == "" {
+		set["status"] = models.DeadLetterResolved
+		set["resolved_at"] = now
+	} else {
+		set["status"] = models.DeadLetterPending
+		set["error"] = errMessage
+	}
+	update := bson.M{
+		"$set": set,
+		"$inc": bson.M{"attempts": 1},
+	}
+	filter := bson.M{"_id": id, "status": models.DeadLetterRequeuing}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var letter models.DeadLetter
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&letter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to finish dead letter: %w", err)
+	}
+	return &letter, nil
+}
@@ -0,0 +1,121 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DraftRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Upsert(ctx context.Context, draft *models.Draft) error
+	Get(ctx context.Context, channelID, userID string) (*models.Draft, error)
+	ListByUser(ctx context.Context, userID string) ([]*models.Draft, error)
+	Delete(ctx context.Context, channelID, userID string) error
+}
+
+type draftRepo struct {
+	collection *mongo.Collection
+}
+
+func NewDraftRepository(db *DB) DraftRepository {
+	return &draftRepo{
+		collection: db.Database.Collection("drafts"),
+	}
+}
+
+func (r *draftRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "user_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_user_channel").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create draft indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *draftRepo) Upsert(ctx context.Context, draft *models.Draft) error {
+	now := time.Now()
+	filter := exactTenant(ctx, bson.M{"channel_id": draft.ChannelID, "user_id": draft.UserID})
+	update := bson.M{
+		"$set": bson.M{
+			"message":    draft.Message,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(draft)
+	if err != nil {
+		return fmt.Errorf("failed to upsert draft: %w", err)
+	}
+	return nil
+}
+
+// Get returns the user's draft for the channel, or nil when there is none
+func (r *draftRepo) Get(ctx context.Context, channelID, userID string) (*models.Draft, error) {
+	var draft models.Draft
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID, "user_id": userID})).Decode(&draft)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	return &draft, nil
+}
+
+// ListByUser returns every draft of the user, most recently edited first
+func (r *draftRepo) ListByUser(ctx context.Context, userID string) ([]*models.Draft, error) {
+	filter := exactTenant(ctx, bson.M{"user_id": userID})
+	opts := options.Find().SetSort(bson.D{{Key: "updated_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	drafts := []*models.Draft{}
+	for cursor.Next(ctx) {
+		var draft models.Draft
+		if err := cursor.Decode(&draft); err != nil {
+			return nil, fmt.Errorf("failed to decode draft: %w", err)
+		}
+		drafts = append(drafts, &draft)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return drafts, nil
+}
+
+func (r *draftRepo) Delete(ctx context.Context, channelID, userID string) error {
+	result, err := r.collection.DeleteOne(ctx, exactTenant(ctx, bson.M{"channel_id": channelID, "user_id": userID}))
+	if err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EmailVerificationRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Replace stores the verification, replacing the user's pending one
+	Replace(ctx context.Context, verification *models.EmailVerification) error
+	// GetByUserID returns the user's unexpired verification, or nil when
+	// there is none
+	GetByUserID(ctx context.Context, userID primitive.ObjectID) (*models.EmailVerification, error)
+	// GetByTokenHash returns the unexpired verification of a link, or nil.
+	// Links are opened without credentials, so it is not scoped to the
+	// tenant of ctx.
+	GetByTokenHash(ctx context.Context, tokenHash string) (*models.EmailVerification, error)
+	// IncrementAttempts counts a wrong code and returns the new count
+	IncrementAttempts(ctx context.Context, id primitive.ObjectID) (int, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+}
+
+type emailVerificationRepo struct {
+	collection *mongo.Collection
+}
+
+func NewEmailVerificationRepository(db *DB) EmailVerificationRepository {
+	return &emailVerificationRepo{
+		collection: db.Database.Collection("email_verifications"),
+	}
+}
+
+// EnsureIndexes keeps one verification per user, indexes link tokens and
+// removes expired verifications through expires_at
+func (r *emailVerificationRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("uniq_user_id").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "token_hash", Value: 1}},
+			Options: options.Index().SetName("token_hash"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create email verification indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *emailVerificationRepo) Replace(ctx context.Context, verification *models.EmailVerification) error {
+	verification.ID = primitive.NewObjectID()
+	if verification.TenantID == nil {
+		verification.TenantID = ctxTenantID(ctx)
+	}
+	verification.CreatedAt = time.Now()
+
+	opts := options.Replace().SetUpsert(true)
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"user_id": verification.UserID}, verification, opts)
+	if err != nil {
+		return fmt.Errorf("failed to save email verification: %w", err)
+	}
+	return nil
+}
+
+func (r *emailVerificationRepo) GetByUserID(ctx context.Context, userID primitive.ObjectID) (*models.EmailVerification, error) {
+	return r.findOne(ctx, scoped(ctx, bson.M{"user_id": userID, "expires_at": bson.M{"$gt": time.Now()}}))
+}
+
+func (r *emailVerificationRepo) GetByTokenHash(ctx context.Context, tokenHash string) (*models.EmailVerification, error) {
+	return r.findOne(ctx, bson.M{"token_hash": tokenHash, "expires_at": bson.M{"$gt": time.Now()}})
+}
+
+func (r *emailVerificationRepo) findOne(ctx context.Context, filter bson.M) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	err := r.collection.FindOne(ctx, filter).Decode(&verification)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get email verification: %w", err)
+	}
+	return &verification, nil
+}
+
+func (r *emailVerificationRepo) IncrementAttempts(ctx context.Context, id primitive.ObjectID) (int, error) {
+	var verification models.EmailVerification
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$inc": bson.M{"attempts": 1}}, opts).Decode(&verification)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count verification attempt: %w", err)
+	}
+	return verification.Attempts, nil
+}
+
+func (r *emailVerificationRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return fmt.Errorf("failed to delete email verification: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+)
+
+// indexedCollections are the collections whose repositories ensure indexes on
+// startup, see app.InitializeIndexes
+var indexedCollections = []string{
+	"activity_feed",
+	"api_keys",
+	"audit_logs",
+	"auth_failures",
+	"channel_assignments",
+	"channel_budgets",
+	"channel_claims",
+	"channel_chat_modes",
+	"channel_contexts",
+	"channel_cursors",
+	"channel_items",
+	"channel_privacy",
+	"channel_sentiments",
+	"chat_sessions",
+	"chotot_links",
+	"dead_letters",
+	"drafts",
+	"email_verifications",
+	"jobs",
+	"llm_costs",
+	"llm_outages",
+	"message_templates",
+	"onboardings",
+	"personas",
+	"prompt_logs",
+	"reply_suggestions",
+	"reservations",
+	"scam_flags",
+	"session_traces",
+	"session_transcripts",
+	"system_messages",
+	"teams",
+	"test_messages",
+	"user_attributes",
+	"whitelist_entries",
+}
+
+// MissingIndexes returns the indexed collections that only have the default
+// _id index, which means their indexes were dropped or never built
+func MissingIndexes(ctx context.Context, db *DB) ([]string, error) {
+	var missing []string
+	for _, name := range indexedCollections {
+		specs, err := db.Database.Collection(name).Indexes().ListSpecifications(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s indexes: %w", name, err)
+		}
+		if len(specs) < 2 {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,232 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultJobLimit = 100
+
+type JobRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, job *models.Job) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error)
+	List(ctx context.Context, filter models.JobFilter) ([]*models.Job, error)
+	// Claim starts the oldest pending job, or a running one whose heartbeat
+	// is older than staleBefore, for workerID. It returns nil when there is none.
+	Claim(ctx context.Context, workerID string, staleBefore time.Time) (*models.Job, error)
+	// Heartbeat stores the progress of a job run by workerID and returns the
+	// job, or nil when another worker took it over or it was cancelled
+	Heartbeat(ctx context.Context, id primitive.ObjectID, workerID string, progress models.JobProgress, errors []string) (*models.Job, error)
+	// Finish stores the final state of a job run by workerID
+	Finish(ctx context.Context, job *models.Job, workerID string) error
+	// Cancel cancels a pending job, or asks the worker of a running one to
+	// stop. It returns models.ErrJobFinished once the job stopped.
+	Cancel(ctx context.Context, id primitive.ObjectID) (*models.Job, error)
+}
+
+type jobRepo struct {
+	collection *mongo.Collection
+}
+
+func NewJobRepository(db *DB) JobRepository {
+	return &jobRepo{
+		collection: db.Database.Collection("jobs"),
+	}
+}
+
+func (r *jobRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("status_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("tenant_created_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create job indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepo) Create(ctx context.Context, job *models.Job) error {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	if job.TenantID == nil {
+		job.TenantID = ctxTenantID(ctx)
+	}
+	job.Status = models.JobPending
+	job.CreatedAt = now
+	job.UpdatedAt = now
+
+	if _, err := r.collection.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	var job models.Job
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepo) List(ctx context.Context, filter models.JobFilter) ([]*models.Job, error) {
+	query := bson.M{}
+	if filter.TenantID != nil {
+		query["tenant_id"] = *filter.TenantID
+	}
+	if filter.Type != "" {
+		query["type"] = filter.Type
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultJobLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, scoped(ctx, query), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []*models.Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode jobs: %w", err)
+	}
+	return jobs, nil
+}
+
+func (r *jobRepo) Claim(ctx context.Context, workerID string, staleBefore time.Time) (*models.Job, error) {
+	now := time.Now()
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": models.JobPending},
+			bson.M{"status": models.JobRunning, "heartbeat_at": bson.M{"$lt": staleBefore}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       models.JobRunning,
+			"worker_id":    workerID,
+			"heartbeat_at": now,
+			"started_at":   now,
+			"updated_at":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "created_at", Value: 1}}).
+		SetReturnDocument(options.After)
+	var job models.Job
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return &job, nil
+}
+
+func (r *jobRepo) Heartbeat(ctx context.Context, id primitive.ObjectID, workerID string, progress models.JobProgress, errors []string) (*models.Job, error) {
+	now := time.Now()
+	filter := bson.M{"_id": id, "worker_id": workerID, "status": models.JobRunning}
+	update := bson.M{
+		"$set": bson.M{
+			"progress":     progress,
+			"errors":       errors,
+			"heartbeat_at": now,
+			"updated_at":   now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var job models.Job
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to record job heartbeat: %w", err)
+	}
+	if job.CancelRequested {
+		return nil, nil
+	}
+	return &job, nil
+}
+
+func (r *jobRepo) Finish(ctx context.Context, job *models.Job, workerID string) error {
+	now := time.Now()
+	filter := bson.M{"_id": job.ID, "worker_id": workerID, "status": models.JobRunning}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      job.Status,
+			"progress":    job.Progress,
+			"errors":      job.Errors,
+			"finished_at": now,
+			"updated_at":  now,
+		},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	return nil
+}
+
+func (r *jobRepo) Cancel(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateOne(ctx,
+		scoped(ctx, bson.M{"_id": id, "status": models.JobPending}),
+		bson.M{"$set": bson.M{
+			"status":           models.JobCancelled,
+			"cancel_requested": true,
+			"finished_at":      now,
+			"updated_at":       now,
+		}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to cancel job: %w", err)
+	}
+	if result.ModifiedCount == 0 {
+		result, err = r.collection.UpdateOne(ctx,
+			scoped(ctx, bson.M{"_id": id, "status": models.JobRunning}),
+			bson.M{"$set": bson.M{"cancel_requested": true, "updated_at": now}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to cancel job: %w", err)
+		}
+	}
+
+	job, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if result.MatchedCount == 0 {
+		return nil, models.ErrJobFinished
+	}
+	return job, nil
+}
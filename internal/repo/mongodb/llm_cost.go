@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LLMCostRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Add adds usage and its estimated cost to the month's counters of the
+	// seller, chat mode and model
+	Add(ctx context.Context, month, sellerID, chatMode, model string, usage models.SessionUsage, costUSD float64) error
+	// Breakdown sums the month's counters by the field, across tenants unless
+	// tenantID is set
+	Breakdown(ctx context.Context, month string, tenantID *primitive.ObjectID, field string) ([]models.CostBreakdown, error)
+}
+
+type llmCostRepo struct {
+	collection *mongo.Collection
+}
+
+func NewLLMCostRepository(db *DB) LLMCostRepository {
+	return &llmCostRepo{
+		collection: db.Database.Collection("llm_costs"),
+	}
+}
+
+func (r *llmCostRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "month", Value: 1},
+				{Key: "seller_id", Value: 1},
+				{Key: "chat_mode", Value: 1},
+				{Key: "model", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_month_seller_mode_model").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "month", Value: 1}},
+			Options: options.Index().SetName("idx_month"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create llm cost indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *llmCostRepo) Add(ctx context.Context, month, sellerID, chatMode, model string, usage models.SessionUsage, costUSD float64) error {
+	filter := exactTenant(ctx, bson.M{
+		"month":     month,
+		"seller_id": sellerID,
+		"chat_mode": chatMode,
+		"model":     model,
+	})
+	now := time.Now()
+	update := bson.M{
+		"$inc": bson.M{
+			"generations":   usage.Generations,
+			"input_tokens":  usage.InputTokens,
+			"output_tokens": usage.OutputTokens,
+			"cost_usd":      costUSD,
+		},
+		"$set":         bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := r.collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		return fmt.Errorf("failed to add llm cost: %w", err)
+	}
+	return nil
+}
+
+func (r *llmCostRepo) Breakdown(ctx context.Context, month string, tenantID *primitive.ObjectID, field string) ([]models.CostBreakdown, error) {
+	match := bson.M{"month": month}
+	if tenantID != nil {
+		match["tenant_id"] = tenantID
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.M{
+			"_id":           "$" + field,
+			"generations":   bson.M{"$sum": "$generations"},
+			"input_tokens":  bson.M{"$sum": "$input_tokens"},
+			"output_tokens": bson.M{"$sum": "$output_tokens"},
+			"cost_usd":      bson.M{"$sum": "$cost_usd"},
+		}}},
+		// tenant IDs are object IDs, the other dimensions strings
+		{{Key: "$project", Value: bson.M{
+			"_id":           0,
+			"key":           bson.M{"$toString": "$_id"},
+			"generations":   1,
+			"input_tokens":  1,
+			"output_tokens": 1,
+			"cost_usd":      1,
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "cost_usd", Value: -1}, {Key: "key", Value: 1}}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to break down llm costs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	breakdown := []models.CostBreakdown{}
+	if err := cursor.All(ctx, &breakdown); err != nil {
+		return nil, fmt.Errorf("failed to decode llm costs: %w", err)
+	}
+	return breakdown, nil
+}
@@ -0,0 +1,76 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LLMKeyRepository interface {
+	Upsert(ctx context.Context, key *models.LLMKey) error
+	Get(ctx context.Context, provider models.LLMProvider, sellerID string) (*models.LLMKey, error)
+	Delete(ctx context.Context, provider models.LLMProvider, sellerID string) error
+}
+
+type llmKeyRepo struct {
+	collection *mongo.Collection
+}
+
+func NewLLMKeyRepository(db *DB) LLMKeyRepository {
+	return &llmKeyRepo{
+		collection: db.Database.Collection("llm_keys"),
+	}
+}
+
+func (r *llmKeyRepo) Upsert(ctx context.Context, key *models.LLMKey) error {
+	now := time.Now()
+	filter := exactTenant(ctx, bson.M{"provider": key.Provider, "seller_id": key.SellerID})
+	update := bson.M{
+		"$set": bson.M{
+			"encrypted_key": key.EncryptedKey,
+			"hint":          key.Hint,
+			"updated_at":    now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(key)
+	if err != nil {
+		return fmt.Errorf("failed to upsert llm key: %w", err)
+	}
+	return nil
+}
+
+// Get returns the key stored for the exact scope, or nil when there is none
+func (r *llmKeyRepo) Get(ctx context.Context, provider models.LLMProvider, sellerID string) (*models.LLMKey, error) {
+	var key models.LLMKey
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"provider": provider, "seller_id": sellerID})).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get llm key: %w", err)
+	}
+	return &key, nil
+}
+
+func (r *llmKeyRepo) Delete(ctx context.Context, provider models.LLMProvider, sellerID string) error {
+	result, err := r.collection.DeleteOne(ctx, exactTenant(ctx, bson.M{"provider": provider, "seller_id": sellerID}))
+	if err != nil {
+		return fmt.Errorf("failed to delete llm key: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
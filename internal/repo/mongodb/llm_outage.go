@@ -0,0 +1,85 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultLLMOutageLimit = 100
+
+type LLMOutageRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, outage *models.LLMOutage) error
+	// List returns outages across tenants since the given time, latest first
+	List(ctx context.Context, since time.Time, limit int) ([]*models.LLMOutage, error)
+}
+
+type llmOutageRepo struct {
+	collection *mongo.Collection
+}
+
+func NewLLMOutageRepository(db *DB) LLMOutageRepository {
+	return &llmOutageRepo{
+		collection: db.Database.Collection("llm_outages"),
+	}
+}
+
+func (r *llmOutageRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create llm outage indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *llmOutageRepo) Create(ctx context.Context, outage *models.LLMOutage) error {
+	outage.ID = primitive.NewObjectID()
+	outage.TenantID = ctxTenantID(ctx)
+	outage.CreatedAt = time.Now()
+
+	if _, err := r.collection.InsertOne(ctx, outage); err != nil {
+		return fmt.Errorf("failed to create llm outage: %w", err)
+	}
+	return nil
+}
+
+func (r *llmOutageRepo) List(ctx context.Context, since time.Time, limit int) ([]*models.LLMOutage, error) {
+	query := bson.M{}
+	if !since.IsZero() {
+		query["created_at"] = bson.M{"$gte": since}
+	}
+	if limit <= 0 {
+		limit = defaultLLMOutageLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list llm outages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var outages []*models.LLMOutage
+	if err := cursor.All(ctx, &outages); err != nil {
+		return nil, fmt.Errorf("failed to decode llm outages: %w", err)
+	}
+	return outages, nil
+}
@@ -0,0 +1,123 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MessageTemplateRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Upsert stores the template under its partner and name
+	Upsert(ctx context.Context, template *models.MessageTemplate) error
+	// Get returns the template, or nil when there is none
+	Get(ctx context.Context, partner, name string) (*models.MessageTemplate, error)
+	// List returns the partner's templates by name, every partner's when
+	// partner is empty
+	List(ctx context.Context, partner string) ([]*models.MessageTemplate, error)
+	// ListApproved returns the partner's approved templates by name
+	ListApproved(ctx context.Context, partner string) ([]*models.MessageTemplate, error)
+	Delete(ctx context.Context, partner, name string) error
+}
+
+type messageTemplateRepo struct {
+	collection *mongo.Collection
+}
+
+func NewMessageTemplateRepository(db *DB) MessageTemplateRepository {
+	return &messageTemplateRepo{
+		collection: db.Database.Collection("message_templates"),
+	}
+}
+
+func (r *messageTemplateRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "partner", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_partner_name").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create message template indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *messageTemplateRepo) Upsert(ctx context.Context, template *models.MessageTemplate) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"body":       template.Body,
+			"params":     template.Params,
+			"status":     template.Status,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"partner": template.Partner, "name": template.Name}), update, opts).Decode(template)
+	if err != nil {
+		return fmt.Errorf("failed to upsert message template: %w", err)
+	}
+	return nil
+}
+
+func (r *messageTemplateRepo) Get(ctx context.Context, partner, name string) (*models.MessageTemplate, error) {
+	var template models.MessageTemplate
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"partner": partner, "name": name})).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get message template: %w", err)
+	}
+	return &template, nil
+}
+
+func (r *messageTemplateRepo) List(ctx context.Context, partner string) ([]*models.MessageTemplate, error) {
+	filter := bson.M{}
+	if partner != "" {
+		filter["partner"] = partner
+	}
+	return r.find(ctx, filter)
+}
+
+func (r *messageTemplateRepo) ListApproved(ctx context.Context, partner string) ([]*models.MessageTemplate, error) {
+	return r.find(ctx, bson.M{"partner": partner, "status": models.TemplateStatusApproved})
+}
+
+func (r *messageTemplateRepo) find(ctx context.Context, filter bson.M) ([]*models.MessageTemplate, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "partner", Value: 1}, {Key: "name", Value: 1}})
+	cursor, err := r.collection.Find(ctx, scoped(ctx, filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list message templates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	templates := []*models.MessageTemplate{}
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, fmt.Errorf("failed to decode message templates: %w", err)
+	}
+	return templates, nil
+}
+
+func (r *messageTemplateRepo) Delete(ctx context.Context, partner, name string) error {
+	result, err := r.collection.DeleteOne(ctx, exactTenant(ctx, bson.M{"partner": partner, "name": name}))
+	if err != nil {
+		return fmt.Errorf("failed to delete message template: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
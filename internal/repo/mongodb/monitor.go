@@ -0,0 +1,97 @@
+package mongodb
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// NewSlowQueryMonitor logs every command that takes longer than threshold,
+// along with the collection it targeted
+func NewSlowQueryMonitor(threshold time.Duration) *event.CommandMonitor {
+	// the finished events do not carry the command, so remember the target
+	// collection of each in-flight request
+	var collections sync.Map
+
+	finished := func(ctx context.Context, evt event.CommandFinishedEvent, failure string) {
+		collection, _ := collections.LoadAndDelete(evt.RequestID)
+		if evt.Duration < threshold {
+			return
+		}
+
+		args := []any{
+			"command", evt.CommandName,
+			"database", evt.DatabaseName,
+			"collection", collection,
+			"duration_ms", evt.Duration.Milliseconds(),
+		}
+		if failure != "" {
+			args = append(args, "failure", failure)
+		}
+		log.Warnw(ctx, "Slow mongo command", args...)
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			if elem, err := evt.Command.IndexErr(0); err == nil {
+				if name, ok := elem.Value().StringValueOK(); ok {
+					collections.Store(evt.RequestID, name)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			finished(ctx, evt.CommandFinishedEvent, "")
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			finished(ctx, evt.CommandFinishedEvent, evt.Failure)
+		},
+	}
+}
+
+// NewRequestStatsMonitor counts every command against the request stats of its
+// context, when the request collects any
+func NewRequestStatsMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			reqstats.FromContext(ctx).AddMongo(evt.Duration)
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			reqstats.FromContext(ctx).AddMongo(evt.Duration)
+		},
+	}
+}
+
+// CombineMonitors fans every event out to each of monitors, since a client
+// takes a single monitor
+func CombineMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	if len(monitors) == 1 {
+		return monitors[0]
+	}
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
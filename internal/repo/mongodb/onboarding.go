@@ -0,0 +1,83 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OnboardingRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// GetByUserID returns nil when the user has not started onboarding
+	GetByUserID(ctx context.Context, userID primitive.ObjectID) (*models.Onboarding, error)
+	Upsert(ctx context.Context, onboarding *models.Onboarding) error
+}
+
+type onboardingRepo struct {
+	collection *mongo.Collection
+}
+
+func NewOnboardingRepository(db *DB) OnboardingRepository {
+	return &onboardingRepo{
+		collection: db.Database.Collection("onboardings"),
+	}
+}
+
+func (r *onboardingRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "user_id", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_user").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create onboarding indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *onboardingRepo) GetByUserID(ctx context.Context, userID primitive.ObjectID) (*models.Onboarding, error) {
+	var onboarding models.Onboarding
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"user_id": userID})).Decode(&onboarding)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get onboarding: %w", err)
+	}
+	return &onboarding, nil
+}
+
+func (r *onboardingRepo) Upsert(ctx context.Context, onboarding *models.Onboarding) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"step":              onboarding.Step,
+			"completed_steps":   onboarding.CompletedSteps,
+			"chotot_id":         onboarding.ChototID,
+			"chotot_oid":        onboarding.ChototOID,
+			"chat_mode":         onboarding.ChatMode,
+			"working_hours":     onboarding.WorkingHours,
+			"test_conversation": onboarding.TestConversation,
+			"completed_at":      onboarding.CompletedAt,
+			"updated_at":        now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"user_id": onboarding.UserID}), update, opts).Decode(onboarding)
+	if err != nil {
+		return fmt.Errorf("failed to upsert onboarding: %w", err)
+	}
+	return nil
+}
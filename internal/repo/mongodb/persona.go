@@ -0,0 +1,95 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PersonaRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Upsert(ctx context.Context, persona *models.Persona) error
+	Get(ctx context.Context, sellerID string) (*models.Persona, error)
+	Delete(ctx context.Context, sellerID string) error
+}
+
+type personaRepo struct {
+	collection *mongo.Collection
+}
+
+func NewPersonaRepository(db *DB) PersonaRepository {
+	return &personaRepo{
+		collection: db.Database.Collection("personas"),
+	}
+}
+
+func (r *personaRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "seller_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_tenant_seller").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create persona indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *personaRepo) Upsert(ctx context.Context, persona *models.Persona) error {
+	now := time.Now()
+	filter := exactTenant(ctx, bson.M{"seller_id": persona.SellerID})
+	update := bson.M{
+		"$set": bson.M{
+			"display_name":     persona.DisplayName,
+			"tone":             persona.Tone,
+			"languages":        persona.Languages,
+			"forbidden_topics": persona.ForbiddenTopics,
+			"updated_at":       now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(persona)
+	if err != nil {
+		return fmt.Errorf("failed to upsert persona: %w", err)
+	}
+	return nil
+}
+
+// Get returns the persona stored for the exact scope, or nil when there is none
+func (r *personaRepo) Get(ctx context.Context, sellerID string) (*models.Persona, error) {
+	var persona models.Persona
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"seller_id": sellerID})).Decode(&persona)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get persona: %w", err)
+	}
+	return &persona, nil
+}
+
+func (r *personaRepo) Delete(ctx context.Context, sellerID string) error {
+	result, err := r.collection.DeleteOne(ctx, exactTenant(ctx, bson.M{"seller_id": sellerID}))
+	if err != nil {
+		return fmt.Errorf("failed to delete persona: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
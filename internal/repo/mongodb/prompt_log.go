@@ -0,0 +1,122 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultPromptLogLimit = 50
+
+type PromptLogRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, entry *models.PromptLog) error
+	List(ctx context.Context, filter models.PromptLogFilter) ([]*models.PromptLog, error)
+}
+
+type promptLogRepo struct {
+	collection *mongo.Collection
+}
+
+func NewPromptLogRepository(db *DB) PromptLogRepository {
+	return &promptLogRepo{
+		collection: db.Database.Collection("prompt_logs"),
+	}
+}
+
+// EnsureIndexes creates the lookup indexes and the TTL index that enforces
+// the retention policy through expires_at
+func (r *promptLogRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}, {Key: "iteration", Value: 1}},
+			Options: options.Index().SetName("session_iteration"),
+		},
+		{
+			Keys:    bson.D{{Key: "chat_mode", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("chat_mode_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create prompt log indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *promptLogRepo) Create(ctx context.Context, entry *models.PromptLog) error {
+	entry.ID = primitive.NewObjectID()
+	if entry.TenantID == nil {
+		entry.TenantID = ctxTenantID(ctx)
+	}
+	entry.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to create prompt log: %w", err)
+	}
+	return nil
+}
+
+// List is meant for administrators, so it filters by filter.TenantID rather
+// than by the tenant of ctx
+func (r *promptLogRepo) List(ctx context.Context, filter models.PromptLogFilter) ([]*models.PromptLog, error) {
+	query := bson.M{}
+	if filter.TenantID != nil {
+		query["tenant_id"] = *filter.TenantID
+	}
+	if filter.SessionID != nil {
+		query["session_id"] = *filter.SessionID
+	}
+	if filter.ChatMode != "" {
+		query["chat_mode"] = filter.ChatMode
+	}
+	createdAt := bson.M{}
+	if !filter.Since.IsZero() {
+		createdAt["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		createdAt["$lt"] = filter.Until
+	}
+	if len(createdAt) > 0 {
+		query["created_at"] = createdAt
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultPromptLogLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt logs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*models.PromptLog
+	for cursor.Next(ctx) {
+		var entry models.PromptLog
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode prompt log: %w", err)
+		}
+		entries = append(entries, &entry)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return entries, nil
+}
@@ -16,6 +16,9 @@ type PurchaseIntentRepository interface {
 	Create(ctx context.Context, intent *models.PurchaseIntent) error
 	GetBySessionID(ctx context.Context, sessionID primitive.ObjectID) ([]*models.PurchaseIntent, error)
 	GetByChannelID(ctx context.Context, channelID string) ([]*models.PurchaseIntent, error)
+	// ListByUserAndChannels returns the latest intents of a buyer in the given
+	// channels, most recent first
+	ListByUserAndChannels(ctx context.Context, userID string, channelIDs []string, limit int) ([]*models.PurchaseIntent, error)
 }
 
 type purchaseIntentRepo struct {
@@ -30,6 +33,9 @@ func NewPurchaseIntentRepository(db *DB) PurchaseIntentRepository {
 
 func (r *purchaseIntentRepo) Create(ctx context.Context, intent *models.PurchaseIntent) error {
 	intent.ID = primitive.NewObjectID()
+	if intent.TenantID == nil {
+		intent.TenantID = ctxTenantID(ctx)
+	}
 	intent.CreatedAt = time.Now()
 
 	_, err := r.collection.InsertOne(ctx, intent)
@@ -40,7 +46,7 @@ func (r *purchaseIntentRepo) Create(ctx context.Context, intent *models.Purchase
 }
 
 func (r *purchaseIntentRepo) GetBySessionID(ctx context.Context, sessionID primitive.ObjectID) ([]*models.PurchaseIntent, error) {
-	filter := bson.M{"session_id": sessionID}
+	filter := scoped(ctx, bson.M{"session_id": sessionID})
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	cursor, err := r.collection.Find(ctx, filter, opts)
@@ -66,7 +72,7 @@ func (r *purchaseIntentRepo) GetBySessionID(ctx context.Context, sessionID primi
 }
 
 func (r *purchaseIntentRepo) GetByChannelID(ctx context.Context, channelID string) ([]*models.PurchaseIntent, error) {
-	filter := bson.M{"channel_id": channelID}
+	filter := scoped(ctx, bson.M{"channel_id": channelID})
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 
 	cursor, err := r.collection.Find(ctx, filter, opts)
@@ -90,3 +96,23 @@ func (r *purchaseIntentRepo) GetByChannelID(ctx context.Context, channelID strin
 
 	return intents, nil
 }
+
+func (r *purchaseIntentRepo) ListByUserAndChannels(ctx context.Context, userID string, channelIDs []string, limit int) ([]*models.PurchaseIntent, error) {
+	filter := scoped(ctx, bson.M{
+		"user_id":    userID,
+		"channel_id": bson.M{"$in": channelIDs},
+	})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list purchase intents: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var intents []*models.PurchaseIntent
+	if err := cursor.All(ctx, &intents); err != nil {
+		return nil, fmt.Errorf("failed to decode purchase intents: %w", err)
+	}
+	return intents, nil
+}
@@ -0,0 +1,142 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReplySuggestionRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, suggestion *models.ReplySuggestion) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.ReplySuggestion, error)
+	ListPendingBySeller(ctx context.Context, sellerID string) ([]*models.ReplySuggestion, error)
+	// Decide moves a pending suggestion to status, returning models.ErrNotFound
+	// when it is no longer pending
+	Decide(ctx context.Context, id primitive.ObjectID, status models.SuggestionStatus, sentMessage string, decidedBy models.Actor) error
+	// Reopen puts an approved suggestion back to pending when sending it failed
+	Reopen(ctx context.Context, id primitive.ObjectID) error
+}
+
+type replySuggestionRepo struct {
+	collection *mongo.Collection
+}
+
+func NewReplySuggestionRepository(db *DB) ReplySuggestionRepository {
+	return &replySuggestionRepo{
+		collection: db.Database.Collection("reply_suggestions"),
+	}
+}
+
+func (r *replySuggestionRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "seller_id", Value: 1},
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: 1},
+			},
+			Options: options.Index().SetName("tenant_seller_status_created_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reply suggestion indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *replySuggestionRepo) Create(ctx context.Context, suggestion *models.ReplySuggestion) error {
+	now := time.Now()
+	suggestion.ID = primitive.NewObjectID()
+	suggestion.TenantID = ctxTenantID(ctx)
+	suggestion.Status = models.SuggestionStatusPending
+	suggestion.CreatedAt = now
+	suggestion.UpdatedAt = now
+
+	if _, err := r.collection.InsertOne(ctx, suggestion); err != nil {
+		return fmt.Errorf("failed to create reply suggestion: %w", err)
+	}
+	return nil
+}
+
+func (r *replySuggestionRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.ReplySuggestion, error) {
+	var suggestion models.ReplySuggestion
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&suggestion)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get reply suggestion: %w", err)
+	}
+	return &suggestion, nil
+}
+
+// ListPendingBySeller returns the seller's suggestions awaiting a decision, oldest first
+func (r *replySuggestionRepo) ListPendingBySeller(ctx context.Context, sellerID string) ([]*models.ReplySuggestion, error) {
+	filter := scoped(ctx, bson.M{
+		"seller_id": sellerID,
+		"status":    models.SuggestionStatusPending,
+	})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reply suggestions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var suggestions []*models.ReplySuggestion
+	if err := cursor.All(ctx, &suggestions); err != nil {
+		return nil, fmt.Errorf("failed to decode reply suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+func (r *replySuggestionRepo) Decide(ctx context.Context, id primitive.ObjectID, status models.SuggestionStatus, sentMessage string, decidedBy models.Actor) error {
+	now := time.Now()
+	filter := scoped(ctx, bson.M{
+		"_id":    id,
+		"status": models.SuggestionStatusPending,
+	})
+	set := bson.M{
+		"status":     status,
+		"decided_at": now,
+		"decided_by": decidedBy,
+		"updated_at": now,
+	}
+	if sentMessage != "" {
+		set["sent_message"] = sentMessage
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to decide reply suggestion: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *replySuggestionRepo) Reopen(ctx context.Context, id primitive.ObjectID) error {
+	filter := scoped(ctx, bson.M{
+		"_id":    id,
+		"status": models.SuggestionStatusApproved,
+	})
+	update := bson.M{
+		"$set":   bson.M{"status": models.SuggestionStatusPending, "updated_at": time.Now()},
+		"$unset": bson.M{"sent_message": "", "decided_at": "", "decided_by": ""},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to reopen reply suggestion: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReservationRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Reserve(ctx context.Context, reservation *models.Reservation) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Reservation, error)
+	GetActiveByItem(ctx context.Context, sellerID, itemID string) (*models.Reservation, error)
+	ListActiveBySeller(ctx context.Context, sellerID string) ([]*models.Reservation, error)
+	ListByChannel(ctx context.Context, channelID string) ([]*models.Reservation, error)
+	// Release releases the seller's active reservation, models.ErrNotFound
+	// when there is none of the seller's with that ID
+	Release(ctx context.Context, sellerID string, id primitive.ObjectID) error
+}
+
+type reservationRepo struct {
+	collection *mongo.Collection
+}
+
+func NewReservationRepository(db *DB) ReservationRepository {
+	return &reservationRepo{
+		collection: db.Database.Collection("reservations"),
+	}
+}
+
+// EnsureIndexes creates the partial unique index that guarantees at most one
+// active hold per seller listing, even when two buyers reserve concurrently
+func (r *reservationRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "seller_id", Value: 1}, {Key: "item_id", Value: 1}},
+			Options: options.Index().
+				SetName("uniq_active_seller_item").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"status": models.ReservationStatusActive}),
+		},
+		{
+			Keys:    bson.D{{Key: "status", Value: 1}, {Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("status_expires_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reservation indexes: %w", err)
+	}
+	return nil
+}
+
+// Reserve places a hold on the item. If the same buyer already holds the item
+// the existing reservation is extended; if another buyer holds it,
+// models.ErrReservationConflict is returned.
+func (r *reservationRepo) Reserve(ctx context.Context, reservation *models.Reservation) error {
+	if err := r.expireItem(ctx, reservation.SellerID, reservation.ItemID); err != nil {
+		return err
+	}
+
+	existing, err := r.GetActiveByItem(ctx, reservation.SellerID, reservation.ItemID)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		if existing.BuyerID != reservation.BuyerID {
+			return models.ErrReservationConflict
+		}
+		return r.extend(ctx, existing, reservation)
+	}
+
+	now := time.Now()
+	reservation.ID = primitive.NewObjectID()
+	if reservation.TenantID == nil {
+		reservation.TenantID = ctxTenantID(ctx)
+	}
+	reservation.Status = models.ReservationStatusActive
+	reservation.CreatedAt = now
+	reservation.UpdatedAt = now
+
+	_, err = r.collection.InsertOne(ctx, reservation)
+	if mongo.IsDuplicateKeyError(err) {
+		// lost the race against another buyer reserving the same item
+		return models.ErrReservationConflict
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create reservation: %w", err)
+	}
+	return nil
+}
+
+func (r *reservationRepo) extend(ctx context.Context, existing, reservation *models.Reservation) error {
+	now := time.Now()
+	filter := scoped(ctx, bson.M{
+		"_id":    existing.ID,
+		"status": models.ReservationStatusActive,
+	})
+	update := bson.M{
+		"$set": bson.M{
+			"expires_at": reservation.ExpiresAt,
+			"session_id": reservation.SessionID,
+			"updated_at": now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to extend reservation: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrReservationConflict
+	}
+
+	existing.ExpiresAt = reservation.ExpiresAt
+	existing.SessionID = reservation.SessionID
+	existing.UpdatedAt = now
+	*reservation = *existing
+	return nil
+}
+
+func (r *reservationRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Reservation, error) {
+	var reservation models.Reservation
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&reservation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get reservation: %w", err)
+	}
+	return &reservation, nil
+}
+
+func (r *reservationRepo) GetActiveByItem(ctx context.Context, sellerID, itemID string) (*models.Reservation, error) {
+	filter := scoped(ctx, bson.M{
+		"seller_id":  sellerID,
+		"item_id":    itemID,
+		"status":     models.ReservationStatusActive,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+
+	var reservation models.Reservation
+	err := r.collection.FindOne(ctx, filter).Decode(&reservation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active reservation: %w", err)
+	}
+	return &reservation, nil
+}
+
+func (r *reservationRepo) ListActiveBySeller(ctx context.Context, sellerID string) ([]*models.Reservation, error) {
+	filter := scoped(ctx, bson.M{
+		"seller_id":  sellerID,
+		"status":     models.ReservationStatusActive,
+		"expires_at": bson.M{"$gt": time.Now()},
+	})
+	opts := options.Find().SetSort(bson.D{{Key: "expires_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active reservations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reservations []*models.Reservation
+	for cursor.Next(ctx) {
+		var reservation models.Reservation
+		if err := cursor.Decode(&reservation); err != nil {
+			return nil, fmt.Errorf("failed to decode reservation: %w", err)
+		}
+		reservations = append(reservations, &reservation)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return reservations, nil
+}
+
+func (r *reservationRepo) ListByChannel(ctx context.Context, channelID string) ([]*models.Reservation, error) {
+	filter := scoped(ctx, bson.M{"channel_id": channelID})
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list channel reservations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reservations []*models.Reservation
+	if err := cursor.All(ctx, &reservations); err != nil {
+		return nil, fmt.Errorf("failed to decode reservations: %w", err)
+	}
+	return reservations, nil
+}
+
+func (r *reservationRepo) Release(ctx context.Context, sellerID string, id primitive.ObjectID) error {
+	now := time.Now()
+	filter := scoped(ctx, bson.M{
+		"_id":       id,
+		"seller_id": sellerID,
+		"status":    models.ReservationStatusActive,
+	})
+	update := bson.M{
+		"$set": bson.M{
+			"status":      models.ReservationStatusReleased,
+			"released_at": now,
+			"updated_at":  now,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// expireItem flips the item's active reservation past its TTL to expired so
+// the partial unique index frees the item for a new hold. The index spans
+// tenants, so the lapsed hold is expired whichever tenant it belongs to.
+func (r *reservationRepo) expireItem(ctx context.Context, sellerID, itemID string) error {
+	now := time.Now()
+	filter := bson.M{
+		"seller_id":  sellerID,
+		"item_id":    itemID,
+		"status":     models.ReservationStatusActive,
+		"expires_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     models.ReservationStatusExpired,
+			"updated_at": now,
+		},
+	}
+
+	if _, err := r.collection.UpdateOne(ctx, filter, update); err != nil {
+		return fmt.Errorf("failed to expire reservation: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestReservationRepo(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	tenantID := primitive.NewObjectID()
+	ctx := models.WithTenantID(context.Background(), tenantID)
+
+	mt.Run("Release Is Scoped To The Tenant And Seller", func(mt *mtest.T) {
+		// no reservation of the seller matches
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		repo := &reservationRepo{collection: mt.Coll}
+
+		err := repo.Release(ctx, "seller-1", primitive.NewObjectID())
+		assert.ErrorIs(mt, err, models.ErrNotFound)
+
+		event := mt.GetStartedEvent()
+		require.NotNil(mt, event)
+		filter := event.Command.Lookup("updates").Array().Index(0).Value().Document().Lookup("q").Document()
+		assert.Equal(mt, "seller-1", filter.Lookup("seller_id").StringValue())
+		assert.Equal(mt, tenantID, filter.Lookup("tenant_id").ObjectID())
+	})
+
+	mt.Run("Reserve Only Expires The Item's Hold", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}),
+			mtest.CreateCursorResponse(0, "test.reservations", mtest.FirstBatch),
+			mtest.CreateSuccessResponse(),
+		)
+		repo := &reservationRepo{collection: mt.Coll}
+
+		err := repo.Reserve(ctx, &models.Reservation{
+			SellerID:  "seller-1",
+			ItemID:    "item-1",
+			BuyerID:   "buyer-1",
+			ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(mt, err)
+
+		event := mt.GetStartedEvent()
+		require.NotNil(mt, event)
+		assert.Equal(mt, "update", event.CommandName)
+		updates := event.Command.Lookup("updates").Array()
+		values, err := updates.Values()
+		require.NoError(mt, err)
+		require.Len(mt, values, 1)
+		update := values[0].Document()
+		multi, _ := update.Lookup("multi").BooleanOK()
+		assert.False(mt, multi)
+		filter := update.Lookup("q").Document()
+		assert.Equal(mt, "seller-1", filter.Lookup("seller_id").StringValue())
+		assert.Equal(mt, "item-1", filter.Lookup("item_id").StringValue())
+	})
+}
@@ -0,0 +1,157 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultScamFlagLimit = 100
+
+type ScamFlagRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, flag *models.ScamFlag) error
+	// GetByID is not tenant scoped, the review queue spans every tenant
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.ScamFlag, error)
+	// List returns flags matching filter across tenants, latest first
+	List(ctx context.Context, filter models.ScamFlagFilter) ([]*models.ScamFlag, error)
+	// HasPending reports whether the channel has a flag awaiting review
+	HasPending(ctx context.Context, channelID string) (bool, error)
+	// Review moves a pending flag to status, returning models.ErrNotFound when
+	// it is no longer pending
+	Review(ctx context.Context, id primitive.ObjectID, status models.ScamFlagStatus) error
+}
+
+type scamFlagRepo struct {
+	collection *mongo.Collection
+}
+
+func NewScamFlagRepository(db *DB) ScamFlagRepository {
+	return &scamFlagRepo{
+		collection: db.Database.Collection("scam_flags"),
+	}
+}
+
+func (r *scamFlagRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "status", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("status_created_at"),
+		},
+		{
+			Keys: bson.D{
+				{Key: "tenant_id", Value: 1},
+				{Key: "channel_id", Value: 1},
+				{Key: "status", Value: 1},
+			},
+			Options: options.Index().SetName("tenant_channel_status"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create scam flag indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *scamFlagRepo) Create(ctx context.Context, flag *models.ScamFlag) error {
+	now := time.Now()
+	flag.ID = primitive.NewObjectID()
+	flag.TenantID = ctxTenantID(ctx)
+	flag.Status = models.ScamFlagStatusPending
+	flag.CreatedAt = now
+	flag.UpdatedAt = now
+
+	if _, err := r.collection.InsertOne(ctx, flag); err != nil {
+		return fmt.Errorf("failed to create scam flag: %w", err)
+	}
+	return nil
+}
+
+func (r *scamFlagRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.ScamFlag, error) {
+	var flag models.ScamFlag
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&flag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get scam flag: %w", err)
+	}
+	return &flag, nil
+}
+
+func (r *scamFlagRepo) List(ctx context.Context, filter models.ScamFlagFilter) ([]*models.ScamFlag, error) {
+	query := bson.M{}
+	if filter.TenantID != nil {
+		query["tenant_id"] = *filter.TenantID
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.ChannelID != "" {
+		query["channel_id"] = filter.ChannelID
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultScamFlagLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scam flags: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var flags []*models.ScamFlag
+	if err := cursor.All(ctx, &flags); err != nil {
+		return nil, fmt.Errorf("failed to decode scam flags: %w", err)
+	}
+	return flags, nil
+}
+
+func (r *scamFlagRepo) HasPending(ctx context.Context, channelID string) (bool, error) {
+	filter := scoped(ctx, bson.M{
+		"channel_id": channelID,
+		"status":     models.ScamFlagStatusPending,
+	})
+
+	count, err := r.collection.CountDocuments(ctx, filter, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to count pending scam flags: %w", err)
+	}
+	return count > 0, nil
+}
+
+func (r *scamFlagRepo) Review(ctx context.Context, id primitive.ObjectID, status models.ScamFlagStatus) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id":    id,
+		"status": models.ScamFlagStatusPending,
+	}
+	update := bson.M{"$set": bson.M{
+		"status":      status,
+		"reviewed_at": now,
+		"updated_at":  now,
+	}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to review scam flag: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
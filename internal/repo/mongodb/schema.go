@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Schema validation modes, mapped to MongoDB validation actions except off
+const (
+	SchemaValidationError = "error"
+	SchemaValidationWarn  = "warn"
+	SchemaValidationOff   = "off"
+)
+
+var (
+	optionalObjectID = bson.M{"bsonType": bson.A{"objectId", "null"}}
+	nonEmptyString   = bson.M{"bsonType": "string", "minLength": 1}
+)
+
+// collectionSchemas are the $jsonSchema validators of the core collections.
+// They only pin fields every write path sets, so malformed documents are
+// caught without blocking optional fields added later.
+var collectionSchemas = []struct {
+	name   string
+	schema bson.M
+}{
+	{
+		name: "users",
+		schema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"name", "email", "created_at"},
+			"properties": bson.M{
+				"tenant_id":  optionalObjectID,
+				"name":       bson.M{"bsonType": "string"},
+				"email":      nonEmptyString,
+				"avatar_url": bson.M{"bsonType": "string"},
+				"created_at": bson.M{"bsonType": "date"},
+				"updated_at": bson.M{"bsonType": "date"},
+			},
+		},
+	},
+	{
+		name: "user_attributes",
+		schema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"user_id", "key", "value"},
+			"properties": bson.M{
+				"tenant_id": optionalObjectID,
+				"user_id":   bson.M{"bsonType": "objectId"},
+				"key":       bson.M{"bsonType": "string", "pattern": "^[a-zA-Z0-9_]+$"},
+				"value":     nonEmptyString,
+				"tags":      bson.M{"bsonType": bson.A{"array", "null"}, "items": bson.M{"bsonType": "string"}},
+			},
+		},
+	},
+	{
+		name: "chat_modes",
+		schema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"name", "model", "prompt_template"},
+			"properties": bson.M{
+				"tenant_id":       optionalObjectID,
+				"name":            nonEmptyString,
+				"model":           nonEmptyString,
+				"prompt_template": nonEmptyString,
+				"condition":       bson.M{"bsonType": "string"},
+				"tools":           bson.M{"bsonType": bson.A{"array", "null"}, "items": bson.M{"bsonType": "string"}},
+				"max_iterations":  bson.M{"bsonType": bson.A{"int", "long"}, "minimum": 0},
+			},
+		},
+	},
+	{
+		name: "chat_sessions",
+		schema: bson.M{
+			"bsonType": "object",
+			"required": bson.A{"channel_id", "user_id", "chat_mode", "status", "started_at"},
+			"properties": bson.M{
+				"tenant_id":  optionalObjectID,
+				"channel_id": nonEmptyString,
+				"user_id":    nonEmptyString,
+				"chat_mode":  nonEmptyString,
+				"status": bson.M{"enum": bson.A{
+					string(models.SessionStatusActive),
+					string(models.SessionStatusEnded),
+					string(models.SessionStatusAbandoned),
+				}},
+				"end_reason": bson.M{"enum": bson.A{
+					string(models.SessionEndedByModel),
+					string(models.SessionEndedInactive),
+					string(models.SessionEndedAbandoned),
+				}},
+				"started_at": bson.M{"bsonType": "date"},
+			},
+		},
+	},
+}
+
+// ApplySchemaValidators installs the validators of the core collections with
+// collMod, creating collections that do not exist yet. The moderate level
+// leaves existing invalid documents updatable, so enabling validation never
+// blocks writes to legacy data.
+func ApplySchemaValidators(ctx context.Context, db *DB, mode string) error {
+	switch mode {
+	case SchemaValidationOff:
+		return nil
+	case SchemaValidationError, SchemaValidationWarn:
+	default:
+		return fmt.Errorf("invalid schema validation mode %q, want error, warn or off", mode)
+	}
+
+	for _, c := range collectionSchemas {
+		validator := bson.M{"$jsonSchema": c.schema}
+		err := db.Database.RunCommand(ctx, bson.D{
+			{Key: "collMod", Value: c.name},
+			{Key: "validator", Value: validator},
+			{Key: "validationLevel", Value: "moderate"},
+			{Key: "validationAction", Value: mode},
+		}).Err()
+
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && cmdErr.Name == "NamespaceNotFound" {
+			err = db.Database.CreateCollection(ctx, c.name, options.CreateCollection().
+				SetValidator(validator).
+				SetValidationLevel("moderate").
+				SetValidationAction(mode))
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply %s schema validator: %w", c.name, err)
+		}
+	}
+
+	log.Infow(ctx, "Applied collection schema validators", "mode", mode, "collections", len(collectionSchemas))
+	return nil
+}
@@ -0,0 +1,99 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SessionTraceRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, trace *models.SessionTrace) error
+	// Count returns how many traces sessionID has
+	Count(ctx context.Context, sessionID primitive.ObjectID) (int64, error)
+	// ListBySession returns the traces of sessionID in iteration order
+	ListBySession(ctx context.Context, sessionID primitive.ObjectID) ([]*models.SessionTrace, error)
+}
+
+type sessionTraceRepo struct {
+	collection *mongo.Collection
+}
+
+func NewSessionTraceRepository(db *DB) SessionTraceRepository {
+	return &sessionTraceRepo{
+		collection: db.Database.Collection("session_traces"),
+	}
+}
+
+// EnsureIndexes creates the session lookup index and the TTL index that
+// enforces the retention policy through expires_at
+func (r *sessionTraceRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}, {Key: "created_at", Value: 1}},
+			Options: options.Index().SetName("session_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create session trace indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionTraceRepo) Create(ctx context.Context, trace *models.SessionTrace) error {
+	trace.ID = primitive.NewObjectID()
+	if trace.TenantID == nil {
+		trace.TenantID = ctxTenantID(ctx)
+	}
+	trace.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, trace)
+	if err != nil {
+		return fmt.Errorf("failed to create session trace: %w", err)
+	}
+	return nil
+}
+
+func (r *sessionTraceRepo) Count(ctx context.Context, sessionID primitive.ObjectID) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, bson.M{"session_id": sessionID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count session traces: %w", err)
+	}
+	return count, nil
+}
+
+// ListBySession is meant for administrators, so it is not scoped to the
+// tenant of ctx
+func (r *sessionTraceRepo) ListBySession(ctx context.Context, sessionID primitive.ObjectID) ([]*models.SessionTrace, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"session_id": sessionID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list session traces: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var traces []*models.SessionTrace
+	for cursor.Next(ctx) {
+		var trace models.SessionTrace
+		if err := cursor.Decode(&trace); err != nil {
+			return nil, fmt.Errorf("failed to decode session trace: %w", err)
+		}
+		traces = append(traces, &trace)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return traces, nil
+}
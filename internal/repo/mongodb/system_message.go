@@ -0,0 +1,102 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SystemMessageRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Upsert stores the override under its key and locale
+	Upsert(ctx context.Context, override *models.SystemMessageOverride) error
+	// Get returns the override, or nil when there is none
+	Get(ctx context.Context, key models.SystemMessageKey, locale string) (*models.SystemMessageOverride, error)
+	List(ctx context.Context) ([]*models.SystemMessageOverride, error)
+	Delete(ctx context.Context, key models.SystemMessageKey, locale string) error
+}
+
+type systemMessageRepo struct {
+	collection *mongo.Collection
+}
+
+func NewSystemMessageRepository(db *DB) SystemMessageRepository {
+	return &systemMessageRepo{
+		collection: db.Database.Collection("system_messages"),
+	}
+}
+
+func (r *systemMessageRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "key", Value: 1}, {Key: "locale", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_key_locale").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create system message indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *systemMessageRepo) Upsert(ctx context.Context, override *models.SystemMessageOverride) error {
+	update := bson.M{
+		"$set": bson.M{
+			"text":       override.Text,
+			"updated_at": time.Now(),
+		},
+		"$setOnInsert": bson.M{
+			"_id": primitive.NewObjectID(),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.collection.FindOneAndUpdate(ctx, exactTenant(ctx, bson.M{"key": override.Key, "locale": override.Locale}), update, opts).Decode(override)
+	if err != nil {
+		return fmt.Errorf("failed to upsert system message: %w", err)
+	}
+	return nil
+}
+
+func (r *systemMessageRepo) Get(ctx context.Context, key models.SystemMessageKey, locale string) (*models.SystemMessageOverride, error) {
+	var override models.SystemMessageOverride
+	err := r.collection.FindOne(ctx, exactTenant(ctx, bson.M{"key": key, "locale": locale})).Decode(&override)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get system message: %w", err)
+	}
+	return &override, nil
+}
+
+func (r *systemMessageRepo) List(ctx context.Context) ([]*models.SystemMessageOverride, error) {
+	cursor, err := r.collection.Find(ctx, exactTenant(ctx, bson.M{}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list system messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	overrides := []*models.SystemMessageOverride{}
+	if err := cursor.All(ctx, &overrides); err != nil {
+		return nil, fmt.Errorf("failed to decode system messages: %w", err)
+	}
+	return overrides, nil
+}
+
+func (r *systemMessageRepo) Delete(ctx context.Context, key models.SystemMessageKey, locale string) error {
+	result, err := r.collection.DeleteOne(ctx, exactTenant(ctx, bson.M{"key": key, "locale": locale}))
+	if err != nil {
+		return fmt.Errorf("failed to delete system message: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
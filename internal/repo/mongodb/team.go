@@ -0,0 +1,144 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TeamRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Create stores a team, returning models.ErrTeamExists when the seller
+	// already has one
+	Create(ctx context.Context, team *models.Team) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Team, error)
+	// GetBySeller returns the seller's team, or nil when there is none
+	GetBySeller(ctx context.Context, sellerID string) (*models.Team, error)
+	// AddMember adds the member, or updates its role when the user is already
+	// in the team
+	AddMember(ctx context.Context, id primitive.ObjectID, member models.TeamMember) error
+	RemoveMember(ctx context.Context, id, userID primitive.ObjectID) error
+}
+
+type teamRepo struct {
+	collection *mongo.Collection
+}
+
+func NewTeamRepository(db *DB) TeamRepository {
+	return &teamRepo{
+		collection: db.Database.Collection("teams"),
+	}
+}
+
+func (r *teamRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "tenant_id", Value: 1}, {Key: "seller_id", Value: 1}},
+			Options: options.Index().SetName("uniq_tenant_seller").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "members.user_id", Value: 1}},
+			Options: options.Index().SetName("members_user_id"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create team indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepo) Create(ctx context.Context, team *models.Team) error {
+	now := time.Now()
+	team.ID = primitive.NewObjectID()
+	team.TenantID = ctxTenantID(ctx)
+	team.CreatedAt = now
+	team.UpdatedAt = now
+
+	_, err := r.collection.InsertOne(ctx, team)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrTeamExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create team: %w", err)
+	}
+	return nil
+}
+
+func (r *teamRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Team, error) {
+	var team models.Team
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&team)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get team: %w", err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepo) GetBySeller(ctx context.Context, sellerID string) (*models.Team, error) {
+	var team models.Team
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"seller_id": sellerID})).Decode(&team)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get team by seller: %w", err)
+	}
+	return &team, nil
+}
+
+func (r *teamRepo) AddMember(ctx context.Context, id primitive.ObjectID, member models.TeamMember) error {
+	now := time.Now()
+
+	// an existing member only changes role, keeping when it was added
+	result, err := r.collection.UpdateOne(ctx,
+		scoped(ctx, bson.M{"_id": id, "members.user_id": member.UserID}),
+		bson.M{"$set": bson.M{"members.$.role": member.Role, "updated_at": now}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update team member: %w", err)
+	}
+	if result.MatchedCount > 0 {
+		return nil
+	}
+
+	member.AddedAt = now
+	result, err = r.collection.UpdateOne(ctx,
+		scoped(ctx, bson.M{"_id": id, "members.user_id": bson.M{"$ne": member.UserID}}),
+		bson.M{
+			"$push": bson.M{"members": member},
+			"$set":  bson.M{"updated_at": now},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add team member: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *teamRepo) RemoveMember(ctx context.Context, id, userID primitive.ObjectID) error {
+	result, err := r.collection.UpdateOne(ctx,
+		scoped(ctx, bson.M{"_id": id, "members.user_id": userID}),
+		bson.M{
+			"$pull": bson.M{"members": bson.M{"user_id": userID}},
+			"$set":  bson.M{"updated_at": time.Now()},
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to remove team member: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotTeamMember
+	}
+	return nil
+}
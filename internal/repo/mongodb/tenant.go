@@ -0,0 +1,96 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TenantRepository interface {
+	Create(ctx context.Context, tenant *models.Tenant) error
+	GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error)
+	UpdateSettings(ctx context.Context, id primitive.ObjectID, settings models.TenantSettings) error
+	List(ctx context.Context) ([]*models.Tenant, error)
+}
+
+type tenantRepo struct {
+	collection *mongo.Collection
+}
+
+func NewTenantRepository(db *DB) TenantRepository {
+	return &tenantRepo{
+		collection: db.Database.Collection("tenants"),
+	}
+}
+
+func (r *tenantRepo) Create(ctx context.Context, tenant *models.Tenant) error {
+	tenant.ID = primitive.NewObjectID()
+	tenant.CreatedAt = time.Now()
+	tenant.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, tenant)
+	if err != nil {
+		return fmt.Errorf("failed to create tenant: %w", err)
+	}
+	return nil
+}
+
+func (r *tenantRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error) {
+	var tenant models.Tenant
+	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&tenant)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return &tenant, nil
+}
+
+func (r *tenantRepo) UpdateSettings(ctx context.Context, id primitive.ObjectID, settings models.TenantSettings) error {
+	update := bson.M{
+		"$set": bson.M{
+			"settings":   settings,
+			"updated_at": time.Now(),
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return fmt.Errorf("failed to update tenant settings: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *tenantRepo) List(ctx context.Context) ([]*models.Tenant, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenants: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tenants []*models.Tenant
+	for cursor.Next(ctx) {
+		var tenant models.Tenant
+		if err := cursor.Decode(&tenant); err != nil {
+			return nil, fmt.Errorf("failed to decode tenant: %w", err)
+		}
+		tenants = append(tenants, &tenant)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return tenants, nil
+}
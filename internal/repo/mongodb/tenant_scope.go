@@ -0,0 +1,40 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scoped restricts a query filter to the tenant carried by ctx.
+// Every repository query must go through it so data never leaks across tenants.
+// Contexts without a tenant (single-tenant deployments, requests without an
+// api key) only match documents that belong to no tenant. Only contexts marked
+// with models.WithAllTenants (sweeps, the consumer, admin requests) are left
+// unscoped.
+func scoped(ctx context.Context, filter bson.M) bson.M {
+	if models.AllTenantsFromContext(ctx) {
+		return filter
+	}
+	filter["tenant_id"] = ctxTenantID(ctx)
+	return filter
+}
+
+// exactTenant restricts filter to the tenant of ctx, or to documents without a
+// tenant when ctx has none, even for contexts reading across tenants. Upserts
+// and documents keyed per tenant go through it, so work without a tenant never
+// touches or takes over a tenant's document.
+func exactTenant(ctx context.Context, filter bson.M) bson.M {
+	filter["tenant_id"] = ctxTenantID(ctx)
+	return filter
+}
+
+// ctxTenantID returns the tenant of ctx to stamp on newly created documents
+func ctxTenantID(ctx context.Context) *primitive.ObjectID {
+	if tenantID, ok := models.TenantIDFromContext(ctx); ok {
+		return &tenantID
+	}
+	return nil
+}
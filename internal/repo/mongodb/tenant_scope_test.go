@@ -0,0 +1,58 @@
+package mongodb
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestScopedQueries(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tenantA := primitive.NewObjectID()
+	tenantB := primitive.NewObjectID()
+	// a user of tenant B, as stored
+	stored := bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "tenant_id", Value: tenantB}}
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		wantVisible bool
+	}{
+		{"Without Tenant", context.Background(), false},
+		{"Other Tenant", models.WithTenantID(context.Background(), tenantA), false},
+		{"Same Tenant", models.WithTenantID(context.Background(), tenantB), true},
+		{"All Tenants", models.WithAllTenants(context.Background()), true},
+		{"Tenant Wins Over All Tenants", models.WithTenantID(models.WithAllTenants(context.Background()), tenantA), false},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, "test.users", mtest.FirstBatch))
+			repo := &userRepo{collection: mt.Coll}
+
+			_, _ = repo.GetByID(tt.ctx, stored[0].Value.(primitive.ObjectID))
+
+			event := mt.GetStartedEvent()
+			require.NotNil(mt, event)
+			filter := event.Command.Lookup("filter").Document()
+			assert.Equal(mt, tt.wantVisible, visible(filter, tenantB), "filter %s", filter)
+		})
+	}
+}
+
+// visible reports whether the tenant condition of filter matches a document
+// of tenantID
+func visible(filter bson.Raw, tenantID primitive.ObjectID) bool {
+	condition, err := filter.LookupErr("tenant_id")
+	if err != nil {
+		return true
+	}
+	id, ok := condition.ObjectIDOK()
+	return ok && id == tenantID
+}
@@ -0,0 +1,90 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const defaultTestMessageLimit = 100
+
+type TestMessageRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, message *models.TestMessage) error
+	// List returns the held messages across tenants, latest first, of the
+	// channel when channelID is set
+	List(ctx context.Context, channelID string, limit int) ([]*models.TestMessage, error)
+}
+
+type testMessageRepo struct {
+	collection *mongo.Collection
+}
+
+func NewTestMessageRepository(db *DB) TestMessageRepository {
+	return &testMessageRepo{
+		collection: db.Database.Collection("test_messages"),
+	}
+}
+
+func (r *testMessageRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "channel_id", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("channel_id_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create test message indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *testMessageRepo) Create(ctx context.Context, message *models.TestMessage) error {
+	message.ID = primitive.NewObjectID()
+	message.TenantID = ctxTenantID(ctx)
+	message.CreatedAt = time.Now()
+
+	if _, err := r.collection.InsertOne(ctx, message); err != nil {
+		return fmt.Errorf("failed to create test message: %w", err)
+	}
+	return nil
+}
+
+func (r *testMessageRepo) List(ctx context.Context, channelID string, limit int) ([]*models.TestMessage, error) {
+	query := bson.M{}
+	if channelID != "" {
+		query["channel_id"] = channelID
+	}
+	if limit <= 0 {
+		limit = defaultTestMessageLimit
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list test messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var messages []*models.TestMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode test messages: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,102 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TranscriptRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	Create(ctx context.Context, transcript *models.SessionTranscript) error
+	GetBySessionID(ctx context.Context, sessionID primitive.ObjectID) (*models.SessionTranscript, error)
+	// ListRecent returns the newest transcripts, of chatMode only when it is set
+	ListRecent(ctx context.Context, chatMode string, limit int) ([]*models.SessionTranscript, error)
+}
+
+type transcriptRepo struct {
+	collection *mongo.Collection
+}
+
+func NewTranscriptRepository(db *DB) TranscriptRepository {
+	return &transcriptRepo{
+		collection: db.Database.Collection("session_transcripts"),
+	}
+}
+
+// EnsureIndexes creates the session lookup index and the TTL index that
+// removes transcripts once they pass expires_at
+func (r *transcriptRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "session_id", Value: 1}},
+			Options: options.Index().SetName("uniq_session").SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "chat_mode", Value: 1}, {Key: "created_at", Value: -1}},
+			Options: options.Index().SetName("chat_mode_created_at"),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetName("ttl_expires_at").SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create transcript indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *transcriptRepo) Create(ctx context.Context, transcript *models.SessionTranscript) error {
+	transcript.ID = primitive.NewObjectID()
+	if transcript.TenantID == nil {
+		transcript.TenantID = ctxTenantID(ctx)
+	}
+	transcript.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, transcript)
+	if err != nil {
+		return fmt.Errorf("failed to create transcript: %w", err)
+	}
+	return nil
+}
+
+func (r *transcriptRepo) GetBySessionID(ctx context.Context, sessionID primitive.ObjectID) (*models.SessionTranscript, error) {
+	var transcript models.SessionTranscript
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"session_id": sessionID})).Decode(&transcript)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, models.ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get transcript: %w", err)
+	}
+	return &transcript, nil
+}
+
+func (r *transcriptRepo) ListRecent(ctx context.Context, chatMode string, limit int) ([]*models.SessionTranscript, error) {
+	filter := bson.M{}
+	if chatMode != "" {
+		filter["chat_mode"] = chatMode
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, scoped(ctx, filter), opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transcripts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transcripts []*models.SessionTranscript
+	if err := cursor.All(ctx, &transcripts); err != nil {
+		return nil, fmt.Errorf("failed to decode transcripts: %w", err)
+	}
+	return transcripts, nil
+}
@@ -14,8 +14,12 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*models.User, error)
+	GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
+	// SetEmailVerified sets when the user's email was verified, clearing it
+	// when at is nil
+	SetEmailVerified(ctx context.Context, id primitive.ObjectID, at *time.Time) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
 	List(ctx context.Context, limit, offset int) ([]*models.User, error)
 }
@@ -32,6 +36,9 @@ func NewUserRepository(db *DB) UserRepository {
 
 func (r *userRepo) Create(ctx context.Context, user *models.User) error {
 	user.ID = primitive.NewObjectID()
+	if user.TenantID == nil {
+		user.TenantID = ctxTenantID(ctx)
+	}
 	user.CreatedAt = time.Now()
 	user.UpdatedAt = time.Now()
 
@@ -44,7 +51,7 @@ func (r *userRepo) Create(ctx context.Context, user *models.User) error {
 
 func (r *userRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&user)
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("user not found")
@@ -54,9 +61,37 @@ func (r *userRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.
 	return &user, nil
 }
 
+// GetByIDs fetches users in a single query, silently skipping unknown IDs
+func (r *userRepo) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users by IDs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var users []*models.User
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			return nil, fmt.Errorf("failed to decode user: %w", err)
+		}
+		users = append(users, &user)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return users, nil
+}
+
 func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User, error) {
 	var user models.User
-	err := r.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"email": email})).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -69,7 +104,7 @@ func (r *userRepo) GetByEmail(ctx context.Context, email string) (*models.User,
 func (r *userRepo) Update(ctx context.Context, user *models.User) error {
 	user.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": user.ID}
+	filter := scoped(ctx, bson.M{"_id": user.ID})
 	update := bson.M{"$set": user}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
@@ -79,8 +114,21 @@ func (r *userRepo) Update(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+func (r *userRepo) SetEmailVerified(ctx context.Context, id primitive.ObjectID, at *time.Time) error {
+	update := bson.M{"$unset": bson.M{"email_verified_at": ""}}
+	if at != nil {
+		update = bson.M{"$set": bson.M{"email_verified_at": *at}}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, scoped(ctx, bson.M{"_id": id}), update)
+	if err != nil {
+		return fmt.Errorf("failed to set email verification: %w", err)
+	}
+	return nil
+}
+
 func (r *userRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	_, err := r.collection.DeleteOne(ctx, scoped(ctx, bson.M{"_id": id}))
 	if err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
@@ -88,7 +136,7 @@ func (r *userRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
 }
 
 func (r *userRepo) List(ctx context.Context, limit, offset int) ([]*models.User, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{})
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
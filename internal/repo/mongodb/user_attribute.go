@@ -13,11 +13,15 @@ import (
 )
 
 type UserAttributeRepository interface {
+	EnsureIndexes(ctx context.Context) error
 	Create(ctx context.Context, attr *models.UserAttribute) error
 	GetByID(ctx context.Context, id primitive.ObjectID) (*models.UserAttribute, error)
 	GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.UserAttribute, error)
+	GetByUserIDs(ctx context.Context, userIDs []primitive.ObjectID) ([]*models.UserAttribute, error)
 	GetByUserIDAndKey(ctx context.Context, userID primitive.ObjectID, key string) (*models.UserAttribute, error)
 	GetByKey(ctx context.Context, key string) ([]*models.UserAttribute, error)
+	GetByKeyAndValue(ctx context.Context, key, value string) (*models.UserAttribute, error)
+	GetByKeyAndValues(ctx context.Context, key string, values []string) ([]*models.UserAttribute, error)
 	GetByTags(ctx context.Context, tags []string) ([]*models.UserAttribute, error)
 	GetByUserIDAndTags(ctx context.Context, userID primitive.ObjectID, tags []string) ([]*models.UserAttribute, error)
 	Update(ctx context.Context, attr *models.UserAttribute) error
@@ -36,12 +40,48 @@ func NewUserAttributeRepository(db *DB) UserAttributeRepository {
 	}
 }
 
+// EnsureIndexes backs key/value lookups and guarantees identity attributes
+// (models.UniqueAttributeKeys) are claimed by a single user
+func (r *userAttributeRepo) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "key", Value: 1}, {Key: "value", Value: 1}},
+			Options: options.Index().SetName("key_value"),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetName("user_id_key"),
+		},
+	}
+	for _, key := range models.UniqueAttributeKeys {
+		indexes = append(indexes, mongo.IndexModel{
+			Keys: bson.D{{Key: "value", Value: 1}},
+			Options: options.Index().
+				SetName("uniq_" + key).
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"key": key}),
+		})
+	}
+
+	_, err := r.collection.Indexes().CreateMany(ctx, indexes)
+	if err != nil {
+		return fmt.Errorf("failed to create user attribute indexes: %w", err)
+	}
+	return nil
+}
+
 func (r *userAttributeRepo) Create(ctx context.Context, attr *models.UserAttribute) error {
 	attr.ID = primitive.NewObjectID()
+	if attr.TenantID == nil {
+		attr.TenantID = ctxTenantID(ctx)
+	}
 	attr.CreatedAt = time.Now()
 	attr.UpdatedAt = time.Now()
 
 	_, err := r.collection.InsertOne(ctx, attr)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrAttributeConflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to create user attribute: %w", err)
 	}
@@ -50,7 +90,7 @@ func (r *userAttributeRepo) Create(ctx context.Context, attr *models.UserAttribu
 
 func (r *userAttributeRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.UserAttribute, error) {
 	var attr models.UserAttribute
-	err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&attr)
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{"_id": id})).Decode(&attr)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, fmt.Errorf("user attribute not found")
@@ -61,7 +101,7 @@ func (r *userAttributeRepo) GetByID(ctx context.Context, id primitive.ObjectID)
 }
 
 func (r *userAttributeRepo) GetByUserID(ctx context.Context, userID primitive.ObjectID) ([]*models.UserAttribute, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID})
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{"user_id": userID}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user attributes: %w", err)
 	}
@@ -83,12 +123,19 @@ func (r *userAttributeRepo) GetByUserID(ctx context.Context, userID primitive.Ob
 	return attrs, nil
 }
 
+func (r *userAttributeRepo) GetByUserIDs(ctx context.Context, userIDs []primitive.ObjectID) ([]*models.UserAttribute, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.find(ctx, bson.M{"user_id": bson.M{"$in": userIDs}})
+}
+
 func (r *userAttributeRepo) GetByUserIDAndKey(ctx context.Context, userID primitive.ObjectID, key string) (*models.UserAttribute, error) {
 	var attr models.UserAttribute
-	err := r.collection.FindOne(ctx, bson.M{
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{
 		"user_id": userID,
 		"key":     key,
-	}).Decode(&attr)
+	})).Decode(&attr)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			return nil, nil
@@ -99,7 +146,7 @@ func (r *userAttributeRepo) GetByUserIDAndKey(ctx context.Context, userID primit
 }
 
 func (r *userAttributeRepo) GetByKey(ctx context.Context, key string) ([]*models.UserAttribute, error) {
-	cursor, err := r.collection.Find(ctx, bson.M{"key": key})
+	cursor, err := r.collection.Find(ctx, scoped(ctx, bson.M{"key": key}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user attributes by key: %w", err)
 	}
@@ -121,8 +168,58 @@ func (r *userAttributeRepo) GetByKey(ctx context.Context, key string) ([]*models
 	return attrs, nil
 }
 
+// GetByKeyAndValue returns the attribute with the given key and value, or nil when there is none
+func (r *userAttributeRepo) GetByKeyAndValue(ctx context.Context, key, value string) (*models.UserAttribute, error) {
+	var attr models.UserAttribute
+	err := r.collection.FindOne(ctx, scoped(ctx, bson.M{
+		"key":   key,
+		"value": value,
+	})).Decode(&attr)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get user attribute by key and value: %w", err)
+	}
+	return &attr, nil
+}
+
+// GetByKeyAndValues resolves many values of one key in a single query
+func (r *userAttributeRepo) GetByKeyAndValues(ctx context.Context, key string, values []string) ([]*models.UserAttribute, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return r.find(ctx, bson.M{
+		"key":   key,
+		"value": bson.M{"$in": values},
+	})
+}
+
+func (r *userAttributeRepo) find(ctx context.Context, filter bson.M) ([]*models.UserAttribute, error) {
+	cursor, err := r.collection.Find(ctx, scoped(ctx, filter))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user attributes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var attrs []*models.UserAttribute
+	for cursor.Next(ctx) {
+		var attr models.UserAttribute
+		if err := cursor.Decode(&attr); err != nil {
+			return nil, fmt.Errorf("failed to decode user attribute: %w", err)
+		}
+		attrs = append(attrs, &attr)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cursor error: %w", err)
+	}
+
+	return attrs, nil
+}
+
 func (r *userAttributeRepo) GetByTags(ctx context.Context, tags []string) ([]*models.UserAttribute, error) {
-	filter := bson.M{"tags": bson.M{"$in": tags}}
+	filter := scoped(ctx, bson.M{"tags": bson.M{"$in": tags}})
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user attributes by tags: %w", err)
@@ -146,10 +243,10 @@ func (r *userAttributeRepo) GetByTags(ctx context.Context, tags []string) ([]*mo
 }
 
 func (r *userAttributeRepo) GetByUserIDAndTags(ctx context.Context, userID primitive.ObjectID, tags []string) ([]*models.UserAttribute, error) {
-	filter := bson.M{
+	filter := scoped(ctx, bson.M{
 		"user_id": userID,
 		"tags":    bson.M{"$in": tags},
-	}
+	})
 	cursor, err := r.collection.Find(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user attributes by user ID and tags: %w", err)
@@ -175,10 +272,13 @@ func (r *userAttributeRepo) GetByUserIDAndTags(ctx context.Context, userID primi
 func (r *userAttributeRepo) Update(ctx context.Context, attr *models.UserAttribute) error {
 	attr.UpdatedAt = time.Now()
 
-	filter := bson.M{"_id": attr.ID}
+	filter := scoped(ctx, bson.M{"_id": attr.ID})
 	update := bson.M{"$set": attr}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update)
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrAttributeConflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update user attribute: %w", err)
 	}
@@ -189,10 +289,10 @@ func (r *userAttributeRepo) Upsert(ctx context.Context, attr *models.UserAttribu
 	now := time.Now()
 	attr.UpdatedAt = now
 
-	filter := bson.M{
+	filter := scoped(ctx, bson.M{
 		"user_id": attr.UserID,
 		"key":     attr.Key,
-	}
+	})
 
 	update := bson.M{
 		"$set": bson.M{
@@ -207,6 +307,9 @@ func (r *userAttributeRepo) Upsert(ctx context.Context, attr *models.UserAttribu
 	}
 
 	_, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return models.ErrAttributeConflict
+	}
 	if err != nil {
 		return fmt.Errorf("failed to upsert user attribute: %w", err)
 	}
@@ -214,7 +317,7 @@ func (r *userAttributeRepo) Upsert(ctx context.Context, attr *models.UserAttribu
 }
 
 func (r *userAttributeRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	_, err := r.collection.DeleteOne(ctx, scoped(ctx, bson.M{"_id": id}))
 	if err != nil {
 		return fmt.Errorf("failed to delete user attribute: %w", err)
 	}
@@ -222,10 +325,10 @@ func (r *userAttributeRepo) Delete(ctx context.Context, id primitive.ObjectID) e
 }
 
 func (r *userAttributeRepo) DeleteByUserIDAndKey(ctx context.Context, userID primitive.ObjectID, key string) error {
-	_, err := r.collection.DeleteOne(ctx, bson.M{
+	_, err := r.collection.DeleteOne(ctx, scoped(ctx, bson.M{
 		"user_id": userID,
 		"key":     key,
-	})
+	}))
 	if err != nil {
 		return fmt.Errorf("failed to delete user attribute: %w", err)
 	}
@@ -0,0 +1,141 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// whitelistRolloutID is the _id of the single rollout document
+const whitelistRolloutID = "rollout"
+
+// WhitelistRepository stores the whitelist entries and rollout added by
+// administrators. They are global, so nothing here is scoped to a tenant.
+type WhitelistRepository interface {
+	EnsureIndexes(ctx context.Context) error
+	// Add stores the entry, keeping the existing one when the kind and
+	// value are already whitelisted
+	Add(ctx context.Context, entry *models.WhitelistEntry) error
+	// Get returns the entry, or nil when there is none
+	Get(ctx context.Context, kind models.WhitelistKind, value string) (*models.WhitelistEntry, error)
+	List(ctx context.Context) ([]*models.WhitelistEntry, error)
+	Remove(ctx context.Context, kind models.WhitelistKind, value string) error
+	// GetRollout returns the rollout, or nil when none was set
+	GetRollout(ctx context.Context) (*models.WhitelistRollout, error)
+	SetRollout(ctx context.Context, rollout *models.WhitelistRollout) error
+}
+
+type whitelistRepo struct {
+	collection *mongo.Collection
+	settings   *mongo.Collection
+}
+
+func NewWhitelistRepository(db *DB) WhitelistRepository {
+	return &whitelistRepo{
+		collection: db.Database.Collection("whitelist_entries"),
+		settings:   db.Database.Collection("whitelist_settings"),
+	}
+}
+
+func (r *whitelistRepo) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "kind", Value: 1}, {Key: "value", Value: 1}},
+			Options: options.Index().SetName("uniq_kind_value").SetUnique(true),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create whitelist indexes: %w", err)
+	}
+	return nil
+}
+
+func (r *whitelistRepo) Add(ctx context.Context, entry *models.WhitelistEntry) error {
+	filter := bson.M{"kind": entry.Kind, "value": entry.Value}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"note":       entry.Note,
+			"created_by": entry.CreatedBy,
+			"created_at": time.Now(),
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(entry); err != nil {
+		return fmt.Errorf("failed to add whitelist entry: %w", err)
+	}
+	return nil
+}
+
+func (r *whitelistRepo) Get(ctx context.Context, kind models.WhitelistKind, value string) (*models.WhitelistEntry, error) {
+	var entry models.WhitelistEntry
+	err := r.collection.FindOne(ctx, bson.M{"kind": kind, "value": value}).Decode(&entry)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get whitelist entry: %w", err)
+	}
+	return &entry, nil
+}
+
+func (r *whitelistRepo) List(ctx context.Context) ([]*models.WhitelistEntry, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "kind", Value: 1}, {Key: "value", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list whitelist entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []*models.WhitelistEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode whitelist entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (r *whitelistRepo) Remove(ctx context.Context, kind models.WhitelistKind, value string) error {
+	result, err := r.collection.DeleteOne(ctx, bson.M{"kind": kind, "value": value})
+	if err != nil {
+		return fmt.Errorf("failed to remove whitelist entry: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+func (r *whitelistRepo) GetRollout(ctx context.Context) (*models.WhitelistRollout, error) {
+	var rollout models.WhitelistRollout
+	err := r.settings.FindOne(ctx, bson.M{"_id": whitelistRolloutID}).Decode(&rollout)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get whitelist rollout: %w", err)
+	}
+	return &rollout, nil
+}
+
+func (r *whitelistRepo) SetRollout(ctx context.Context, rollout *models.WhitelistRollout) error {
+	rollout.UpdatedAt = time.Now()
+	update := bson.M{"$set": bson.M{
+		"percent":    rollout.Percent,
+		"updated_by": rollout.UpdatedBy,
+		"updated_at": rollout.UpdatedAt,
+	}}
+
+	opts := options.Update().SetUpsert(true)
+	if _, err := r.settings.UpdateOne(ctx, bson.M{"_id": whitelistRolloutID}, update, opts); err != nil {
+		return fmt.Errorf("failed to set whitelist rollout: %w", err)
+	}
+	return nil
+}
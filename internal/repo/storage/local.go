@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+)
+
+type localStorage struct {
+	dir           string
+	publicBaseURL string
+}
+
+// NewLocalStorage writes objects to a directory which the HTTP server exposes under /media
+func NewLocalStorage(cfg config.StorageConfig) Storage {
+	return &localStorage{
+		dir:           cfg.LocalDir,
+		publicBaseURL: strings.TrimSuffix(cfg.PublicBaseURL, "/"),
+	}
+}
+
+func (s *localStorage) Put(ctx context.Context, key, contentType string, data []byte) (string, error) {
+	key = path.Clean("/" + key)[1:]
+	target := filepath.Join(s.dir, filepath.FromSlash(key))
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	if err := os.WriteFile(target, data, 0o644); err != nil {
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+	return s.publicBaseURL + "/" + key, nil
+}
@@ -0,0 +1,32 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/httpx"
+)
+
+const (
+	ProviderLocal = "local"
+	ProviderS3    = "s3"
+)
+
+// Storage persists public media objects such as avatars
+type Storage interface {
+	// Put writes data under key and returns the URL clients should load it from
+	Put(ctx context.Context, key, contentType string, data []byte) (string, error)
+}
+
+// NewStorage returns the provider selected by the storage config
+func NewStorage(cfg *config.Config) (Storage, error) {
+	switch cfg.Storage.Provider {
+	case ProviderLocal:
+		return NewLocalStorage(cfg.Storage), nil
+	case ProviderS3:
+		return NewS3Storage(cfg.Storage, httpx.New("s3", cfg.PartnerHTTP))
+	default:
+		return nil, fmt.Errorf("unsupported storage provider: %s", cfg.Storage.Provider)
+	}
+}
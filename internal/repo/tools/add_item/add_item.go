@@ -0,0 +1,144 @@
+package add_item
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ToolName        = "AddItem"
+	ToolDescription = "Add another of the seller's listings to the items discussed in this chat, e.g. one found with ListProducts that the buyer asks about. Set current when the buyer is now talking about it. Returns every item discussed in the chat."
+)
+
+// AddItemArgs defines the arguments for the AddItem tool
+type AddItemArgs struct {
+	ItemID    string `json:"item_id"`
+	ItemName  string `json:"item_name"`
+	ItemPrice string `json:"item_price,omitempty"`
+	Current   bool   `json:"current,omitempty"`
+}
+
+// AddItemOutput defines the output of the AddItem tool
+type AddItemOutput struct {
+	Items []models.ChannelItem `json:"items"`
+}
+
+type Tool interface {
+	toolsmanager.Tool
+}
+
+// tool implements the toolsmanager.Tool interface
+type tool struct {
+	activityRepo    mongodb.ChatActivityRepository
+	channelItemRepo mongodb.ChannelItemRepository
+}
+
+// NewTool creates a new AddItem tool instance
+func NewTool(
+	activityRepo mongodb.ChatActivityRepository,
+	channelItemRepo mongodb.ChannelItemRepository,
+) Tool {
+	return &tool{
+		activityRepo:    activityRepo,
+		channelItemRepo: channelItemRepo,
+	}
+}
+
+// Name returns the tool's unique identifier
+func (t *tool) Name() string {
+	return ToolName
+}
+
+// Description returns a human-readable description of what the tool does
+func (t *tool) Description() string {
+	return ToolDescription
+}
+
+// Execute runs the tool with the given arguments and session context
+func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanager.SessionContext) (interface{}, error) {
+	var addArgs AddItemArgs
+	if err := t.parseArgs(args, &addArgs); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if addArgs.ItemID == "" || addArgs.ItemName == "" {
+		return nil, fmt.Errorf("item_id and item_name are required")
+	}
+
+	// messages seed the channel's items, so they exist by the time tools run
+	items, err := t.channelItemRepo.Seed(ctx, session.GetChannelID(), nil)
+	if err != nil {
+		return nil, err
+	}
+	items.Add(models.ChannelItem{
+		ItemID:    addArgs.ItemID,
+		ItemName:  addArgs.ItemName,
+		ItemPrice: addArgs.ItemPrice,
+	}, addArgs.Current)
+	if err := t.channelItemRepo.SetItems(ctx, session.GetChannelID(), items.Items); err != nil {
+		return nil, fmt.Errorf("failed to add item: %w", err)
+	}
+
+	if err := t.logActivity(ctx, addArgs, session); err != nil {
+		log.Errorf(ctx, "Failed to log AddItem activity: %v", err)
+	}
+
+	log.Infow(ctx, "Item added to channel", "channel_id", session.GetChannelID(), "item_id", addArgs.ItemID, "current", addArgs.Current)
+	return &AddItemOutput{Items: items.Items}, nil
+}
+
+// GetGenkitTool returns the Firebase Genkit tool definition for AI integration
+func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
+	return genkit.DefineTool(g, ToolName, ToolDescription,
+		func(toolCtx *ai.ToolContext, input AddItemArgs) (*AddItemOutput, error) {
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
+			if err != nil {
+				return nil, err
+			}
+
+			if output, ok := result.(*AddItemOutput); ok {
+				return output, nil
+			}
+			return nil, fmt.Errorf("unexpected result type: %T", result)
+		})
+}
+
+// parseArgs converts interface{} arguments to the expected type
+func (t *tool) parseArgs(args interface{}, target interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal args: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+	return nil
+}
+
+// logActivity logs the tool execution activity
+func (t *tool) logActivity(ctx context.Context, args AddItemArgs, session toolsmanager.SessionContext) error {
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	activity := &models.ChatActivity{
+		SessionID: sessionID,
+		ChannelID: session.GetChannelID(),
+		Action:    models.ActivityAddItem,
+		Data:      args,
+	}
+
+	return t.activityRepo.Create(ctx, activity)
+}
@@ -84,7 +84,10 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
 	return genkit.DefineTool(g, ToolName, ToolDescription,
 		func(toolCtx *ai.ToolContext, input EndSessionArgs) (string, error) {
-			result, err := t.Execute(session.Context(), input, session)
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
 			if err != nil {
 				return "", err
 			}
@@ -0,0 +1,85 @@
+package fetch_full_context
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+)
+
+const (
+	ToolName        = "FetchFullContext"
+	ToolDescription = "Fetch the full channel context when the prompt only has its summary and a detail is missing from it"
+)
+
+// FetchFullContextArgs defines the arguments for the FetchFullContext tool
+type FetchFullContextArgs struct{}
+
+// FetchFullContextOutput defines the output of the FetchFullContext tool
+type FetchFullContextOutput struct {
+	Context string `json:"context,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type Tool interface {
+	toolsmanager.Tool
+}
+
+// tool implements the toolsmanager.Tool interface
+type tool struct {
+	channelContextRepo mongodb.ChannelContextRepository
+}
+
+// NewTool creates a new FetchFullContext tool instance
+func NewTool(channelContextRepo mongodb.ChannelContextRepository) Tool {
+	return &tool{
+		channelContextRepo: channelContextRepo,
+	}
+}
+
+// Name returns the tool's unique identifier
+func (t *tool) Name() string {
+	return ToolName
+}
+
+// Description returns a human-readable description of what the tool does
+func (t *tool) Description() string {
+	return ToolDescription
+}
+
+// Execute runs the tool with the given arguments and session context
+func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanager.SessionContext) (interface{}, error) {
+	channelContext, err := t.channelContextRepo.Get(ctx, session.GetChannelID())
+	if err != nil {
+		return nil, err
+	}
+	if channelContext == nil {
+		return &FetchFullContextOutput{Reason: "the channel context is not summarized, the prompt has all of it"}, nil
+	}
+
+	log.Infow(ctx, "Fetched full channel context", "channel_id", session.GetChannelID(), "chars", len(channelContext.Full))
+	return &FetchFullContextOutput{Context: channelContext.Full}, nil
+}
+
+// GetGenkitTool returns the Firebase Genkit tool definition for AI integration
+func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
+	return genkit.DefineTool(g, ToolName, ToolDescription,
+		func(toolCtx *ai.ToolContext, input FetchFullContextArgs) (*FetchFullContextOutput, error) {
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
+			if err != nil {
+				return nil, err
+			}
+
+			if output, ok := result.(*FetchFullContextOutput); ok {
+				return output, nil
+			}
+			return nil, fmt.Errorf("unexpected result type: %T", result)
+		})
+}
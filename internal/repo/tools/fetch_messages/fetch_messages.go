@@ -8,6 +8,7 @@ import (
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
@@ -33,17 +34,24 @@ type Tool interface {
 type tool struct {
 	chatAPIClient chatapi.Client
 	activityRepo  mongodb.ChatActivityRepository
+	// privacyRepo and privacy leave private messages out of the results
+	privacyRepo mongodb.ChannelPrivacyRepository
+	privacy     config.PrivacyConfig
 }
 
 // NewTool creates a new FetchMessages tool instance
 func NewTool(
 	chatAPIClient chatapi.Client,
 	activityRepo mongodb.ChatActivityRepository,
+	privacyRepo mongodb.ChannelPrivacyRepository,
+	cfg *config.Config,
 	toolsManager toolsmanager.ToolsManager,
 ) Tool {
 	t := &tool{
 		chatAPIClient: chatAPIClient,
 		activityRepo:  activityRepo,
+		privacyRepo:   privacyRepo,
+		privacy:       cfg.Privacy,
 	}
 	toolsManager.AddTool(t)
 	return t
@@ -82,6 +90,11 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch messages: %w", err)
 	}
+	privacy, err := t.privacyRepo.Get(ctx, session.GetChannelID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel privacy: %w", err)
+	}
+	messageHistory = privacy.FilterHistory(messageHistory, t.privacy.DetectSensitive)
 
 	// Log activity
 	if err := t.logActivity(ctx, fetchArgs, session); err != nil {
@@ -96,7 +109,10 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
 	return genkit.DefineTool(g, ToolName, ToolDescription,
 		func(toolCtx *ai.ToolContext, input FetchMessagesArgs) (*models.MessageHistory, error) {
-			result, err := t.Execute(session.Context(), input, session)
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
 			if err != nil {
 				return nil, err
 			}
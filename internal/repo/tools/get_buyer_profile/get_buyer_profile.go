@@ -0,0 +1,234 @@
+package get_buyer_profile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ToolName        = "GetBuyerProfile"
+	ToolDescription = "Get what is known about the buyer from earlier chats with this seller: whether they are returning, their past purchase intents, items reserved for them, their preferred language and a lead score from 0 to 100. Use it to personalize replies and avoid asking again what the buyer already told the seller."
+)
+
+const (
+	sessionLimit       = 50
+	intentLimit        = 5
+	languageSampleSize = 20
+)
+
+// GetBuyerProfileArgs defines the arguments for the GetBuyerProfile tool; the
+// buyer and seller come from the session
+type GetBuyerProfileArgs struct{}
+
+type Tool interface {
+	toolsmanager.Tool
+}
+
+// tool implements the toolsmanager.Tool interface
+type tool struct {
+	chatAPIClient      chatapi.Client
+	activityRepo       mongodb.ChatActivityRepository
+	sessionRepo        mongodb.ChatSessionRepository
+	purchaseIntentRepo mongodb.PurchaseIntentRepository
+	reservationRepo    mongodb.ReservationRepository
+}
+
+// NewTool creates a new GetBuyerProfile tool instance
+func NewTool(
+	chatAPIClient chatapi.Client,
+	activityRepo mongodb.ChatActivityRepository,
+	sessionRepo mongodb.ChatSessionRepository,
+	purchaseIntentRepo mongodb.PurchaseIntentRepository,
+	reservationRepo mongodb.ReservationRepository,
+) Tool {
+	return &tool{
+		chatAPIClient:      chatAPIClient,
+		activityRepo:       activityRepo,
+		sessionRepo:        sessionRepo,
+		purchaseIntentRepo: purchaseIntentRepo,
+		reservationRepo:    reservationRepo,
+	}
+}
+
+// Name returns the tool's unique identifier
+func (t *tool) Name() string {
+	return ToolName
+}
+
+// Description returns a human-readable description of what the tool does
+func (t *tool) Description() string {
+	return ToolDescription
+}
+
+// Execute runs the tool with the given arguments and session context
+func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanager.SessionContext) (interface{}, error) {
+	var profileArgs GetBuyerProfileArgs
+	if err := t.parseArgs(args, &profileArgs); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	buyerID := session.GetUserID()
+	sellerID := session.GetSenderID()
+	profile := &models.BuyerProfile{BuyerID: buyerID}
+
+	sessions, err := t.sessionRepo.ListByBuyerAndSeller(ctx, buyerID, sellerID, sessionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make(map[string]bool)
+	channelIDs := []string{}
+	for _, s := range sessions {
+		if !channels[s.ChannelID] {
+			channels[s.ChannelID] = true
+			channelIDs = append(channelIDs, s.ChannelID)
+		}
+		if s.ID.Hex() == session.GetSessionID() {
+			continue
+		}
+		profile.PreviousSessions++
+		startedAt := s.StartedAt
+		if profile.LastContactAt == nil {
+			profile.LastContactAt = &startedAt
+		}
+		profile.FirstContactAt = &startedAt
+	}
+	profile.Returning = profile.PreviousSessions > 0
+	profile.PreviousChannels = len(channelIDs)
+	if channels[session.GetChannelID()] {
+		profile.PreviousChannels--
+	}
+
+	if len(channelIDs) > 0 {
+		intents, err := t.purchaseIntentRepo.ListByUserAndChannels(ctx, buyerID, channelIDs, intentLimit)
+		if err != nil {
+			return nil, err
+		}
+		for _, intent := range intents {
+			profile.PurchaseIntents = append(profile.PurchaseIntents, models.BuyerIntent{
+				ItemName:   intent.ItemName,
+				Intent:     intent.Intent,
+				Percentage: intent.Percentage,
+				CreatedAt:  intent.CreatedAt,
+			})
+		}
+	}
+
+	reservations, err := t.reservationRepo.ListActiveBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		if reservation.BuyerID == buyerID {
+			profile.ReservedItems = append(profile.ReservedItems, reservation.ItemName)
+		}
+	}
+
+	profile.PreferredLanguage = t.preferredLanguage(ctx, session)
+	profile.LeadScore = leadScore(profile)
+
+	if err := t.logActivity(ctx, profile, session); err != nil {
+		log.Errorf(ctx, "Failed to log GetBuyerProfile activity: %v", err)
+	}
+
+	log.Infof(ctx, "Built profile of buyer %s: %d previous sessions, lead score %d", buyerID, profile.PreviousSessions, profile.LeadScore)
+	return profile, nil
+}
+
+// GetGenkitTool returns the Firebase Genkit tool definition for AI integration
+func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
+	return genkit.DefineTool(g, ToolName, ToolDescription,
+		func(toolCtx *ai.ToolContext, input GetBuyerProfileArgs) (*models.BuyerProfile, error) {
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
+			if err != nil {
+				return nil, err
+			}
+
+			if profile, ok := result.(*models.BuyerProfile); ok {
+				return profile, nil
+			}
+			return nil, fmt.Errorf("unexpected result type: %T", result)
+		})
+}
+
+// preferredLanguage guesses the language of the buyer's recent messages in
+// the current channel. The profile is still useful without it, so a failed
+// lookup only leaves it empty.
+func (t *tool) preferredLanguage(ctx context.Context, session toolsmanager.SessionContext) string {
+	history, err := t.chatAPIClient.GetMessageHistoryWithParams(ctx, chatapi.MessageHistoryRequest{
+		ChannelID: session.GetChannelID(),
+		UserID:    session.GetUserID(),
+		Limit:     languageSampleSize,
+	})
+	if err != nil {
+		log.Warnf(ctx, "Failed to fetch messages for buyer language: %v", err)
+		return ""
+	}
+
+	var texts []string
+	for _, message := range history.Messages {
+		if message.SenderID == session.GetUserID() {
+			texts = append(texts, message.Message)
+		}
+	}
+	return textx.DetectLanguage(texts...)
+}
+
+// leadScore starts from the strongest purchase intent the buyer showed, adds
+// 20 for an item on hold and 10 for a returning buyer, capped at 100
+func leadScore(profile *models.BuyerProfile) int {
+	score := 0
+	for _, intent := range profile.PurchaseIntents {
+		score = max(score, intent.Percentage)
+	}
+	if len(profile.ReservedItems) > 0 {
+		score += 20
+	}
+	if profile.Returning {
+		score += 10
+	}
+	return min(score, 100)
+}
+
+// parseArgs converts interface{} arguments to the expected type
+func (t *tool) parseArgs(args interface{}, target interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal args: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+	return nil
+}
+
+// logActivity logs the tool execution activity
+func (t *tool) logActivity(ctx context.Context, profile *models.BuyerProfile, session toolsmanager.SessionContext) error {
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	activity := &models.ChatActivity{
+		SessionID: sessionID,
+		ChannelID: session.GetChannelID(),
+		Action:    models.ActivityGetBuyerProfile,
+		Data:      profile,
+	}
+
+	return t.activityRepo.Create(ctx, activity)
+}
@@ -41,6 +41,7 @@ type tool struct {
 	userAttributeRepo mongodb.UserAttributeRepository
 	activityRepo      mongodb.ChatActivityRepository
 	serviceRegistry   ProductServiceRegistry
+	tenantRepo        mongodb.TenantRepository
 }
 
 // NewTool creates a new ListProducts tool instance
@@ -49,12 +50,14 @@ func NewTool(
 	userAttributeRepo mongodb.UserAttributeRepository,
 	activityRepo mongodb.ChatActivityRepository,
 	serviceRegistry ProductServiceRegistry,
+	tenantRepo mongodb.TenantRepository,
 ) Tool {
 	return &tool{
 		userRepo:          userRepo,
 		userAttributeRepo: userAttributeRepo,
 		activityRepo:      activityRepo,
 		serviceRegistry:   serviceRegistry,
+		tenantRepo:        tenantRepo,
 	}
 }
 
@@ -91,23 +94,12 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 	}
 
 	// Step 1: Map from chotot_id (seller ID) to internal user ID
-	// Find user attribute with key="chotot_id" and value=sellerID
-	chototIDAttrs, err := t.userAttributeRepo.GetByKey(ctx, "chotot_id")
+	chototIDAttr, err := t.userAttributeRepo.GetByKeyAndValue(ctx, models.AttributeChototID, sellerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get chotot_id attributes: %w", err)
+		return nil, fmt.Errorf("failed to get chotot_id attribute: %w", err)
 	}
 
-	var internalUserID primitive.ObjectID
-	var found bool
-	for _, attr := range chototIDAttrs {
-		if attr.Value == sellerID {
-			internalUserID = attr.UserID
-			found = true
-			break
-		}
-	}
-
-	if !found {
+	if chototIDAttr == nil {
 		log.Infow(ctx, "No internal user found for chotot_id", "chotot_id", sellerID)
 		return &ListProductsOutput{
 			Products: []Product{},
@@ -116,7 +108,8 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 	}
 
 	// Step 2: Get the chotot_oid attribute for this internal user
-	chototOIDAttr, err := t.userAttributeRepo.GetByUserIDAndKey(ctx, internalUserID, "chotot_oid")
+	internalUserID := chototIDAttr.UserID
+	chototOIDAttr, err := t.userAttributeRepo.GetByUserIDAndKey(ctx, internalUserID, models.AttributeChototOID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chotot_oid attribute: %w", err)
 	}
@@ -129,6 +122,18 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 		}, nil
 	}
 
+	enabled, err := t.isPartnerEnabled(ctx, "chotot")
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		log.Infow(ctx, "Chotot partner disabled for tenant", "chotot_id", sellerID)
+		return &ListProductsOutput{
+			Products: []Product{},
+			Total:    0,
+		}, nil
+	}
+
 	// Get Chotot product service
 	service, exists := t.serviceRegistry.GetService("chotot")
 	if !exists {
@@ -166,7 +171,10 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
 	return genkit.DefineTool(g, ToolName, ToolDescription,
 		func(toolCtx *ai.ToolContext, input ListProductsInput) (*ListProductsOutput, error) {
-			result, err := t.Execute(session.Context(), input, session)
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
 			if err != nil {
 				return nil, err
 			}
@@ -178,6 +186,20 @@ func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genk
 		})
 }
 
+// isPartnerEnabled checks the partner against the settings of the session's tenant
+func (t *tool) isPartnerEnabled(ctx context.Context, partner string) (bool, error) {
+	tenantID, ok := models.TenantIDFromContext(ctx)
+	if !ok {
+		return true, nil
+	}
+
+	tenant, err := t.tenantRepo.GetByID(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant.Settings.IsPartnerEnabled(partner), nil
+}
+
 // parseArgs converts interface{} arguments to the expected type
 func (t *tool) parseArgs(args interface{}, target interface{}) error {
 	data, err := json.Marshal(args)
@@ -4,10 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
@@ -38,19 +40,25 @@ type tool struct {
 	chatAPIClient      chatapi.Client
 	activityRepo       mongodb.ChatActivityRepository
 	purchaseIntentRepo mongodb.PurchaseIntentRepository
+	feedRepo           mongodb.ActivityFeedRepository
+	feedRetention      time.Duration
 }
 
 // NewTool creates a new PurchaseIntent tool instance
 func NewTool(
+	cfg *config.Config,
 	chatAPIClient chatapi.Client,
 	activityRepo mongodb.ChatActivityRepository,
 	purchaseIntentRepo mongodb.PurchaseIntentRepository,
+	feedRepo mongodb.ActivityFeedRepository,
 	toolsManager toolsmanager.ToolsManager,
 ) Tool {
 	t := &tool{
 		chatAPIClient:      chatAPIClient,
 		activityRepo:       activityRepo,
 		purchaseIntentRepo: purchaseIntentRepo,
+		feedRepo:           feedRepo,
+		feedRetention:      cfg.ActivityFeed.Retention,
 	}
 	toolsManager.AddTool(t)
 	return t
@@ -109,6 +117,11 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 		log.Errorf(ctx, "Failed to log PurchaseIntent activity: %v", err)
 	}
 
+	// Show it in the seller's activity feed
+	if err := t.publishFeedItem(ctx, intent, session); err != nil {
+		log.Errorf(ctx, "Failed to publish PurchaseIntent feed item: %v", err)
+	}
+
 	log.Infof(ctx, "Purchase intent logged: %s wants to buy %s for %s (%d%% confidence)", session.GetUserID(), purchaseIntentArgs.ItemName, purchaseIntentArgs.ItemPrice, purchaseIntentArgs.Percentage)
 	return "Purchase intent logged successfully", nil
 }
@@ -119,7 +132,10 @@ func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genk
 		func(toolCtx *ai.ToolContext, input PurchaseIntentArgs) (string, error) {
 			// This is a placeholder - in practice, the session context will be provided
 			// by the tool manager when the tool is executed
-			result, err := t.Execute(session.Context(), input, session)
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
 			if err != nil {
 				return "", err
 			}
@@ -165,3 +181,21 @@ func (t *tool) logActivity(ctx context.Context, args PurchaseIntentArgs, session
 
 	return t.activityRepo.Create(ctx, activity)
 }
+
+// publishFeedItem adds the purchase intent to the seller's activity feed
+func (t *tool) publishFeedItem(ctx context.Context, intent *models.PurchaseIntent, session toolsmanager.SessionContext) error {
+	item := &models.FeedItem{
+		SellerID:  session.GetSenderID(),
+		Type:      models.FeedPurchaseIntent,
+		ChannelID: intent.ChannelID,
+		BuyerID:   intent.UserID,
+		Summary:   fmt.Sprintf("Buyer wants to buy %s for %s (%d%% confidence)", intent.ItemName, intent.ItemPrice, intent.Percentage),
+		Data: map[string]any{
+			"purchase_intent_id": intent.ID.Hex(),
+			"item_name":          intent.ItemName,
+			"item_price":         intent.ItemPrice,
+			"percentage":         intent.Percentage,
+		},
+	}
+	return t.feedRepo.Create(ctx, item, t.feedRetention)
+}
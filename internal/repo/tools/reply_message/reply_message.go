@@ -9,10 +9,13 @@ import (
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/firebase/genkit/go/ai"
 	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -32,19 +35,33 @@ type Tool interface {
 
 // Tool implements the toolsmanager.Tool interface
 type tool struct {
-	chatAPIClient chatapi.Client
-	activityRepo  mongodb.ChatActivityRepository
+	budgetConfig      config.BudgetConfig
+	feedRetention     time.Duration
+	chatAPIClient     chatapi.Client
+	activityRepo      mongodb.ChatActivityRepository
+	suggestionRepo    mongodb.ReplySuggestionRepository
+	channelBudgetRepo mongodb.ChannelBudgetRepository
+	feedRepo          mongodb.ActivityFeedRepository
 }
 
 // NewTool creates a new ReplyMessage tool instance
 func NewTool(
+	cfg *config.Config,
 	chatAPIClient chatapi.Client,
 	activityRepo mongodb.ChatActivityRepository,
+	suggestionRepo mongodb.ReplySuggestionRepository,
+	channelBudgetRepo mongodb.ChannelBudgetRepository,
+	feedRepo mongodb.ActivityFeedRepository,
 	toolsManager toolsmanager.ToolsManager,
 ) Tool {
 	t := &tool{
-		chatAPIClient: chatAPIClient,
-		activityRepo:  activityRepo,
+		budgetConfig:      cfg.Budget,
+		feedRetention:     cfg.ActivityFeed.Retention,
+		chatAPIClient:     chatAPIClient,
+		activityRepo:      activityRepo,
+		suggestionRepo:    suggestionRepo,
+		channelBudgetRepo: channelBudgetRepo,
+		feedRepo:          feedRepo,
 	}
 	toolsManager.AddTool(t)
 	return t
@@ -68,6 +85,10 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 		return nil, fmt.Errorf("failed to parse arguments: %w", err)
 	}
 
+	if session.RequiresApproval() {
+		return t.suggest(ctx, replyArgs, session)
+	}
+
 	// Send the message
 	outgoingMessage := &models.OutgoingMessage{
 		ChannelID: session.GetChannelID(),
@@ -76,11 +97,22 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 	}
 
 	if err := t.chatAPIClient.SendMessage(ctx, outgoingMessage); err != nil {
+		if feedErr := t.publishFailedDelivery(ctx, outgoingMessage, session, err); feedErr != nil {
+			log.Errorf(ctx, "Failed to publish ReplyMessage feed item: %v", feedErr)
+		}
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
+	models.OutboundFromContext(ctx).Mark()
+	livestats.Inc(models.StatBotReplies)
+
+	// Counted towards the channel's daily replies, see usecase.BudgetUsecase
+	day := models.BudgetDay(time.Now())
+	if err := t.channelBudgetRepo.Add(ctx, session.GetChannelID(), day, 1, 0, t.budgetConfig.Retention); err != nil {
+		log.Errorf(ctx, "Failed to count reply in channel budget: %v", err)
+	}
 
 	// Log activity
-	if err := t.logActivity(ctx, replyArgs, session); err != nil {
+	if err := t.logActivity(ctx, models.ActivityReplyMessage, replyArgs, session); err != nil {
 		log.Errorf(ctx, "Failed to log ReplyMessage activity: %v", err)
 	}
 
@@ -88,14 +120,41 @@ func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanag
 	return "Message sent successfully", nil
 }
 
+// suggest stores the reply for the seller to approve instead of sending it
+func (t *tool) suggest(ctx context.Context, args ReplyMessageArgs, session toolsmanager.SessionContext) (interface{}, error) {
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	suggestion := &models.ReplySuggestion{
+		SessionID: sessionID,
+		ChannelID: session.GetChannelID(),
+		SellerID:  session.GetSenderID(),
+		BuyerID:   session.GetUserID(),
+		Message:   textx.Normalize(args.Message),
+	}
+	if err := t.suggestionRepo.Create(ctx, suggestion); err != nil {
+		return nil, fmt.Errorf("failed to store reply suggestion: %w", err)
+	}
+	models.OutboundFromContext(ctx).Mark()
+
+	if err := t.logActivity(ctx, models.ActivityReplySuggested, args, session); err != nil {
+		log.Errorf(ctx, "Failed to log ReplyMessage activity: %v", err)
+	}
+
+	log.Infof(ctx, "Reply held for approval in channel %s: %s", session.GetChannelID(), suggestion.ID.Hex())
+	return "Reply saved for the seller to approve; it was not sent yet", nil
+}
+
 // GetGenkitTool returns the Firebase Genkit tool definition for AI integration
 func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
 	return genkit.DefineTool(g, ToolName, ToolDescription,
 		func(toolCtx *ai.ToolContext, input ReplyMessageArgs) (string, error) {
-			timeoutCtx, cancel := context.WithTimeout(session.Context(), 30*time.Second)
+			ctx, cancel := session.ToolContext(ToolName)
 			defer cancel()
 
-			result, err := t.Execute(timeoutCtx, input, session)
+			result, err := t.Execute(ctx, input, session)
 			if err != nil {
 				return "", err
 			}
@@ -120,7 +179,7 @@ func (t *tool) parseArgs(args interface{}, target interface{}) error {
 }
 
 // logActivity logs the tool execution activity
-func (t *tool) logActivity(ctx context.Context, args ReplyMessageArgs, session toolsmanager.SessionContext) error {
+func (t *tool) logActivity(ctx context.Context, action models.ActivityAction, args ReplyMessageArgs, session toolsmanager.SessionContext) error {
 	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
 	if err != nil {
 		return fmt.Errorf("invalid session ID: %w", err)
@@ -129,9 +188,27 @@ func (t *tool) logActivity(ctx context.Context, args ReplyMessageArgs, session t
 	activity := &models.ChatActivity{
 		SessionID: sessionID,
 		ChannelID: session.GetChannelID(),
-		Action:    models.ActivityReplyMessage,
+		Action:    action,
 		Data:      args,
 	}
 
 	return t.activityRepo.Create(ctx, activity)
 }
+
+// publishFailedDelivery tells the seller in their activity feed that a reply
+// did not reach the buyer
+func (t *tool) publishFailedDelivery(ctx context.Context, message *models.OutgoingMessage, session toolsmanager.SessionContext, sendErr error) error {
+	item := &models.FeedItem{
+		SellerID:  session.GetSenderID(),
+		Type:      models.FeedDeliveryFailed,
+		ChannelID: message.ChannelID,
+		BuyerID:   session.GetUserID(),
+		Summary:   "A bot reply could not be delivered to the buyer",
+		Data: map[string]any{
+			"message": message.Message,
+			"error":   sendErr.Error(),
+		},
+	}
+	// the send may have failed on the tool deadline, which must not drop the item
+	return t.feedRepo.Create(context.WithoutCancel(ctx), item, t.feedRetention)
+}
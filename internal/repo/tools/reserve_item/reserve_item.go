@@ -0,0 +1,205 @@
+package reserve_item
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ToolName        = "ReserveItem"
+	ToolDescription = "Place a short hold on one of the seller's listings for the buyer once they have committed to buy. The hold expires automatically. If another buyer already holds the item the result explains until when."
+)
+
+// ReserveItemArgs defines the arguments for the ReserveItem tool
+type ReserveItemArgs struct {
+	ItemID          string `json:"item_id"`
+	ItemName        string `json:"item_name"`
+	DurationMinutes int    `json:"duration_minutes,omitempty"`
+	Message         string `json:"message,omitempty"`
+}
+
+// ReserveItemOutput defines the output of the ReserveItem tool
+type ReserveItemOutput struct {
+	Reserved  bool      `json:"reserved"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Reason    string    `json:"reason,omitempty"`
+}
+
+type Tool interface {
+	toolsmanager.Tool
+}
+
+// tool implements the toolsmanager.Tool interface
+type tool struct {
+	config          config.ReservationConfig
+	chatAPIClient   chatapi.Client
+	activityRepo    mongodb.ChatActivityRepository
+	reservationRepo mongodb.ReservationRepository
+}
+
+// NewTool creates a new ReserveItem tool instance
+func NewTool(
+	cfg *config.Config,
+	chatAPIClient chatapi.Client,
+	activityRepo mongodb.ChatActivityRepository,
+	reservationRepo mongodb.ReservationRepository,
+) Tool {
+	return &tool{
+		config:          cfg.Reservation,
+		chatAPIClient:   chatAPIClient,
+		activityRepo:    activityRepo,
+		reservationRepo: reservationRepo,
+	}
+}
+
+// Name returns the tool's unique identifier
+func (t *tool) Name() string {
+	return ToolName
+}
+
+// Description returns a human-readable description of what the tool does
+func (t *tool) Description() string {
+	return ToolDescription
+}
+
+// Execute runs the tool with the given arguments and session context
+func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanager.SessionContext) (interface{}, error) {
+	// Parse arguments
+	var reserveArgs ReserveItemArgs
+	if err := t.parseArgs(args, &reserveArgs); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if reserveArgs.ItemID == "" {
+		return nil, fmt.Errorf("item_id is required")
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	reservation := &models.Reservation{
+		SessionID: sessionID,
+		ChannelID: session.GetChannelID(),
+		SellerID:  session.GetSenderID(),
+		BuyerID:   session.GetUserID(),
+		ItemID:    reserveArgs.ItemID,
+		ItemName:  reserveArgs.ItemName,
+		ExpiresAt: time.Now().Add(t.holdDuration(reserveArgs.DurationMinutes)),
+	}
+
+	err = t.reservationRepo.Reserve(ctx, reservation)
+	if errors.Is(err, models.ErrReservationConflict) {
+		output := &ReserveItemOutput{Reserved: false, Reason: "item is already reserved by another buyer"}
+		existing, getErr := t.reservationRepo.GetActiveByItem(ctx, reservation.SellerID, reservation.ItemID)
+		if getErr == nil && existing != nil {
+			output.ExpiresAt = existing.ExpiresAt
+		}
+		log.Infow(ctx, "Reservation conflict", "item_id", reservation.ItemID, "buyer_id", reservation.BuyerID)
+		return output, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve item: %w", err)
+	}
+	models.OutboundFromContext(ctx).Mark()
+
+	// Notify the seller in the channel, mirroring PurchaseIntent
+	if err := t.notifySeller(ctx, session, reservation, reserveArgs.Message); err != nil {
+		log.Errorf(ctx, "Failed to send reservation notice: %v", err)
+	}
+
+	// Log activity
+	if err := t.logActivity(ctx, reserveArgs, sessionID, session); err != nil {
+		log.Errorf(ctx, "Failed to log ReserveItem activity: %v", err)
+	}
+
+	log.Infof(ctx, "Item %s reserved for buyer %s until %s", reservation.ItemID, reservation.BuyerID, reservation.ExpiresAt.Format(time.RFC3339))
+	return &ReserveItemOutput{
+		Reserved:  true,
+		ExpiresAt: reservation.ExpiresAt,
+	}, nil
+}
+
+// GetGenkitTool returns the Firebase Genkit tool definition for AI integration
+func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
+	return genkit.DefineTool(g, ToolName, ToolDescription,
+		func(toolCtx *ai.ToolContext, input ReserveItemArgs) (*ReserveItemOutput, error) {
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
+			if err != nil {
+				return nil, err
+			}
+
+			if output, ok := result.(*ReserveItemOutput); ok {
+				return output, nil
+			}
+			return nil, fmt.Errorf("unexpected result type: %T", result)
+		})
+}
+
+// holdDuration clamps the requested duration to the configured bounds
+func (t *tool) holdDuration(minutes int) time.Duration {
+	if minutes <= 0 {
+		return t.config.DefaultTTL
+	}
+	duration := time.Duration(minutes) * time.Minute
+	if duration > t.config.MaxTTL {
+		return t.config.MaxTTL
+	}
+	return duration
+}
+
+// parseArgs converts interface{} arguments to the expected type
+func (t *tool) parseArgs(args interface{}, target interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal args: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+	return nil
+}
+
+// notifySeller posts a tagged notice into the channel so the seller sees the active hold
+func (t *tool) notifySeller(ctx context.Context, session toolsmanager.SessionContext, reservation *models.Reservation, message string) error {
+	notice := fmt.Sprintf("[RESERVED until %s] %s", reservation.ExpiresAt.Format("15:04 02/01"), reservation.ItemName)
+	if message != "" {
+		notice = fmt.Sprintf("%s - %s", notice, message)
+	}
+
+	outgoingMessage := &models.OutgoingMessage{
+		ChannelID: session.GetChannelID(),
+		SenderID:  session.GetSenderID(),
+		Message:   notice,
+	}
+
+	return t.chatAPIClient.SendMessage(ctx, outgoingMessage)
+}
+
+// logActivity logs the tool execution activity
+func (t *tool) logActivity(ctx context.Context, args ReserveItemArgs, sessionID primitive.ObjectID, session toolsmanager.SessionContext) error {
+	activity := &models.ChatActivity{
+		SessionID: sessionID,
+		ChannelID: session.GetChannelID(),
+		Action:    models.ActivityReserveItem,
+		Data:      args,
+	}
+
+	return t.activityRepo.Create(ctx, activity)
+}
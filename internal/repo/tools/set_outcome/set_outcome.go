@@ -0,0 +1,118 @@
+package set_outcome
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ToolName        = "SetOutcome"
+	ToolDescription = "Record how the conversation ended: \"sold\" once the buyer confirmed the purchase, \"lost\" once they clearly declined or bought elsewhere. Do not call it while the buyer is still deciding."
+)
+
+// SetOutcomeArgs defines the arguments for the SetOutcome tool
+type SetOutcomeArgs struct {
+	Outcome string `json:"outcome" jsonschema:"enum=sold,enum=lost"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// Recorder stores session outcomes, implemented by usecase.OutcomeUsecase
+type Recorder interface {
+	Record(ctx context.Context, sessionID primitive.ObjectID, outcome models.SessionOutcome, source models.OutcomeSource, reason, orderID string) (*models.ChatSession, error)
+}
+
+type Tool interface {
+	toolsmanager.Tool
+}
+
+// tool implements the toolsmanager.Tool interface
+type tool struct {
+	recorder Recorder
+}
+
+// NewTool creates a new SetOutcome tool instance
+func NewTool(recorder Recorder) Tool {
+	return &tool{
+		recorder: recorder,
+	}
+}
+
+// Name returns the tool's unique identifier
+func (t *tool) Name() string {
+	return ToolName
+}
+
+// Description returns a human-readable description of what the tool does
+func (t *tool) Description() string {
+	return ToolDescription
+}
+
+// Execute runs the tool with the given arguments and session context
+func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanager.SessionContext) (interface{}, error) {
+	var outcomeArgs SetOutcomeArgs
+	if err := t.parseArgs(args, &outcomeArgs); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+
+	// the other outcomes come from the seller and the resolver, not the bot
+	outcome := models.SessionOutcome(outcomeArgs.Outcome)
+	if outcome != models.SessionOutcomeSold && outcome != models.SessionOutcomeLost {
+		return nil, fmt.Errorf("outcome must be sold or lost, got %q", outcomeArgs.Outcome)
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return nil, fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	_, err = t.recorder.Record(ctx, sessionID, outcome, models.OutcomeSourceTool, outcomeArgs.Reason, "")
+	if errors.Is(err, models.ErrOutcomeSet) {
+		return "The outcome of this conversation was already recorded", nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record outcome: %w", err)
+	}
+
+	log.Infow(ctx, "Session outcome recorded by the bot", "session_id", sessionID.Hex(), "outcome", outcome)
+	return fmt.Sprintf("Outcome recorded: %s", outcome), nil
+}
+
+// GetGenkitTool returns the Firebase Genkit tool definition for AI integration
+func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
+	return genkit.DefineTool(g, ToolName, ToolDescription,
+		func(toolCtx *ai.ToolContext, input SetOutcomeArgs) (string, error) {
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
+			if err != nil {
+				return "", err
+			}
+
+			if resultStr, ok := result.(string); ok {
+				return resultStr, nil
+			}
+			return "Outcome recorded", nil
+		})
+}
+
+// parseArgs converts interface{} arguments to the expected type
+func (t *tool) parseArgs(args interface{}, target interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal args: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+	return nil
+}
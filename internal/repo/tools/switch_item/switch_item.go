@@ -0,0 +1,145 @@
+package switch_item
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ToolName        = "SwitchItem"
+	ToolDescription = "Switch the conversation to another item already discussed in this chat, by its item_id. Set drop_previous when the buyer is no longer interested in the item discussed so far. Returns every item discussed in the chat."
+)
+
+// SwitchItemArgs defines the arguments for the SwitchItem tool
+type SwitchItemArgs struct {
+	ItemID       string `json:"item_id"`
+	DropPrevious bool   `json:"drop_previous,omitempty"`
+}
+
+// SwitchItemOutput defines the output of the SwitchItem tool
+type SwitchItemOutput struct {
+	Switched bool                 `json:"switched"`
+	Reason   string               `json:"reason,omitempty"`
+	Items    []models.ChannelItem `json:"items"`
+}
+
+type Tool interface {
+	toolsmanager.Tool
+}
+
+// tool implements the toolsmanager.Tool interface
+type tool struct {
+	activityRepo    mongodb.ChatActivityRepository
+	channelItemRepo mongodb.ChannelItemRepository
+}
+
+// NewTool creates a new SwitchItem tool instance
+func NewTool(
+	activityRepo mongodb.ChatActivityRepository,
+	channelItemRepo mongodb.ChannelItemRepository,
+) Tool {
+	return &tool{
+		activityRepo:    activityRepo,
+		channelItemRepo: channelItemRepo,
+	}
+}
+
+// Name returns the tool's unique identifier
+func (t *tool) Name() string {
+	return ToolName
+}
+
+// Description returns a human-readable description of what the tool does
+func (t *tool) Description() string {
+	return ToolDescription
+}
+
+// Execute runs the tool with the given arguments and session context
+func (t *tool) Execute(ctx context.Context, args interface{}, session toolsmanager.SessionContext) (interface{}, error) {
+	var switchArgs SwitchItemArgs
+	if err := t.parseArgs(args, &switchArgs); err != nil {
+		return nil, fmt.Errorf("failed to parse arguments: %w", err)
+	}
+	if switchArgs.ItemID == "" {
+		return nil, fmt.Errorf("item_id is required")
+	}
+
+	items, err := t.channelItemRepo.Seed(ctx, session.GetChannelID(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if !items.Switch(switchArgs.ItemID, switchArgs.DropPrevious) {
+		return &SwitchItemOutput{
+			Switched: false,
+			Reason:   "item is not discussed in this chat, add it with AddItem first",
+			Items:    items.Items,
+		}, nil
+	}
+	if err := t.channelItemRepo.SetItems(ctx, session.GetChannelID(), items.Items); err != nil {
+		return nil, fmt.Errorf("failed to switch item: %w", err)
+	}
+
+	if err := t.logActivity(ctx, switchArgs, session); err != nil {
+		log.Errorf(ctx, "Failed to log SwitchItem activity: %v", err)
+	}
+
+	log.Infow(ctx, "Channel switched item", "channel_id", session.GetChannelID(), "item_id", switchArgs.ItemID, "drop_previous", switchArgs.DropPrevious)
+	return &SwitchItemOutput{Switched: true, Items: items.Items}, nil
+}
+
+// GetGenkitTool returns the Firebase Genkit tool definition for AI integration
+func (t *tool) GetGenkitTool(session toolsmanager.SessionContext, g *genkit.Genkit) ai.Tool {
+	return genkit.DefineTool(g, ToolName, ToolDescription,
+		func(toolCtx *ai.ToolContext, input SwitchItemArgs) (*SwitchItemOutput, error) {
+			ctx, cancel := session.ToolContext(ToolName)
+			defer cancel()
+
+			result, err := t.Execute(ctx, input, session)
+			if err != nil {
+				return nil, err
+			}
+
+			if output, ok := result.(*SwitchItemOutput); ok {
+				return output, nil
+			}
+			return nil, fmt.Errorf("unexpected result type: %T", result)
+		})
+}
+
+// parseArgs converts interface{} arguments to the expected type
+func (t *tool) parseArgs(args interface{}, target interface{}) error {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to marshal args: %w", err)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("failed to unmarshal args: %w", err)
+	}
+	return nil
+}
+
+// logActivity logs the tool execution activity
+func (t *tool) logActivity(ctx context.Context, args SwitchItemArgs, session toolsmanager.SessionContext) error {
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return fmt.Errorf("invalid session ID: %w", err)
+	}
+
+	activity := &models.ChatActivity{
+		SessionID: sessionID,
+		ChannelID: session.GetChannelID(),
+		Action:    models.ActivitySwitchItem,
+		Data:      args,
+	}
+
+	return t.activityRepo.Create(ctx, activity)
+}
@@ -0,0 +1,32 @@
+package toolsmanager
+
+import (
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+)
+
+// dryToolResult answers dry calls of tools without a fixture
+var dryToolResult = map[string]any{"success": true, "dry_run": true}
+
+// dryTool defines on the session's Genkit a stand-in for tool, with its name,
+// description and input schema, that answers every call with the tool's
+// fixture instead of running it. The real tool is only defined on scratch,
+// to read its definition.
+func dryTool(s SessionContext, tool Tool, scratch *genkit.Genkit) ai.Tool {
+	defined := tool.GetGenkitTool(s, scratch)
+	if defined == nil {
+		return nil
+	}
+	def := defined.Definition()
+
+	result, ok := s.ToolFixture(tool.Name())
+	if !ok {
+		result = dryToolResult
+	}
+	return genkit.DefineToolWithInputSchema(s.Genkit(), def.Name, def.Description, def.InputSchema,
+		func(toolCtx *ai.ToolContext, input any) (any, error) {
+			log.Infow(s.Context(), "Dry tool call", "tool_name", def.Name, "session_id", s.GetSessionID())
+			return result, nil
+		})
+}
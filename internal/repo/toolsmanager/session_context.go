@@ -3,9 +3,12 @@ package toolsmanager
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -20,7 +23,13 @@ type sessionContext struct {
 	senderID             string
 	ended                bool
 	nextMessageTimestamp *int64
+	requireApproval      bool
 	sessionRepo          mongodb.ChatSessionRepository
+	timeouts             config.TimeoutConfig
+	// allowedTools is nil when every tool is allowed
+	allowedTools []string
+	// toolFixtures is non-nil in dry sessions
+	toolFixtures map[string]any
 }
 
 // SessionContextConfig holds configuration for creating a SessionContext
@@ -31,6 +40,14 @@ type SessionContextConfig struct {
 	UserID      string
 	SenderID    string
 	SessionRepo mongodb.ChatSessionRepository
+	Timeouts    config.TimeoutConfig
+	// RequireApproval holds replies for the seller's approval (safe mode)
+	RequireApproval bool
+	// AllowedTools is the tenant's tool allowlist for the seller, nil allows every tool
+	AllowedTools []string
+	// ToolFixtures are the results of the session's tools by name. Non-nil
+	// makes the session dry: tools return their fixture and have no effect.
+	ToolFixtures map[string]any
 }
 
 // NewSessionContext creates a new SessionContext instance
@@ -44,6 +61,11 @@ func NewSessionContext(ctx context.Context, config SessionContextConfig) Session
 		senderID:    config.SenderID,
 		ended:       false,
 		sessionRepo: config.SessionRepo,
+		timeouts:    config.Timeouts,
+
+		requireApproval: config.RequireApproval,
+		allowedTools:    config.AllowedTools,
+		toolFixtures:    config.ToolFixtures,
 	}
 }
 
@@ -51,6 +73,10 @@ func (s *sessionContext) Context() context.Context {
 	return s.ctx
 }
 
+func (s *sessionContext) ToolContext(toolName string) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(s.ctx, s.timeouts.ToolTimeout(toolName))
+}
+
 func (s *sessionContext) Genkit() *genkit.Genkit {
 	return s.genkit
 }
@@ -81,7 +107,7 @@ func (s *sessionContext) EndSession() error {
 		return nil // Already ended
 	}
 
-	if err := s.sessionRepo.EndSession(context.Background(), s.sessionID); err != nil {
+	if err := s.sessionRepo.EndSession(context.Background(), s.sessionID, models.SessionEndedByModel); err != nil {
 		return fmt.Errorf("failed to end session: %w", err)
 	}
 
@@ -95,6 +121,27 @@ func (s *sessionContext) IsEnded() bool {
 	return s.ended
 }
 
+// RequiresApproval returns whether replies are held for the seller's approval
+func (s *sessionContext) RequiresApproval() bool {
+	return s.requireApproval
+}
+
+// ToolAllowed reports whether the tool policy lets the session use the tool
+func (s *sessionContext) ToolAllowed(toolName string) bool {
+	return s.allowedTools == nil || slices.Contains(s.allowedTools, toolName)
+}
+
+// DryTools returns whether the session's tools return fixtures instead of running
+func (s *sessionContext) DryTools() bool {
+	return s.toolFixtures != nil
+}
+
+// ToolFixture returns the dry result of the named tool
+func (s *sessionContext) ToolFixture(toolName string) (any, bool) {
+	fixture, ok := s.toolFixtures[toolName]
+	return fixture, ok
+}
+
 // GetNextMessageTimestamp returns the next message timestamp
 func (s *sessionContext) GetNextMessageTimestamp() *int64 {
 	return s.nextMessageTimestamp
@@ -7,6 +7,7 @@ import (
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
 )
 
 // toolsManager is the concrete implementation of ToolsManager
@@ -80,6 +81,12 @@ func (tm *toolsManager) GetToolsForNames(s SessionContext, toolNames []string) (
 	tm.mutex.RLock()
 	defer tm.mutex.RUnlock()
 
+	// dry sessions only define the real tools here, to copy their schemas
+	var scratch *genkit.Genkit
+	if s.DryTools() {
+		scratch = genkit.Init(s.Context())
+	}
+
 	var genkitTools []ai.Tool
 	for _, toolName := range toolNames {
 		tool, exists := tm.tools[toolName]
@@ -87,8 +94,17 @@ func (tm *toolsManager) GetToolsForNames(s SessionContext, toolNames []string) (
 			log.Warnw(s.Context(), "Requested tool not found", "tool_name", toolName)
 			continue
 		}
+		if !s.ToolAllowed(toolName) {
+			log.Infow(s.Context(), "Tool not allowed by tool policy", "tool_name", toolName)
+			continue
+		}
 
-		genkitTool := tool.GetGenkitTool(s, s.Genkit())
+		var genkitTool ai.Tool
+		if scratch != nil {
+			genkitTool = dryTool(s, tool, scratch)
+		} else {
+			genkitTool = tool.GetGenkitTool(s, s.Genkit())
+		}
 		if genkitTool != nil {
 			genkitTools = append(genkitTools, genkitTool)
 		}
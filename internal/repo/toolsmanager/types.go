@@ -27,7 +27,9 @@ type ToolsManager interface {
 	ExecuteTool(ctx context.Context, toolName string, args interface{}, session SessionContext) (interface{}, error)
 	// GetAvailableTools returns a list of all registered tool names
 	GetAvailableTools() []string
-	// GetToolsForNames returns Genkit tools for the specified tool names
+	// GetToolsForNames returns Genkit tools for the specified tool names,
+	// leaving out those the session is not allowed to use. Dry sessions get
+	// stand-ins returning the tools' fixtures.
 	GetToolsForNames(session SessionContext, toolNames []string) ([]ai.Tool, error)
 	// HasTool checks if a tool with the given name is registered
 	HasTool(toolName string) bool
@@ -36,6 +38,9 @@ type ToolsManager interface {
 // SessionContext provides access to session-related data and operations
 type SessionContext interface {
 	Context() context.Context
+	// ToolContext derives the context of one call of the named tool, bounded
+	// by its configured timeout
+	ToolContext(toolName string) (context.Context, context.CancelFunc)
 	Genkit() *genkit.Genkit
 
 	// Session identification
@@ -47,6 +52,17 @@ type SessionContext interface {
 	// Session control
 	EndSession() error
 	IsEnded() bool
+	// RequiresApproval is true in safe mode, where replies wait for the
+	// seller's approval instead of being sent
+	RequiresApproval() bool
+	// ToolAllowed is false for tools the tenant's tool policy keeps from
+	// the session
+	ToolAllowed(toolName string) bool
+	// DryTools is true when the session's tools return fixtures instead of
+	// running, for prompt development
+	DryTools() bool
+	// ToolFixture is the result the named tool returns in a dry session
+	ToolFixture(toolName string) (any, bool)
 
 	// Message tracking
 	GetNextMessageTimestamp() *int64
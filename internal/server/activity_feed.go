@@ -0,0 +1,91 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Activity feed endpoints, for sellers catching up on what the bot did
+
+func (h *controller) ListActivityFeed(c echo.Context) error {
+	filter := models.FeedFilter{
+		SellerID:   c.Param("seller_id"),
+		UnseenOnly: c.QueryParam("unseen") == "true",
+	}
+	if filter.SellerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "seller_id is required")
+	}
+
+	if typesParam := c.QueryParam("types"); typesParam != "" {
+		for _, t := range strings.Split(typesParam, ",") {
+			switch eventType := models.FeedEventType(t); eventType {
+			case models.FeedPurchaseIntent, models.FeedOffer, models.FeedHandoff, models.FeedDeliveryFailed, models.FeedAssignmentChanged, models.FeedClaimChanged:
+				filter.Types = append(filter.Types, eventType)
+			default:
+				return echo.NewHTTPError(http.StatusBadRequest, "invalid type "+t)
+			}
+		}
+	}
+	if beforeParam := c.QueryParam("before"); beforeParam != "" {
+		before, err := primitive.ObjectIDFromHex(beforeParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid before")
+		}
+		filter.Before = &before
+	}
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 100 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 100")
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request().Context()
+	page, err := h.feedUsecase.List(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, page)
+}
+
+type MarkActivityFeedSeenRequest struct {
+	// IDs are the items to mark seen, none marks the whole feed seen
+	IDs []string `json:"ids" validate:"omitempty,max=100,dive,objectid"`
+}
+
+func (h *controller) MarkActivityFeedSeen(c echo.Context) error {
+	sellerID := c.Param("seller_id")
+	if sellerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "seller_id is required")
+	}
+
+	var req MarkActivityFeedSeenRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		objectID, _ := primitive.ObjectIDFromHex(id) // checked by the objectid validation
+		ids = append(ids, objectID)
+	}
+
+	ctx := c.Request().Context()
+	marked, err := h.feedUsecase.MarkSeen(ctx, sellerID, ids)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]int64{"marked": marked})
+}
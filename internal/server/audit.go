@@ -0,0 +1,68 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Audit log endpoints
+
+func (h *controller) ListAuditLogs(c echo.Context) error {
+	filter := models.AuditLogFilter{
+		ActorType:    models.ActorType(c.QueryParam("actor_type")),
+		ActorID:      c.QueryParam("actor_id"),
+		Action:       models.AuditAction(c.QueryParam("action")),
+		ResourceType: c.QueryParam("resource_type"),
+		ResourceID:   c.QueryParam("resource_id"),
+	}
+
+	if tenantParam := c.QueryParam("tenant_id"); tenantParam != "" {
+		tenantID, err := primitive.ObjectIDFromHex(tenantParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+		}
+		filter.TenantID = &tenantID
+	}
+
+	var err error
+	if filter.Since, err = parseTimeParam(c, "since"); err != nil {
+		return err
+	}
+	if filter.Until, err = parseTimeParam(c, "until"); err != nil {
+		return err
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 1000")
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request().Context()
+	entries, err := h.auditUsecase.List(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
+
+// parseTimeParam reads an optional RFC3339 query parameter
+func parseTimeParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, echo.NewHTTPError(http.StatusBadRequest, "invalid "+name+", expected RFC3339")
+	}
+	return t, nil
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Auth lockout endpoints, listing and lifting the lockouts of addresses and
+// accounts that failed to authenticate too often
+
+func (h *controller) ListAuthLockouts(c echo.Context) error {
+	ctx := c.Request().Context()
+	lockouts, err := h.authGuard.ListLocked(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, lockouts)
+}
+
+func (h *controller) UnlockAuthLockout(c echo.Context) error {
+	scope := models.AuthScope(c.Param("scope"))
+	if !scope.Valid() {
+		return echo.NewHTTPError(http.StatusBadRequest, "scope must be ip or account")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.authGuard.Unlock(ctx, scope, c.Param("key")); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "lockout not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func authGuardError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrAuthLocked):
+		return echo.NewHTTPError(http.StatusTooManyRequests, err.Error())
+	case errors.Is(err, models.ErrCaptchaRequired):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Avatar endpoints
+
+const maxAvatarUploadBytes = 5 << 20
+
+func (h *controller) UploadUserAvatar(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, maxAvatarUploadBytes)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			return echo.NewHTTPError(http.StatusRequestEntityTooLarge, "avatar must be at most 5MB")
+		}
+		return echo.NewHTTPError(http.StatusBadRequest, "file is required")
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "failed to read file")
+	}
+	defer file.Close()
+
+	ctx := c.Request().Context()
+	user, err := h.avatarUsecase.UploadAvatar(ctx, userID, file)
+	if err != nil {
+		return avatarError(err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *controller) SyncUserAvatar(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	user, err := h.avatarUsecase.SyncPartnerAvatar(ctx, userID)
+	if err != nil {
+		return avatarError(err)
+	}
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func avatarError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "user not found")
+	case errors.Is(err, models.ErrAvatarUnavailable):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrInvalidImage):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Backup endpoints
+
+func (h *controller) ExportTenant(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	ctx := c.Request().Context()
+	if _, err := h.tenantUsecase.GetTenant(ctx, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "application/gzip")
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", "tenant-"+id.Hex()+".jsonl.gz"))
+	res.WriteHeader(http.StatusOK)
+
+	// The status is already sent, a failure midway leaves an archive without
+	// its manifest which restore rejects
+	if _, err := h.backupUsecase.Export(ctx, id, res); err != nil {
+		log.Errorw(ctx, "Failed to export tenant", "tenant_id", id.Hex(), "error", err)
+	}
+	return nil
+}
+
+func (h *controller) RestoreTenant(c echo.Context) error {
+	ctx := c.Request().Context()
+	manifest, err := h.backupUsecase.Restore(ctx, c.Request().Body)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidBackup) {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, manifest)
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Budget endpoints, showing the counters the bot's caps are checked against
+
+func (h *controller) GetSession(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid session ID")
+	}
+
+	ctx := c.Request().Context()
+	session, err := h.budgetUsecase.GetSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "session not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, session)
+}
+
+func (h *controller) GetChannelBudget(c echo.Context) error {
+	ctx := c.Request().Context()
+	report, err := h.budgetUsecase.GetChannelBudget(ctx, c.Param("channel_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
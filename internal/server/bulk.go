@@ -0,0 +1,81 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Bulk endpoints, admin operations over many channels and sessions. They
+// queue a job and return it; its progress is read from the job endpoints.
+
+type AbandonInactiveRequest struct {
+	InactiveDays int `json:"inactive_days" validate:"required,min=1"`
+	// TenantID limits the operation to a tenant, all tenants when empty
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+func (h *controller) AbandonInactiveSessions(c echo.Context) error {
+	var req AbandonInactiveRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx, err := bulkTenantContext(c.Request().Context(), req.TenantID)
+	if err != nil {
+		return err
+	}
+	job, err := h.jobUsecase.Enqueue(ctx, models.JobAbandonInactiveSessions, models.AbandonInactiveJobParams{
+		InactiveDays: req.InactiveDays,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+type ReassignSellerChatModeRequest struct {
+	ChatMode string `json:"chat_mode" validate:"required"`
+}
+
+func (h *controller) ReassignSellerChatMode(c echo.Context) error {
+	var req ReassignSellerChatModeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	job, err := h.jobUsecase.Enqueue(ctx, models.JobReassignChatMode, models.ReassignChatModeJobParams{
+		SellerID: c.Param("seller_id"),
+		ChatMode: req.ChatMode,
+	})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
+
+// bulkTenantContext scopes ctx to tenantID when one is given
+func bulkTenantContext(ctx context.Context, tenantID string) (context.Context, error) {
+	if tenantID == "" {
+		return ctx, nil
+	}
+	id, err := primitive.ObjectIDFromHex(tenantID)
+	if err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+	}
+	return models.WithTenantID(ctx, id), nil
+}
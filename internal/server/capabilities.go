@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Capabilities endpoints
+
+// GetCapabilities returns the protocol versions, features and events the
+// server supports. It needs no credentials, so clients can check it first.
+func (h *controller) GetCapabilities(c echo.Context) error {
+	budget := h.conf.Budget
+	return c.JSON(http.StatusOK, &models.Capabilities{
+		ProtocolVersions: models.ProtocolVersions,
+		Features: map[string]bool{
+			"message_includes":   true,
+			"drafts":             true,
+			"reply_assist":       true,
+			"safe_mode":          true,
+			"structured_output":  true,
+			"session_outcomes":   true,
+			"multi_item_rooms":   true,
+			"bot_budgets":        budget.MaxRepliesPerDay > 0 || budget.MaxTokensPerSession > 0,
+			"context_compaction": h.conf.ChannelContext.MaxChars > 0,
+			"outcome_resolver":   h.conf.Outcome.Interval > 0,
+			"blocks":             len(h.conf.ChatAPI.BlockTypes) > 0,
+			"reactions":          false,
+			"threads":            false,
+			"streaming":          false,
+		},
+		EventTypes: []string{models.EventMessageSent},
+	})
+}
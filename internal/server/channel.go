@@ -0,0 +1,245 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+)
+
+// Channel endpoints
+
+func (h *controller) GetChannelParticipants(c echo.Context) error {
+	channelID := c.Param("channel_id")
+	if channelID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "channel_id is required")
+	}
+
+	ctx := c.Request().Context()
+	participants, err := h.channelUsecase.GetParticipants(ctx, channelID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, participants)
+}
+
+// GetChannelMessages lists channel history as seen by the user_id query param.
+// Pass include=sender to embed sender profiles and include=listings to add
+// product card blocks for linked chotot listings; both may be combined.
+func (h *controller) GetChannelMessages(c echo.Context) error {
+	req, includes, err := channelMessagesRequest(c, 20)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	history, err := h.channelUsecase.GetMessages(ctx, req, includes)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, history)
+}
+
+// ExportChannelMessages streams the whole history GetChannelMessages pages
+// through, newest first, flushing every page of limit messages. Clients
+// accepting text/event-stream get server-sent events, the others NDJSON.
+func (h *controller) ExportChannelMessages(c echo.Context) error {
+	req, includes, err := channelMessagesRequest(c, 100)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	stream := newMessageStream(c.Response(), strings.Contains(c.Request().Header.Get(echo.HeaderAccept), mimeEventStream))
+	err = h.channelUsecase.StreamMessages(ctx, req, includes, stream.write)
+	if err != nil && !stream.started {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	// The status is already sent, so a failure midway ends the stream with
+	// an error the client can tell from a complete export
+	if err != nil {
+		log.Errorw(ctx, "Failed to export channel messages", "channel_id", req.ChannelID, "error", err)
+		stream.fail(err)
+		return nil
+	}
+	stream.done()
+	return nil
+}
+
+// channelMessagesRequest reads the history query of the channel message
+// endpoints, defaulting to pages of defaultLimit messages
+func channelMessagesRequest(c echo.Context, defaultLimit int) (chatapi.MessageHistoryRequest, usecase.MessageIncludes, error) {
+	req := chatapi.MessageHistoryRequest{
+		ChannelID: c.Param("channel_id"),
+		UserID:    c.QueryParam("user_id"),
+		Limit:     defaultLimit,
+	}
+	var includes usecase.MessageIncludes
+	if req.UserID == "" {
+		return req, includes, echo.NewHTTPError(http.StatusBadRequest, "user_id is required")
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 100 {
+			return req, includes, echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 100")
+		}
+		req.Limit = limit
+	}
+
+	if beforeParam := c.QueryParam("before_ts"); beforeParam != "" {
+		beforeTs, err := strconv.ParseInt(beforeParam, 10, 64)
+		if err != nil {
+			return req, includes, echo.NewHTTPError(http.StatusBadRequest, "invalid before_ts")
+		}
+		req.BeforeTs = &beforeTs
+	}
+
+	for _, include := range strings.Split(c.QueryParam("include"), ",") {
+		switch strings.TrimSpace(include) {
+		case "sender":
+			includes.Sender = true
+		case "listings":
+			includes.Listings = true
+		}
+	}
+	return req, includes, nil
+}
+
+type SetChannelChatModeRequest struct {
+	ChatMode string `json:"chat_mode" validate:"required"`
+}
+
+func (h *controller) SetChannelChatMode(c echo.Context) error {
+	var req SetChannelChatModeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	override, err := h.channelUsecase.SetChatMode(ctx, c.Param("channel_id"), req.ChatMode)
+	if err != nil {
+		return channelChatModeError(err)
+	}
+
+	return c.JSON(http.StatusOK, override)
+}
+
+func (h *controller) GetChannelChatMode(c echo.Context) error {
+	ctx := c.Request().Context()
+	override, err := h.channelUsecase.GetChatMode(ctx, c.Param("channel_id"))
+	if err != nil {
+		return channelChatModeError(err)
+	}
+
+	return c.JSON(http.StatusOK, override)
+}
+
+func (h *controller) ClearChannelChatMode(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.channelUsecase.ClearChatMode(ctx, c.Param("channel_id")); err != nil {
+		return channelChatModeError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func channelChatModeError(err error) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
+
+const (
+	mimeEventStream = "text/event-stream"
+	mimeNDJSON      = "application/x-ndjson"
+)
+
+// messageStream writes exported messages as NDJSON lines or server-sent
+// events, sending the status with the first page so an export failing
+// before it still gets an error status
+type messageStream struct {
+	res     *echo.Response
+	sse     bool
+	started bool
+	count   int
+}
+
+func newMessageStream(res *echo.Response, sse bool) *messageStream {
+	return &messageStream{res: res, sse: sse}
+}
+
+func (s *messageStream) start() {
+	contentType := mimeNDJSON
+	if s.sse {
+		contentType = mimeEventStream
+		s.res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	}
+	s.res.Header().Set(echo.HeaderContentType, contentType)
+	s.res.WriteHeader(http.StatusOK)
+	s.started = true
+}
+
+// write sends a page of messages and flushes it
+func (s *messageStream) write(messages []models.HistoryMessage) error {
+	if !s.started {
+		s.start()
+	}
+	for _, message := range messages {
+		if err := s.event("message", message); err != nil {
+			return err
+		}
+	}
+	s.count += len(messages)
+	s.res.Flush()
+	return nil
+}
+
+// event writes data as one NDJSON line, or as one server-sent event named name
+func (s *messageStream) event(name string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", name, err)
+	}
+	if s.sse {
+		_, err = fmt.Fprintf(s.res, "event: %s\ndata: %s\n\n", name, payload)
+	} else {
+		_, err = fmt.Fprintf(s.res, "%s\n", payload)
+	}
+	return err
+}
+
+// done ends a complete export. Server-sent events end with a done event
+// counting the messages, NDJSON with the last message.
+func (s *messageStream) done() {
+	if !s.started {
+		s.start()
+	}
+	if s.sse {
+		_ = s.event("done", map[string]int{"count": s.count})
+	}
+	s.res.Flush()
+}
+
+// fail ends an export cut short by err with an error event, or an NDJSON line
+// holding only the error
+func (s *messageStream) fail(err error) {
+	_ = s.event("error", map[string]string{"error": err.Error()})
+	s.res.Flush()
+}
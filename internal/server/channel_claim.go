@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Channel claim endpoints, called by team agents while they type in a room
+
+type ClaimChannelRequest struct {
+	// Override takes the room from the agent currently handling it
+	Override bool `json:"override"`
+}
+
+func (h *controller) ClaimChannel(c echo.Context) error {
+	var req ClaimChannelRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	claim, err := h.claimUsecase.Claim(ctx, c.Param("channel_id"), req.Override)
+	if errors.Is(err, models.ErrChannelClaimed) && claim != nil {
+		// tells the agent who is handling the room
+		return c.JSON(http.StatusConflict, claim)
+	}
+	if err != nil {
+		return channelClaimError(err)
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+func (h *controller) GetChannelClaim(c echo.Context) error {
+	ctx := c.Request().Context()
+	claim, err := h.claimUsecase.Get(ctx, c.Param("channel_id"))
+	if err != nil {
+		return channelClaimError(err)
+	}
+	if claim == nil {
+		return echo.NewHTTPError(http.StatusNotFound, "channel is not claimed")
+	}
+
+	return c.JSON(http.StatusOK, claim)
+}
+
+func (h *controller) ReleaseChannelClaim(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.claimUsecase.Release(ctx, c.Param("channel_id")); err != nil {
+		return channelClaimError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func channelClaimError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "channel or claim not found")
+	case errors.Is(err, models.ErrChannelClaimed):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrNotTeamMember):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Chat mode pack endpoints
+
+type SelectVerticalRequest struct {
+	Vertical string `json:"vertical" validate:"required"`
+}
+
+func (h *controller) ListChatModePacks(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.chatModePackUsecase.ListPacks())
+}
+
+func (h *controller) SelectVertical(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req SelectVerticalRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	pack, err := h.chatModePackUsecase.SelectVertical(ctx, userID, req.Vertical)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, pack)
+}
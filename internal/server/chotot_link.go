@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Chotot link endpoints
+
+type StartChototLinkRequest struct {
+	ChototID  string `json:"chotot_id" validate:"required"`
+	ChototOID string `json:"chotot_oid" validate:"required"`
+}
+
+func (h *controller) StartChototLink(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req StartChototLinkRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	link, err := h.chototLinkUsecase.StartLink(ctx, userID, req.ChototID, req.ChototOID)
+	if err != nil {
+		return chototLinkError(err)
+	}
+
+	return c.JSON(http.StatusCreated, link)
+}
+
+func (h *controller) GetChototLink(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	link, err := h.chototLinkUsecase.GetLink(ctx, userID)
+	if err != nil {
+		return chototLinkError(err)
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+func (h *controller) RevokeChototLink(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	link, err := h.chototLinkUsecase.RevokeLink(ctx, userID)
+	if err != nil {
+		return chototLinkError(err)
+	}
+
+	return c.JSON(http.StatusOK, link)
+}
+
+func chototLinkError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChototLinkUnverified):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrEmailUnverified):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	case errors.Is(err, models.ErrChototLinkActive), errors.Is(err, models.ErrAttributeConflict):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Config endpoints
+
+// GetTimeoutConfig returns the effective timeouts, as duration strings, after
+// env overrides and defaults have been applied
+func (h *controller) GetTimeoutConfig(c echo.Context) error {
+	timeouts := h.conf.Timeouts
+	toolOverrides := make(map[string]string, len(timeouts.ToolOverrides))
+	for name, d := range timeouts.ToolOverrides {
+		toolOverrides[name] = d.String()
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"message_processing": timeouts.MessageProcessing.String(),
+		"llm_generate":       timeouts.LLMGenerate.String(),
+		"tool":               timeouts.Tool.String(),
+		"tool_overrides":     toolOverrides,
+		"startup":            timeouts.Startup.String(),
+		"database": map[string]string{
+			"operation":        h.conf.Database.Timeout.String(),
+			"connect":          h.conf.Database.ConnectTimeout.String(),
+			"server_selection": h.conf.Database.ServerSelectionTimeout.String(),
+		},
+		"partner_http": map[string]string{
+			"request":          h.conf.PartnerHTTP.Timeout.String(),
+			"breaker_cooldown": h.conf.PartnerHTTP.BreakerCooldown.String(),
+		},
+	})
+}
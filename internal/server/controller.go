@@ -1,11 +1,17 @@
 package server
 
 import (
+	"errors"
+	"maps"
 	"net/http"
+	"slices"
+	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -24,17 +30,325 @@ type Controller interface {
 	GetUserAttributes(c echo.Context) error
 	GetUserAttributeByKey(c echo.Context) error
 	RemoveUserAttribute(c echo.Context) error
+
+	// Avatar endpoints
+	UploadUserAvatar(c echo.Context) error
+	SyncUserAvatar(c echo.Context) error
+
+	// Chotot link endpoints
+	StartChototLink(c echo.Context) error
+	GetChototLink(c echo.Context) error
+	RevokeChototLink(c echo.Context) error
+
+	// Onboarding endpoints
+	GetOnboarding(c echo.Context) error
+	LinkChotot(c echo.Context) error
+	SelectOnboardingChatMode(c echo.Context) error
+	SetOnboardingWorkingHours(c echo.Context) error
+	RunOnboardingTestConversation(c echo.Context) error
+
+	// Reservation endpoints
+	ListSellerReservations(c echo.Context) error
+	ReleaseReservation(c echo.Context) error
+
+	// Tenant admin endpoints
+	CreateTenant(c echo.Context) error
+	GetTenant(c echo.Context) error
+	UpdateTenantSettings(c echo.Context) error
+	GetToolPolicy(c echo.Context) error
+	UpdateToolPolicy(c echo.Context) error
+	CreateAPIKey(c echo.Context) error
+	RevokeAPIKey(c echo.Context) error
+
+	// LLM key endpoints
+	SetLLMKey(c echo.Context) error
+	ValidateLLMKey(c echo.Context) error
+	DeleteLLMKey(c echo.Context) error
+
+	// Audit log endpoints
+	ListAuditLogs(c echo.Context) error
+
+	// Channel endpoints
+	GetChannelParticipants(c echo.Context) error
+	GetChannelMessages(c echo.Context) error
+	ExportChannelMessages(c echo.Context) error
+	SetChannelChatMode(c echo.Context) error
+	GetChannelChatMode(c echo.Context) error
+	ClearChannelChatMode(c echo.Context) error
+
+	// Draft endpoints
+	SaveDraft(c echo.Context) error
+	GetDraft(c echo.Context) error
+	DeleteDraft(c echo.Context) error
+	ListDrafts(c echo.Context) error
+
+	// Session transcript endpoints
+	GetSessionTranscript(c echo.Context) error
+
+	// Session trace endpoints
+	ListSessionTraces(c echo.Context) error
+
+	// Email verification endpoints
+	SendEmailVerification(c echo.Context) error
+	ConfirmEmailVerification(c echo.Context) error
+	ConfirmEmailVerificationLink(c echo.Context) error
+
+	// Auth lockout endpoints
+	ListAuthLockouts(c echo.Context) error
+	UnlockAuthLockout(c echo.Context) error
+
+	// Whitelist endpoints
+	GetWhitelist(c echo.Context) error
+	AddWhitelistEntry(c echo.Context) error
+	RemoveWhitelistEntry(c echo.Context) error
+	SetWhitelistRollout(c echo.Context) error
+
+	// Prompt log endpoints
+	ListPromptLogs(c echo.Context) error
+
+	// Replay endpoints
+	RunReplay(c echo.Context) error
+
+	// Simulator endpoints
+	SimulateChatMode(c echo.Context) error
+
+	// Test message endpoints
+	ListTestMessages(c echo.Context) error
+
+	// Reconcile endpoints
+	RunReconcile(c echo.Context) error
+
+	// Chat mode pack endpoints
+	ListChatModePacks(c echo.Context) error
+	SelectVertical(c echo.Context) error
+
+	// Backup endpoints
+	ExportTenant(c echo.Context) error
+	RestoreTenant(c echo.Context) error
+
+	// Persona endpoints
+	SetPersona(c echo.Context) error
+	GetPersona(c echo.Context) error
+	DeletePersona(c echo.Context) error
+
+	// Reply suggestion endpoints
+	ListSellerSuggestions(c echo.Context) error
+	ApproveSuggestion(c echo.Context) error
+	RejectSuggestion(c echo.Context) error
+
+	// Reply assist endpoints
+	SuggestReplies(c echo.Context) error
+
+	// Sentiment endpoints
+	GetChannelSentiment(c echo.Context) error
+	ResumeChannel(c echo.Context) error
+	ListSentimentAlerts(c echo.Context) error
+
+	// Scam flag endpoints
+	ListScamFlags(c echo.Context) error
+	ReviewScamFlag(c echo.Context) error
+
+	// Budget endpoints
+	GetSession(c echo.Context) error
+	GetChannelBudget(c echo.Context) error
+
+	// Outcome endpoints
+	SetChannelOutcome(c echo.Context) error
+	GetConversionReport(c echo.Context) error
+
+	// Bulk endpoints
+	AbandonInactiveSessions(c echo.Context) error
+	ReassignSellerChatMode(c echo.Context) error
+
+	// Job endpoints
+	ListJobs(c echo.Context) error
+	GetJob(c echo.Context) error
+	CancelJob(c echo.Context) error
+
+	// Config endpoints
+	GetTimeoutConfig(c echo.Context) error
+
+	// Capabilities endpoints
+	GetCapabilities(c echo.Context) error
+
+	// Stats endpoints
+	GetLiveStats(c echo.Context) error
+
+	// Activity feed endpoints
+	ListActivityFeed(c echo.Context) error
+	MarkActivityFeedSeen(c echo.Context) error
+
+	// Team endpoints
+	CreateTeam(c echo.Context) error
+	GetTeam(c echo.Context) error
+	AddTeamMember(c echo.Context) error
+	RemoveTeamMember(c echo.Context) error
+	AssignChannel(c echo.Context) error
+	UnassignChannel(c echo.Context) error
+	ListChannelAssignments(c echo.Context) error
+
+	// Channel claim endpoints
+	ClaimChannel(c echo.Context) error
+	GetChannelClaim(c echo.Context) error
+	ReleaseChannelClaim(c echo.Context) error
+
+	// Message template endpoints
+	SetMessageTemplate(c echo.Context) error
+	ListMessageTemplates(c echo.Context) error
+	DeleteMessageTemplate(c echo.Context) error
+
+	// System message endpoints
+	ListSystemMessages(c echo.Context) error
+	SetSystemMessage(c echo.Context) error
+	DeleteSystemMessage(c echo.Context) error
+
+	// Message block endpoints
+	PreviewMessageBlocks(c echo.Context) error
+
+	// Cost endpoints
+	GetCostReport(c echo.Context) error
+
+	// Dead letter endpoints
+	ListDeadLetters(c echo.Context) error
+	RequeueDeadLetter(c echo.Context) error
+	RequeueDeadLetters(c echo.Context) error
+
+	// Channel privacy endpoints
+	GetChannelPrivacy(c echo.Context) error
+	SetChannelPrivacy(c echo.Context) error
+	MarkMessagePrivate(c echo.Context) error
+	UnmarkMessagePrivate(c echo.Context) error
 }
 
 type controller struct {
-	messageUsecase usecase.MessageUsecase
-	userUsecase    usecase.UserUsecase
+	messageUsecase      usecase.MessageUsecase
+	userUsecase         usecase.UserUsecase
+	reservationUsecase  usecase.ReservationUsecase
+	tenantUsecase       usecase.TenantUsecase
+	llmKeyUsecase       usecase.LLMKeyUsecase
+	auditUsecase        usecase.AuditUsecase
+	channelUsecase      usecase.ChannelUsecase
+	avatarUsecase       usecase.AvatarUsecase
+	draftUsecase        usecase.DraftUsecase
+	transcriptUsecase   usecase.TranscriptUsecase
+	promptLogUsecase    usecase.PromptLogUsecase
+	replayUsecase       usecase.ReplayUsecase
+	onboardingUsecase   usecase.OnboardingUsecase
+	chototLinkUsecase   usecase.ChototLinkUsecase
+	reconcileUsecase    usecase.ReconcileUsecase
+	backupUsecase       usecase.BackupUsecase
+	chatModePackUsecase usecase.ChatModePackUsecase
+	personaUsecase      usecase.PersonaUsecase
+	suggestionUsecase   usecase.SuggestionUsecase
+	replyAssistUsecase  usecase.ReplyAssistUsecase
+	sentimentUsecase    usecase.SentimentUsecase
+	scamUsecase         usecase.ScamUsecase
+	budgetUsecase       usecase.BudgetUsecase
+	outcomeUsecase      usecase.OutcomeUsecase
+	jobUsecase          usecase.JobUsecase
+	simulatorUsecase    usecase.SimulatorUsecase
+	testMessageUsecase  usecase.TestMessageUsecase
+	feedUsecase         usecase.ActivityFeedUsecase
+	teamUsecase         usecase.TeamUsecase
+	claimUsecase        usecase.ChannelClaimUsecase
+	templateUsecase     usecase.TemplateUsecase
+	systemMessages      usecase.SystemMessageUsecase
+	traceUsecase        usecase.SessionTraceUsecase
+	whitelistService    usecase.WhitelistService
+	emailVerifier       usecase.EmailVerificationUsecase
+	authGuard           usecase.AuthGuardUsecase
+	costUsecase         usecase.CostUsecase
+	chatModeChecker     usecase.ChatModeChecker
+	deadLetterUsecase   usecase.DeadLetterUsecase
+	privacyUsecase      usecase.PrivacyUsecase
+	conf                *config.Config
 }
 
-func NewHandler(messageUsecase usecase.MessageUsecase, userUsecase usecase.UserUsecase) Controller {
+func NewHandler(
+	messageUsecase usecase.MessageUsecase,
+	userUsecase usecase.UserUsecase,
+	reservationUsecase usecase.ReservationUsecase,
+	tenantUsecase usecase.TenantUsecase,
+	llmKeyUsecase usecase.LLMKeyUsecase,
+	auditUsecase usecase.AuditUsecase,
+	channelUsecase usecase.ChannelUsecase,
+	avatarUsecase usecase.AvatarUsecase,
+	draftUsecase usecase.DraftUsecase,
+	transcriptUsecase usecase.TranscriptUsecase,
+	promptLogUsecase usecase.PromptLogUsecase,
+	replayUsecase usecase.ReplayUsecase,
+	onboardingUsecase usecase.OnboardingUsecase,
+	chototLinkUsecase usecase.ChototLinkUsecase,
+	reconcileUsecase usecase.ReconcileUsecase,
+	backupUsecase usecase.BackupUsecase,
+	chatModePackUsecase usecase.ChatModePackUsecase,
+	personaUsecase usecase.PersonaUsecase,
+	suggestionUsecase usecase.SuggestionUsecase,
+	replyAssistUsecase usecase.ReplyAssistUsecase,
+	sentimentUsecase usecase.SentimentUsecase,
+	scamUsecase usecase.ScamUsecase,
+	budgetUsecase usecase.BudgetUsecase,
+	outcomeUsecase usecase.OutcomeUsecase,
+	jobUsecase usecase.JobUsecase,
+	simulatorUsecase usecase.SimulatorUsecase,
+	testMessageUsecase usecase.TestMessageUsecase,
+	feedUsecase usecase.ActivityFeedUsecase,
+	teamUsecase usecase.TeamUsecase,
+	claimUsecase usecase.ChannelClaimUsecase,
+	templateUsecase usecase.TemplateUsecase,
+	systemMessages usecase.SystemMessageUsecase,
+	traceUsecase usecase.SessionTraceUsecase,
+	whitelistService usecase.WhitelistService,
+	emailVerifier usecase.EmailVerificationUsecase,
+	authGuard usecase.AuthGuardUsecase,
+	costUsecase usecase.CostUsecase,
+	chatModeChecker usecase.ChatModeChecker,
+	deadLetterUsecase usecase.DeadLetterUsecase,
+	privacyUsecase usecase.PrivacyUsecase,
+	conf *config.Config,
+) Controller {
 	return &controller{
-		messageUsecase: messageUsecase,
-		userUsecase:    userUsecase,
+		messageUsecase:      messageUsecase,
+		userUsecase:         userUsecase,
+		reservationUsecase:  reservationUsecase,
+		tenantUsecase:       tenantUsecase,
+		llmKeyUsecase:       llmKeyUsecase,
+		auditUsecase:        auditUsecase,
+		channelUsecase:      channelUsecase,
+		avatarUsecase:       avatarUsecase,
+		draftUsecase:        draftUsecase,
+		transcriptUsecase:   transcriptUsecase,
+		promptLogUsecase:    promptLogUsecase,
+		replayUsecase:       replayUsecase,
+		onboardingUsecase:   onboardingUsecase,
+		chototLinkUsecase:   chototLinkUsecase,
+		reconcileUsecase:    reconcileUsecase,
+		backupUsecase:       backupUsecase,
+		chatModePackUsecase: chatModePackUsecase,
+		personaUsecase:      personaUsecase,
+		suggestionUsecase:   suggestionUsecase,
+		replyAssistUsecase:  replyAssistUsecase,
+		sentimentUsecase:    sentimentUsecase,
+		scamUsecase:         scamUsecase,
+		budgetUsecase:       budgetUsecase,
+		outcomeUsecase:      outcomeUsecase,
+		jobUsecase:          jobUsecase,
+		simulatorUsecase:    simulatorUsecase,
+		testMessageUsecase:  testMessageUsecase,
+		feedUsecase:         feedUsecase,
+		teamUsecase:         teamUsecase,
+		claimUsecase:        claimUsecase,
+		templateUsecase:     templateUsecase,
+		systemMessages:      systemMessages,
+		traceUsecase:        traceUsecase,
+		whitelistService:    whitelistService,
+		emailVerifier:       emailVerifier,
+		authGuard:           authGuard,
+		costUsecase:         costUsecase,
+		chatModeChecker:     chatModeChecker,
+		deadLetterUsecase:   deadLetterUsecase,
+		privacyUsecase:      privacyUsecase,
+		conf:                conf,
 	}
 }
 
@@ -51,6 +365,7 @@ func (h *controller) ProcessMessage(c echo.Context) error {
 	if message.Metadata.LLM.ChatMode == "" {
 		return echo.NewHTTPError(http.StatusBadRequest, "missing chat_mode in metadata.llm")
 	}
+	livestats.Inc(models.StatMessagesPrefix + "http")
 
 	ctx := c.Request().Context()
 	if err := h.messageUsecase.ProcessMessage(ctx, message); err != nil {
@@ -64,10 +379,31 @@ func (h *controller) ProcessMessage(c echo.Context) error {
 }
 
 func (h *controller) Health(c echo.Context) error {
-	return c.JSON(http.StatusOK, map[string]string{
-		"status":  "healthy",
-		"service": "chat-bot",
-	})
+	// an LLM outage in the live stats window degrades the service, which keeps
+	// answering with the fallback model or acknowledgments
+	status, llm := "healthy", "ok"
+	for name, stat := range livestats.Snapshot() {
+		if strings.HasPrefix(name, models.StatLLMOutagesPrefix) && stat.Count > 0 {
+			status, llm = "degraded", "degraded"
+			break
+		}
+	}
+
+	response := map[string]any{
+		"status":     status,
+		"service":    "chat-bot",
+		"llm":        llm,
+		"chat_modes": "ok",
+	}
+	// chat modes that failed the startup check keep failing at message time;
+	// their problems are in the self-check report, health only names them
+	if unhealthy := h.chatModeChecker.Unhealthy(); len(unhealthy) > 0 {
+		response["status"] = "degraded"
+		response["chat_modes"] = "degraded"
+		response["unhealthy_chat_modes"] = slices.Sorted(maps.Keys(unhealthy))
+	}
+
+	return c.JSON(http.StatusOK, response)
 }
 
 // User management endpoints
@@ -191,8 +527,15 @@ func (h *controller) SetUserAttribute(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 	}
 
+	if slices.Contains(models.VerifiedAttributeKeys, req.Key) {
+		return echo.NewHTTPError(http.StatusForbidden, models.ErrProtectedAttribute.Error())
+	}
+
 	ctx := c.Request().Context()
 	if err := h.userUsecase.SetUserAttribute(ctx, userID, req.Key, req.Value, req.Tags); err != nil {
+		if errors.Is(err, models.ErrAttributeConflict) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
 	}
 
@@ -251,6 +594,10 @@ func (h *controller) RemoveUserAttribute(c echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, "key is required")
 	}
 
+	if slices.Contains(models.VerifiedAttributeKeys, key) {
+		return echo.NewHTTPError(http.StatusForbidden, models.ErrProtectedAttribute.Error())
+	}
+
 	ctx := c.Request().Context()
 	if err := h.userUsecase.RemoveUserAttribute(ctx, userID, key); err != nil {
 		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
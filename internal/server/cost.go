@@ -0,0 +1,53 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Cost endpoints
+
+// GetCostReport breaks a month's estimated LLM costs down by tenant, seller,
+// chat mode or model. The month defaults to the current one and the
+// breakdown to tenants.
+func (h *controller) GetCostReport(c echo.Context) error {
+	month := c.QueryParam("month")
+	if month == "" {
+		month = models.CostMonth(time.Now())
+	}
+	groupBy := models.CostGroupBy(c.QueryParam("group_by"))
+	if groupBy == "" {
+		groupBy = models.CostGroupByTenant
+	}
+
+	var tenantID *primitive.ObjectID
+	if tenantParam := c.QueryParam("tenant_id"); tenantParam != "" {
+		id, err := primitive.ObjectIDFromHex(tenantParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+		}
+		tenantID = &id
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.costUsecase.Report(ctx, month, tenantID, groupBy)
+	if err != nil {
+		return costError(err)
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func costError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidCostGroupBy), errors.Is(err, models.ErrInvalidCostMonth):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
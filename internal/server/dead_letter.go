@@ -0,0 +1,70 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dead letter endpoints, the chat events that failed every processing attempt
+
+func (h *controller) ListDeadLetters(c echo.Context) error {
+	filter := models.DeadLetterFilter{
+		Status:    models.DeadLetterStatus(c.QueryParam("status")),
+		ChannelID: c.QueryParam("channel_id"),
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 1000")
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request().Context()
+	letters, err := h.deadLetterUsecase.List(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, letters)
+}
+
+// RequeueDeadLetter processes a pending dead letter again and returns it,
+// resolved or pending with the new error
+func (h *controller) RequeueDeadLetter(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid dead letter ID")
+	}
+
+	ctx := c.Request().Context()
+	letter, err := h.deadLetterUsecase.Requeue(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "dead letter not found")
+		case errors.Is(err, models.ErrDeadLetterNotPending):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, letter)
+}
+
+// RequeueDeadLetters queues a job requeuing every pending dead letter
+func (h *controller) RequeueDeadLetters(c echo.Context) error {
+	ctx := c.Request().Context()
+	job, err := h.jobUsecase.Enqueue(ctx, models.JobRequeueDeadLetters, nil)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusAccepted, job)
+}
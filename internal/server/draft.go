@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Draft endpoints, keyed by channel and chat-api user
+
+type SaveDraftRequest struct {
+	UserID  string `json:"user_id" validate:"required"`
+	Message string `json:"message"`
+}
+
+func (h *controller) SaveDraft(c echo.Context) error {
+	var req SaveDraftRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	draft, err := h.draftUsecase.SaveDraft(ctx, c.Param("channel_id"), req.UserID, req.Message)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+	if draft == nil {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+func (h *controller) GetDraft(c echo.Context) error {
+	userID := c.QueryParam("user_id")
+	if userID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "user_id is required")
+	}
+
+	ctx := c.Request().Context()
+	draft, err := h.draftUsecase.GetDraft(ctx, c.Param("channel_id"), userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "draft not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, draft)
+}
+
+func (h *controller) DeleteDraft(c echo.Context) error {
+	userID := c.QueryParam("user_id")
+	if userID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "user_id is required")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.draftUsecase.DeleteDraft(ctx, c.Param("channel_id"), userID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "draft not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "draft deleted successfully",
+	})
+}
+
+// ListDrafts returns all drafts of the user_id query param across channels,
+// so a client can restore unfinished messages when it starts
+func (h *controller) ListDrafts(c echo.Context) error {
+	userID := c.QueryParam("user_id")
+	if userID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "user_id is required")
+	}
+
+	ctx := c.Request().Context()
+	drafts, err := h.draftUsecase.ListDrafts(ctx, userID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, drafts)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Email verification endpoints
+
+type ConfirmEmailVerificationRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+func (h *controller) SendEmailVerification(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	verification, err := h.emailVerifier.Send(ctx, userID)
+	if err != nil {
+		return emailVerificationError(err)
+	}
+
+	return c.JSON(http.StatusCreated, verification)
+}
+
+func (h *controller) ConfirmEmailVerification(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req ConfirmEmailVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	account := userID.Hex()
+	if err := h.authGuard.Check(ctx, models.AuthScopeAccount, account); err != nil {
+		return authGuardError(err)
+	}
+	if err := h.authGuard.Check(ctx, models.AuthScopeIP, c.RealIP()); err != nil {
+		return authGuardError(err)
+	}
+	if err := h.authGuard.RequireCaptcha(ctx, models.AuthScopeAccount, account, c.Request().Header.Get(headerCaptchaToken)); err != nil {
+		return authGuardError(err)
+	}
+
+	user, err := h.emailVerifier.Confirm(ctx, userID, req.Code)
+	if err != nil {
+		if errors.Is(err, models.ErrEmailVerificationInvalid) {
+			h.authGuard.Fail(ctx, models.AuthScopeAccount, account)
+			h.authGuard.Fail(ctx, models.AuthScopeIP, c.RealIP())
+		}
+		return emailVerificationError(err)
+	}
+	h.authGuard.Reset(ctx, models.AuthScopeAccount, account)
+
+	return c.JSON(http.StatusOK, user)
+}
+
+func (h *controller) ConfirmEmailVerificationLink(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.authGuard.Check(ctx, models.AuthScopeIP, c.RealIP()); err != nil {
+		return authGuardError(err)
+	}
+	if _, err := h.emailVerifier.ConfirmLink(ctx, c.Param("token")); err != nil {
+		if errors.Is(err, models.ErrEmailVerificationInvalid) {
+			h.authGuard.Fail(ctx, models.AuthScopeIP, c.RealIP())
+		}
+		return emailVerificationError(err)
+	}
+
+	return c.String(http.StatusOK, "Your email is verified. You can close this page.")
+}
+
+func emailVerificationError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrEmailVerificationInvalid):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
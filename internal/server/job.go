@@ -0,0 +1,83 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job endpoints, the admin view of background jobs
+
+func (h *controller) ListJobs(c echo.Context) error {
+	filter := models.JobFilter{
+		Type:   models.JobType(c.QueryParam("type")),
+		Status: models.JobStatus(c.QueryParam("status")),
+	}
+
+	if tenantParam := c.QueryParam("tenant_id"); tenantParam != "" {
+		tenantID, err := primitive.ObjectIDFromHex(tenantParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+		}
+		filter.TenantID = &tenantID
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 1000")
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request().Context()
+	jobs, err := h.jobUsecase.List(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, jobs)
+}
+
+func (h *controller) GetJob(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid job ID")
+	}
+
+	ctx := c.Request().Context()
+	job, err := h.jobUsecase.Get(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "job not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
+
+func (h *controller) CancelJob(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid job ID")
+	}
+
+	ctx := c.Request().Context()
+	job, err := h.jobUsecase.Cancel(ctx, id)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "job not found")
+		case errors.Is(err, models.ErrJobFinished):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// LLM key endpoints, scoped to the caller's tenant. Admins manage the keys of
+// sellers outside of tenants through the same handlers.
+
+type SetLLMKeyRequest struct {
+	APIKey   string `json:"api_key" validate:"required"`
+	SellerID string `json:"seller_id"`
+}
+
+func (h *controller) SetLLMKey(c echo.Context) error {
+	provider := models.LLMProvider(c.Param("provider"))
+
+	var req SetLLMKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	key, err := h.llmKeyUsecase.SetKey(ctx, provider, req.SellerID, req.APIKey)
+	if err != nil {
+		return llmKeyError(err)
+	}
+
+	return c.JSON(http.StatusOK, key)
+}
+
+type ValidateLLMKeyRequest struct {
+	APIKey string `json:"api_key" validate:"required"`
+}
+
+func (h *controller) ValidateLLMKey(c echo.Context) error {
+	provider := models.LLMProvider(c.Param("provider"))
+
+	var req ValidateLLMKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	if err := h.llmKeyUsecase.ValidateKey(ctx, provider, req.APIKey); err != nil {
+		return llmKeyError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "llm key is valid",
+	})
+}
+
+func (h *controller) DeleteLLMKey(c echo.Context) error {
+	provider := models.LLMProvider(c.Param("provider"))
+
+	ctx := c.Request().Context()
+	if err := h.llmKeyUsecase.DeleteKey(ctx, provider, c.QueryParam("seller_id")); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "llm key not found")
+		}
+		return llmKeyError(err)
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "llm key deleted successfully",
+	})
+}
+
+func llmKeyError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrInvalidLLMKey), errors.Is(err, models.ErrUnsupportedLLMProvider), errors.Is(err, models.ErrGlobalLLMKey):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrTenantRequired):
+		return echo.NewHTTPError(http.StatusForbidden, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,42 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+)
+
+// Message block endpoints
+
+type PreviewMessageBlocksRequest struct {
+	Message string                `json:"message" validate:"max=4096"`
+	Blocks  []models.MessageBlock `json:"blocks" validate:"required,min=1,max=10"`
+}
+
+// PreviewMessageBlocks shows how a message with blocks would be sent to each
+// partner, which blocks the partner renders and which are downgraded to text.
+// Nothing is sent.
+func (h *controller) PreviewMessageBlocks(c echo.Context) error {
+	var req PreviewMessageBlocksRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	for _, block := range req.Blocks {
+		if err := block.Validate(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid block of type "+block.Type)
+		}
+	}
+
+	rendered := models.RenderBlocks(chatapi.BlockCapabilities(h.conf.ChatAPI.BlockTypes), req.Message, req.Blocks)
+	rendered.Message = chatapi.FormatMessage(rendered.Message, h.conf.ChatAPI.MaxMessageChars)
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"renderings": []models.RenderedMessage{rendered},
+	})
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Message template endpoints, scoped to the caller's tenant. Statuses record
+// the partner's review of each template.
+
+type SetMessageTemplateRequest struct {
+	Body   string                `json:"body" validate:"required,max=1024"`
+	Status models.TemplateStatus `json:"status" validate:"required,oneof=pending approved rejected"`
+}
+
+func (h *controller) SetMessageTemplate(c echo.Context) error {
+	var req SetMessageTemplateRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	template, err := h.templateUsecase.SetTemplate(ctx, &models.MessageTemplate{
+		Partner: c.Param("partner"),
+		Name:    c.Param("name"),
+		Body:    req.Body,
+		Status:  req.Status,
+	})
+	if err != nil {
+		return messageTemplateError(err)
+	}
+
+	return c.JSON(http.StatusOK, template)
+}
+
+func (h *controller) ListMessageTemplates(c echo.Context) error {
+	ctx := c.Request().Context()
+	templates, err := h.templateUsecase.ListTemplates(ctx, c.QueryParam("partner"))
+	if err != nil {
+		return messageTemplateError(err)
+	}
+
+	return c.JSON(http.StatusOK, templates)
+}
+
+func (h *controller) DeleteMessageTemplate(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.templateUsecase.DeleteTemplate(ctx, c.Param("partner"), c.Param("name")); err != nil {
+		return messageTemplateError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func messageTemplateError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "template not found")
+	case errors.Is(err, models.ErrTemplateParams):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -1,10 +1,30 @@
 package server
 
 import (
+	"crypto/subtle"
 	"errors"
+	"net"
 	"net/http"
 
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	pkgmdw "github.com/nguyentranbao-ct/chat-bot/internal/server/middleware"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	headerAPIKey   = "X-API-Key"
+	headerAdminKey = "X-Admin-Key"
+	// headerCaptchaToken carries the captcha answer required after repeated
+	// failures
+	headerCaptchaToken = "X-Captcha-Token"
+	// headerDebugStats asks for the request stats, which are returned under
+	// the same header
+	headerDebugStats = "X-Debug-Stats"
 )
 
 func errorHandler() echo.HTTPErrorHandler {
@@ -31,3 +51,133 @@ func errorHandler() echo.HTTPErrorHandler {
 		}
 	}
 }
+
+// tenantResolver scopes the request context to the tenant of the X-API-Key
+// header and records the caller as the actor for audit logs. Requests without
+// a key only see data that belongs to no tenant, unless conf.Required rejects
+// them. Addresses that keep sending invalid api keys are locked out.
+func tenantResolver(conf config.TenantConfig, tenantUsecase usecase.TenantUsecase, authGuard usecase.AuthGuardUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ctx := c.Request().Context()
+
+			actor := models.Actor{Type: models.ActorTypeAnonymous}
+			if userID := pkgmdw.GetUserID(c); userID != "" {
+				actor = models.Actor{Type: models.ActorTypeUser, ID: userID}
+			}
+
+			var tenantID primitive.ObjectID
+			if rawKey := c.Request().Header.Get(headerAPIKey); rawKey != "" {
+				if err := authGuard.Check(ctx, models.AuthScopeIP, c.RealIP()); err != nil {
+					return authGuardError(err)
+				}
+				key, err := tenantUsecase.ResolveAPIKey(ctx, rawKey)
+				if err != nil {
+					log.Errorw(ctx, "Failed to resolve api key", "error", err)
+					return echo.NewHTTPError(http.StatusInternalServerError, "failed to resolve api key")
+				}
+				if key == nil {
+					authGuard.Fail(ctx, models.AuthScopeIP, c.RealIP())
+					return echo.NewHTTPError(http.StatusUnauthorized, "invalid api key")
+				}
+				tenantID = key.TenantID
+				actor = models.Actor{Type: models.ActorTypeAPIKey, ID: key.ID.Hex()}
+			}
+
+			if tenantID.IsZero() && conf.Required {
+				return echo.NewHTTPError(http.StatusUnauthorized, "tenant could not be resolved")
+			}
+
+			ctx = models.WithActor(ctx, actor)
+			if !tenantID.IsZero() {
+				c.Set("tenant_id", tenantID.Hex())
+				ctx = models.WithTenantID(ctx, tenantID)
+			}
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// adminAuth guards the admin endpoints with the configured admin key, read on
+// every request so a rotated key takes effect without a restart. Addresses
+// that keep sending a wrong key are locked out.
+func adminAuth(conf *config.Config, authGuard usecase.AuthGuardUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			adminKey := conf.SecretValue(c.Request().Context(), config.SecretAdminAPIKey)
+			if adminKey == "" {
+				return echo.NewHTTPError(http.StatusForbidden, "admin API is disabled")
+			}
+			if err := authGuard.Check(c.Request().Context(), models.AuthScopeIP, c.RealIP()); err != nil {
+				return authGuardError(err)
+			}
+			given := c.Request().Header.Get(headerAdminKey)
+			if subtle.ConstantTimeCompare([]byte(given), []byte(adminKey)) != 1 {
+				authGuard.Fail(c.Request().Context(), models.AuthScopeIP, c.RealIP())
+				return echo.NewHTTPError(http.StatusUnauthorized, "invalid admin key")
+			}
+
+			// admins work across tenants, routes for one tenant scope themselves
+			ctx := models.WithAllTenants(c.Request().Context())
+			ctx = models.WithActor(ctx, models.Actor{Type: models.ActorTypeAdmin})
+			c.SetRequest(c.Request().WithContext(ctx))
+			return next(c)
+		}
+	}
+}
+
+// ipExtractor reads the client address for RealIP, which keys the brute-force
+// lockouts. Forwarding headers are ignored unless the request came through one
+// of the trusted proxies, so clients can't pick their own address.
+func ipExtractor(conf config.ServerConfig) echo.IPExtractor {
+	if len(conf.TrustedProxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, cidr := range conf.TrustedProxies {
+		// validated with the config
+		if _, ipRange, err := net.ParseCIDR(cidr); err == nil {
+			options = append(options, echo.TrustIPRange(ipRange))
+		}
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// debugStats collects the database, cache and LLM work of requests that ask for
+// it with the X-Debug-Stats header and carry a valid admin key, and returns the
+// stats in the X-Debug-Stats response header. Requests with a wrong key are
+// served without stats and counted like wrong keys on admin routes, so the
+// header can't be used to guess the key.
+func debugStats(conf *config.Config, authGuard usecase.AuthGuardUsecase) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(headerDebugStats) == "" {
+				return next(c)
+			}
+			adminKey := conf.SecretValue(c.Request().Context(), config.SecretAdminAPIKey)
+			if adminKey == "" {
+				return next(c)
+			}
+			if err := authGuard.Check(c.Request().Context(), models.AuthScopeIP, c.RealIP()); err != nil {
+				return authGuardError(err)
+			}
+			given := c.Request().Header.Get(headerAdminKey)
+			if subtle.ConstantTimeCompare([]byte(given), []byte(adminKey)) != 1 {
+				authGuard.Fail(c.Request().Context(), models.AuthScopeIP, c.RealIP())
+				return next(c)
+			}
+
+			ctx, stats := reqstats.With(c.Request().Context())
+			c.SetRequest(c.Request().WithContext(ctx))
+			c.Response().Before(func() {
+				c.Response().Header().Set(headerDebugStats, stats.String())
+			})
+			return next(c)
+		}
+	}
+}
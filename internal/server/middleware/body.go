@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+type (
+	// BodyRule bounds the size of a request body in bytes and the media
+	// types it may have; no content types accepts any
+	BodyRule struct {
+		Limit        int64
+		ContentTypes []string
+	}
+
+	BodyConfig struct {
+		Skipper Skipper
+		// Default applies to the routes without their own rule
+		Default BodyRule
+		// Routes are rules by method and route path, e.g. "POST /api/v1/messages"
+		Routes map[string]BodyRule
+	}
+)
+
+// Body rejects POST, PUT and PATCH bodies over the limit of their route with
+// 413 and bodies of another media type with 415. A body without a length is
+// cut at the limit while the handler reads it.
+func Body(config BodyConfig) echo.MiddlewareFunc {
+	if config.Skipper == nil {
+		config.Skipper = DefaultSkipper
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			if config.Skipper(c) || !hasBody(req.Method) {
+				return next(c)
+			}
+
+			rule, ok := config.Routes[req.Method+" "+c.Path()]
+			if !ok {
+				rule = config.Default
+			}
+
+			if req.ContentLength != 0 && len(rule.ContentTypes) > 0 {
+				mediaType, _, err := mime.ParseMediaType(req.Header.Get(echo.HeaderContentType))
+				if err != nil || !slices.Contains(rule.ContentTypes, mediaType) {
+					return echo.NewHTTPError(http.StatusUnsupportedMediaType, "content type must be one of "+strings.Join(rule.ContentTypes, ", "))
+				}
+			}
+
+			if rule.Limit <= 0 {
+				return next(c)
+			}
+			if req.ContentLength > rule.Limit {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+
+			body := &limitedBody{ReadCloser: http.MaxBytesReader(c.Response(), req.Body, rule.Limit)}
+			req.Body = body
+			err := next(c)
+			// handlers turn read errors into their own, so an overflow is
+			// reported here unless a response was already sent
+			if body.exceeded && !c.Response().Committed {
+				return echo.ErrStatusRequestEntityTooLarge
+			}
+			return err
+		}
+	}
+}
+
+// limitedBody remembers whether the handler read past the limit
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+func hasBody(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"encoding/json"
 	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Validator struct {
@@ -45,6 +48,28 @@ func NewValidator() *Validator {
 		return true
 	})
 
+	validate.RegisterValidation("objectid", func(fl validator.FieldLevel) bool {
+		return primitive.IsValidObjectID(fl.Field().String())
+	})
+
+	// maxdepth and maxjson bound free-form metadata before it reaches MongoDB,
+	// e.g. `validate:"maxdepth=8,maxjson=16384"`
+	validate.RegisterValidation("maxdepth", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		if err != nil {
+			return false
+		}
+		return valueDepth(fl.Field().Interface()) <= limit
+	})
+	validate.RegisterValidation("maxjson", func(fl validator.FieldLevel) bool {
+		limit, err := strconv.Atoi(fl.Param())
+		if err != nil {
+			return false
+		}
+		data, err := json.Marshal(fl.Field().Interface())
+		return err == nil && len(data) <= limit
+	})
+
 	v := &Validator{
 		validate: validate,
 	}
@@ -52,6 +77,24 @@ func NewValidator() *Validator {
 	return v
 }
 
+// valueDepth is the nesting depth of a decoded JSON value, 0 for scalars
+func valueDepth(value any) int {
+	depth := 0
+	switch value := value.(type) {
+	case map[string]any:
+		for _, item := range value {
+			depth = max(depth, valueDepth(item))
+		}
+	case []any:
+		for _, item := range value {
+			depth = max(depth, valueDepth(item))
+		}
+	default:
+		return 0
+	}
+	return depth + 1
+}
+
 func (v *Validator) Validate(i interface{}) error {
 	return v.validate.Struct(i)
 }
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator(t *testing.T) {
+	type request struct {
+		ID       string         `json:"id" validate:"omitempty,objectid"`
+		IDs      []string       `json:"ids" validate:"omitempty,dive,objectid"`
+		Metadata map[string]any `json:"metadata" validate:"omitempty,maxdepth=2,maxjson=32"`
+	}
+
+	tests := []struct {
+		name    string
+		req     request
+		wantErr bool
+	}{
+		{"Valid", request{ID: "66f1c0a2e4b0a1b2c3d4e5f6", IDs: []string{"66f1c0a2e4b0a1b2c3d4e5f6"}, Metadata: map[string]any{"a": map[string]any{"b": 1}}}, false},
+		{"Invalid ObjectID", request{ID: "not-an-id"}, true},
+		{"Invalid ObjectID In Slice", request{IDs: []string{"66f1c0a2e4b0a1b2c3d4e5f6", "123"}}, true},
+		{"Metadata Too Deep", request{Metadata: map[string]any{"a": map[string]any{"b": []any{1}}}}, true},
+		{"Metadata Too Large", request{Metadata: map[string]any{"a": strings.Repeat("x", 32)}}, true},
+	}
+
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := v.Validate(tt.req)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const testAPIKey = "ck_valid"
+
+type fakeTenantUsecase struct {
+	usecase.TenantUsecase
+	key *models.APIKey
+}
+
+func (u *fakeTenantUsecase) ResolveAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	if rawKey == testAPIKey {
+		return u.key, nil
+	}
+	return nil, nil
+}
+
+// fakeAuthGuard never locks out and records the keys of failed attempts
+type fakeAuthGuard struct {
+	usecase.AuthGuardUsecase
+	failed []string
+}
+
+func (g *fakeAuthGuard) Check(ctx context.Context, scope models.AuthScope, key string) error {
+	return nil
+}
+
+func (g *fakeAuthGuard) Fail(ctx context.Context, scope models.AuthScope, key string) {
+	g.failed = append(g.failed, string(scope)+":"+key)
+}
+
+func TestTenantResolver(t *testing.T) {
+	key := &models.APIKey{ID: primitive.NewObjectID(), TenantID: primitive.NewObjectID()}
+
+	tests := []struct {
+		name       string
+		required   bool
+		apiKey     string
+		wantStatus int
+		wantTenant string
+		wantActor  models.ActorType
+		wantFailed int
+	}{
+		{"API Key", false, testAPIKey, http.StatusOK, key.TenantID.Hex(), models.ActorTypeAPIKey, 0},
+		{"Missing Key Is Unscoped", false, "", http.StatusOK, "", models.ActorTypeAnonymous, 0},
+		{"Missing Key When Required", true, "", http.StatusUnauthorized, "", "", 0},
+		{"Invalid Key", false, "ck_wrong", http.StatusUnauthorized, "", "", 1},
+		{"Invalid Key When Required", true, "ck_wrong", http.StatusUnauthorized, "", "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authGuard := &fakeAuthGuard{}
+			e := echo.New()
+			e.Use(tenantResolver(config.TenantConfig{Required: tt.required}, &fakeTenantUsecase{key: key}, authGuard))
+
+			var gotTenant string
+			var gotActor models.ActorType
+			e.GET("/api/v1/sessions", func(c echo.Context) error {
+				if tenantID, ok := models.TenantIDFromContext(c.Request().Context()); ok {
+					gotTenant = tenantID.Hex()
+				}
+				gotActor = models.ActorFromContext(c.Request().Context()).Type
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(headerAPIKey, tt.apiKey)
+			}
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantTenant, gotTenant)
+			assert.Equal(t, tt.wantActor, gotActor)
+			assert.Len(t, authGuard.failed, tt.wantFailed)
+		})
+	}
+}
+
+func TestIPExtractor(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		forwarded  []string
+		wantKey    string
+	}{
+		{"Spoofed Header Without Proxy", nil, "203.0.113.7:4242", []string{"198.51.100.1", "198.51.100.2"}, "ip:203.0.113.7"},
+		{"Spoofed Header From Untrusted Peer", []string{"10.0.0.0/8"}, "203.0.113.7:4242", []string{"198.51.100.1", "198.51.100.2"}, "ip:203.0.113.7"},
+		{"Forwarded By Trusted Proxy", []string{"10.0.0.0/8"}, "10.1.2.3:4242", []string{"198.51.100.1", "198.51.100.1"}, "ip:198.51.100.1"},
+		{"Spoofed Hop Behind Trusted Proxy", []string{"10.0.0.0/8"}, "10.1.2.3:4242", []string{"198.51.100.1, 203.0.113.7", "198.51.100.2, 203.0.113.7"}, "ip:203.0.113.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authGuard := &fakeAuthGuard{}
+			e := echo.New()
+			e.IPExtractor = ipExtractor(config.ServerConfig{TrustedProxies: tt.proxies})
+			e.Use(tenantResolver(config.TenantConfig{}, &fakeTenantUsecase{}, authGuard))
+			e.GET("/api/v1/sessions", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			for _, forwarded := range tt.forwarded {
+				req := httptest.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+				req.RemoteAddr = tt.remoteAddr
+				req.Header.Set(echo.HeaderXForwardedFor, forwarded)
+				req.Header.Set(echo.HeaderXRealIP, forwarded)
+				req.Header.Set(headerAPIKey, "ck_wrong")
+				e.ServeHTTP(httptest.NewRecorder(), req)
+			}
+
+			assert.Equal(t, []string{tt.wantKey, tt.wantKey}, authGuard.failed)
+		})
+	}
+}
+
+func TestDebugStats(t *testing.T) {
+	const adminKey = "admin-key-0123456789"
+
+	tests := []struct {
+		name       string
+		key        string
+		wantStats  bool
+		wantFailed int
+	}{
+		{"Admin Key", adminKey, true, 0},
+		{"Wrong Key", "admin-key-guess", false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &config.Config{}
+			conf.Tenant.AdminAPIKey = adminKey
+			authGuard := &fakeAuthGuard{}
+			e := echo.New()
+			e.Use(debugStats(conf, authGuard))
+			e.GET("/health", func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			req.Header.Set(headerDebugStats, "1")
+			req.Header.Set(headerAdminKey, tt.key)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.wantStats, rec.Header().Get(headerDebugStats) != "")
+			assert.Len(t, authGuard.failed, tt.wantFailed)
+		})
+	}
+}
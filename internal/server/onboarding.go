@@ -0,0 +1,134 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Onboarding endpoints
+
+type SelectChatModeRequest struct {
+	ChatMode string `json:"chat_mode" validate:"required"`
+}
+
+type TestConversationRequest struct {
+	// Message defaults to ONBOARDING_TEST_MESSAGE
+	Message string `json:"message"`
+}
+
+func (h *controller) GetOnboarding(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	onboarding, err := h.onboardingUsecase.GetOnboarding(ctx, userID)
+	if err != nil {
+		return onboardingError(err)
+	}
+
+	return c.JSON(http.StatusOK, onboarding)
+}
+
+func (h *controller) LinkChotot(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	onboarding, err := h.onboardingUsecase.LinkChotot(ctx, userID)
+	if err != nil {
+		return onboardingError(err)
+	}
+
+	return c.JSON(http.StatusOK, onboarding)
+}
+
+func (h *controller) SelectOnboardingChatMode(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req SelectChatModeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	onboarding, err := h.onboardingUsecase.SelectChatMode(ctx, userID, req.ChatMode)
+	if err != nil {
+		return onboardingError(err)
+	}
+
+	return c.JSON(http.StatusOK, onboarding)
+}
+
+func (h *controller) SetOnboardingWorkingHours(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req models.WorkingHours
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	onboarding, err := h.onboardingUsecase.SetWorkingHours(ctx, userID, req)
+	if err != nil {
+		return onboardingError(err)
+	}
+
+	return c.JSON(http.StatusOK, onboarding)
+}
+
+func (h *controller) RunOnboardingTestConversation(c echo.Context) error {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req TestConversationRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	onboarding, err := h.onboardingUsecase.RunTestConversation(ctx, userID, req.Message)
+	if err != nil {
+		return onboardingError(err)
+	}
+
+	return c.JSON(http.StatusOK, onboarding)
+}
+
+func onboardingError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, err.Error())
+	case errors.Is(err, models.ErrChototLinkUnverified), errors.Is(err, models.ErrInvalidWorkingHours):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	case errors.Is(err, models.ErrOnboardingStepLocked), errors.Is(err, models.ErrAttributeConflict):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrOnboardingTestFailed):
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,62 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Outcome endpoints, how conversations ended and which chat modes convert
+
+type SetChannelOutcomeRequest struct {
+	Outcome models.SessionOutcome `json:"outcome" validate:"required,oneof=sold lost no_response handed_off"`
+	Reason  string                `json:"reason,omitempty" validate:"max=500"`
+	// OrderID is the seller's reference of the order
+	OrderID string `json:"order_id,omitempty" validate:"max=100"`
+}
+
+func (h *controller) SetChannelOutcome(c echo.Context) error {
+	var req SetChannelOutcomeRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	session, err := h.outcomeUsecase.RecordChannel(ctx, c.Param("channel_id"), req.Outcome, models.OutcomeSourceSeller, req.Reason, req.OrderID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "channel has no session")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, session)
+}
+
+func (h *controller) GetConversionReport(c echo.Context) error {
+	since, err := parseTimeParam(c, "since")
+	if err != nil {
+		return err
+	}
+	until, err := parseTimeParam(c, "until")
+	if err != nil {
+		return err
+	}
+	if !since.IsZero() && !until.IsZero() && !since.Before(until) {
+		return echo.NewHTTPError(http.StatusBadRequest, "since must be before until")
+	}
+
+	ctx := c.Request().Context()
+	report, err := h.outcomeUsecase.ConversionReport(ctx, since, until)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,75 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Persona endpoints, scoped to the caller's tenant. An empty seller_id
+// addresses the tenant-wide persona.
+
+type SetPersonaRequest struct {
+	SellerID        string   `json:"seller_id"`
+	DisplayName     string   `json:"display_name" validate:"max=64"`
+	Tone            string   `json:"tone" validate:"max=1000"`
+	Languages       []string `json:"languages" validate:"max=10"`
+	ForbiddenTopics []string `json:"forbidden_topics" validate:"max=50"`
+}
+
+func (h *controller) SetPersona(c echo.Context) error {
+	var req SetPersonaRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	persona, err := h.personaUsecase.SetPersona(ctx, &models.Persona{
+		SellerID:        req.SellerID,
+		DisplayName:     req.DisplayName,
+		Tone:            req.Tone,
+		Languages:       req.Languages,
+		ForbiddenTopics: req.ForbiddenTopics,
+	})
+	if err != nil {
+		return personaError(err)
+	}
+
+	return c.JSON(http.StatusOK, persona)
+}
+
+func (h *controller) GetPersona(c echo.Context) error {
+	ctx := c.Request().Context()
+	persona, err := h.personaUsecase.GetPersona(ctx, c.QueryParam("seller_id"))
+	if err != nil {
+		return personaError(err)
+	}
+
+	return c.JSON(http.StatusOK, persona)
+}
+
+func (h *controller) DeletePersona(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.personaUsecase.DeletePersona(ctx, c.QueryParam("seller_id")); err != nil {
+		return personaError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func personaError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "persona not found")
+	case errors.Is(err, models.ErrEmptyPersona):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Channel privacy endpoints, keeping rooms and messages away from the LLM
+
+func (h *controller) GetChannelPrivacy(c echo.Context) error {
+	ctx := c.Request().Context()
+	privacy, err := h.privacyUsecase.Get(ctx, c.Param("channel_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, privacy)
+}
+
+type SetChannelPrivacyRequest struct {
+	Private bool `json:"private"`
+}
+
+func (h *controller) SetChannelPrivacy(c echo.Context) error {
+	var req SetChannelPrivacyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	privacy, err := h.privacyUsecase.SetPrivate(ctx, c.Param("channel_id"), req.Private)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, privacy)
+}
+
+func (h *controller) MarkMessagePrivate(c echo.Context) error {
+	return h.setMessagePrivate(c, true)
+}
+
+func (h *controller) UnmarkMessagePrivate(c echo.Context) error {
+	return h.setMessagePrivate(c, false)
+}
+
+func (h *controller) setMessagePrivate(c echo.Context, private bool) error {
+	ctx := c.Request().Context()
+	privacy, err := h.privacyUsecase.SetMessagePrivate(ctx, c.Param("channel_id"), c.Param("message_id"), private)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, privacy)
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Prompt log endpoints
+
+func (h *controller) ListPromptLogs(c echo.Context) error {
+	filter := models.PromptLogFilter{
+		ChatMode: c.QueryParam("chat_mode"),
+	}
+
+	if tenantParam := c.QueryParam("tenant_id"); tenantParam != "" {
+		tenantID, err := primitive.ObjectIDFromHex(tenantParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+		}
+		filter.TenantID = &tenantID
+	}
+	if sessionParam := c.QueryParam("session_id"); sessionParam != "" {
+		sessionID, err := primitive.ObjectIDFromHex(sessionParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid session_id")
+		}
+		filter.SessionID = &sessionID
+	}
+
+	var err error
+	if filter.Since, err = parseTimeParam(c, "since"); err != nil {
+		return err
+	}
+	if filter.Until, err = parseTimeParam(c, "until"); err != nil {
+		return err
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 500 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 500")
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request().Context()
+	entries, err := h.promptLogUsecase.List(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, entries)
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Reconcile endpoints
+
+func (h *controller) RunReconcile(c echo.Context) error {
+	ctx := c.Request().Context()
+	report, err := h.reconcileUsecase.Reconcile(ctx)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,39 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Reply assist endpoints
+
+type SuggestRepliesRequest struct {
+	// ChatMode overrides the chat mode selected for the channel
+	ChatMode string `json:"chat_mode"`
+	Count    int    `json:"count" validate:"omitempty,min=1,max=5"`
+}
+
+func (h *controller) SuggestReplies(c echo.Context) error {
+	var req SuggestRepliesRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	candidates, err := h.replyAssistUsecase.SuggestReplies(ctx, c.Param("channel_id"), req.ChatMode, req.Count)
+	if err != nil {
+		if errors.Is(err, models.ErrNoBuyerMessage) {
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, candidates)
+}
@@ -0,0 +1,52 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reservation endpoints
+
+func (h *controller) ListSellerReservations(c echo.Context) error {
+	sellerID := c.Param("seller_id")
+	if sellerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "seller_id is required")
+	}
+
+	ctx := c.Request().Context()
+	reservations, err := h.reservationUsecase.ListActiveReservations(ctx, sellerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, reservations)
+}
+
+func (h *controller) ReleaseReservation(c echo.Context) error {
+	sellerID := c.Param("seller_id")
+	if sellerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "seller_id is required")
+	}
+	idParam := c.Param("id")
+	id, err := primitive.ObjectIDFromHex(idParam)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid reservation ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.reservationUsecase.ReleaseReservation(ctx, sellerID, id); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "active reservation not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "reservation released successfully",
+	})
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Scam flag endpoints, the admin review queue of likely scam messages
+
+func (h *controller) ListScamFlags(c echo.Context) error {
+	filter := models.ScamFlagFilter{
+		Status:    models.ScamFlagStatus(c.QueryParam("status")),
+		ChannelID: c.QueryParam("channel_id"),
+	}
+
+	if tenantParam := c.QueryParam("tenant_id"); tenantParam != "" {
+		tenantID, err := primitive.ObjectIDFromHex(tenantParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant_id")
+		}
+		filter.TenantID = &tenantID
+	}
+
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		limit, err := strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 1000 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 1000")
+		}
+		filter.Limit = limit
+	}
+
+	ctx := c.Request().Context()
+	flags, err := h.scamUsecase.ListFlags(ctx, filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, flags)
+}
+
+type ReviewScamFlagRequest struct {
+	Status models.ScamFlagStatus `json:"status" validate:"required,oneof=confirmed dismissed"`
+}
+
+func (h *controller) ReviewScamFlag(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid scam flag ID")
+	}
+
+	var req ReviewScamFlagRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	flag, err := h.scamUsecase.Review(ctx, id, req.Status)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "scam flag not found")
+		case errors.Is(err, models.ErrScamFlagReviewed):
+			return echo.NewHTTPError(http.StatusConflict, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, flag)
+}
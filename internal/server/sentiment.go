@@ -0,0 +1,52 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Sentiment endpoints
+
+func (h *controller) GetChannelSentiment(c echo.Context) error {
+	ctx := c.Request().Context()
+	sentiment, err := h.sentimentUsecase.GetChannelSentiment(ctx, c.Param("channel_id"))
+	if err != nil {
+		return sentimentError(err, "no sentiment recorded for channel")
+	}
+
+	return c.JSON(http.StatusOK, sentiment)
+}
+
+func (h *controller) ResumeChannel(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.sentimentUsecase.Resume(ctx, c.Param("channel_id")); err != nil {
+		return sentimentError(err, "channel has no sentiment alert")
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *controller) ListSentimentAlerts(c echo.Context) error {
+	sellerID := c.Param("seller_id")
+	if sellerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "seller_id is required")
+	}
+
+	ctx := c.Request().Context()
+	alerts, err := h.sentimentUsecase.ListAlerts(ctx, sellerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, alerts)
+}
+
+func sentimentError(err error, notFound string) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return echo.NewHTTPError(http.StatusNotFound, notFound)
+	}
+	return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+}
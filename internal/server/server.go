@@ -10,7 +10,9 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/storage"
 	pkgmdw "github.com/nguyentranbao-ct/chat-bot/internal/server/middleware"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
 	"go.uber.org/fx"
 )
 
@@ -19,10 +21,13 @@ func StartServer(
 	sd fx.Shutdowner,
 	conf *config.Config,
 	handler Controller,
+	tenantUsecase usecase.TenantUsecase,
+	authGuard usecase.AuthGuardUsecase,
 ) {
 	e := echo.New()
 	e.Validator = pkgmdw.NewValidator()
 	e.HTTPErrorHandler = errorHandler()
+	e.IPExtractor = ipExtractor(conf.Server)
 
 	logConfig := pkgmdw.LogRequestConfig{
 		Logger: logger.MustNamed("http"),
@@ -30,11 +35,23 @@ func StartServer(
 			uri := c.Request().RequestURI
 			return uri != "/health" && uri != "/metrics"
 		},
+		// Tenant archives and message exports are streamed, don't buffer
+		// them for the log
+		ResponseBody: func(c echo.Context) bool {
+			switch c.Path() {
+			case "/api/v1/admin/tenants/:id/backup", "/api/v1/channels/:channel_id/messages/export":
+				return false
+			}
+			return true
+		},
 		KeyAndValues: func(c echo.Context) []any {
-			args := make([]any, 0, 4)
+			args := make([]any, 0, 6)
 			if c.Get("user_id") != nil {
 				args = append(args, "user_id", c.Get("user_id"))
 			}
+			if c.Get("tenant_id") != nil {
+				args = append(args, "tenant_id", c.Get("tenant_id"))
+			}
 			if c.Get("project_id") != nil {
 				args = append(args, "project_id", c.Get("project_id"))
 			}
@@ -45,6 +62,21 @@ func StartServer(
 	pkgmdw.AutoVersioning(e)
 	e.Use(pkgmdw.Metrics())
 	e.Use(pkgmdw.RequestID())
+	if conf.Server.DebugStats {
+		e.Use(debugStats(conf, authGuard))
+	}
+	// before the request log, which buffers the body
+	e.Use(pkgmdw.Body(pkgmdw.BodyConfig{
+		Default: pkgmdw.BodyRule{Limit: conf.Server.BodyLimit, ContentTypes: []string{echo.MIMEApplicationJSON}},
+		Routes: map[string]pkgmdw.BodyRule{
+			"POST /api/v1/messages": {Limit: conf.Server.MessageBodyLimit, ContentTypes: []string{echo.MIMEApplicationJSON}},
+			"POST /api/v1/admin/tenants/restore": {
+				Limit:        conf.Server.UploadBodyLimit,
+				ContentTypes: []string{"application/gzip", "application/x-gzip", echo.MIMEOctetStream},
+			},
+			"POST /api/v1/users/:id/avatar": {Limit: conf.Server.UploadBodyLimit, ContentTypes: []string{echo.MIMEMultipartForm}},
+		},
+	}))
 	e.Use(pkgmdw.LogRequest(logConfig))
 	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{
 		LogErrorFunc: func(c echo.Context, err error, stack []byte) error {
@@ -54,8 +86,56 @@ func StartServer(
 	}))
 
 	e.GET("/health", handler.Health)
+	e.GET("/api/v1/capabilities", handler.GetCapabilities)
+	// Verification links are opened from the mailbox, without credentials
+	e.GET("/api/v1/email-verifications/:token", handler.ConfirmEmailVerificationLink)
+	if conf.Storage.Provider == storage.ProviderLocal {
+		e.Static("/media", conf.Storage.LocalDir)
+	}
 
-	api := e.Group("/api/v1")
+	// Tenant administration, authenticated with the admin key rather than a tenant
+	admin := e.Group("/api/v1/admin", adminAuth(conf, authGuard))
+	admin.POST("/tenants", handler.CreateTenant)
+	admin.GET("/tenants/:id", handler.GetTenant)
+	admin.PUT("/tenants/:id/settings", handler.UpdateTenantSettings)
+	admin.GET("/tenants/:id/tool-policy", handler.GetToolPolicy)
+	admin.PUT("/tenants/:id/tool-policy", handler.UpdateToolPolicy)
+	admin.POST("/tenants/:id/api-keys", handler.CreateAPIKey)
+	admin.DELETE("/tenants/:id/api-keys/:key_id", handler.RevokeAPIKey)
+	admin.GET("/tenants/:id/backup", handler.ExportTenant)
+	admin.POST("/tenants/restore", handler.RestoreTenant)
+	admin.GET("/audit-logs", handler.ListAuditLogs)
+	admin.GET("/sessions/:id/transcript", handler.GetSessionTranscript)
+	admin.GET("/sessions/:id/traces", handler.ListSessionTraces)
+	admin.GET("/prompt-logs", handler.ListPromptLogs)
+	admin.POST("/replays", handler.RunReplay)
+	admin.POST("/chat-modes/:name/simulate", handler.SimulateChatMode)
+	admin.GET("/test-messages", handler.ListTestMessages)
+	admin.POST("/reconcile", handler.RunReconcile)
+	admin.GET("/config/timeouts", handler.GetTimeoutConfig)
+	admin.POST("/message-blocks/preview", handler.PreviewMessageBlocks)
+	admin.GET("/stats/live", handler.GetLiveStats)
+	admin.GET("/costs", handler.GetCostReport)
+	admin.GET("/dead-letters", handler.ListDeadLetters)
+	admin.POST("/dead-letters/requeue", handler.RequeueDeadLetters)
+	admin.POST("/dead-letters/:id/requeue", handler.RequeueDeadLetter)
+	admin.GET("/scam-flags", handler.ListScamFlags)
+	admin.PUT("/scam-flags/:id", handler.ReviewScamFlag)
+	admin.POST("/bulk/abandon-inactive-sessions", handler.AbandonInactiveSessions)
+	admin.POST("/bulk/sellers/:seller_id/chat-mode", handler.ReassignSellerChatMode)
+	admin.GET("/whitelist", handler.GetWhitelist)
+	admin.PUT("/whitelist/rollout", handler.SetWhitelistRollout)
+	admin.PUT("/whitelist/:kind/:value", handler.AddWhitelistEntry)
+	admin.DELETE("/whitelist/:kind/:value", handler.RemoveWhitelistEntry)
+	admin.GET("/auth-lockouts", handler.ListAuthLockouts)
+	admin.DELETE("/auth-lockouts/:scope/:key", handler.UnlockAuthLockout)
+	admin.PUT("/llm-keys/:provider", handler.SetLLMKey)
+	admin.DELETE("/llm-keys/:provider", handler.DeleteLLMKey)
+	admin.GET("/jobs", handler.ListJobs)
+	admin.GET("/jobs/:id", handler.GetJob)
+	admin.POST("/jobs/:id/cancel", handler.CancelJob)
+
+	api := e.Group("/api/v1", tenantResolver(conf.Tenant, tenantUsecase, authGuard))
 	api.POST("/messages", handler.ProcessMessage)
 
 	// User management routes
@@ -70,6 +150,109 @@ func StartServer(
 	api.GET("/users/:id/attributes/:key", handler.GetUserAttributeByKey)
 	api.DELETE("/users/:id/attributes/:key", handler.RemoveUserAttribute)
 
+	// User avatar routes
+	api.POST("/users/:id/avatar", handler.UploadUserAvatar)
+	api.POST("/users/:id/avatar/sync", handler.SyncUserAvatar)
+
+	// Email verification routes
+	api.POST("/users/:id/email-verification", handler.SendEmailVerification)
+	api.POST("/users/:id/email-verification/confirm", handler.ConfirmEmailVerification)
+
+	// Chotot account link routes
+	api.POST("/users/:id/chotot-link", handler.StartChototLink)
+	api.GET("/users/:id/chotot-link", handler.GetChototLink)
+	api.DELETE("/users/:id/chotot-link", handler.RevokeChototLink)
+
+	// Seller onboarding routes
+	api.GET("/users/:id/onboarding", handler.GetOnboarding)
+	api.PUT("/users/:id/onboarding/chotot", handler.LinkChotot)
+	api.PUT("/users/:id/onboarding/chat-mode", handler.SelectOnboardingChatMode)
+	api.PUT("/users/:id/onboarding/working-hours", handler.SetOnboardingWorkingHours)
+	api.POST("/users/:id/onboarding/test-conversation", handler.RunOnboardingTestConversation)
+
+	// Chat mode pack routes
+	api.GET("/chat-mode-packs", handler.ListChatModePacks)
+	api.PUT("/users/:id/vertical", handler.SelectVertical)
+
+	// Reservation routes
+	api.GET("/sellers/:seller_id/reservations", handler.ListSellerReservations)
+	api.DELETE("/sellers/:seller_id/reservations/:id", handler.ReleaseReservation)
+
+	// Reply suggestion routes
+	api.GET("/sellers/:seller_id/suggestions", handler.ListSellerSuggestions)
+	api.POST("/suggestions/:id/approve", handler.ApproveSuggestion)
+	api.POST("/suggestions/:id/reject", handler.RejectSuggestion)
+
+	// Channel routes
+	api.GET("/channels/:channel_id/participants", handler.GetChannelParticipants)
+	api.GET("/channels/:channel_id/messages", handler.GetChannelMessages)
+	api.GET("/channels/:channel_id/messages/export", handler.ExportChannelMessages)
+	api.PUT("/channels/:channel_id/chat-mode", handler.SetChannelChatMode)
+	api.GET("/channels/:channel_id/chat-mode", handler.GetChannelChatMode)
+	api.DELETE("/channels/:channel_id/chat-mode", handler.ClearChannelChatMode)
+	api.GET("/channels/:channel_id/privacy", handler.GetChannelPrivacy)
+	api.PUT("/channels/:channel_id/privacy", handler.SetChannelPrivacy)
+	api.PUT("/channels/:channel_id/messages/:message_id/private", handler.MarkMessagePrivate)
+	api.DELETE("/channels/:channel_id/messages/:message_id/private", handler.UnmarkMessagePrivate)
+	api.POST("/channels/:channel_id/suggest", handler.SuggestReplies)
+
+	// Sentiment routes
+	api.GET("/channels/:channel_id/sentiment", handler.GetChannelSentiment)
+	api.DELETE("/channels/:channel_id/sentiment/alert", handler.ResumeChannel)
+	api.GET("/sellers/:seller_id/sentiment-alerts", handler.ListSentimentAlerts)
+
+	// Budget routes
+	api.GET("/sessions/:id", handler.GetSession)
+	api.GET("/channels/:channel_id/budget", handler.GetChannelBudget)
+
+	// Outcome routes
+	api.PUT("/channels/:channel_id/outcome", handler.SetChannelOutcome)
+	api.GET("/reports/conversions", handler.GetConversionReport)
+
+	// Draft routes
+	api.PUT("/channels/:channel_id/draft", handler.SaveDraft)
+	api.GET("/channels/:channel_id/draft", handler.GetDraft)
+	api.DELETE("/channels/:channel_id/draft", handler.DeleteDraft)
+	api.GET("/drafts", handler.ListDrafts)
+
+	// LLM key routes
+	api.PUT("/llm-keys/:provider", handler.SetLLMKey)
+	api.POST("/llm-keys/:provider/validate", handler.ValidateLLMKey)
+	api.DELETE("/llm-keys/:provider", handler.DeleteLLMKey)
+
+	// Persona routes
+	api.PUT("/persona", handler.SetPersona)
+	api.GET("/persona", handler.GetPersona)
+	api.DELETE("/persona", handler.DeletePersona)
+
+	// Activity feed routes
+	api.GET("/sellers/:seller_id/activity", handler.ListActivityFeed)
+	api.POST("/sellers/:seller_id/activity/seen", handler.MarkActivityFeedSeen)
+
+	// Team routes
+	api.POST("/teams", handler.CreateTeam)
+	api.GET("/teams/:id", handler.GetTeam)
+	api.PUT("/teams/:id/members/:user_id", handler.AddTeamMember)
+	api.DELETE("/teams/:id/members/:user_id", handler.RemoveTeamMember)
+	api.GET("/teams/:id/assignments", handler.ListChannelAssignments)
+	api.PUT("/teams/:id/assignments/:channel_id", handler.AssignChannel)
+	api.DELETE("/teams/:id/assignments/:channel_id", handler.UnassignChannel)
+
+	// Channel claim routes
+	api.GET("/channels/:channel_id/claim", handler.GetChannelClaim)
+	api.PUT("/channels/:channel_id/claim", handler.ClaimChannel)
+	api.DELETE("/channels/:channel_id/claim", handler.ReleaseChannelClaim)
+
+	// Message template routes
+	api.GET("/templates", handler.ListMessageTemplates)
+	api.PUT("/templates/:partner/:name", handler.SetMessageTemplate)
+	api.DELETE("/templates/:partner/:name", handler.DeleteMessageTemplate)
+
+	// System message routes
+	api.GET("/system-messages", handler.ListSystemMessages)
+	api.PUT("/system-messages/:locale/:key", handler.SetSystemMessage)
+	api.DELETE("/system-messages/:locale/:key", handler.DeleteSystemMessage)
+
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			go func() {
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/httpx"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+)
+
+// Stats endpoints
+
+func (h *controller) GetLiveStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, liveStats(livestats.Snapshot()))
+}
+
+// liveStats groups the series of snapshot by what they measure
+func liveStats(snapshot map[string]livestats.Stat) *models.LiveStats {
+	generations := snapshot[models.StatLLMGenerate]
+	errors := snapshot[models.StatLLMErrors]
+	stats := &models.LiveStats{
+		WindowSeconds: int(livestats.Window.Seconds()),
+		Messages:      map[string]livestats.Stat{},
+		Intents:       map[string]livestats.Stat{},
+		Sentiment:     map[string]livestats.Stat{},
+		BotReplies:    snapshot[models.StatBotReplies],
+		LLM: models.LiveLLMStats{
+			Generations:  generations,
+			Errors:       errors,
+			AvgLatencyMs: generations.Avg,
+			Outages:      map[string]livestats.Stat{},
+		},
+		Partners:        map[string]models.LivePartnerStats{},
+		ReplyWindow:     map[string]livestats.Stat{},
+		SentimentAlerts: snapshot[models.StatSentimentAlerts],
+		ScamFlags:       snapshot[models.StatScamFlags],
+	}
+	if generations.Count > 0 {
+		stats.LLM.ErrorRate = float64(errors.Count) / float64(generations.Count)
+	}
+
+	for name, stat := range snapshot {
+		switch {
+		case strings.HasPrefix(name, models.StatMessagesPrefix):
+			stats.Messages[strings.TrimPrefix(name, models.StatMessagesPrefix)] = stat
+		case strings.HasPrefix(name, models.StatIntentsPrefix):
+			stats.Intents[strings.TrimPrefix(name, models.StatIntentsPrefix)] = stat
+		case strings.HasPrefix(name, models.StatSentimentPrefix):
+			stats.Sentiment[strings.TrimPrefix(name, models.StatSentimentPrefix)] = stat
+		case strings.HasPrefix(name, models.StatLLMOutagesPrefix):
+			stats.LLM.Outages[strings.TrimPrefix(name, models.StatLLMOutagesPrefix)] = stat
+		case strings.HasPrefix(name, models.StatReplyWindowPrefix):
+			stats.ReplyWindow[strings.TrimPrefix(name, models.StatReplyWindowPrefix)] = stat
+		case strings.HasPrefix(name, httpx.StatPrefix):
+			partner := strings.TrimPrefix(name, httpx.StatPrefix)
+			stats.Partners[partner] = models.LivePartnerStats{
+				Requests:     stat,
+				Errors:       snapshot[httpx.ErrorStatPrefix+partner],
+				AvgLatencyMs: stat.Avg,
+			}
+		}
+	}
+	return stats
+}
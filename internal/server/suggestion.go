@@ -0,0 +1,82 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Reply suggestion endpoints, for sellers reviewing the bot's replies in safe mode
+
+func (h *controller) ListSellerSuggestions(c echo.Context) error {
+	sellerID := c.Param("seller_id")
+	if sellerID == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "seller_id is required")
+	}
+
+	ctx := c.Request().Context()
+	suggestions, err := h.suggestionUsecase.ListPending(ctx, sellerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, suggestions)
+}
+
+type ApproveSuggestionRequest struct {
+	// Message replaces the suggested reply when the seller edited it
+	Message string `json:"message" validate:"max=4000"`
+}
+
+func (h *controller) ApproveSuggestion(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid suggestion ID")
+	}
+
+	var req ApproveSuggestionRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	suggestion, err := h.suggestionUsecase.Approve(ctx, id, req.Message)
+	if err != nil {
+		return suggestionError(err)
+	}
+
+	return c.JSON(http.StatusOK, suggestion)
+}
+
+func (h *controller) RejectSuggestion(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid suggestion ID")
+	}
+
+	ctx := c.Request().Context()
+	suggestion, err := h.suggestionUsecase.Reject(ctx, id)
+	if err != nil {
+		return suggestionError(err)
+	}
+
+	return c.JSON(http.StatusOK, suggestion)
+}
+
+func suggestionError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "suggestion not found")
+	case errors.Is(err, models.ErrSuggestionDecided):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
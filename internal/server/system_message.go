@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// System message endpoints, for tenants overriding the canned messages the
+// bot sends to chats
+
+type SetSystemMessageRequest struct {
+	Text string `json:"text" validate:"required,max=1000"`
+}
+
+func (h *controller) ListSystemMessages(c echo.Context) error {
+	ctx := c.Request().Context()
+	messages, err := h.systemMessages.List(ctx)
+	if err != nil {
+		return systemMessageError(err)
+	}
+
+	return c.JSON(http.StatusOK, messages)
+}
+
+func (h *controller) SetSystemMessage(c echo.Context) error {
+	var req SetSystemMessageRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	key := models.SystemMessageKey(c.Param("key"))
+	override, err := h.systemMessages.SetOverride(ctx, key, c.Param("locale"), req.Text)
+	if err != nil {
+		return systemMessageError(err)
+	}
+
+	return c.JSON(http.StatusOK, override)
+}
+
+func (h *controller) DeleteSystemMessage(c echo.Context) error {
+	ctx := c.Request().Context()
+	key := models.SystemMessageKey(c.Param("key"))
+	if err := h.systemMessages.DeleteOverride(ctx, key, c.Param("locale")); err != nil {
+		return systemMessageError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func systemMessageError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "system message override not found")
+	case errors.Is(err, models.ErrUnknownSystemMessage), errors.Is(err, models.ErrInvalidSystemMessage):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Team endpoints, for shops sharing a seller's inbox between several agents
+
+type CreateTeamRequest struct {
+	SellerID string `json:"seller_id" validate:"required"`
+	Name     string `json:"name" validate:"required,max=100"`
+}
+
+func (h *controller) CreateTeam(c echo.Context) error {
+	var req CreateTeamRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	team, err := h.teamUsecase.CreateTeam(ctx, req.SellerID, req.Name)
+	if err != nil {
+		return teamError(err)
+	}
+
+	return c.JSON(http.StatusCreated, team)
+}
+
+func (h *controller) GetTeam(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team ID")
+	}
+
+	ctx := c.Request().Context()
+	team, err := h.teamUsecase.GetTeam(ctx, id)
+	if err != nil {
+		return teamError(err)
+	}
+
+	return c.JSON(http.StatusOK, team)
+}
+
+type AddTeamMemberRequest struct {
+	Role models.TeamRole `json:"role" validate:"required,oneof=owner agent"`
+}
+
+func (h *controller) AddTeamMember(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team ID")
+	}
+	userID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	var req AddTeamMemberRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	team, err := h.teamUsecase.AddMember(ctx, id, userID, req.Role)
+	if err != nil {
+		return teamError(err)
+	}
+
+	return c.JSON(http.StatusOK, team)
+}
+
+func (h *controller) RemoveTeamMember(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team ID")
+	}
+	userID, err := primitive.ObjectIDFromHex(c.Param("user_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid user ID")
+	}
+
+	ctx := c.Request().Context()
+	team, err := h.teamUsecase.RemoveMember(ctx, id, userID)
+	if err != nil {
+		return teamError(err)
+	}
+
+	return c.JSON(http.StatusOK, team)
+}
+
+type AssignChannelRequest struct {
+	AgentID string `json:"agent_id" validate:"required,objectid"`
+}
+
+func (h *controller) AssignChannel(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team ID")
+	}
+
+	var req AssignChannelRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+	agentID, _ := primitive.ObjectIDFromHex(req.AgentID) // checked by the objectid validation
+
+	ctx := c.Request().Context()
+	assignment, err := h.teamUsecase.AssignChannel(ctx, id, c.Param("channel_id"), agentID)
+	if err != nil {
+		return teamError(err)
+	}
+
+	return c.JSON(http.StatusOK, assignment)
+}
+
+func (h *controller) UnassignChannel(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.teamUsecase.UnassignChannel(ctx, id, c.Param("channel_id")); err != nil {
+		return teamError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *controller) ListChannelAssignments(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid team ID")
+	}
+
+	var agentID *primitive.ObjectID
+	if agentParam := c.QueryParam("agent_id"); agentParam != "" {
+		agent, err := primitive.ObjectIDFromHex(agentParam)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, "invalid agent_id")
+		}
+		agentID = &agent
+	}
+
+	ctx := c.Request().Context()
+	assignments, err := h.teamUsecase.ListAssignments(ctx, id, agentID)
+	if err != nil {
+		return teamError(err)
+	}
+
+	return c.JSON(http.StatusOK, assignments)
+}
+
+func teamError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "team or assignment not found")
+	case errors.Is(err, models.ErrTeamExists):
+		return echo.NewHTTPError(http.StatusConflict, err.Error())
+	case errors.Is(err, models.ErrNotTeamMember), errors.Is(err, models.ErrLastTeamOwner):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tenant admin endpoints
+
+type CreateTenantRequest struct {
+	Name     string                `json:"name" validate:"required"`
+	Settings models.TenantSettings `json:"settings"`
+}
+
+func (h *controller) CreateTenant(c echo.Context) error {
+	var req CreateTenantRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	tenant, err := h.tenantUsecase.CreateTenant(ctx, req.Name, req.Settings)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, tenant)
+}
+
+func (h *controller) GetTenant(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	ctx := c.Request().Context()
+	tenant, err := h.tenantUsecase.GetTenant(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+func (h *controller) UpdateTenantSettings(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	var settings models.TenantSettings
+	if err := c.Bind(&settings); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	tenant, err := h.tenantUsecase.UpdateTenantSettings(ctx, id, settings)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, tenant)
+}
+
+func (h *controller) GetToolPolicy(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	ctx := c.Request().Context()
+	tenant, err := h.tenantUsecase.GetTenant(ctx, id)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, tenant.Settings.ToolPolicy)
+}
+
+func (h *controller) UpdateToolPolicy(c echo.Context) error {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	var policy models.ToolPolicy
+	if err := c.Bind(&policy); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	ctx := c.Request().Context()
+	tenant, err := h.tenantUsecase.UpdateToolPolicy(ctx, id, policy)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+		case errors.Is(err, models.ErrUnknownTool):
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, tenant.Settings.ToolPolicy)
+}
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type CreateAPIKeyResponse struct {
+	*models.APIKey
+	// Key is only returned once, at creation time
+	Key string `json:"key"`
+}
+
+func (h *controller) CreateAPIKey(c echo.Context) error {
+	tenantID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+
+	var req CreateAPIKeyRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	key, rawKey, err := h.tenantUsecase.CreateAPIKey(ctx, tenantID, req.Name)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "tenant not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusCreated, CreateAPIKeyResponse{APIKey: key, Key: rawKey})
+}
+
+func (h *controller) RevokeAPIKey(c echo.Context) error {
+	tenantID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid tenant ID")
+	}
+	keyID, err := primitive.ObjectIDFromHex(c.Param("key_id"))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid api key ID")
+	}
+
+	ctx := c.Request().Context()
+	if err := h.tenantUsecase.RevokeAPIKey(ctx, tenantID, keyID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return echo.NewHTTPError(http.StatusNotFound, "active api key not found")
+		}
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"status":  "success",
+		"message": "api key revoked successfully",
+	})
+}
@@ -0,0 +1,29 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Test message endpoints
+
+func (h *controller) ListTestMessages(c echo.Context) error {
+	limit := 0
+	if limitParam := c.QueryParam("limit"); limitParam != "" {
+		var err error
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil || limit <= 0 || limit > 500 {
+			return echo.NewHTTPError(http.StatusBadRequest, "limit must be between 1 and 500")
+		}
+	}
+
+	ctx := c.Request().Context()
+	messages, err := h.testMessageUsecase.List(ctx, c.QueryParam("channel_id"), limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+
+	return c.JSON(http.StatusOK, messages)
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+)
+
+// Whitelist endpoints. Entries and the rollout are global and apply on top
+// of KAFKA_SELLER_WHITELIST.
+
+type AddWhitelistEntryRequest struct {
+	Note string `json:"note" validate:"max=256"`
+}
+
+type SetWhitelistRolloutRequest struct {
+	Percent *int `json:"percent" validate:"required,min=0,max=100"`
+}
+
+func (h *controller) GetWhitelist(c echo.Context) error {
+	ctx := c.Request().Context()
+	whitelist, err := h.whitelistService.Whitelist(ctx)
+	if err != nil {
+		return whitelistError(err)
+	}
+
+	return c.JSON(http.StatusOK, whitelist)
+}
+
+func (h *controller) AddWhitelistEntry(c echo.Context) error {
+	var req AddWhitelistEntryRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	entry, err := h.whitelistService.AddEntry(ctx, &models.WhitelistEntry{
+		Kind:  models.WhitelistKind(c.Param("kind")),
+		Value: c.Param("value"),
+		Note:  req.Note,
+	})
+	if err != nil {
+		return whitelistError(err)
+	}
+
+	return c.JSON(http.StatusOK, entry)
+}
+
+func (h *controller) RemoveWhitelistEntry(c echo.Context) error {
+	ctx := c.Request().Context()
+	if err := h.whitelistService.RemoveEntry(ctx, models.WhitelistKind(c.Param("kind")), c.Param("value")); err != nil {
+		return whitelistError(err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *controller) SetWhitelistRollout(c echo.Context) error {
+	var req SetWhitelistRolloutRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "invalid request body")
+	}
+
+	if err := c.Validate(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	ctx := c.Request().Context()
+	rollout, err := h.whitelistService.SetRollout(ctx, *req.Percent)
+	if err != nil {
+		return whitelistError(err)
+	}
+
+	return c.JSON(http.StatusOK, rollout)
+}
+
+func whitelistError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		return echo.NewHTTPError(http.StatusNotFound, "whitelist entry not found")
+	case errors.Is(err, models.ErrInvalidWhitelistEntry):
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	default:
+		return echo.NewHTTPError(http.StatusInternalServerError, err.Error())
+	}
+}
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultFeedPageSize = 20
+	// feedSummaryChars bounds the buyer message quoted in a feed item
+	feedSummaryChars = 200
+)
+
+// ActivityFeedUsecase keeps each seller's feed of noteworthy events from the
+// bot's chats: purchase intents, offers, hand offs and failed deliveries
+type ActivityFeedUsecase interface {
+	// Publish adds an event to its seller's feed. The feed is informational,
+	// so a failure is logged rather than failing the caller.
+	Publish(ctx context.Context, item *models.FeedItem)
+	List(ctx context.Context, filter models.FeedFilter) (*models.FeedPage, error)
+	// MarkSeen marks the given items of the seller seen, or all of them
+	// without IDs, and returns how many were unseen
+	MarkSeen(ctx context.Context, sellerID string, ids []primitive.ObjectID) (int64, error)
+}
+
+type activityFeedUsecase struct {
+	feedRepo  mongodb.ActivityFeedRepository
+	retention time.Duration
+}
+
+func NewActivityFeedUsecase(feedRepo mongodb.ActivityFeedRepository, conf *config.Config) ActivityFeedUsecase {
+	return &activityFeedUsecase{
+		feedRepo:  feedRepo,
+		retention: conf.ActivityFeed.Retention,
+	}
+}
+
+func (uc *activityFeedUsecase) Publish(ctx context.Context, item *models.FeedItem) {
+	if item.SellerID == "" {
+		return
+	}
+	if err := uc.feedRepo.Create(ctx, item, uc.retention); err != nil {
+		log.Errorw(ctx, "Failed to publish feed item", "type", item.Type, "seller_id", item.SellerID, "channel_id", item.ChannelID, "error", err)
+	}
+}
+
+func (uc *activityFeedUsecase) List(ctx context.Context, filter models.FeedFilter) (*models.FeedPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultFeedPageSize
+	}
+	// one more item tells whether there is a next page
+	filter.Limit = limit + 1
+	items, err := uc.feedRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	unseen, err := uc.feedRepo.CountUnseen(ctx, filter.SellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &models.FeedPage{Items: items, Unseen: unseen}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextBefore = page.Items[limit-1].ID.Hex()
+	}
+	return page, nil
+}
+
+func (uc *activityFeedUsecase) MarkSeen(ctx context.Context, sellerID string, ids []primitive.ObjectID) (int64, error) {
+	return uc.feedRepo.MarkSeen(ctx, sellerID, ids)
+}
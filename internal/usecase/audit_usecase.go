@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+type AuditUsecase interface {
+	// Record stores an audit entry for the actor of ctx. Failures are logged and
+	// never fail the audited operation. before/after may be nil.
+	Record(ctx context.Context, action models.AuditAction, resourceType, resourceID string, before, after any)
+	List(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error)
+}
+
+type auditUsecase struct {
+	auditLogRepo mongodb.AuditLogRepository
+}
+
+func NewAuditUsecase(auditLogRepo mongodb.AuditLogRepository) AuditUsecase {
+	return &auditUsecase{
+		auditLogRepo: auditLogRepo,
+	}
+}
+
+func (uc *auditUsecase) Record(ctx context.Context, action models.AuditAction, resourceType, resourceID string, before, after any) {
+	entry := &models.AuditLog{
+		Actor:        models.ActorFromContext(ctx),
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Before:       snapshot(ctx, before),
+		After:        snapshot(ctx, after),
+	}
+
+	if err := uc.auditLogRepo.Create(ctx, entry); err != nil {
+		log.Errorw(ctx, "Failed to record audit log", "action", action, "resource_id", resourceID, "error", err)
+	}
+}
+
+func (uc *auditUsecase) List(ctx context.Context, filter models.AuditLogFilter) ([]*models.AuditLog, error) {
+	entries, err := uc.auditLogRepo.List(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return entries, nil
+}
+
+// snapshot converts v to its API (JSON) shape, which drops fields tagged
+// json:"-" such as key hashes and encrypted secrets
+func snapshot(ctx context.Context, v any) map[string]any {
+	if v == nil {
+		return nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Warnw(ctx, "Failed to snapshot audited resource", "error", err)
+		return nil
+	}
+	var out map[string]any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil
+	}
+	return out
+}
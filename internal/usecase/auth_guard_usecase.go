@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/ttlcache"
+)
+
+// authGuardCacheTTL bounds how long an instance trusts its view of a
+// lockout, so unlocks and lockouts by other instances apply quickly
+const authGuardCacheTTL = 10 * time.Second
+
+// AuthGuardUsecase protects credential checks against brute force by
+// counting failures per client address and per account, and locking them
+// out once there are too many
+type AuthGuardUsecase interface {
+	// Check returns ErrAuthLocked while the address or account is locked out
+	Check(ctx context.Context, scope models.AuthScope, key string) error
+	// RequireCaptcha returns ErrCaptchaRequired once the key failed
+	// AUTH_GUARD_CAPTCHA_AFTER times in the window, unless captchaToken
+	// passes the captcha verifier
+	RequireCaptcha(ctx context.Context, scope models.AuthScope, key, captchaToken string) error
+	// Fail counts a failed attempt, locking the key out when it reaches
+	// the limit. Errors are logged, a guard outage never blocks callers.
+	Fail(ctx context.Context, scope models.AuthScope, key string)
+	// Reset clears the failures of key after a successful attempt
+	Reset(ctx context.Context, scope models.AuthScope, key string)
+
+	ListLocked(ctx context.Context) ([]*models.AuthFailure, error)
+	Unlock(ctx context.Context, scope models.AuthScope, key string) error
+}
+
+// CaptchaVerifier checks the captcha answer a client sends after repeated
+// failures. Provide one through fx.Decorate to enable captchas.
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, token string) (bool, error)
+}
+
+type authGuardUsecase struct {
+	repo         mongodb.AuthFailureRepository
+	auditUsecase AuditUsecase
+	captcha      CaptchaVerifier
+	conf         config.AuthGuardConfig
+	failures     *ttlcache.Cache[string, *models.AuthFailure]
+}
+
+func NewAuthGuardUsecase(repo mongodb.AuthFailureRepository, auditUsecase AuditUsecase, captcha CaptchaVerifier, conf *config.Config) AuthGuardUsecase {
+	return &authGuardUsecase{
+		repo:         repo,
+		auditUsecase: auditUsecase,
+		captcha:      captcha,
+		conf:         conf.AuthGuard,
+		failures:     ttlcache.New[string, *models.AuthFailure](authGuardCacheTTL),
+	}
+}
+
+// NewCaptchaVerifier is the default verifier, which rejects every answer.
+// It only matters once AUTH_GUARD_CAPTCHA_AFTER is set.
+func NewCaptchaVerifier() CaptchaVerifier {
+	return noCaptchaVerifier{}
+}
+
+type noCaptchaVerifier struct{}
+
+func (noCaptchaVerifier) Verify(ctx context.Context, token string) (bool, error) {
+	return false, fmt.Errorf("no captcha verifier is configured")
+}
+
+func authGuardCacheKey(scope models.AuthScope, key string) string {
+	return string(scope) + ":" + key
+}
+
+// get returns the failures of key, nil when there are none
+func (uc *authGuardUsecase) get(ctx context.Context, scope models.AuthScope, key string) (*models.AuthFailure, error) {
+	cacheKey := authGuardCacheKey(scope, key)
+	if failure, ok := uc.failures.Get(cacheKey); ok {
+		return failure, nil
+	}
+	failure, err := uc.repo.Get(ctx, scope, key)
+	if err != nil {
+		return nil, err
+	}
+	uc.failures.Set(cacheKey, failure)
+	return failure, nil
+}
+
+func (uc *authGuardUsecase) Check(ctx context.Context, scope models.AuthScope, key string) error {
+	failure, err := uc.get(ctx, scope, key)
+	if err != nil {
+		log.Errorw(ctx, "Failed to check auth lockout", "scope", scope, "key", key, "error", err)
+		return nil
+	}
+	if failure.Locked(time.Now()) {
+		return fmt.Errorf("%w until %s", models.ErrAuthLocked, failure.LockedUntil.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (uc *authGuardUsecase) RequireCaptcha(ctx context.Context, scope models.AuthScope, key, captchaToken string) error {
+	if uc.conf.CaptchaAfter <= 0 {
+		return nil
+	}
+	failure, err := uc.get(ctx, scope, key)
+	if err != nil {
+		log.Errorw(ctx, "Failed to check auth failures", "scope", scope, "key", key, "error", err)
+		return nil
+	}
+	if failure == nil || failure.Failures < uc.conf.CaptchaAfter || time.Since(failure.WindowStart) > uc.conf.Window {
+		return nil
+	}
+
+	if captchaToken != "" {
+		ok, err := uc.captcha.Verify(ctx, captchaToken)
+		if err != nil {
+			log.Errorw(ctx, "Failed to verify captcha", "scope", scope, "key", key, "error", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return models.ErrCaptchaRequired
+}
+
+func (uc *authGuardUsecase) Fail(ctx context.Context, scope models.AuthScope, key string) {
+	ctx = context.WithoutCancel(ctx)
+	now := time.Now()
+	failure, err := uc.repo.RecordFailure(ctx, scope, key, now.Add(-uc.conf.Window), now.Add(uc.conf.Window))
+	if err != nil {
+		log.Errorw(ctx, "Failed to record auth failure", "scope", scope, "key", key, "error", err)
+		return
+	}
+
+	if failure.Failures >= uc.conf.MaxFailures && !failure.Locked(now) {
+		until := now.Add(uc.conf.Lockout)
+		if err := uc.repo.Lock(ctx, scope, key, until); err != nil {
+			log.Errorw(ctx, "Failed to lock out", "scope", scope, "key", key, "error", err)
+			return
+		}
+		failure.LockedUntil = &until
+		log.Warnw(ctx, "Locked out after repeated auth failures", "scope", scope, "key", key, "failures", failure.Failures, "locked_until", until)
+		uc.auditUsecase.Record(ctx, models.AuditAuthLockout, "auth_"+string(scope), key, nil, failure)
+	}
+	uc.failures.Set(authGuardCacheKey(scope, key), failure)
+}
+
+func (uc *authGuardUsecase) Reset(ctx context.Context, scope models.AuthScope, key string) {
+	failure, err := uc.get(ctx, scope, key)
+	if err != nil || failure == nil {
+		return
+	}
+	if err := uc.repo.Delete(context.WithoutCancel(ctx), scope, key); err != nil {
+		log.Errorw(ctx, "Failed to reset auth failures", "scope", scope, "key", key, "error", err)
+		return
+	}
+	uc.failures.Delete(authGuardCacheKey(scope, key))
+}
+
+func (uc *authGuardUsecase) ListLocked(ctx context.Context) ([]*models.AuthFailure, error) {
+	return uc.repo.ListLocked(ctx, time.Now())
+}
+
+func (uc *authGuardUsecase) Unlock(ctx context.Context, scope models.AuthScope, key string) error {
+	before, err := uc.repo.Get(ctx, scope, key)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.ErrNotFound
+	}
+
+	if err := uc.repo.Delete(ctx, scope, key); err != nil {
+		return err
+	}
+	uc.failures.Delete(authGuardCacheKey(scope, key))
+	uc.auditUsecase.Record(ctx, models.AuditAuthUnlock, "auth_"+string(scope), key, before, nil)
+	return nil
+}
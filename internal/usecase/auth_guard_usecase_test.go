@@ -0,0 +1,248 @@
+package usecase_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthFailureRepo keeps failures in memory the way the mongo repository
+// does, handing out copies and counting reads
+type fakeAuthFailureRepo struct {
+	mongodb.AuthFailureRepository
+	mu       sync.Mutex
+	failures map[string]models.AuthFailure
+	gets     int
+}
+
+func newFakeAuthFailureRepo() *fakeAuthFailureRepo {
+	return &fakeAuthFailureRepo{failures: map[string]models.AuthFailure{}}
+}
+
+func (r *fakeAuthFailureRepo) RecordFailure(ctx context.Context, scope models.AuthScope, key string, windowStart time.Time, expiresAt time.Time) (*models.AuthFailure, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	failure, ok := r.failures[string(scope)+":"+key]
+	if !ok || failure.WindowStart.Before(windowStart) {
+		failure = models.AuthFailure{Scope: scope, Key: key, WindowStart: now, LockedUntil: failure.LockedUntil}
+	}
+	failure.Failures++
+	failure.LastFailureAt = now
+	if expiresAt.After(failure.ExpiresAt) {
+		failure.ExpiresAt = expiresAt
+	}
+	r.failures[string(scope)+":"+key] = failure
+	return &failure, nil
+}
+
+func (r *fakeAuthFailureRepo) Lock(ctx context.Context, scope models.AuthScope, key string, until time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failure := r.failures[string(scope)+":"+key]
+	failure.LockedUntil = &until
+	r.failures[string(scope)+":"+key] = failure
+	return nil
+}
+
+func (r *fakeAuthFailureRepo) Get(ctx context.Context, scope models.AuthScope, key string) (*models.AuthFailure, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gets++
+	failure, ok := r.failures[string(scope)+":"+key]
+	if !ok {
+		return nil, nil
+	}
+	return &failure, nil
+}
+
+func (r *fakeAuthFailureRepo) Delete(ctx context.Context, scope models.AuthScope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, string(scope)+":"+key)
+	return nil
+}
+
+func (r *fakeAuthFailureRepo) getCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.gets
+}
+
+type fakeLockoutAudit struct {
+	usecase.AuditUsecase
+	mu       sync.Mutex
+	lockouts []string
+}
+
+func (a *fakeLockoutAudit) Record(ctx context.Context, action models.AuditAction, resourceType, resourceID string, before, after any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if action == models.AuditAuthLockout {
+		a.lockouts = append(a.lockouts, resourceType+":"+resourceID)
+	}
+}
+
+func newAuthGuard(repo mongodb.AuthFailureRepository, audit usecase.AuditUsecase, lockout time.Duration) usecase.AuthGuardUsecase {
+	conf := &config.Config{AuthGuard: config.AuthGuardConfig{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		Lockout:     lockout,
+	}}
+	return usecase.NewAuthGuardUsecase(repo, audit, usecase.NewCaptchaVerifier(), conf)
+}
+
+func TestAuthGuard(t *testing.T) {
+	t.Parallel()
+
+	type attempt struct {
+		scope models.AuthScope
+		key   string
+	}
+	ip := attempt{models.AuthScopeIP, "203.0.113.7"}
+	account := attempt{models.AuthScopeAccount, "user-1"}
+	// the same key in the other scope
+	ipNamedLikeAccount := attempt{models.AuthScopeIP, "user-1"}
+
+	tests := []struct {
+		name    string
+		lockout time.Duration
+		fails   []attempt
+		// wait passes before the checks
+		wait         time.Duration
+		locked       []attempt
+		unlocked     []attempt
+		wantLockouts []string
+	}{
+		{
+			name:     "Below The Threshold",
+			lockout:  time.Minute,
+			fails:    []attempt{ip, ip},
+			unlocked: []attempt{ip},
+		},
+		{
+			name:         "Locks At The Threshold",
+			lockout:      time.Minute,
+			fails:        []attempt{ip, ip, ip},
+			locked:       []attempt{ip},
+			wantLockouts: []string{"auth_ip:203.0.113.7"},
+		},
+		{
+			name:         "Locks Once Past The Threshold",
+			lockout:      time.Minute,
+			fails:        []attempt{ip, ip, ip, ip, ip},
+			locked:       []attempt{ip},
+			wantLockouts: []string{"auth_ip:203.0.113.7"},
+		},
+		{
+			name:         "Lockout Expires",
+			lockout:      20 * time.Millisecond,
+			fails:        []attempt{account, account, account},
+			wait:         50 * time.Millisecond,
+			unlocked:     []attempt{account},
+			wantLockouts: []string{"auth_account:user-1"},
+		},
+		{
+			name:         "Scopes Are Counted Apart",
+			lockout:      time.Minute,
+			fails:        []attempt{ip, account, ip, account, ip, ipNamedLikeAccount},
+			locked:       []attempt{ip},
+			unlocked:     []attempt{account, ipNamedLikeAccount},
+			wantLockouts: []string{"auth_ip:203.0.113.7"},
+		},
+		{
+			name:         "Account Locks Without The Address",
+			lockout:      time.Minute,
+			fails:        []attempt{account, account, account},
+			locked:       []attempt{account},
+			unlocked:     []attempt{ip, ipNamedLikeAccount},
+			wantLockouts: []string{"auth_account:user-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx := context.Background()
+			audit := &fakeLockoutAudit{}
+			guard := newAuthGuard(newFakeAuthFailureRepo(), audit, tt.lockout)
+
+			for _, a := range tt.fails {
+				guard.Fail(ctx, a.scope, a.key)
+			}
+			time.Sleep(tt.wait)
+
+			for _, a := range tt.locked {
+				assert.ErrorIs(t, guard.Check(ctx, a.scope, a.key), models.ErrAuthLocked, "%s %s", a.scope, a.key)
+			}
+			for _, a := range tt.unlocked {
+				assert.NoError(t, guard.Check(ctx, a.scope, a.key), "%s %s", a.scope, a.key)
+			}
+			assert.Equal(t, tt.wantLockouts, audit.lockouts)
+		})
+	}
+}
+
+func TestAuthGuardCache(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	key := "203.0.113.7"
+
+	t.Run("Check After Fail Is Served From The Cache", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeAuthFailureRepo()
+		guard := newAuthGuard(repo, &fakeLockoutAudit{}, time.Minute)
+
+		for range 3 {
+			guard.Fail(ctx, models.AuthScopeIP, key)
+		}
+		assert.ErrorIs(t, guard.Check(ctx, models.AuthScopeIP, key), models.ErrAuthLocked)
+		assert.Zero(t, repo.getCount())
+	})
+
+	t.Run("Unlock By Another Instance Waits For The Cache", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeAuthFailureRepo()
+		guard := newAuthGuard(repo, &fakeLockoutAudit{}, time.Minute)
+
+		for range 3 {
+			guard.Fail(ctx, models.AuthScopeIP, key)
+		}
+		// another instance lifts the lockout in the shared store
+		require.NoError(t, repo.Delete(ctx, models.AuthScopeIP, key))
+		assert.ErrorIs(t, guard.Check(ctx, models.AuthScopeIP, key), models.ErrAuthLocked)
+		assert.Zero(t, repo.getCount())
+	})
+
+	t.Run("Unlock On This Instance Applies At Once", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeAuthFailureRepo()
+		guard := newAuthGuard(repo, &fakeLockoutAudit{}, time.Minute)
+
+		for range 3 {
+			guard.Fail(ctx, models.AuthScopeIP, key)
+		}
+		require.NoError(t, guard.Unlock(ctx, models.AuthScopeIP, key))
+		assert.NoError(t, guard.Check(ctx, models.AuthScopeIP, key))
+	})
+
+	t.Run("Fail Replaces A Cached Miss", func(t *testing.T) {
+		t.Parallel()
+		repo := newFakeAuthFailureRepo()
+		guard := newAuthGuard(repo, &fakeLockoutAudit{}, time.Minute)
+
+		require.NoError(t, guard.Check(ctx, models.AuthScopeIP, key))
+		for range 3 {
+			guard.Fail(ctx, models.AuthScopeIP, key)
+		}
+		assert.ErrorIs(t, guard.Check(ctx, models.AuthScopeIP, key), models.ErrAuthLocked)
+		assert.Equal(t, 1, repo.getCount())
+	})
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chotot"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/storage"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/imagex"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	avatarSize    = 256
+	avatarQuality = 85
+)
+
+type AvatarUsecase interface {
+	// UploadAvatar resizes the image read from r and stores it as the user's avatar
+	UploadAvatar(ctx context.Context, userID primitive.ObjectID, r io.Reader) (*models.User, error)
+	// SyncPartnerAvatar copies the avatar of the user's linked chotot account, returning
+	// models.ErrAvatarUnavailable when the user is not linked or has no avatar there
+	SyncPartnerAvatar(ctx context.Context, userID primitive.ObjectID) (*models.User, error)
+}
+
+type avatarUsecase struct {
+	userRepo          mongodb.UserRepository
+	userAttributeRepo mongodb.UserAttributeRepository
+	storage           storage.Storage
+	chototClient      chotot.Client
+	auditUsecase      AuditUsecase
+}
+
+func NewAvatarUsecase(
+	userRepo mongodb.UserRepository,
+	userAttributeRepo mongodb.UserAttributeRepository,
+	mediaStorage storage.Storage,
+	chototClient chotot.Client,
+	auditUsecase AuditUsecase,
+) AvatarUsecase {
+	return &avatarUsecase{
+		userRepo:          userRepo,
+		userAttributeRepo: userAttributeRepo,
+		storage:           mediaStorage,
+		chototClient:      chototClient,
+		auditUsecase:      auditUsecase,
+	}
+}
+
+func (uc *avatarUsecase) UploadAvatar(ctx context.Context, userID primitive.ObjectID, r io.Reader) (*models.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	data, err := imagex.Fit(r, avatarSize, avatarQuality)
+	if err != nil {
+		if errors.Is(err, imagex.ErrUnsupportedFormat) {
+			return nil, models.ErrInvalidImage
+		}
+		return nil, fmt.Errorf("failed to process avatar: %w", err)
+	}
+
+	// a new key per upload keeps CDN and browser caches from serving the old image
+	key := fmt.Sprintf("avatars/%s/%d.jpg", user.ID.Hex(), time.Now().UnixMilli())
+	url, err := uc.storage.Put(ctx, key, "image/jpeg", data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store avatar: %w", err)
+	}
+
+	return uc.setAvatarURL(ctx, user, url)
+}
+
+func (uc *avatarUsecase) SyncPartnerAvatar(ctx context.Context, userID primitive.ObjectID) (*models.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	attr, err := uc.userAttributeRepo.GetByUserIDAndKey(ctx, userID, models.AttributeChototOID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chotot account: %w", err)
+	}
+	if attr == nil {
+		return nil, models.ErrAvatarUnavailable
+	}
+
+	profile, err := uc.chototClient.GetProfile(ctx, attr.Value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chotot profile: %w", err)
+	}
+	if profile.Avatar == "" {
+		return nil, models.ErrAvatarUnavailable
+	}
+	if profile.Avatar == user.AvatarURL {
+		return user, nil
+	}
+
+	return uc.setAvatarURL(ctx, user, profile.Avatar)
+}
+
+func (uc *avatarUsecase) setAvatarURL(ctx context.Context, user *models.User, url string) (*models.User, error) {
+	before := *user
+	user.AvatarURL = url
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditUserAvatarUpdate, "user", user.ID.Hex(), &before, user)
+	return user, nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BackupUsecase moves a tenant's data between environments, e.g. to reproduce
+// a support case on staging. Conversation history stays in chat-api and is
+// not part of the archive.
+type BackupUsecase interface {
+	// Export writes the tenant's archive to w; it returns models.ErrNotFound
+	// before writing anything when the tenant does not exist
+	Export(ctx context.Context, tenantID primitive.ObjectID, w io.Writer) (*models.BackupManifest, error)
+	Restore(ctx context.Context, r io.Reader) (*models.BackupManifest, error)
+}
+
+type backupUsecase struct {
+	backupRepo    mongodb.BackupRepository
+	tenantUsecase TenantUsecase
+	auditUsecase  AuditUsecase
+}
+
+func NewBackupUsecase(
+	backupRepo mongodb.BackupRepository,
+	tenantUsecase TenantUsecase,
+	auditUsecase AuditUsecase,
+) BackupUsecase {
+	return &backupUsecase{
+		backupRepo:    backupRepo,
+		tenantUsecase: tenantUsecase,
+		auditUsecase:  auditUsecase,
+	}
+}
+
+func (uc *backupUsecase) Export(ctx context.Context, tenantID primitive.ObjectID, w io.Writer) (*models.BackupManifest, error) {
+	if _, err := uc.tenantUsecase.GetTenant(ctx, tenantID); err != nil {
+		return nil, err
+	}
+
+	manifest, err := uc.backupRepo.Export(ctx, tenantID, w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to export tenant: %w", err)
+	}
+
+	uc.auditUsecase.Record(models.WithTenantID(ctx, tenantID), models.AuditTenantExport, "tenant", tenantID.Hex(), nil, manifest)
+	return manifest, nil
+}
+
+func (uc *backupUsecase) Restore(ctx context.Context, r io.Reader) (*models.BackupManifest, error) {
+	manifest, err := uc.backupRepo.Restore(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore tenant: %w", err)
+	}
+
+	uc.auditUsecase.Record(models.WithTenantID(ctx, manifest.TenantID), models.AuditTenantRestore, "tenant", manifest.TenantID.Hex(), nil, manifest)
+	return manifest, nil
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BudgetUsecase caps what the bot spends per channel and per session: the
+// replies sent in a channel each day and the LLM tokens of a session. Once a
+// cap is reached the bot stops and, with BUDGET_NOTIFY, tells the channel the
+// seller takes over.
+type BudgetUsecase interface {
+	// CheckChannel returns models.ErrBudgetExceeded once the channel got its
+	// replies for the day
+	CheckChannel(ctx context.Context, channelID, sellerID string) error
+	// RecordReply counts a reply sent to the channel
+	RecordReply(ctx context.Context, channelID string) error
+	// RecordUsage adds the usage of a generation to the session and its
+	// channel, reporting whether the session reached its token cap
+	RecordUsage(ctx context.Context, sessionID primitive.ObjectID, channelID, sellerID string, usage models.SessionUsage) (bool, error)
+	GetSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error)
+	GetChannelBudget(ctx context.Context, channelID string) (*models.ChannelBudgetReport, error)
+}
+
+type budgetUsecase struct {
+	conf              config.BudgetConfig
+	sessionRepo       mongodb.ChatSessionRepository
+	channelBudgetRepo mongodb.ChannelBudgetRepository
+	chatAPIClient     chatapi.Client
+	systemMessages    SystemMessageUsecase
+}
+
+func NewBudgetUsecase(
+	conf *config.Config,
+	sessionRepo mongodb.ChatSessionRepository,
+	channelBudgetRepo mongodb.ChannelBudgetRepository,
+	chatAPIClient chatapi.Client,
+	systemMessages SystemMessageUsecase,
+) BudgetUsecase {
+	return &budgetUsecase{
+		conf:              conf.Budget,
+		sessionRepo:       sessionRepo,
+		channelBudgetRepo: channelBudgetRepo,
+		chatAPIClient:     chatAPIClient,
+		systemMessages:    systemMessages,
+	}
+}
+
+func (uc *budgetUsecase) CheckChannel(ctx context.Context, channelID, sellerID string) error {
+	if uc.conf.MaxRepliesPerDay <= 0 {
+		return nil
+	}
+
+	day := models.BudgetDay(time.Now())
+	budget, err := uc.channelBudgetRepo.Get(ctx, channelID, day)
+	if err != nil {
+		return err
+	}
+	if budget == nil || budget.Replies < uc.conf.MaxRepliesPerDay {
+		return nil
+	}
+
+	uc.stop(ctx, channelID, sellerID, models.BudgetLimitDailyReplies)
+	return models.ErrBudgetExceeded
+}
+
+func (uc *budgetUsecase) RecordReply(ctx context.Context, channelID string) error {
+	return uc.channelBudgetRepo.Add(ctx, channelID, models.BudgetDay(time.Now()), 1, 0, uc.conf.Retention)
+}
+
+func (uc *budgetUsecase) RecordUsage(ctx context.Context, sessionID primitive.ObjectID, channelID, sellerID string, usage models.SessionUsage) (bool, error) {
+	if err := uc.channelBudgetRepo.Add(ctx, channelID, models.BudgetDay(time.Now()), 0, usage.TotalTokens(), uc.conf.Retention); err != nil {
+		return false, err
+	}
+
+	session, err := uc.sessionRepo.AddUsage(ctx, sessionID, usage)
+	if err != nil {
+		return false, err
+	}
+	if uc.conf.MaxTokensPerSession <= 0 || session.Usage.TotalTokens() < uc.conf.MaxTokensPerSession {
+		return false, nil
+	}
+
+	if err := uc.sessionRepo.SetBudgetExceeded(ctx, sessionID, models.BudgetLimitSessionTokens); err != nil {
+		return true, err
+	}
+	uc.stop(ctx, channelID, sellerID, models.BudgetLimitSessionTokens)
+	return true, nil
+}
+
+// stop records that the bot stopped in the channel for limit and posts the
+// notice the first time it happens on a day. Failures are logged, the bot
+// stays stopped either way.
+func (uc *budgetUsecase) stop(ctx context.Context, channelID, sellerID string, limit models.BudgetLimit) {
+	day := models.BudgetDay(time.Now())
+	first, err := uc.channelBudgetRepo.MarkExceeded(ctx, channelID, day, limit, uc.conf.Retention)
+	if err != nil {
+		log.Errorw(ctx, "Failed to mark channel budget exceeded", "channel_id", channelID, "error", err)
+		return
+	}
+	if !first {
+		return
+	}
+	log.Warnw(ctx, "Bot budget exceeded, stopping in channel", "channel_id", channelID, "seller_id", sellerID, "limit", limit)
+
+	if !uc.conf.Notify {
+		return
+	}
+	err = uc.chatAPIClient.SendMessage(ctx, &models.OutgoingMessage{
+		ChannelID: channelID,
+		SenderID:  sellerID,
+		Message:   uc.systemMessages.ForChannel(ctx, models.SystemMessageBudgetPaused, channelID, ""),
+	})
+	if err != nil {
+		log.Errorw(ctx, "Failed to post budget notice", "channel_id", channelID, "error", err)
+		return
+	}
+	if err := uc.channelBudgetRepo.SetNotified(ctx, channelID, day); err != nil {
+		log.Errorw(ctx, "Failed to record budget notice", "channel_id", channelID, "error", err)
+	}
+}
+
+func (uc *budgetUsecase) GetSession(ctx context.Context, id primitive.ObjectID) (*models.ChatSession, error) {
+	return uc.sessionRepo.GetByID(ctx, id)
+}
+
+func (uc *budgetUsecase) GetChannelBudget(ctx context.Context, channelID string) (*models.ChannelBudgetReport, error) {
+	budget, err := uc.channelBudgetRepo.Get(ctx, channelID, models.BudgetDay(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	return &models.ChannelBudgetReport{
+		ChannelID:           channelID,
+		Today:               budget,
+		MaxRepliesPerDay:    uc.conf.MaxRepliesPerDay,
+		MaxTokensPerSession: uc.conf.MaxTokensPerSession,
+	}, nil
+}
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// BulkUsecase runs admin operations over many channels and sessions at once,
+// as jobs. Without a tenant in ctx, they apply to every tenant.
+type BulkUsecase interface {
+	// AbandonInactive marks the active sessions idle for inactiveFor as abandoned
+	AbandonInactive(ctx context.Context, inactiveFor time.Duration, progress JobReporter) error
+	// ReassignSellerChatMode pins chatMode on every channel the seller has
+	// sessions in, within the seller's tenant
+	ReassignSellerChatMode(ctx context.Context, sellerID, chatMode string, progress JobReporter) error
+}
+
+type bulkUsecase struct {
+	sessionRepo    mongodb.ChatSessionRepository
+	chatModeRepo   mongodb.ChatModeRepository
+	channelUsecase ChannelUsecase
+	identityMapper IdentityMapper
+	userUsecase    UserUsecase
+}
+
+func NewBulkUsecase(
+	sessionRepo mongodb.ChatSessionRepository,
+	chatModeRepo mongodb.ChatModeRepository,
+	channelUsecase ChannelUsecase,
+	identityMapper IdentityMapper,
+	userUsecase UserUsecase,
+) BulkUsecase {
+	return &bulkUsecase{
+		sessionRepo:    sessionRepo,
+		chatModeRepo:   chatModeRepo,
+		channelUsecase: channelUsecase,
+		identityMapper: identityMapper,
+		userUsecase:    userUsecase,
+	}
+}
+
+func (uc *bulkUsecase) AbandonInactive(ctx context.Context, inactiveFor time.Duration, progress JobReporter) error {
+	abandoned, err := uc.sessionRepo.AbandonInactive(ctx, time.Now().Add(-inactiveFor))
+	if err != nil {
+		return err
+	}
+
+	progress.SetTotal(abandoned)
+	progress.Add(abandoned, 0)
+	log.Infow(ctx, "Abandoned inactive sessions", "inactive_for", inactiveFor.String(), "abandoned", abandoned)
+	return nil
+}
+
+func (uc *bulkUsecase) ReassignSellerChatMode(ctx context.Context, sellerID, chatMode string, progress JobReporter) error {
+	if _, err := uc.chatModeRepo.GetByName(ctx, chatMode); err != nil {
+		return fmt.Errorf("%w: %v", models.ErrNotFound, err)
+	}
+
+	ctx, err := uc.sellerContext(ctx, sellerID)
+	if err != nil {
+		return err
+	}
+	channelIDs, err := uc.sessionRepo.ListChannelsBySeller(ctx, sellerID)
+	if err != nil {
+		return err
+	}
+
+	progress.SetTotal(int64(len(channelIDs)))
+	for _, channelID := range channelIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if _, err := uc.channelUsecase.SetChatMode(ctx, channelID, chatMode); err != nil {
+			progress.Fail(fmt.Errorf("channel %s: %w", channelID, err))
+			continue
+		}
+		progress.Add(1, 0)
+	}
+
+	log.Infow(ctx, "Reassigned seller chat mode", "seller_id", sellerID, "chat_mode", chatMode, "channels", len(channelIDs))
+	return nil
+}
+
+// sellerContext scopes ctx to the tenant owning the seller, as incoming
+// messages are, unless ctx has a tenant already
+func (uc *bulkUsecase) sellerContext(ctx context.Context, sellerID string) (context.Context, error) {
+	if _, ok := models.TenantIDFromContext(ctx); ok {
+		return ctx, nil
+	}
+
+	userID, err := uc.identityMapper.ResolveExternalID(ctx, models.PartnerChotot, sellerID)
+	if err != nil || userID == nil {
+		return ctx, err
+	}
+	user, err := uc.userUsecase.GetUser(ctx, *userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TenantID == nil {
+		return ctx, nil
+	}
+	return models.WithTenantID(ctx, *user.TenantID), nil
+}
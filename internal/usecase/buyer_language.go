@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+// buyerLanguageSessionLimit bounds the sessions read to find a buyer's
+// dominant language
+const buyerLanguageSessionLimit = 20
+
+// BuyerLanguageTracker aggregates the languages detected on a buyer's messages
+// into the language they mostly write in
+type BuyerLanguageTracker interface {
+	// Track returns the dominant language of the buyer's latest sessions with
+	// the seller and of the current message, and stores it on the buyer's
+	// linked user. Errors are logged and fall back to the current message's
+	// language.
+	Track(ctx context.Context, buyerID, sellerID, language string) string
+}
+
+type buyerLanguageTracker struct {
+	sessionRepo    mongodb.ChatSessionRepository
+	identityMapper IdentityMapper
+	userUsecase    UserUsecase
+}
+
+func NewBuyerLanguageTracker(
+	sessionRepo mongodb.ChatSessionRepository,
+	identityMapper IdentityMapper,
+	userUsecase UserUsecase,
+) BuyerLanguageTracker {
+	return &buyerLanguageTracker{
+		sessionRepo:    sessionRepo,
+		identityMapper: identityMapper,
+		userUsecase:    userUsecase,
+	}
+}
+
+func (t *buyerLanguageTracker) Track(ctx context.Context, buyerID, sellerID, language string) string {
+	sessions, err := t.sessionRepo.ListByBuyerAndSeller(ctx, buyerID, sellerID, buyerLanguageSessionLimit)
+	if err != nil {
+		log.Warnw(ctx, "Failed to list sessions for buyer language", "buyer_id", buyerID, "seller_id", sellerID, "error", err)
+		return language
+	}
+
+	// The current message comes first, so it wins ties
+	languages := []string{language}
+	for _, session := range sessions {
+		languages = append(languages, session.Language)
+	}
+	dominant := textx.DominantLanguage(languages...)
+	if dominant == "" {
+		return ""
+	}
+
+	if err := t.store(ctx, buyerID, dominant); err != nil {
+		log.Warnw(ctx, "Failed to store buyer language", "buyer_id", buyerID, "error", err)
+	}
+	return dominant
+}
+
+// store sets the language attribute of the user linked to the buyer, if any,
+// when it changed
+func (t *buyerLanguageTracker) store(ctx context.Context, buyerID, language string) error {
+	userID, err := t.identityMapper.ResolveExternalID(ctx, models.PartnerChotot, buyerID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve buyer: %w", err)
+	}
+	if userID == nil {
+		return nil
+	}
+
+	current, err := t.userUsecase.GetUserAttributeByKey(ctx, *userID, models.AttributeBuyerLanguage)
+	if err != nil {
+		return err
+	}
+	if current != nil && current.Value == language {
+		return nil
+	}
+	return t.userUsecase.SetUserAttribute(ctx, *userID, models.AttributeBuyerLanguage, language, nil)
+}
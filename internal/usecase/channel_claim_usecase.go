@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChannelClaimUsecase tracks which team agent is handling a room. Agents claim
+// a room while they type in it; the claim is released when they stop for
+// CHANNEL_CLAIM_INACTIVITY, and the bot does not answer claimed rooms.
+type ChannelClaimUsecase interface {
+	// Claim claims the room for the calling agent, or extends their claim.
+	// When another agent holds the room it returns their claim with
+	// models.ErrChannelClaimed, unless override takes the room from them.
+	Claim(ctx context.Context, channelID string, override bool) (*models.ChannelClaim, error)
+	// Get returns the room's active claim, or nil when there is none
+	Get(ctx context.Context, channelID string) (*models.ChannelClaim, error)
+	// Release releases the calling agent's claim on the room
+	Release(ctx context.Context, channelID string) error
+}
+
+type channelClaimUsecase struct {
+	claimRepo     mongodb.ChannelClaimRepository
+	teamRepo      mongodb.TeamRepository
+	chatAPIClient chatapi.Client
+	feedUsecase   ActivityFeedUsecase
+	auditUsecase  AuditUsecase
+	inactivity    time.Duration
+}
+
+func NewChannelClaimUsecase(
+	claimRepo mongodb.ChannelClaimRepository,
+	teamRepo mongodb.TeamRepository,
+	chatAPIClient chatapi.Client,
+	feedUsecase ActivityFeedUsecase,
+	auditUsecase AuditUsecase,
+	conf *config.Config,
+) ChannelClaimUsecase {
+	return &channelClaimUsecase{
+		claimRepo:     claimRepo,
+		teamRepo:      teamRepo,
+		chatAPIClient: chatAPIClient,
+		feedUsecase:   feedUsecase,
+		auditUsecase:  auditUsecase,
+		inactivity:    conf.ChannelClaim.Inactivity,
+	}
+}
+
+func (uc *channelClaimUsecase) Claim(ctx context.Context, channelID string, override bool) (*models.ChannelClaim, error) {
+	agentID, sellerID, err := uc.agent(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	claim := &models.ChannelClaim{
+		SellerID:  sellerID,
+		ChannelID: channelID,
+		AgentID:   agentID,
+	}
+	previous, err := uc.claimRepo.Claim(ctx, claim, uc.inactivity, override)
+	if errors.Is(err, models.ErrChannelClaimed) {
+		current, getErr := uc.claimRepo.Get(ctx, channelID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return current, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// a claim the agent extended keeps when it was first claimed
+	if !claim.ClaimedAt.Equal(claim.LastActiveAt) {
+		return claim, nil
+	}
+
+	data := map[string]any{"claimed_by": agentID.Hex()}
+	if previous != nil && previous.ExpiresAt.After(claim.ClaimedAt) {
+		data["previous_agent_id"] = previous.AgentID.Hex()
+		uc.auditUsecase.Record(ctx, models.AuditChannelClaimOverride, "channel", channelID, previous, claim)
+	}
+	uc.feedUsecase.Publish(ctx, &models.FeedItem{
+		SellerID:  sellerID,
+		Type:      models.FeedClaimChanged,
+		ChannelID: channelID,
+		Summary:   "An agent is handling the chat",
+		Data:      data,
+	})
+	return claim, nil
+}
+
+func (uc *channelClaimUsecase) Get(ctx context.Context, channelID string) (*models.ChannelClaim, error) {
+	return uc.claimRepo.Get(ctx, channelID)
+}
+
+func (uc *channelClaimUsecase) Release(ctx context.Context, channelID string) error {
+	agentID, sellerID, err := uc.agent(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	released, err := uc.claimRepo.Release(ctx, channelID, agentID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return models.ErrNotFound
+	}
+
+	uc.feedUsecase.Publish(ctx, &models.FeedItem{
+		SellerID:  sellerID,
+		Type:      models.FeedClaimChanged,
+		ChannelID: channelID,
+		Summary:   "The chat was released",
+		Data:      map[string]any{"released_by": agentID.Hex()},
+	})
+	return nil
+}
+
+// agent returns the calling user and the room's seller, checking the user is
+// in the seller's team
+func (uc *channelClaimUsecase) agent(ctx context.Context, channelID string) (primitive.ObjectID, string, error) {
+	actor := models.ActorFromContext(ctx)
+	if actor.Type != models.ActorTypeUser {
+		return primitive.NilObjectID, "", models.ErrNotTeamMember
+	}
+	agentID, err := primitive.ObjectIDFromHex(actor.ID)
+	if err != nil {
+		return primitive.NilObjectID, "", models.ErrNotTeamMember
+	}
+
+	channelInfo, err := uc.chatAPIClient.GetChannelInfo(ctx, channelID)
+	if err != nil {
+		return primitive.NilObjectID, "", fmt.Errorf("failed to get channel info: %w", err)
+	}
+	sellerID := findSellerIDFromChannel(channelInfo)
+	if sellerID == "" {
+		return primitive.NilObjectID, "", models.ErrNotFound
+	}
+
+	team, err := uc.teamRepo.GetBySeller(ctx, sellerID)
+	if err != nil {
+		return primitive.NilObjectID, "", err
+	}
+	if team == nil || team.Member(agentID) == nil {
+		return primitive.NilObjectID, "", models.ErrNotTeamMember
+	}
+	return agentID, sellerID, nil
+}
@@ -0,0 +1,192 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// MessageIncludes selects the optional data embedded in channel messages
+type MessageIncludes struct {
+	// Sender embeds the sender profile of each message
+	Sender bool
+	// Listings adds a product card block for each chotot listing URL in a message
+	Listings bool
+}
+
+type ChannelUsecase interface {
+	GetParticipants(ctx context.Context, channelID string) ([]models.Participant, error)
+	// GetMessages returns channel history as seen by req.UserID, with the
+	// optional data selected by includes
+	GetMessages(ctx context.Context, req chatapi.MessageHistoryRequest, includes MessageIncludes) (*models.MessageHistory, error)
+	// StreamMessages pages through the history GetMessages returns, newest
+	// first from req.BeforeTs, and passes each page of req.Limit messages to
+	// fn, so whole rooms are exported without holding them in memory. It stops
+	// at the first error of fn.
+	StreamMessages(ctx context.Context, req chatapi.MessageHistoryRequest, includes MessageIncludes, fn func(messages []models.HistoryMessage) error) error
+
+	// SetChatMode pins the chat mode answering the channel, ahead of the
+	// seller's choice and the tenant rules
+	SetChatMode(ctx context.Context, channelID, chatMode string) (*models.ChannelChatMode, error)
+	// GetChatMode returns models.ErrNotFound when the channel has no override
+	GetChatMode(ctx context.Context, channelID string) (*models.ChannelChatMode, error)
+	ClearChatMode(ctx context.Context, channelID string) error
+}
+
+type channelUsecase struct {
+	chatAPIClient       chatapi.Client
+	userHydrator        UserHydrator
+	listingExpander     ListingExpander
+	chatModeRepo        mongodb.ChatModeRepository
+	channelChatModeRepo mongodb.ChannelChatModeRepository
+	auditUsecase        AuditUsecase
+}
+
+func NewChannelUsecase(
+	chatAPIClient chatapi.Client,
+	userHydrator UserHydrator,
+	listingExpander ListingExpander,
+	chatModeRepo mongodb.ChatModeRepository,
+	channelChatModeRepo mongodb.ChannelChatModeRepository,
+	auditUsecase AuditUsecase,
+) ChannelUsecase {
+	return &channelUsecase{
+		chatAPIClient:       chatAPIClient,
+		userHydrator:        userHydrator,
+		listingExpander:     listingExpander,
+		chatModeRepo:        chatModeRepo,
+		channelChatModeRepo: channelChatModeRepo,
+		auditUsecase:        auditUsecase,
+	}
+}
+
+func (uc *channelUsecase) GetParticipants(ctx context.Context, channelID string) ([]models.Participant, error) {
+	channelInfo, err := uc.chatAPIClient.GetChannelInfo(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel info: %w", err)
+	}
+
+	participants := channelInfo.Participants
+	if err := uc.userHydrator.HydrateParticipants(ctx, participants); err != nil {
+		return nil, fmt.Errorf("failed to hydrate participants: %w", err)
+	}
+	return participants, nil
+}
+
+func (uc *channelUsecase) GetMessages(ctx context.Context, req chatapi.MessageHistoryRequest, includes MessageIncludes) (*models.MessageHistory, error) {
+	history, err := uc.chatAPIClient.GetMessageHistoryWithParams(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message history: %w", err)
+	}
+
+	if err := uc.includeMessageData(ctx, history.Messages, includes); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (uc *channelUsecase) StreamMessages(ctx context.Context, req chatapi.MessageHistoryRequest, includes MessageIncludes, fn func(messages []models.HistoryMessage) error) error {
+	// Pages continue before the oldest timestamp of the previous one, like
+	// FetchMessages. Messages sharing that timestamp may come back, so the
+	// ones already passed to fn are skipped, and a page of only those ends
+	// the stream.
+	var seen map[string]bool
+	for {
+		history, err := uc.chatAPIClient.GetMessageHistoryWithParams(ctx, req)
+		if err != nil {
+			return fmt.Errorf("failed to get message history: %w", err)
+		}
+
+		messages := slices.DeleteFunc(history.Messages, func(message models.HistoryMessage) bool {
+			return seen[message.ID]
+		})
+		if len(messages) == 0 {
+			return nil
+		}
+		if err := uc.includeMessageData(ctx, messages, includes); err != nil {
+			return err
+		}
+		if err := fn(messages); err != nil {
+			return err
+		}
+		if !history.HasMore {
+			return nil
+		}
+
+		oldest := messages[0].CreatedAt.UnixMilli()
+		for _, message := range messages {
+			oldest = min(oldest, message.CreatedAt.UnixMilli())
+		}
+		seen = make(map[string]bool)
+		for _, message := range messages {
+			if message.CreatedAt.UnixMilli() == oldest {
+				seen[message.ID] = true
+			}
+		}
+		req.BeforeTs = &oldest
+	}
+}
+
+// includeMessageData embeds the data selected by includes into messages
+func (uc *channelUsecase) includeMessageData(ctx context.Context, messages []models.HistoryMessage, includes MessageIncludes) error {
+	if includes.Sender {
+		if err := uc.userHydrator.HydrateMessages(ctx, messages); err != nil {
+			return fmt.Errorf("failed to hydrate message senders: %w", err)
+		}
+	}
+	if includes.Listings {
+		uc.listingExpander.HydrateMessages(ctx, messages)
+	}
+	return nil
+}
+
+func (uc *channelUsecase) SetChatMode(ctx context.Context, channelID, chatMode string) (*models.ChannelChatMode, error) {
+	if _, err := uc.chatModeRepo.GetByName(ctx, chatMode); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrNotFound, err)
+	}
+
+	before, err := uc.channelChatModeRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+
+	override := &models.ChannelChatMode{
+		ChannelID: channelID,
+		ChatMode:  chatMode,
+	}
+	if err := uc.channelChatModeRepo.Upsert(ctx, override); err != nil {
+		return nil, err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditChannelChatModeSet, "channel", channelID, before, override)
+	return override, nil
+}
+
+func (uc *channelUsecase) GetChatMode(ctx context.Context, channelID string) (*models.ChannelChatMode, error) {
+	override, err := uc.channelChatModeRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if override == nil {
+		return nil, models.ErrNotFound
+	}
+	return override, nil
+}
+
+func (uc *channelUsecase) ClearChatMode(ctx context.Context, channelID string) error {
+	before, err := uc.GetChatMode(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.channelChatModeRepo.Delete(ctx, channelID); err != nil {
+		return err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditChannelChatModeClear, "channel", channelID, before, nil)
+	return nil
+}
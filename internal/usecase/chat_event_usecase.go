@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+)
+
+// ChatEventUsecase handles the chat-api events published to Kafka, shared by
+// the consumer and dead letter requeues
+type ChatEventUsecase interface {
+	// HandleEvent processes a raw chat event; events other than message.sent
+	// are ignored
+	HandleEvent(ctx context.Context, payload []byte) error
+}
+
+type chatEventUsecase struct {
+	messageUsecase    MessageUsecase
+	chototLinkUsecase ChototLinkUsecase
+}
+
+func NewChatEventUsecase(messageUsecase MessageUsecase, chototLinkUsecase ChototLinkUsecase) ChatEventUsecase {
+	return &chatEventUsecase{
+		messageUsecase:    messageUsecase,
+		chototLinkUsecase: chototLinkUsecase,
+	}
+}
+
+func (uc *chatEventUsecase) HandleEvent(ctx context.Context, payload []byte) error {
+	incomingMessage, pattern, err := models.ParseChatEvent(payload)
+	if err != nil {
+		return err
+	}
+
+	// Only process message.sent events
+	if incomingMessage == nil {
+		log.Infow(ctx, "Ignoring non-message.sent event", "pattern", pattern)
+		return nil
+	}
+
+	livestats.Inc(models.StatMessagesPrefix + "kafka")
+
+	// Chat-api events carry an authenticated sender, so only they can
+	// verify a chotot link; the code itself is never answered
+	linked, err := uc.chototLinkUsecase.VerifyFromMessage(ctx, incomingMessage.SenderID, incomingMessage.Message)
+	if err != nil {
+		return fmt.Errorf("failed to verify chotot link: %w", err)
+	}
+	if linked {
+		log.Infow(ctx, "Verified chotot link", "sender_id", incomingMessage.SenderID)
+		return nil
+	}
+
+	log.Infow(ctx, "Processing Kafka message",
+		"channel_id", incomingMessage.ChannelID,
+		"sender_id", incomingMessage.SenderID)
+
+	return uc.messageUsecase.ProcessMessage(ctx, *incomingMessage)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sync"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// ChatModeChecker validates the stored chat modes ahead of the messages that
+// use them, and remembers the unhealthy ones for the health endpoint
+type ChatModeChecker interface {
+	// Check validates every chat mode, returning the problems of the
+	// unhealthy ones by name
+	Check(ctx context.Context) (map[string][]string, error)
+	// Unhealthy returns the problems found by the last Check
+	Unhealthy() map[string][]string
+}
+
+type chatModeChecker struct {
+	chatModeRepo mongodb.ChatModeRepository
+	llmUsecase   LLMUsecase
+
+	mu        sync.RWMutex
+	unhealthy map[string][]string
+}
+
+// NewChatModeChecker takes the LLM usecase, which registers the tools chat
+// modes are checked against
+func NewChatModeChecker(chatModeRepo mongodb.ChatModeRepository, llmUsecase LLMUsecase) ChatModeChecker {
+	return &chatModeChecker{
+		chatModeRepo: chatModeRepo,
+		llmUsecase:   llmUsecase,
+	}
+}
+
+func (c *chatModeChecker) Check(ctx context.Context) (map[string][]string, error) {
+	modes, err := c.chatModeRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list chat modes: %w", err)
+	}
+
+	unhealthy := make(map[string][]string)
+	for _, mode := range modes {
+		if problems := c.llmUsecase.ValidateChatMode(mode); len(problems) > 0 {
+			unhealthy[mode.Name] = problems
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unhealthy = unhealthy
+	return maps.Clone(unhealthy), nil
+}
+
+func (c *chatModeChecker) Unhealthy() map[string][]string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return maps.Clone(c.unhealthy)
+}
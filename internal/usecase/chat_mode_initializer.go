@@ -4,9 +4,11 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
-	"time"
+	"reflect"
+	"slices"
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
 	"gopkg.in/yaml.v3"
@@ -15,13 +17,38 @@ import (
 //go:embed default_chat_modes.yaml
 var defaultChatModesData []byte
 
-func AutoMigrate(repo mongodb.ChatModeRepository) error {
+//go:embed chat_mode_packs.yaml
+var chatModePacksData []byte
+
+// loadChatModePacks parses the embedded vertical packs
+func loadChatModePacks() ([]models.ChatModePack, error) {
+	var packs []models.ChatModePack
+	if err := yaml.Unmarshal(chatModePacksData, &packs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chat mode packs: %w", err)
+	}
+	for _, pack := range packs {
+		if !slices.ContainsFunc(pack.ChatModes, func(mode models.ChatMode) bool { return mode.Name == pack.DefaultChatMode }) {
+			return nil, fmt.Errorf("chat mode pack '%s' does not contain its default chat mode '%s'", pack.Vertical, pack.DefaultChatMode)
+		}
+	}
+	return packs, nil
+}
+
+func AutoMigrate(repo mongodb.ChatModeRepository, auditUsecase AuditUsecase, conf *config.Config) error {
 	var defaultModes []models.ChatMode
 	if err := yaml.Unmarshal(defaultChatModesData, &defaultModes); err != nil {
 		return fmt.Errorf("failed to unmarshal default chat modes: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	packs, err := loadChatModePacks()
+	if err != nil {
+		return err
+	}
+	for _, pack := range packs {
+		defaultModes = append(defaultModes, pack.ChatModes...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Timeouts.Startup)
 	defer cancel()
 
 	log.Debugw(ctx, "Loaded chat modes from YAML", "count", len(defaultModes))
@@ -39,9 +66,36 @@ func AutoMigrate(repo mongodb.ChatModeRepository) error {
 		)
 	}
 	for _, mode := range defaultModes {
+		// a lookup error just means the mode is new
+		before, _ := repo.GetByName(ctx, mode.Name)
 		if err := repo.Upsert(ctx, &mode); err != nil {
 			return fmt.Errorf("failed to upsert chat mode '%s': %w", mode.Name, err)
 		}
+		if !chatModeChanged(before, &mode) {
+			continue
+		}
+
+		after, err := repo.GetByName(ctx, mode.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get chat mode '%s': %w", mode.Name, err)
+		}
+		auditUsecase.Record(ctx, models.AuditChatModeMigrate, "chat_mode", after.ID.Hex(), before, after)
 	}
 	return nil
 }
+
+// chatModeChanged reports whether upserting mode modified the stored chat mode
+func chatModeChanged(before, mode *models.ChatMode) bool {
+	if before == nil {
+		return true
+	}
+	return before.PromptTemplate != mode.PromptTemplate ||
+		before.Condition != mode.Condition ||
+		before.Model != mode.Model ||
+		!reflect.DeepEqual(before.Tools, mode.Tools) ||
+		before.MaxIterations != mode.MaxIterations ||
+		before.MaxPromptTokens != mode.MaxPromptTokens ||
+		before.MaxResponseTokens != mode.MaxResponseTokens ||
+		!sameOutputSchema(before.OutputSchema, mode.OutputSchema) ||
+		!reflect.DeepEqual(before.SessionTimeout, mode.SessionTimeout)
+}
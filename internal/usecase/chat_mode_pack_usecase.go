@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ChatModePackUsecase lets sellers pick one of the vertical packs installed by AutoMigrate
+type ChatModePackUsecase interface {
+	ListPacks() []models.ChatModePack
+	// SelectVertical stores the vertical on the user and switches their chat
+	// mode to the pack's default; it returns models.ErrNotFound for an unknown vertical
+	SelectVertical(ctx context.Context, userID primitive.ObjectID, vertical string) (*models.ChatModePack, error)
+}
+
+type chatModePackUsecase struct {
+	packs       []models.ChatModePack
+	userUsecase UserUsecase
+}
+
+func NewChatModePackUsecase(userUsecase UserUsecase) (ChatModePackUsecase, error) {
+	packs, err := loadChatModePacks()
+	if err != nil {
+		return nil, err
+	}
+	return &chatModePackUsecase{
+		packs:       packs,
+		userUsecase: userUsecase,
+	}, nil
+}
+
+func (uc *chatModePackUsecase) ListPacks() []models.ChatModePack {
+	return uc.packs
+}
+
+func (uc *chatModePackUsecase) SelectVertical(ctx context.Context, userID primitive.ObjectID, vertical string) (*models.ChatModePack, error) {
+	var pack *models.ChatModePack
+	for i := range uc.packs {
+		if uc.packs[i].Vertical == vertical {
+			pack = &uc.packs[i]
+			break
+		}
+	}
+	if pack == nil {
+		return nil, fmt.Errorf("%w: unknown vertical %q", models.ErrNotFound, vertical)
+	}
+
+	if _, err := uc.userUsecase.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := uc.userUsecase.SetUserAttribute(ctx, userID, models.AttributeChototVertical, pack.Vertical, nil); err != nil {
+		return nil, err
+	}
+	if err := uc.userUsecase.SetUserAttribute(ctx, userID, models.AttributeChototChatMode, pack.DefaultChatMode, nil); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
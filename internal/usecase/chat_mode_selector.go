@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// ChatModeSelector picks the chat mode answering a buyer message. The first
+// step with an answer wins:
+//  1. the chat mode forced by the message metadata
+//  2. the channel's override
+//  3. the seller's chotot_chat_mode attribute
+//  4. the first tenant rule matching the channel's item category and price
+//     and the message intent
+//  5. the tenant's default chat mode
+//  6. the global default
+type ChatModeSelector interface {
+	Select(ctx context.Context, message models.IncomingMessage, channelInfo *models.ChannelInfo, sellerID string) (string, models.ChatModeSource, error)
+}
+
+type chatModeSelector struct {
+	channelChatModeRepo mongodb.ChannelChatModeRepository
+	userAttributeRepo   mongodb.UserAttributeRepository
+	identityMapper      IdentityMapper
+	tenantUsecase       TenantUsecase
+	defaultChatMode     string
+}
+
+func NewChatModeSelector(
+	channelChatModeRepo mongodb.ChannelChatModeRepository,
+	userAttributeRepo mongodb.UserAttributeRepository,
+	identityMapper IdentityMapper,
+	tenantUsecase TenantUsecase,
+	conf *config.Config,
+) ChatModeSelector {
+	return &chatModeSelector{
+		channelChatModeRepo: channelChatModeRepo,
+		userAttributeRepo:   userAttributeRepo,
+		identityMapper:      identityMapper,
+		tenantUsecase:       tenantUsecase,
+		defaultChatMode:     conf.ChatMode.Default,
+	}
+}
+
+func (s *chatModeSelector) Select(ctx context.Context, message models.IncomingMessage, channelInfo *models.ChannelInfo, sellerID string) (string, models.ChatModeSource, error) {
+	if message.Metadata.LLM.ChatMode != "" {
+		return message.Metadata.LLM.ChatMode, models.ChatModeSourceRequest, nil
+	}
+
+	override, err := s.channelChatModeRepo.Get(ctx, message.ChannelID)
+	if err != nil {
+		return "", "", err
+	}
+	if override != nil {
+		return override.ChatMode, models.ChatModeSourceChannel, nil
+	}
+
+	sellerMode, err := s.sellerChatMode(ctx, sellerID)
+	if err != nil {
+		return "", "", err
+	}
+	if sellerMode != "" {
+		return sellerMode, models.ChatModeSourceSeller, nil
+	}
+
+	if tenantID, ok := models.TenantIDFromContext(ctx); ok {
+		tenant, err := s.tenantUsecase.GetTenant(ctx, tenantID)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get tenant: %w", err)
+		}
+
+		price := parseItemPrice(channelInfo.ItemPrice)
+		for _, rule := range tenant.Settings.ChatModeRules {
+			if rule.Matches(channelInfo.ItemCategory, price, message.Metadata.Intent) {
+				return rule.ChatMode, models.ChatModeSourceRule, nil
+			}
+		}
+		if tenant.Settings.DefaultChatMode != "" {
+			return tenant.Settings.DefaultChatMode, models.ChatModeSourceTenant, nil
+		}
+	}
+
+	return s.defaultChatMode, models.ChatModeSourceDefault, nil
+}
+
+// sellerChatMode returns the chat mode chosen by the user linked to the chotot
+// seller, or "" when the seller is unknown or chose none
+func (s *chatModeSelector) sellerChatMode(ctx context.Context, sellerID string) (string, error) {
+	userID, err := s.identityMapper.ResolveExternalID(ctx, models.PartnerChotot, sellerID)
+	if err != nil {
+		return "", err
+	}
+	if userID == nil {
+		return "", nil
+	}
+
+	attr, err := s.userAttributeRepo.GetByUserIDAndKey(ctx, *userID, models.AttributeChototChatMode)
+	if err != nil {
+		return "", fmt.Errorf("failed to get seller chat mode: %w", err)
+	}
+	if attr == nil {
+		return "", nil
+	}
+	return attr.Value, nil
+}
+
+// parseItemPrice reads a chat-api item price such as "1.500.000 đ", returning
+// 0 when it holds no amount
+func parseItemPrice(itemPrice string) int64 {
+	digits := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, itemPrice)
+
+	price, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return price
+}
@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chotot"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const chototLinkCodePrefix = "LINK-"
+
+var chototLinkCodePattern = regexp.MustCompile(chototLinkCodePrefix + `\d{6}`)
+
+// ChototLinkUsecase proves that a user owns the chotot account they claim.
+// Starting a link issues a challenge code that the seller sends in any chotot
+// chat; chat-api authenticates the sender, so receiving the code from the
+// claimed chotot_id verifies the link.
+type ChototLinkUsecase interface {
+	StartLink(ctx context.Context, userID primitive.ObjectID, chototID, chototOID string) (*models.ChototLink, error)
+	GetLink(ctx context.Context, userID primitive.ObjectID) (*models.ChototLink, error)
+	// VerifyFromMessage verifies the pending link whose code the sender sent,
+	// reporting whether the message was a linking code
+	VerifyFromMessage(ctx context.Context, senderID, text string) (bool, error)
+	// RevokeLink removes the chotot attributes of a verified link, or cancels a pending one
+	RevokeLink(ctx context.Context, userID primitive.ObjectID) (*models.ChototLink, error)
+}
+
+type chototLinkUsecase struct {
+	chototLinkRepo mongodb.ChototLinkRepository
+	identityMapper IdentityMapper
+	userUsecase    UserUsecase
+	auditUsecase   AuditUsecase
+	chototClient   chotot.Client
+	emailVerifier  EmailVerificationUsecase
+	codeTTL        time.Duration
+}
+
+func NewChototLinkUsecase(
+	chototLinkRepo mongodb.ChototLinkRepository,
+	identityMapper IdentityMapper,
+	userUsecase UserUsecase,
+	auditUsecase AuditUsecase,
+	chototClient chotot.Client,
+	emailVerifier EmailVerificationUsecase,
+	conf *config.Config,
+) ChototLinkUsecase {
+	return &chototLinkUsecase{
+		chototLinkRepo: chototLinkRepo,
+		identityMapper: identityMapper,
+		userUsecase:    userUsecase,
+		auditUsecase:   auditUsecase,
+		chototClient:   chototClient,
+		emailVerifier:  emailVerifier,
+		codeTTL:        conf.ChototLink.CodeTTL,
+	}
+}
+
+func (uc *chototLinkUsecase) StartLink(ctx context.Context, userID primitive.ObjectID, chototID, chototOID string) (*models.ChototLink, error) {
+	if _, err := uc.userUsecase.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := uc.emailVerifier.RequireVerified(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	before, err := uc.chototLinkRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chotot link: %w", err)
+	}
+	if before != nil && before.Status == models.ChototLinkVerified {
+		return nil, models.ErrChototLinkActive
+	}
+
+	claimed, err := uc.identityMapper.ResolveExternalID(ctx, models.PartnerChotot, chototID)
+	if err != nil {
+		return nil, err
+	}
+	if claimed != nil && *claimed != userID {
+		return nil, models.ErrAttributeConflict
+	}
+
+	if err := uc.verifyChototAccount(ctx, chototID, chototOID); err != nil {
+		return nil, err
+	}
+
+	code, err := generateChototLinkCode()
+	if err != nil {
+		return nil, err
+	}
+	expiresAt := time.Now().Add(uc.codeTTL)
+	link := &models.ChototLink{
+		UserID:        userID,
+		ChototID:      chototID,
+		ChototOID:     chototOID,
+		Status:        models.ChototLinkPending,
+		CodeHash:      hashAPIKey(code),
+		CodeExpiresAt: &expiresAt,
+	}
+	if err := uc.chototLinkRepo.Upsert(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to save chotot link: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditChototLinkStart, "chotot_link", userID.Hex(), before, link)
+
+	link.Code = code
+	return link, nil
+}
+
+// verifyChototAccount checks that chototOID is an existing account and, when
+// the account has listings, that they carry chototID as their account ID
+func (uc *chototLinkUsecase) verifyChototAccount(ctx context.Context, chototID, chototOID string) error {
+	profile, err := uc.chototClient.GetProfile(ctx, chototOID)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get profile: %v", models.ErrChototLinkUnverified, err)
+	}
+	if profile.AccountOID != "" && profile.AccountOID != chototOID {
+		return fmt.Errorf("%w: profile belongs to another account", models.ErrChototLinkUnverified)
+	}
+
+	ads, err := uc.chototClient.GetUserAds(ctx, chototOID, 1, 1)
+	if err != nil {
+		return fmt.Errorf("%w: failed to get listings: %v", models.ErrChototLinkUnverified, err)
+	}
+	if len(ads.Ads) > 0 && strconv.Itoa(ads.Ads[0].Info.AccountID) != chototID {
+		return fmt.Errorf("%w: chotot_id does not match the account", models.ErrChototLinkUnverified)
+	}
+	return nil
+}
+
+func generateChototLinkCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate link code: %w", err)
+	}
+	return fmt.Sprintf("%s%06d", chototLinkCodePrefix, n.Int64()), nil
+}
+
+func (uc *chototLinkUsecase) GetLink(ctx context.Context, userID primitive.ObjectID) (*models.ChototLink, error) {
+	link, err := uc.chototLinkRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chotot link: %w", err)
+	}
+	if link == nil {
+		return nil, models.ErrNotFound
+	}
+	return link, nil
+}
+
+func (uc *chototLinkUsecase) VerifyFromMessage(ctx context.Context, senderID, text string) (bool, error) {
+	code := chototLinkCodePattern.FindString(text)
+	if code == "" {
+		return false, nil
+	}
+
+	link, err := uc.chototLinkRepo.GetPendingByCode(ctx, senderID, hashAPIKey(code))
+	if err != nil {
+		return false, fmt.Errorf("failed to get pending chotot link: %w", err)
+	}
+	if link == nil {
+		return false, nil
+	}
+	if link.TenantID != nil {
+		ctx = models.WithTenantID(ctx, *link.TenantID)
+	}
+
+	if err := uc.identityMapper.RegisterExternalID(ctx, link.UserID, models.PartnerChotot, link.ChototID, "link_id"); err != nil {
+		return false, err
+	}
+	if err := uc.userUsecase.SetUserAttribute(ctx, link.UserID, models.AttributeChototOID, link.ChototOID, []string{"chotot", "account_oid"}); err != nil {
+		return false, err
+	}
+
+	before := *link
+	now := time.Now()
+	link.Status = models.ChototLinkVerified
+	link.CodeHash = ""
+	link.CodeExpiresAt = nil
+	link.VerifiedAt = &now
+	if err := uc.chototLinkRepo.Upsert(ctx, link); err != nil {
+		return false, fmt.Errorf("failed to save chotot link: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditChototLinkVerify, "chotot_link", link.UserID.Hex(), before, link)
+
+	log.Infow(ctx, "Verified chotot link", "user_id", link.UserID.Hex(), "chotot_id", link.ChototID)
+	return true, nil
+}
+
+func (uc *chototLinkUsecase) RevokeLink(ctx context.Context, userID primitive.ObjectID) (*models.ChototLink, error) {
+	link, err := uc.GetLink(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if link.Status == models.ChototLinkRevoked {
+		return link, nil
+	}
+
+	if link.Status == models.ChototLinkVerified {
+		for _, key := range models.VerifiedAttributeKeys {
+			if err := uc.userUsecase.RemoveUserAttribute(ctx, userID, key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	before := *link
+	now := time.Now()
+	link.Status = models.ChototLinkRevoked
+	link.CodeHash = ""
+	link.CodeExpiresAt = nil
+	link.RevokedAt = &now
+	if err := uc.chototLinkRepo.Upsert(ctx, link); err != nil {
+		return nil, fmt.Errorf("failed to save chotot link: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditChototLinkRevoke, "chotot_link", userID.Hex(), before, link)
+	return link, nil
+}
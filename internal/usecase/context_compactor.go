@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"unicode/utf8"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+// ContextCompactor keeps channel metadata contexts within CHANNEL_CONTEXT_MAX_CHARS
+type ContextCompactor interface {
+	// Compact returns the channel context to put in prompts and whether it is
+	// a digest. Longer contexts are summarized with model once per change, the
+	// full text stays available to the FetchFullContext tool. Errors are logged
+	// and fall back to the truncated context.
+	Compact(ctx context.Context, channelID, sellerID, model, channelContext string) (string, bool)
+}
+
+type contextCompactor struct {
+	conf               config.ChannelContextConfig
+	channelContextRepo mongodb.ChannelContextRepository
+	llmUsecase         LLMUsecase
+}
+
+func NewContextCompactor(
+	conf *config.Config,
+	channelContextRepo mongodb.ChannelContextRepository,
+	llmUsecase LLMUsecase,
+) ContextCompactor {
+	return &contextCompactor{
+		conf:               conf.ChannelContext,
+		channelContextRepo: channelContextRepo,
+		llmUsecase:         llmUsecase,
+	}
+}
+
+func (c *contextCompactor) Compact(ctx context.Context, channelID, sellerID, model, channelContext string) (string, bool) {
+	maxChars := c.conf.MaxChars
+	if maxChars <= 0 || utf8.RuneCountInString(channelContext) <= maxChars {
+		return channelContext, false
+	}
+
+	hash := models.ChannelContextHash(channelContext)
+	stored, err := c.channelContextRepo.Get(ctx, channelID)
+	if err != nil {
+		log.Warnw(ctx, "Failed to get compacted channel context", "channel_id", channelID, "error", err)
+	}
+	if stored != nil && stored.Hash == hash && stored.Digest != "" {
+		return stored.Digest, true
+	}
+
+	// the full context is stored even without a digest, the next message
+	// summarizes again
+	digest, err := c.llmUsecase.SummarizeContext(ctx, sellerID, model, channelContext, maxChars)
+	if err != nil {
+		log.Warnw(ctx, "Failed to summarize channel context, truncating it", "channel_id", channelID, "error", err)
+	}
+	err = c.channelContextRepo.Save(ctx, &models.ChannelContext{
+		ChannelID: channelID,
+		Hash:      hash,
+		Full:      channelContext,
+		Digest:    digest,
+	})
+	if err != nil {
+		log.Warnw(ctx, "Failed to save channel context", "channel_id", channelID, "error", err)
+	}
+
+	if digest == "" {
+		return textx.Truncate(channelContext, maxChars), true
+	}
+	log.Infow(ctx, "Compacted channel context", "channel_id", channelID,
+		"chars", utf8.RuneCountInString(channelContext), "digest_chars", utf8.RuneCountInString(digest))
+	return digest, true
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+const (
+	// recapSessionLimit bounds the sessions read to find earlier conversations
+	recapSessionLimit = 100
+	recapMaxChannels  = 3
+)
+
+// ConversationRecapper recaps a buyer's earlier conversations with the same
+// seller from the stored sessions, purchase intents and reservations
+type ConversationRecapper interface {
+	// Recap returns the latest conversations in channels other than
+	// channelID, most recent first. It returns nil when the tenant disabled
+	// recaps, and leaves them out rather than failing the message on errors.
+	Recap(ctx context.Context, buyerID, sellerID, channelID string) []models.ConversationRecap
+}
+
+type conversationRecapper struct {
+	sessionRepo        mongodb.ChatSessionRepository
+	purchaseIntentRepo mongodb.PurchaseIntentRepository
+	reservationRepo    mongodb.ReservationRepository
+	tenantUsecase      TenantUsecase
+}
+
+func NewConversationRecapper(
+	sessionRepo mongodb.ChatSessionRepository,
+	purchaseIntentRepo mongodb.PurchaseIntentRepository,
+	reservationRepo mongodb.ReservationRepository,
+	tenantUsecase TenantUsecase,
+) ConversationRecapper {
+	return &conversationRecapper{
+		sessionRepo:        sessionRepo,
+		purchaseIntentRepo: purchaseIntentRepo,
+		reservationRepo:    reservationRepo,
+		tenantUsecase:      tenantUsecase,
+	}
+}
+
+func (r *conversationRecapper) Recap(ctx context.Context, buyerID, sellerID, channelID string) []models.ConversationRecap {
+	if tenantID, ok := models.TenantIDFromContext(ctx); ok {
+		tenant, err := r.tenantUsecase.GetTenant(ctx, tenantID)
+		if err != nil {
+			log.Warnw(ctx, "Failed to get tenant for conversation recap", "error", err)
+			return nil
+		}
+		if tenant.Settings.DisableConversationRecap {
+			return nil
+		}
+	}
+
+	recaps, err := r.recap(ctx, buyerID, sellerID, channelID)
+	if err != nil {
+		log.Warnw(ctx, "Failed to recap earlier conversations", "buyer_id", buyerID, "seller_id", sellerID, "error", err)
+		return nil
+	}
+	return recaps
+}
+
+func (r *conversationRecapper) recap(ctx context.Context, buyerID, sellerID, channelID string) ([]models.ConversationRecap, error) {
+	sessions, err := r.sessionRepo.ListByBuyerAndSeller(ctx, buyerID, sellerID, recapSessionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	var recaps []models.ConversationRecap
+	byChannel := make(map[string]*models.ConversationRecap)
+	for _, session := range sessions {
+		if session.ChannelID == channelID {
+			continue
+		}
+		recap, ok := byChannel[session.ChannelID]
+		if !ok {
+			if len(recaps) == recapMaxChannels {
+				continue
+			}
+			// sessions come most recent first
+			recaps = append(recaps, models.ConversationRecap{
+				ChannelID:     session.ChannelID,
+				ItemName:      session.ItemName,
+				LastContactAt: session.StartedAt,
+			})
+			recap = &recaps[len(recaps)-1]
+			byChannel[session.ChannelID] = recap
+		}
+		recap.Messages++
+	}
+	if len(recaps) == 0 {
+		return nil, nil
+	}
+
+	channelIDs := make([]string, 0, len(recaps))
+	for _, recap := range recaps {
+		channelIDs = append(channelIDs, recap.ChannelID)
+	}
+	intents, err := r.purchaseIntentRepo.ListByUserAndChannels(ctx, buyerID, channelIDs, recapSessionLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, intent := range intents {
+		recap := byChannel[intent.ChannelID]
+		if recap.PurchaseIntent == nil || intent.Percentage > recap.PurchaseIntent.Percentage {
+			recap.PurchaseIntent = &models.BuyerIntent{
+				ItemName:   intent.ItemName,
+				Intent:     intent.Intent,
+				Percentage: intent.Percentage,
+				CreatedAt:  intent.CreatedAt,
+			}
+		}
+	}
+
+	reservations, err := r.reservationRepo.ListActiveBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, reservation := range reservations {
+		if recap, ok := byChannel[reservation.ChannelID]; ok && reservation.BuyerID == buyerID {
+			recap.Reserved = true
+		}
+	}
+	return recaps, nil
+}
+
+// describeConversationRecaps tells the model what happened in the buyer's
+// earlier conversations with the seller
+func describeConversationRecaps(recaps []models.ConversationRecap) string {
+	var sb strings.Builder
+	sb.WriteString("The buyer talked to this seller before, in other chats:")
+	for _, recap := range recaps {
+		item := recap.ItemName
+		if item == "" {
+			item = "an unnamed item"
+		}
+		fmt.Fprintf(&sb, "\n- %s, about %q: %d messages", recap.LastContactAt.Format("2006-01-02"), item, recap.Messages)
+		if intent := recap.PurchaseIntent; intent != nil {
+			fmt.Fprintf(&sb, ", purchase intent %d%% for %q (%s)", intent.Percentage, intent.ItemName, intent.Intent)
+		}
+		if recap.Reserved {
+			sb.WriteString(", an item is on hold for them")
+		}
+	}
+	sb.WriteString("\nUse this to avoid asking again, but don't mention details the buyer hasn't brought up.")
+	return sb.String()
+}
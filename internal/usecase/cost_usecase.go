@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CostUsecase estimates what LLM generations cost from their token usage and
+// the configured per-model prices
+type CostUsecase interface {
+	// Record adds the usage of a generation to the month's estimates. Errors
+	// are logged, cost tracking never fails a conversation.
+	Record(ctx context.Context, sellerID, chatMode, model string, usage models.SessionUsage)
+	// Report breaks the month's estimates down by groupBy, across tenants
+	// unless tenantID is set
+	Report(ctx context.Context, month string, tenantID *primitive.ObjectID, groupBy models.CostGroupBy) (*models.CostReport, error)
+}
+
+type costUsecase struct {
+	conf        config.CostConfig
+	llmCostRepo mongodb.LLMCostRepository
+	// costMetrics and tokenMetrics expose running totals; sellers are left
+	// out of the labels to keep their cardinality bounded
+	costMetrics  *prometheus.CounterVec
+	tokenMetrics *prometheus.CounterVec
+}
+
+func NewCostUsecase(conf *config.Config, llmCostRepo mongodb.LLMCostRepository) (CostUsecase, error) {
+	costMetrics, err := util.GetCounterVec("llm_cost_usd_total", "tenant", "chat_mode", "model")
+	if err != nil {
+		return nil, fmt.Errorf("get counter vec: %w", err)
+	}
+	tokenMetrics, err := util.GetCounterVec("llm_tokens_total", "tenant", "chat_mode", "model", "kind")
+	if err != nil {
+		return nil, fmt.Errorf("get counter vec: %w", err)
+	}
+
+	return &costUsecase{
+		conf:         conf.Cost,
+		llmCostRepo:  llmCostRepo,
+		costMetrics:  costMetrics,
+		tokenMetrics: tokenMetrics,
+	}, nil
+}
+
+func (uc *costUsecase) Record(ctx context.Context, sellerID, chatMode, model string, usage models.SessionUsage) {
+	cost := uc.conf.Estimate(model, usage.InputTokens, usage.OutputTokens)
+
+	tenant := "none"
+	if tenantID, ok := models.TenantIDFromContext(ctx); ok {
+		tenant = tenantID.Hex()
+	}
+	uc.costMetrics.WithLabelValues(tenant, chatMode, model).Add(cost)
+	uc.tokenMetrics.WithLabelValues(tenant, chatMode, model, "input").Add(float64(usage.InputTokens))
+	uc.tokenMetrics.WithLabelValues(tenant, chatMode, model, "output").Add(float64(usage.OutputTokens))
+
+	month := models.CostMonth(time.Now())
+	if err := uc.llmCostRepo.Add(ctx, month, sellerID, chatMode, model, usage, cost); err != nil {
+		log.Errorw(ctx, "Failed to record llm cost", "seller_id", sellerID, "chat_mode", chatMode, "model", model, "error", err)
+	}
+}
+
+func (uc *costUsecase) Report(ctx context.Context, month string, tenantID *primitive.ObjectID, groupBy models.CostGroupBy) (*models.CostReport, error) {
+	field, ok := groupBy.Field()
+	if !ok {
+		return nil, models.ErrInvalidCostGroupBy
+	}
+	if _, err := time.Parse("2006-01", month); err != nil {
+		return nil, models.ErrInvalidCostMonth
+	}
+
+	rows, err := uc.llmCostRepo.Breakdown(ctx, month, tenantID, field)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.CostReport{Month: month, GroupBy: groupBy, Rows: rows}
+	for _, row := range rows {
+		report.TotalUSD += row.CostUSD
+	}
+	return report, nil
+}
@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/ctxval"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// deadLetterBatchSize is how many pending letters a bulk requeue loads at once
+const deadLetterBatchSize = 100
+
+// DeadLetterUsecase keeps the chat events that failed every processing
+// attempt and processes them again on demand
+type DeadLetterUsecase interface {
+	// Record stores a message as a dead letter after its last failed
+	// attempt. Errors are logged, the consumer moves on either way.
+	Record(ctx context.Context, letter *models.DeadLetter)
+	List(ctx context.Context, filter models.DeadLetterFilter) ([]*models.DeadLetter, error)
+	// Requeue processes a pending letter again. The letter is resolved when
+	// processing succeeds or its message was answered meanwhile, and pending
+	// with the new error otherwise.
+	Requeue(ctx context.Context, id primitive.ObjectID) (*models.DeadLetter, error)
+	// RequeueAll requeues every pending letter, oldest first
+	RequeueAll(ctx context.Context, progress JobReporter) error
+}
+
+type deadLetterUsecase struct {
+	conf             config.KafkaConfig
+	timeout          time.Duration
+	deadLetterRepo   mongodb.DeadLetterRepository
+	chatEventUsecase ChatEventUsecase
+	auditUsecase     AuditUsecase
+}
+
+func NewDeadLetterUsecase(
+	conf *config.Config,
+	deadLetterRepo mongodb.DeadLetterRepository,
+	chatEventUsecase ChatEventUsecase,
+	auditUsecase AuditUsecase,
+) DeadLetterUsecase {
+	return &deadLetterUsecase{
+		conf:             conf.Kafka,
+		timeout:          conf.Timeouts.MessageProcessing,
+		deadLetterRepo:   deadLetterRepo,
+		chatEventUsecase: chatEventUsecase,
+		auditUsecase:     auditUsecase,
+	}
+}
+
+func (uc *deadLetterUsecase) Record(ctx context.Context, letter *models.DeadLetter) {
+	letter.ChannelID = eventChannelID([]byte(letter.Payload))
+	letter.ExpiresAt = time.Now().Add(uc.conf.DeadLetterRetention)
+	if err := uc.deadLetterRepo.Create(ctx, letter); err != nil {
+		log.Errorw(ctx, "Failed to record dead letter", "topic", letter.Topic, "partition", letter.Partition, "offset", letter.Offset, "error", err)
+		return
+	}
+	log.Warnw(ctx, "Dead-lettered message", "dead_letter_id", letter.ID.Hex(), "channel_id", letter.ChannelID, "attempts", letter.Attempts)
+}
+
+func (uc *deadLetterUsecase) List(ctx context.Context, filter models.DeadLetterFilter) ([]*models.DeadLetter, error) {
+	return uc.deadLetterRepo.List(ctx, filter)
+}
+
+func (uc *deadLetterUsecase) Requeue(ctx context.Context, id primitive.ObjectID) (*models.DeadLetter, error) {
+	// a requeue left unfinished by a crash can be claimed again once it has
+	// outlived the processing timeout
+	before, err := uc.deadLetterRepo.Claim(ctx, id, time.Now().Add(-2*uc.timeout))
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			if _, getErr := uc.deadLetterRepo.GetByID(ctx, id); getErr == nil {
+				return nil, models.ErrDeadLetterNotPending
+			}
+		}
+		return nil, err
+	}
+
+	var errMessage string
+	if err := uc.chatEventUsecase.HandleEvent(ctxval.Wrap(ctx), []byte(before.Payload)); err != nil {
+		errMessage = err.Error()
+	}
+	// the outcome is stored even when the request that asked for it is gone
+	after, err := uc.deadLetterRepo.Finish(context.WithoutCancel(ctx), id, errMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditDeadLetterRequeue, "dead_letter", id.Hex(), before, after)
+	log.Infow(ctx, "Requeued dead letter", "dead_letter_id", id.Hex(), "status", after.Status)
+	return after, nil
+}
+
+func (uc *deadLetterUsecase) RequeueAll(ctx context.Context, progress JobReporter) error {
+	// letters that fail again go back to pending, the cursor keeps them from
+	// being tried twice in a run
+	var (
+		afterID primitive.ObjectID
+		total   int64
+	)
+	for ctx.Err() == nil {
+		letters, err := uc.deadLetterRepo.ListPending(ctx, afterID, deadLetterBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(letters) == 0 {
+			return nil
+		}
+		total += int64(len(letters))
+		progress.SetTotal(total)
+
+		for _, letter := range letters {
+			if ctx.Err() != nil {
+				break
+			}
+			afterID = letter.ID
+			after, err := uc.Requeue(ctx, letter.ID)
+			switch {
+			case errors.Is(err, models.ErrDeadLetterNotPending):
+				// requeued by someone else meanwhile
+				progress.Add(1, 0)
+			case err != nil:
+				progress.Fail(fmt.Errorf("dead letter %s: %w", letter.ID.Hex(), err))
+			case after.Status != models.DeadLetterResolved:
+				progress.Fail(fmt.Errorf("dead letter %s: %s", letter.ID.Hex(), after.Error))
+			default:
+				progress.Add(1, 0)
+			}
+		}
+	}
+	return ctx.Err()
+}
+
+// eventChannelID returns the channel of a raw chat event, empty when the
+// payload does not parse
+func eventChannelID(payload []byte) string {
+	var kafkaMessage models.KafkaMessage
+	if err := json.Unmarshal(payload, &kafkaMessage); err != nil {
+		return ""
+	}
+	return kafkaMessage.Data.ChannelID
+}
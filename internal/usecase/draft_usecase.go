@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+type DraftUsecase interface {
+	// SaveDraft stores the user's draft for the channel. Saving a blank message
+	// clears the draft and returns nil, matching a composer that was emptied.
+	SaveDraft(ctx context.Context, channelID, userID, message string) (*models.Draft, error)
+	GetDraft(ctx context.Context, channelID, userID string) (*models.Draft, error)
+	ListDrafts(ctx context.Context, userID string) ([]*models.Draft, error)
+	DeleteDraft(ctx context.Context, channelID, userID string) error
+}
+
+type draftUsecase struct {
+	draftRepo mongodb.DraftRepository
+}
+
+func NewDraftUsecase(draftRepo mongodb.DraftRepository) DraftUsecase {
+	return &draftUsecase{
+		draftRepo: draftRepo,
+	}
+}
+
+func (uc *draftUsecase) SaveDraft(ctx context.Context, channelID, userID, message string) (*models.Draft, error) {
+	message = textx.Normalize(message)
+	if strings.TrimSpace(message) == "" {
+		if err := uc.DeleteDraft(ctx, channelID, userID); err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	draft := &models.Draft{
+		ChannelID: channelID,
+		UserID:    userID,
+		Message:   message,
+	}
+	if err := uc.draftRepo.Upsert(ctx, draft); err != nil {
+		return nil, fmt.Errorf("failed to save draft: %w", err)
+	}
+	return draft, nil
+}
+
+// GetDraft returns models.ErrNotFound when the user has no draft in the channel
+func (uc *draftUsecase) GetDraft(ctx context.Context, channelID, userID string) (*models.Draft, error) {
+	draft, err := uc.draftRepo.Get(ctx, channelID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft: %w", err)
+	}
+	if draft == nil {
+		return nil, models.ErrNotFound
+	}
+	return draft, nil
+}
+
+func (uc *draftUsecase) ListDrafts(ctx context.Context, userID string) ([]*models.Draft, error) {
+	drafts, err := uc.draftRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list drafts: %w", err)
+	}
+	return drafts, nil
+}
+
+func (uc *draftUsecase) DeleteDraft(ctx context.Context, channelID, userID string) error {
+	if err := uc.draftRepo.Delete(ctx, channelID, userID); err != nil {
+		return fmt.Errorf("failed to delete draft: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mailer"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailVerificationUsecase proves that users own their email address by
+// mailing them a code and a link, either of which verifies it
+type EmailVerificationUsecase interface {
+	// Send mails a new code and link, replacing any pending ones
+	Send(ctx context.Context, userID primitive.ObjectID) (*models.EmailVerification, error)
+	// Confirm verifies the user's email with the mailed code
+	Confirm(ctx context.Context, userID primitive.ObjectID, code string) (*models.User, error)
+	// ConfirmLink verifies the email whose link carries token
+	ConfirmLink(ctx context.Context, token string) (*models.User, error)
+	// RequireVerified returns ErrEmailUnverified when verification is
+	// required and the user's email is not verified
+	RequireVerified(ctx context.Context, userID primitive.ObjectID) error
+}
+
+type emailVerificationUsecase struct {
+	verificationRepo mongodb.EmailVerificationRepository
+	userRepo         mongodb.UserRepository
+	auditUsecase     AuditUsecase
+	mailer           mailer.Mailer
+	conf             config.EmailVerificationConfig
+}
+
+func NewEmailVerificationUsecase(
+	verificationRepo mongodb.EmailVerificationRepository,
+	userRepo mongodb.UserRepository,
+	auditUsecase AuditUsecase,
+	mailer mailer.Mailer,
+	conf *config.Config,
+) EmailVerificationUsecase {
+	return &emailVerificationUsecase{
+		verificationRepo: verificationRepo,
+		userRepo:         userRepo,
+		auditUsecase:     auditUsecase,
+		mailer:           mailer,
+		conf:             conf.EmailVerification,
+	}
+}
+
+func (uc *emailVerificationUsecase) Send(ctx context.Context, userID primitive.ObjectID) (*models.EmailVerification, error) {
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	code, err := generateVerificationCode()
+	if err != nil {
+		return nil, err
+	}
+	token, err := generateVerificationToken()
+	if err != nil {
+		return nil, err
+	}
+
+	verification := &models.EmailVerification{
+		UserID:    user.ID,
+		Email:     user.Email,
+		CodeHash:  hashAPIKey(code),
+		TokenHash: hashAPIKey(token),
+		ExpiresAt: time.Now().Add(uc.conf.CodeTTL),
+	}
+	if err := uc.verificationRepo.Replace(ctx, verification); err != nil {
+		return nil, err
+	}
+
+	link := strings.TrimSuffix(uc.conf.LinkBaseURL, "/") + "/api/v1/email-verifications/" + token
+	err = uc.mailer.Send(ctx, mailer.Mail{
+		To:      user.Email,
+		Subject: "Verify your email",
+		Body: fmt.Sprintf("Hi %s,\n\nYour verification code is %s. You can also open this link to verify your email:\n%s\n\nThe code and link expire in %s.\n",
+			user.Name, code, link, uc.conf.CodeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infow(ctx, "Sent email verification", "user_id", user.ID.Hex())
+	return verification, nil
+}
+
+func (uc *emailVerificationUsecase) Confirm(ctx context.Context, userID primitive.ObjectID, code string) (*models.User, error) {
+	verification, err := uc.verificationRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, models.ErrEmailVerificationInvalid
+	}
+
+	if hashAPIKey(strings.TrimSpace(code)) != verification.CodeHash {
+		attempts, err := uc.verificationRepo.IncrementAttempts(ctx, verification.ID)
+		if err != nil {
+			return nil, err
+		}
+		if attempts >= uc.conf.MaxAttempts {
+			if err := uc.verificationRepo.Delete(ctx, verification.ID); err != nil {
+				return nil, err
+			}
+		}
+		return nil, models.ErrEmailVerificationInvalid
+	}
+
+	return uc.verify(ctx, verification)
+}
+
+func (uc *emailVerificationUsecase) ConfirmLink(ctx context.Context, token string) (*models.User, error) {
+	verification, err := uc.verificationRepo.GetByTokenHash(ctx, hashAPIKey(token))
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		return nil, models.ErrEmailVerificationInvalid
+	}
+	if verification.TenantID != nil {
+		ctx = models.WithTenantID(ctx, *verification.TenantID)
+	}
+
+	return uc.verify(ctx, verification)
+}
+
+// verify marks the user's email verified, unless it changed since the code
+// was sent
+func (uc *emailVerificationUsecase) verify(ctx context.Context, verification *models.EmailVerification) (*models.User, error) {
+	user, err := uc.userRepo.GetByID(ctx, verification.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Email != verification.Email {
+		return nil, models.ErrEmailVerificationInvalid
+	}
+
+	before := *user
+	now := time.Now()
+	if err := uc.userRepo.SetEmailVerified(ctx, user.ID, &now); err != nil {
+		return nil, err
+	}
+	if err := uc.verificationRepo.Delete(ctx, verification.ID); err != nil {
+		return nil, err
+	}
+	user.EmailVerifiedAt = &now
+	uc.auditUsecase.Record(ctx, models.AuditUserEmailVerify, "user", user.ID.Hex(), &before, user)
+	return user, nil
+}
+
+func (uc *emailVerificationUsecase) RequireVerified(ctx context.Context, userID primitive.ObjectID) error {
+	if !uc.conf.Required {
+		return nil
+	}
+	user, err := uc.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.EmailVerifiedAt == nil {
+		return models.ErrEmailUnverified
+	}
+	return nil
+}
+
+func generateVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func generateVerificationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate verification token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdentityMapper links internal users to their IDs at partners, stored as
+// the models.ExternalIDKey user attribute of the partner
+type IdentityMapper interface {
+	// RegisterExternalID links the user to externalID at partner. tags are
+	// added to the partner's on the attribute.
+	RegisterExternalID(ctx context.Context, userID primitive.ObjectID, partner, externalID string, tags ...string) error
+	// ResolveExternalID returns the user linked to externalID at partner, or
+	// nil when none is
+	ResolveExternalID(ctx context.Context, partner, externalID string) (*primitive.ObjectID, error)
+	// ResolveExternalIDs maps each linked external ID at partner to its user
+	ResolveExternalIDs(ctx context.Context, partner string, externalIDs []string) (map[string]primitive.ObjectID, error)
+}
+
+type identityMapper struct {
+	userUsecase       UserUsecase
+	userAttributeRepo mongodb.UserAttributeRepository
+}
+
+func NewIdentityMapper(
+	userUsecase UserUsecase,
+	userAttributeRepo mongodb.UserAttributeRepository,
+) IdentityMapper {
+	return &identityMapper{
+		userUsecase:       userUsecase,
+		userAttributeRepo: userAttributeRepo,
+	}
+}
+
+func (m *identityMapper) RegisterExternalID(ctx context.Context, userID primitive.ObjectID, partner, externalID string, tags ...string) error {
+	return m.userUsecase.SetUserAttribute(ctx, userID, models.ExternalIDKey(partner), externalID, append([]string{partner}, tags...))
+}
+
+func (m *identityMapper) ResolveExternalID(ctx context.Context, partner, externalID string) (*primitive.ObjectID, error) {
+	attr, err := m.userAttributeRepo.GetByKeyAndValue(ctx, models.ExternalIDKey(partner), externalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s ID attribute: %w", partner, err)
+	}
+	if attr == nil {
+		return nil, nil
+	}
+	return &attr.UserID, nil
+}
+
+func (m *identityMapper) ResolveExternalIDs(ctx context.Context, partner string, externalIDs []string) (map[string]primitive.ObjectID, error) {
+	attrs, err := m.userAttributeRepo.GetByKeyAndValues(ctx, models.ExternalIDKey(partner), externalIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s ID attributes: %w", partner, err)
+	}
+
+	userIDs := make(map[string]primitive.ObjectID, len(attrs))
+	for _, attr := range attrs {
+		userIDs[attr.Value] = attr.UserID
+	}
+	return userIDs, nil
+}
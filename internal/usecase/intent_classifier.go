@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed intent_rules.yaml
+var intentRulesData []byte
+
+// IntentClassifier labels buyer messages with keyword rules, cheap enough to
+// run on every message ahead of chat mode selection
+type IntentClassifier interface {
+	Classify(message string) models.MessageIntent
+}
+
+type intentClassifier struct {
+	rules []models.IntentRule
+}
+
+func NewIntentClassifier() (IntentClassifier, error) {
+	var rules []models.IntentRule
+	if err := yaml.Unmarshal(intentRulesData, &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal intent rules: %w", err)
+	}
+	for i, rule := range rules {
+		if rule.Intent == "" || len(rule.Keywords) == 0 {
+			return nil, fmt.Errorf("intent rule %d needs an intent and keywords", i)
+		}
+		for j, keyword := range rule.Keywords {
+			rules[i].Keywords[j] = " " + foldKeywordText(keyword) + " "
+		}
+	}
+	return &intentClassifier{rules: rules}, nil
+}
+
+func (c *intentClassifier) Classify(message string) models.MessageIntent {
+	text := " " + foldKeywordText(message) + " "
+	for _, rule := range c.rules {
+		for _, keyword := range rule.Keywords {
+			if strings.Contains(text, keyword) {
+				return rule.Intent
+			}
+		}
+	}
+	return models.MessageIntentOther
+}
+
+// foldKeywordText lowercases text, strips Vietnamese accents and turns
+// punctuation into single spaces, so "Còn hàng không?" reads "con hang khong"
+func foldKeywordText(text string) string {
+	stripAccents := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(stripAccents, strings.ToLower(text))
+	if err != nil {
+		folded = strings.ToLower(text)
+	}
+	folded = strings.ReplaceAll(folded, "đ", "d")
+
+	return strings.Join(strings.FieldsFunc(folded, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
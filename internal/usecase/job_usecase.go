@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/fx"
+)
+
+// JobReporter lets a running job report its progress, which its worker
+// stores with each heartbeat
+type JobReporter interface {
+	// SetTotal sets how many items the job goes through
+	SetTotal(total int64)
+	Add(done, failed int64)
+	// Fail counts a failed item and keeps its error
+	Fail(err error)
+}
+
+// jobHandler runs a job of one type. It must stop once ctx is done, which is
+// how cancellations reach it.
+type jobHandler func(ctx context.Context, job *models.Job, progress JobReporter) error
+
+// JobUsecase queues long-running operations and runs them in the background.
+// Jobs outlive restarts: a running job left without heartbeat is run again.
+type JobUsecase interface {
+	// Enqueue queues a job of jobType with params, in the tenant of ctx
+	Enqueue(ctx context.Context, jobType models.JobType, params any) (*models.Job, error)
+	Get(ctx context.Context, id primitive.ObjectID) (*models.Job, error)
+	List(ctx context.Context, filter models.JobFilter) ([]*models.Job, error)
+	// Cancel cancels a pending job, or stops a running one at its next heartbeat
+	Cancel(ctx context.Context, id primitive.ObjectID) (*models.Job, error)
+	// RunPending runs the queued jobs one after another until none is left
+	RunPending(ctx context.Context)
+}
+
+type jobUsecase struct {
+	conf         config.JobConfig
+	jobRepo      mongodb.JobRepository
+	auditUsecase AuditUsecase
+	handlers     map[models.JobType]jobHandler
+	// workerID tells this process's jobs apart from other replicas'
+	workerID string
+}
+
+func NewJobUsecase(
+	conf *config.Config,
+	jobRepo mongodb.JobRepository,
+	bulkUsecase BulkUsecase,
+	deadLetterUsecase DeadLetterUsecase,
+	auditUsecase AuditUsecase,
+) JobUsecase {
+	return &jobUsecase{
+		conf:         conf.Job,
+		jobRepo:      jobRepo,
+		auditUsecase: auditUsecase,
+		handlers: map[models.JobType]jobHandler{
+			models.JobAbandonInactiveSessions: func(ctx context.Context, job *models.Job, progress JobReporter) error {
+				var params models.AbandonInactiveJobParams
+				if err := util.TranscodeJSON(job.Params, &params); err != nil {
+					return fmt.Errorf("invalid job params: %w", err)
+				}
+				return bulkUsecase.AbandonInactive(ctx, time.Duration(params.InactiveDays)*24*time.Hour, progress)
+			},
+			models.JobReassignChatMode: func(ctx context.Context, job *models.Job, progress JobReporter) error {
+				var params models.ReassignChatModeJobParams
+				if err := util.TranscodeJSON(job.Params, &params); err != nil {
+					return fmt.Errorf("invalid job params: %w", err)
+				}
+				return bulkUsecase.ReassignSellerChatMode(ctx, params.SellerID, params.ChatMode, progress)
+			},
+			models.JobRequeueDeadLetters: func(ctx context.Context, job *models.Job, progress JobReporter) error {
+				return deadLetterUsecase.RequeueAll(ctx, progress)
+			},
+		},
+		workerID: primitive.NewObjectID().Hex(),
+	}
+}
+
+func (uc *jobUsecase) Enqueue(ctx context.Context, jobType models.JobType, params any) (*models.Job, error) {
+	if _, ok := uc.handlers[jobType]; !ok {
+		return nil, fmt.Errorf("unknown job type %q", jobType)
+	}
+
+	job := &models.Job{Type: jobType}
+	if err := util.TranscodeJSON(params, &job.Params); err != nil {
+		return nil, fmt.Errorf("invalid job params: %w", err)
+	}
+	if err := uc.jobRepo.Create(ctx, job); err != nil {
+		return nil, err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditJobEnqueue, "job", job.ID.Hex(), nil, job)
+	log.Infow(ctx, "Enqueued job", "job_id", job.ID.Hex(), "type", jobType)
+	return job, nil
+}
+
+func (uc *jobUsecase) Get(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	return uc.jobRepo.GetByID(ctx, id)
+}
+
+func (uc *jobUsecase) List(ctx context.Context, filter models.JobFilter) ([]*models.Job, error) {
+	return uc.jobRepo.List(ctx, filter)
+}
+
+func (uc *jobUsecase) Cancel(ctx context.Context, id primitive.ObjectID) (*models.Job, error) {
+	before, err := uc.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	after, err := uc.jobRepo.Cancel(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditJobCancel, "job", id.Hex(), before, after)
+	return after, nil
+}
+
+func (uc *jobUsecase) RunPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := uc.jobRepo.Claim(ctx, uc.workerID, time.Now().Add(-uc.conf.StaleAfter))
+		if err != nil {
+			log.Errorw(ctx, "Failed to claim job", "error", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		uc.run(ctx, job)
+	}
+}
+
+// run runs a claimed job with heartbeats until it finishes. A job stopped by
+// shutdown is left running, so another worker picks it up once stale.
+func (uc *jobUsecase) run(ctx context.Context, job *models.Job) {
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if job.TenantID != nil {
+		jobCtx = models.WithTenantID(jobCtx, *job.TenantID)
+	}
+
+	log.Infow(ctx, "Running job", "job_id", job.ID.Hex(), "type", job.Type, "attempt", job.Attempts)
+	progress := &jobReporter{}
+	done := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+		ticker := time.NewTicker(uc.conf.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			snapshot, errors := progress.snapshot()
+			running, err := uc.jobRepo.Heartbeat(ctx, job.ID, uc.workerID, snapshot, errors)
+			if err != nil {
+				log.Warnw(ctx, "Failed to record job heartbeat", "job_id", job.ID.Hex(), "error", err)
+				continue
+			}
+			if running == nil {
+				log.Infow(ctx, "Stopping job, it was cancelled or taken over", "job_id", job.ID.Hex())
+				cancel()
+				return
+			}
+		}
+	}()
+
+	var err error
+	if handler, ok := uc.handlers[job.Type]; ok {
+		err = handler(jobCtx, job, progress)
+	} else {
+		err = fmt.Errorf("unknown job type %q", job.Type)
+	}
+	close(done)
+	<-heartbeatDone
+
+	if ctx.Err() != nil {
+		log.Warnw(ctx, "Job interrupted by shutdown", "job_id", job.ID.Hex())
+		return
+	}
+
+	job.Progress, job.Errors = progress.snapshot()
+	switch {
+	case err == nil:
+		job.Status = models.JobSucceeded
+	case jobCtx.Err() != nil:
+		job.Status = models.JobCancelled
+	default:
+		job.Status = models.JobFailed
+		job.Errors = appendJobError(job.Errors, err)
+	}
+	if err := uc.jobRepo.Finish(ctx, job, uc.workerID); err != nil {
+		log.Errorw(ctx, "Failed to finish job", "job_id", job.ID.Hex(), "error", err)
+		return
+	}
+	log.Infow(ctx, "Finished job", "job_id", job.ID.Hex(), "type", job.Type, "status", job.Status,
+		"done", job.Progress.Done, "failed", job.Progress.Failed)
+}
+
+// jobReporter collects the progress of a running job
+type jobReporter struct {
+	mu       sync.Mutex
+	progress models.JobProgress
+	errors   []string
+}
+
+func (r *jobReporter) SetTotal(total int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.Total = total
+}
+
+func (r *jobReporter) Add(done, failed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.Done += done
+	r.progress.Failed += failed
+}
+
+func (r *jobReporter) Fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.progress.Failed++
+	r.errors = appendJobError(r.errors, err)
+}
+
+func (r *jobReporter) snapshot() (models.JobProgress, []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.progress, append([]string(nil), r.errors...)
+}
+
+// appendJobError keeps the first models.MaxJobErrors errors of a job
+func appendJobError(errors []string, err error) []string {
+	if len(errors) >= models.MaxJobErrors {
+		return errors
+	}
+	return append(errors, err.Error())
+}
+
+// StartJobWorker runs the queued jobs, checking every JOB_POLL_INTERVAL,
+// while the app is running
+func StartJobWorker(lc fx.Lifecycle, uc JobUsecase, conf *config.Config) {
+	if conf.Job.PollInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(models.WithAllTenants(context.Background()))
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(conf.Job.PollInterval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+
+					uc.RunPending(ctx)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chotot"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/ttlcache"
+	"github.com/spf13/cast"
+)
+
+const (
+	// maxListingsPerMessage bounds the chotot calls a single pasted message can trigger
+	maxListingsPerMessage = 3
+	listingCacheTTL       = 10 * time.Minute
+)
+
+// ListingExpander turns chotot listing URLs in message text into structured listing data
+type ListingExpander interface {
+	// Expand resolves the listing URLs in text. Listings that cannot be
+	// resolved are left out rather than failing the message.
+	Expand(ctx context.Context, text string) []models.ListingCard
+	// HydrateMessages attaches a product card block for each listing URL in the messages
+	HydrateMessages(ctx context.Context, messages []models.HistoryMessage)
+}
+
+type listingExpander struct {
+	chototClient chotot.Client
+	// keyed by list ID, nil marks listings that failed to resolve
+	cache *ttlcache.Cache[string, *models.ListingCard]
+}
+
+func NewListingExpander(chototClient chotot.Client) ListingExpander {
+	return &listingExpander{
+		chototClient: chototClient,
+		cache:        ttlcache.New[string, *models.ListingCard](listingCacheTTL),
+	}
+}
+
+func (e *listingExpander) Expand(ctx context.Context, text string) []models.ListingCard {
+	ids := chotot.ParseListingIDs(text)
+	if len(ids) > maxListingsPerMessage {
+		ids = ids[:maxListingsPerMessage]
+	}
+
+	var cards []models.ListingCard
+	for _, id := range ids {
+		if card := e.resolve(ctx, id); card != nil {
+			cards = append(cards, *card)
+		}
+	}
+	return cards
+}
+
+func (e *listingExpander) HydrateMessages(ctx context.Context, messages []models.HistoryMessage) {
+	for i := range messages {
+		for _, card := range e.Expand(ctx, messages[i].Message) {
+			messages[i].Blocks = append(messages[i].Blocks, models.MessageBlock{
+				Type:    models.MessageBlockProductCard,
+				Product: &card,
+			})
+		}
+	}
+}
+
+func (e *listingExpander) resolve(ctx context.Context, listID string) *models.ListingCard {
+	if card, ok := e.cache.Get(listID); ok {
+		return card
+	}
+
+	ad, err := e.chototClient.GetAd(ctx, listID)
+	if err != nil {
+		log.Warnw(ctx, "Failed to resolve chotot listing", "list_id", listID, "error", err)
+		e.cache.Set(listID, nil)
+		return nil
+	}
+
+	card := &models.ListingCard{
+		ListID:      listID,
+		Title:       ad.Subject,
+		Price:       cast.ToInt(ad.Price),
+		PriceString: ad.PriceString,
+		Location:    joinNonEmpty(", ", ad.AreaName, ad.RegionName),
+		SellerOID:   ad.AccountOID,
+		URL:         chotot.ListingURL(listID),
+	}
+	if len(ad.Images) > 0 {
+		card.ImageURL = ad.Images[0]
+	}
+	e.cache.Set(listID, card)
+	return card
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+	parts := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, sep)
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+const summarizeContextInstruction = `Summarize the following context of a marketplace chat between a buyer and a seller in at most %d characters.
+Keep every fact a seller assistant may need to answer the buyer: items, prices, conditions, agreements, dates and contact or delivery details.
+Reply with the summary only.`
+
+// SummarizeContext condenses a channel context to at most maxChars with the
+// seller's model key. Nothing is stored.
+func (l *llmUsecase) SummarizeContext(ctx context.Context, sellerID, model, channelContext string, maxChars int) (string, error) {
+	gk, err := l.newGenkit(ctx, sellerID)
+	if err != nil {
+		return "", err
+	}
+
+	genCtx, cancel := context.WithTimeout(ctx, l.config.Timeouts.LLMGenerate)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := genkit.Generate(genCtx, gk,
+		ai.WithMessages(
+			ai.NewSystemTextMessage(fmt.Sprintf(summarizeContextInstruction, maxChars)),
+			ai.NewUserTextMessage(channelContext),
+		),
+		ai.WithModelName(model),
+	)
+	elapsed := time.Since(start)
+	livestats.Observe(models.StatLLMGenerate, float64(elapsed.Milliseconds()))
+	reqstats.FromContext(ctx).AddLLM(elapsed)
+	if err != nil {
+		livestats.Inc(models.StatLLMErrors)
+		return "", fmt.Errorf("failed to summarize context: %w", err)
+	}
+
+	digest := strings.TrimSpace(resp.Text())
+	if digest == "" {
+		return "", fmt.Errorf("failed to summarize context: empty summary")
+	}
+	return textx.Truncate(digest, maxChars), nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/end_session"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reply_message"
+)
+
+// fakeModelName is the model answering every session generation while
+// LLM_FAKE is set
+const fakeModelName = "fake/load-test"
+
+// fakeReply is the text the fake model replies to the buyer with
+const fakeReply = "Thanks for your message, the shop will get back to you shortly."
+
+// defineFakeModel registers a model that waits latency, then replies to the
+// buyer on the first turn and ends the session on the next one, so load tests
+// go through the same tools and writes as a real session. Tools the chat mode
+// does not offer are left out.
+func defineFakeModel(gk *genkit.Genkit, latency time.Duration) {
+	genkit.DefineModel(gk, fakeModelName, &ai.ModelOptions{
+		Label:    "Fake load test model",
+		Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true, Tools: true},
+	}, func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		if !sleepContext(ctx, latency) {
+			return nil, ctx.Err()
+		}
+
+		offered := make(map[string]bool, len(req.Tools))
+		for _, tool := range req.Tools {
+			offered[tool.Name] = true
+		}
+		replied := false
+		for _, message := range req.Messages {
+			replied = replied || message.Role == ai.RoleTool
+		}
+
+		part := ai.NewTextPart(fakeReply)
+		switch {
+		case !replied && offered[reply_message.ToolName]:
+			part = ai.NewToolRequestPart(&ai.ToolRequest{
+				Name:  reply_message.ToolName,
+				Input: map[string]any{"message": fakeReply},
+			})
+		case replied && offered[end_session.ToolName]:
+			part = ai.NewToolRequestPart(&ai.ToolRequest{
+				Name:  end_session.ToolName,
+				Input: map[string]any{"reason": "load test"},
+			})
+		}
+
+		return &ai.ModelResponse{
+			Message:      ai.NewMessage(ai.RoleModel, nil, part),
+			FinishReason: ai.FinishReasonStop,
+			Request:      req,
+			Usage:        &ai.GenerationUsage{},
+		}, nil
+	})
+}
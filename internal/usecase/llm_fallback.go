@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+)
+
+// generate asks chatMode's model for the next turn, retrying it with backoff,
+// then asks the fallback model once. The error wraps models.ErrLLMUnavailable
+// when no model answered. An outage covered by the fallback model is recorded
+// here, the others by acknowledge.
+func (l *llmUsecase) generate(ctx context.Context, session toolsmanager.SessionContext, chatMode *models.ChatMode, messages []*ai.Message, availableTools []ai.Tool) (*ai.ModelResponse, error) {
+	conf := l.config.LLMFallback
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var response *ai.ModelResponse
+		response, err = l.generateResponse(session, chatMode.Model, messages, availableTools, chatMode.OutputSchema)
+		if err == nil {
+			return response, nil
+		}
+		// the message deadline is spent, so there is nothing left to retry for
+		if attempt >= conf.Retries || ctx.Err() != nil {
+			break
+		}
+
+		delay := fallbackBackoff(conf.BaseBackoff, conf.MaxBackoff, attempt)
+		log.Warnw(ctx, "Retrying LLM generation", "model", chatMode.Model,
+			"attempt", attempt+1, "delay_ms", delay.Milliseconds(), "error", err)
+		if !sleepContext(ctx, delay) {
+			break
+		}
+	}
+
+	if conf.Model != "" && ctx.Err() == nil {
+		log.Warnw(ctx, "Chat mode model failed, trying the fallback model",
+			"model", chatMode.Model, "fallback_model", conf.Model, "error", err)
+		response, fallbackErr := l.generateResponse(session, conf.Model, messages, availableTools, chatMode.OutputSchema)
+		if fallbackErr == nil {
+			l.recordOutage(ctx, session, chatMode, err, models.LLMOutageFallback)
+			return response, nil
+		}
+		log.Errorw(ctx, "Fallback model failed", "fallback_model", conf.Model, "error", fallbackErr)
+	}
+	return nil, fmt.Errorf("%w: %v", models.ErrLLMUnavailable, err)
+}
+
+// acknowledge sends the canned acknowledgment when no model could answer the
+// buyer's message, and records the outage. Only the first turn is
+// acknowledged: later turns follow tool calls that may have replied already.
+func (l *llmUsecase) acknowledge(ctx context.Context, session toolsmanager.SessionContext, chatMode *models.ChatMode, genErr error, firstTurn bool) {
+	conf := l.config.LLMFallback
+	outcome := models.LLMOutageFailed
+
+	// held replies are the seller's to send, safe mode gets no acknowledgment,
+	// nor do dry sessions, which send nothing
+	if conf.Acknowledge && firstTurn && !session.RequiresApproval() && !session.DryTools() {
+		// the message deadline may be what failed the model
+		ackCtx := context.WithoutCancel(ctx)
+		err := l.chatAPIClient.SendMessage(ackCtx, &models.OutgoingMessage{
+			ChannelID: session.GetChannelID(),
+			SenderID:  session.GetSenderID(),
+			Message:   l.systemMessages.ForChannel(ackCtx, models.SystemMessageAcknowledge, session.GetChannelID(), ""),
+		})
+		if err != nil {
+			log.Errorw(ctx, "Failed to send outage acknowledgment", "channel_id", session.GetChannelID(), "error", err)
+		} else {
+			outcome = models.LLMOutageAcknowledged
+		}
+	}
+	l.recordOutage(ctx, session, chatMode, genErr, outcome)
+}
+
+// recordOutage counts the outage in the live stats and metrics and stores it.
+// Failures to store are logged and otherwise ignored.
+func (l *llmUsecase) recordOutage(ctx context.Context, session toolsmanager.SessionContext, chatMode *models.ChatMode, genErr error, outcome models.LLMOutageOutcome) {
+	livestats.Inc(models.StatLLMOutagesPrefix + string(outcome))
+	l.outageMetrics.WithLabelValues(string(outcome)).Inc()
+	log.Errorw(ctx, "LLM outage", "model", chatMode.Model, "outcome", outcome,
+		"session_id", session.GetSessionID(), "error", genErr)
+
+	outage := &models.LLMOutage{
+		SessionID:     session.GetSessionID(),
+		ChannelID:     session.GetChannelID(),
+		ChatMode:      chatMode.Name,
+		Model:         chatMode.Model,
+		FallbackModel: l.config.LLMFallback.Model,
+		Outcome:       outcome,
+		Error:         redactPrompt(genErr.Error()),
+		ExpiresAt:     time.Now().Add(l.config.LLMFallback.OutageRetention),
+	}
+	if err := l.llmOutageRepo.Create(context.WithoutCancel(ctx), outage); err != nil {
+		log.Errorw(ctx, "Failed to save llm outage", "session_id", session.GetSessionID(), "error", err)
+	}
+}
+
+// fallbackBackoff is exponential with full jitter, capped at maxBackoff
+func fallbackBackoff(base, maxBackoff time.Duration, retry int) time.Duration {
+	ceiling := base << retry
+	if ceiling <= 0 || ceiling > maxBackoff {
+		ceiling = maxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+// sleepContext waits for d, reporting false when ctx is done first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
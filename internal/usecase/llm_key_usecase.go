@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/googleai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/crypto"
+)
+
+var errKeyEncryptionDisabled = errors.New("LLM_KEY_ENCRYPTION_KEY is not configured")
+
+// LLMKeyUsecase manages provider API keys per tenant and seller. Keys are looked
+// up on every session, so a rotated key takes effect without a restart.
+type LLMKeyUsecase interface {
+	SetKey(ctx context.Context, provider models.LLMProvider, sellerID, apiKey string) (*models.LLMKey, error)
+	ValidateKey(ctx context.Context, provider models.LLMProvider, apiKey string) error
+	DeleteKey(ctx context.Context, provider models.LLMProvider, sellerID string) error
+	ResolveKey(ctx context.Context, provider models.LLMProvider, sellerID string) (string, error)
+}
+
+type llmKeyUsecase struct {
+	config         *config.Config
+	cipher         crypto.Cipher
+	llmKeyRepo     mongodb.LLMKeyRepository
+	googleAIClient googleai.Client
+	auditUsecase   AuditUsecase
+}
+
+func NewLLMKeyUsecase(
+	cfg *config.Config,
+	llmKeyRepo mongodb.LLMKeyRepository,
+	googleAIClient googleai.Client,
+	auditUsecase AuditUsecase,
+) (LLMKeyUsecase, error) {
+	uc := &llmKeyUsecase{
+		config:         cfg,
+		llmKeyRepo:     llmKeyRepo,
+		googleAIClient: googleAIClient,
+		auditUsecase:   auditUsecase,
+	}
+
+	if cfg.LLM.KeyEncryptionKey != "" {
+		cipher, err := crypto.NewAESGCMFromBase64(cfg.LLM.KeyEncryptionKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM key encryption key: %w", err)
+		}
+		uc.cipher = cipher
+	}
+
+	return uc, nil
+}
+
+func (uc *llmKeyUsecase) SetKey(ctx context.Context, provider models.LLMProvider, sellerID, apiKey string) (*models.LLMKey, error) {
+	if uc.cipher == nil {
+		return nil, errKeyEncryptionDisabled
+	}
+	if err := checkKeyScope(ctx, sellerID); err != nil {
+		return nil, err
+	}
+	if err := uc.ValidateKey(ctx, provider, apiKey); err != nil {
+		return nil, err
+	}
+
+	before, err := uc.llmKeyRepo.Get(ctx, provider, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get llm key: %w", err)
+	}
+
+	encrypted, err := uc.cipher.Encrypt([]byte(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt llm key: %w", err)
+	}
+
+	key := &models.LLMKey{
+		SellerID:     sellerID,
+		Provider:     provider,
+		EncryptedKey: encrypted,
+		Hint:         keyHint(apiKey),
+	}
+	if err := uc.llmKeyRepo.Upsert(ctx, key); err != nil {
+		return nil, fmt.Errorf("failed to store llm key: %w", err)
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditLLMKeySet, "llm_key", key.ID.Hex(), before, key)
+	log.Infow(ctx, "LLM key stored", "provider", provider, "seller_id", sellerID, "hint", key.Hint)
+	return key, nil
+}
+
+func (uc *llmKeyUsecase) ValidateKey(ctx context.Context, provider models.LLMProvider, apiKey string) error {
+	switch provider {
+	case models.LLMProviderGoogleAI:
+		if err := uc.googleAIClient.ValidateAPIKey(ctx, apiKey); err != nil {
+			log.Infow(ctx, "LLM key validation failed", "provider", provider, "error", err)
+			return models.ErrInvalidLLMKey
+		}
+		return nil
+	default:
+		return models.ErrUnsupportedLLMProvider
+	}
+}
+
+func (uc *llmKeyUsecase) DeleteKey(ctx context.Context, provider models.LLMProvider, sellerID string) error {
+	if err := checkKeyScope(ctx, sellerID); err != nil {
+		return err
+	}
+	before, err := uc.llmKeyRepo.Get(ctx, provider, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to get llm key: %w", err)
+	}
+	if before == nil {
+		return models.ErrNotFound
+	}
+
+	if err := uc.llmKeyRepo.Delete(ctx, provider, sellerID); err != nil {
+		return fmt.Errorf("failed to delete llm key: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditLLMKeyDelete, "llm_key", before.ID.Hex(), before, nil)
+	return nil
+}
+
+// checkKeyScope lets tenants manage their keys and admins the keys of sellers
+// outside of tenants. Keys are never written without a tenant and seller: that
+// scope is the deployment's key, which only comes from the config.
+func checkKeyScope(ctx context.Context, sellerID string) error {
+	if _, ok := models.TenantIDFromContext(ctx); ok {
+		return nil
+	}
+	if models.ActorFromContext(ctx).Type != models.ActorTypeAdmin {
+		return models.ErrTenantRequired
+	}
+	if sellerID == "" {
+		return models.ErrGlobalLLMKey
+	}
+	return nil
+}
+
+// ResolveKey picks the seller key, then the tenant key, then the global config key
+func (uc *llmKeyUsecase) ResolveKey(ctx context.Context, provider models.LLMProvider, sellerID string) (string, error) {
+	if uc.cipher != nil {
+		scopes := []string{sellerID, ""}
+		if sellerID == "" {
+			scopes = scopes[1:]
+		}
+
+		for _, scope := range scopes {
+			key, err := uc.llmKeyRepo.Get(ctx, provider, scope)
+			if err != nil {
+				return "", err
+			}
+			if key == nil {
+				continue
+			}
+
+			plain, err := uc.cipher.Decrypt(key.EncryptedKey)
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt llm key %s: %w", key.ID.Hex(), err)
+			}
+			return string(plain), nil
+		}
+	}
+
+	switch provider {
+	case models.LLMProviderGoogleAI:
+		return uc.config.SecretValue(ctx, config.SecretGoogleAIAPIKey), nil
+	default:
+		return "", models.ErrUnsupportedLLMProvider
+	}
+}
+
+func keyHint(apiKey string) string {
+	if len(apiKey) <= 4 {
+		return "****"
+	}
+	return "..." + apiKey[len(apiKey)-4:]
+}
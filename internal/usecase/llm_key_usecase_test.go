@@ -0,0 +1,94 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeLLMKeyRepo holds the keys of one tenant scope by seller
+type fakeLLMKeyRepo struct {
+	mongodb.LLMKeyRepository
+	keys map[string]*models.LLMKey
+}
+
+func (r *fakeLLMKeyRepo) Get(ctx context.Context, provider models.LLMProvider, sellerID string) (*models.LLMKey, error) {
+	return r.keys[sellerID], nil
+}
+
+func (r *fakeLLMKeyRepo) Upsert(ctx context.Context, key *models.LLMKey) error {
+	key.ID = primitive.NewObjectID()
+	r.keys[key.SellerID] = key
+	return nil
+}
+
+func (r *fakeLLMKeyRepo) Delete(ctx context.Context, provider models.LLMProvider, sellerID string) error {
+	delete(r.keys, sellerID)
+	return nil
+}
+
+type fakeGoogleAI struct{}
+
+func (fakeGoogleAI) ValidateAPIKey(ctx context.Context, apiKey string) error {
+	return nil
+}
+
+func TestLLMKeyScopes(t *testing.T) {
+	t.Parallel()
+
+	anonymous := models.WithActor(context.Background(), models.Actor{Type: models.ActorTypeAnonymous})
+	admin := models.WithAllTenants(models.WithActor(context.Background(), models.Actor{Type: models.ActorTypeAdmin}))
+	tenant := models.WithTenantID(models.WithActor(context.Background(), models.Actor{Type: models.ActorTypeAPIKey}), primitive.NewObjectID())
+
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		sellerID string
+		wantErr  error
+	}{
+		{"Anonymous Seller Key", anonymous, "seller-1", models.ErrTenantRequired},
+		{"Anonymous Global Key", anonymous, "", models.ErrTenantRequired},
+		{"Admin Seller Key", admin, "seller-1", nil},
+		{"Admin Global Key", admin, "", models.ErrGlobalLLMKey},
+		{"Tenant Seller Key", tenant, "seller-1", nil},
+		{"Tenant Key", tenant, "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			conf := &config.Config{}
+			conf.LLM.KeyEncryptionKey = base64.StdEncoding.EncodeToString(make([]byte, 32))
+			repo := &fakeLLMKeyRepo{keys: map[string]*models.LLMKey{}}
+			uc, err := usecase.NewLLMKeyUsecase(conf, repo, fakeGoogleAI{}, &fakeAudit{})
+			require.NoError(t, err)
+
+			_, err = uc.SetKey(tt.ctx, models.LLMProviderGoogleAI, tt.sellerID, "AIza-test")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, repo.keys)
+			} else {
+				require.NoError(t, err)
+				assert.Contains(t, repo.keys, tt.sellerID)
+			}
+
+			// deletes are allowed in the same scopes as writes
+			repo.keys[tt.sellerID] = &models.LLMKey{ID: primitive.NewObjectID(), SellerID: tt.sellerID}
+			err = uc.DeleteKey(tt.ctx, models.LLMProviderGoogleAI, tt.sellerID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, repo.keys, tt.sellerID)
+			} else {
+				require.NoError(t, err)
+				assert.NotContains(t, repo.keys, tt.sellerID)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/end_session"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+// recordedModelName is the model that answers with the turns of a transcript
+const recordedModelName = "replay/recorded"
+
+func (l *llmUsecase) Replay(ctx context.Context, chatMode *models.ChatMode, transcript *models.SessionTranscript, provider models.ReplayProvider) (*models.ReplayResult, error) {
+	if transcript.Input == nil {
+		return nil, fmt.Errorf("transcript has no recorded input")
+	}
+
+	result := &models.ReplayResult{
+		SessionID:      transcript.SessionID.Hex(),
+		SourceChatMode: transcript.ChatMode,
+		Original:       recordedToolCalls(transcript),
+	}
+	data := &PromptData{
+		ChannelInfo:    transcript.Input.ChannelInfo,
+		SessionID:      transcript.SessionID.Hex(),
+		UserID:         transcript.UserID,
+		SenderRole:     transcript.Input.SenderRole,
+		Message:        transcript.Input.Message,
+		RecentMessages: transcript.Input.RecentMessages,
+		Listings:       transcript.Input.Listings,
+		BuyerLanguage:  textx.DetectLanguage(transcript.Input.Message),
+	}
+	if err := l.validateInputs(ctx, chatMode, data); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	shouldProcess, err := l.evaluateCondition(chatMode.Condition, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate when condition: %w", err)
+	}
+	if !shouldProcess {
+		result.Skipped = true
+		compareToolCalls(result)
+		return result, nil
+	}
+
+	prompt, err := l.buildPrompt(chatMode.PromptTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	sellerID := findSellerIDFromChannel(data.ChannelInfo)
+	if sellerID == "" {
+		sellerID = "chat-bot"
+	}
+	model := chatMode.Model
+	var gk *genkit.Genkit
+	if provider == models.ReplayProviderLive {
+		if gk, err = l.newGenkit(ctx, sellerID); err != nil {
+			return nil, err
+		}
+	} else {
+		gk = genkit.Init(ctx)
+		defineRecordedModel(gk, transcript)
+		model = recordedModelName
+	}
+
+	// Tools are offered to the model but never run: generations return their
+	// tool requests, which are answered with the outputs originally recorded
+	session := toolsmanager.NewSessionContext(ctx, toolsmanager.SessionContextConfig{
+		Genkit:    gk,
+		SessionID: transcript.SessionID,
+		ChannelID: l.getChannelID(data),
+		UserID:    data.UserID,
+		SenderID:  sellerID,
+		Timeouts:  l.config.Timeouts,
+	})
+	availableTools, err := l.toolsManager.GetToolsForNames(session, chatMode.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tools: %w", err)
+	}
+	var toolRefs []ai.ToolRef
+	for _, tool := range availableTools {
+		toolRefs = append(toolRefs, tool)
+	}
+
+	messages := l.buildInitialMessages(prompt, data, session)
+	outputs := newRecordedToolOutputs(transcript)
+	for i := 1; i <= chatMode.MaxIterations; i++ {
+		response, err := l.generateToolRequests(ctx, gk, model, messages, toolRefs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate response: %w", err)
+		}
+
+		toolRequests := response.ToolRequests()
+		if len(toolRequests) == 0 {
+			break
+		}
+		messages = append(messages, response.Message)
+
+		var parts []*ai.Part
+		ended := false
+		for _, req := range toolRequests {
+			result.Candidate = append(result.Candidate, models.ReplayToolCall{
+				Iteration: i,
+				Name:      req.Name,
+				Input:     encodeToolData(req.Input),
+			})
+			parts = append(parts, ai.NewToolResponsePart(&ai.ToolResponse{
+				Name:   req.Name,
+				Ref:    req.Ref,
+				Output: outputs.next(req.Name),
+			}))
+			ended = ended || req.Name == end_session.ToolName
+		}
+		messages = append(messages, ai.NewMessage(ai.RoleTool, nil, parts...))
+		if ended {
+			break
+		}
+	}
+
+	compareToolCalls(result)
+	return result, nil
+}
+
+func (l *llmUsecase) generateToolRequests(ctx context.Context, gk *genkit.Genkit, model string, messages []*ai.Message, toolRefs []ai.ToolRef) (*ai.ModelResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.config.Timeouts.LLMGenerate)
+	defer cancel()
+
+	return genkit.Generate(ctx, gk,
+		ai.WithMessages(messages...),
+		ai.WithModelName(model),
+		ai.WithTools(toolRefs...),
+		ai.WithReturnToolRequests(true),
+	)
+}
+
+// defineRecordedModel registers a model that answers the n-th generation
+// with the model text and tool requests recorded for iteration n, leaving
+// out requests for tools the candidate does not offer
+func defineRecordedModel(gk *genkit.Genkit, transcript *models.SessionTranscript) {
+	turn := 0
+	genkit.DefineModel(gk, recordedModelName, &ai.ModelOptions{
+		Label:    "Recorded transcript",
+		Supports: &ai.ModelSupports{Multiturn: true, SystemRole: true, Tools: true},
+	}, func(ctx context.Context, req *ai.ModelRequest, _ ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+		turn++
+		offered := make(map[string]bool, len(req.Tools))
+		for _, tool := range req.Tools {
+			offered[tool.Name] = true
+		}
+
+		var parts []*ai.Part
+		for _, entry := range transcript.Entries {
+			if entry.Iteration != turn {
+				continue
+			}
+			switch {
+			case entry.Type == models.TranscriptModel:
+				parts = append(parts, ai.NewTextPart(entry.Text))
+			case entry.Type == models.TranscriptToolRequest && offered[entry.ToolName]:
+				parts = append(parts, ai.NewToolRequestPart(&ai.ToolRequest{
+					Name:  entry.ToolName,
+					Ref:   entry.ToolRef,
+					Input: decodeToolData(entry.ToolData),
+				}))
+			}
+		}
+		if len(parts) == 0 {
+			parts = append(parts, ai.NewTextPart(""))
+		}
+
+		return &ai.ModelResponse{
+			Message:      ai.NewMessage(ai.RoleModel, nil, parts...),
+			FinishReason: ai.FinishReasonStop,
+			Request:      req,
+		}, nil
+	})
+}
+
+func recordedToolCalls(transcript *models.SessionTranscript) []models.ReplayToolCall {
+	var calls []models.ReplayToolCall
+	for _, entry := range transcript.Entries {
+		if entry.Type == models.TranscriptToolRequest {
+			calls = append(calls, models.ReplayToolCall{
+				Iteration: entry.Iteration,
+				Name:      entry.ToolName,
+				Input:     entry.ToolData,
+			})
+		}
+	}
+	return calls
+}
+
+// recordedToolOutputs hands out the recorded outputs of each tool in the
+// order they were produced
+type recordedToolOutputs map[string][]any
+
+func newRecordedToolOutputs(transcript *models.SessionTranscript) recordedToolOutputs {
+	outputs := recordedToolOutputs{}
+	for _, entry := range transcript.Entries {
+		if entry.Type != models.TranscriptToolResponse {
+			continue
+		}
+		var output any = decodeToolData(entry.ToolData)
+		if entry.Error != "" {
+			output = map[string]any{"error": entry.Error}
+		}
+		outputs[entry.ToolName] = append(outputs[entry.ToolName], output)
+	}
+	return outputs
+}
+
+func (o recordedToolOutputs) next(toolName string) any {
+	queue := o[toolName]
+	if len(queue) == 0 {
+		return map[string]any{"result": "no recorded output, assume the call succeeded"}
+	}
+	o[toolName] = queue[1:]
+	return queue[0]
+}
+
+// compareToolCalls marks result changed when the tools were called in a
+// different order, and lists the calls only one side made
+func compareToolCalls(result *models.ReplayResult) {
+	counts := map[string]int{}
+	for _, call := range result.Original {
+		counts[call.Name]++
+	}
+	for _, call := range result.Candidate {
+		counts[call.Name]--
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result.Diff = nil
+	for _, name := range names {
+		for n := counts[name]; n > 0; n-- {
+			result.Diff = append(result.Diff, "-"+name)
+		}
+		for n := counts[name]; n < 0; n++ {
+			result.Diff = append(result.Diff, "+"+name)
+		}
+	}
+
+	result.Changed = len(result.Original) != len(result.Candidate)
+	for i := 0; !result.Changed && i < len(result.Original); i++ {
+		result.Changed = result.Original[i].Name != result.Candidate[i].Name
+	}
+}
+
+func decodeToolData(data string) any {
+	if data == "" {
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal([]byte(data), &v); err != nil {
+		return data
+	}
+	return v
+}
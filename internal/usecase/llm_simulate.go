@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/end_session"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reply_message"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/redact"
+)
+
+// Simulate runs the agent loop of chatMode on data in a sandbox session. The
+// tools run dry with data.ToolFixtures, and neither the session, its usage
+// nor its transcript are stored. Like a replay, the model returns its tool
+// requests so each call is traced.
+func (l *llmUsecase) Simulate(ctx context.Context, chatMode *models.ChatMode, data *PromptData) (*models.SimulationResult, error) {
+	if len(chatMode.OutputSchema) > 0 {
+		return nil, fmt.Errorf("%w: chat mode %q has an output schema", models.ErrInvalidSimulation, chatMode.Name)
+	}
+	data.DryTools = true
+
+	result := &models.SimulationResult{
+		ChatMode:  chatMode.Name,
+		Model:     chatMode.Model,
+		Replies:   []string{},
+		ToolCalls: []models.SimulatedToolCall{},
+	}
+	if err := l.validateInputs(ctx, chatMode, data); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	shouldProcess, err := l.evaluateCondition(chatMode.Condition, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate when condition: %w", err)
+	}
+	if !shouldProcess {
+		result.Skipped = true
+		return result, nil
+	}
+
+	prompt, err := l.buildPrompt(chatMode.PromptTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	session, err := l.createSessionContext(ctx, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session context: %w", err)
+	}
+	availableTools, err := l.toolsManager.GetToolsForNames(session, chatMode.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tools: %w", err)
+	}
+	var toolRefs []ai.ToolRef
+	for _, tool := range availableTools {
+		toolRefs = append(toolRefs, tool)
+	}
+
+	messages := l.buildInitialMessages(prompt, data, session)
+	transcript := newTranscriptRecorder(config.TranscriptConfig{Enabled: true}, chatMode, data, session.GetChannelID())
+	transcript.addMessages(messages)
+
+	for i := 1; i <= chatMode.MaxIterations; i++ {
+		transcript.startIteration(i)
+		response, err := l.generateToolRequests(ctx, session.Genkit(), chatMode.Model, messages, toolRefs)
+		if err != nil {
+			result.Error = redact.Secrets(fmt.Sprintf("failed to generate response: %v", err))
+			break
+		}
+		if text := response.Text(); text != "" {
+			transcript.addModelText(text)
+		}
+
+		toolRequests := response.ToolRequests()
+		if len(toolRequests) == 0 {
+			break
+		}
+		messages = append(messages, response.Message)
+
+		parts, err := l.executeToolRequests(ctx, toolRequests, availableTools, session, transcript)
+		if err != nil {
+			result.Error = redact.Secrets(err.Error())
+			break
+		}
+		if len(parts) > 0 {
+			messages = append(messages, ai.NewMessage(ai.RoleTool, nil, parts...))
+		}
+		if slices.ContainsFunc(toolRequests, func(req *ai.ToolRequest) bool { return req.Name == end_session.ToolName }) {
+			break
+		}
+	}
+
+	result.Transcript = transcript.transcript.Entries
+	for _, entry := range result.Transcript {
+		switch entry.Type {
+		case models.TranscriptToolRequest:
+			result.ToolCalls = append(result.ToolCalls, models.SimulatedToolCall{
+				Iteration: entry.Iteration,
+				Name:      entry.ToolName,
+				Input:     entry.ToolData,
+			})
+			var args reply_message.ReplyMessageArgs
+			if entry.ToolName == reply_message.ToolName && json.Unmarshal([]byte(entry.ToolData), &args) == nil {
+				result.Replies = append(result.Replies, args.Message)
+			}
+		case models.TranscriptToolResponse:
+			// responses follow their request
+			if n := len(result.ToolCalls); n > 0 {
+				result.ToolCalls[n-1].Output = entry.ToolData
+				result.ToolCalls[n-1].Error = entry.Error
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// runStructuredOutput asks the model once for a response matching the chat
+// mode's output schema and stores it on the session. Nothing is sent to the
+// buyer: structured chat modes feed other flows, not the conversation.
+func (l *llmUsecase) runStructuredOutput(ctx context.Context, chatMode *models.ChatMode, messages []*ai.Message, session toolsmanager.SessionContext, transcript *transcriptRecorder, promptLog *promptLogger, tracer *sessionTracer) error {
+	transcript.startIteration(1)
+
+	start := time.Now()
+	response, err := l.generate(ctx, session, chatMode, messages, nil)
+	latency := time.Since(start)
+	promptLog.record(ctx, 1, messages, response, latency, err)
+	tracer.record(ctx, 1, messages, response, latency, err)
+	if err != nil {
+		if errors.Is(err, models.ErrLLMUnavailable) {
+			// not a buyer-facing turn, so there is nothing to acknowledge
+			l.acknowledge(ctx, session, chatMode, err, false)
+		}
+		return fmt.Errorf("failed to generate response: %w", err)
+	}
+	l.recordUsage(ctx, session, chatMode, response)
+	transcript.addModelText(response.Text())
+
+	var output map[string]any
+	if err := response.Output(&output); err != nil {
+		return fmt.Errorf("failed to parse structured output: %w", err)
+	}
+
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return fmt.Errorf("invalid session ID format: %w", err)
+	}
+	if err := l.sessionRepo.SetOutput(ctx, sessionID, output); err != nil {
+		return fmt.Errorf("failed to save structured output: %w", err)
+	}
+
+	log.Infow(ctx, "Stored structured output", "chat_mode", chatMode.Name, "session_id", sessionID.Hex())
+	return nil
+}
+
+// outputSchemaMiddleware constrains the model to JSON matching schema. Genkit
+// only derives schemas from Go types, so the chat mode's raw schema is set on
+// the model request directly.
+func outputSchemaMiddleware(schema map[string]any) ai.ModelMiddleware {
+	return func(next ai.ModelFunc) ai.ModelFunc {
+		return func(ctx context.Context, req *ai.ModelRequest, cb ai.ModelStreamCallback) (*ai.ModelResponse, error) {
+			req.Output = &ai.ModelOutputConfig{
+				Format:      "json",
+				ContentType: "application/json",
+				Schema:      schema,
+				Constrained: true,
+			}
+			return next(ctx, req, cb)
+		}
+	}
+}
+
+// normalizeOutputSchema turns a schema decoded from YAML or BSON into plain
+// JSON values, which is what the model plugins expect
+func normalizeOutputSchema(schema map[string]any) (map[string]any, error) {
+	var normalized map[string]any
+	if err := util.TranscodeJSON(schema, &normalized); err != nil {
+		return nil, fmt.Errorf("invalid output schema: %w", err)
+	}
+	if _, ok := normalized["type"].(string); !ok {
+		return nil, fmt.Errorf("output schema type is required")
+	}
+	return normalized, nil
+}
+
+// sameOutputSchema compares schemas by their JSON encoding, since the stored
+// one decodes with BSON types
+func sameOutputSchema(a, b map[string]any) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	aJSON, aErr := json.Marshal(a)
+	bJSON, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJSON, bJSON)
+}
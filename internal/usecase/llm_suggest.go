@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/firebase/genkit/go/ai"
+	"github.com/firebase/genkit/go/genkit"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
+)
+
+const suggestRepliesInstruction = `You are not talking to the buyer yourself and no tools are available.
+Write %d different replies the seller could send next to the buyer's last message, each ready to send as is.
+Vary them, e.g. a short answer, a detailed one and one that asks a question back.`
+
+// replyCandidatesOutput is the structured output asked from the model
+type replyCandidatesOutput struct {
+	Replies []string `json:"replies"`
+}
+
+// SuggestReplies drafts count replies to data.Message with the chat mode's
+// prompt in a single generation. No tools are offered and no session is stored.
+func (l *llmUsecase) SuggestReplies(ctx context.Context, chatMode *models.ChatMode, data *PromptData, count int) ([]string, error) {
+	if chatMode.PromptTemplate == "" {
+		return nil, fmt.Errorf("chat mode prompt template is required")
+	}
+
+	prompt, err := l.buildPrompt(chatMode.PromptTemplate, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build prompt: %w", err)
+	}
+
+	sellerID := findSellerIDFromChannel(data.ChannelInfo)
+	if sellerID == "" {
+		sellerID = "chat-bot"
+	}
+	gk, err := l.newGenkit(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only needed to build the messages, tools never run
+	session := toolsmanager.NewSessionContext(ctx, toolsmanager.SessionContextConfig{
+		Genkit:    gk,
+		ChannelID: l.getChannelID(data),
+		UserID:    data.UserID,
+		SenderID:  sellerID,
+		Timeouts:  l.config.Timeouts,
+	})
+	messages := l.buildInitialMessages(prompt, data, session)
+	messages = append(messages, ai.NewSystemTextMessage(fmt.Sprintf(suggestRepliesInstruction, count)))
+
+	genCtx, cancel := context.WithTimeout(ctx, l.config.Timeouts.LLMGenerate)
+	defer cancel()
+
+	start := time.Now()
+	output, _, err := genkit.GenerateData[replyCandidatesOutput](genCtx, gk,
+		ai.WithMessages(messages...),
+		ai.WithModelName(chatMode.Model),
+	)
+	elapsed := time.Since(start)
+	livestats.Observe(models.StatLLMGenerate, float64(elapsed.Milliseconds()))
+	reqstats.FromContext(ctx).AddLLM(elapsed)
+	if err != nil {
+		livestats.Inc(models.StatLLMErrors)
+		return nil, fmt.Errorf("failed to generate replies: %w", err)
+	}
+
+	replies := compactStrings(output.Replies)
+	if len(replies) > count {
+		replies = replies[:count]
+	}
+	return replies, nil
+}
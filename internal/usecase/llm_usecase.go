@@ -3,9 +3,11 @@ package usecase
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"text/template"
+	"time"
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/firebase/genkit/go/ai"
@@ -13,27 +15,65 @@ import (
 	"github.com/firebase/genkit/go/plugins/googlegenai"
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/add_item"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/end_session"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/fetch_full_context"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/fetch_messages"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/get_buyer_profile"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/list_products"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/purchase_intent"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reply_message"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reserve_item"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/set_outcome"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/switch_item"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/reqstats"
 	"github.com/nguyentranbao-ct/chat-bot/pkg/util"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // LLMUsecase defines the interface for LLM operations
 type LLMUsecase interface {
 	ProcessMessage(ctx context.Context, chatMode *models.ChatMode, data *PromptData) error
+	// Replay runs the input of a logged session through chatMode without
+	// executing any tool, and compares its tool calls with the original ones
+	Replay(ctx context.Context, chatMode *models.ChatMode, transcript *models.SessionTranscript, provider models.ReplayProvider) (*models.ReplayResult, error)
+	// SuggestReplies drafts candidate replies without running tools or
+	// creating a session
+	SuggestReplies(ctx context.Context, chatMode *models.ChatMode, data *PromptData, count int) ([]string, error)
+	// Simulate runs chatMode on data in a sandbox session with dry tools,
+	// tracing its tool calls
+	Simulate(ctx context.Context, chatMode *models.ChatMode, data *PromptData) (*models.SimulationResult, error)
+	// SummarizeContext condenses a channel context to at most maxChars
+	SummarizeContext(ctx context.Context, sellerID, model, channelContext string, maxChars int) (string, error)
+	// ValidateChatMode renders the condition and prompt of chatMode against
+	// sample data and checks its settings and tools, returning the problems found
+	ValidateChatMode(chatMode *models.ChatMode) []string
 }
 
 // llmUsecase is the concrete implementation
 type llmUsecase struct {
-	toolsManager toolsmanager.ToolsManager
-	sessionRepo  mongodb.ChatSessionRepository
-	config       *config.Config
+	toolsManager   toolsmanager.ToolsManager
+	sessionRepo    mongodb.ChatSessionRepository
+	llmKeyUsecase  LLMKeyUsecase
+	transcriptRepo mongodb.TranscriptRepository
+	promptLogRepo  mongodb.PromptLogRepository
+	traceRepo      mongodb.SessionTraceRepository
+	llmOutageRepo  mongodb.LLMOutageRepository
+	chatAPIClient  chatapi.Client
+	budgetUsecase  BudgetUsecase
+	costUsecase    CostUsecase
+	tenantUsecase  TenantUsecase
+	systemMessages SystemMessageUsecase
+	config         *config.Config
+	// outageMetrics counts LLM outages by outcome
+	outageMetrics *prometheus.CounterVec
+	// toolFixtures are the default results of tools in dry sessions
+	toolFixtures map[string]any
 }
 
 // NewLLMUsecase creates a new LLM usecase instance
@@ -41,11 +81,27 @@ func NewLLMUsecase(
 	cfg *config.Config,
 	toolsManager toolsmanager.ToolsManager,
 	sessionRepo mongodb.ChatSessionRepository,
+	llmKeyUsecase LLMKeyUsecase,
+	transcriptRepo mongodb.TranscriptRepository,
+	promptLogRepo mongodb.PromptLogRepository,
+	traceRepo mongodb.SessionTraceRepository,
+	llmOutageRepo mongodb.LLMOutageRepository,
+	chatAPIClient chatapi.Client,
+	budgetUsecase BudgetUsecase,
+	costUsecase CostUsecase,
+	tenantUsecase TenantUsecase,
+	systemMessages SystemMessageUsecase,
 	endSessionTool end_session.Tool,
 	fetchMessagesTool fetch_messages.Tool,
 	replyMessageTool reply_message.Tool,
 	purchaseIntentTool purchase_intent.Tool,
 	listProductsTool list_products.Tool,
+	reserveItemTool reserve_item.Tool,
+	getBuyerProfileTool get_buyer_profile.Tool,
+	setOutcomeTool set_outcome.Tool,
+	addItemTool add_item.Tool,
+	switchItemTool switch_item.Tool,
+	fetchFullContextTool fetch_full_context.Tool,
 ) (LLMUsecase, error) {
 	util.PanicOnError(
 		"register tools",
@@ -54,12 +110,40 @@ func NewLLMUsecase(
 		toolsManager.AddTool(replyMessageTool),
 		toolsManager.AddTool(purchaseIntentTool),
 		toolsManager.AddTool(listProductsTool),
+		toolsManager.AddTool(reserveItemTool),
+		toolsManager.AddTool(getBuyerProfileTool),
+		toolsManager.AddTool(setOutcomeTool),
+		toolsManager.AddTool(addItemTool),
+		toolsManager.AddTool(switchItemTool),
+		toolsManager.AddTool(fetchFullContextTool),
 	)
 
+	outageMetrics, err := util.GetCounterVec("llm_outages_total", "outcome")
+	if err != nil {
+		return nil, fmt.Errorf("get counter vec: %w", err)
+	}
+
+	toolFixtures, err := loadToolFixtures()
+	if err != nil {
+		return nil, err
+	}
+
 	return &llmUsecase{
-		toolsManager: toolsManager,
-		sessionRepo:  sessionRepo,
-		config:       cfg,
+		toolsManager:   toolsManager,
+		sessionRepo:    sessionRepo,
+		llmKeyUsecase:  llmKeyUsecase,
+		transcriptRepo: transcriptRepo,
+		promptLogRepo:  promptLogRepo,
+		traceRepo:      traceRepo,
+		llmOutageRepo:  llmOutageRepo,
+		chatAPIClient:  chatAPIClient,
+		budgetUsecase:  budgetUsecase,
+		costUsecase:    costUsecase,
+		tenantUsecase:  tenantUsecase,
+		systemMessages: systemMessages,
+		config:         cfg,
+		outageMetrics:  outageMetrics,
+		toolFixtures:   toolFixtures,
 	}, nil
 }
 
@@ -71,6 +155,34 @@ type PromptData struct {
 	SenderRole     string
 	Message        string
 	RecentMessages *models.MessageHistory
+	// Listings are the chotot listings linked in Message
+	Listings []models.ListingCard
+	// Items are the listings discussed in the room, ChannelInfo describes
+	// the current one
+	Items []models.ChannelItem
+	// Intent is the classified label of Message
+	Intent models.MessageIntent
+	// Sentiment is the score of Message
+	Sentiment *models.SentimentScore
+	// BuyerLanguage is the language the buyer mostly writes in, "vi" or
+	// "en", empty when unknown
+	BuyerLanguage string
+	// PreviousConversations recap the buyer's other chats with the seller
+	PreviousConversations []models.ConversationRecap
+	// Persona is the tenant and seller persona, nil when none is set
+	Persona *models.Persona
+	// RequireApproval holds the bot's replies for the seller's approval
+	RequireApproval bool
+	// ContextCompacted tells that ChannelInfo.Context is a digest of a longer
+	// context, available through FetchFullContext
+	ContextCompacted bool
+	// ReplyWindow is how long after the buyer's last message the partner
+	// delivers free-form replies, 0 when it is not limited
+	ReplyWindow time.Duration
+	// DryTools runs the session's tools dry, answering with ToolFixtures
+	// over the default fixtures
+	DryTools     bool
+	ToolFixtures map[string]any
 }
 
 // ProcessMessage processes a message with early validation and deferred expensive operations
@@ -114,8 +226,19 @@ func (l *llmUsecase) ProcessMessage(ctx context.Context, chatMode *models.ChatMo
 	// PHASE 6: Build initial messages
 	messages := l.buildInitialMessages(prompt, data, session)
 
-	// PHASE 7: Run AI agent loop
-	if err := l.runAgentLoop(ctx, chatMode, messages, availableTools, session); err != nil {
+	transcript := newTranscriptRecorder(l.config.Transcript, chatMode, data, session.GetChannelID())
+	transcript.addMessages(messages)
+	promptLog := newPromptLogger(l.config.PromptLog, l.promptLogRepo, chatMode, data)
+	tracer := newSessionTracer(l.config.SessionTrace, l.traceRepo, chatMode, data)
+
+	// PHASE 7: Run AI agent loop, or a single structured generation
+	if len(chatMode.OutputSchema) > 0 {
+		err = l.runStructuredOutput(ctx, chatMode, messages, session, transcript, promptLog, tracer)
+	} else {
+		err = l.runAgentLoop(ctx, chatMode, messages, availableTools, session, transcript, promptLog, tracer)
+	}
+	transcript.save(ctx, l.transcriptRepo, err)
+	if err != nil {
 		return err
 	}
 
@@ -125,21 +248,8 @@ func (l *llmUsecase) ProcessMessage(ctx context.Context, chatMode *models.ChatMo
 
 // validateInputs performs comprehensive validation before any processing
 func (l *llmUsecase) validateInputs(ctx context.Context, chatMode *models.ChatMode, data *PromptData) error {
-	// Validate chat mode
-	if chatMode == nil {
-		return fmt.Errorf("chat mode is required")
-	}
-	if chatMode.Name == "" {
-		return fmt.Errorf("chat mode name is required")
-	}
-	if chatMode.Model == "" {
-		return fmt.Errorf("chat mode model is required")
-	}
-	if chatMode.PromptTemplate == "" {
-		return fmt.Errorf("chat mode prompt template is required")
-	}
-	if chatMode.MaxIterations <= 0 {
-		return fmt.Errorf("chat mode max iterations must be positive")
+	if err := validateChatModeSettings(chatMode); err != nil {
+		return err
 	}
 
 	// Validate prompt data
@@ -177,6 +287,34 @@ func (l *llmUsecase) validateInputs(ctx context.Context, chatMode *models.ChatMo
 	return nil
 }
 
+// validateChatModeSettings rejects chat modes missing what every run needs
+func validateChatModeSettings(chatMode *models.ChatMode) error {
+	if chatMode == nil {
+		return fmt.Errorf("chat mode is required")
+	}
+	if chatMode.Name == "" {
+		return fmt.Errorf("chat mode name is required")
+	}
+	if chatMode.Model == "" {
+		return fmt.Errorf("chat mode model is required")
+	}
+	if chatMode.PromptTemplate == "" {
+		return fmt.Errorf("chat mode prompt template is required")
+	}
+	if chatMode.MaxIterations <= 0 {
+		return fmt.Errorf("chat mode max iterations must be positive")
+	}
+	if len(chatMode.OutputSchema) > 0 {
+		if len(chatMode.Tools) > 0 {
+			return fmt.Errorf("chat mode with an output schema cannot use tools")
+		}
+		if _, err := normalizeOutputSchema(chatMode.OutputSchema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // createSessionContext creates session context after validation passes
 func (l *llmUsecase) createSessionContext(ctx context.Context, data *PromptData) (toolsmanager.SessionContext, error) {
 	sessionID, err := primitive.ObjectIDFromHex(data.SessionID)
@@ -193,9 +331,15 @@ func (l *llmUsecase) createSessionContext(ctx context.Context, data *PromptData)
 		sellerID = "chat-bot" // Default fallback
 	}
 
-	gk := genkit.Init(ctx, genkit.WithPlugins(&googlegenai.GoogleAI{
-		APIKey: l.config.LLM.GoogleAIAPIKey,
-	}))
+	gk, err := l.newGenkit(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedTools, err := l.tenantUsecase.AllowedTools(ctx, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve tool policy: %w", err)
+	}
 
 	// Create session context for tool operations
 	session := toolsmanager.NewSessionContext(ctx, toolsmanager.SessionContextConfig{
@@ -205,11 +349,36 @@ func (l *llmUsecase) createSessionContext(ctx context.Context, data *PromptData)
 		UserID:      userID,
 		SenderID:    sellerID,
 		SessionRepo: l.sessionRepo,
+		Timeouts:    l.config.Timeouts,
+
+		RequireApproval: data.RequireApproval,
+		AllowedTools:    allowedTools,
+		ToolFixtures:    l.sessionToolFixtures(data),
 	})
 
 	return session, nil
 }
 
+// newGenkit initializes Genkit with the provider key of the seller, resolved
+// per session so keys rotated through the API apply immediately. With LLM_FAKE
+// set it only serves the fake model.
+func (l *llmUsecase) newGenkit(ctx context.Context, sellerID string) (*genkit.Genkit, error) {
+	if l.config.LLM.Fake {
+		gk := genkit.Init(ctx)
+		defineFakeModel(gk, l.config.LLM.FakeLatency)
+		return gk, nil
+	}
+
+	apiKey, err := l.llmKeyUsecase.ResolveKey(ctx, models.LLMProviderGoogleAI, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve LLM key: %w", err)
+	}
+
+	return genkit.Init(ctx, genkit.WithPlugins(&googlegenai.GoogleAI{
+		APIKey: apiKey,
+	})), nil
+}
+
 // buildPrompt generates the prompt from template and data
 func (l *llmUsecase) buildPrompt(templateStr string, data *PromptData) (string, error) {
 	tmpl, err := template.New("prompt").Parse(templateStr)
@@ -247,18 +416,108 @@ func (l *llmUsecase) evaluateCondition(whenTemplate string, data *PromptData) (b
 
 // buildInitialMessages creates the initial message array for the AI
 func (l *llmUsecase) buildInitialMessages(prompt string, data *PromptData, session toolsmanager.SessionContext) []*ai.Message {
-	messages := []*ai.Message{
-		ai.NewSystemTextMessage(prompt),
+	var messages []*ai.Message
+	if data.Persona != nil {
+		messages = append(messages, ai.NewSystemTextMessage(describePersona(data.Persona)))
+	}
+	messages = append(messages, ai.NewSystemTextMessage(prompt))
+
+	if data.ContextCompacted {
+		messages = append(messages, ai.NewSystemTextMessage(compactedContextNote))
+	}
+
+	if data.ReplyWindow > 0 {
+		messages = append(messages, ai.NewSystemTextMessage(describeReplyWindow(data.ReplyWindow)))
+	}
+
+	if data.ChannelInfo != nil && len(data.ChannelInfo.Participants) > 0 {
+		messages = append(messages, ai.NewSystemTextMessage(describeParticipants(data.ChannelInfo, data.UserID)))
+	}
+
+	if len(data.PreviousConversations) > 0 {
+		messages = append(messages, ai.NewSystemTextMessage(describeConversationRecaps(data.PreviousConversations)))
 	}
 
 	if data.RecentMessages != nil && len(data.RecentMessages.Messages) > 0 {
 		messages = l.addRecentMessages(messages, data, session)
 	}
 
+	if len(data.Items) > 1 {
+		messages = append(messages, ai.NewSystemTextMessage(describeChannelItems(data.Items)))
+	}
+
+	if len(data.Listings) > 0 {
+		messages = append(messages, ai.NewSystemTextMessage(describeListings(data.Listings)))
+	}
+
 	messages = append(messages, ai.NewUserTextMessage(data.Message))
 	return messages
 }
 
+// describeListings tells the model which listings the buyer linked in their message
+func describeListings(listings []models.ListingCard) string {
+	var sb strings.Builder
+	sb.WriteString("The buyer's next message links these Chotot listings:")
+	for _, listing := range listings {
+		fmt.Fprintf(&sb, "\n- list_id %s: %q, price %s", listing.ListID, listing.Title, listing.PriceString)
+		if listing.Location != "" {
+			fmt.Fprintf(&sb, ", location %s", listing.Location)
+		}
+		fmt.Fprintf(&sb, " (%s)", listing.URL)
+	}
+	return sb.String()
+}
+
+// compactedContextNote tells the model the channel context is a digest
+const compactedContextNote = "The channel context above is a summary of a longer one. Call FetchFullContext when you need a detail it leaves out."
+
+// describeReplyWindow keeps the model from promising follow ups the partner
+// would not deliver
+func describeReplyWindow(window time.Duration) string {
+	length := window.String()
+	if window%time.Hour == 0 {
+		length = fmt.Sprintf("%d hours", int(window.Hours()))
+	}
+	return fmt.Sprintf("Messages can only reach the buyer within %s of their last message. Do not promise to message them later or to follow up; ask them to write back when they want an update.", length)
+}
+
+// describeParticipants tells the model who is in the chat, which matters in
+// group rooms with several buyers or sellers
+func describeParticipants(channelInfo *models.ChannelInfo, buyerID string) string {
+	primary := channelInfo.PrimarySeller()
+	var sb strings.Builder
+	sb.WriteString("Participants of this chat:")
+	for _, p := range channelInfo.Participants {
+		name := p.UserID
+		if p.Profile != nil && p.Profile.Name != "" {
+			name = fmt.Sprintf("%s (user %s)", p.Profile.Name, p.UserID)
+		}
+		fmt.Fprintf(&sb, "\n- %s: %s", name, p.Role)
+		switch {
+		case primary != nil && p.UserID == primary.UserID:
+			sb.WriteString(", you reply as this seller")
+		case p.UserID == buyerID:
+			sb.WriteString(", wrote the next message")
+		}
+	}
+	return sb.String()
+}
+
+// describeChannelItems tells the model which items the room is about, so it
+// can switch between them with SwitchItem
+func describeChannelItems(items []models.ChannelItem) string {
+	var sb strings.Builder
+	sb.WriteString("This chat discusses several of the seller's items:")
+	for _, item := range items {
+		fmt.Fprintf(&sb, "\n- item_id %s: %q", item.ItemID, item.ItemName)
+		if item.ItemPrice != "" {
+			fmt.Fprintf(&sb, ", price %s", item.ItemPrice)
+		}
+		fmt.Fprintf(&sb, " (%s)", item.Status)
+	}
+	return sb.String()
+}
+
 // addRecentMessages adds recent message history to the conversation
 func (l *llmUsecase) addRecentMessages(messages []*ai.Message, data *PromptData, session toolsmanager.SessionContext) []*ai.Message {
 	for _, msg := range data.RecentMessages.Messages {
@@ -278,17 +537,27 @@ func (l *llmUsecase) addRecentMessages(messages []*ai.Message, data *PromptData,
 }
 
 // runAgentLoop executes the AI agent conversation loop
-func (l *llmUsecase) runAgentLoop(ctx context.Context, chatMode *models.ChatMode, messages []*ai.Message, availableTools []ai.Tool, session toolsmanager.SessionContext) error {
+func (l *llmUsecase) runAgentLoop(ctx context.Context, chatMode *models.ChatMode, messages []*ai.Message, availableTools []ai.Tool, session toolsmanager.SessionContext, transcript *transcriptRecorder, promptLog *promptLogger, tracer *sessionTracer) error {
 	for i := 0; i < chatMode.MaxIterations; i++ {
 		log.Infow(ctx, "Agent iteration", "current", i+1, "max", chatMode.MaxIterations)
+		transcript.startIteration(i + 1)
 
-		response, err := l.generateResponse(session, chatMode, messages, availableTools)
+		start := time.Now()
+		response, err := l.generate(ctx, session, chatMode, messages, availableTools)
+		latency := time.Since(start)
+		promptLog.record(ctx, i+1, messages, response, latency, err)
+		tracer.record(ctx, i+1, messages, response, latency, err)
 		if err != nil {
+			if errors.Is(err, models.ErrLLMUnavailable) {
+				l.acknowledge(ctx, session, chatMode, err, i == 0)
+			}
 			return fmt.Errorf("failed to generate response: %w", err)
 		}
+		overBudget := l.recordUsage(ctx, session, chatMode, response)
 
 		if response.Text() != "" {
 			messages = append(messages, ai.NewModelTextMessage(response.Text()))
+			transcript.addModelText(response.Text())
 			log.Infow(ctx, "AI generated text response", "response", response.Text())
 		}
 
@@ -300,7 +569,7 @@ func (l *llmUsecase) runAgentLoop(ctx context.Context, chatMode *models.ChatMode
 
 		log.Infow(ctx, "Processing tool requests", "count", len(toolRequests))
 
-		toolResponseParts, err := l.executeToolRequests(ctx, toolRequests, availableTools, session)
+		toolResponseParts, err := l.executeToolRequests(ctx, toolRequests, availableTools, session, transcript)
 		if err != nil {
 			return err
 		}
@@ -313,37 +582,96 @@ func (l *llmUsecase) runAgentLoop(ctx context.Context, chatMode *models.ChatMode
 			log.Info(ctx, "Session has been terminated by tool execution, ending conversation")
 			break
 		}
+
+		// the tools of the generation crossing the cap still run, so a reply
+		// it drafted reaches the buyer
+		if overBudget {
+			log.Info(ctx, "Session reached its token budget, ending conversation")
+			break
+		}
 	}
 	return nil
 }
 
-// generateResponse generates AI response using Genkit
-func (l *llmUsecase) generateResponse(session toolsmanager.SessionContext, chatMode *models.ChatMode, messages []*ai.Message, availableTools []ai.Tool) (*ai.ModelResponse, error) {
+// recordUsage adds the tokens of response to the session's budget and the
+// cost estimates, reporting whether the session reached its cap. Errors are
+// logged and keep the session going.
+func (l *llmUsecase) recordUsage(ctx context.Context, session toolsmanager.SessionContext, chatMode *models.ChatMode, response *ai.ModelResponse) bool {
+	sessionID, err := primitive.ObjectIDFromHex(session.GetSessionID())
+	if err != nil {
+		return false
+	}
+
+	usage := models.SessionUsage{Generations: 1}
+	if response.Usage != nil {
+		usage.InputTokens = response.Usage.InputTokens
+		usage.OutputTokens = response.Usage.OutputTokens
+	}
+
+	// generations answered by the fallback model are priced as the chat
+	// mode's model
+	l.costUsecase.Record(ctx, session.GetSenderID(), chatMode.Name, chatMode.Model, usage)
+
+	exceeded, err := l.budgetUsecase.RecordUsage(ctx, sessionID, session.GetChannelID(), session.GetSenderID(), usage)
+	if err != nil {
+		log.Errorw(ctx, "Failed to record session usage", "session_id", sessionID.Hex(), "error", err)
+	}
+	return exceeded
+}
+
+// generateResponse generates AI response from model using Genkit, constrained
+// to outputSchema when one is set
+func (l *llmUsecase) generateResponse(session toolsmanager.SessionContext, model string, messages []*ai.Message, availableTools []ai.Tool, outputSchema map[string]any) (*ai.ModelResponse, error) {
+	if l.config.LLM.Fake {
+		model = fakeModelName
+	}
 	var toolRefs []ai.ToolRef
 	for _, tool := range availableTools {
 		toolRefs = append(toolRefs, tool)
 	}
-
-	return genkit.Generate(session.Context(), session.Genkit(),
+	opts := []ai.GenerateOption{
 		ai.WithMessages(messages...),
-		ai.WithModelName(chatMode.Model),
+		ai.WithModelName(model),
 		ai.WithTools(toolRefs...),
-	)
+	}
+	if len(outputSchema) > 0 {
+		schema, err := normalizeOutputSchema(outputSchema)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, ai.WithMiddleware(outputSchemaMiddleware(schema)))
+	}
+
+	ctx, cancel := context.WithTimeout(session.Context(), l.config.Timeouts.LLMGenerate)
+	defer cancel()
+
+	start := time.Now()
+	resp, err := genkit.Generate(ctx, session.Genkit(), opts...)
+	elapsed := time.Since(start)
+	livestats.Observe(models.StatLLMGenerate, float64(elapsed.Milliseconds()))
+	reqstats.FromContext(ctx).AddLLM(elapsed)
+	if err != nil {
+		livestats.Inc(models.StatLLMErrors)
+	}
+	return resp, err
 }
 
 // executeToolRequests executes the requested tools
-func (l *llmUsecase) executeToolRequests(ctx context.Context, toolRequests []*ai.ToolRequest, availableTools []ai.Tool, session toolsmanager.SessionContext) ([]*ai.Part, error) {
+func (l *llmUsecase) executeToolRequests(ctx context.Context, toolRequests []*ai.ToolRequest, availableTools []ai.Tool, session toolsmanager.SessionContext, transcript *transcriptRecorder) ([]*ai.Part, error) {
 	var toolResponseParts []*ai.Part
 	for _, req := range toolRequests {
 		log.Infow(ctx, "Executing tool", "tool_name", req.Name)
+		transcript.addToolRequest(req)
 
 		tool := l.findToolByName(req.Name, availableTools)
 		if tool == nil {
 			log.Errorw(ctx, "Tool not found", "tool_name", req.Name)
+			transcript.addToolResponse(req, nil, fmt.Errorf("tool not found"))
 			continue
 		}
 
 		output, err := tool.RunRaw(ctx, req.Input)
+		transcript.addToolResponse(req, output, err)
 		if err != nil {
 			log.Errorw(ctx, "Tool execution failed", "tool_name", req.Name, "error", err)
 			continue
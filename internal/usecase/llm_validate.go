@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// samplePromptData fills every field a prompt template can reference, so
+// rendering it reports unknown fields and functions before a buyer message does
+func samplePromptData() *PromptData {
+	now := time.Now()
+	return &PromptData{
+		ChannelInfo: &models.ChannelInfo{
+			ID:           "sample-channel",
+			Name:         "Sample room",
+			ItemName:     "iPhone 13 128GB",
+			ItemPrice:    "12.000.000 đ",
+			ItemCategory: "phones",
+			Context:      "Listed a week ago, ships from Ho Chi Minh City",
+			Participants: []models.Participant{
+				{UserID: "sample-buyer", Role: models.ParticipantRoleBuyer},
+				{UserID: "sample-seller", Role: models.ParticipantRoleSeller, Primary: true},
+			},
+		},
+		SessionID:  primitive.NewObjectID().Hex(),
+		UserID:     "sample-buyer",
+		SenderRole: string(models.ParticipantRoleBuyer),
+		Message:    "Is this still available?",
+		RecentMessages: &models.MessageHistory{
+			Messages: []models.HistoryMessage{
+				{ID: "sample-message", ChannelID: "sample-channel", SenderID: "sample-buyer", Message: "Hi", CreatedAt: now},
+			},
+		},
+		Listings: []models.ListingCard{
+			{ListID: "100000001", Title: "iPhone 13 128GB", Price: 12000000, PriceString: "12.000.000 đ", URL: "https://www.chotot.com/100000001.htm"},
+		},
+		Items: []models.ChannelItem{
+			{ItemID: models.ChannelItemID, ItemName: "iPhone 13 128GB", ItemPrice: "12.000.000 đ", Status: models.ChannelItemCurrent, AddedAt: now},
+		},
+		Intent:        models.MessageIntentAvailability,
+		Sentiment:     &models.SentimentScore{Sentiment: models.SentimentNeutral, At: now},
+		BuyerLanguage: "vi",
+		PreviousConversations: []models.ConversationRecap{
+			{ChannelID: "sample-previous-channel", ItemName: "iPhone 12", Messages: 4, LastContactAt: now},
+		},
+		Persona:     &models.Persona{SellerID: "sample-seller", DisplayName: "Shop", Tone: "friendly", Languages: []string{"vi"}},
+		ReplyWindow: 24 * time.Hour,
+	}
+}
+
+// ValidateChatMode checks chatMode the way a message would use it: its
+// settings, its condition and prompt rendered against sample data, and its
+// tools. It returns every problem found.
+func (l *llmUsecase) ValidateChatMode(chatMode *models.ChatMode) []string {
+	if err := validateChatModeSettings(chatMode); err != nil {
+		return []string{err.Error()}
+	}
+
+	data := samplePromptData()
+	var problems []string
+	if _, err := l.evaluateCondition(chatMode.Condition, data); err != nil {
+		problems = append(problems, fmt.Sprintf("condition: %s", err))
+	}
+	if _, err := l.buildPrompt(chatMode.PromptTemplate, data); err != nil {
+		problems = append(problems, fmt.Sprintf("prompt template: %s", err))
+	}
+	for _, toolName := range chatMode.Tools {
+		if !l.toolsManager.HasTool(toolName) {
+			problems = append(problems, fmt.Sprintf("unknown tool %s", toolName))
+		}
+	}
+	return problems
+}
@@ -2,13 +2,17 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
 	"github.com/nguyentranbao-ct/chat-bot/internal/models"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
 	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
 )
 
 type MessageUsecase interface {
@@ -16,34 +20,112 @@ type MessageUsecase interface {
 }
 
 type messageUsecase struct {
-	chatModeRepo     mongodb.ChatModeRepository
-	sessionRepo      mongodb.ChatSessionRepository
-	activityRepo     mongodb.ChatActivityRepository
-	chatAPIClient    chatapi.Client
-	llmUsecase       LLMUsecase
-	whitelistService WhitelistService
+	chatModeRepo      mongodb.ChatModeRepository
+	sessionRepo       mongodb.ChatSessionRepository
+	activityRepo      mongodb.ChatActivityRepository
+	cursorRepo        mongodb.ChannelCursorRepository
+	chatAPIClient     chatapi.Client
+	llmUsecase        LLMUsecase
+	whitelistService  WhitelistService
+	userUsecase       UserUsecase
+	tenantUsecase     TenantUsecase
+	listingExpander   ListingExpander
+	chatModeSelector  ChatModeSelector
+	intentClassifier  IntentClassifier
+	sentimentUsecase  SentimentUsecase
+	scamUsecase       ScamUsecase
+	budgetUsecase     BudgetUsecase
+	recapper          ConversationRecapper
+	personaUsecase    PersonaUsecase
+	suggestionUsecase SuggestionUsecase
+	outcomeUsecase    OutcomeUsecase
+	timeout           time.Duration
+	// channelItemRepo tracks the items discussed in each room
+	channelItemRepo mongodb.ChannelItemRepository
+	// contextCompactor keeps long channel contexts out of prompts
+	contextCompactor ContextCompactor
+	identityMapper   IdentityMapper
+	languageTracker  BuyerLanguageTracker
+	feedUsecase      ActivityFeedUsecase
+	// claimRepo tells the rooms an agent is handling, where the bot stays quiet
+	claimRepo mongodb.ChannelClaimRepository
+	// privacyUsecase keeps private rooms and messages away from the LLM
+	privacyUsecase PrivacyUsecase
+	// replyWindow limits when chat-api delivers free-form replies, 0 when it
+	// does not
+	replyWindow time.Duration
+	// testMode tags sessions whose replies chat-api test mode holds
+	testMode bool
 }
 
 func NewMessageUsecase(
 	chatModeRepo mongodb.ChatModeRepository,
 	sessionRepo mongodb.ChatSessionRepository,
 	activityRepo mongodb.ChatActivityRepository,
+	cursorRepo mongodb.ChannelCursorRepository,
 	chatAPIClient chatapi.Client,
 	llmUsecase LLMUsecase,
 	whitelistService WhitelistService,
+	userUsecase UserUsecase,
+	tenantUsecase TenantUsecase,
+	listingExpander ListingExpander,
+	chatModeSelector ChatModeSelector,
+	intentClassifier IntentClassifier,
+	sentimentUsecase SentimentUsecase,
+	scamUsecase ScamUsecase,
+	budgetUsecase BudgetUsecase,
+	recapper ConversationRecapper,
+	personaUsecase PersonaUsecase,
+	suggestionUsecase SuggestionUsecase,
+	outcomeUsecase OutcomeUsecase,
+	channelItemRepo mongodb.ChannelItemRepository,
+	contextCompactor ContextCompactor,
+	identityMapper IdentityMapper,
+	languageTracker BuyerLanguageTracker,
+	feedUsecase ActivityFeedUsecase,
+	claimRepo mongodb.ChannelClaimRepository,
+	privacyUsecase PrivacyUsecase,
+	conf *config.Config,
 ) MessageUsecase {
 	return &messageUsecase{
-		chatModeRepo:     chatModeRepo,
-		sessionRepo:      sessionRepo,
-		activityRepo:     activityRepo,
-		chatAPIClient:    chatAPIClient,
-		llmUsecase:       llmUsecase,
-		whitelistService: whitelistService,
+		chatModeRepo:      chatModeRepo,
+		sessionRepo:       sessionRepo,
+		activityRepo:      activityRepo,
+		cursorRepo:        cursorRepo,
+		chatAPIClient:     chatAPIClient,
+		llmUsecase:        llmUsecase,
+		whitelistService:  whitelistService,
+		userUsecase:       userUsecase,
+		tenantUsecase:     tenantUsecase,
+		listingExpander:   listingExpander,
+		chatModeSelector:  chatModeSelector,
+		intentClassifier:  intentClassifier,
+		sentimentUsecase:  sentimentUsecase,
+		scamUsecase:       scamUsecase,
+		budgetUsecase:     budgetUsecase,
+		recapper:          recapper,
+		personaUsecase:    personaUsecase,
+		suggestionUsecase: suggestionUsecase,
+		outcomeUsecase:    outcomeUsecase,
+		timeout:           conf.Timeouts.MessageProcessing,
+		channelItemRepo:   channelItemRepo,
+		contextCompactor:  contextCompactor,
+		identityMapper:    identityMapper,
+		languageTracker:   languageTracker,
+		feedUsecase:       feedUsecase,
+		claimRepo:         claimRepo,
+		privacyUsecase:    privacyUsecase,
+		replyWindow:       replyWindow(conf.ReplyWindow),
+		testMode:          conf.TestMode.Applies(models.PartnerChatAPI),
 	}
 }
 
-func (uc *messageUsecase) ProcessMessage(ctx context.Context, message models.IncomingMessage) error {
+func (uc *messageUsecase) ProcessMessage(ctx context.Context, message models.IncomingMessage) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
 	log.Infof(ctx, "Processing message from user %s in channel %s", message.SenderID, message.ChannelID)
+	message = message.Normalize()
 
 	// Get channel info first to check sender role and seller whitelist
 	channelInfo, err := uc.chatAPIClient.GetChannelInfo(ctx, message.ChannelID)
@@ -54,31 +136,134 @@ func (uc *messageUsecase) ProcessMessage(ctx context.Context, message models.Inc
 	// Find sender role from channel participants
 	senderRole := findSenderRole(channelInfo, message.SenderID)
 
-	// Skip processing if message is from seller (bot acts as seller, so this prevents loops)
-	if senderRole == "seller" {
+	// Skip processing if message is from a seller (bot acts as seller, so this prevents loops)
+	if senderRole == string(models.ParticipantRoleSeller) {
 		log.Infof(ctx, "Skipping message from seller %s in channel %s to prevent bot loops", message.SenderID, message.ChannelID)
 		return nil
 	}
 
-	// Find seller ID from channel participants and check whitelist
-	sellerID := findSellerIDFromChannel(channelInfo)
-	if sellerID == "" {
-		log.Infof(ctx, "No seller found in channel %s, skipping message", message.ChannelID)
-		return nil // Skip message if no seller found
+	// Find the primary seller from channel participants and check whitelist
+	if err := channelInfo.Validate(); err != nil {
+		log.Infow(ctx, "Invalid channel participants, skipping message", "channel_id", message.ChannelID, "error", err)
+		return nil // Skip message if the bot cannot tell which seller it replies as
 	}
+	sellerID := findSellerIDFromChannel(channelInfo)
 
 	// Check if seller is whitelisted
-	if !uc.whitelistService.IsSellerAllowed(sellerID) {
+	if !uc.whitelistService.IsAllowed(ctx, sellerID, message.ChannelID) {
 		log.Infof(ctx, "Ignoring message from non-whitelisted seller %s in channel %s", sellerID, message.ChannelID)
 		return nil // Skip message if seller not whitelisted
 	}
 
-	chatMode, err := uc.chatModeRepo.GetByName(ctx, message.Metadata.LLM.ChatMode)
+	ctx = uc.withSellerTenant(ctx, sellerID)
+
+	// Claim the message so redeliveries and the reconciler never answer it twice
+	cursor := &models.ChannelCursor{
+		ChannelID:     message.ChannelID,
+		BuyerID:       message.SenderID,
+		ChatMode:      message.Metadata.LLM.ChatMode,
+		LastMessageAt: message.CreatedAt,
+	}
+	claimed, err := uc.cursorRepo.Claim(ctx, cursor)
+	if err != nil {
+		return fmt.Errorf("failed to claim message: %w", err)
+	}
+	if !claimed {
+		log.Infof(ctx, "Skipping message from %s in channel %s, a newer or the same message was already processed", message.SenderID, message.ChannelID)
+		return nil
+	}
+	// A failure from here on releases the claim, so a retry, a dead letter
+	// requeue or the reconciler can still answer the message. Once a tool
+	// replied or reserved an item the claim is kept, a retry would repeat it.
+	ctx, outbound := models.WithOutbound(ctx)
+	defer func() {
+		if err != nil && !outbound.Done() {
+			uc.releaseClaim(ctx, cursor)
+		}
+	}()
+
+	// the language picks the system messages posted from here on
+	message.Metadata.Language = textx.DetectLanguage(message.Message)
+
+	if uc.flagged(ctx, message, sellerID) {
+		log.Infof(ctx, "Skipping message from %s in channel %s, flagged as a likely scam", message.SenderID, message.ChannelID)
+		return nil
+	}
+
+	if uc.handedOff(ctx, &message, sellerID) {
+		uc.outcomeUsecase.HandOff(ctx, message.ChannelID)
+		log.Infof(ctx, "Skipping message from %s in channel %s, handed off to the seller after negative sentiment", message.SenderID, message.ChannelID)
+		return nil
+	}
+
+	if uc.claimed(ctx, message.ChannelID) {
+		log.Infof(ctx, "Skipping message from %s in channel %s, an agent is handling the channel", message.SenderID, message.ChannelID)
+		return nil
+	}
+
+	if uc.privacyUsecase.Excludes(ctx, message.ChannelID, models.HistoryMessage{Message: message.Message}) {
+		log.Infof(ctx, "Skipping message from %s in channel %s, the room or message is private", message.SenderID, message.ChannelID)
+		return nil
+	}
+
+	if err := uc.tenantUsecase.CheckSessionQuota(ctx); err != nil {
+		if errors.Is(err, models.ErrQuotaExceeded) {
+			log.Warnw(ctx, "Tenant session quota exceeded, skipping message", "seller_id", sellerID, "channel_id", message.ChannelID)
+			return nil
+		}
+		return fmt.Errorf("failed to check session quota: %w", err)
+	}
+
+	if err := uc.budgetUsecase.CheckChannel(ctx, message.ChannelID, sellerID); err != nil {
+		if errors.Is(err, models.ErrBudgetExceeded) {
+			log.Warnw(ctx, "Channel reply budget exceeded, skipping message", "seller_id", sellerID, "channel_id", message.ChannelID)
+			return nil
+		}
+		return fmt.Errorf("failed to check channel budget: %w", err)
+	}
+
+	message.Metadata.Intent = uc.intentClassifier.Classify(message.Message)
+	livestats.Inc(models.StatIntentsPrefix + string(message.Metadata.Intent))
+	if message.Metadata.Intent == models.MessageIntentPriceNegotiation {
+		uc.feedUsecase.Publish(ctx, &models.FeedItem{
+			SellerID:  sellerID,
+			Type:      models.FeedOffer,
+			ChannelID: message.ChannelID,
+			BuyerID:   message.SenderID,
+			Summary:   textx.Truncate(message.Message, feedSummaryChars),
+			Data:      map[string]any{"item_name": channelInfo.ItemName, "item_price": channelInfo.ItemPrice},
+		})
+	}
+
+	chatModeName, source, err := uc.chatModeSelector.Select(ctx, message, channelInfo, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to select chat mode: %w", err)
+	}
+	log.Debugw(ctx, "Selected chat mode", "chat_mode", chatModeName, "source", source, "intent", message.Metadata.Intent, "channel_id", message.ChannelID)
+
+	chatMode, err := uc.chatModeRepo.GetByName(ctx, chatModeName)
 	if err != nil {
-		return fmt.Errorf("failed to get chat mode '%s': %w", message.Metadata.LLM.ChatMode, err)
+		return fmt.Errorf("failed to get chat mode '%s': %w", chatModeName, err)
 	}
 
-	session, err := uc.newSession(ctx, message, chatMode)
+	persona, err := uc.personaUsecase.ResolvePersona(ctx, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to resolve persona: %w", err)
+	}
+
+	requireApproval, err := uc.suggestionUsecase.RequiresApproval(ctx, sellerID, message.ChannelID)
+	if err != nil {
+		return fmt.Errorf("failed to check safe mode: %w", err)
+	}
+
+	// Read before the new session is stored, so it only covers earlier chats
+	previousConversations := uc.recapper.Recap(ctx, message.SenderID, sellerID, message.ChannelID)
+	buyerLanguage := uc.languageTracker.Track(ctx, message.SenderID, sellerID, message.Metadata.Language)
+
+	channelInfo, items := uc.channelItems(ctx, message.ChannelID, channelInfo)
+	channelInfo, contextCompacted := uc.compactContext(ctx, message.ChannelID, sellerID, chatMode, channelInfo)
+
+	session, err := uc.newSession(ctx, message, channelInfo, sellerID, chatMode)
 	if err != nil {
 		return fmt.Errorf("failed to create session: %w", err)
 	}
@@ -91,6 +276,9 @@ func (uc *messageUsecase) ProcessMessage(ctx context.Context, message models.Inc
 		recentMessages = &models.MessageHistory{Messages: []models.HistoryMessage{}}
 	}
 
+	// Resolve pasted listing links so the bot answers about the exact item
+	message.Metadata.Listings = uc.listingExpander.Expand(ctx, message.Message)
+
 	promptData := &PromptData{
 		ChannelInfo:    channelInfo,
 		SessionID:      session.ID.Hex(),
@@ -98,6 +286,19 @@ func (uc *messageUsecase) ProcessMessage(ctx context.Context, message models.Inc
 		SenderRole:     senderRole,
 		Message:        message.Message,
 		RecentMessages: recentMessages,
+		Listings:       message.Metadata.Listings,
+		Items:          items,
+		Intent:         message.Metadata.Intent,
+		Sentiment:      message.Metadata.Sentiment,
+		BuyerLanguage:  buyerLanguage,
+
+		PreviousConversations: previousConversations,
+		Persona:               persona,
+		RequireApproval:       requireApproval,
+		ContextCompacted:      contextCompacted,
+		ReplyWindow:           uc.replyWindow,
+		DryTools:              message.Metadata.LLM.DryTools,
+		ToolFixtures:          message.Metadata.LLM.ToolFixtures,
 	}
 
 	if err := uc.llmUsecase.ProcessMessage(ctx, chatMode, promptData); err != nil {
@@ -108,13 +309,31 @@ func (uc *messageUsecase) ProcessMessage(ctx context.Context, message models.Inc
 	return nil
 }
 
-func (uc *messageUsecase) newSession(ctx context.Context, message models.IncomingMessage, chatMode *models.ChatMode) (*models.ChatSession, error) {
+// releaseClaim releases the claim of a message that failed, even once the
+// message deadline is spent. Errors are logged, the message is then only
+// answered by a newer one.
+func (uc *messageUsecase) releaseClaim(ctx context.Context, cursor *models.ChannelCursor) {
+	if err := uc.cursorRepo.Release(context.WithoutCancel(ctx), cursor); err != nil {
+		log.Errorw(ctx, "Failed to release message claim", "channel_id", cursor.ChannelID, "error", err)
+	}
+}
+
+func (uc *messageUsecase) newSession(ctx context.Context, message models.IncomingMessage, channelInfo *models.ChannelInfo, sellerID string, chatMode *models.ChatMode) (*models.ChatSession, error) {
 	session := &models.ChatSession{
 		ChannelID: message.ChannelID,
 		UserID:    message.SenderID,
+		SellerID:  sellerID,
+		ItemName:  channelInfo.ItemName,
+		Intent:    message.Metadata.Intent,
+		Sentiment: message.Metadata.Sentiment,
 		ChatMode:  chatMode.Name,
 		Status:    models.SessionStatusActive,
 		StartedAt: time.Now(),
+
+		PromptVersion: models.PromptVersion(chatMode.PromptTemplate),
+		DryTools:      message.Metadata.LLM.DryTools,
+		TestMode:      uc.testMode,
+		Language:      message.Metadata.Language,
 	}
 
 	if err := uc.sessionRepo.Create(ctx, session); err != nil {
@@ -125,34 +344,136 @@ func (uc *messageUsecase) newSession(ctx context.Context, message models.Incomin
 	return session, nil
 }
 
-// findSenderRole finds the role of the sender from channel participants
-func findSenderRole(channelInfo *models.ChannelInfo, senderID string) string {
-	if channelInfo == nil || len(channelInfo.Participants) == 0 {
-		return "unknown"
+// channelItems returns the items discussed in the room, seeding them with the
+// channel metadata's item on its first message. The returned channel info
+// describes the current item, which the bot may have switched to. Errors are
+// logged and leave the metadata's item.
+func (uc *messageUsecase) channelItems(ctx context.Context, channelID string, channelInfo *models.ChannelInfo) (*models.ChannelInfo, []models.ChannelItem) {
+	var seed []models.ChannelItem
+	if channelInfo.ItemName != "" {
+		seed = append(seed, models.ChannelItem{
+			ItemID:    models.ChannelItemID,
+			ItemName:  channelInfo.ItemName,
+			ItemPrice: channelInfo.ItemPrice,
+			Status:    models.ChannelItemCurrent,
+			AddedAt:   time.Now(),
+		})
 	}
 
-	for _, participant := range channelInfo.Participants {
-		if participant.UserID == senderID {
-			return participant.Role
-		}
+	items, err := uc.channelItemRepo.Seed(ctx, channelID, seed)
+	if err != nil {
+		log.Errorw(ctx, "Failed to load channel items", "channel_id", channelID, "error", err)
+		return channelInfo, nil
+	}
+
+	current := items.Current()
+	if current == nil || current.ItemID == models.ChannelItemID {
+		return channelInfo, items.Items
 	}
-	return "unknown"
+	info := *channelInfo
+	info.ItemName = current.ItemName
+	info.ItemPrice = current.ItemPrice
+	return &info, items.Items
 }
 
-// findSellerIDFromChannel finds the seller ID from channel participants
-func findSellerIDFromChannel(channelInfo *models.ChannelInfo) string {
-	if channelInfo == nil || len(channelInfo.Participants) == 0 {
-		return ""
+// compactContext replaces a channel context too long for prompts with its
+// digest, reporting whether it did
+func (uc *messageUsecase) compactContext(ctx context.Context, channelID, sellerID string, chatMode *models.ChatMode, channelInfo *models.ChannelInfo) (*models.ChannelInfo, bool) {
+	channelContext, compacted := uc.contextCompactor.Compact(ctx, channelID, sellerID, chatMode.Model, channelInfo.Context)
+	if !compacted {
+		return channelInfo, false
 	}
+	info := *channelInfo
+	info.Context = channelContext
+	return &info, true
+}
 
-	for _, participant := range channelInfo.Participants {
-		if participant.Role == "seller" {
-			return participant.UserID
-		}
+// flagged reports whether the buyer message looks like a scam, which the bot
+// never answers. Check only fails once a pattern matched, so a message whose
+// flag could not be stored is still left unanswered.
+func (uc *messageUsecase) flagged(ctx context.Context, message models.IncomingMessage, sellerID string) bool {
+	flag, err := uc.scamUsecase.Check(ctx, message, sellerID)
+	if err != nil {
+		log.Errorw(ctx, "Failed to flag scam message", "channel_id", message.ChannelID, "error", err)
+		return true
 	}
-	return ""
+	return flag != nil
 }
 
+// handedOff scores the buyer message, adds it to the channel's sentiment and
+// reports whether the channel was handed off to the seller. Tracking errors
+// are logged and leave the bot answering.
+func (uc *messageUsecase) handedOff(ctx context.Context, message *models.IncomingMessage, sellerID string) bool {
+	score := uc.sentimentUsecase.Score(message.Message)
+	message.Metadata.Sentiment = &score
+
+	sentiment, err := uc.sentimentUsecase.Track(ctx, message.ChannelID, sellerID, message.SenderID, score)
+	if err != nil {
+		log.Errorw(ctx, "Failed to track channel sentiment", "channel_id", message.ChannelID, "error", err)
+		return false
+	}
+	return uc.sentimentUsecase.IsHandedOff(sentiment)
+}
+
+// replyWindow returns chat-api's reply window, 0 when it has none
+func replyWindow(conf config.ReplyWindowConfig) time.Duration {
+	if !conf.Applies(models.PartnerChatAPI) {
+		return 0
+	}
+	return conf.Window
+}
+
+// claimed reports whether an agent is handling the channel. Errors are logged
+// and leave the bot answering.
+func (uc *messageUsecase) claimed(ctx context.Context, channelID string) bool {
+	claim, err := uc.claimRepo.Get(ctx, channelID)
+	if err != nil {
+		log.Errorw(ctx, "Failed to get channel claim", "channel_id", channelID, "error", err)
+		return false
+	}
+	return claim != nil
+}
+
+// withSellerTenant scopes ctx to the tenant owning the seller when the caller
+// (e.g. the Kafka consumer) did not resolve one already
+func (uc *messageUsecase) withSellerTenant(ctx context.Context, sellerID string) context.Context {
+	if _, ok := models.TenantIDFromContext(ctx); ok {
+		return ctx
+	}
+
+	userID, err := uc.identityMapper.ResolveExternalID(ctx, models.PartnerChotot, sellerID)
+	if err != nil || userID == nil {
+		return ctx
+	}
+	user, err := uc.userUsecase.GetUser(ctx, *userID)
+	if err != nil || user.TenantID == nil {
+		return ctx
+	}
+
+	log.Debugw(ctx, "Resolved tenant from seller", "seller_id", sellerID, "tenant_id", user.TenantID.Hex())
+	return models.WithTenantID(ctx, *user.TenantID)
+}
+
+// findSenderRole finds the role of the sender from channel participants
+func findSenderRole(channelInfo *models.ChannelInfo, senderID string) string {
+	participant := channelInfo.Participant(senderID)
+	if participant == nil {
+		return string(models.ParticipantRoleUnknown)
+	}
+	return string(participant.Role)
+}
+
+// findSellerIDFromChannel finds the primary seller of the channel, empty
+// when it has none or several sellers and none marked primary
+func findSellerIDFromChannel(channelInfo *models.ChannelInfo) string {
+	seller := channelInfo.PrimarySeller()
+	if seller == nil {
+		return ""
+	}
+	return seller.UserID
+}
+
+// fetchRecentMessages returns the channel's latest messages, private ones left out
 func (uc *messageUsecase) fetchRecentMessages(ctx context.Context, userID, channelID string) (*models.MessageHistory, error) {
 	req := chatapi.MessageHistoryRequest{
 		UserID:    userID,
@@ -160,5 +481,9 @@ func (uc *messageUsecase) fetchRecentMessages(ctx context.Context, userID, chann
 		Limit:     20,
 		BeforeTs:  nil,
 	}
-	return uc.chatAPIClient.GetMessageHistoryWithParams(ctx, req)
+	history, err := uc.chatAPIClient.GetMessageHistoryWithParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return uc.privacyUsecase.FilterHistory(ctx, channelID, history)
 }
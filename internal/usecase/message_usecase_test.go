@@ -0,0 +1,361 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	testChannelID = "channel-1"
+	testBuyerID   = "buyer-1"
+	testSellerID  = "seller-1"
+)
+
+// fakeCursorRepo claims and releases like the MongoDB cursor, with a
+// missing cursor at 0
+type fakeCursorRepo struct {
+	mongodb.ChannelCursorRepository
+	mu      sync.Mutex
+	cursors map[string]int64
+}
+
+func (r *fakeCursorRepo) Claim(ctx context.Context, cursor *models.ChannelCursor) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	previous := r.cursors[cursor.ChannelID]
+	if previous >= cursor.LastMessageAt {
+		return false, nil
+	}
+	r.cursors[cursor.ChannelID] = cursor.LastMessageAt
+	cursor.PreviousMessageAt = previous
+	return true, nil
+}
+
+func (r *fakeCursorRepo) Release(ctx context.Context, cursor *models.ChannelCursor) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cursors[cursor.ChannelID] == cursor.LastMessageAt {
+		r.cursors[cursor.ChannelID] = cursor.PreviousMessageAt
+	}
+	return nil
+}
+
+// fakeLLM fails the first failures messages as if the provider was down,
+// then replies to each with its text
+type fakeLLM struct {
+	usecase.LLMUsecase
+	failures int
+	// failAfterReply sends the reply of a failing message before it fails,
+	// like a tool loop erroring after ReplyMessage
+	failAfterReply bool
+	calls          int
+	replies        []string
+}
+
+func (l *fakeLLM) ProcessMessage(ctx context.Context, chatMode *models.ChatMode, data *usecase.PromptData) error {
+	l.calls++
+	if l.calls <= l.failures {
+		if l.failAfterReply {
+			l.reply(ctx, data)
+		}
+		return fmt.Errorf("failed to generate response: %w", models.ErrLLMUnavailable)
+	}
+	l.reply(ctx, data)
+	return nil
+}
+
+func (l *fakeLLM) reply(ctx context.Context, data *usecase.PromptData) {
+	l.replies = append(l.replies, "Re: "+data.Message)
+	models.OutboundFromContext(ctx).Mark()
+}
+
+type fakeChatAPI struct{ chatapi.Client }
+
+func (c *fakeChatAPI) GetChannelInfo(ctx context.Context, channelID string) (*models.ChannelInfo, error) {
+	return &models.ChannelInfo{
+		ID:       channelID,
+		ItemName: "iPhone 13",
+		Participants: []models.Participant{
+			{UserID: testBuyerID, Role: models.ParticipantRoleBuyer},
+			{UserID: testSellerID, Role: models.ParticipantRoleSeller},
+		},
+	}, nil
+}
+
+func (c *fakeChatAPI) GetMessageHistoryWithParams(ctx context.Context, req chatapi.MessageHistoryRequest) (*models.MessageHistory, error) {
+	return &models.MessageHistory{Messages: []models.HistoryMessage{}}, nil
+}
+
+type fakeWhitelist struct{ usecase.WhitelistService }
+
+func (w *fakeWhitelist) IsAllowed(ctx context.Context, sellerID, channelID string) bool { return true }
+
+type fakeScam struct{ usecase.ScamUsecase }
+
+func (s *fakeScam) Check(ctx context.Context, message models.IncomingMessage, sellerID string) (*models.ScamFlag, error) {
+	return nil, nil
+}
+
+type fakeSentiment struct{ usecase.SentimentUsecase }
+
+func (s *fakeSentiment) Score(message string) models.SentimentScore { return models.SentimentScore{} }
+
+func (s *fakeSentiment) Track(ctx context.Context, channelID, sellerID, buyerID string, score models.SentimentScore) (*models.ChannelSentiment, error) {
+	return &models.ChannelSentiment{}, nil
+}
+
+func (s *fakeSentiment) IsHandedOff(sentiment *models.ChannelSentiment) bool { return false }
+
+type fakeChannelClaims struct{ mongodb.ChannelClaimRepository }
+
+func (r *fakeChannelClaims) Get(ctx context.Context, channelID string) (*models.ChannelClaim, error) {
+	return nil, nil
+}
+
+type fakePrivacy struct{ usecase.PrivacyUsecase }
+
+func (p *fakePrivacy) Excludes(ctx context.Context, channelID string, message models.HistoryMessage) bool {
+	return false
+}
+
+func (p *fakePrivacy) FilterHistory(ctx context.Context, channelID string, history *models.MessageHistory) (*models.MessageHistory, error) {
+	return history, nil
+}
+
+type fakeTenants struct{ usecase.TenantUsecase }
+
+func (t *fakeTenants) CheckSessionQuota(ctx context.Context) error { return nil }
+
+type fakeBudget struct{ usecase.BudgetUsecase }
+
+func (b *fakeBudget) CheckChannel(ctx context.Context, channelID, sellerID string) error { return nil }
+
+type fakeIntents struct{ usecase.IntentClassifier }
+
+func (c *fakeIntents) Classify(message string) models.MessageIntent { return "" }
+
+type fakeChatModeSelector struct{ usecase.ChatModeSelector }
+
+func (s *fakeChatModeSelector) Select(ctx context.Context, message models.IncomingMessage, channelInfo *models.ChannelInfo, sellerID string) (string, models.ChatModeSource, error) {
+	return "seller_mode", models.ChatModeSourceSeller, nil
+}
+
+type fakeChatModes struct{ mongodb.ChatModeRepository }
+
+func (r *fakeChatModes) GetByName(ctx context.Context, name string) (*models.ChatMode, error) {
+	return &models.ChatMode{Name: name, Model: "fake/model", PromptTemplate: "{{.Message}}", MaxIterations: 2}, nil
+}
+
+type fakePersonas struct{ usecase.PersonaUsecase }
+
+func (p *fakePersonas) ResolvePersona(ctx context.Context, sellerID string) (*models.Persona, error) {
+	return nil, nil
+}
+
+type fakeSuggestions struct{ usecase.SuggestionUsecase }
+
+func (s *fakeSuggestions) RequiresApproval(ctx context.Context, sellerID, channelID string) (bool, error) {
+	return false, nil
+}
+
+type fakeRecapper struct{ usecase.ConversationRecapper }
+
+func (r *fakeRecapper) Recap(ctx context.Context, buyerID, sellerID, channelID string) []models.ConversationRecap {
+	return nil
+}
+
+type fakeLanguages struct{ usecase.BuyerLanguageTracker }
+
+func (t *fakeLanguages) Track(ctx context.Context, buyerID, sellerID, language string) string {
+	return language
+}
+
+type fakeChannelItems struct{ mongodb.ChannelItemRepository }
+
+func (r *fakeChannelItems) Seed(ctx context.Context, channelID string, seed []models.ChannelItem) (*models.ChannelItems, error) {
+	return &models.ChannelItems{ChannelID: channelID, Items: seed}, nil
+}
+
+type fakeCompactor struct{ usecase.ContextCompactor }
+
+func (c *fakeCompactor) Compact(ctx context.Context, channelID, sellerID, model, channelContext string) (string, bool) {
+	return channelContext, false
+}
+
+type fakeSessions struct{ mongodb.ChatSessionRepository }
+
+func (r *fakeSessions) Create(ctx context.Context, session *models.ChatSession) error {
+	session.ID = primitive.NewObjectID()
+	return nil
+}
+
+type fakeListings struct{ usecase.ListingExpander }
+
+func (e *fakeListings) Expand(ctx context.Context, text string) []models.ListingCard { return nil }
+
+type fakeChototLinks struct{ usecase.ChototLinkUsecase }
+
+func (u *fakeChototLinks) VerifyFromMessage(ctx context.Context, senderID, text string) (bool, error) {
+	return false, nil
+}
+
+type fakeDeadLetters struct {
+	mongodb.DeadLetterRepository
+	letter *models.DeadLetter
+}
+
+func (r *fakeDeadLetters) Claim(ctx context.Context, id primitive.ObjectID, staleBefore time.Time) (*models.DeadLetter, error) {
+	if r.letter.Status != models.DeadLetterPending {
+		return nil, models.ErrNotFound
+	}
+	before := *r.letter
+	r.letter.Status = models.DeadLetterRequeuing
+	return &before, nil
+}
+
+func (r *fakeDeadLetters) Finish(ctx context.Context, id primitive.ObjectID, errMessage string) (*models.DeadLetter, error) {
+	r.letter.Status, r.letter.Error = models.DeadLetterResolved, ""
+	if errMessage != "" {
+		r.letter.Status, r.letter.Error = models.DeadLetterPending, errMessage
+	}
+	r.letter.Attempts++
+	return r.letter, nil
+}
+
+type fakeAudit struct{ usecase.AuditUsecase }
+
+func (a *fakeAudit) Record(ctx context.Context, action models.AuditAction, resourceType, resourceID string, before, after any) {
+}
+
+// newChatEvents wires the chat event handling the consumer and dead letter
+// requeues share, answering with llm
+func newChatEvents(llm usecase.LLMUsecase) (usecase.ChatEventUsecase, *fakeCursorRepo) {
+	cursors := &fakeCursorRepo{cursors: map[string]int64{}}
+	conf := &config.Config{}
+	conf.Timeouts.MessageProcessing = time.Second
+
+	messages := usecase.NewMessageUsecase(
+		&fakeChatModes{}, &fakeSessions{}, nil, cursors, &fakeChatAPI{}, llm,
+		&fakeWhitelist{}, nil, &fakeTenants{}, &fakeListings{}, &fakeChatModeSelector{},
+		&fakeIntents{}, &fakeSentiment{}, &fakeScam{}, &fakeBudget{}, &fakeRecapper{},
+		&fakePersonas{}, &fakeSuggestions{}, nil, &fakeChannelItems{}, &fakeCompactor{},
+		nil, &fakeLanguages{}, nil, &fakeChannelClaims{}, &fakePrivacy{}, conf,
+	)
+	return usecase.NewChatEventUsecase(messages, &fakeChototLinks{}), cursors
+}
+
+func messageSent(t *testing.T, createdAt int64, text string) []byte {
+	t.Helper()
+	payload, err := json.Marshal(models.KafkaMessage{
+		Pattern: models.EventMessageSent,
+		Data: models.KafkaMessageData{
+			ChannelID: testChannelID,
+			SenderID:  testBuyerID,
+			CreatedAt: createdAt,
+			Message:   text,
+		},
+	})
+	require.NoError(t, err)
+	return payload
+}
+
+func TestFailedMessagesAreAnsweredLater(t *testing.T) {
+	t.Parallel()
+
+	// each event is tenant scoped already, so no seller tenant is resolved
+	ctx := models.WithTenantID(context.Background(), primitive.NewObjectID())
+
+	t.Run("Retry Answers After LLM Failure", func(t *testing.T) {
+		t.Parallel()
+		llm := &fakeLLM{failures: 1}
+		events, cursors := newChatEvents(llm)
+		payload := messageSent(t, 1000, "Is it still available?")
+
+		// the attempts the consumer makes for one event
+		err := events.HandleEvent(ctx, payload)
+		require.ErrorIs(t, err, models.ErrLLMUnavailable)
+		assert.Equal(t, int64(0), cursors.cursors[testChannelID])
+
+		require.NoError(t, events.HandleEvent(ctx, payload))
+		assert.Equal(t, []string{"Re: Is it still available?"}, llm.replies)
+		assert.Equal(t, int64(1000), cursors.cursors[testChannelID])
+
+		// a redelivery of the answered event is skipped
+		require.NoError(t, events.HandleEvent(ctx, payload))
+		assert.Len(t, llm.replies, 1)
+	})
+
+	t.Run("Claim Is Kept After A Reply", func(t *testing.T) {
+		t.Parallel()
+		llm := &fakeLLM{failures: 1, failAfterReply: true}
+		events, cursors := newChatEvents(llm)
+		payload := messageSent(t, 1500, "Is the price negotiable?")
+
+		err := events.HandleEvent(ctx, payload)
+		require.ErrorIs(t, err, models.ErrLLMUnavailable)
+		assert.Equal(t, int64(1500), cursors.cursors[testChannelID])
+
+		// the retry is skipped rather than replying twice
+		require.NoError(t, events.HandleEvent(ctx, payload))
+		assert.Equal(t, []string{"Re: Is the price negotiable?"}, llm.replies)
+	})
+
+	t.Run("Requeue Answers A Dead Letter", func(t *testing.T) {
+		t.Parallel()
+		llm := &fakeLLM{failures: 3}
+		events, _ := newChatEvents(llm)
+		payload := messageSent(t, 2000, "Can you ship it?")
+
+		var err error
+		for range 3 {
+			err = events.HandleEvent(ctx, payload)
+		}
+		require.ErrorIs(t, err, models.ErrLLMUnavailable)
+
+		letters := &fakeDeadLetters{letter: &models.DeadLetter{
+			ID:       primitive.NewObjectID(),
+			Payload:  string(payload),
+			Status:   models.DeadLetterPending,
+			Attempts: 3,
+		}}
+		conf := &config.Config{}
+		conf.Timeouts.MessageProcessing = time.Second
+		deadLetters := usecase.NewDeadLetterUsecase(conf, letters, events, &fakeAudit{})
+
+		letter, err := deadLetters.Requeue(ctx, letters.letter.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.DeadLetterResolved, letter.Status)
+		assert.Equal(t, []string{"Re: Can you ship it?"}, llm.replies)
+	})
+
+	t.Run("Newer Message Keeps Its Claim", func(t *testing.T) {
+		t.Parallel()
+		llm := &fakeLLM{}
+		events, cursors := newChatEvents(llm)
+
+		// a release of an older claim never moves the cursor back past a
+		// newer one
+		older := &models.ChannelCursor{ChannelID: testChannelID, LastMessageAt: 3000}
+		claimed, err := cursors.Claim(ctx, older)
+		require.NoError(t, err)
+		require.True(t, claimed)
+		require.NoError(t, events.HandleEvent(ctx, messageSent(t, 4000, "Hello?")))
+		require.NoError(t, cursors.Release(ctx, older))
+
+		assert.Equal(t, int64(4000), cursors.cursors[testChannelID])
+		assert.Equal(t, []string{"Re: Hello?"}, llm.replies)
+	})
+}
@@ -0,0 +1,272 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/tools/reply_message"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const sandboxChannelName = "Onboarding sandbox"
+
+// OnboardingUsecase walks a new seller through the onboarding steps in the
+// order of models.OnboardingSteps. A step returns models.ErrOnboardingStepLocked
+// until the steps before it are completed.
+type OnboardingUsecase interface {
+	GetOnboarding(ctx context.Context, userID primitive.ObjectID) (*models.Onboarding, error)
+	// LinkChotot completes the first step once the user's chotot link is
+	// verified, see ChototLinkUsecase
+	LinkChotot(ctx context.Context, userID primitive.ObjectID) (*models.Onboarding, error)
+	SelectChatMode(ctx context.Context, userID primitive.ObjectID, chatMode string) (*models.Onboarding, error)
+	SetWorkingHours(ctx context.Context, userID primitive.ObjectID, hours models.WorkingHours) (*models.Onboarding, error)
+	// RunTestConversation has the chosen chat mode answer a sample buyer
+	// message, without running any tool, and posts the exchange to the
+	// sandbox channel when one is configured
+	RunTestConversation(ctx context.Context, userID primitive.ObjectID, message string) (*models.Onboarding, error)
+}
+
+type onboardingUsecase struct {
+	onboardingRepo    mongodb.OnboardingRepository
+	chatModeRepo      mongodb.ChatModeRepository
+	userUsecase       UserUsecase
+	chototLinkUsecase ChototLinkUsecase
+	llmUsecase        LLMUsecase
+	auditUsecase      AuditUsecase
+	chatAPIClient     chatapi.Client
+	conf              config.OnboardingConfig
+}
+
+func NewOnboardingUsecase(
+	onboardingRepo mongodb.OnboardingRepository,
+	chatModeRepo mongodb.ChatModeRepository,
+	userUsecase UserUsecase,
+	chototLinkUsecase ChototLinkUsecase,
+	llmUsecase LLMUsecase,
+	auditUsecase AuditUsecase,
+	chatAPIClient chatapi.Client,
+	conf *config.Config,
+) OnboardingUsecase {
+	return &onboardingUsecase{
+		onboardingRepo:    onboardingRepo,
+		chatModeRepo:      chatModeRepo,
+		userUsecase:       userUsecase,
+		chototLinkUsecase: chototLinkUsecase,
+		llmUsecase:        llmUsecase,
+		auditUsecase:      auditUsecase,
+		chatAPIClient:     chatAPIClient,
+		conf:              conf.Onboarding,
+	}
+}
+
+func (uc *onboardingUsecase) GetOnboarding(ctx context.Context, userID primitive.ObjectID) (*models.Onboarding, error) {
+	if _, err := uc.userUsecase.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	onboarding, err := uc.onboardingRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get onboarding: %w", err)
+	}
+	if onboarding == nil {
+		onboarding = &models.Onboarding{
+			UserID:         userID,
+			Step:           models.OnboardingSteps[0],
+			CompletedSteps: []models.OnboardingStep{},
+		}
+	}
+	return onboarding, nil
+}
+
+func (uc *onboardingUsecase) LinkChotot(ctx context.Context, userID primitive.ObjectID) (*models.Onboarding, error) {
+	return uc.completeStep(ctx, userID, models.OnboardingStepLinkChotot, func(onboarding *models.Onboarding) error {
+		link, err := uc.chototLinkUsecase.GetLink(ctx, userID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return err
+		}
+		if link == nil || link.Status != models.ChototLinkVerified {
+			return fmt.Errorf("%w: chotot link is not verified", models.ErrChototLinkUnverified)
+		}
+
+		onboarding.ChototID = link.ChototID
+		onboarding.ChototOID = link.ChototOID
+		return nil
+	})
+}
+
+func (uc *onboardingUsecase) SelectChatMode(ctx context.Context, userID primitive.ObjectID, chatMode string) (*models.Onboarding, error) {
+	return uc.completeStep(ctx, userID, models.OnboardingStepChatMode, func(onboarding *models.Onboarding) error {
+		if _, err := uc.chatModeRepo.GetByName(ctx, chatMode); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrNotFound, err)
+		}
+		// The seller's attribute is what the chat mode selector reads
+		if err := uc.userUsecase.SetUserAttribute(ctx, userID, models.AttributeChototChatMode, chatMode, nil); err != nil {
+			return err
+		}
+		onboarding.ChatMode = chatMode
+		return nil
+	})
+}
+
+func (uc *onboardingUsecase) SetWorkingHours(ctx context.Context, userID primitive.ObjectID, hours models.WorkingHours) (*models.Onboarding, error) {
+	return uc.completeStep(ctx, userID, models.OnboardingStepWorkingHours, func(onboarding *models.Onboarding) error {
+		if err := validateWorkingHours(hours); err != nil {
+			return err
+		}
+		onboarding.WorkingHours = &hours
+		return nil
+	})
+}
+
+func validateWorkingHours(hours models.WorkingHours) error {
+	if _, err := time.LoadLocation(hours.Timezone); err != nil {
+		return fmt.Errorf("%w: unknown timezone %q", models.ErrInvalidWorkingHours, hours.Timezone)
+	}
+	for _, slot := range hours.Days {
+		start, err := time.Parse("15:04", slot.Start)
+		if err != nil {
+			return fmt.Errorf("%w: start %q must be HH:MM", models.ErrInvalidWorkingHours, slot.Start)
+		}
+		end, err := time.Parse("15:04", slot.End)
+		if err != nil {
+			return fmt.Errorf("%w: end %q must be HH:MM", models.ErrInvalidWorkingHours, slot.End)
+		}
+		if !end.After(start) {
+			return fmt.Errorf("%w: %s ends before it starts", models.ErrInvalidWorkingHours, slot.Weekday)
+		}
+	}
+	return nil
+}
+
+func (uc *onboardingUsecase) RunTestConversation(ctx context.Context, userID primitive.ObjectID, message string) (*models.Onboarding, error) {
+	if message == "" {
+		message = uc.conf.TestMessage
+	}
+
+	return uc.completeStep(ctx, userID, models.OnboardingStepTestConversation, func(onboarding *models.Onboarding) error {
+		chatMode, err := uc.chatModeRepo.GetByName(ctx, onboarding.ChatMode)
+		if err != nil {
+			return fmt.Errorf("failed to get chat mode: %w", err)
+		}
+
+		channelID := uc.conf.SandboxChannelID
+		if channelID == "" {
+			channelID = "onboarding-sandbox"
+		}
+		// the exchange is generated like a replay of a session that never happened
+		transcript := &models.SessionTranscript{
+			SessionID: primitive.NewObjectID(),
+			ChannelID: channelID,
+			UserID:    uc.conf.SandboxBuyerID,
+			ChatMode:  chatMode.Name,
+			Input: &models.TranscriptInput{
+				ChannelInfo: &models.ChannelInfo{
+					ID:   channelID,
+					Name: sandboxChannelName,
+					Participants: []models.Participant{
+						{UserID: onboarding.ChototID, Role: models.ParticipantRoleSeller},
+						{UserID: uc.conf.SandboxBuyerID, Role: models.ParticipantRoleBuyer},
+					},
+				},
+				SenderRole: "buyer",
+				Message:    message,
+			},
+		}
+		result, err := uc.llmUsecase.Replay(ctx, chatMode, transcript, models.ReplayProviderLive)
+		if err != nil {
+			return fmt.Errorf("failed to generate test conversation: %w", err)
+		}
+
+		conversation := &models.OnboardingTestConversation{
+			BuyerMessage: message,
+			At:           time.Now(),
+		}
+		for _, call := range result.Candidate {
+			var args reply_message.ReplyMessageArgs
+			if call.Name == reply_message.ToolName && conversation.Reply == "" && json.Unmarshal([]byte(call.Input), &args) == nil {
+				conversation.Reply = args.Message
+				continue
+			}
+			conversation.ToolCalls = append(conversation.ToolCalls, fmt.Sprintf("%s(%s)", call.Name, call.Input))
+		}
+		if conversation.Reply == "" {
+			return models.ErrOnboardingTestFailed
+		}
+
+		if uc.conf.SandboxChannelID != "" {
+			if err := uc.postTestConversation(ctx, onboarding.ChototID, conversation); err != nil {
+				return err
+			}
+			conversation.ChannelID = uc.conf.SandboxChannelID
+			conversation.Posted = true
+		}
+
+		onboarding.TestConversation = conversation
+		return nil
+	})
+}
+
+func (uc *onboardingUsecase) postTestConversation(ctx context.Context, sellerID string, conversation *models.OnboardingTestConversation) error {
+	messages := []*models.OutgoingMessage{
+		{ChannelID: uc.conf.SandboxChannelID, SenderID: uc.conf.SandboxBuyerID, Message: conversation.BuyerMessage},
+		{ChannelID: uc.conf.SandboxChannelID, SenderID: sellerID, Message: conversation.Reply},
+	}
+	for _, msg := range messages {
+		if err := uc.chatAPIClient.SendMessage(ctx, msg); err != nil {
+			return fmt.Errorf("failed to post test conversation: %w", err)
+		}
+	}
+	return nil
+}
+
+// completeStep runs apply on the user's onboarding once the steps before step
+// are completed, marks step completed and moves on to the next open step
+func (uc *onboardingUsecase) completeStep(ctx context.Context, userID primitive.ObjectID, step models.OnboardingStep, apply func(onboarding *models.Onboarding) error) (*models.Onboarding, error) {
+	onboarding, err := uc.GetOnboarding(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, previous := range models.OnboardingSteps {
+		if previous == step {
+			break
+		}
+		if !onboarding.IsCompleted(previous) {
+			return nil, models.ErrOnboardingStepLocked
+		}
+	}
+
+	before := *onboarding
+	before.CompletedSteps = slices.Clone(onboarding.CompletedSteps)
+	if err := apply(onboarding); err != nil {
+		return nil, err
+	}
+
+	if !onboarding.IsCompleted(step) {
+		onboarding.CompletedSteps = append(onboarding.CompletedSteps, step)
+	}
+	onboarding.Step = models.OnboardingStepCompleted
+	for _, next := range models.OnboardingSteps {
+		if !onboarding.IsCompleted(next) {
+			onboarding.Step = next
+			break
+		}
+	}
+	if onboarding.Step == models.OnboardingStepCompleted && onboarding.CompletedAt == nil {
+		now := time.Now()
+		onboarding.CompletedAt = &now
+	}
+
+	if err := uc.onboardingRepo.Upsert(ctx, onboarding); err != nil {
+		return nil, fmt.Errorf("failed to save onboarding: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditOnboardingUpdate, "onboarding", userID.Hex(), before, onboarding)
+	return onboarding, nil
+}
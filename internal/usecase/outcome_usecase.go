@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/fx"
+)
+
+// defaultReportWindow is the span of a conversion report without a since
+const defaultReportWindow = 30 * 24 * time.Hour
+
+// OutcomeUsecase tracks how conversations ended for the seller and attributes
+// the outcomes to the chat mode and prompt version that handled them. With a
+// session per buyer message, the outcome of a conversation goes on its latest
+// session, so attribution is last touch.
+type OutcomeUsecase interface {
+	// Record sets the outcome of a session and links it to the purchase
+	// intents and reservations of its channel. Only the seller can change an
+	// outcome already set, others get models.ErrOutcomeSet.
+	Record(ctx context.Context, sessionID primitive.ObjectID, outcome models.SessionOutcome, source models.OutcomeSource, reason, orderID string) (*models.ChatSession, error)
+	// RecordChannel records the outcome on the channel's latest session
+	RecordChannel(ctx context.Context, channelID string, outcome models.SessionOutcome, source models.OutcomeSource, reason, orderID string) (*models.ChatSession, error)
+	// HandOff resolves the channel's latest session as handed_off unless it
+	// has an outcome. Errors are logged.
+	HandOff(ctx context.Context, channelID string)
+	// ResolveStale resolves as no_response the latest session of the channels
+	// quiet for OUTCOME_NO_RESPONSE_AFTER, returning how many it resolved
+	ResolveStale(ctx context.Context) (int, error)
+	ConversionReport(ctx context.Context, since, until time.Time) (*models.ConversionReport, error)
+}
+
+type outcomeUsecase struct {
+	conf               config.OutcomeConfig
+	sessionRepo        mongodb.ChatSessionRepository
+	purchaseIntentRepo mongodb.PurchaseIntentRepository
+	reservationRepo    mongodb.ReservationRepository
+	auditUsecase       AuditUsecase
+}
+
+func NewOutcomeUsecase(
+	conf *config.Config,
+	sessionRepo mongodb.ChatSessionRepository,
+	purchaseIntentRepo mongodb.PurchaseIntentRepository,
+	reservationRepo mongodb.ReservationRepository,
+	auditUsecase AuditUsecase,
+) OutcomeUsecase {
+	return &outcomeUsecase{
+		conf:               conf.Outcome,
+		sessionRepo:        sessionRepo,
+		purchaseIntentRepo: purchaseIntentRepo,
+		reservationRepo:    reservationRepo,
+		auditUsecase:       auditUsecase,
+	}
+}
+
+func (uc *outcomeUsecase) Record(ctx context.Context, sessionID primitive.ObjectID, outcome models.SessionOutcome, source models.OutcomeSource, reason, orderID string) (*models.ChatSession, error) {
+	before, err := uc.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.record(ctx, before, outcome, source, reason, orderID)
+}
+
+func (uc *outcomeUsecase) RecordChannel(ctx context.Context, channelID string, outcome models.SessionOutcome, source models.OutcomeSource, reason, orderID string) (*models.ChatSession, error) {
+	before, err := uc.sessionRepo.GetLatestByChannel(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, models.ErrNotFound
+	}
+	return uc.record(ctx, before, outcome, source, reason, orderID)
+}
+
+func (uc *outcomeUsecase) HandOff(ctx context.Context, channelID string) {
+	_, err := uc.RecordChannel(ctx, channelID, models.SessionOutcomeHandedOff, models.OutcomeSourceHeuristic, "negative sentiment", "")
+	if err != nil && !errors.Is(err, models.ErrOutcomeSet) && !errors.Is(err, models.ErrNotFound) {
+		log.Errorw(ctx, "Failed to record handed off outcome", "channel_id", channelID, "error", err)
+	}
+}
+
+func (uc *outcomeUsecase) ResolveStale(ctx context.Context) (int, error) {
+	now := time.Now()
+	sessions, err := uc.sessionRepo.ListUnresolved(ctx, now.Add(-uc.conf.Lookback), now.Add(-uc.conf.NoResponseAfter))
+	if err != nil {
+		return 0, err
+	}
+
+	resolved := 0
+	for _, session := range sessions {
+		// the resolver runs without a tenant, the links are looked up in the session's
+		sessionCtx := ctx
+		if session.TenantID != nil {
+			sessionCtx = models.WithTenantID(ctx, *session.TenantID)
+		}
+		_, err := uc.record(sessionCtx, session, models.SessionOutcomeNoResponse, models.OutcomeSourceHeuristic, "", "")
+		if err != nil {
+			if !errors.Is(err, models.ErrOutcomeSet) {
+				log.Warnw(ctx, "Failed to resolve stale session", "session_id", session.ID.Hex(), "error", err)
+			}
+			continue
+		}
+		resolved++
+	}
+	return resolved, nil
+}
+
+func (uc *outcomeUsecase) ConversionReport(ctx context.Context, since, until time.Time) (*models.ConversionReport, error) {
+	if until.IsZero() {
+		until = time.Now()
+	}
+	if since.IsZero() {
+		since = until.Add(-defaultReportWindow)
+	}
+
+	counts, err := uc.sessionRepo.CountOutcomes(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ConversionReport{Since: since, Until: until, Rows: []models.ConversionRow{}}
+	rows := make(map[[2]string]int)
+	for _, count := range counts {
+		key := [2]string{count.ChatMode, count.PromptVersion}
+		i, ok := rows[key]
+		if !ok {
+			i = len(report.Rows)
+			rows[key] = i
+			report.Rows = append(report.Rows, models.ConversionRow{ChatMode: count.ChatMode, PromptVersion: count.PromptVersion})
+		}
+
+		row := &report.Rows[i]
+		row.Sessions += count.Count
+		switch count.Outcome {
+		case models.SessionOutcomeSold:
+			row.Sold += count.Count
+		case models.SessionOutcomeLost:
+			row.Lost += count.Count
+		case models.SessionOutcomeNoResponse:
+			row.NoResponse += count.Count
+		case models.SessionOutcomeHandedOff:
+			row.HandedOff += count.Count
+		}
+	}
+	for i := range report.Rows {
+		row := &report.Rows[i]
+		row.ConversionRate = float64(row.Sold) / float64(row.Sessions)
+	}
+	return report, nil
+}
+
+// record stores the outcome on session. Seller outcomes overwrite and are
+// audited, the others only fill sessions without one.
+func (uc *outcomeUsecase) record(ctx context.Context, session *models.ChatSession, outcome models.SessionOutcome, source models.OutcomeSource, reason, orderID string) (*models.ChatSession, error) {
+	overwrite := source == models.OutcomeSourceSeller
+	if session.Outcome != nil && !overwrite {
+		return nil, models.ErrOutcomeSet
+	}
+
+	record := &models.SessionOutcomeRecord{
+		Outcome: outcome,
+		Source:  source,
+		Reason:  reason,
+		OrderID: orderID,
+		At:      time.Now(),
+	}
+	if err := uc.link(ctx, session.ChannelID, record); err != nil {
+		return nil, err
+	}
+
+	set, err := uc.sessionRepo.SetOutcome(ctx, session.ID, record, overwrite)
+	if err != nil {
+		return nil, err
+	}
+	if !set {
+		return nil, models.ErrOutcomeSet
+	}
+	log.Infow(ctx, "Recorded session outcome", "session_id", session.ID.Hex(), "channel_id", session.ChannelID,
+		"outcome", outcome, "source", source)
+
+	after, err := uc.sessionRepo.GetByID(ctx, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if overwrite {
+		uc.auditUsecase.Record(ctx, models.AuditSessionOutcome, "chat_session", session.ID.Hex(), session, after)
+	}
+	return after, nil
+}
+
+// link adds the purchase intents and reservations of the channel to record
+func (uc *outcomeUsecase) link(ctx context.Context, channelID string, record *models.SessionOutcomeRecord) error {
+	intents, err := uc.purchaseIntentRepo.GetByChannelID(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	for _, intent := range intents {
+		record.PurchaseIntentIDs = append(record.PurchaseIntentIDs, intent.ID)
+	}
+
+	reservations, err := uc.reservationRepo.ListByChannel(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	for _, reservation := range reservations {
+		record.ReservationIDs = append(record.ReservationIDs, reservation.ID)
+	}
+	return nil
+}
+
+// StartOutcomeResolver runs ResolveStale every OUTCOME_INTERVAL while the app is running
+func StartOutcomeResolver(lc fx.Lifecycle, uc OutcomeUsecase, conf *config.Config) {
+	if conf.Outcome.Interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(models.WithAllTenants(context.Background()))
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(conf.Outcome.Interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+
+					resolved, err := uc.ResolveStale(ctx)
+					if err != nil {
+						log.Errorw(ctx, "Failed to resolve stale sessions", "error", err)
+						continue
+					}
+					log.Infow(ctx, "Resolved stale sessions", "resolved", resolved)
+				}
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
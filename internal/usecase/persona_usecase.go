@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// PersonaUsecase manages the bot persona per tenant and seller. Personas are
+// looked up on every session, so an edit applies to the next message.
+type PersonaUsecase interface {
+	// SetPersona replaces the persona of the seller, or the tenant-wide one
+	// when sellerID is empty
+	SetPersona(ctx context.Context, persona *models.Persona) (*models.Persona, error)
+	// GetPersona returns models.ErrNotFound when the scope has no persona
+	GetPersona(ctx context.Context, sellerID string) (*models.Persona, error)
+	DeletePersona(ctx context.Context, sellerID string) error
+	// ResolvePersona merges the seller persona over the tenant-wide one. It
+	// returns nil when neither is set.
+	ResolvePersona(ctx context.Context, sellerID string) (*models.Persona, error)
+}
+
+type personaUsecase struct {
+	personaRepo  mongodb.PersonaRepository
+	auditUsecase AuditUsecase
+}
+
+func NewPersonaUsecase(personaRepo mongodb.PersonaRepository, auditUsecase AuditUsecase) PersonaUsecase {
+	return &personaUsecase{
+		personaRepo:  personaRepo,
+		auditUsecase: auditUsecase,
+	}
+}
+
+func (uc *personaUsecase) SetPersona(ctx context.Context, persona *models.Persona) (*models.Persona, error) {
+	persona.DisplayName = strings.TrimSpace(persona.DisplayName)
+	persona.Tone = strings.TrimSpace(persona.Tone)
+	persona.Languages = compactStrings(persona.Languages)
+	persona.ForbiddenTopics = compactStrings(persona.ForbiddenTopics)
+	if persona.IsEmpty() {
+		return nil, models.ErrEmptyPersona
+	}
+
+	before, err := uc.personaRepo.Get(ctx, persona.SellerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.personaRepo.Upsert(ctx, persona); err != nil {
+		return nil, fmt.Errorf("failed to store persona: %w", err)
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditPersonaSet, "persona", persona.ID.Hex(), before, persona)
+	log.Infow(ctx, "Persona stored", "seller_id", persona.SellerID, "display_name", persona.DisplayName)
+	return persona, nil
+}
+
+func (uc *personaUsecase) GetPersona(ctx context.Context, sellerID string) (*models.Persona, error) {
+	persona, err := uc.personaRepo.Get(ctx, sellerID)
+	if err != nil {
+		return nil, err
+	}
+	if persona == nil {
+		return nil, models.ErrNotFound
+	}
+	return persona, nil
+}
+
+func (uc *personaUsecase) DeletePersona(ctx context.Context, sellerID string) error {
+	before, err := uc.personaRepo.Get(ctx, sellerID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.ErrNotFound
+	}
+
+	if err := uc.personaRepo.Delete(ctx, sellerID); err != nil {
+		return err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditPersonaDelete, "persona", before.ID.Hex(), before, nil)
+	return nil
+}
+
+func (uc *personaUsecase) ResolvePersona(ctx context.Context, sellerID string) (*models.Persona, error) {
+	tenantPersona, err := uc.personaRepo.Get(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var sellerPersona *models.Persona
+	if sellerID != "" {
+		sellerPersona, err = uc.personaRepo.Get(ctx, sellerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if tenantPersona == nil && sellerPersona == nil {
+		return nil, nil
+	}
+
+	resolved := models.Persona{}.Override(tenantPersona).Override(sellerPersona)
+	return &resolved, nil
+}
+
+// compactStrings trims the values and drops the empty ones
+func compactStrings(values []string) []string {
+	var out []string
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// describePersona is the preamble put ahead of every chat mode prompt
+func describePersona(persona *models.Persona) string {
+	var sb strings.Builder
+	sb.WriteString("Persona, which applies to every reply:")
+	if persona.DisplayName != "" {
+		fmt.Fprintf(&sb, "\n- Your name is %s. Introduce yourself with it when asked who you are.", persona.DisplayName)
+	}
+	if persona.Tone != "" {
+		fmt.Fprintf(&sb, "\n- Tone: %s", persona.Tone)
+	}
+	if len(persona.Languages) > 0 {
+		fmt.Fprintf(&sb, "\n- Only answer in %s. Prefer the buyer's language when it is one of these, otherwise use %s.",
+			strings.Join(persona.Languages, ", "), persona.Languages[0])
+	}
+	if len(persona.ForbiddenTopics) > 0 {
+		fmt.Fprintf(&sb, "\n- Never discuss: %s. Politely decline and steer back to the item.",
+			strings.Join(persona.ForbiddenTopics, "; "))
+	}
+	return sb.String()
+}
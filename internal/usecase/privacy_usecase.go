@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// PrivacyUsecase keeps private rooms and messages out of prompts, tool
+// results and suggestions. Private content stays visible in the room.
+type PrivacyUsecase interface {
+	// Get returns the channel's privacy, empty when nothing was made private
+	Get(ctx context.Context, channelID string) (*models.ChannelPrivacy, error)
+	SetPrivate(ctx context.Context, channelID string, private bool) (*models.ChannelPrivacy, error)
+	SetMessagePrivate(ctx context.Context, channelID, messageID string, private bool) (*models.ChannelPrivacy, error)
+	// Excludes reports whether message is kept from the LLM. Errors are
+	// logged and exclude it.
+	Excludes(ctx context.Context, channelID string, message models.HistoryMessage) bool
+	// FilterHistory returns the channel's history without its private messages
+	FilterHistory(ctx context.Context, channelID string, history *models.MessageHistory) (*models.MessageHistory, error)
+}
+
+type privacyUsecase struct {
+	conf               config.PrivacyConfig
+	channelPrivacyRepo mongodb.ChannelPrivacyRepository
+	auditUsecase       AuditUsecase
+}
+
+func NewPrivacyUsecase(
+	conf *config.Config,
+	channelPrivacyRepo mongodb.ChannelPrivacyRepository,
+	auditUsecase AuditUsecase,
+) PrivacyUsecase {
+	return &privacyUsecase{
+		conf:               conf.Privacy,
+		channelPrivacyRepo: channelPrivacyRepo,
+		auditUsecase:       auditUsecase,
+	}
+}
+
+func (uc *privacyUsecase) Get(ctx context.Context, channelID string) (*models.ChannelPrivacy, error) {
+	privacy, err := uc.channelPrivacyRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if privacy == nil {
+		return &models.ChannelPrivacy{ChannelID: channelID}, nil
+	}
+	return privacy, nil
+}
+
+func (uc *privacyUsecase) SetPrivate(ctx context.Context, channelID string, private bool) (*models.ChannelPrivacy, error) {
+	before, err := uc.channelPrivacyRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := uc.channelPrivacyRepo.SetPrivate(ctx, channelID, private)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditChannelPrivacySet, "channel", channelID, before, after)
+	return after, nil
+}
+
+func (uc *privacyUsecase) SetMessagePrivate(ctx context.Context, channelID, messageID string, private bool) (*models.ChannelPrivacy, error) {
+	before, err := uc.channelPrivacyRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	after, err := uc.channelPrivacyRepo.SetMessagePrivate(ctx, channelID, messageID, private)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.auditUsecase.Record(ctx, models.AuditChannelMessagePrivacySet, "channel", channelID, before, after)
+	return after, nil
+}
+
+func (uc *privacyUsecase) Excludes(ctx context.Context, channelID string, message models.HistoryMessage) bool {
+	privacy, err := uc.channelPrivacyRepo.Get(ctx, channelID)
+	if err != nil {
+		log.Errorw(ctx, "Failed to get channel privacy", "channel_id", channelID, "error", err)
+		return true
+	}
+	return privacy.Excludes(message, uc.conf.DetectSensitive)
+}
+
+func (uc *privacyUsecase) FilterHistory(ctx context.Context, channelID string, history *models.MessageHistory) (*models.MessageHistory, error) {
+	privacy, err := uc.channelPrivacyRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	return privacy.FilterHistory(history, uc.conf.DetectSensitive), nil
+}
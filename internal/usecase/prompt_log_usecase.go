@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/redact"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PromptLogUsecase interface {
+	List(ctx context.Context, filter models.PromptLogFilter) ([]*models.PromptLog, error)
+}
+
+type promptLogUsecase struct {
+	promptLogRepo mongodb.PromptLogRepository
+}
+
+func NewPromptLogUsecase(promptLogRepo mongodb.PromptLogRepository) PromptLogUsecase {
+	return &promptLogUsecase{
+		promptLogRepo: promptLogRepo,
+	}
+}
+
+func (uc *promptLogUsecase) List(ctx context.Context, filter models.PromptLogFilter) ([]*models.PromptLog, error) {
+	return uc.promptLogRepo.List(ctx, filter)
+}
+
+// promptLogger stores every generation of a sampled session. Sampling is
+// decided once per session so a session is either logged completely or not
+// at all. A nil logger records nothing.
+type promptLogger struct {
+	repo      mongodb.PromptLogRepository
+	sessionID primitive.ObjectID
+	chatMode  string
+	model     string
+	retention time.Duration
+}
+
+func newPromptLogger(conf config.PromptLogConfig, repo mongodb.PromptLogRepository, chatMode *models.ChatMode, data *PromptData) *promptLogger {
+	if !conf.Enabled || rand.Float64() >= conf.SampleRate {
+		return nil
+	}
+	// the session ID was validated before the logger is created
+	sessionID, _ := primitive.ObjectIDFromHex(data.SessionID)
+	return &promptLogger{
+		repo:      repo,
+		sessionID: sessionID,
+		chatMode:  chatMode.Name,
+		model:     chatMode.Model,
+		retention: conf.Retention,
+	}
+}
+
+// record stores the prompt of one generation along with its response, or the
+// error it failed with. Failures to store are logged and otherwise ignored.
+func (p *promptLogger) record(ctx context.Context, iteration int, messages []*ai.Message, response *ai.ModelResponse, latency time.Duration, genErr error) {
+	if p == nil {
+		return
+	}
+
+	entry := &models.PromptLog{
+		SessionID: p.sessionID,
+		ChatMode:  p.chatMode,
+		Model:     p.model,
+		Iteration: iteration,
+		LatencyMs: latency.Milliseconds(),
+		ExpiresAt: time.Now().Add(p.retention),
+	}
+	for _, msg := range messages {
+		entry.Messages = append(entry.Messages, models.PromptLogMessage{
+			Role: string(msg.Role),
+			Text: redactPrompt(flattenMessage(msg)),
+		})
+	}
+	if genErr != nil {
+		entry.Error = redactPrompt(genErr.Error())
+	}
+	if response != nil {
+		entry.Response = redactPrompt(response.Text())
+		entry.FinishReason = string(response.FinishReason)
+		for _, req := range response.ToolRequests() {
+			entry.ToolRequests = append(entry.ToolRequests, redactPrompt(formatToolRequest(req)))
+		}
+		if response.Usage != nil {
+			entry.InputTokens = response.Usage.InputTokens
+			entry.OutputTokens = response.Usage.OutputTokens
+		}
+	}
+
+	if err := p.repo.Create(context.WithoutCancel(ctx), entry); err != nil {
+		log.Errorw(ctx, "Failed to save prompt log", "session_id", p.sessionID.Hex(), "error", err)
+	}
+}
+
+// flattenMessage renders the text, tool request and tool response parts of
+// msg as plain text
+func flattenMessage(msg *ai.Message) string {
+	var parts []string
+	for _, part := range msg.Content {
+		switch {
+		case part.IsText():
+			parts = append(parts, part.Text)
+		case part.IsToolRequest():
+			parts = append(parts, formatToolRequest(part.ToolRequest))
+		case part.IsToolResponse():
+			parts = append(parts, fmt.Sprintf("%s -> %s", part.ToolResponse.Name, encodeToolData(part.ToolResponse.Output)))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+func formatToolRequest(req *ai.ToolRequest) string {
+	return fmt.Sprintf("%s(%s)", req.Name, encodeToolData(req.Input))
+}
+
+func redactPrompt(s string) string {
+	return redact.PII(redact.Secrets(s))
+}
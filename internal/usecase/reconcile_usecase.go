@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"go.uber.org/fx"
+)
+
+// ReconcileUsecase answers buyer messages that never reached the bot, e.g.
+// while Kafka was unavailable or the service was down
+type ReconcileUsecase interface {
+	// Reconcile checks every channel active within the lookback window and
+	// processes its newest buyer message when it is unanswered and was never
+	// claimed. Earlier missed messages are part of the history the bot reads.
+	Reconcile(ctx context.Context) (*models.ReconcileReport, error)
+}
+
+type reconcileUsecase struct {
+	cursorRepo     mongodb.ChannelCursorRepository
+	chatAPIClient  chatapi.Client
+	messageUsecase MessageUsecase
+	conf           config.ReconcileConfig
+}
+
+func NewReconcileUsecase(
+	cursorRepo mongodb.ChannelCursorRepository,
+	chatAPIClient chatapi.Client,
+	messageUsecase MessageUsecase,
+	conf *config.Config,
+) ReconcileUsecase {
+	return &reconcileUsecase{
+		cursorRepo:     cursorRepo,
+		chatAPIClient:  chatAPIClient,
+		messageUsecase: messageUsecase,
+		conf:           conf.Reconcile,
+	}
+}
+
+func (uc *reconcileUsecase) Reconcile(ctx context.Context) (*models.ReconcileReport, error) {
+	report := &models.ReconcileReport{
+		StartedAt:  time.Now(),
+		Backfilled: []models.ReconciledMessage{},
+		Failed:     []models.ReconciledMessage{},
+	}
+
+	cursors, err := uc.cursorRepo.ListUpdatedSince(ctx, report.StartedAt.Add(-uc.conf.Lookback))
+	if err != nil {
+		return nil, err
+	}
+	report.Channels = len(cursors)
+
+	for _, cursor := range cursors {
+		missed, err := uc.reconcileChannel(ctx, cursor, report.StartedAt)
+		if err != nil {
+			log.Warnw(ctx, "Failed to reconcile channel", "channel_id", cursor.ChannelID, "error", err)
+			report.Failed = append(report.Failed, models.ReconciledMessage{ChannelID: cursor.ChannelID, Error: err.Error()})
+			continue
+		}
+		if missed != nil {
+			report.Backfilled = append(report.Backfilled, *missed)
+		}
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// reconcileChannel processes the newest message of the channel when it comes
+// from the buyer after the cursor, returning nil when nothing was missed
+func (uc *reconcileUsecase) reconcileChannel(ctx context.Context, cursor *models.ChannelCursor, now time.Time) (*models.ReconciledMessage, error) {
+	history, err := uc.chatAPIClient.GetMessageHistory(ctx, cursor.BuyerID, cursor.ChannelID, 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message history: %w", err)
+	}
+	if len(history.Messages) == 0 {
+		return nil, nil
+	}
+
+	// anything newer than the buyer's message, such as a seller reply, means
+	// the conversation moved on
+	latest := history.Messages[0]
+	createdAt := latest.CreatedAt.UnixMilli()
+	if latest.SenderID != cursor.BuyerID || createdAt <= cursor.LastMessageAt {
+		return nil, nil
+	}
+	if now.Sub(latest.CreatedAt) < uc.conf.MinAge {
+		return nil, nil
+	}
+
+	livestats.Inc(models.StatMessagesPrefix + "reconcile")
+	log.Infow(ctx, "Backfilling missed message", "channel_id", cursor.ChannelID, "sender_id", latest.SenderID, "created_at", createdAt)
+	message := models.IncomingMessage{
+		ChannelID: cursor.ChannelID,
+		CreatedAt: createdAt,
+		SenderID:  latest.SenderID,
+		Message:   latest.Message,
+		Metadata: models.IncomingMessageMeta{
+			LLM: models.LLMMetadata{ChatMode: cursor.ChatMode},
+		},
+	}
+	if err := uc.messageUsecase.ProcessMessage(ctx, message); err != nil {
+		return nil, err
+	}
+
+	return &models.ReconciledMessage{
+		ChannelID: cursor.ChannelID,
+		SenderID:  latest.SenderID,
+		CreatedAt: createdAt,
+	}, nil
+}
+
+// StartReconciler runs Reconcile every RECONCILE_INTERVAL while the app is running
+func StartReconciler(lc fx.Lifecycle, uc ReconcileUsecase, conf *config.Config) {
+	if conf.Reconcile.Interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(models.WithAllTenants(context.Background()))
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(conf.Reconcile.Interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+
+					report, err := uc.Reconcile(ctx)
+					if err != nil {
+						log.Errorw(ctx, "Failed to reconcile missed messages", "error", err)
+						continue
+					}
+					log.Infow(ctx, "Reconciled missed messages",
+						"channels", report.Channels,
+						"backfilled", len(report.Backfilled),
+						"failed", len(report.Failed))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
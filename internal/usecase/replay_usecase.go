@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultReplayLimit = 20
+	maxReplayLimit     = 200
+)
+
+// ReplayUsecase evaluates chat mode changes offline, against the inputs of
+// logged sessions
+type ReplayUsecase interface {
+	Run(ctx context.Context, req models.ReplayRequest) (*models.ReplayReport, error)
+}
+
+type replayUsecase struct {
+	chatModeRepo   mongodb.ChatModeRepository
+	transcriptRepo mongodb.TranscriptRepository
+	llmUsecase     LLMUsecase
+}
+
+func NewReplayUsecase(
+	chatModeRepo mongodb.ChatModeRepository,
+	transcriptRepo mongodb.TranscriptRepository,
+	llmUsecase LLMUsecase,
+) ReplayUsecase {
+	return &replayUsecase{
+		chatModeRepo:   chatModeRepo,
+		transcriptRepo: transcriptRepo,
+		llmUsecase:     llmUsecase,
+	}
+}
+
+func (uc *replayUsecase) Run(ctx context.Context, req models.ReplayRequest) (*models.ReplayReport, error) {
+	provider := req.Provider
+	if provider == "" {
+		provider = models.ReplayProviderRecorded
+	}
+	if provider != models.ReplayProviderRecorded && provider != models.ReplayProviderLive {
+		return nil, fmt.Errorf("%w: unknown provider %q", models.ErrInvalidReplayRequest, provider)
+	}
+
+	candidate, err := uc.candidateChatMode(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	transcripts, results, err := uc.loadTranscripts(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReplayReport{
+		ChatMode:  candidate.Name,
+		Model:     candidate.Model,
+		Provider:  provider,
+		StartedAt: time.Now(),
+	}
+	for _, transcript := range transcripts {
+		result, err := uc.llmUsecase.Replay(ctx, candidate, transcript, provider)
+		if err != nil {
+			log.Warnw(ctx, "Failed to replay session", "session_id", transcript.SessionID.Hex(), "error", err)
+			result = &models.ReplayResult{
+				SessionID:      transcript.SessionID.Hex(),
+				SourceChatMode: transcript.ChatMode,
+				Error:          err.Error(),
+			}
+		}
+		results = append(results, *result)
+	}
+
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			report.Failed++
+		case result.Changed:
+			report.Changed++
+		}
+	}
+	report.Results = results
+	report.Sessions = len(results)
+	report.Duration = time.Since(report.StartedAt).Round(time.Millisecond).String()
+	return report, nil
+}
+
+// candidateChatMode loads the stored chat mode and applies the overrides of req
+func (uc *replayUsecase) candidateChatMode(ctx context.Context, req models.ReplayRequest) (*models.ChatMode, error) {
+	stored, err := uc.chatModeRepo.GetByName(ctx, req.ChatMode)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidReplayRequest, err)
+	}
+
+	candidate := *stored
+	if req.PromptTemplate != "" {
+		candidate.PromptTemplate = req.PromptTemplate
+	}
+	if req.Condition != nil {
+		candidate.Condition = *req.Condition
+	}
+	if req.Model != "" {
+		candidate.Model = req.Model
+	}
+	if req.Tools != nil {
+		candidate.Tools = req.Tools
+	}
+	return &candidate, nil
+}
+
+// loadTranscripts returns the transcripts to replay, along with failed
+// results for requested sessions that have none
+func (uc *replayUsecase) loadTranscripts(ctx context.Context, req models.ReplayRequest) ([]*models.SessionTranscript, []models.ReplayResult, error) {
+	if len(req.SessionIDs) == 0 {
+		limit := req.Limit
+		if limit <= 0 {
+			limit = defaultReplayLimit
+		}
+		if limit > maxReplayLimit {
+			return nil, nil, fmt.Errorf("%w: limit must be at most %d", models.ErrInvalidReplayRequest, maxReplayLimit)
+		}
+		transcripts, err := uc.transcriptRepo.ListRecent(ctx, req.SourceChatMode, limit)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list transcripts: %w", err)
+		}
+		return transcripts, nil, nil
+	}
+
+	if len(req.SessionIDs) > maxReplayLimit {
+		return nil, nil, fmt.Errorf("%w: at most %d sessions can be replayed at once", models.ErrInvalidReplayRequest, maxReplayLimit)
+	}
+
+	var transcripts []*models.SessionTranscript
+	var missing []models.ReplayResult
+	for _, idParam := range req.SessionIDs {
+		sessionID, err := primitive.ObjectIDFromHex(idParam)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: invalid session ID %q", models.ErrInvalidReplayRequest, idParam)
+		}
+
+		transcript, err := uc.transcriptRepo.GetBySessionID(ctx, sessionID)
+		if errors.Is(err, models.ErrNotFound) {
+			missing = append(missing, models.ReplayResult{SessionID: idParam, Error: "transcript not found"})
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		transcripts = append(transcripts, transcript)
+	}
+	return transcripts, missing, nil
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+)
+
+// DefaultReplyCandidates is the number of replies suggested when the caller
+// does not ask for a count
+const DefaultReplyCandidates = 3
+
+// ReplyAssistUsecase drafts replies for sellers who answer buyers themselves,
+// reusing the chat modes without the agent loop
+type ReplyAssistUsecase interface {
+	// SuggestReplies drafts count replies to the channel's latest buyer
+	// message. chatMode overrides the selected mode when set.
+	SuggestReplies(ctx context.Context, channelID, chatMode string, count int) (*models.ReplyCandidates, error)
+}
+
+type replyAssistUsecase struct {
+	chatAPIClient    chatapi.Client
+	chatModeRepo     mongodb.ChatModeRepository
+	chatModeSelector ChatModeSelector
+	intentClassifier IntentClassifier
+	personaUsecase   PersonaUsecase
+	listingExpander  ListingExpander
+	llmUsecase       LLMUsecase
+	privacyUsecase   PrivacyUsecase
+}
+
+func NewReplyAssistUsecase(
+	chatAPIClient chatapi.Client,
+	chatModeRepo mongodb.ChatModeRepository,
+	chatModeSelector ChatModeSelector,
+	intentClassifier IntentClassifier,
+	personaUsecase PersonaUsecase,
+	listingExpander ListingExpander,
+	llmUsecase LLMUsecase,
+	privacyUsecase PrivacyUsecase,
+) ReplyAssistUsecase {
+	return &replyAssistUsecase{
+		chatAPIClient:    chatAPIClient,
+		chatModeRepo:     chatModeRepo,
+		chatModeSelector: chatModeSelector,
+		intentClassifier: intentClassifier,
+		personaUsecase:   personaUsecase,
+		listingExpander:  listingExpander,
+		llmUsecase:       llmUsecase,
+		privacyUsecase:   privacyUsecase,
+	}
+}
+
+func (uc *replyAssistUsecase) SuggestReplies(ctx context.Context, channelID, chatMode string, count int) (*models.ReplyCandidates, error) {
+	if count <= 0 {
+		count = DefaultReplyCandidates
+	}
+
+	channelInfo, err := uc.chatAPIClient.GetChannelInfo(ctx, channelID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel info: %w", err)
+	}
+	sellerID := findSellerIDFromChannel(channelInfo)
+
+	history, err := uc.chatAPIClient.GetMessageHistoryWithParams(ctx, chatapi.MessageHistoryRequest{
+		UserID:    sellerID,
+		ChannelID: channelID,
+		Limit:     20,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get message history: %w", err)
+	}
+	// private messages are neither answered nor shown to the LLM
+	history, err = uc.privacyUsecase.FilterHistory(ctx, channelID, history)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter private messages: %w", err)
+	}
+
+	// History comes newest first; answer the latest buyer message
+	latest := -1
+	for i, msg := range history.Messages {
+		if msg.Message != "" && findSenderRole(channelInfo, msg.SenderID) == "buyer" {
+			latest = i
+			break
+		}
+	}
+	if latest < 0 {
+		return nil, models.ErrNoBuyerMessage
+	}
+	buyerMessage := history.Messages[latest]
+
+	message := models.IncomingMessage{
+		ChannelID: channelID,
+		CreatedAt: buyerMessage.CreatedAt.UnixMilli(),
+		SenderID:  buyerMessage.SenderID,
+		Message:   buyerMessage.Message,
+		Metadata: models.IncomingMessageMeta{
+			LLM:    models.LLMMetadata{ChatMode: chatMode},
+			Intent: uc.intentClassifier.Classify(buyerMessage.Message),
+		},
+	}
+	chatModeName, _, err := uc.chatModeSelector.Select(ctx, message, channelInfo, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select chat mode: %w", err)
+	}
+	mode, err := uc.chatModeRepo.GetByName(ctx, chatModeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat mode '%s': %w", chatModeName, err)
+	}
+
+	persona, err := uc.personaUsecase.ResolvePersona(ctx, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve persona: %w", err)
+	}
+
+	data := &PromptData{
+		ChannelInfo:    channelInfo,
+		UserID:         message.SenderID,
+		SenderRole:     "buyer",
+		Message:        message.Message,
+		RecentMessages: &models.MessageHistory{Messages: history.Messages[latest+1:]},
+		Listings:       uc.listingExpander.Expand(ctx, message.Message),
+		Intent:         message.Metadata.Intent,
+		BuyerLanguage:  textx.DetectLanguage(message.Message),
+		Persona:        persona,
+	}
+	replies, err := uc.llmUsecase.SuggestReplies(ctx, mode, data, count)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infow(ctx, "Suggested replies", "channel_id", channelID, "chat_mode", mode.Name, "count", len(replies))
+	return &models.ReplyCandidates{
+		ChannelID: channelID,
+		ChatMode:  mode.Name,
+		InReplyTo: message.Message,
+		Replies:   replies,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReservationUsecase interface {
+	ListActiveReservations(ctx context.Context, sellerID string) ([]*models.Reservation, error)
+	// ReleaseReservation releases a hold of the seller, models.ErrNotFound
+	// when the reservation is not the seller's or not active
+	ReleaseReservation(ctx context.Context, sellerID string, id primitive.ObjectID) error
+}
+
+type reservationUsecase struct {
+	reservationRepo mongodb.ReservationRepository
+	auditUsecase    AuditUsecase
+}
+
+func NewReservationUsecase(reservationRepo mongodb.ReservationRepository, auditUsecase AuditUsecase) ReservationUsecase {
+	return &reservationUsecase{
+		reservationRepo: reservationRepo,
+		auditUsecase:    auditUsecase,
+	}
+}
+
+func (uc *reservationUsecase) ListActiveReservations(ctx context.Context, sellerID string) ([]*models.Reservation, error) {
+	reservations, err := uc.reservationRepo.ListActiveBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reservations: %w", err)
+	}
+	return reservations, nil
+}
+
+func (uc *reservationUsecase) ReleaseReservation(ctx context.Context, sellerID string, id primitive.ObjectID) error {
+	if err := uc.reservationRepo.Release(ctx, sellerID, id); err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+
+	after, err := uc.reservationRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get reservation: %w", err)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditReservationRelease, "reservation", id.Hex(), nil, after)
+	return nil
+}
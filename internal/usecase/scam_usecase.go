@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed scam_patterns.yaml
+var scamPatternsData []byte
+
+// linkPattern matches URLs and bare domains with a common TLD, capturing the host
+var linkPattern = regexp.MustCompile(`(?i)(?:https?://|www\.)([a-z0-9.-]+)|\b((?:[a-z0-9-]+\.)+(?:com|net|org|vn|info|xyz|top|site|online|link|shop|store|app|ly|me|cc|io))\b`)
+
+// ScamUsecase flags buyer messages that look like marketplace scams: links to
+// outside pages, requests to move off Chợ Tốt or to pay in advance, and known
+// scam templates. Flags wait in a review queue for admins.
+type ScamUsecase interface {
+	// Detect returns the scam patterns found in message, nil when none
+	Detect(message string) []models.ScamPattern
+	// Check flags the buyer message when it matches a scam pattern and posts
+	// the safety notice to its channel, once per pending review
+	Check(ctx context.Context, message models.IncomingMessage, sellerID string) (*models.ScamFlag, error)
+	ListFlags(ctx context.Context, filter models.ScamFlagFilter) ([]*models.ScamFlag, error)
+	// Review confirms or dismisses a pending flag
+	Review(ctx context.Context, id primitive.ObjectID, status models.ScamFlagStatus) (*models.ScamFlag, error)
+}
+
+type scamUsecase struct {
+	conf           config.ScamConfig
+	allowedDomains []string
+	rules          []models.ScamRule
+	scamFlagRepo   mongodb.ScamFlagRepository
+	chatAPIClient  chatapi.Client
+	auditUsecase   AuditUsecase
+	systemMessages SystemMessageUsecase
+}
+
+func NewScamUsecase(
+	conf *config.Config,
+	scamFlagRepo mongodb.ScamFlagRepository,
+	chatAPIClient chatapi.Client,
+	auditUsecase AuditUsecase,
+	systemMessages SystemMessageUsecase,
+) (ScamUsecase, error) {
+	var rules models.ScamRules
+	if err := yaml.Unmarshal(scamPatternsData, &rules); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal scam patterns: %w", err)
+	}
+	for i, rule := range rules.Patterns {
+		if rule.Pattern == "" || len(rule.Keywords) == 0 {
+			return nil, fmt.Errorf("scam pattern %d needs a pattern and keywords", i)
+		}
+		for j, keyword := range rule.Keywords {
+			rules.Patterns[i].Keywords[j] = " " + foldKeywordText(keyword) + " "
+		}
+	}
+
+	return &scamUsecase{
+		conf:           conf.Scam,
+		allowedDomains: rules.AllowedDomains,
+		rules:          rules.Patterns,
+		scamFlagRepo:   scamFlagRepo,
+		chatAPIClient:  chatAPIClient,
+		auditUsecase:   auditUsecase,
+		systemMessages: systemMessages,
+	}, nil
+}
+
+func (uc *scamUsecase) Detect(message string) []models.ScamPattern {
+	var patterns []models.ScamPattern
+	if uc.hasExternalLink(message) {
+		patterns = append(patterns, models.ScamPatternExternalLink)
+	}
+
+	text := " " + foldKeywordText(message) + " "
+	for _, rule := range uc.rules {
+		for _, keyword := range rule.Keywords {
+			if strings.Contains(text, keyword) {
+				patterns = append(patterns, rule.Pattern)
+				break
+			}
+		}
+	}
+	return patterns
+}
+
+// hasExternalLink reports whether message links to a host outside the allowed domains
+func (uc *scamUsecase) hasExternalLink(message string) bool {
+	for _, match := range linkPattern.FindAllStringSubmatch(message, -1) {
+		host := strings.ToLower(strings.Trim(match[1]+match[2], "."))
+		if host != "" && !uc.isAllowedDomain(host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (uc *scamUsecase) isAllowedDomain(host string) bool {
+	for _, domain := range uc.allowedDomains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+func (uc *scamUsecase) Check(ctx context.Context, message models.IncomingMessage, sellerID string) (*models.ScamFlag, error) {
+	patterns := uc.Detect(message.Message)
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+	livestats.Inc(models.StatScamFlags)
+
+	flag := &models.ScamFlag{
+		ChannelID: message.ChannelID,
+		SellerID:  sellerID,
+		SenderID:  message.SenderID,
+		Message:   message.Message,
+		Patterns:  patterns,
+	}
+
+	// one notice per review, so a scammer repeating themselves does not
+	// flood the channel with warnings
+	pending, err := uc.scamFlagRepo.HasPending(ctx, message.ChannelID)
+	if err != nil {
+		return nil, err
+	}
+	if uc.conf.Notify && !pending {
+		if err := uc.notify(ctx, message.ChannelID, sellerID, message.Metadata.Language); err != nil {
+			log.Errorw(ctx, "Failed to post scam safety notice", "channel_id", message.ChannelID, "error", err)
+		} else {
+			flag.Notified = true
+		}
+	}
+
+	if err := uc.scamFlagRepo.Create(ctx, flag); err != nil {
+		return nil, err
+	}
+	log.Warnw(ctx, "Flagged buyer message as a likely scam",
+		"channel_id", message.ChannelID, "sender_id", message.SenderID, "patterns", patterns, "notified", flag.Notified)
+	return flag, nil
+}
+
+// notify posts the safety notice to the channel as the seller, the bot's
+// identity, so both parties see it
+func (uc *scamUsecase) notify(ctx context.Context, channelID, sellerID, language string) error {
+	return uc.chatAPIClient.SendMessage(ctx, &models.OutgoingMessage{
+		ChannelID: channelID,
+		SenderID:  sellerID,
+		Message:   uc.systemMessages.ForChannel(ctx, models.SystemMessageScamNotice, channelID, language),
+	})
+}
+
+func (uc *scamUsecase) ListFlags(ctx context.Context, filter models.ScamFlagFilter) ([]*models.ScamFlag, error) {
+	return uc.scamFlagRepo.List(ctx, filter)
+}
+
+func (uc *scamUsecase) Review(ctx context.Context, id primitive.ObjectID, status models.ScamFlagStatus) (*models.ScamFlag, error) {
+	before, err := uc.scamFlagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if before.Status != models.ScamFlagStatusPending {
+		return nil, models.ErrScamFlagReviewed
+	}
+
+	if err := uc.scamFlagRepo.Review(ctx, id, status); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, models.ErrScamFlagReviewed
+		}
+		return nil, err
+	}
+
+	after, err := uc.scamFlagRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// the admin request carries no tenant, record the entry under the flag's
+	if after.TenantID != nil {
+		ctx = models.WithTenantID(ctx, *after.TenantID)
+	}
+	uc.auditUsecase.Record(ctx, models.AuditScamFlagReview, "scam_flag", id.Hex(), before, after)
+	return after, nil
+}
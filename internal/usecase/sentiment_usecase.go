@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed sentiment_lexicon.yaml
+var sentimentLexiconData []byte
+
+// SentimentUsecase scores buyer messages with a word lexicon, tracks the
+// sentiment of each channel and alerts when a buyer turns very negative. An
+// alerted channel is handed off to the seller when SENTIMENT_HAND_OFF is set.
+type SentimentUsecase interface {
+	// Score rates a message without storing anything
+	Score(message string) models.SentimentScore
+	// Track adds the score of a buyer message to its channel and raises the
+	// alert once the channel's score drops to the threshold
+	Track(ctx context.Context, channelID, sellerID, buyerID string, score models.SentimentScore) (*models.ChannelSentiment, error)
+	// IsHandedOff reports whether the bot must leave the channel to the seller
+	IsHandedOff(sentiment *models.ChannelSentiment) bool
+	// GetChannelSentiment returns models.ErrNotFound when no message of the
+	// channel was scored
+	GetChannelSentiment(ctx context.Context, channelID string) (*models.ChannelSentiment, error)
+	ListAlerts(ctx context.Context, sellerID string) ([]*models.ChannelSentiment, error)
+	// Resume clears the channel's alert, handing it back to the bot
+	Resume(ctx context.Context, channelID string) error
+}
+
+type sentimentUsecase struct {
+	conf          config.SentimentConfig
+	positiveWords []string
+	negativeWords []string
+	sentimentRepo mongodb.ChannelSentimentRepository
+	auditUsecase  AuditUsecase
+	feedUsecase   ActivityFeedUsecase
+}
+
+func NewSentimentUsecase(
+	conf *config.Config,
+	sentimentRepo mongodb.ChannelSentimentRepository,
+	auditUsecase AuditUsecase,
+	feedUsecase ActivityFeedUsecase,
+) (SentimentUsecase, error) {
+	var lexicon models.SentimentLexicon
+	if err := yaml.Unmarshal(sentimentLexiconData, &lexicon); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal sentiment lexicon: %w", err)
+	}
+
+	fold := func(words []string) []string {
+		folded := make([]string, 0, len(words))
+		for _, word := range words {
+			folded = append(folded, " "+foldKeywordText(word)+" ")
+		}
+		return folded
+	}
+	return &sentimentUsecase{
+		conf:          conf.Sentiment,
+		positiveWords: fold(lexicon.Positive),
+		negativeWords: fold(lexicon.Negative),
+		sentimentRepo: sentimentRepo,
+		auditUsecase:  auditUsecase,
+		feedUsecase:   feedUsecase,
+	}, nil
+}
+
+func (uc *sentimentUsecase) Score(message string) models.SentimentScore {
+	text := " " + foldKeywordText(message) + " "
+	count := func(words []string) int {
+		n := 0
+		for _, word := range words {
+			n += strings.Count(text, word)
+		}
+		return n
+	}
+	positive, negative := count(uc.positiveWords), count(uc.negativeWords)
+
+	score := models.SentimentScore{Sentiment: models.SentimentNeutral, At: time.Now()}
+	if positive+negative == 0 {
+		return score
+	}
+	score.Score = float64(positive-negative) / float64(positive+negative)
+	switch {
+	case score.Score > 0:
+		score.Sentiment = models.SentimentPositive
+	case score.Score < 0:
+		score.Sentiment = models.SentimentNegative
+	}
+	return score
+}
+
+func (uc *sentimentUsecase) Track(ctx context.Context, channelID, sellerID, buyerID string, score models.SentimentScore) (*models.ChannelSentiment, error) {
+	livestats.Inc(models.StatSentimentPrefix + string(score.Sentiment))
+
+	sentiment, err := uc.sentimentRepo.Record(ctx, channelID, sellerID, buyerID, score, uc.conf.TrendSize)
+	if err != nil {
+		return nil, err
+	}
+	if sentiment.AlertedAt != nil || sentiment.Messages < uc.conf.AlertMinMessages || sentiment.Score > uc.conf.AlertThreshold {
+		return sentiment, nil
+	}
+
+	alerted, err := uc.sentimentRepo.MarkAlerted(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if alerted {
+		now := time.Now()
+		sentiment.AlertedAt = &now
+		livestats.Inc(models.StatSentimentAlerts)
+		log.Warnw(ctx, "Buyer sentiment dropped below the alert threshold",
+			"channel_id", channelID, "seller_id", sellerID, "score", sentiment.Score, "hand_off", uc.conf.HandOff)
+		if uc.conf.HandOff {
+			uc.feedUsecase.Publish(ctx, &models.FeedItem{
+				SellerID:  sellerID,
+				Type:      models.FeedHandoff,
+				ChannelID: channelID,
+				BuyerID:   buyerID,
+				Summary:   "The bot stopped answering after negative buyer messages, the chat is yours",
+				Data:      map[string]any{"score": sentiment.Score},
+			})
+		}
+	}
+	return sentiment, nil
+}
+
+func (uc *sentimentUsecase) IsHandedOff(sentiment *models.ChannelSentiment) bool {
+	return uc.conf.HandOff && sentiment != nil && sentiment.AlertedAt != nil
+}
+
+func (uc *sentimentUsecase) GetChannelSentiment(ctx context.Context, channelID string) (*models.ChannelSentiment, error) {
+	sentiment, err := uc.sentimentRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	if sentiment == nil {
+		return nil, models.ErrNotFound
+	}
+	return sentiment, nil
+}
+
+func (uc *sentimentUsecase) ListAlerts(ctx context.Context, sellerID string) ([]*models.ChannelSentiment, error) {
+	return uc.sentimentRepo.ListAlertedBySeller(ctx, sellerID)
+}
+
+func (uc *sentimentUsecase) Resume(ctx context.Context, channelID string) error {
+	before, err := uc.sentimentRepo.Get(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if before == nil || before.AlertedAt == nil {
+		return models.ErrNotFound
+	}
+
+	if err := uc.sentimentRepo.ClearAlert(ctx, channelID); err != nil {
+		return err
+	}
+
+	after, err := uc.sentimentRepo.Get(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditChannelSentimentResume, "channel_sentiment", before.ID.Hex(), before, after)
+	return nil
+}
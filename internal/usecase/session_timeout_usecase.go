@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.uber.org/fx"
+)
+
+// SessionTimeoutUsecase ends the sessions that stayed inactive longer than
+// the session timeout of their chat mode
+type SessionTimeoutUsecase interface {
+	// EndInactive ends the timed out sessions and returns how many it ended.
+	// The chat mode's farewell is sent to the channels whose latest session
+	// timed out, so a buyer who wrote again is not interrupted.
+	EndInactive(ctx context.Context) (int, error)
+}
+
+type sessionTimeoutUsecase struct {
+	chatModeRepo  mongodb.ChatModeRepository
+	sessionRepo   mongodb.ChatSessionRepository
+	activityRepo  mongodb.ChatActivityRepository
+	chatAPIClient chatapi.Client
+	batchSize     int
+}
+
+func NewSessionTimeoutUsecase(
+	chatModeRepo mongodb.ChatModeRepository,
+	sessionRepo mongodb.ChatSessionRepository,
+	activityRepo mongodb.ChatActivityRepository,
+	chatAPIClient chatapi.Client,
+	conf *config.Config,
+) SessionTimeoutUsecase {
+	return &sessionTimeoutUsecase{
+		chatModeRepo:  chatModeRepo,
+		sessionRepo:   sessionRepo,
+		activityRepo:  activityRepo,
+		chatAPIClient: chatAPIClient,
+		batchSize:     conf.SessionTimeout.BatchSize,
+	}
+}
+
+func (uc *sessionTimeoutUsecase) EndInactive(ctx context.Context) (int, error) {
+	modes, err := uc.chatModeRepo.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// Tenants may customise a chat mode, so sessions are looked up with the
+	// shortest timeout of each name and checked against their tenant's mode
+	shortest := make(map[string]time.Duration)
+	for _, mode := range modes {
+		timeout := mode.SessionTimeout.Inactivity()
+		if timeout > 0 && (shortest[mode.Name] == 0 || timeout < shortest[mode.Name]) {
+			shortest[mode.Name] = timeout
+		}
+	}
+
+	now := time.Now()
+	ended := 0
+	for name, timeout := range shortest {
+		sessions, err := uc.sessionRepo.ListInactive(ctx, name, now.Add(-timeout), uc.batchSize)
+		if err != nil {
+			return ended, err
+		}
+		for _, session := range sessions {
+			ok, err := uc.end(ctx, session, now)
+			if err != nil {
+				log.Warnw(ctx, "Failed to end inactive session", "session_id", session.ID.Hex(), "error", err)
+				continue
+			}
+			if ok {
+				ended++
+			}
+		}
+	}
+	return ended, nil
+}
+
+// end ends the session if it passed the timeout of its tenant's chat mode
+func (uc *sessionTimeoutUsecase) end(ctx context.Context, session *models.ChatSession, now time.Time) (bool, error) {
+	// the sweep runs without a tenant, the chat mode is the session tenant's
+	if session.TenantID != nil {
+		ctx = models.WithTenantID(ctx, *session.TenantID)
+	}
+
+	mode, err := uc.chatModeRepo.GetByName(ctx, session.ChatMode)
+	if err != nil {
+		return false, err
+	}
+	timeout := mode.SessionTimeout.Inactivity()
+	if timeout == 0 {
+		return false, nil
+	}
+	before := now.Add(-timeout)
+	if !session.UpdatedAt.Before(before) {
+		return false, nil
+	}
+
+	ended, err := uc.sessionRepo.EndInactive(ctx, session.ID, before)
+	if err != nil || !ended {
+		return false, err
+	}
+
+	farewell := mode.SessionTimeout.Farewell
+	if farewell != "" {
+		farewell, err = uc.sendFarewell(ctx, session, farewell)
+		if err != nil {
+			log.Warnw(ctx, "Failed to send farewell message", "session_id", session.ID.Hex(), "channel_id", session.ChannelID, "error", err)
+		}
+	}
+
+	activity := &models.ChatActivity{
+		SessionID: session.ID,
+		ChannelID: session.ChannelID,
+		Action:    models.ActivityEndSession,
+		Data: map[string]any{
+			"reason":   models.SessionEndedInactive,
+			"farewell": farewell,
+		},
+	}
+	if err := uc.activityRepo.Create(ctx, activity); err != nil {
+		log.Errorf(ctx, "Failed to log session timeout activity: %v", err)
+	}
+
+	log.Infow(ctx, "Ended inactive session", "session_id", session.ID.Hex(), "channel_id", session.ChannelID, "chat_mode", session.ChatMode, "inactive_for", now.Sub(session.UpdatedAt))
+	return true, nil
+}
+
+// sendFarewell sends the farewell as the seller when the session is still the
+// latest of its channel, and returns the message it sent
+func (uc *sessionTimeoutUsecase) sendFarewell(ctx context.Context, session *models.ChatSession, farewell string) (string, error) {
+	if session.SellerID == "" {
+		return "", nil
+	}
+
+	latest, err := uc.sessionRepo.GetLatestByChannel(ctx, session.ChannelID)
+	if err != nil {
+		return "", err
+	}
+	if latest == nil || latest.ID != session.ID {
+		return "", nil
+	}
+
+	err = uc.chatAPIClient.SendMessage(ctx, &models.OutgoingMessage{
+		ChannelID: session.ChannelID,
+		SenderID:  session.SellerID,
+		Message:   farewell,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+	return farewell, nil
+}
+
+// StartSessionTimeouts runs EndInactive every SESSION_TIMEOUT_INTERVAL while the app is running
+func StartSessionTimeouts(lc fx.Lifecycle, uc SessionTimeoutUsecase, conf *config.Config) {
+	if conf.SessionTimeout.Interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(models.WithAllTenants(context.Background()))
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(_ context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(conf.SessionTimeout.Interval)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+
+					ended, err := uc.EndInactive(ctx)
+					if err != nil {
+						log.Errorw(ctx, "Failed to end inactive sessions", "error", err)
+						continue
+					}
+					if ended > 0 {
+						log.Infow(ctx, "Ended inactive sessions", "ended", ended)
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(_ context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
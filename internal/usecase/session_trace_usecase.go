@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// promptHashLength is the number of hex characters of the prompt hash kept
+const promptHashLength = 16
+
+type SessionTraceUsecase interface {
+	ListBySession(ctx context.Context, sessionID primitive.ObjectID) ([]*models.SessionTrace, error)
+}
+
+type sessionTraceUsecase struct {
+	sessionTraceRepo mongodb.SessionTraceRepository
+}
+
+func NewSessionTraceUsecase(sessionTraceRepo mongodb.SessionTraceRepository) SessionTraceUsecase {
+	return &sessionTraceUsecase{
+		sessionTraceRepo: sessionTraceRepo,
+	}
+}
+
+func (uc *sessionTraceUsecase) ListBySession(ctx context.Context, sessionID primitive.ObjectID) ([]*models.SessionTrace, error) {
+	return uc.sessionTraceRepo.ListBySession(ctx, sessionID)
+}
+
+// sessionTracer stores a summary of every generation of a session, up to
+// MaxPerSession of them. A nil tracer records nothing.
+type sessionTracer struct {
+	repo      mongodb.SessionTraceRepository
+	sessionID primitive.ObjectID
+	chatMode  string
+	model     string
+	retention time.Duration
+	limit     int
+	// recorded is the number of traces the session has, loaded on the first
+	// record since a session can span several messages
+	recorded int
+	loaded   bool
+}
+
+func newSessionTracer(conf config.SessionTraceConfig, repo mongodb.SessionTraceRepository, chatMode *models.ChatMode, data *PromptData) *sessionTracer {
+	if !conf.Enabled {
+		return nil
+	}
+	// the session ID was validated before the tracer is created
+	sessionID, _ := primitive.ObjectIDFromHex(data.SessionID)
+	return &sessionTracer{
+		repo:      repo,
+		sessionID: sessionID,
+		chatMode:  chatMode.Name,
+		model:     chatMode.Model,
+		retention: conf.Retention,
+		limit:     conf.MaxPerSession,
+	}
+}
+
+// record stores the trace of one generation. Failures to store are logged
+// and otherwise ignored.
+func (t *sessionTracer) record(ctx context.Context, iteration int, messages []*ai.Message, response *ai.ModelResponse, latency time.Duration, genErr error) {
+	if t == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	if !t.loaded {
+		count, err := t.repo.Count(ctx, t.sessionID)
+		if err != nil {
+			log.Errorw(ctx, "Failed to count session traces", "session_id", t.sessionID.Hex(), "error", err)
+			return
+		}
+		t.recorded = int(count)
+		t.loaded = true
+	}
+	if t.recorded >= t.limit {
+		return
+	}
+
+	trace := &models.SessionTrace{
+		SessionID:      t.sessionID,
+		ChatMode:       t.chatMode,
+		Model:          t.model,
+		Iteration:      iteration,
+		LatencyMs:      latency.Milliseconds(),
+		PromptHash:     hashPrompt(messages),
+		PromptMessages: len(messages),
+		ExpiresAt:      time.Now().Add(t.retention),
+	}
+	if genErr != nil {
+		trace.Error = redactPrompt(genErr.Error())
+	}
+	if response != nil {
+		trace.FinishReason = string(response.FinishReason)
+		for _, req := range response.ToolRequests() {
+			trace.ToolsRequested = append(trace.ToolsRequested, req.Name)
+		}
+		if response.Usage != nil {
+			trace.InputTokens = response.Usage.InputTokens
+			trace.OutputTokens = response.Usage.OutputTokens
+		}
+	}
+
+	if err := t.repo.Create(ctx, trace); err != nil {
+		log.Errorw(ctx, "Failed to save session trace", "session_id", t.sessionID.Hex(), "error", err)
+		return
+	}
+	t.recorded++
+}
+
+// hashPrompt returns a truncated SHA-256 of the roles and flattened text of
+// messages
+func hashPrompt(messages []*ai.Message) string {
+	h := sha256.New()
+	for _, msg := range messages {
+		h.Write([]byte(msg.Role))
+		h.Write([]byte{0})
+		h.Write([]byte(flattenMessage(msg)))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:promptHashLength]
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/textx"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The sandbox session of simulations is not a chat-api channel
+const (
+	simulatorChannelID = "chat-mode-simulator"
+	simulatorBuyerID   = "simulator-buyer"
+	simulatorSellerID  = "simulator-seller"
+)
+
+// SimulatorUsecase lets prompt authors converse with a chat mode directly
+type SimulatorUsecase interface {
+	Simulate(ctx context.Context, chatModeName string, req models.SimulationRequest) (*models.SimulationResult, error)
+}
+
+type simulatorUsecase struct {
+	chatModeRepo mongodb.ChatModeRepository
+	llmUsecase   LLMUsecase
+}
+
+func NewSimulatorUsecase(chatModeRepo mongodb.ChatModeRepository, llmUsecase LLMUsecase) SimulatorUsecase {
+	return &simulatorUsecase{
+		chatModeRepo: chatModeRepo,
+		llmUsecase:   llmUsecase,
+	}
+}
+
+func (uc *simulatorUsecase) Simulate(ctx context.Context, chatModeName string, req models.SimulationRequest) (*models.SimulationResult, error) {
+	chatMode, err := uc.chatModeRepo.GetByName(ctx, chatModeName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidSimulation, err)
+	}
+
+	last := req.Messages[len(req.Messages)-1]
+	senderID := simulatorBuyerID
+	if last.Role == "seller" {
+		senderID = simulatorSellerID
+	}
+
+	// recent messages come newest first, like chat-api returns them
+	history := &models.MessageHistory{Messages: []models.HistoryMessage{}}
+	now := time.Now()
+	for i := len(req.Messages) - 2; i >= 0; i-- {
+		msg := req.Messages[i]
+		msgSenderID := simulatorBuyerID
+		if msg.Role == "seller" {
+			msgSenderID = simulatorSellerID
+		}
+		history.Messages = append(history.Messages, models.HistoryMessage{
+			ID:        fmt.Sprintf("simulated-%d", i),
+			ChannelID: simulatorChannelID,
+			SenderID:  msgSenderID,
+			Message:   msg.Message,
+			CreatedAt: now.Add(time.Duration(i-len(req.Messages)) * time.Minute),
+		})
+	}
+
+	data := &PromptData{
+		ChannelInfo: &models.ChannelInfo{
+			ID:        simulatorChannelID,
+			Name:      "Chat mode simulator",
+			ItemName:  req.ItemName,
+			ItemPrice: req.ItemPrice,
+			Context:   req.Context,
+			Participants: []models.Participant{
+				{UserID: simulatorSellerID, Role: models.ParticipantRoleSeller},
+				{UserID: simulatorBuyerID, Role: models.ParticipantRoleBuyer},
+			},
+		},
+		SessionID:      primitive.NewObjectID().Hex(),
+		UserID:         senderID,
+		SenderRole:     last.Role,
+		Message:        last.Message,
+		RecentMessages: history,
+		BuyerLanguage:  simulatedBuyerLanguage(req.Messages),
+		ToolFixtures:   req.ToolFixtures,
+	}
+	return uc.llmUsecase.Simulate(ctx, chatMode, data)
+}
+
+// simulatedBuyerLanguage detects the language of the buyer's messages
+func simulatedBuyerLanguage(messages []models.SimulationMessage) string {
+	var texts []string
+	for _, msg := range messages {
+		if msg.Role != "seller" {
+			texts = append(texts, msg.Message)
+		}
+	}
+	return textx.DetectLanguage(texts...)
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SuggestionUsecase runs safe mode: in the configured chats the bot's replies
+// are stored as suggestions, and only sent once the seller approves them
+type SuggestionUsecase interface {
+	// RequiresApproval reports whether replies for the seller in the channel
+	// are held, from the SAFE_MODE_ config or the tenant's safe mode settings
+	RequiresApproval(ctx context.Context, sellerID, channelID string) (bool, error)
+	ListPending(ctx context.Context, sellerID string) ([]*models.ReplySuggestion, error)
+	// Approve sends the suggestion, or message instead when it is not empty
+	Approve(ctx context.Context, id primitive.ObjectID, message string) (*models.ReplySuggestion, error)
+	Reject(ctx context.Context, id primitive.ObjectID) (*models.ReplySuggestion, error)
+}
+
+type suggestionUsecase struct {
+	conf           config.SafeModeConfig
+	suggestionRepo mongodb.ReplySuggestionRepository
+	chatAPIClient  chatapi.Client
+	tenantUsecase  TenantUsecase
+	budgetUsecase  BudgetUsecase
+	auditUsecase   AuditUsecase
+}
+
+func NewSuggestionUsecase(
+	conf *config.Config,
+	suggestionRepo mongodb.ReplySuggestionRepository,
+	chatAPIClient chatapi.Client,
+	tenantUsecase TenantUsecase,
+	budgetUsecase BudgetUsecase,
+	auditUsecase AuditUsecase,
+) SuggestionUsecase {
+	return &suggestionUsecase{
+		conf:           conf.SafeMode,
+		suggestionRepo: suggestionRepo,
+		chatAPIClient:  chatAPIClient,
+		tenantUsecase:  tenantUsecase,
+		budgetUsecase:  budgetUsecase,
+		auditUsecase:   auditUsecase,
+	}
+}
+
+func (uc *suggestionUsecase) RequiresApproval(ctx context.Context, sellerID, channelID string) (bool, error) {
+	if slices.Contains(uc.conf.Sellers, sellerID) || slices.Contains(uc.conf.Channels, channelID) {
+		return true, nil
+	}
+
+	tenantID, ok := models.TenantIDFromContext(ctx)
+	if !ok {
+		return false, nil
+	}
+	tenant, err := uc.tenantUsecase.GetTenant(ctx, tenantID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant.Settings.SafeMode.Applies(sellerID, channelID), nil
+}
+
+func (uc *suggestionUsecase) ListPending(ctx context.Context, sellerID string) ([]*models.ReplySuggestion, error) {
+	suggestions, err := uc.suggestionRepo.ListPendingBySeller(ctx, sellerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list suggestions: %w", err)
+	}
+	return suggestions, nil
+}
+
+func (uc *suggestionUsecase) Approve(ctx context.Context, id primitive.ObjectID, message string) (*models.ReplySuggestion, error) {
+	before, err := uc.suggestionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		message = before.Message
+	}
+
+	// Claimed before sending, so a suggestion approved twice at once is sent once
+	if err := uc.decide(ctx, id, models.SuggestionStatusApproved, message); err != nil {
+		return nil, err
+	}
+
+	err = uc.chatAPIClient.SendMessage(ctx, &models.OutgoingMessage{
+		ChannelID: before.ChannelID,
+		SenderID:  before.SellerID,
+		Message:   message,
+	})
+	if err != nil {
+		if reopenErr := uc.suggestionRepo.Reopen(ctx, id); reopenErr != nil {
+			log.Errorw(ctx, "Failed to reopen suggestion after send failure", "suggestion_id", id.Hex(), "error", reopenErr)
+		}
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+	livestats.Inc(models.StatBotReplies)
+	if err := uc.budgetUsecase.RecordReply(ctx, before.ChannelID); err != nil {
+		log.Errorw(ctx, "Failed to count reply in channel budget", "channel_id", before.ChannelID, "error", err)
+	}
+
+	after, err := uc.suggestionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditSuggestionApprove, "reply_suggestion", id.Hex(), before, after)
+	log.Infow(ctx, "Suggestion approved and sent", "suggestion_id", id.Hex(), "channel_id", after.ChannelID, "edited", message != before.Message)
+	return after, nil
+}
+
+func (uc *suggestionUsecase) Reject(ctx context.Context, id primitive.ObjectID) (*models.ReplySuggestion, error) {
+	before, err := uc.suggestionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.decide(ctx, id, models.SuggestionStatusRejected, ""); err != nil {
+		return nil, err
+	}
+
+	after, err := uc.suggestionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditSuggestionReject, "reply_suggestion", id.Hex(), before, after)
+	return after, nil
+}
+
+// decide moves a pending suggestion, telling a decided one apart from a missing
+// one. The caller is recorded as the decider, so a team's agents are credited
+// with the replies they approved.
+func (uc *suggestionUsecase) decide(ctx context.Context, id primitive.ObjectID, status models.SuggestionStatus, sentMessage string) error {
+	err := uc.suggestionRepo.Decide(ctx, id, status, sentMessage, models.ActorFromContext(ctx))
+	if errors.Is(err, models.ErrNotFound) {
+		return models.ErrSuggestionDecided
+	}
+	return err
+}
@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"text/template"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/chatapi"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"gopkg.in/yaml.v3"
+)
+
+// systemMessageBundles holds a YAML bundle per locale, named after it
+//
+//go:embed system_messages/*.yaml
+var systemMessageBundles embed.FS
+
+// SystemMessageUsecase localizes the canned messages the bot sends to chats.
+// A message is the tenant's override for the locale, else the deployment's
+// env text, else the locale's bundle, in the buyer's language when it has a
+// bundle and SYSTEM_MESSAGES_DEFAULT_LOCALE otherwise.
+type SystemMessageUsecase interface {
+	// ForChannel returns the message to send to the channel. An empty locale
+	// takes the language of the channel's latest session.
+	ForChannel(ctx context.Context, key models.SystemMessageKey, channelID, locale string) string
+	// List returns every message in every locale, with the tenant's overrides
+	List(ctx context.Context) ([]models.SystemMessage, error)
+	SetOverride(ctx context.Context, key models.SystemMessageKey, locale, text string) (*models.SystemMessageOverride, error)
+	DeleteOverride(ctx context.Context, key models.SystemMessageKey, locale string) error
+}
+
+type systemMessageUsecase struct {
+	bundles       map[string]map[models.SystemMessageKey]string
+	envTexts      map[models.SystemMessageKey]string
+	defaultLocale string
+	messageRepo   mongodb.SystemMessageRepository
+	sessionRepo   mongodb.ChatSessionRepository
+	chatAPIClient chatapi.Client
+	auditUsecase  AuditUsecase
+}
+
+func NewSystemMessageUsecase(
+	conf *config.Config,
+	messageRepo mongodb.SystemMessageRepository,
+	sessionRepo mongodb.ChatSessionRepository,
+	chatAPIClient chatapi.Client,
+	auditUsecase AuditUsecase,
+) (SystemMessageUsecase, error) {
+	bundles, err := loadSystemMessageBundles()
+	if err != nil {
+		return nil, err
+	}
+	defaultLocale := conf.SystemMessages.DefaultLocale
+	if _, ok := bundles[defaultLocale]; !ok {
+		return nil, fmt.Errorf("SYSTEM_MESSAGES_DEFAULT_LOCALE %q has no bundle", defaultLocale)
+	}
+
+	envTexts := make(map[models.SystemMessageKey]string)
+	for key, text := range map[models.SystemMessageKey]string{
+		models.SystemMessageAcknowledge:  conf.LLMFallback.AcknowledgeMessage,
+		models.SystemMessageBudgetPaused: conf.Budget.Notice,
+		models.SystemMessageScamNotice:   conf.Scam.Notice,
+	} {
+		if text != "" {
+			envTexts[key] = text
+		}
+	}
+
+	return &systemMessageUsecase{
+		bundles:       bundles,
+		envTexts:      envTexts,
+		defaultLocale: defaultLocale,
+		messageRepo:   messageRepo,
+		sessionRepo:   sessionRepo,
+		chatAPIClient: chatAPIClient,
+		auditUsecase:  auditUsecase,
+	}, nil
+}
+
+// loadSystemMessageBundles parses the embedded bundles. Every bundle must have
+// the messages of the others and parse as templates.
+func loadSystemMessageBundles() (map[string]map[models.SystemMessageKey]string, error) {
+	files, err := systemMessageBundles.ReadDir("system_messages")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read system message bundles: %w", err)
+	}
+
+	bundles := make(map[string]map[models.SystemMessageKey]string)
+	keys := make(map[models.SystemMessageKey]bool)
+	for _, file := range files {
+		data, err := systemMessageBundles.ReadFile(path.Join("system_messages", file.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read system message bundle %s: %w", file.Name(), err)
+		}
+		var bundle map[models.SystemMessageKey]string
+		if err := yaml.Unmarshal(data, &bundle); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal system message bundle %s: %w", file.Name(), err)
+		}
+		for key, text := range bundle {
+			if _, err := template.New(string(key)).Parse(text); err != nil {
+				return nil, fmt.Errorf("system message %s in %s: %w", key, file.Name(), err)
+			}
+			keys[key] = true
+		}
+		bundles[strings.TrimSuffix(file.Name(), path.Ext(file.Name()))] = bundle
+	}
+
+	for locale, bundle := range bundles {
+		for key := range keys {
+			if bundle[key] == "" {
+				return nil, fmt.Errorf("system message %s is missing in the %s bundle", key, locale)
+			}
+		}
+	}
+	return bundles, nil
+}
+
+func (uc *systemMessageUsecase) ForChannel(ctx context.Context, key models.SystemMessageKey, channelID, locale string) string {
+	if locale == "" {
+		session, err := uc.sessionRepo.GetLatestByChannel(ctx, channelID)
+		if err != nil {
+			log.Warnw(ctx, "Failed to get the channel's language", "channel_id", channelID, "error", err)
+		} else if session != nil {
+			locale = session.Language
+		}
+	}
+	if _, ok := uc.bundles[locale]; !ok {
+		locale = uc.defaultLocale
+	}
+
+	text, _ := uc.text(ctx, key, locale)
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+
+	params := map[string]string{}
+	channelInfo, err := uc.chatAPIClient.GetChannelInfo(ctx, channelID)
+	if err != nil {
+		log.Warnw(ctx, "Failed to get channel info for system message params", "channel_id", channelID, "error", err)
+	} else {
+		params["item_name"] = channelInfo.ItemName
+		params["item_price"] = channelInfo.ItemPrice
+	}
+	rendered, err := renderSystemMessage(text, params)
+	if err != nil {
+		// overrides are checked when stored, so this is a bundle or env text
+		log.Errorw(ctx, "Failed to render system message", "key", key, "locale", locale, "error", err)
+		return text
+	}
+	return rendered
+}
+
+// text returns the message in the locale and whether it is the tenant's
+// override. Failures to read the override fall back to the default text.
+func (uc *systemMessageUsecase) text(ctx context.Context, key models.SystemMessageKey, locale string) (string, bool) {
+	override, err := uc.messageRepo.Get(ctx, key, locale)
+	if err != nil {
+		log.Warnw(ctx, "Failed to get system message override", "key", key, "locale", locale, "error", err)
+	} else if override != nil {
+		return override.Text, true
+	}
+
+	if text, ok := uc.envTexts[key]; ok {
+		return text, false
+	}
+	return uc.bundles[locale][key], false
+}
+
+func renderSystemMessage(text string, params map[string]string) (string, error) {
+	tmpl, err := template.New("system_message").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func (uc *systemMessageUsecase) List(ctx context.Context) ([]models.SystemMessage, error) {
+	overrides, err := uc.messageRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overridden := make(map[string]string, len(overrides))
+	for _, override := range overrides {
+		overridden[override.Locale+"/"+string(override.Key)] = override.Text
+	}
+
+	var messages []models.SystemMessage
+	for _, locale := range uc.locales() {
+		keys := make([]models.SystemMessageKey, 0, len(uc.bundles[locale]))
+		for key := range uc.bundles[locale] {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+
+		for _, key := range keys {
+			message := models.SystemMessage{Key: key, Locale: locale, Text: uc.bundles[locale][key]}
+			if text, ok := uc.envTexts[key]; ok {
+				message.Text = text
+			}
+			if text, ok := overridden[locale+"/"+string(key)]; ok {
+				message.Text = text
+				message.Overridden = true
+			}
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (uc *systemMessageUsecase) locales() []string {
+	locales := make([]string, 0, len(uc.bundles))
+	for locale := range uc.bundles {
+		locales = append(locales, locale)
+	}
+	slices.Sort(locales)
+	return locales
+}
+
+func (uc *systemMessageUsecase) SetOverride(ctx context.Context, key models.SystemMessageKey, locale, text string) (*models.SystemMessageOverride, error) {
+	if _, ok := uc.bundles[locale][key]; !ok {
+		return nil, models.ErrUnknownSystemMessage
+	}
+	text = strings.TrimSpace(text)
+	if _, err := renderSystemMessage(text, map[string]string{}); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidSystemMessage, err)
+	}
+
+	before, err := uc.messageRepo.Get(ctx, key, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	override := &models.SystemMessageOverride{Key: key, Locale: locale, Text: text}
+	if err := uc.messageRepo.Upsert(ctx, override); err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditSystemMessageSet, "system_message", override.ID.Hex(), before, override)
+	return override, nil
+}
+
+func (uc *systemMessageUsecase) DeleteOverride(ctx context.Context, key models.SystemMessageKey, locale string) error {
+	before, err := uc.messageRepo.Get(ctx, key, locale)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.ErrNotFound
+	}
+
+	if err := uc.messageRepo.Delete(ctx, key, locale); err != nil {
+		return err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditSystemMessageDelete, "system_message", before.ID.Hex(), before, nil)
+	return nil
+}
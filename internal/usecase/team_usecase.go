@@ -0,0 +1,231 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TeamUsecase lets several agents share one seller's inbox: the seller's team
+// lists the agents, and each of the seller's channels can be assigned to one
+// of them
+type TeamUsecase interface {
+	// CreateTeam creates the seller's team, with the calling user as its owner
+	CreateTeam(ctx context.Context, sellerID, name string) (*models.Team, error)
+	GetTeam(ctx context.Context, id primitive.ObjectID) (*models.Team, error)
+	// AddMember adds an existing user to the team, or changes their role
+	AddMember(ctx context.Context, id, userID primitive.ObjectID, role models.TeamRole) (*models.Team, error)
+	// RemoveMember removes the user from the team and unassigns their channels
+	RemoveMember(ctx context.Context, id, userID primitive.ObjectID) (*models.Team, error)
+	// AssignChannel gives the channel to a member of the team
+	AssignChannel(ctx context.Context, id primitive.ObjectID, channelID string, agentID primitive.ObjectID) (*models.ChannelAssignment, error)
+	UnassignChannel(ctx context.Context, id primitive.ObjectID, channelID string) error
+	// ListAssignments returns the team's assignments, only the agent's when
+	// agentID is set
+	ListAssignments(ctx context.Context, id primitive.ObjectID, agentID *primitive.ObjectID) ([]*models.ChannelAssignment, error)
+}
+
+type teamUsecase struct {
+	teamRepo       mongodb.TeamRepository
+	assignmentRepo mongodb.ChannelAssignmentRepository
+	userUsecase    UserUsecase
+	feedUsecase    ActivityFeedUsecase
+	auditUsecase   AuditUsecase
+}
+
+func NewTeamUsecase(
+	teamRepo mongodb.TeamRepository,
+	assignmentRepo mongodb.ChannelAssignmentRepository,
+	userUsecase UserUsecase,
+	feedUsecase ActivityFeedUsecase,
+	auditUsecase AuditUsecase,
+) TeamUsecase {
+	return &teamUsecase{
+		teamRepo:       teamRepo,
+		assignmentRepo: assignmentRepo,
+		userUsecase:    userUsecase,
+		feedUsecase:    feedUsecase,
+		auditUsecase:   auditUsecase,
+	}
+}
+
+func (uc *teamUsecase) CreateTeam(ctx context.Context, sellerID, name string) (*models.Team, error) {
+	team := &models.Team{
+		SellerID: sellerID,
+		Name:     name,
+		Members:  []models.TeamMember{},
+	}
+
+	// teams created with an API key or the admin token start without an owner
+	actor := models.ActorFromContext(ctx)
+	if actor.Type == models.ActorTypeUser {
+		if userID, err := primitive.ObjectIDFromHex(actor.ID); err == nil {
+			team.Members = append(team.Members, models.TeamMember{UserID: userID, Role: models.TeamRoleOwner})
+		}
+	}
+
+	if err := uc.teamRepo.Create(ctx, team); err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditTeamCreate, "team", team.ID.Hex(), nil, team)
+	return team, nil
+}
+
+func (uc *teamUsecase) GetTeam(ctx context.Context, id primitive.ObjectID) (*models.Team, error) {
+	return uc.teamRepo.GetByID(ctx, id)
+}
+
+func (uc *teamUsecase) AddMember(ctx context.Context, id, userID primitive.ObjectID, role models.TeamRole) (*models.Team, error) {
+	before, err := uc.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.TeamRoleOwner && isLastOwner(before, userID) {
+		return nil, models.ErrLastTeamOwner
+	}
+	if _, err := uc.userUsecase.GetUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if err := uc.teamRepo.AddMember(ctx, id, models.TeamMember{UserID: userID, Role: role}); err != nil {
+		return nil, err
+	}
+
+	after, err := uc.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditTeamAddMember, "team", id.Hex(), before, after)
+	return after, nil
+}
+
+func (uc *teamUsecase) RemoveMember(ctx context.Context, id, userID primitive.ObjectID) (*models.Team, error) {
+	before, err := uc.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if isLastOwner(before, userID) {
+		return nil, models.ErrLastTeamOwner
+	}
+
+	if err := uc.teamRepo.RemoveMember(ctx, id, userID); err != nil {
+		return nil, err
+	}
+
+	assignments, err := uc.assignmentRepo.List(ctx, id, &userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the member's channels: %w", err)
+	}
+	for _, assignment := range assignments {
+		if err := uc.UnassignChannel(ctx, id, assignment.ChannelID); err != nil {
+			return nil, err
+		}
+	}
+
+	after, err := uc.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditTeamRemoveMember, "team", id.Hex(), before, after)
+	return after, nil
+}
+
+// isLastOwner reports whether the user is the only owner of the team
+func isLastOwner(team *models.Team, userID primitive.ObjectID) bool {
+	member := team.Member(userID)
+	if member == nil || member.Role != models.TeamRoleOwner {
+		return false
+	}
+	for _, other := range team.Members {
+		if other.UserID != userID && other.Role == models.TeamRoleOwner {
+			return false
+		}
+	}
+	return true
+}
+
+func (uc *teamUsecase) AssignChannel(ctx context.Context, id primitive.ObjectID, channelID string, agentID primitive.ObjectID) (*models.ChannelAssignment, error) {
+	team, err := uc.teamRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if team.Member(agentID) == nil {
+		return nil, models.ErrNotTeamMember
+	}
+
+	assignment := &models.ChannelAssignment{
+		TeamID:     team.ID,
+		SellerID:   team.SellerID,
+		ChannelID:  channelID,
+		AgentID:    agentID,
+		AssignedBy: models.ActorFromContext(ctx),
+	}
+	previous, err := uc.assignmentRepo.Assign(ctx, assignment)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := uc.assignmentRepo.Get(ctx, channelID)
+	if err != nil {
+		return nil, err
+	}
+	// reassigning the same agent changes nothing worth reporting
+	if previous == nil || previous.AgentID != agentID {
+		uc.auditUsecase.Record(ctx, models.AuditChannelAssign, "channel", channelID, previous, after)
+		uc.publishAssignment(ctx, team.SellerID, channelID, previous, &agentID)
+	}
+	return after, nil
+}
+
+func (uc *teamUsecase) UnassignChannel(ctx context.Context, id primitive.ObjectID, channelID string) error {
+	current, err := uc.assignmentRepo.Get(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if current == nil || current.TeamID != id {
+		return models.ErrNotFound
+	}
+
+	previous, err := uc.assignmentRepo.Unassign(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if previous == nil {
+		return nil
+	}
+	uc.auditUsecase.Record(ctx, models.AuditChannelUnassign, "channel", channelID, previous, nil)
+	uc.publishAssignment(ctx, previous.SellerID, channelID, previous, nil)
+	return nil
+}
+
+func (uc *teamUsecase) ListAssignments(ctx context.Context, id primitive.ObjectID, agentID *primitive.ObjectID) ([]*models.ChannelAssignment, error) {
+	if _, err := uc.teamRepo.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return uc.assignmentRepo.List(ctx, id, agentID)
+}
+
+// publishAssignment adds the assignment change to the seller's feed. agentID
+// is nil when the channel was unassigned.
+func (uc *teamUsecase) publishAssignment(ctx context.Context, sellerID, channelID string, previous *models.ChannelAssignment, agentID *primitive.ObjectID) {
+	data := map[string]any{"assigned_by": models.ActorFromContext(ctx)}
+	summary := "The chat was unassigned"
+	if agentID != nil {
+		data["agent_id"] = agentID.Hex()
+		summary = "The chat was assigned to another agent"
+	}
+	if previous != nil {
+		data["previous_agent_id"] = previous.AgentID.Hex()
+	}
+
+	uc.feedUsecase.Publish(ctx, &models.FeedItem{
+		SellerID:  sellerID,
+		Type:      models.FeedAssignmentChanged,
+		ChannelID: channelID,
+		Summary:   summary,
+		Data:      data,
+	})
+}
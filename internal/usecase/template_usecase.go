@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// TemplateUsecase keeps the catalog of partner message templates, sent in
+// place of free-form messages outside a partner's reply window
+type TemplateUsecase interface {
+	// SetTemplate stores the template, taking its params from the body
+	SetTemplate(ctx context.Context, template *models.MessageTemplate) (*models.MessageTemplate, error)
+	ListTemplates(ctx context.Context, partner string) ([]*models.MessageTemplate, error)
+	DeleteTemplate(ctx context.Context, partner, name string) error
+}
+
+type templateUsecase struct {
+	templateRepo mongodb.MessageTemplateRepository
+	auditUsecase AuditUsecase
+}
+
+func NewTemplateUsecase(templateRepo mongodb.MessageTemplateRepository, auditUsecase AuditUsecase) TemplateUsecase {
+	return &templateUsecase{
+		templateRepo: templateRepo,
+		auditUsecase: auditUsecase,
+	}
+}
+
+func (uc *templateUsecase) SetTemplate(ctx context.Context, template *models.MessageTemplate) (*models.MessageTemplate, error) {
+	template.Body = strings.TrimSpace(template.Body)
+	template.Params = models.TemplateParams(template.Body)
+	for _, param := range template.Params {
+		if !slices.Contains(models.TemplateParamNames, param) {
+			return nil, fmt.Errorf("%w: %s, use %s", models.ErrTemplateParams, param, strings.Join(models.TemplateParamNames, ", "))
+		}
+	}
+
+	before, err := uc.templateRepo.Get(ctx, template.Partner, template.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.templateRepo.Upsert(ctx, template); err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditTemplateSet, "message_template", template.ID.Hex(), before, template)
+	return template, nil
+}
+
+func (uc *templateUsecase) ListTemplates(ctx context.Context, partner string) ([]*models.MessageTemplate, error) {
+	return uc.templateRepo.List(ctx, partner)
+}
+
+func (uc *templateUsecase) DeleteTemplate(ctx context.Context, partner, name string) error {
+	before, err := uc.templateRepo.Get(ctx, partner, name)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.ErrNotFound
+	}
+
+	if err := uc.templateRepo.Delete(ctx, partner, name); err != nil {
+		return err
+	}
+	uc.auditUsecase.Record(ctx, models.AuditTemplateDelete, "message_template", before.ID.Hex(), before, nil)
+	return nil
+}
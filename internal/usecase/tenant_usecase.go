@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/toolsmanager"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const apiKeyPrefix = "cbk_"
+
+type TenantUsecase interface {
+	CreateTenant(ctx context.Context, name string, settings models.TenantSettings) (*models.Tenant, error)
+	GetTenant(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error)
+	UpdateTenantSettings(ctx context.Context, id primitive.ObjectID, settings models.TenantSettings) (*models.Tenant, error)
+	// UpdateToolPolicy replaces the tenant's tool allowlist, returning
+	// models.ErrUnknownTool when it names a tool that is not registered
+	UpdateToolPolicy(ctx context.Context, id primitive.ObjectID, policy models.ToolPolicy) (*models.Tenant, error)
+	// AllowedTools returns the tools the seller may use in the tenant of ctx,
+	// nil when every tool is allowed
+	AllowedTools(ctx context.Context, sellerID string) ([]string, error)
+
+	// CreateAPIKey returns the stored key along with its plaintext value, which is never persisted
+	CreateAPIKey(ctx context.Context, tenantID primitive.ObjectID, name string) (*models.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, tenantID, keyID primitive.ObjectID) error
+	ResolveAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error)
+
+	// CheckSessionQuota returns models.ErrQuotaExceeded when the tenant of ctx
+	// has used up its daily session allowance
+	CheckSessionQuota(ctx context.Context) error
+}
+
+type tenantUsecase struct {
+	tenantRepo   mongodb.TenantRepository
+	apiKeyRepo   mongodb.APIKeyRepository
+	sessionRepo  mongodb.ChatSessionRepository
+	toolsManager toolsmanager.ToolsManager
+	auditUsecase AuditUsecase
+}
+
+func NewTenantUsecase(
+	tenantRepo mongodb.TenantRepository,
+	apiKeyRepo mongodb.APIKeyRepository,
+	sessionRepo mongodb.ChatSessionRepository,
+	toolsManager toolsmanager.ToolsManager,
+	auditUsecase AuditUsecase,
+) TenantUsecase {
+	return &tenantUsecase{
+		tenantRepo:   tenantRepo,
+		apiKeyRepo:   apiKeyRepo,
+		sessionRepo:  sessionRepo,
+		toolsManager: toolsManager,
+		auditUsecase: auditUsecase,
+	}
+}
+
+func (uc *tenantUsecase) CreateTenant(ctx context.Context, name string, settings models.TenantSettings) (*models.Tenant, error) {
+	tenant := &models.Tenant{
+		Name:     name,
+		Settings: settings,
+	}
+	if err := uc.tenantRepo.Create(ctx, tenant); err != nil {
+		return nil, fmt.Errorf("failed to create tenant: %w", err)
+	}
+	uc.auditUsecase.Record(models.WithTenantID(ctx, tenant.ID), models.AuditTenantCreate, "tenant", tenant.ID.Hex(), nil, tenant)
+	return tenant, nil
+}
+
+func (uc *tenantUsecase) GetTenant(ctx context.Context, id primitive.ObjectID) (*models.Tenant, error) {
+	tenant, err := uc.tenantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tenant: %w", err)
+	}
+	return tenant, nil
+}
+
+func (uc *tenantUsecase) UpdateTenantSettings(ctx context.Context, id primitive.ObjectID, settings models.TenantSettings) (*models.Tenant, error) {
+	before, err := uc.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.tenantRepo.UpdateSettings(ctx, id, settings); err != nil {
+		return nil, fmt.Errorf("failed to update tenant settings: %w", err)
+	}
+
+	after, err := uc.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(models.WithTenantID(ctx, id), models.AuditTenantUpdateSettings, "tenant", id.Hex(), before, after)
+	return after, nil
+}
+
+func (uc *tenantUsecase) UpdateToolPolicy(ctx context.Context, id primitive.ObjectID, policy models.ToolPolicy) (*models.Tenant, error) {
+	for _, name := range policy.ToolNames() {
+		if !uc.toolsManager.HasTool(name) {
+			return nil, fmt.Errorf("%w: %s", models.ErrUnknownTool, name)
+		}
+	}
+
+	before, err := uc.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := before.Settings
+	settings.ToolPolicy = policy
+	if err := uc.tenantRepo.UpdateSettings(ctx, id, settings); err != nil {
+		return nil, fmt.Errorf("failed to update tool policy: %w", err)
+	}
+
+	after, err := uc.GetTenant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.auditUsecase.Record(models.WithTenantID(ctx, id), models.AuditTenantToolPolicy, "tenant", id.Hex(), before, after)
+	return after, nil
+}
+
+func (uc *tenantUsecase) AllowedTools(ctx context.Context, sellerID string) ([]string, error) {
+	tenantID, ok := models.TenantIDFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	tenant, err := uc.GetTenant(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return tenant.Settings.ToolPolicy.AllowedTools(sellerID), nil
+}
+
+func (uc *tenantUsecase) CreateAPIKey(ctx context.Context, tenantID primitive.ObjectID, name string) (*models.APIKey, string, error) {
+	if _, err := uc.GetTenant(ctx, tenantID); err != nil {
+		return nil, "", err
+	}
+
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	rawKey := apiKeyPrefix + hex.EncodeToString(secret)
+
+	key := &models.APIKey{
+		TenantID: tenantID,
+		Name:     name,
+		Prefix:   rawKey[:len(apiKeyPrefix)+6],
+		KeyHash:  hashAPIKey(rawKey),
+	}
+	if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", fmt.Errorf("failed to create api key: %w", err)
+	}
+	uc.auditUsecase.Record(models.WithTenantID(ctx, tenantID), models.AuditAPIKeyCreate, "api_key", key.ID.Hex(), nil, key)
+	return key, rawKey, nil
+}
+
+func (uc *tenantUsecase) RevokeAPIKey(ctx context.Context, tenantID, keyID primitive.ObjectID) error {
+	if err := uc.apiKeyRepo.Revoke(ctx, tenantID, keyID); err != nil {
+		return fmt.Errorf("failed to revoke api key: %w", err)
+	}
+	uc.auditUsecase.Record(models.WithTenantID(ctx, tenantID), models.AuditAPIKeyRevoke, "api_key", keyID.Hex(), nil, nil)
+	return nil
+}
+
+// ResolveAPIKey returns the active key matching rawKey, or nil when it is unknown or revoked
+func (uc *tenantUsecase) ResolveAPIKey(ctx context.Context, rawKey string) (*models.APIKey, error) {
+	key, err := uc.apiKeyRepo.GetActiveByHash(ctx, hashAPIKey(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve api key: %w", err)
+	}
+	return key, nil
+}
+
+func (uc *tenantUsecase) CheckSessionQuota(ctx context.Context) error {
+	tenantID, ok := models.TenantIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	tenant, err := uc.GetTenant(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	limit := tenant.Settings.Quotas.MaxSessionsPerDay
+	if limit <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	count, err := uc.sessionRepo.CountStartedSince(ctx, startOfDay)
+	if err != nil {
+		return fmt.Errorf("failed to check session quota: %w", err)
+	}
+	if count >= int64(limit) {
+		return models.ErrQuotaExceeded
+	}
+	return nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
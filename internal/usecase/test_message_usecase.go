@@ -0,0 +1,27 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+)
+
+// TestMessageUsecase serves the messages held while partners are in test mode
+type TestMessageUsecase interface {
+	List(ctx context.Context, channelID string, limit int) ([]*models.TestMessage, error)
+}
+
+type testMessageUsecase struct {
+	testMessageRepo mongodb.TestMessageRepository
+}
+
+func NewTestMessageUsecase(testMessageRepo mongodb.TestMessageRepository) TestMessageUsecase {
+	return &testMessageUsecase{
+		testMessageRepo: testMessageRepo,
+	}
+}
+
+func (uc *testMessageUsecase) List(ctx context.Context, channelID string, limit int) ([]*models.TestMessage, error) {
+	return uc.testMessageRepo.List(ctx, channelID, limit)
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	_ "embed"
+	"fmt"
+	"maps"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default_tool_fixtures.yaml
+var defaultToolFixturesData []byte
+
+// loadToolFixtures parses the embedded results of tools in dry sessions
+func loadToolFixtures() (map[string]any, error) {
+	fixtures := make(map[string]any)
+	if err := yaml.Unmarshal(defaultToolFixturesData, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tool fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// sessionToolFixtures returns the fixtures of a dry session, the message's
+// over the defaults, and nil for sessions whose tools run
+func (l *llmUsecase) sessionToolFixtures(data *PromptData) map[string]any {
+	if !data.DryTools {
+		return nil
+	}
+	fixtures := maps.Clone(l.toolFixtures)
+	maps.Copy(fixtures, data.ToolFixtures)
+	return fixtures
+}
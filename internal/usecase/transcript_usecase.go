@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/firebase/genkit/go/ai"
+	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/redact"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TranscriptUsecase interface {
+	GetTranscript(ctx context.Context, sessionID primitive.ObjectID) (*models.SessionTranscript, error)
+}
+
+type transcriptUsecase struct {
+	transcriptRepo mongodb.TranscriptRepository
+}
+
+func NewTranscriptUsecase(transcriptRepo mongodb.TranscriptRepository) TranscriptUsecase {
+	return &transcriptUsecase{
+		transcriptRepo: transcriptRepo,
+	}
+}
+
+func (uc *transcriptUsecase) GetTranscript(ctx context.Context, sessionID primitive.ObjectID) (*models.SessionTranscript, error) {
+	return uc.transcriptRepo.GetBySessionID(ctx, sessionID)
+}
+
+// transcriptRecorder collects the model conversation of one session as it
+// happens. A nil recorder records nothing, which is how transcripts are disabled.
+type transcriptRecorder struct {
+	transcript *models.SessionTranscript
+	iteration  int
+}
+
+func newTranscriptRecorder(conf config.TranscriptConfig, chatMode *models.ChatMode, data *PromptData, channelID string) *transcriptRecorder {
+	if !conf.Enabled {
+		return nil
+	}
+	// the session ID was validated before the recorder is created
+	sessionID, _ := primitive.ObjectIDFromHex(data.SessionID)
+	return &transcriptRecorder{
+		transcript: &models.SessionTranscript{
+			SessionID: sessionID,
+			ChannelID: channelID,
+			UserID:    data.UserID,
+			ChatMode:  chatMode.Name,
+			Model:     chatMode.Model,
+			Input:     transcriptInput(data),
+			ExpiresAt: time.Now().Add(conf.Retention),
+		},
+	}
+}
+
+// transcriptInput copies the prompt inputs of data with secrets redacted
+func transcriptInput(data *PromptData) *models.TranscriptInput {
+	input := &models.TranscriptInput{
+		ChannelInfo: data.ChannelInfo,
+		SenderRole:  data.SenderRole,
+		Message:     redact.Secrets(data.Message),
+		Listings:    data.Listings,
+	}
+	if data.RecentMessages != nil {
+		history := &models.MessageHistory{HasMore: data.RecentMessages.HasMore}
+		for _, msg := range data.RecentMessages.Messages {
+			msg.Message = redact.Secrets(msg.Message)
+			history.Messages = append(history.Messages, msg)
+		}
+		input.RecentMessages = history
+	}
+	return input
+}
+
+func (r *transcriptRecorder) startIteration(iteration int) {
+	if r == nil {
+		return
+	}
+	r.iteration = iteration
+}
+
+// addMessages records the rendered system prompt, history and buyer message
+// the conversation starts from
+func (r *transcriptRecorder) addMessages(messages []*ai.Message) {
+	for _, msg := range messages {
+		entryType := models.TranscriptUser
+		switch msg.Role {
+		case ai.RoleSystem:
+			entryType = models.TranscriptSystem
+		case ai.RoleModel:
+			entryType = models.TranscriptModel
+		}
+		r.add(models.TranscriptEntry{Type: entryType, Text: msg.Text()})
+	}
+}
+
+func (r *transcriptRecorder) addModelText(text string) {
+	r.add(models.TranscriptEntry{Type: models.TranscriptModel, Text: text})
+}
+
+func (r *transcriptRecorder) addToolRequest(req *ai.ToolRequest) {
+	r.add(models.TranscriptEntry{
+		Type:     models.TranscriptToolRequest,
+		ToolName: req.Name,
+		ToolRef:  req.Ref,
+		ToolData: encodeToolData(req.Input),
+	})
+}
+
+func (r *transcriptRecorder) addToolResponse(req *ai.ToolRequest, output any, err error) {
+	entry := models.TranscriptEntry{
+		Type:     models.TranscriptToolResponse,
+		ToolName: req.Name,
+		ToolRef:  req.Ref,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else {
+		entry.ToolData = encodeToolData(output)
+	}
+	r.add(entry)
+}
+
+func (r *transcriptRecorder) add(entry models.TranscriptEntry) {
+	if r == nil {
+		return
+	}
+	entry.Iteration = r.iteration
+	entry.Text = redact.Secrets(entry.Text)
+	entry.ToolData = redact.Secrets(entry.ToolData)
+	entry.Error = redact.Secrets(entry.Error)
+	entry.At = time.Now()
+	r.transcript.Entries = append(r.transcript.Entries, entry)
+}
+
+// save stores the transcript along with the error that ended processing, if
+// any. It runs after processing, so it ignores the deadline of ctx.
+func (r *transcriptRecorder) save(ctx context.Context, repo mongodb.TranscriptRepository, processErr error) {
+	if r == nil {
+		return
+	}
+	if processErr != nil {
+		r.transcript.Error = redact.Secrets(processErr.Error())
+	}
+	if err := repo.Create(context.WithoutCancel(ctx), r.transcript); err != nil {
+		log.Errorw(ctx, "Failed to save session transcript", "session_id", r.transcript.SessionID.Hex(), "error", err)
+	}
+}
+
+func encodeToolData(v any) string {
+	if v == nil {
+		return ""
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
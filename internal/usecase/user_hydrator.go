@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/ttlcache"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// profileCacheTTL keeps renamed users from showing stale names for long while
+// sparing the database when the same channel is rendered repeatedly
+const profileCacheTTL = time.Minute
+
+// UserHydrator attaches display info to chat-api user IDs with a constant
+// number of queries, regardless of how many users are involved
+type UserHydrator interface {
+	Profiles(ctx context.Context, chatUserIDs []string) (map[string]*models.UserProfile, error)
+	HydrateParticipants(ctx context.Context, participants []models.Participant) error
+	HydrateMessages(ctx context.Context, messages []models.HistoryMessage) error
+}
+
+type userHydrator struct {
+	userRepo          mongodb.UserRepository
+	userAttributeRepo mongodb.UserAttributeRepository
+	// keyed by tenant and chat-api user ID, nil marks users known to be unlinked
+	cache *ttlcache.Cache[string, *models.UserProfile]
+}
+
+func NewUserHydrator(
+	userRepo mongodb.UserRepository,
+	userAttributeRepo mongodb.UserAttributeRepository,
+) UserHydrator {
+	return &userHydrator{
+		userRepo:          userRepo,
+		userAttributeRepo: userAttributeRepo,
+		cache:             ttlcache.New[string, *models.UserProfile](profileCacheTTL),
+	}
+}
+
+// Profiles maps each chat-api user ID to its profile. IDs without a linked
+// internal user are absent from the result.
+func (h *userHydrator) Profiles(ctx context.Context, chatUserIDs []string) (map[string]*models.UserProfile, error) {
+	profiles := make(map[string]*models.UserProfile)
+	var misses []string
+	for _, id := range uniqueStrings(chatUserIDs) {
+		profile, ok := h.cache.Get(profileCacheKey(ctx, id))
+		if !ok {
+			misses = append(misses, id)
+			continue
+		}
+		if profile != nil {
+			profiles[id] = profile
+		}
+	}
+	if len(misses) == 0 {
+		return profiles, nil
+	}
+
+	attrs, err := h.userAttributeRepo.GetByKeyAndValues(ctx, models.AttributeChototID, misses)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chotot attributes: %w", err)
+	}
+
+	userIDs := make([]primitive.ObjectID, 0, len(attrs))
+	for _, attr := range attrs {
+		userIDs = append(userIDs, attr.UserID)
+	}
+	users, err := h.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
+	}
+
+	usersByID := make(map[primitive.ObjectID]*models.User, len(users))
+	for _, user := range users {
+		usersByID[user.ID] = user
+	}
+
+	for _, attr := range attrs {
+		user, ok := usersByID[attr.UserID]
+		if !ok {
+			continue
+		}
+		profiles[attr.Value] = &models.UserProfile{
+			ID:        attr.Value,
+			UserID:    user.ID.Hex(),
+			Name:      user.Name,
+			AvatarURL: user.AvatarURL,
+		}
+	}
+
+	for _, id := range misses {
+		h.cache.Set(profileCacheKey(ctx, id), profiles[id])
+	}
+	return profiles, nil
+}
+
+func profileCacheKey(ctx context.Context, chatUserID string) string {
+	tenantID, _ := models.TenantIDFromContext(ctx)
+	return tenantID.Hex() + "/" + chatUserID
+}
+
+func (h *userHydrator) HydrateParticipants(ctx context.Context, participants []models.Participant) error {
+	ids := make([]string, 0, len(participants))
+	for _, participant := range participants {
+		ids = append(ids, participant.UserID)
+	}
+
+	profiles, err := h.Profiles(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range participants {
+		participants[i].Profile = profiles[participants[i].UserID]
+	}
+	return nil
+}
+
+func (h *userHydrator) HydrateMessages(ctx context.Context, messages []models.HistoryMessage) error {
+	ids := make([]string, 0, len(messages))
+	for _, message := range messages {
+		ids = append(ids, message.SenderID)
+	}
+
+	profiles, err := h.Profiles(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for i := range messages {
+		messages[i].Sender = profiles[messages[i].SenderID]
+	}
+	return nil
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
@@ -0,0 +1,181 @@
+package usecase_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
+	"github.com/nguyentranbao-ct/chat-bot/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// roundTrip simulates the network latency of one database query
+const roundTrip = 200 * time.Microsecond
+
+type fakeStore struct {
+	users   map[primitive.ObjectID]*models.User
+	attrs   []*models.UserAttribute
+	queries int
+}
+
+func newFakeStore(n int) *fakeStore {
+	s := &fakeStore{users: make(map[primitive.ObjectID]*models.User, n)}
+	for i := 0; i < n; i++ {
+		user := &models.User{ID: primitive.NewObjectID(), Name: fmt.Sprintf("User %d", i)}
+		s.users[user.ID] = user
+		s.attrs = append(s.attrs, &models.UserAttribute{
+			UserID: user.ID,
+			Key:    models.AttributeChototID,
+			Value:  fmt.Sprintf("%d", 1000+i),
+		})
+	}
+	return s
+}
+
+func (s *fakeStore) query() {
+	s.queries++
+	time.Sleep(roundTrip)
+}
+
+type fakeUserRepo struct {
+	mongodb.UserRepository
+	store *fakeStore
+}
+
+func (r *fakeUserRepo) GetByID(ctx context.Context, id primitive.ObjectID) (*models.User, error) {
+	r.store.query()
+	if user, ok := r.store.users[id]; ok {
+		return user, nil
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *fakeUserRepo) GetByIDs(ctx context.Context, ids []primitive.ObjectID) ([]*models.User, error) {
+	r.store.query()
+	var users []*models.User
+	for _, id := range ids {
+		if user, ok := r.store.users[id]; ok {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+type fakeUserAttributeRepo struct {
+	mongodb.UserAttributeRepository
+	store *fakeStore
+}
+
+func (r *fakeUserAttributeRepo) GetByKeyAndValue(ctx context.Context, key, value string) (*models.UserAttribute, error) {
+	r.store.query()
+	for _, attr := range r.store.attrs {
+		if attr.Key == key && attr.Value == value {
+			return attr, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserAttributeRepo) GetByKeyAndValues(ctx context.Context, key string, values []string) ([]*models.UserAttribute, error) {
+	r.store.query()
+	wanted := make(map[string]bool, len(values))
+	for _, value := range values {
+		wanted[value] = true
+	}
+	var attrs []*models.UserAttribute
+	for _, attr := range r.store.attrs {
+		if attr.Key == key && wanted[attr.Value] {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs, nil
+}
+
+func chatUserIDs(n int) []string {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, fmt.Sprintf("%d", 1000+i))
+	}
+	return ids
+}
+
+func TestUserHydrator(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Profiles In Constant Queries", func(t *testing.T) {
+		store := newFakeStore(50)
+		hydrator := usecase.NewUserHydrator(&fakeUserRepo{store: store}, &fakeUserAttributeRepo{store: store})
+
+		ids := append(chatUserIDs(50), "1000", "unknown", "")
+		profiles, err := hydrator.Profiles(t.Context(), ids)
+		require.NoError(t, err)
+
+		assert.Len(t, profiles, 50)
+		assert.Equal(t, "User 0", profiles["1000"].Name)
+		assert.NotContains(t, profiles, "unknown")
+		assert.Equal(t, 2, store.queries, "Hydration should use one attribute and one user query")
+
+		_, err = hydrator.Profiles(t.Context(), ids)
+		require.NoError(t, err)
+		assert.Equal(t, 2, store.queries, "Repeated lookups should be served from the cache")
+	})
+
+	t.Run("Hydrate Messages", func(t *testing.T) {
+		store := newFakeStore(2)
+		hydrator := usecase.NewUserHydrator(&fakeUserRepo{store: store}, &fakeUserAttributeRepo{store: store})
+
+		messages := []models.HistoryMessage{
+			{ID: "m1", SenderID: "1000"},
+			{ID: "m2", SenderID: "1001"},
+			{ID: "m3", SenderID: "1000"},
+			{ID: "m4", SenderID: "stranger"},
+		}
+		require.NoError(t, hydrator.HydrateMessages(t.Context(), messages))
+
+		assert.Equal(t, "User 0", messages[0].Sender.Name)
+		assert.Equal(t, "User 1", messages[1].Sender.Name)
+		assert.Same(t, messages[0].Sender, messages[2].Sender)
+		assert.Nil(t, messages[3].Sender)
+	})
+}
+
+func BenchmarkUserLookup(b *testing.B) {
+	for _, n := range []int{10, 50} {
+		b.Run(fmt.Sprintf("PerUser/%d", n), func(b *testing.B) {
+			store := newFakeStore(n)
+			users := usecase.NewUserUsecase(&fakeUserRepo{store: store}, &fakeUserAttributeRepo{store: store}, nil)
+			identities := usecase.NewIdentityMapper(users, &fakeUserAttributeRepo{store: store})
+			ids := chatUserIDs(n)
+
+			for b.Loop() {
+				for _, id := range ids {
+					userID, err := identities.ResolveExternalID(b.Context(), models.PartnerChotot, id)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if _, err := users.GetUser(b.Context(), *userID); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("Batched/%d", n), func(b *testing.B) {
+			store := newFakeStore(n)
+			ids := chatUserIDs(n)
+
+			for b.Loop() {
+				// a fresh hydrator per iteration measures cold lookups, not the profile cache
+				hydrator := usecase.NewUserHydrator(&fakeUserRepo{store: store}, &fakeUserAttributeRepo{store: store})
+				if _, err := hydrator.Profiles(b.Context(), ids); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	_ "embed"
 	"fmt"
+	"reflect"
 	"time"
 
 	log "github.com/carousell/ct-go/pkg/logger/log_context"
@@ -30,8 +31,8 @@ type DefaultUserAttribute struct {
 	Tags      []string `yaml:"tags"`
 }
 
-func AutoMigrateUsers(userRepo mongodb.UserRepository, userAttrRepo mongodb.UserAttributeRepository) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func AutoMigrateUsers(userRepo mongodb.UserRepository, userAttrRepo mongodb.UserAttributeRepository, auditUsecase AuditUsecase, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	// Load and create default users
@@ -64,6 +65,7 @@ func AutoMigrateUsers(userRepo mongodb.UserRepository, userAttrRepo mongodb.User
 			if err := userRepo.Create(ctx, user); err != nil {
 				return fmt.Errorf("failed to create user '%s': %w", defaultUser.Email, err)
 			}
+			auditUsecase.Record(ctx, models.AuditUserMigrate, "user", user.ID.Hex(), nil, user)
 			log.Infow(ctx, "Created default user", "email", defaultUser.Email)
 		} else {
 			log.Debugw(ctx, "User already exists", "email", defaultUser.Email)
@@ -99,6 +101,11 @@ func AutoMigrateUsers(userRepo mongodb.UserRepository, userAttrRepo mongodb.User
 			continue
 		}
 
+		before, err := userAttrRepo.GetByUserIDAndKey(ctx, user.ID, defaultAttr.Key)
+		if err != nil {
+			return fmt.Errorf("failed to get user attribute '%s' for user '%s': %w", defaultAttr.Key, defaultAttr.UserEmail, err)
+		}
+
 		// Upsert user attribute
 		attr := &models.UserAttribute{
 			UserID: user.ID,
@@ -109,6 +116,9 @@ func AutoMigrateUsers(userRepo mongodb.UserRepository, userAttrRepo mongodb.User
 		if err := userAttrRepo.Upsert(ctx, attr); err != nil {
 			return fmt.Errorf("failed to upsert user attribute '%s' for user '%s': %w", defaultAttr.Key, defaultAttr.UserEmail, err)
 		}
+		if before == nil || before.Value != attr.Value || !reflect.DeepEqual(before.Tags, attr.Tags) {
+			auditUsecase.Record(ctx, models.AuditUserAttributeMigrate, "user_attribute", attributeResourceID(user.ID, attr.Key), before, attr)
+		}
 		log.Infow(ctx, "Created/updated default user attribute",
 			"user_email", defaultAttr.UserEmail,
 			"key", defaultAttr.Key,
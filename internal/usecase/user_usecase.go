@@ -21,22 +21,24 @@ type UserUsecase interface {
 	GetUserAttributes(ctx context.Context, userID primitive.ObjectID) ([]*models.UserAttribute, error)
 	GetUserAttributeByKey(ctx context.Context, userID primitive.ObjectID, key string) (*models.UserAttribute, error)
 	GetUsersByTag(ctx context.Context, tags []string) ([]*models.User, error)
-	GetUserByChototID(ctx context.Context, chototID string) (*models.User, error)
 	RemoveUserAttribute(ctx context.Context, userID primitive.ObjectID, key string) error
 }
 
 type userUsecase struct {
 	userRepo          mongodb.UserRepository
 	userAttributeRepo mongodb.UserAttributeRepository
+	auditUsecase      AuditUsecase
 }
 
 func NewUserUsecase(
 	userRepo mongodb.UserRepository,
 	userAttributeRepo mongodb.UserAttributeRepository,
+	auditUsecase AuditUsecase,
 ) UserUsecase {
 	return &userUsecase{
 		userRepo:          userRepo,
 		userAttributeRepo: userAttributeRepo,
+		auditUsecase:      auditUsecase,
 	}
 }
 
@@ -58,6 +60,7 @@ func (uc *userUsecase) CreateUser(ctx context.Context, name, email string) (*mod
 	if err := uc.userRepo.Create(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
+	uc.auditUsecase.Record(ctx, models.AuditUserCreate, "user", user.ID.Hex(), nil, user)
 
 	return user, nil
 }
@@ -79,16 +82,35 @@ func (uc *userUsecase) GetUserByEmail(ctx context.Context, email string) (*model
 }
 
 func (uc *userUsecase) UpdateUser(ctx context.Context, user *models.User) error {
+	before, err := uc.userRepo.GetByID(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
 	if err := uc.userRepo.Update(ctx, user); err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
+	// a new address has to be verified again
+	if user.Email != before.Email && before.EmailVerifiedAt != nil {
+		if err := uc.userRepo.SetEmailVerified(ctx, user.ID, nil); err != nil {
+			return err
+		}
+		user.EmailVerifiedAt = nil
+	}
+	uc.auditUsecase.Record(ctx, models.AuditUserUpdate, "user", user.ID.Hex(), before, user)
 	return nil
 }
 
 func (uc *userUsecase) DeleteUser(ctx context.Context, id primitive.ObjectID) error {
+	before, err := uc.userRepo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
 	if err := uc.userRepo.Delete(ctx, id); err != nil {
 		return fmt.Errorf("failed to delete user: %w", err)
 	}
+	uc.auditUsecase.Record(ctx, models.AuditUserDelete, "user", id.Hex(), before, nil)
 	return nil
 }
 
@@ -98,6 +120,11 @@ func (uc *userUsecase) SetUserAttribute(ctx context.Context, userID primitive.Ob
 		return fmt.Errorf("invalid attribute key format: %s (must be alpha-numeric with underscores)", key)
 	}
 
+	before, err := uc.userAttributeRepo.GetByUserIDAndKey(ctx, userID, key)
+	if err != nil {
+		return fmt.Errorf("failed to get user attribute: %w", err)
+	}
+
 	attr := &models.UserAttribute{
 		UserID: userID,
 		Key:    key,
@@ -108,6 +135,7 @@ func (uc *userUsecase) SetUserAttribute(ctx context.Context, userID primitive.Ob
 	if err := uc.userAttributeRepo.Upsert(ctx, attr); err != nil {
 		return fmt.Errorf("failed to set user attribute: %w", err)
 	}
+	uc.auditUsecase.Record(ctx, models.AuditUserAttributeSet, "user_attribute", attributeResourceID(userID, key), before, attr)
 
 	return nil
 }
@@ -137,50 +165,43 @@ func (uc *userUsecase) GetUsersByTag(ctx context.Context, tags []string) ([]*mod
 
 	// Extract unique user IDs
 	userIDMap := make(map[primitive.ObjectID]bool)
+	userIDs := make([]primitive.ObjectID, 0, len(attrs))
 	for _, attr := range attrs {
-		userIDMap[attr.UserID] = true
+		if !userIDMap[attr.UserID] {
+			userIDMap[attr.UserID] = true
+			userIDs = append(userIDs, attr.UserID)
+		}
 	}
 
-	// Get users by IDs
-	var users []*models.User
-	for userID := range userIDMap {
-		user, err := uc.userRepo.GetByID(ctx, userID)
-		if err != nil {
-			continue // Skip users that can't be retrieved
-		}
-		users = append(users, user)
+	users, err := uc.userRepo.GetByIDs(ctx, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get users: %w", err)
 	}
 
 	return users, nil
 }
 
-func (uc *userUsecase) GetUserByChototID(ctx context.Context, chototID string) (*models.User, error) {
-	// Find user attribute with chotot_id key and matching value
-	attrs, err := uc.userAttributeRepo.GetByKey(ctx, "chotot_id")
+func (uc *userUsecase) RemoveUserAttribute(ctx context.Context, userID primitive.ObjectID, key string) error {
+	before, err := uc.userAttributeRepo.GetByUserIDAndKey(ctx, userID, key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get chotot attributes: %w", err)
-	}
-
-	for _, attr := range attrs {
-		if attr.Value == chototID {
-			user, err := uc.userRepo.GetByID(ctx, attr.UserID)
-			if err != nil {
-				continue // Skip if user can't be retrieved
-			}
-			return user, nil
-		}
+		return fmt.Errorf("failed to get user attribute: %w", err)
 	}
 
-	return nil, fmt.Errorf("user with chotot ID %s not found", chototID)
-}
-
-func (uc *userUsecase) RemoveUserAttribute(ctx context.Context, userID primitive.ObjectID, key string) error {
 	if err := uc.userAttributeRepo.DeleteByUserIDAndKey(ctx, userID, key); err != nil {
 		return fmt.Errorf("failed to remove user attribute: %w", err)
 	}
+	if before != nil {
+		uc.auditUsecase.Record(ctx, models.AuditUserAttributeRemove, "user_attribute", attributeResourceID(userID, key), before, nil)
+	}
 	return nil
 }
 
+// attributeResourceID identifies an attribute by its owner and key, which stays
+// stable across upserts unlike the document ID
+func attributeResourceID(userID primitive.ObjectID, key string) string {
+	return userID.Hex() + "/" + key
+}
+
 // isValidAttributeKey validates that the key contains only alpha-numeric characters and underscores
 func isValidAttributeKey(key string) bool {
 	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_]+$`, key)
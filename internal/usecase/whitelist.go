@@ -1,23 +1,54 @@
 package usecase
 
 import (
+	"context"
+	"fmt"
+	"hash/fnv"
 	"strings"
+	"sync"
+	"time"
 
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
 	"github.com/nguyentranbao-ct/chat-bot/internal/config"
+	"github.com/nguyentranbao-ct/chat-bot/internal/models"
+	"github.com/nguyentranbao-ct/chat-bot/internal/repo/mongodb"
 )
 
 type WhitelistService interface {
-	IsSellerAllowed(sellerID string) bool
+	// IsAllowed reports whether the bot engages the seller's conversation
+	// in the channel
+	IsAllowed(ctx context.Context, sellerID, channelID string) bool
 	GetWhitelistedSellers() []string
+
+	// Whitelist returns every rule, for administrators
+	Whitelist(ctx context.Context) (*models.Whitelist, error)
+	AddEntry(ctx context.Context, entry *models.WhitelistEntry) (*models.WhitelistEntry, error)
+	RemoveEntry(ctx context.Context, kind models.WhitelistKind, value string) error
+	SetRollout(ctx context.Context, percent int) (*models.WhitelistRollout, error)
 }
 
 type whitelistService struct {
 	allowedSellers map[string]bool
 	whitelist      []string
+	repo           mongodb.WhitelistRepository
+	auditUsecase   AuditUsecase
+	refresh        time.Duration
+
+	mu       sync.Mutex
+	snapshot *whitelistSnapshot
+}
+
+// whitelistSnapshot is the stored part of the whitelist, reloaded every
+// refresh interval so changes reach every instance
+type whitelistSnapshot struct {
+	sellers  map[string]bool
+	channels map[string]bool
+	rollout  *models.WhitelistRollout
+	loadedAt time.Time
 }
 
 // NewWhitelistService creates a new whitelist service
-func NewWhitelistService(cfg *config.Config) WhitelistService {
+func NewWhitelistService(cfg *config.Config, repo mongodb.WhitelistRepository, auditUsecase AuditUsecase) WhitelistService {
 	allowedSellers := make(map[string]bool)
 	for _, sellerID := range cfg.Kafka.Whitelist {
 		if sellerID = strings.TrimSpace(sellerID); sellerID != "" {
@@ -28,24 +59,167 @@ func NewWhitelistService(cfg *config.Config) WhitelistService {
 	return &whitelistService{
 		allowedSellers: allowedSellers,
 		whitelist:      cfg.Kafka.Whitelist,
+		repo:           repo,
+		auditUsecase:   auditUsecase,
+		refresh:        cfg.Whitelist.RefreshInterval,
 	}
 }
 
-// IsSellerAllowed checks if a seller is in the whitelist
-func (w *whitelistService) IsSellerAllowed(sellerID string) bool {
-	if w.allowedSellers["all"] {
+// IsAllowed admits a conversation when its seller or channel is whitelisted,
+// or its seller falls in the rollout percentage. With no whitelist and no
+// rollout at all, every conversation is admitted.
+func (w *whitelistService) IsAllowed(ctx context.Context, sellerID, channelID string) bool {
+	if w.allowedSellers["all"] || w.allowedSellers[sellerID] {
 		return true
 	}
 
-	// If whitelist is empty, allow all sellers
-	if len(w.allowedSellers) == 0 {
+	snap := w.load(ctx)
+	if snap.sellers[sellerID] || snap.channels[channelID] {
 		return true
 	}
+	if snap.rollout != nil {
+		return rolloutBucket(sellerID) < snap.rollout.Percent
+	}
 
-	return w.allowedSellers[sellerID]
+	// If whitelist is empty, allow all sellers
+	return len(w.allowedSellers) == 0 && len(snap.sellers) == 0 && len(snap.channels) == 0
 }
 
 // GetWhitelistedSellers returns the list of whitelisted sellers
 func (w *whitelistService) GetWhitelistedSellers() []string {
 	return w.whitelist
 }
+
+func (w *whitelistService) Whitelist(ctx context.Context) (*models.Whitelist, error) {
+	entries, err := w.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := w.repo.GetRollout(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Whitelist{
+		Sellers: w.whitelist,
+		Entries: entries,
+		Rollout: rollout,
+	}, nil
+}
+
+func (w *whitelistService) AddEntry(ctx context.Context, entry *models.WhitelistEntry) (*models.WhitelistEntry, error) {
+	entry.Value = strings.TrimSpace(entry.Value)
+	if !entry.Kind.Valid() || entry.Value == "" {
+		return nil, fmt.Errorf("%w: kind must be seller or channel and value is required", models.ErrInvalidWhitelistEntry)
+	}
+
+	entry.CreatedBy = models.ActorFromContext(ctx)
+	if err := w.repo.Add(ctx, entry); err != nil {
+		return nil, err
+	}
+	w.invalidate()
+	w.auditUsecase.Record(ctx, models.AuditWhitelistAdd, "whitelist_entry", entry.ID.Hex(), nil, entry)
+	return entry, nil
+}
+
+func (w *whitelistService) RemoveEntry(ctx context.Context, kind models.WhitelistKind, value string) error {
+	before, err := w.repo.Get(ctx, kind, value)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return models.ErrNotFound
+	}
+
+	if err := w.repo.Remove(ctx, kind, value); err != nil {
+		return err
+	}
+	w.invalidate()
+	w.auditUsecase.Record(ctx, models.AuditWhitelistRemove, "whitelist_entry", before.ID.Hex(), before, nil)
+	return nil
+}
+
+func (w *whitelistService) SetRollout(ctx context.Context, percent int) (*models.WhitelistRollout, error) {
+	if percent < 0 || percent > 100 {
+		return nil, fmt.Errorf("%w: rollout percent must be between 0 and 100", models.ErrInvalidWhitelistEntry)
+	}
+
+	before, err := w.repo.GetRollout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rollout := &models.WhitelistRollout{
+		Percent:   percent,
+		UpdatedBy: models.ActorFromContext(ctx),
+	}
+	if err := w.repo.SetRollout(ctx, rollout); err != nil {
+		return nil, err
+	}
+	w.invalidate()
+	w.auditUsecase.Record(ctx, models.AuditWhitelistRollout, "whitelist_rollout", "rollout", before, rollout)
+	return rollout, nil
+}
+
+// load returns the stored whitelist, reloading it when it is older than the
+// refresh interval. When reloading fails the previous snapshot is kept.
+func (w *whitelistService) load(ctx context.Context) *whitelistSnapshot {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.snapshot != nil && time.Since(w.snapshot.loadedAt) < w.refresh {
+		return w.snapshot
+	}
+
+	snap, err := w.fetch(ctx)
+	if err != nil {
+		log.Errorw(ctx, "Failed to load whitelist", "error", err)
+		if w.snapshot == nil {
+			// fall back to the setting alone, retrying on the next message
+			return &whitelistSnapshot{}
+		}
+		return w.snapshot
+	}
+	w.snapshot = snap
+	return snap
+}
+
+func (w *whitelistService) fetch(ctx context.Context) (*whitelistSnapshot, error) {
+	entries, err := w.repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rollout, err := w.repo.GetRollout(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	snap := &whitelistSnapshot{
+		sellers:  make(map[string]bool),
+		channels: make(map[string]bool),
+		rollout:  rollout,
+		loadedAt: time.Now(),
+	}
+	for _, entry := range entries {
+		switch entry.Kind {
+		case models.WhitelistKindSeller:
+			snap.sellers[entry.Value] = true
+		case models.WhitelistKindChannel:
+			snap.channels[entry.Value] = true
+		}
+	}
+	return snap, nil
+}
+
+// invalidate makes the next check reload the stored whitelist
+func (w *whitelistService) invalidate() {
+	w.mu.Lock()
+	w.snapshot = nil
+	w.mu.Unlock()
+}
+
+// rolloutBucket places the seller in one of 100 stable buckets
+func rolloutBucket(sellerID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(sellerID))
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,72 @@
+// Package crypto provides authenticated encryption for secrets stored at rest.
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Cipher encrypts and decrypts small secrets such as API keys
+type Cipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
+
+type aesGCM struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCM returns an AES-GCM cipher. The key must be 16, 24 or 32 bytes.
+func NewAESGCM(key []byte) (Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create block cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return &aesGCM{aead: aead}, nil
+}
+
+// NewAESGCMFromBase64 is NewAESGCM with a base64 (std encoding) encoded key,
+// the form the key is kept in configuration
+func NewAESGCMFromBase64(encodedKey string) (Cipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+	return NewAESGCM(key)
+}
+
+// Encrypt seals plaintext with a random nonce and returns base64(nonce|ciphertext)
+func (c *aesGCM) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *aesGCM) Decrypt(ciphertext string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+	plaintext, err := c.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
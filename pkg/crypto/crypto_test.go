@@ -0,0 +1,71 @@
+package crypto_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/nguyentranbao-ct/chat-bot/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAESGCM(t *testing.T) {
+	t.Parallel()
+	key := bytes.Repeat([]byte{0x42}, 32)
+
+	t.Run("Round Trip", func(t *testing.T) {
+		c, err := crypto.NewAESGCM(key)
+		require.NoError(t, err)
+
+		sealed, err := c.Encrypt([]byte("AIza-secret"))
+		require.NoError(t, err)
+		assert.NotContains(t, sealed, "AIza-secret")
+
+		plain, err := c.Decrypt(sealed)
+		require.NoError(t, err)
+		assert.Equal(t, "AIza-secret", string(plain))
+	})
+
+	t.Run("Random Nonce", func(t *testing.T) {
+		c, err := crypto.NewAESGCM(key)
+		require.NoError(t, err)
+
+		first, _ := c.Encrypt([]byte("same"))
+		second, _ := c.Encrypt([]byte("same"))
+		assert.NotEqual(t, first, second, "Each encryption should use a fresh nonce")
+	})
+
+	t.Run("Wrong Key", func(t *testing.T) {
+		c, _ := crypto.NewAESGCM(key)
+		other, _ := crypto.NewAESGCM(bytes.Repeat([]byte{0x24}, 32))
+
+		sealed, _ := c.Encrypt([]byte("secret"))
+		_, err := other.Decrypt(sealed)
+		assert.ErrorIs(t, err, crypto.ErrInvalidCiphertext)
+	})
+
+	t.Run("Tampered Ciphertext", func(t *testing.T) {
+		c, _ := crypto.NewAESGCM(key)
+		sealed, _ := c.Encrypt([]byte("secret"))
+		data, _ := base64.StdEncoding.DecodeString(sealed)
+		data[len(data)-1] ^= 0xff
+
+		_, err := c.Decrypt(base64.StdEncoding.EncodeToString(data))
+		assert.ErrorIs(t, err, crypto.ErrInvalidCiphertext)
+
+		_, err = c.Decrypt("short")
+		assert.ErrorIs(t, err, crypto.ErrInvalidCiphertext)
+	})
+
+	t.Run("Base64 Key", func(t *testing.T) {
+		_, err := crypto.NewAESGCMFromBase64(base64.StdEncoding.EncodeToString(key))
+		assert.NoError(t, err)
+
+		_, err = crypto.NewAESGCMFromBase64("not-base64!")
+		assert.Error(t, err)
+
+		_, err = crypto.NewAESGCMFromBase64(base64.StdEncoding.EncodeToString([]byte("short")))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,280 @@
+// Package httpx wraps outbound partner calls with retries, per-host rate limits,
+// circuit breaking and request logging, so partner clients do not hand-roll them.
+package httpx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/carousell/ct-go/pkg/logger/log_context"
+	"github.com/hashicorp/go-retryablehttp"
+	"github.com/nguyentranbao-ct/chat-bot/pkg/livestats"
+	"golang.org/x/time/rate"
+)
+
+// Live stats series of each attempt, followed by the client name. StatPrefix
+// observes the attempt latency in milliseconds.
+const (
+	StatPrefix      = "partner."
+	ErrorStatPrefix = "partner_errors."
+)
+
+// ErrCircuitOpen is returned without calling the partner while its breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Config tunes a Client. The env tags let services embed it in their own config.
+type Config struct {
+	// Timeout bounds each attempt; the caller's context still bounds the whole call
+	Timeout time.Duration `env:"TIMEOUT" envDefault:"30s"`
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries  int           `env:"MAX_RETRIES" envDefault:"2"`
+	BaseBackoff time.Duration `env:"BASE_BACKOFF" envDefault:"200ms"`
+	MaxBackoff  time.Duration `env:"MAX_BACKOFF" envDefault:"5s"`
+	// RateLimit is the steady requests per second allowed per host; 0 disables limiting
+	RateLimit float64 `env:"RATE_LIMIT" envDefault:"20"`
+	Burst     int     `env:"BURST" envDefault:"10"`
+	// BreakerThreshold consecutive failures open a host's breaker for BreakerCooldown; 0 disables it
+	BreakerThreshold int           `env:"BREAKER_THRESHOLD" envDefault:"5"`
+	BreakerCooldown  time.Duration `env:"BREAKER_COOLDOWN" envDefault:"30s"`
+}
+
+type Client struct {
+	name       string
+	cfg        Config
+	httpClient *http.Client
+	mu         sync.Mutex
+	hosts      map[string]*host
+}
+
+// New returns a client for the partner identified by name, used in logs
+func New(name string, cfg Config) *Client {
+	return &Client{
+		name: name,
+		cfg:  cfg,
+		// the client timeout bounds each attempt, including reading the body
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		hosts:      make(map[string]*host),
+	}
+}
+
+// Do sends req under the client's policies. Only idempotent requests, or those
+// carrying an Idempotency-Key header, are retried, following the same policy as
+// go-retryablehttp: transient network errors, 429 and 5xx other than 501.
+// Bodies must be replayable, which http.NewRequest ensures for common readers.
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	retryable := isIdempotent(req) && (req.Body == nil || req.GetBody != nil)
+
+	var resp *http.Response
+	err := c.run(req.Context(), req.URL.Host, retryable, func(ctx context.Context) (time.Duration, error) {
+		attemptReq := req.Clone(ctx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return 0, permanent(fmt.Errorf("failed to rewind request body: %w", err))
+			}
+			attemptReq.Body = body
+		}
+
+		start := time.Now()
+		r, err := c.httpClient.Do(attemptReq)
+		retry, _ := retryablehttp.DefaultRetryPolicy(ctx, r, err)
+		if err != nil {
+			if !retry {
+				return 0, permanent(err)
+			}
+			return 0, err
+		}
+		log.Debugw(ctx, "Partner request", "partner", c.name, "method", req.Method, "host", req.URL.Host,
+			"path", req.URL.Path, "status", r.StatusCode, "duration_ms", time.Since(start).Milliseconds())
+
+		if retry {
+			r.Body.Close()
+			return retryAfter(r), fmt.Errorf("%w: status %d", errServerStatus, r.StatusCode)
+		}
+		resp = r
+		return 0, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Call runs fn under the client's policies, for partners reached through an SDK
+// rather than raw requests. fn is retried on any error only when retryable is set.
+func (c *Client) Call(ctx context.Context, hostName string, retryable bool, fn func(ctx context.Context) error) error {
+	return c.run(ctx, hostName, retryable, func(ctx context.Context) (time.Duration, error) {
+		if c.cfg.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+			defer cancel()
+		}
+		return 0, fn(ctx)
+	})
+}
+
+var errServerStatus = errors.New("partner returned a retryable status")
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+func permanent(err error) error { return permanentError{err: err} }
+
+// run drives the attempts. attempt returns the server-requested delay, if any, with its error.
+func (c *Client) run(ctx context.Context, hostName string, retryable bool, attempt func(ctx context.Context) (time.Duration, error)) error {
+	h := c.host(hostName)
+
+	for i := 0; ; i++ {
+		if !h.breaker.allow(time.Now()) {
+			return fmt.Errorf("%s: %w", c.name, ErrCircuitOpen)
+		}
+		if h.limiter != nil {
+			if err := h.limiter.Wait(ctx); err != nil {
+				return fmt.Errorf("rate limit wait: %w", err)
+			}
+		}
+
+		start := time.Now()
+		delay, err := attempt(ctx)
+		livestats.Observe(StatPrefix+c.name, float64(time.Since(start).Milliseconds()))
+		if err != nil {
+			livestats.Inc(ErrorStatPrefix + c.name)
+		}
+		if err == nil {
+			h.breaker.success()
+			return nil
+		}
+
+		var perm permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		h.breaker.failure(time.Now())
+
+		// the caller gave up, so there is nothing left to retry for
+		if !retryable || i >= c.cfg.MaxRetries || ctx.Err() != nil {
+			return err
+		}
+
+		// a server-requested delay is honoured up to MaxBackoff
+		if delay == 0 {
+			delay = c.backoff(i)
+		} else if delay > c.cfg.MaxBackoff {
+			delay = c.cfg.MaxBackoff
+		}
+		log.Warnw(ctx, "Retrying partner request", "partner", c.name, "host", hostName,
+			"attempt", i+1, "delay_ms", delay.Milliseconds(), "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff is exponential with full jitter, capped at MaxBackoff
+func (c *Client) backoff(retry int) time.Duration {
+	ceiling := c.cfg.BaseBackoff << retry
+	if ceiling <= 0 || ceiling > c.cfg.MaxBackoff {
+		ceiling = c.cfg.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling)
+}
+
+func (c *Client) host(name string) *host {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h, ok := c.hosts[name]
+	if !ok {
+		h = &host{
+			breaker: &breaker{threshold: c.cfg.BreakerThreshold, cooldown: c.cfg.BreakerCooldown},
+		}
+		if c.cfg.RateLimit > 0 {
+			h.limiter = rate.NewLimiter(rate.Limit(c.cfg.RateLimit), max(c.cfg.Burst, 1))
+		}
+		c.hosts[name] = h
+	}
+	return h
+}
+
+type host struct {
+	limiter *rate.Limiter
+	breaker *breaker
+}
+
+// breaker opens after threshold consecutive failures. Once cooldown has passed
+// it lets a single trial call through, closing again if that call succeeds.
+type breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	trial     bool
+}
+
+func (b *breaker) allow(now time.Time) bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return true
+	}
+	if now.Before(b.openUntil) || b.trial {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.trial = false
+}
+
+func (b *breaker) failure(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	b.trial = false
+	if b.failures >= b.threshold {
+		b.openUntil = now.Add(b.cooldown)
+	}
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryAfter reads a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
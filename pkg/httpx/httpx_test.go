@@ -0,0 +1,171 @@
+package httpx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() Config {
+	return Config{
+		Timeout:     time.Second,
+		MaxRetries:  2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  5 * time.Millisecond,
+	}
+}
+
+func TestClientDo(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Retry Server Errors", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			_, _ = io.WriteString(w, "ok")
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		resp, err := New("test", testConfig()).Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", string(body))
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("Replay Body On Retry", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			assert.Equal(t, "payload", string(body))
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodPut, srv.URL, strings.NewReader("payload"))
+		require.NoError(t, err)
+		resp, err := New("test", testConfig()).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("Do Not Retry Non Idempotent Requests", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader("{}"))
+		require.NoError(t, err)
+		_, err = New("test", testConfig()).Do(req)
+		assert.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("Return Client Errors Without Retry", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		resp, err := New("test", testConfig()).Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestClientCall(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Open Circuit After Consecutive Failures", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.MaxRetries = 0
+		cfg.BreakerThreshold = 2
+		cfg.BreakerCooldown = time.Hour
+		c := New("test", cfg)
+
+		var calls int
+		fail := func(ctx context.Context) error {
+			calls++
+			return errors.New("boom")
+		}
+		for i := 0; i < 2; i++ {
+			assert.Error(t, c.Call(context.Background(), "partner", true, fail))
+		}
+
+		err := c.Call(context.Background(), "partner", true, fail)
+		assert.ErrorIs(t, err, ErrCircuitOpen)
+		assert.Equal(t, 2, calls, "An open circuit should not reach the partner")
+
+		err = c.Call(context.Background(), "other", true, func(ctx context.Context) error { return nil })
+		assert.NoError(t, err, "Breakers should be tracked per host")
+	})
+
+	t.Run("Close Circuit After Successful Trial", func(t *testing.T) {
+		b := &breaker{threshold: 1, cooldown: time.Minute}
+		now := time.Now()
+		b.failure(now)
+
+		assert.False(t, b.allow(now.Add(30*time.Second)))
+		assert.True(t, b.allow(now.Add(time.Minute)), "A trial call should pass after the cooldown")
+		assert.False(t, b.allow(now.Add(time.Minute)), "Only one trial call should pass at a time")
+
+		b.success()
+		assert.True(t, b.allow(now.Add(time.Minute)))
+	})
+
+	t.Run("Stop Retrying When Context Is Done", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.MaxRetries = 10
+		cfg.BaseBackoff = time.Hour
+		cfg.MaxBackoff = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		var calls int
+		err := New("test", cfg).Call(ctx, "partner", true, func(ctx context.Context) error {
+			calls++
+			return errors.New("boom")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Retry Only When Allowed", func(t *testing.T) {
+		var calls int
+		err := New("test", testConfig()).Call(context.Background(), "partner", false, func(ctx context.Context) error {
+			calls++
+			return errors.New("boom")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 1, calls)
+	})
+}
@@ -0,0 +1,83 @@
+// Package imagex decodes uploaded images and downsizes them with the standard library only.
+package imagex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+
+	// registered decoders for image.Decode
+	_ "image/gif"
+	_ "image/png"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// Fit decodes r and scales it down, preserving the aspect ratio, so it fits in
+// maxSize x maxSize. Smaller images are kept as is. The result is always JPEG.
+func Fit(r io.Reader, maxSize int, quality int) ([]byte, error) {
+	src, _, err := image.Decode(r)
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, ErrUnsupportedFormat
+		}
+		return nil, fmt.Errorf("decode image: %w", err)
+	}
+
+	dst := src
+	b := src.Bounds()
+	if b.Dx() > maxSize || b.Dy() > maxSize {
+		w, h := maxSize, maxSize
+		if b.Dx() > b.Dy() {
+			h = max(1, b.Dy()*maxSize/b.Dx())
+		} else {
+			w = max(1, b.Dx()*maxSize/b.Dy())
+		}
+		dst = downscale(src, w, h)
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// downscale averages every source pixel covered by a destination pixel (box
+// filter), which avoids the aliasing of nearest-neighbour for large reductions
+func downscale(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*b.Dy()/h
+		y1 := max(y0+1, b.Min.Y+(y+1)*b.Dy()/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*b.Dx()/w
+			x1 := max(x0+1, b.Min.X+(x+1)*b.Dx()/w)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					bl += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(bl / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,80 @@
+package imagex_test
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/nguyentranbao-ct/chat-bot/pkg/imagex"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, w, h int, c color.Color) *bytes.Buffer {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return &buf
+}
+
+func TestFit(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Downscale Landscape", func(t *testing.T) {
+		out, err := imagex.Fit(encodePNG(t, 400, 200, color.White), 100, 85)
+		require.NoError(t, err)
+
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg", format)
+		assert.Equal(t, 100, cfg.Width)
+		assert.Equal(t, 50, cfg.Height)
+	})
+
+	t.Run("Downscale Portrait", func(t *testing.T) {
+		out, err := imagex.Fit(encodePNG(t, 90, 300, color.White), 150, 85)
+		require.NoError(t, err)
+
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, 45, cfg.Width)
+		assert.Equal(t, 150, cfg.Height)
+	})
+
+	t.Run("Keep Small Image Size", func(t *testing.T) {
+		out, err := imagex.Fit(encodePNG(t, 64, 32, color.White), 256, 85)
+		require.NoError(t, err)
+
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(out))
+		require.NoError(t, err)
+		assert.Equal(t, 64, cfg.Width)
+		assert.Equal(t, 32, cfg.Height)
+	})
+
+	t.Run("Preserve Color", func(t *testing.T) {
+		out, err := imagex.Fit(encodePNG(t, 300, 300, color.RGBA{R: 200, A: 255}), 30, 100)
+		require.NoError(t, err)
+
+		img, err := jpeg.Decode(bytes.NewReader(out))
+		require.NoError(t, err)
+		r, g, b, _ := img.At(15, 15).RGBA()
+		assert.InDelta(t, 200, r>>8, 3)
+		assert.InDelta(t, 0, g>>8, 3)
+		assert.InDelta(t, 0, b>>8, 3)
+	})
+
+	t.Run("Reject Non Image", func(t *testing.T) {
+		_, err := imagex.Fit(strings.NewReader("not an image"), 100, 85)
+		assert.ErrorIs(t, err, imagex.ErrUnsupportedFormat)
+	})
+}
@@ -0,0 +1,53 @@
+// Package keyedpool runs tasks on a fixed set of workers. Tasks sharing a key
+// always land on the same worker, so they run one at a time in the order they
+// were submitted, while tasks of different keys run concurrently.
+package keyedpool
+
+import (
+	"hash/fnv"
+	"sync"
+)
+
+type Pool struct {
+	queues []chan func()
+	wg     sync.WaitGroup
+}
+
+// New starts workers goroutines, each buffering up to queueSize tasks
+func New(workers, queueSize int) *Pool {
+	if workers <= 0 {
+		workers = 1
+	}
+	p := &Pool{queues: make([]chan func(), workers)}
+	for i := range p.queues {
+		queue := make(chan func(), queueSize)
+		p.queues[i] = queue
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for task := range queue {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// Run queues task on the worker owning key, blocking while that worker's queue is full
+func (p *Pool) Run(key string, task func()) {
+	p.queues[p.worker(key)] <- task
+}
+
+func (p *Pool) worker(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// Close stops accepting tasks and waits for the queued ones to finish
+func (p *Pool) Close() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
@@ -0,0 +1,96 @@
+package keyedpool
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPool(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Same Key Runs In Order", func(t *testing.T) {
+		p := New(4, 8)
+
+		var mu sync.Mutex
+		got := map[string][]int{}
+		for i := 0; i < 100; i++ {
+			key := fmt.Sprintf("channel-%d", i%3)
+			p.Run(key, func() {
+				mu.Lock()
+				defer mu.Unlock()
+				got[key] = append(got[key], i)
+			})
+		}
+		p.Close()
+
+		for key, seq := range got {
+			assert.IsIncreasing(t, seq, "Tasks of %s should run in submission order", key)
+		}
+		assert.Len(t, got, 3)
+	})
+
+	t.Run("Same Key Never Overlaps", func(t *testing.T) {
+		p := New(4, 8)
+
+		var running, overlaps atomic.Int32
+		for i := 0; i < 20; i++ {
+			p.Run("channel", func() {
+				if running.Add(1) > 1 {
+					overlaps.Add(1)
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+			})
+		}
+		p.Close()
+
+		assert.Zero(t, overlaps.Load())
+	})
+
+	t.Run("Different Keys Run Concurrently", func(t *testing.T) {
+		p := New(2, 1)
+
+		// find two keys owned by different workers
+		keys := []string{"a"}
+		for i := 0; len(keys) < 2; i++ {
+			key := fmt.Sprintf("k%d", i)
+			if p.worker(key) != p.worker("a") {
+				keys = append(keys, key)
+			}
+		}
+
+		release := make(chan struct{})
+		p.Run(keys[0], func() { <-release })
+
+		done := make(chan struct{})
+		p.Run(keys[1], func() { close(done) })
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("A blocked key should not hold up other workers")
+		}
+		close(release)
+		p.Close()
+	})
+
+	t.Run("Close Waits For Queued Tasks", func(t *testing.T) {
+		p := New(1, 10)
+
+		var count atomic.Int32
+		for i := 0; i < 10; i++ {
+			p.Run("k", func() {
+				time.Sleep(time.Millisecond)
+				count.Add(1)
+			})
+		}
+		p.Close()
+
+		assert.Equal(t, int32(10), count.Load())
+	})
+}
@@ -0,0 +1,121 @@
+// Package livestats keeps rolling one-minute counters in memory, giving a live
+// view of the service without an external metrics system.
+package livestats
+
+import (
+	"sync"
+	"time"
+)
+
+// Window is the span every Stat covers
+const Window = time.Minute
+
+const buckets = int64(Window / time.Second)
+
+// Stat summarizes the events of a series over the last Window
+type Stat struct {
+	Count     int64   `json:"count"`
+	PerSecond float64 `json:"per_second"`
+	// Avg is the mean observed value, 0 for series that only count events
+	Avg float64 `json:"avg,omitempty"`
+}
+
+type bucket struct {
+	second int64
+	count  int64
+	sum    float64
+}
+
+// series holds one bucket per second, reused as the window rolls over
+type series [buckets]bucket
+
+func (s *series) add(now time.Time, v float64) {
+	sec := now.Unix()
+	b := &s[sec%buckets]
+	if b.second != sec {
+		*b = bucket{second: sec}
+	}
+	b.count++
+	b.sum += v
+}
+
+func (s *series) stat(now time.Time) Stat {
+	sec := now.Unix()
+	var count int64
+	var sum float64
+	for _, b := range s {
+		if b.second > sec-buckets && b.second <= sec {
+			count += b.count
+			sum += b.sum
+		}
+	}
+
+	stat := Stat{Count: count, PerSecond: float64(count) / Window.Seconds()}
+	if count > 0 {
+		stat.Avg = sum / float64(count)
+	}
+	return stat
+}
+
+type Registry struct {
+	now    func() time.Time
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+func New() *Registry {
+	return &Registry{
+		now:    time.Now,
+		series: make(map[string]*series),
+	}
+}
+
+// Inc counts one event of the named series
+func (r *Registry) Inc(name string) {
+	r.Observe(name, 0)
+}
+
+// Observe counts one event of the named series carrying value v, such as a latency
+func (r *Registry) Observe(name string, v float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.series[name]
+	if !ok {
+		s = new(series)
+		r.series[name] = s
+	}
+	s.add(r.now(), v)
+}
+
+// Get returns the named series, which is empty when nothing was recorded
+func (r *Registry) Get(name string) Stat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.series[name]
+	if !ok {
+		return Stat{}
+	}
+	return s.stat(r.now())
+}
+
+// Snapshot returns every series recorded since the registry was created
+func (r *Registry) Snapshot() map[string]Stat {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	stats := make(map[string]Stat, len(r.series))
+	for name, s := range r.series {
+		stats[name] = s.stat(now)
+	}
+	return stats
+}
+
+// Default is the process-wide registry
+var Default = New()
+
+func Inc(name string)                { Default.Inc(name) }
+func Observe(name string, v float64) { Default.Observe(name, v) }
+func Snapshot() map[string]Stat      { return Default.Snapshot() }
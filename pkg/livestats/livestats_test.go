@@ -0,0 +1,97 @@
+package livestats
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Counts Events", func(t *testing.T) {
+		r := New()
+		for i := 0; i < 30; i++ {
+			r.Inc("messages")
+		}
+
+		stat := r.Get("messages")
+		assert.Equal(t, int64(30), stat.Count)
+		assert.Equal(t, 0.5, stat.PerSecond)
+		assert.Zero(t, stat.Avg)
+	})
+
+	t.Run("Averages Observed Values", func(t *testing.T) {
+		r := New()
+		r.Observe("latency", 100)
+		r.Observe("latency", 300)
+
+		stat := r.Get("latency")
+		assert.Equal(t, int64(2), stat.Count)
+		assert.Equal(t, 200.0, stat.Avg)
+	})
+
+	t.Run("Events Leave The Window", func(t *testing.T) {
+		now := time.Unix(1_700_000_000, 0)
+		r := New()
+		r.now = func() time.Time { return now }
+
+		r.Inc("messages")
+		now = now.Add(30 * time.Second)
+		r.Inc("messages")
+		assert.Equal(t, int64(2), r.Get("messages").Count)
+
+		now = now.Add(30 * time.Second)
+		assert.Equal(t, int64(1), r.Get("messages").Count, "The first event should be a minute old")
+
+		now = now.Add(30 * time.Second)
+		assert.Zero(t, r.Get("messages").Count)
+	})
+
+	t.Run("Reused Buckets Start Empty", func(t *testing.T) {
+		now := time.Unix(1_700_000_000, 0)
+		r := New()
+		r.now = func() time.Time { return now }
+
+		r.Inc("messages")
+		now = now.Add(Window)
+		r.Inc("messages")
+
+		assert.Equal(t, int64(1), r.Get("messages").Count)
+	})
+
+	t.Run("Unknown Series Is Empty", func(t *testing.T) {
+		r := New()
+		assert.Equal(t, Stat{}, r.Get("missing"))
+		assert.Empty(t, r.Snapshot())
+	})
+
+	t.Run("Snapshot", func(t *testing.T) {
+		r := New()
+		r.Inc("a")
+		r.Observe("b", 10)
+
+		snapshot := r.Snapshot()
+		assert.Len(t, snapshot, 2)
+		assert.Equal(t, int64(1), snapshot["a"].Count)
+		assert.Equal(t, 10.0, snapshot["b"].Avg)
+	})
+
+	t.Run("Concurrent Access", func(t *testing.T) {
+		r := New()
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				r.Inc("messages")
+				r.Snapshot()
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int64(50), r.Get("messages").Count)
+	})
+}
@@ -0,0 +1,62 @@
+// Package redact masks secrets and personal data in free text before it is
+// stored or shown to operators.
+package redact
+
+import "regexp"
+
+// Placeholder replaces every redacted value
+const Placeholder = "[REDACTED]"
+
+var secretPatterns = []*regexp.Regexp{
+	// chat-bot tenant API keys
+	regexp.MustCompile(`cbk_[0-9a-f]{8,}`),
+	// Google API keys
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	// OpenAI and Anthropic style keys
+	regexp.MustCompile(`sk-[0-9A-Za-z_\-]{20,}`),
+	// AWS access key IDs
+	regexp.MustCompile(`(?:AKIA|ASIA)[0-9A-Z]{16}`),
+	// JSON web tokens
+	regexp.MustCompile(`eyJ[0-9A-Za-z_\-]+\.[0-9A-Za-z_\-]+\.[0-9A-Za-z_\-]+`),
+}
+
+var (
+	// bearer and basic credentials, keeping the scheme
+	authPattern = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[0-9A-Za-z._~+/\-]+=*`)
+	// key=value and "key": "value" pairs with a secret-looking key, keeping the key and any auth scheme
+	assignmentPattern = regexp.MustCompile(`(?i)("?\b[\w\-]*(?:api[_\-]?key|secret|password|passwd|token|authorization)"?\s*[:=]\s*"?(?:(?:bearer|basic)\s+)?)[^"\s,;&}]+`)
+)
+
+// Secrets masks API keys, tokens, credentials and secret-looking assignments in s
+func Secrets(s string) string {
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, Placeholder)
+	}
+	s = authPattern.ReplaceAllString(s, "$1 "+Placeholder)
+	return assignmentPattern.ReplaceAllString(s, "${1}"+Placeholder)
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// Vietnamese mobile and landline numbers, with or without the +84 prefix and separators
+	phonePattern = regexp.MustCompile(`(?:\+84|\b84|\b0)(?:[\s.\-]?\d){9,10}\b`)
+	// payment card numbers, with or without separators
+	cardPattern = regexp.MustCompile(`\b\d{4}(?:[\s\-]?\d{4}){3}\b`)
+	// Vietnamese citizen identity numbers; 9 digit legacy IDs are left alone as
+	// they are indistinguishable from Chotot listing IDs
+	nationalIDPattern = regexp.MustCompile(`\b\d{12}\b`)
+)
+
+// PII masks email addresses, phone numbers, card numbers and national ID numbers in s
+func PII(s string) string {
+	s = emailPattern.ReplaceAllString(s, "[EMAIL]")
+	s = cardPattern.ReplaceAllString(s, "[CARD]")
+	s = phonePattern.ReplaceAllString(s, "[PHONE]")
+	return nationalIDPattern.ReplaceAllString(s, "[ID]")
+}
+
+// Sensitive reports whether s holds card or national ID numbers, which are
+// kept from third parties altogether rather than masked
+func Sensitive(s string) bool {
+	return cardPattern.MatchString(s) || nationalIDPattern.MatchString(s)
+}
@@ -0,0 +1,127 @@
+// Package reqstats collects the work done on behalf of a single request, so it
+// can be reported back to the caller while debugging.
+package reqstats
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Stats counts the database, cache and LLM work of one request. The zero value
+// is ready to use and every method is safe on a nil *Stats.
+type Stats struct {
+	mu sync.Mutex
+
+	MongoQueries  int
+	MongoDuration time.Duration
+	CacheHits     int
+	CacheMisses   int
+	LLMCalls      int
+	LLMDuration   time.Duration
+}
+
+type ctxKey struct{}
+
+// With returns a context that collects stats into a fresh Stats
+func With(ctx context.Context) (context.Context, *Stats) {
+	stats := &Stats{}
+	return context.WithValue(ctx, ctxKey{}, stats), stats
+}
+
+// FromContext returns the Stats collected for ctx, or nil when ctx does not
+// collect any
+func FromContext(ctx context.Context) *Stats {
+	if ctx == nil {
+		return nil
+	}
+	stats, _ := ctx.Value(ctxKey{}).(*Stats)
+	return stats
+}
+
+// AddMongo records one database command
+func (s *Stats) AddMongo(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.MongoQueries++
+	s.MongoDuration += d
+}
+
+// AddCache records one cache lookup
+func (s *Stats) AddCache(hit bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if hit {
+		s.CacheHits++
+	} else {
+		s.CacheMisses++
+	}
+}
+
+// AddLLM records one model call
+func (s *Stats) AddLLM(d time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.LLMCalls++
+	s.LLMDuration += d
+}
+
+// String formats the stats as a header value, e.g.
+// "mongo=3;mongo_ms=12;cache_hit=1;cache_miss=2;llm=1;llm_ms=840"
+func (s *Stats) String() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fmt.Sprintf("mongo=%d;mongo_ms=%d;cache_hit=%d;cache_miss=%d;llm=%d;llm_ms=%d",
+		s.MongoQueries, s.MongoDuration.Milliseconds(),
+		s.CacheHits, s.CacheMisses,
+		s.LLMCalls, s.LLMDuration.Milliseconds())
+}
+
+// Parse reads stats formatted by String. Unknown fields are ignored, so
+// callers keep working against servers reporting more.
+func Parse(header string) (*Stats, error) {
+	stats := &Stats{}
+	if header == "" {
+		return stats, nil
+	}
+	for _, field := range strings.Split(header, ";") {
+		key, raw, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid stats field %q", field)
+		}
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stats field %q: %w", field, err)
+		}
+		switch key {
+		case "mongo":
+			stats.MongoQueries = value
+		case "mongo_ms":
+			stats.MongoDuration = time.Duration(value) * time.Millisecond
+		case "cache_hit":
+			stats.CacheHits = value
+		case "cache_miss":
+			stats.CacheMisses = value
+		case "llm":
+			stats.LLMCalls = value
+		case "llm_ms":
+			stats.LLMDuration = time.Duration(value) * time.Millisecond
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,92 @@
+package reqstats
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Collects Into The Context", func(t *testing.T) {
+		ctx, stats := With(context.Background())
+		FromContext(ctx).AddMongo(10 * time.Millisecond)
+		FromContext(ctx).AddMongo(5 * time.Millisecond)
+		FromContext(ctx).AddCache(true)
+		FromContext(ctx).AddCache(false)
+		FromContext(ctx).AddCache(false)
+		FromContext(ctx).AddLLM(800 * time.Millisecond)
+
+		assert.Equal(t, "mongo=2;mongo_ms=15;cache_hit=1;cache_miss=2;llm=1;llm_ms=800", stats.String())
+	})
+
+	t.Run("Ignores Contexts Without Stats", func(t *testing.T) {
+		stats := FromContext(context.Background())
+		assert.Nil(t, stats)
+
+		assert.NotPanics(t, func() {
+			stats.AddMongo(time.Millisecond)
+			stats.AddCache(true)
+			stats.AddLLM(time.Millisecond)
+		})
+		assert.Empty(t, stats.String())
+	})
+
+	t.Run("Safe For Concurrent Use", func(t *testing.T) {
+		_, stats := With(context.Background())
+
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				stats.AddMongo(time.Millisecond)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 50, stats.MongoQueries)
+		assert.Equal(t, 50*time.Millisecond, stats.MongoDuration)
+	})
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Reads What String Formats", func(t *testing.T) {
+		_, want := With(context.Background())
+		want.AddMongo(12 * time.Millisecond)
+		want.AddCache(false)
+		want.AddLLM(840 * time.Millisecond)
+
+		got, err := Parse(want.String())
+		require.NoError(t, err)
+		assert.Equal(t, want.String(), got.String())
+		assert.Equal(t, 840*time.Millisecond, got.LLMDuration)
+	})
+
+	t.Run("Ignores Unknown Fields", func(t *testing.T) {
+		got, err := Parse("mongo=2;queue_ms=7")
+		require.NoError(t, err)
+		assert.Equal(t, 2, got.MongoQueries)
+	})
+
+	t.Run("Empty Header Has No Stats", func(t *testing.T) {
+		got, err := Parse("")
+		require.NoError(t, err)
+		assert.Zero(t, got.MongoQueries)
+	})
+
+	t.Run("Rejects Malformed Fields", func(t *testing.T) {
+		_, err := Parse("mongo")
+		assert.Error(t, err)
+
+		_, err = Parse("mongo=many")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,178 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// AWSConfig are the credentials and region of the SSM and KMS providers
+type AWSConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	// Endpoint replaces https://<service>.<region>.amazonaws.com, e.g. for LocalStack
+	Endpoint string
+}
+
+type awsClient struct {
+	httpClient *http.Client
+	conf       AWSConfig
+	service    string
+}
+
+// NewSSM reads SSM Parameter Store parameters by name, decrypting
+// SecureString parameters with their KMS key
+func NewSSM(conf AWSConfig, httpClient *http.Client) Provider {
+	return &ssm{awsClient{httpClient: httpClient, conf: conf, service: "ssm"}}
+}
+
+// NewKMS decrypts KMS ciphertexts; names are the base64 ciphertext blob
+func NewKMS(conf AWSConfig, httpClient *http.Client) Provider {
+	return &kms{awsClient{httpClient: httpClient, conf: conf, service: "kms"}}
+}
+
+type ssm struct {
+	awsClient
+}
+
+func (s *ssm) Get(ctx context.Context, name string) (string, error) {
+	var out struct {
+		Parameter struct {
+			Value string `json:"Value"`
+		} `json:"Parameter"`
+	}
+	in := map[string]any{"Name": name, "WithDecryption": true}
+	if err := s.call(ctx, "AmazonSSM.GetParameter", in, &out); err != nil {
+		return "", err
+	}
+	return out.Parameter.Value, nil
+}
+
+type kms struct {
+	awsClient
+}
+
+func (k *kms) Get(ctx context.Context, name string) (string, error) {
+	var out struct {
+		Plaintext string `json:"Plaintext"`
+	}
+	if err := k.call(ctx, "TrentService.Decrypt", map[string]any{"CiphertextBlob": name}, &out); err != nil {
+		return "", err
+	}
+	plain, err := base64.StdEncoding.DecodeString(out.Plaintext)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode kms plaintext: %w", err)
+	}
+	return string(plain), nil
+}
+
+// call makes an AWS JSON 1.1 API call signed with Signature Version 4
+func (c *awsClient) call(ctx context.Context, target string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	endpoint := c.conf.Endpoint
+	if endpoint == "" {
+		endpoint = "https://" + c.service + "." + c.conf.Region + ".amazonaws.com"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	c.sign(req, body, time.Now().UTC())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if bytes.Contains(respBody, []byte("ParameterNotFound")) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%s returned status %d: %s", c.service, resp.StatusCode, respBody)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", c.service, err)
+	}
+	return nil
+}
+
+// sign adds an AWS Signature Version 4 Authorization header to req
+func (c *awsClient) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	headers := []string{"content-type", "host", "x-amz-date"}
+	if c.conf.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.conf.SessionToken)
+		headers = append(headers, "x-amz-security-token")
+	}
+	headers = append(headers, "x-amz-target")
+
+	var canonicalHeaders strings.Builder
+	for _, name := range headers {
+		canonicalHeaders.WriteString(name + ":" + req.Header.Get(name) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		"/",
+		"",
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + c.conf.Region + "/" + c.service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.conf.SecretAccessKey), date)
+	key = hmacSHA256(key, c.conf.Region)
+	key = hmacSHA256(key, c.service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.conf.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,100 @@
+// Package secrets resolves secret references kept in configuration, such as
+// "vault:secret/data/chat-bot#google_ai_key", to the values they point to.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nguyentranbao-ct/chat-bot/pkg/ttlcache"
+	"golang.org/x/sync/singleflight"
+)
+
+// Reference schemes, the part of a reference before the first colon
+const (
+	SchemeVault = "vault"
+	SchemeSSM   = "ssm"
+	SchemeKMS   = "kms"
+)
+
+var (
+	ErrNotFound         = errors.New("secret not found")
+	ErrNoProvider       = errors.New("no provider configured for secret reference")
+	errEmptySecretValue = errors.New("secret is empty")
+)
+
+// Provider fetches the secret identified by name, the part of a reference
+// after the scheme
+type Provider interface {
+	Get(ctx context.Context, name string) (string, error)
+}
+
+// ParseReference splits a reference into its scheme and name. Values without a
+// known scheme are not references and are used as they are.
+func ParseReference(value string) (scheme, name string, ok bool) {
+	scheme, name, found := strings.Cut(value, ":")
+	if !found || name == "" {
+		return "", "", false
+	}
+	switch scheme {
+	case SchemeVault, SchemeSSM, SchemeKMS:
+		return scheme, name, true
+	}
+	return "", "", false
+}
+
+// Resolver resolves references through the provider of their scheme. Values
+// are fetched on first use and cached for the TTL, so a rotated secret is
+// picked up once its cached value expires.
+type Resolver struct {
+	providers map[string]Provider
+	cache     *ttlcache.Cache[string, string]
+	group     singleflight.Group
+}
+
+func NewResolver(ttl time.Duration, providers map[string]Provider) *Resolver {
+	return &Resolver{
+		providers: providers,
+		cache:     ttlcache.New[string, string](ttl),
+	}
+}
+
+// Resolve returns the value a reference points to, or value itself when it is
+// not a reference
+func (r *Resolver) Resolve(ctx context.Context, value string) (string, error) {
+	scheme, name, ok := ParseReference(value)
+	if !ok {
+		return value, nil
+	}
+	if cached, ok := r.cache.Get(value); ok {
+		return cached, nil
+	}
+
+	provider, ok := r.providers[scheme]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNoProvider, scheme)
+	}
+	resolved, err, _ := r.group.Do(value, func() (any, error) {
+		secret, err := provider.Get(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		if secret == "" {
+			return "", errEmptySecretValue
+		}
+		r.cache.Set(value, secret)
+		return secret, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s secret %s: %w", scheme, name, err)
+	}
+	return resolved.(string), nil
+}
+
+// Invalidate drops the cached value of a reference so the next Resolve fetches it
+func (r *Resolver) Invalidate(value string) {
+	r.cache.Delete(value)
+}
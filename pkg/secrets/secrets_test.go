@@ -0,0 +1,203 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProvider struct {
+	calls  atomic.Int32
+	values map[string]string
+}
+
+func (p *fakeProvider) Get(_ context.Context, name string) (string, error) {
+	p.calls.Add(1)
+	value, ok := p.values[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func TestParseReference(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value  string
+		scheme string
+		name   string
+		ok     bool
+	}{
+		{"vault:secret/data/app#key", SchemeVault, "secret/data/app#key", true},
+		{"ssm:/chat-bot/api-key", SchemeSSM, "/chat-bot/api-key", true},
+		{"kms:AQICAHh=", SchemeKMS, "AQICAHh=", true},
+		{"plain-value", "", "", false},
+		{"https://example.com", "", "", false},
+		{"vault:", "", "", false},
+	}
+	for _, tt := range tests {
+		scheme, name, ok := ParseReference(tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+		assert.Equal(t, tt.scheme, scheme, tt.value)
+		assert.Equal(t, tt.name, name, tt.value)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Plain Values Pass Through", func(t *testing.T) {
+		r := NewResolver(time.Minute, nil)
+		value, err := r.Resolve(context.Background(), "plain-value")
+		require.NoError(t, err)
+		assert.Equal(t, "plain-value", value)
+	})
+
+	t.Run("Caches Fetched Values", func(t *testing.T) {
+		p := &fakeProvider{values: map[string]string{"/key": "secret"}}
+		r := NewResolver(time.Minute, map[string]Provider{SchemeSSM: p})
+
+		for range 3 {
+			value, err := r.Resolve(context.Background(), "ssm:/key")
+			require.NoError(t, err)
+			assert.Equal(t, "secret", value)
+		}
+		assert.Equal(t, int32(1), p.calls.Load(), "The secret should be fetched once")
+	})
+
+	t.Run("Picks Up Rotated Values", func(t *testing.T) {
+		p := &fakeProvider{values: map[string]string{"/key": "old"}}
+		r := NewResolver(time.Minute, map[string]Provider{SchemeSSM: p})
+
+		value, err := r.Resolve(context.Background(), "ssm:/key")
+		require.NoError(t, err)
+		assert.Equal(t, "old", value)
+
+		p.values["/key"] = "new"
+		r.Invalidate("ssm:/key")
+		value, err = r.Resolve(context.Background(), "ssm:/key")
+		require.NoError(t, err)
+		assert.Equal(t, "new", value)
+	})
+
+	t.Run("Missing Provider", func(t *testing.T) {
+		r := NewResolver(time.Minute, nil)
+		_, err := r.Resolve(context.Background(), "vault:secret/data/app#key")
+		assert.ErrorIs(t, err, ErrNoProvider)
+	})
+
+	t.Run("Errors Are Not Cached", func(t *testing.T) {
+		p := &fakeProvider{values: map[string]string{}}
+		r := NewResolver(time.Minute, map[string]Provider{SchemeSSM: p})
+
+		_, err := r.Resolve(context.Background(), "ssm:/key")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		p.values["/key"] = "secret"
+		value, err := r.Resolve(context.Background(), "ssm:/key")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+}
+
+func TestVault(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/app":
+			_, _ = w.Write([]byte(`{"data":{"data":{"api_key":"v2-secret"},"metadata":{"version":3}}}`))
+		case "/v1/kv/app":
+			_, _ = w.Write([]byte(`{"data":{"value":"v1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	v := NewVault(server.URL, "token", "", server.Client())
+
+	t.Run("KV Version 2", func(t *testing.T) {
+		value, err := v.Get(context.Background(), "secret/data/app#api_key")
+		require.NoError(t, err)
+		assert.Equal(t, "v2-secret", value)
+	})
+
+	t.Run("KV Version 1 Default Key", func(t *testing.T) {
+		value, err := v.Get(context.Background(), "kv/app")
+		require.NoError(t, err)
+		assert.Equal(t, "v1-secret", value)
+	})
+
+	t.Run("Missing Key", func(t *testing.T) {
+		_, err := v.Get(context.Background(), "secret/data/app#other")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("Missing Path", func(t *testing.T) {
+		_, err := v.Get(context.Background(), "secret/data/missing#api_key")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+}
+
+func TestAWS(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var in map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&in)
+
+		switch r.Header.Get("X-Amz-Target") {
+		case "AmazonSSM.GetParameter":
+			if in["Name"] != "/chat-bot/api-key" || in["WithDecryption"] != true {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"__type":"ParameterNotFound"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"Parameter":{"Value":"ssm-secret"}}`))
+		case "TrentService.Decrypt":
+			plain := base64.StdEncoding.EncodeToString([]byte("kms-secret"))
+			_, _ = w.Write([]byte(`{"Plaintext":"` + plain + `"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	conf := AWSConfig{Region: "us-east-1", AccessKeyID: "AKID", SecretAccessKey: "secret", Endpoint: server.URL}
+
+	t.Run("SSM Parameter", func(t *testing.T) {
+		value, err := NewSSM(conf, server.Client()).Get(context.Background(), "/chat-bot/api-key")
+		require.NoError(t, err)
+		assert.Equal(t, "ssm-secret", value)
+	})
+
+	t.Run("SSM Missing Parameter", func(t *testing.T) {
+		_, err := NewSSM(conf, server.Client()).Get(context.Background(), "/chat-bot/missing")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("KMS Ciphertext", func(t *testing.T) {
+		value, err := NewKMS(conf, server.Client()).Get(context.Background(), "AQICAHh=")
+		require.NoError(t, err)
+		assert.Equal(t, "kms-secret", value)
+	})
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// defaultVaultKey is read when a Vault reference names no key
+const defaultVaultKey = "value"
+
+type vault struct {
+	httpClient *http.Client
+	addr       string
+	token      string
+	namespace  string
+}
+
+// NewVault reads secrets from a Vault KV engine, v1 or v2. Names are the API
+// path of the secret and the key to read, e.g. "secret/data/chat-bot#api_key".
+func NewVault(addr, token, namespace string, httpClient *http.Client) Provider {
+	return &vault{
+		httpClient: httpClient,
+		addr:       strings.TrimSuffix(addr, "/"),
+		token:      token,
+		namespace:  namespace,
+	}
+}
+
+func (v *vault) Get(ctx context.Context, name string) (string, error) {
+	path, key, found := strings.Cut(name, "#")
+	if !found || key == "" {
+		key = defaultVaultKey
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.addr+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", v.token)
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("vault returned status %d: %s", resp.StatusCode, body)
+	}
+
+	var secret struct {
+		Data map[string]any `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", fmt.Errorf("failed to decode vault response: %w", err)
+	}
+
+	// KV v2 nests the secret under data.data
+	data := secret.Data
+	if nested, ok := data["data"].(map[string]any); ok {
+		data = nested
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%w: no %q key", ErrNotFound, key)
+	}
+	return value, nil
+}
@@ -0,0 +1,57 @@
+package textx
+
+import "strings"
+
+// Languages DetectLanguage tells apart
+const (
+	LanguageVietnamese = "vi"
+	LanguageEnglish    = "en"
+)
+
+// vietnameseLetters are letters only written in Vietnamese among the
+// languages buyers use, lower case
+const vietnameseLetters = "ăâđêôơưàảãáạằẳẵắặầẩẫấậèẻẽéẹềểễếệìỉĩíịòỏõóọồổỗốộờởỡớợùủũúụừửữứựỳỷỹýỵ"
+
+// DetectLanguage returns "vi" when most texts with letters contain Vietnamese
+// letters, "en" when they don't, and "" without any letters. Texts should be
+// normalized first, decomposed accents are not recognized.
+func DetectLanguage(texts ...string) string {
+	var vi, other int
+	for _, text := range texts {
+		lower := strings.ToLower(text)
+		switch {
+		case strings.ContainsAny(lower, vietnameseLetters):
+			vi++
+		case strings.ContainsFunc(lower, func(r rune) bool { return r >= 'a' && r <= 'z' }):
+			other++
+		}
+	}
+
+	switch {
+	case vi == 0 && other == 0:
+		return ""
+	case vi >= other:
+		return LanguageVietnamese
+	default:
+		return LanguageEnglish
+	}
+}
+
+// DominantLanguage returns the most frequent non-empty language, preferring
+// the earliest on ties, or "" when there is none
+func DominantLanguage(languages ...string) string {
+	counts := make(map[string]int)
+	for _, language := range languages {
+		if language != "" {
+			counts[language]++
+		}
+	}
+
+	dominant := ""
+	for _, language := range languages {
+		if counts[language] > counts[dominant] {
+			dominant = language
+		}
+	}
+	return dominant
+}
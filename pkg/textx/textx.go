@@ -0,0 +1,107 @@
+// Package textx cleans up user and model text before it is stored or sent to partners.
+package textx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Ellipsis ends truncated text
+const Ellipsis = "…"
+
+const (
+	zeroWidthJoiner = '\u200d'
+	byteOrderMark   = '\ufeff'
+)
+
+// Normalize drops invalid UTF-8, control characters other than newlines and
+// tabs, and byte order marks, turns CRLF and CR line endings into LF and
+// composes the text to NFC, so the same message is always the same bytes
+func Normalize(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	s = strings.Map(func(r rune) rune {
+		if r == byteOrderMark || (unicode.IsControl(r) && r != '\n' && r != '\t') {
+			return -1
+		}
+		return r
+	}, s)
+	return norm.NFC.String(s)
+}
+
+// Truncate cuts s to at most n characters, ellipsis included, without
+// splitting a user-perceived character such as an accented letter, an emoji
+// with a skin tone or a flag
+func Truncate(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	budget := n - utf8.RuneCountInString(Ellipsis)
+	end, used := 0, 0
+	for end < len(s) {
+		size := graphemeLen(s[end:])
+		runes := utf8.RuneCountInString(s[end : end+size])
+		if used+runes > budget {
+			break
+		}
+		end += size
+		used += runes
+	}
+	return strings.TrimRightFunc(s[:end], unicode.IsSpace) + Ellipsis
+}
+
+// graphemeLen is the length in bytes of the first grapheme cluster of s. It
+// covers what chat text needs: combining marks, emoji modifiers, variation
+// selectors, tags, zero width joiner sequences, flags and CRLF, not the full
+// UAX #29 rules.
+func graphemeLen(s string) int {
+	first, size := utf8.DecodeRuneInString(s)
+	if first == '\r' && strings.HasPrefix(s[size:], "\n") {
+		return size + 1
+	}
+	if isRegionalIndicator(first) {
+		if next, nextSize := utf8.DecodeRuneInString(s[size:]); isRegionalIndicator(next) {
+			return size + nextSize
+		}
+		return size
+	}
+
+	end := size
+	for end < len(s) {
+		r, rSize := utf8.DecodeRuneInString(s[end:])
+		switch {
+		case r == zeroWidthJoiner:
+			end += rSize
+			if end < len(s) {
+				_, joinedSize := utf8.DecodeRuneInString(s[end:])
+				end += joinedSize
+			}
+		case extends(r):
+			end += rSize
+		default:
+			return end
+		}
+	}
+	return end
+}
+
+// extends reports whether r belongs to the cluster of the rune before it
+func extends(r rune) bool {
+	return unicode.In(r, unicode.Mn, unicode.Me, unicode.Mc) ||
+		(r >= 0xfe00 && r <= 0xfe0f) || // variation selectors
+		(r >= 0x1f3fb && r <= 0x1f3ff) || // emoji skin tones
+		(r >= 0xe0020 && r <= 0xe007f) || // tags, e.g. subdivision flags
+		(r >= 0xe0100 && r <= 0xe01ef) // variation selectors supplement
+}
+
+func isRegionalIndicator(r rune) bool {
+	return r >= 0x1f1e6 && r <= 0x1f1ff
+}
@@ -0,0 +1,100 @@
+package textx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"Composes To NFC", "Tie\u0302\u0301ng Vie\u0323\u0302t", "Ti\u1ebfng Vi\u1ec7t"},
+		{"Strips Control Characters", "a\x00b\x1bc\x7fd", "abcd"},
+		{"Keeps Newlines And Tabs", "a\n\tb", "a\n\tb"},
+		{"Normalizes Line Endings", "a\r\nb\rc", "a\nb\nc"},
+		{"Drops Invalid UTF-8", "a\xffb", "ab"},
+		{"Drops Byte Order Marks", "\ufeffhello", "hello"},
+		{"Keeps Emoji Sequences", "👨\u200d👩\u200d👧 👍🏽 🇻🇳", "👨\u200d👩\u200d👧 👍🏽 🇻🇳"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Normalize(tt.in))
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		n    int
+		want string
+	}{
+		{"Short Text Is Kept", "hello", 5, "hello"},
+		{"Adds Ellipsis", "hello world", 8, "hello w…"},
+		{"Trims Space Before Ellipsis", "hello world", 7, "hello…"},
+		{"Counts Characters Not Bytes", "Xin chào bạn", 9, "Xin chào…"},
+		{"Keeps Combining Marks", "ae\u0301e\u0301", 3, "a…"},
+		{"Keeps Skin Tones", "ab👍🏽c", 4, "ab…"},
+		{"Keeps Flags", "a🇻🇳🇺🇸", 4, "a🇻🇳…"},
+		{"Keeps ZWJ Sequences", "a👨\u200d👩\u200d👧b", 5, "a…"},
+		{"Zero Length", "hello", 0, ""},
+		{"Only Ellipsis Fits", "hello", 1, "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Truncate(tt.in, tt.n))
+		})
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		texts []string
+		want  string
+	}{
+		{"Vietnamese", []string{"Xin chào, còn hàng không?"}, LanguageVietnamese},
+		{"Unaccented Vietnamese Counts As English", []string{"con hang khong"}, LanguageEnglish},
+		{"English", []string{"Is this still available?"}, LanguageEnglish},
+		{"Majority Wins", []string{"hello", "thanks", "cảm ơn"}, LanguageEnglish},
+		{"Ties Go To Vietnamese", []string{"hello", "cảm ơn"}, LanguageVietnamese},
+		{"No Letters", []string{"123", "👍"}, ""},
+		{"No Texts", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectLanguage(tt.texts...))
+		})
+	}
+}
+
+func TestDominantLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		languages []string
+		want      string
+	}{
+		{"Most Frequent", []string{"en", "vi", "vi"}, "vi"},
+		{"Earliest On Ties", []string{"en", "vi", "vi", "en"}, "en"},
+		{"Skips Empty", []string{"", "", "en"}, "en"},
+		{"None", []string{"", ""}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DominantLanguage(tt.languages...))
+		})
+	}
+}
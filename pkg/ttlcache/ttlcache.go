@@ -0,0 +1,74 @@
+// Package ttlcache is a small in-memory cache whose entries expire after a fixed TTL.
+package ttlcache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+type Cache[K comparable, V any] struct {
+	ttl       time.Duration
+	now       func() time.Time
+	mu        sync.Mutex
+	entries   map[K]entry[V]
+	lastSweep time.Time
+}
+
+func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[K]entry[V]),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return *new(V), false
+	}
+	if !c.now().Before(e.expiresAt) {
+		delete(c.entries, key)
+		return *new(V), false
+	}
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	// sweeping at most once per TTL on write is enough to keep memory bounded
+	// without a background goroutine
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for k, e := range c.entries {
+			if !now.Before(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+	c.entries[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Clear drops every entry
+func (c *Cache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]entry[V])
+}
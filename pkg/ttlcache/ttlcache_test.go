@@ -0,0 +1,88 @@
+package ttlcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Set and Get Value", func(t *testing.T) {
+		c := New[string, int](time.Minute)
+		c.Set("a", 1)
+
+		v, ok := c.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, 1, v)
+
+		_, ok = c.Get("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("Entries Expire", func(t *testing.T) {
+		now := time.Now()
+		c := New[string, int](time.Minute)
+		c.now = func() time.Time { return now }
+
+		c.Set("a", 1)
+		now = now.Add(59 * time.Second)
+		_, ok := c.Get("a")
+		assert.True(t, ok, "The entry should live until its TTL")
+
+		now = now.Add(time.Second)
+		_, ok = c.Get("a")
+		assert.False(t, ok, "The entry should be gone after its TTL")
+	})
+
+	t.Run("Caches Nil Values", func(t *testing.T) {
+		c := New[string, *int](time.Minute)
+		c.Set("a", nil)
+
+		v, ok := c.Get("a")
+		assert.True(t, ok, "A negative result should be cached too")
+		assert.Nil(t, v)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		c := New[string, int](time.Minute)
+		c.Set("a", 1)
+		c.Delete("a")
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		c := New[string, int](time.Minute)
+		c.Set("a", 1)
+		c.Set("b", 2)
+		c.Clear()
+
+		_, ok := c.Get("a")
+		assert.False(t, ok)
+		_, ok = c.Get("b")
+		assert.False(t, ok)
+	})
+
+	t.Run("Concurrent Access", func(t *testing.T) {
+		c := New[int, int](time.Minute)
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				c.Set(i, i)
+				c.Get(i)
+			}(i)
+		}
+		wg.Wait()
+
+		v, ok := c.Get(10)
+		assert.True(t, ok)
+		assert.Equal(t, 10, v)
+	})
+}
@@ -143,3 +143,22 @@ func GetHistogramVec(name string, labels ...string) (*prometheus.HistogramVec, e
 
 	return metrics, nil
 }
+
+func GetCounterVec(name string, labels ...string) (*prometheus.CounterVec, error) {
+	metrics := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: name,
+	}, labels)
+	if err := prometheus.Register(metrics); err != nil {
+		// prometheus returns AlreadyRegisteredError by value
+		var registeredErr prometheus.AlreadyRegisteredError
+		if ok := errors.As(err, &registeredErr); ok {
+			metrics, ok := registeredErr.ExistingCollector.(*prometheus.CounterVec)
+			if ok {
+				return metrics, nil
+			}
+		}
+		return nil, fmt.Errorf("register: %w", err)
+	}
+
+	return metrics, nil
+}